@@ -36,7 +36,7 @@ REQUIREMENTS
 COMMANDS
 
   key     Manage private keys (create, list, delete)
-  token   Manage JWT tokens (create)
+  token   Manage JWT tokens (create, revoke)
 
 ENVIRONMENT VARIABLES
 
@@ -53,7 +53,10 @@ EXAMPLES
   kronk security key create --name=my-key
 
   # Create a JWT token for a user
-  kronk security token create --user=john --ttl=1h`,
+  kronk security token create --user=john --ttl=1h
+
+  # Revoke a single token by its ID
+  kronk security token revoke --id=<token-id>`,
 	PersistentPreRunE: authenticate,
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
@@ -5,6 +5,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
 	"github.com/spf13/cobra"
 )
 
@@ -16,16 +17,32 @@ var Cmd = &cobra.Command{
 Flags:
       --duration     Token duration (e.g., 1h, 24h, 720h)
       --endpoints    Comma-separated list of endpoints with optional rate limits
+      --priority     Highest scheduling priority of the token: low, normal, or high
+      --token-budgets Comma-separated list of input/output token budgets
+      --models       Comma-separated list of model IDs or glob patterns the token may use
 
 Endpoint format:
       endpoint                  Unlimited access (default)
       endpoint:unlimited        Unlimited access (explicit)
       endpoint:limit/window     Rate limited (window: day, month, year)
 
+Token budget format:
+      input/output/window        Budget shared by all models (window: day, month, year)
+      model=input/output/window  Budget for a single model
+      A 0 input or output leaves that direction uncapped.
+
+Model format:
+      Qwen3-8B-Q8_0             A single model ID
+      *Qwen3-0.6B*              A glob pattern (*, ?, [abc], {a,b})
+      Without --models the token may use every model.
+
 Examples:
       --endpoints chat-completions,embeddings
       --endpoints "chat-completions:1000/day,embeddings:unlimited"
       --endpoints "chat-completions:100/month,embeddings:500/year"
+      --priority low
+      --token-budgets "2000000/500000/day,Qwen3-8B-Q8_0=0/100000/day"
+      --models "*Qwen3-0.6B*,*Qwen3-8B*"
 
 Environment Variables (web mode - default):
       KRONK_TOKEN         (required when auth enabled)  Authentication token for the kronk server.
@@ -38,6 +55,9 @@ func init() {
 	Cmd.Flags().Bool("local", false, "Run without the model server")
 	Cmd.Flags().String("duration", "", "Token duration (e.g., 1h, 24h, 720h)")
 	Cmd.Flags().StringSlice("endpoints", []string{}, "Endpoints with optional rate limits (e.g., chat-completions:1000/day)")
+	Cmd.Flags().String("priority", "", "Highest scheduling priority of the token: low, normal, or high")
+	Cmd.Flags().StringSlice("token-budgets", []string{}, "Input/output token budgets (e.g., 2000000/500000/day or model=0/100000/day)")
+	Cmd.Flags().StringSlice("models", []string{}, "Model IDs or glob patterns the token may use (e.g., *Qwen3-8B*)")
 }
 
 func main(cmd *cobra.Command, args []string) {
@@ -52,6 +72,9 @@ func run(cmd *cobra.Command) error {
 	adminToken := os.Getenv("KRONK_TOKEN")
 	flagDuration, _ := cmd.Flags().GetString("duration")
 	flagEndpoints, _ := cmd.Flags().GetStringSlice("endpoints")
+	flagPriority, _ := cmd.Flags().GetString("priority")
+	flagTokenBudgets, _ := cmd.Flags().GetStringSlice("token-budgets")
+	flagModels, _ := cmd.Flags().GetStringSlice("models")
 
 	duration, err := time.ParseDuration(flagDuration)
 	if err != nil {
@@ -63,10 +86,26 @@ func run(cmd *cobra.Command) error {
 		return fmt.Errorf("parse-endpoints: %w", err)
 	}
 
+	if err := auth.ValidatePriority(flagPriority); err != nil {
+		return fmt.Errorf("parse-priority: %w", err)
+	}
+
+	tokenBudgets, err := parseTokenBudgets(flagTokenBudgets)
+	if err != nil {
+		return fmt.Errorf("parse-token-budgets: %w", err)
+	}
+
+	if err := auth.ValidateModels(flagModels); err != nil {
+		return fmt.Errorf("parse-models: %w", err)
+	}
+
 	cfg := config{
-		AdminToken: adminToken,
-		Endpoints:  endpoints,
-		Duration:   duration,
+		AdminToken:   adminToken,
+		Endpoints:    endpoints,
+		Duration:     duration,
+		Priority:     flagPriority,
+		TokenBudgets: tokenBudgets,
+		Models:       flagModels,
 	}
 
 	switch local {
@@ -12,19 +12,32 @@ import (
 
 	"github.com/ardanlabs/kronk/cmd/kronk/client"
 	"github.com/ardanlabs/kronk/cmd/kronk/security/sec"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
 )
 
 type config struct {
-	AdminToken string
-	Endpoints  map[string]auth.RateLimit
-	Duration   time.Duration
+	AdminToken   string
+	Endpoints    map[string]auth.RateLimit
+	Duration     time.Duration
+	Priority     string
+	TokenBudgets []auth.TokenBudget
+	Models       []string
 }
 
 func runWeb(cfg config) error {
 	fmt.Println("Token create")
 	fmt.Printf("  Duration: %s\n", cfg.Duration)
 	fmt.Printf("  Endpoints: %v\n", cfg.Endpoints)
+	if cfg.Priority != "" {
+		fmt.Printf("  Priority: %s\n", cfg.Priority)
+	}
+	if len(cfg.TokenBudgets) > 0 {
+		fmt.Printf("  Token Budgets: %v\n", cfg.TokenBudgets)
+	}
+	if len(cfg.Models) > 0 {
+		fmt.Printf("  Models: %v\n", cfg.Models)
+	}
 
 	url, err := client.DefaultURL("/v1/security/token/create")
 	if err != nil {
@@ -34,9 +47,12 @@ func runWeb(cfg config) error {
 	fmt.Println("URL:", url)
 
 	req := client.D{
-		"admin":     false,
-		"endpoints": cfg.Endpoints,
-		"duration":  cfg.Duration,
+		"admin":         false,
+		"endpoints":     cfg.Endpoints,
+		"duration":      cfg.Duration,
+		"priority":      cfg.Priority,
+		"token_budgets": cfg.TokenBudgets,
+		"models":        cfg.Models,
 	}
 
 	cln := client.New(
@@ -64,8 +80,17 @@ func runLocal(cfg config) error {
 	fmt.Println("Token create")
 	fmt.Printf("  Duration: %s\n", cfg.Duration)
 	fmt.Printf("  Endpoints: %v\n", cfg.Endpoints)
+	if cfg.Priority != "" {
+		fmt.Printf("  Priority: %s\n", cfg.Priority)
+	}
+	if len(cfg.TokenBudgets) > 0 {
+		fmt.Printf("  Token Budgets: %v\n", cfg.TokenBudgets)
+	}
+	if len(cfg.Models) > 0 {
+		fmt.Printf("  Models: %v\n", cfg.Models)
+	}
 
-	token, err := sec.Security.GenerateToken(false, cfg.Endpoints, cfg.Duration)
+	token, err := sec.Security.GenerateToken(false, cfg.Endpoints, cfg.Duration, security.WithPriority(cfg.Priority), security.WithTokenBudgets(cfg.TokenBudgets), security.WithModels(cfg.Models))
 	if err != nil {
 		return fmt.Errorf("generate-token: %w", err)
 	}
@@ -135,6 +160,74 @@ func parseEndpointSpec(spec string) (string, auth.RateLimit, error) {
 	return name, auth.RateLimit{Limit: limit, Window: window}, nil
 }
 
+// parseTokenBudgets parses token budget specifications in the format:
+// "input/output/window" for a budget shared by all models or
+// "model=input/output/window" for a single model.
+// Examples:
+//   - "2000000/500000/day" -> 2M input and 500k output tokens per day
+//   - "Qwen3-8B-Q8_0=0/100000/month" -> 100k output tokens per month
+func parseTokenBudgets(specs []string) ([]auth.TokenBudget, error) {
+	var result []auth.TokenBudget
+
+	for _, spec := range specs {
+		budget, err := parseTokenBudgetSpec(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token budget spec %q: %w", spec, err)
+		}
+
+		result = append(result, budget)
+	}
+
+	if err := auth.ValidateTokenBudgets(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseTokenBudgetSpec(spec string) (auth.TokenBudget, error) {
+	var model string
+
+	limitSpec := strings.TrimSpace(spec)
+	if i := strings.LastIndex(limitSpec, "="); i >= 0 {
+		model = strings.TrimSpace(limitSpec[:i])
+		limitSpec = strings.TrimSpace(limitSpec[i+1:])
+
+		if model == "" {
+			return auth.TokenBudget{}, fmt.Errorf("empty model name")
+		}
+	}
+
+	parts := strings.Split(limitSpec, "/")
+	if len(parts) != 3 {
+		return auth.TokenBudget{}, fmt.Errorf("expected format input/output/window (e.g., 2000000/500000/day)")
+	}
+
+	input, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
+	if err != nil {
+		return auth.TokenBudget{}, fmt.Errorf("invalid input limit: %w", err)
+	}
+
+	output, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+	if err != nil {
+		return auth.TokenBudget{}, fmt.Errorf("invalid output limit: %w", err)
+	}
+
+	window, err := parseWindow(strings.TrimSpace(parts[2]))
+	if err != nil {
+		return auth.TokenBudget{}, err
+	}
+
+	budget := auth.TokenBudget{
+		Model:  model,
+		Input:  input,
+		Output: output,
+		Window: window,
+	}
+
+	return budget, nil
+}
+
 func parseWindow(s string) (auth.RateWindow, error) {
 	window, err := auth.ParseRateWindow(strings.ToLower(s))
 	if err != nil {
@@ -0,0 +1,67 @@
+package revoke
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke a security token",
+	Long: `Revoke a single security token without deleting its signing key
+
+A token is identified by its ID (the jti claim, shown by GET /v1/security/tokens)
+or by the token itself. Tokens issued before token IDs were introduced have no
+ID and can only be revoked by deleting their signing key.
+
+Flags:
+      --id       The token ID to revoke
+      --token    The token to revoke
+
+Environment Variables (web mode - default):
+      KRONK_TOKEN         (required when auth enabled)  Authentication token for the kronk server.
+      KRONK_WEB_API_HOST  (default localhost:11435)  IP Address for the kronk server.`,
+	Args: cobra.NoArgs,
+	Run:  main,
+}
+
+func init() {
+	Cmd.Flags().Bool("local", false, "Run without the model server")
+	Cmd.Flags().String("id", "", "The token ID (jti claim) to revoke")
+	Cmd.Flags().String("token", "", "The token to revoke")
+}
+
+func main(cmd *cobra.Command, args []string) {
+	if err := run(cmd); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func run(cmd *cobra.Command) error {
+	local, _ := cmd.Flags().GetBool("local")
+	id, _ := cmd.Flags().GetString("id")
+	token, _ := cmd.Flags().GetString("token")
+
+	if id == "" && token == "" {
+		return errors.New("one of --id or --token is required")
+	}
+
+	var err error
+
+	switch local {
+	case true:
+		err = runLocal(id, token)
+	default:
+		err = runWeb(id, token)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+// Package revoke provides the token revoke command code.
+package revoke
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/kronk/client"
+	"github.com/ardanlabs/kronk/cmd/kronk/security/sec"
+)
+
+func runWeb(id string, token string) error {
+	url, err := client.DefaultURL("/v1/security/token/revoke")
+	if err != nil {
+		return fmt.Errorf("default-url: %w", err)
+	}
+
+	fmt.Println("URL:", url)
+
+	req := client.D{
+		"id":    id,
+		"token": token,
+	}
+
+	cln := client.New(
+		client.FmtLogger,
+		client.WithBearer(os.Getenv("KRONK_TOKEN")),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var resp struct {
+		ID        string    `json:"id"`
+		Subject   string    `json:"subject"`
+		RevokedAt time.Time `json:"revoked_at"`
+	}
+	if err := cln.Do(ctx, http.MethodPost, url, req, &resp); err != nil {
+		return fmt.Errorf("do: unable to revoke token: %w", err)
+	}
+
+	fmt.Printf("Token %q (subject %s) revoked at %s\n", resp.ID, resp.Subject, resp.RevokedAt.Format(time.RFC3339))
+
+	return nil
+}
+
+func runLocal(id string, token string) error {
+	revoked, err := sec.Security.RevokeToken(context.Background(), id, token)
+	if err != nil {
+		return fmt.Errorf("revoke-token: %w", err)
+	}
+
+	fmt.Printf("Token %q (subject %s) revoked at %s\n", revoked.ID, revoked.Subject, revoked.RevokedAt.Format(time.RFC3339))
+
+	return nil
+}
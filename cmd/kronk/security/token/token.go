@@ -3,13 +3,14 @@ package token
 
 import (
 	"github.com/ardanlabs/kronk/cmd/kronk/security/token/create"
+	"github.com/ardanlabs/kronk/cmd/kronk/security/token/revoke"
 	"github.com/spf13/cobra"
 )
 
 var Cmd = &cobra.Command{
 	Use:   "token",
 	Short: "Manage tokens",
-	Long:  `Manage tokens - create and manage security tokens`,
+	Long:  `Manage tokens - create and revoke security tokens`,
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
 	},
@@ -17,4 +18,5 @@ var Cmd = &cobra.Command{
 
 func init() {
 	Cmd.AddCommand(create.Cmd)
+	Cmd.AddCommand(revoke.Cmd)
 }
@@ -61,10 +61,31 @@ func init() {
 	Cmd.Flags().Int("models-in-pool", 0, "Safety-net cap on the number of distinct models kept loaded, regardless of budget (default: 10)")
 	Cmd.Flags().String("pool-ttl", "", "Idle model TTL (e.g., 5m, 1h; 0 disables expiration)")
 
+	// Responses settings
+	Cmd.Flags().String("response-store", "", "Storage for Responses API previous_response_id chaining (memory, disk, none)")
+	Cmd.Flags().String("response-ttl", "", "Stored response TTL (e.g., 24h; 0 disables expiration)")
+
+	// Files settings
+	Cmd.Flags().Int("files-max-bytes", 0, "Maximum size of a file uploaded to the Files API (default: 512 MiB)")
+	Cmd.Flags().Int("files-quota-bytes", 0, "Maximum total size of the files stored for one subject (default: 10 GiB)")
+
+	// Batches settings
+	Cmd.Flags().Int("batch-concurrency", 0, "Number of lines of a batch dispatched at the same time (default: 4)")
+
+	// Media fetch settings
+	Cmd.Flags().Bool("media-fetch-enabled", false, "Download http(s) media URLs in image_url, video_url and input_audio parts")
+	Cmd.Flags().StringSlice("media-fetch-allowed-hosts", nil, "Hosts, *.domain patterns, CIDR blocks and * for any public host media may be fetched from; none are allowed when unset")
+	Cmd.Flags().Bool("media-fetch-allow-private", false, "Allow fetching media from private, loopback and link-local addresses")
+	Cmd.Flags().Int("media-fetch-max-bytes", 0, "Maximum size of a fetched media file (default: 20 MiB)")
+	Cmd.Flags().String("media-fetch-timeout", "", "Timeout for fetching one media URL (default: 10s)")
+	Cmd.Flags().Bool("media-fetch-cache", false, "Cache fetched media with an ETag on disk and revalidate it")
+	Cmd.Flags().Int("media-fetch-cache-max-bytes", 0, "Maximum total size of the media cache; least recently used media is removed first (default: 1 GiB)")
+
 	// Runtime settings
 	Cmd.Flags().String("base-path", "", "Base path for kronk data")
 	Cmd.Flags().String("lib-path", "", "Path to llama library")
 	Cmd.Flags().String("bucky-lib-path", "", "Path to whisper library")
+	Cmd.Flags().String("malina-lib-path", "", "Path to stable-diffusion library")
 	Cmd.Flags().String("lib-version", "", "Version of llama library")
 	Cmd.Flags().String("arch", "", "Architecture override")
 	Cmd.Flags().String("os", "", "OS override")
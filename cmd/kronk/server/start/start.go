@@ -148,10 +148,31 @@ func buildEnvVars(cmd *cobra.Command) []string {
 	addInt("models-in-pool", "KRONK_POOL_MODELS_IN_POOL")
 	addString("pool-ttl", "KRONK_POOL_TTL")
 
+	// Responses settings
+	addString("response-store", "KRONK_RESPONSES_STORE")
+	addString("response-ttl", "KRONK_RESPONSES_TTL")
+
+	// Files settings
+	addInt("files-max-bytes", "KRONK_FILES_MAX_BYTES")
+	addInt("files-quota-bytes", "KRONK_FILES_QUOTA_BYTES")
+
+	// Batches settings
+	addInt("batch-concurrency", "KRONK_BATCHES_CONCURRENCY")
+
+	// Media fetch settings
+	addBool("media-fetch-enabled", "KRONK_MEDIA_FETCH_ENABLED")
+	addStringSlice("media-fetch-allowed-hosts", "KRONK_MEDIA_FETCH_ALLOWED_HOSTS")
+	addBool("media-fetch-allow-private", "KRONK_MEDIA_FETCH_ALLOW_PRIVATE")
+	addInt("media-fetch-max-bytes", "KRONK_MEDIA_FETCH_MAX_BYTES")
+	addString("media-fetch-timeout", "KRONK_MEDIA_FETCH_TIMEOUT")
+	addBool("media-fetch-cache", "KRONK_MEDIA_FETCH_CACHE")
+	addInt("media-fetch-cache-max-bytes", "KRONK_MEDIA_FETCH_CACHE_MAX_BYTES")
+
 	// Runtime settings
 	addString("base-path", "KRONK_BASE_PATH")
 	addString("lib-path", "KRONK_LIB_PATH")
 	addString("bucky-lib-path", "KRONK_BUCKY_LIB_PATH")
+	addString("malina-lib-path", "KRONK_MALINA_LIB_PATH")
 	addString("lib-version", "KRONK_LIB_VERSION")
 	addString("arch", "KRONK_ARCH")
 	addString("os", "KRONK_OS")
@@ -101,11 +101,13 @@ func TestBuildEnvVarsServiceSettings(t *testing.T) {
 	cmd.Flags().String("authorization-mode", "", "")
 	cmd.Flags().Bool("download-enabled", false, "")
 	cmd.Flags().String("bucky-lib-path", "", "")
+	cmd.Flags().String("malina-lib-path", "", "")
 
 	values := map[string]string{
 		"authorization-mode": "management",
 		"download-enabled":   "true",
 		"bucky-lib-path":     "/opt/bucky",
+		"malina-lib-path":    "/opt/malina",
 	}
 	for name, value := range values {
 		if err := cmd.Flags().Set(name, value); err != nil {
@@ -118,6 +120,7 @@ func TestBuildEnvVarsServiceSettings(t *testing.T) {
 		"KRONK_AUTHORIZATION_MODE=management",
 		"KRONK_DOWNLOAD_ENABLED=true",
 		"KRONK_BUCKY_LIB_PATH=/opt/bucky",
+		"KRONK_MALINA_LIB_PATH=/opt/malina",
 	}
 	for _, want := range wants {
 		if !slices.Contains(envVars, want) {
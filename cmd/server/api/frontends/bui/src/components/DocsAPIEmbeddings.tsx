@@ -70,7 +70,7 @@ export default function DocsAPIEmbeddings() {
                     <td><code>input</code></td>
                     <td><code>string|array</code></td>
                     <td>Yes</td>
-                    <td>Text to generate embeddings for. Can be a string or array of strings.</td>
+                    <td>Text to generate embeddings for. Can be a string, an array of strings, an array of token IDs, or an array of token ID arrays.</td>
                   </tr>
                   <tr>
                     <td><code>dimensions</code></td>
@@ -78,6 +78,18 @@ export default function DocsAPIEmbeddings() {
                     <td>No</td>
                     <td>Reduce output to first N dimensions (for Matryoshka models). Must be &lt;= model's native dimensions.</td>
                   </tr>
+                  <tr>
+                    <td><code>encoding_format</code></td>
+                    <td><code>string</code></td>
+                    <td>No</td>
+                    <td>Vector encoding: 'float' (default) or 'base64' (little-endian float32 values).</td>
+                  </tr>
+                  <tr>
+                    <td><code>chunking</code></td>
+                    <td><code>boolean|object</code></td>
+                    <td>No</td>
+                    <td>Split over-long inputs into overlapping chunks and pool them. An object sets size, overlap, pooling ('mean' or 'max') and return_chunks.</td>
+                  </tr>
                 </tbody>
               </table>
               <h5>Response</h5>
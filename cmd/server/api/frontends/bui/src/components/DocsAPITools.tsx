@@ -23,10 +23,57 @@ const endpointGroups: EndpointGroup[] = [
     title: 'OpenAI Model Discovery',
     description: 'OpenAI-compatible model discovery for inference clients.',
     endpoints: [
-      { method: 'GET', path: '/v1/models', description: 'List locally available models and configured model extensions.', auth: 'Inference' },
+      { method: 'GET', path: '/v1/models', description: 'List locally available models and configured model extensions, limited to the models the token may use.', auth: 'Inference' },
       { method: 'GET', path: '/v1/models/{model}', description: 'Retrieve one locally available model by ID.', auth: 'Inference' },
     ],
   },
+  {
+    id: 'completions',
+    title: 'Raw Completions',
+    description: 'Raw prompt and fill-in-the-middle completions that bypass the chat template.',
+    endpoints: [
+      { method: 'POST', path: '/v1/completions', description: 'Legacy OpenAI completions with prompt, suffix for FIM, echo, logprobs, stop, and streaming.', auth: 'Inference' },
+      { method: 'POST', path: '/v1/infill', description: 'Code infill from llama.cpp style input_prefix, input_suffix, and prompt fields.', auth: 'Inference' },
+      { method: 'POST', path: '/infill', description: 'Alias for /v1/infill for editor plugins written against llama.cpp.', auth: 'Inference' },
+    ],
+  },
+  {
+    id: 'files',
+    title: 'Files',
+    description: 'Uploaded files that chat, responses, and messages requests reference by file_id.',
+    endpoints: [
+      { method: 'POST', path: '/v1/files', description: 'Upload a multipart file with a purpose.', auth: 'Inference' },
+      { method: 'GET', path: '/v1/files', description: 'List the caller files with purpose, limit, order, and after filters.', auth: 'Inference' },
+      { method: 'GET', path: '/v1/files/{file_id}', description: 'Return the file object for one file.', auth: 'Inference' },
+      { method: 'GET', path: '/v1/files/{file_id}/content', description: 'Download the original file content.', auth: 'Inference' },
+      { method: 'DELETE', path: '/v1/files/{file_id}', description: 'Delete a file and its content once no other file shares it.', auth: 'Inference' },
+    ],
+  },
+  {
+    id: 'batches',
+    title: 'Batches',
+    description: 'Offline bulk inference over an uploaded JSONL file of chat, embeddings, or rerank requests.',
+    endpoints: [
+      { method: 'POST', path: '/v1/batches', description: 'Queue a batch from a file uploaded with purpose batch.', auth: 'Inference' },
+      { method: 'GET', path: '/v1/batches', description: 'List the caller batches, newest first, with limit and after.', auth: 'Inference' },
+      { method: 'GET', path: '/v1/batches/{batch_id}', description: 'Return the batch object with status, progress counts, and result file IDs.', auth: 'Inference' },
+      { method: 'POST', path: '/v1/batches/{batch_id}/cancel', description: 'Cancel a batch, keeping the results of requests already finished.', auth: 'Inference' },
+    ],
+  },
+  {
+    id: 'ollama',
+    title: 'Ollama API',
+    description: 'Ollama-compatible routes for tools that only speak the Ollama API, streaming newline-delimited JSON by default.',
+    endpoints: [
+      { method: 'POST', path: '/api/chat', description: 'Chat with messages, tools, images, format, think, and options such as temperature and num_predict.', auth: 'Inference' },
+      { method: 'POST', path: '/api/generate', description: 'Generate from a prompt through the chat template, or as a raw or suffix completion.', auth: 'Inference' },
+      { method: 'POST', path: '/api/embed', description: 'Embed a string or an array of strings with truncate and dimensions.', auth: 'Inference' },
+      { method: 'GET', path: '/api/tags', description: 'List local models the token may use, named with a :latest tag.', auth: 'Inference' },
+      { method: 'POST', path: '/api/show', description: 'Return the details, template, parameters, model_info, and capabilities of a model.', auth: 'Inference' },
+      { method: 'GET', path: '/api/ps', description: 'List the loaded models with their memory use and expiry.', auth: 'Inference' },
+      { method: 'POST', path: '/api/pull', description: 'Download a catalog ID or URL and stream Ollama progress lines.', auth: 'Admin' },
+    ],
+  },
   {
     id: 'kronk-libraries',
     title: 'Kronk Libraries',
@@ -49,8 +96,13 @@ const endpointGroups: EndpointGroup[] = [
       { method: 'GET', path: '/v1/kronk/models/integrity', description: 'List local artifact digests and persisted verification evidence without hashing model files.', auth: 'Admin' },
       { method: 'GET', path: '/v1/kronk/models/integrity/{model}', description: 'Return integrity information for one model without inspecting other models.', auth: 'Admin' },
       { method: 'GET', path: '/v1/kronk/models/{model}', description: 'Show metadata and effective configuration for one model.', auth: 'Admin' },
+      { method: 'GET', path: '/v1/kronk/models/adapters/{model}', description: 'List the LoRA adapters loaded against a loaded model.', auth: 'Admin' },
+      { method: 'POST', path: '/v1/kronk/models/adapters/{model}', description: 'Load a LoRA adapter by id or absolute path, loading the model if needed.', auth: 'Admin' },
+      { method: 'DELETE', path: '/v1/kronk/models/adapters/{model}/{id}', description: 'Unload an adapter that was loaded at runtime.', auth: 'Admin' },
       { method: 'GET', path: '/v1/kronk/models/ps', description: 'List models currently loaded in the pool.', auth: 'Admin' },
       { method: 'GET', path: '/v1/kronk/models/imc-sessions', description: 'List active incremental-message-cache sessions.', auth: 'Admin' },
+      { method: 'POST', path: '/v1/kronk/models/imc-sessions/export', description: 'Download one IMC session snapshot and its metadata as a file.', auth: 'Admin' },
+      { method: 'POST', path: '/v1/kronk/models/imc-sessions/import', description: 'Restore an exported IMC session file into a loaded model.', auth: 'Admin' },
       { method: 'POST', path: '/v1/kronk/models/index', description: 'Rebuild the local model index.', auth: 'Admin' },
       { method: 'POST', path: '/v1/kronk/models/pull', description: 'Download a model and optional companion files and stream progress.', auth: 'Admin' },
       { method: 'POST', path: '/v1/kronk/models/autotune', description: 'Resolve an automatically tuned runtime configuration.', auth: 'Admin' },
@@ -224,10 +276,14 @@ const endpointGroups: EndpointGroup[] = [
     title: 'Security',
     description: 'Create tokens and manage authentication signing keys.',
     endpoints: [
-      { method: 'POST', path: '/v1/security/token/create', description: 'Create a token with grants, quotas, and optional administrator status.', auth: 'Admin' },
+      { method: 'POST', path: '/v1/security/token/create', description: 'Create a token with grants, quotas, optional token budgets, optional model patterns, an optional scheduling priority, and optional administrator status.', auth: 'Admin' },
+      { method: 'POST', path: '/v1/security/token/revoke', description: 'Revoke a single token by its ID (jti claim) or by the token itself.', auth: 'Admin' },
+      { method: 'POST', path: '/v1/security/token/introspect', description: 'Report the claims, expiry, revocation, and remaining rate limits and token budgets.', auth: 'Admin' },
+      { method: 'GET', path: '/v1/security/tokens', description: 'List issued and revoked tokens that have not expired.', auth: 'Admin' },
       { method: 'GET', path: '/v1/security/keys', description: 'List signing keys.', auth: 'Admin' },
       { method: 'POST', path: '/v1/security/keys/add', description: 'Create a signing key.', auth: 'Admin' },
       { method: 'POST', path: '/v1/security/keys/remove/{keyid}', description: 'Remove a non-master signing key and revoke its tokens.', auth: 'Admin' },
+      { method: 'GET', path: '/v1/security/quotas/{subject}', description: 'Show the input and output token budget use of a token subject in the current windows.', auth: 'Admin' },
     ],
   },
 ];
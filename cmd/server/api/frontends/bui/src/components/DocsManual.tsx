@@ -453,6 +453,19 @@ models:
           <pre className="code-block"><code className="language-yaml">{`unsloth/Qwen3-0.6B-Q8_0:
   context-window: 32768`}</code></pre>
           <p>A larger window increases KV-cache memory and can reduce the number of parallel sequences that fit. It also cannot create model capability that was absent during training. If the requested window exceeds the model's native context, the model may require RoPE scaling; see <a href="https://www.kronkai.com/manual#chapter-7-yarn-extended-context">Chapter 7</a>.</p>
+          <h4 id="context-overflow">Context overflow</h4>
+          <p>By default, a text chat request whose prompt does not fit the context window is rejected, and generation that reaches the window ends with an error. Long-running agent conversations hit both limits. Two settings change that:</p>
+          <pre className="code-block"><code className="language-yaml">{`unsloth/Qwen3-0.6B-Q8_0:
+  context-window: 32768
+  truncation: auto # disabled, auto, or middle-out
+  context-shift: true`}</code></pre>
+          <p><code>truncation</code> is the default for requests that do not set their own <code>truncation</code> parameter. It applies when the prompt leaves less room for output than the smaller of the request's output limit and a quarter of the context window:</p>
+          <ul>
+            <li><code>auto</code> drops the oldest turns until the prompt fits. System and developer messages, the last user message, and the most recent message are always kept. Turns before the last user message are dropped whole, so the conversation still starts with a user turn. After it, an assistant tool call is dropped together with its tool results.</li>
+            <li><code>middle-out</code> first shortens long tool results by replacing their middle with an omission note, keeping at least 256 characters of each. If the prompt still does not fit, it then drops turns like <code>auto</code>.</li>
+          </ul>
+          <p>Each candidate is measured by rendering and tokenizing the shortened conversation, and a binary search keeps the number of renders logarithmic. Truncation changes the conversation's start, so an Incremental Message Cache session rebuilds on each truncated request. When even the most truncated conversation does not fit, the request is rejected as before. Media requests are not truncated.</p>
+          <p><code>context-shift</code> lets a generation that reaches the context window continue. Kronk keeps the first prompt tokens, up to a quarter of the window, discards the older half of the tokens after them, and moves the rest back. The discarded tokens no longer influence the output. With context shift enabled, <code>max_tokens</code> is not reduced to the room left after the prompt. It applies to text requests only, and Kronk leaves it off for models whose KV memory cannot shift positions, such as recurrent and hybrid models, and for models running with a draft model or MTP head.</p>
           <h4 id="kv-cache-types">KV cache types</h4>
           <p>The KV cache stores attention state for tokens already processed. Configure the key and value caches independently:</p>
           <pre className="code-block"><code className="language-yaml">{`unsloth/Qwen3-0.6B-Q8_0:
@@ -694,10 +707,27 @@ models:
   adapters:
     - path: /opt/adapters/support.gguf
       scale: 1.0`}</code></pre>
-          <p>Each entry must set exactly one of <code>id</code> or <code>path</code>. The file must exist, be a regular file, and have a <code>.gguf</code> extension. The optional <code>scale</code> must be a finite, non-negative number and defaults to <code>1.0</code>; an explicit <code>0</code> disables that adapter's contribution while keeping the configured set unchanged. Multiple adapters compose additively using their configured scales.</p>
-          <p>Adapter files and scales are fixed for the lifetime of the loaded model. After changing them, restart the server or otherwise unload and reload that model. Kronk does not download adapters, resolve them through the model catalog, or accept per-request adapter changes.</p>
+          <p>Each entry must set exactly one of <code>id</code> or <code>path</code>. The file must exist, be a regular file, and have a <code>.gguf</code> extension. The optional <code>scale</code> must be a finite, non-negative number and defaults to <code>1.0</code>. Multiple adapters compose additively.</p>
+          <p>The configured scales apply to requests that do not choose adapters. An explicit <code>0</code> loads the adapter without applying it by default, so only requests that select it use it. Kronk does not download adapters or resolve them through the model catalog.</p>
+          <p><strong>Selecting adapters per request.</strong> A chat request chooses adapters by ID with the <code>adapters</code> parameter (<a href="https://www.kronkai.com/manual#chapter-10-request-parameters">Chapter 10</a>):</p>
+          <pre className="code-block"><code className="language-json">{`{
+  "model": "Qwen3-8B-Q8_0",
+  "messages": [{ "role": "user", "content": "List overdue invoices." }],
+  "adapters": [{ "id": "acme/support", "scale": 0.8 }]
+}`}</code></pre>
+          <p>An adapter configured by <code>id</code> is selected by that ID. An adapter configured by <code>path</code> is selected by its file name without the extension, so <code>/opt/adapters/support.gguf</code> is selected as <code>support</code>. Omitting <code>adapters</code> applies the configured scales, and an empty list runs the base model alone.</p>
+          <p>llama.cpp applies adapters to a whole context rather than to each sequence, so every slot of a model generates under the same adapter set. Requests that choose the set in use are batched together as usual. A request that chooses a different set waits until the running generations finish, and the model then switches to its set. While it waits, requests for the set in use still start, but no more than one per slot, so the switch is not put off indefinitely. Mixing many adapter sets on one model therefore trades throughput for flexibility. Group traffic by adapter set where you can. Cached conversations (IMC sessions) are only reused by requests that run the same adapter set. The system prompt cache only serves requests that use the configured adapters.</p>
+          <p><strong>Loading adapters at runtime.</strong> Adapters can also be registered against a loaded base model without editing the configuration. Loading and unloading require the <code>admin</code> grant:</p>
+          <pre className="code-block"><code className="language-shell">{`curl http://localhost:11435/v1/kronk/models/adapters/Qwen3-8B-Q8_0 \\
+  -d '{"id": "acme/sql"}'
+
+curl http://localhost:11435/v1/kronk/models/adapters/Qwen3-8B-Q8_0
+
+curl -X DELETE http://localhost:11435/v1/kronk/models/adapters/Qwen3-8B-Q8_0/acme/sql`}</code></pre>
+          <p>The load body sets exactly one of <code>id</code> or <code>path</code>, resolved with the same rules as the configuration. Loading loads the base model if needed. Runtime adapters are never applied by default; requests must select them. They last only as long as the loaded model, so they are gone after the model is unloaded or evicted. Configured adapters cannot be unloaded. After an unload, queued requests that selected the adapter fail, while requests already generating with it finish first. The adapter's memory is released once no generation uses it. Runtime loading is available for text generation models only. Embedding and reranking contexts always use the configured adapters.</p>
+          <p>Changing configured adapter files or scales still requires reloading the model.</p>
           <h4 id="speculative-decoding-and-mtp">Speculative decoding and MTP</h4>
-          <p>Kronk supports a separate draft GGUF and Multi-Token Prediction (MTP). MTP may be embedded in the target GGUF or supplied as a model-specific companion file that Kronk's catalog and download flow associates with the target. A separate classic draft must already be downloaded, must have a compatible vocabulary, and requires <code>nseq-max: 1</code>:</p>
+          <p>Kronk supports a separate draft GGUF, Multi-Token Prediction (MTP), and n-gram prompt lookup, which needs no draft model. MTP may be embedded in the target GGUF or supplied as a model-specific companion file that Kronk's catalog and download flow associates with the target. A separate classic draft must already be downloaded, must have a compatible vocabulary, and requires <code>nseq-max: 1</code>:</p>
           <pre className="code-block"><code className="language-yaml">{`some-provider/target-model:
   nseq-max: 1
   draft-model:
@@ -709,8 +739,8 @@ models:
     ndraft: 6`}</code></pre>
           <p>Use <code>speculation</code> to select the implementation for a model:</p>
           <pre className="code-block"><code className="language-yaml">{`some-provider/mtp-target-model:
-  speculation: disabled # auto, disabled, classic, or mtp`}</code></pre>
-          <p><code>auto</code> preserves automatic selection. <code>disabled</code> runs target-only and does not load draft resources. <code>classic</code> requires <code>draft-model.model-id</code>; <code>mtp</code> requires a compatible embedded head or companion assistant.</p>
+  speculation: disabled # auto, disabled, classic, mtp, or ngram`}</code></pre>
+          <p><code>auto</code> preserves automatic selection. <code>disabled</code> runs target-only and does not load draft resources. <code>classic</code> requires <code>draft-model.model-id</code>; <code>mtp</code> requires a compatible embedded head or companion assistant. <code>ngram</code> drafts by copying continuations from the prompt and earlier output, works with any <code>nseq-max</code>, and cannot be combined with <code>draft-model.model-id</code>; an <code>ndraft</code>-only <code>draft-model</code> block sets its candidate ceiling.</p>
           <p>Do not use model names or benchmark results as universal draft-selection rules. Measure acceptance and throughput on the actual workload. See <a href="https://www.kronkai.com/manual#chapter-6-speculative-decoding-and-mtp">Chapter 6</a> for drafter selection, adaptive throttling, observability, and limitations.</p>
           <h4 id="extended-context-with-yarn">Extended context with YaRN</h4>
           <p>Do not add RoPE scaling merely because a large <code>context-window</code> fits in memory. Scaling must match the model and its native training context. Configuration uses <code>rope-scaling-type</code> and the <code>yarn-*</code> keys described in <a href="https://www.kronkai.com/manual#chapter-7-yarn-extended-context">Chapter 7</a>.</p>
@@ -866,10 +896,20 @@ models:
                 <td>Boolean</td>
                 <td>Incremental Message Cache</td>
               </tr>
+              <tr>
+                <td><code>session-store-kind</code></td>
+                <td><code>ram</code>, <code>disk</code></td>
+                <td>Where idle IMC snapshots are kept</td>
+              </tr>
+              <tr>
+                <td><code>session-store-dir</code>, <code>session-store-max-ram-mb</code>, <code>session-store-max-disk-mb</code></td>
+                <td>Path, MiB, MiB</td>
+                <td>Directory and budgets for the <code>disk</code> session store</td>
+              </tr>
               <tr>
                 <td><code>adapters</code></td>
                 <td>List of <code>id</code> or absolute <code>path</code>, plus optional <code>scale</code></td>
-                <td>Fixed load-time LoRA adapters</td>
+                <td>LoRA adapters loaded with the model; scales apply to requests that select no adapters</td>
               </tr>
               <tr>
                 <td><code>draft-model</code></td>
@@ -878,9 +918,19 @@ models:
               </tr>
               <tr>
                 <td><code>speculation</code></td>
-                <td><code>auto</code>, <code>disabled</code>, <code>classic</code>, <code>mtp</code></td>
+                <td><code>auto</code>, <code>disabled</code>, <code>classic</code>, <code>mtp</code>, <code>ngram</code></td>
                 <td>Select speculative-decoding implementation</td>
               </tr>
+              <tr>
+                <td><code>truncation</code></td>
+                <td><code>disabled</code>, <code>auto</code>, <code>middle-out</code></td>
+                <td>Default handling of text prompts that do not fit the context window</td>
+              </tr>
+              <tr>
+                <td><code>context-shift</code></td>
+                <td>Boolean</td>
+                <td>Continue generation past the context window by discarding older tokens</td>
+              </tr>
               <tr>
                 <td><code>rope-scaling-type</code></td>
                 <td>Supported scaling mode</td>
@@ -992,6 +1042,47 @@ models:
           <p>Internally, the batch engine receives admitted jobs through a bounded handoff channel and drains them into its pending-job list until slots become available. The channel is not a second user-visible queue budget. The direct Go SDK option <code>model.WithQueueDepth(n)</code> changes both the outer admission multiplier and the handoff channel capacity. The handoff capacity is <code>NSeqMax × QueueDepth</code>; <code>pendingJobs</code> remains responsible for jobs drained while every slot is busy.</p>
           <p>Waiting honors request cancellation. If a request's context is cancelled while waiting for admission, preparing, submitting, waiting for a slot, or generating, the request returns that cancellation. During model shutdown, the engine rejects new submissions and finishes active and pending jobs with a shutdown error.</p>
           <p>The engine does <strong>not</strong> cancel a long-running request merely because another job has waited for a slot. The visible queue wait begins around engine submission and ends when the first inactive slot is assigned. It remains bounded by the route deadline and caller cancellation.</p>
+          <h4 id="461-priorities-and-tenant-fairness">4.6.1 Priorities and Tenant Fairness</h4>
+          <p>When a slot frees up and several jobs are pending, the engine does not simply take the oldest one. Every job carries a priority (<code>high</code>, <code>normal</code>, or <code>low</code>) and a tenant. All pending <code>high</code> jobs are assigned before any <code>normal</code> job, and all <code>normal</code> jobs before any <code>low</code> job. Within a priority, tenants share slots by weighted fair queuing: each tenant's next job is ordered by the prompt tokens that tenant has already been given, so one tenant sending a burst of long agent prompts cannot push everyone else to the back of the queue. A tenant's own jobs keep their arrival order, and a tenant that has been idle starts level with the busiest tenant rather than with saved-up credit.</p>
+          <p>A steady stream of <code>high</code> traffic can hold <code>low</code> jobs until their route deadline expires, so keep the <code>high</code> class for traffic that is actually latency sensitive.</p>
+          <p>On the model server, the chat completions, responses, and messages endpoints resolve the schedule as follows:</p>
+          <table className="flags-table">
+            <thead>
+              <tr>
+                <th>Input</th>
+                <th>Effect</th>
+              </tr>
+            </thead>
+            <tbody>
+              <tr>
+                <td><code>scheduling.priorities</code> in the KMS config</td>
+                <td>Default priority for each endpoint, such as <code>chat-completions: high</code> or <code>responses: low</code>. Unset endpoints use <code>normal</code>.</td>
+              </tr>
+              <tr>
+                <td>Token <code>priority</code> claim</td>
+                <td>Replaces the endpoint default for requests made with that token. Set it with <code>kronk security token create --priority</code>.</td>
+              </tr>
+              <tr>
+                <td><code>X-Kronk-Priority</code> header</td>
+                <td>Lowers the request's priority. A request cannot raise itself above the endpoint default or token claim.</td>
+              </tr>
+              <tr>
+                <td>Token subject</td>
+                <td>Tenant of an authenticated request.</td>
+              </tr>
+              <tr>
+                <td><code>X-Kronk-Tenant</code> header</td>
+                <td>Tenant of a request when authentication is not required. Ignored for authenticated requests.</td>
+              </tr>
+            </tbody>
+          </table>
+          <p>Requests without a tenant share a single anonymous tenant. The same defaults can be set through the environment:</p>
+          <pre className="code-block"><code className="language-shell">{`export KRONK_SCHEDULING_PRIORITIES="chat-completions:high;responses:low"`}</code></pre>
+          <p>Go SDK callers set the schedule directly on the request context with <code>model.SetSchedule(ctx, model.Schedule&#123;Priority: model.PriorityHigh, Tenant: "team-a"&#125;)</code>.</p>
+          <h4 id="462-preemption">4.6.2 Preemption</h4>
+          <p>When every slot is busy and a job is pending with a higher priority than one of the running generations, the engine pauses that generation to free its slot. The paused request's KV sequence is copied into a session store, using the same <code>SessionStoreFactory</code> as IMC sessions (so a disk session store spills paused generations to disk too), and the sequence is cleared for the urgent job. The paused request keeps its sampler, parser state, and partial output, and its stream stays open; the client just sees a gap between tokens.</p>
+          <p>A paused generation resumes in the next free slot, ahead of pending jobs of the same or lower priority. Resumption restores the sequence into whichever slot is free and continues from the token it was about to decode, so the output is the same as if it had never been paused. Cancelling a paused request ends it without restoring.</p>
+          <p>The engine pauses the lowest-priority generation first and, among equal priorities, the one with the shortest sequence, since it is the cheapest to copy. Jobs only preempt generations of strictly lower priority, so equal priorities never displace one another. Slots still restoring a cache or prefilling a prompt are never paused, and neither are models that run speculative decoding, whose draft state is not captured by the sequence snapshot.</p>
           <h3 id="47-stage-4-—-execute-in-the-slot">4.7 Stage 4 — Execute in the Slot</h3>
           <h4 id="471-bind-and-restore">4.7.1 Bind and Restore</h4>
           <p>When the scheduler assigns a slot, Kronk binds any reserved IMC session to that slot's fixed llama sequence ID. A compatible saved prefix is restored from the session store; otherwise the sequence starts from an empty state. The session identity is not permanently attached to the slot.</p>
@@ -1079,8 +1170,9 @@ make benchmark-rerank-batchseq`}</code></pre>
           <p>Kronk records two direct indicators of generation-slot contention:</p>
           <ul>
             <li>the <code>queue-wait</code> trace span, which wraps the submit attempt and subsequent slot wait for successful jobs; and</li>
-            <li>the <code>chat_queue_wait_seconds</code> Prometheus histogram, recorded when a slot is assigned.</li>
+            <li>the <code>chat_queue_wait_seconds</code> Prometheus histogram, recorded when a slot is assigned and labeled by <code>priority</code>.</li>
           </ul>
+          <p>The <code>chat_queue_depth</code> gauge reports the number of pending and paused jobs waiting for a slot in each priority class, and <code>chat_preemptions_total</code> counts generations paused for a higher priority job, labeled by the paused job's priority. A growing <code>low</code> depth while <code>high</code> stays at zero is the expected shape under load; a growing <code>high</code> depth means the latency-sensitive class itself is oversubscribed.</p>
           <p>For a successful job, timing starts immediately before attempting submission to the batch engine and ends at slot assignment. It does not include time blocked at the outer SDK admission gate or time spent preparing an IMC session before the submit attempt. Compare it with end-to-end request duration and time-to-first-token measurements when diagnosing latency.</p>
           <p>Embedding and reranking expose <code>inference_requests_total</code>, <code>inference_request_duration_seconds</code>, and <code>inference_active_requests</code>, labeled by operation and runtime (<code>batchseq</code> or <code>context_pool</code>). Sequence batching also publishes <code>batchseq_queue_wait_seconds</code>, <code>batchseq_items</code>, and <code>batchseq_batches_total</code>. These distinguish outer request concurrency from the number and width of native batches actually evaluated.</p>
           <p>The <code>inference_*</code> metrics begin after the outer SDK admission permit is acquired. They describe admitted model-layer work and do not count admission wait time or requests that time out or are cancelled before admission.</p>
@@ -1267,6 +1359,21 @@ Next stable rendering retroactively changes an assistant turn:
           <p>For text requests, Kronk can retain an independently rendered and verified system-only preload, including matching draft/MTP state when available. These preloads live in a model-owned RAM pool whose capacity equals the working session count. Unlike working snapshots, System entries do not use the configured session store; persisted working snapshots already contain the System prefix.</p>
           <p>An exact match may skip rewriting the snapshot when the stable state has not changed. This avoids an unnecessary serialization of the state that was just restored. Exact media-plan reuse can receive the same optimization. These are implementation optimizations; they do not change which content is considered part of the cache.</p>
           <p>Snapshots externalize inactive session state from the model's active KV cache. They therefore do not permanently occupy an execution slot or pin their state in accelerator KV memory between requests. They do consume the configured session-store capacity, as described in <a href="#55-configuration-and-storage">Configuration and Storage</a>.</p>
+          <h3 id="531-cache-keys-and-session-export">5.3.1 Cache Keys and Session Export</h3>
+          <p>By default, Kronk picks a session by matching the rendered prompt against every cached prefix. A request can instead carry a cache key that pins it to a named session. Set the OpenAI <code>prompt_cache_key</code> field on a chat completion or Responses request, or send an <code>X-Kronk-Session</code> header with any chat, Responses, or Anthropic Messages request. A key in the request body takes precedence over the header. Keys are limited to 256 bytes.</p>
+          <pre className="code-block"><code className="language-shell">{`curl http://localhost:11435/v1/chat/completions \\
+  -H "X-Kronk-Session: user-42" \\
+  -d '{"model": "Qwen3-8B-Q8_0", "messages": [...]}'`}</code></pre>
+          <p>A keyed request only reuses sessions committed under the same key, and an unkeyed request only reuses unkeyed sessions. This keeps one tenant's cached prefix from serving another tenant's request even when their prompts share a prefix. When a keyed request must rebuild, Kronk reuses the idle session that already holds that key before taking an empty or least recently used session, so a key normally occupies one session. Keyed sessions still take part in LRU eviction, and <code>GET /v1/kronk/models/imc-sessions</code> reports each session's key as <code>cache_key</code>.</p>
+          <p>A text session can be exported to a file and imported again on another server or after a restart. Both routes require the <code>admin</code> grant:</p>
+          <pre className="code-block"><code className="language-shell">{`curl http://localhost:11435/v1/kronk/models/imc-sessions/export \\
+  -d '{"model": "Qwen3-8B-Q8_0", "cache_key": "user-42"}' \\
+  -o user-42.kimc
+
+curl "http://localhost:11435/v1/kronk/models/imc-sessions/import?model=Qwen3-8B-Q8_0" \\
+  --data-binary @user-42.kimc`}</code></pre>
+          <p>Select the session with either <code>session_id</code> or <code>cache_key</code>; a key exports the most recently used session committed under it. The file holds the session's target and draft <code>SessionStore</code> bytes plus the cached tokens, message hashes, and cache key. Export reserves the session while it is written, so requests that would reuse it rebuild elsewhere until the export finishes. Sessions that cache media, sessions being used by a request, and empty sessions cannot be exported.</p>
+          <p>Import restores into the idle session holding the same key, or else an empty or least recently used session. It is rejected unless the model ID, model file size, and <code>cache-type-k</code>/<code>cache-type-v</code> match the exporting server and the cached tokens fit the context window. A draft snapshot is discarded when the importing model has no draft or MTP state. The native state format belongs to llama.cpp, so import files only into the same Kronk and llama.cpp versions that produced them.</p>
           <h2 id="54-media-requests">5.4 Media Requests</h2>
           <p>IMC supports media processed by Kronk's multimodal pipeline. Instead of relying only on text-token equality, Kronk builds a logical plan containing the ordered text and media inputs.</p>
           <p>Kronk can reuse a media session in two cases:</p>
@@ -1312,7 +1419,22 @@ Next stable rendering retroactively changes an assistant turn:
               <tr>
                 <td><code>session-store-kind</code></td>
                 <td><code>ram</code></td>
-                <td>Selects the session-store plugin. Currently, only <code>ram</code> is built in.</td>
+                <td>Selects the session-store plugin: <code>ram</code> or <code>disk</code>.</td>
+              </tr>
+              <tr>
+                <td><code>session-store-dir</code></td>
+                <td><code>sessions/&lt;model-id&gt;</code></td>
+                <td>Spill directory for <code>disk</code>; relative paths resolve under <code>~/.kronk</code>.</td>
+              </tr>
+              <tr>
+                <td><code>session-store-max-ram-mb</code></td>
+                <td><code>0</code></td>
+                <td>Snapshot MiB the <code>disk</code> store keeps in RAM before spilling.</td>
+              </tr>
+              <tr>
+                <td><code>session-store-max-disk-mb</code></td>
+                <td><code>0</code> (no cap)</td>
+                <td>Snapshot MiB the <code>disk</code> store may park on disk.</td>
               </tr>
             </tbody>
           </table>
@@ -1321,6 +1443,17 @@ Next stable rendering retroactively changes an assistant turn:
           <p>Set <code>incremental-cache: false</code> if a workload is entirely short-lived or if you need to compare behavior without prompt caching.</p>
           <h3 id="built-in-ram-storage">Built-in RAM storage</h3>
           <p>The built-in <code>ram</code> store keeps snapshots in process memory. It is selected by default when <code>session-store-kind</code> is omitted. Each session buffer grows as needed and retains its peak allocation for reuse. Actual memory use depends on the model, cached conversation lengths, KV data types, and number of sessions that have been used. Budget for peak conversation state across the branches you expect to keep warm, not just the <code>nseq-max</code> requests that can run simultaneously.</p>
+          <h3 id="built-in-disk-storage">Built-in disk storage</h3>
+          <p>The <code>disk</code> store keeps each snapshot in RAM exactly like the <code>ram</code> store until the model's snapshots exceed <code>session-store-max-ram-mb</code>. Kronk then writes the least recently used idle snapshots to files under <code>session-store-dir</code> and releases their memory. A spilled snapshot is read back the next time its session is restored, so a returning conversation keeps its cache instead of being rebuilt. A snapshot is never spilled while Kronk is capturing it.</p>
+          <p>Use it with a large <code>imc-session-capacity</code> to park thousands of long-lived agent sessions on local NVMe instead of dropping the least recently used ones when the session pool is exhausted:</p>
+          <pre className="code-block"><code className="language-yaml">{`unsloth/Qwen3-1.7B-UD-Q8_K_XL:
+  imc-session-capacity: 4096
+  session-store-kind: disk
+  session-store-dir: /nvme/kronk/sessions
+  session-store-max-ram-mb: 8192
+  session-store-max-disk-mb: 262144`}</code></pre>
+          <p>With the default <code>session-store-max-ram-mb: 0</code>, every snapshot except the most recently used one lives on disk, which costs a file write and read per turn. When a spill would exceed <code>session-store-max-disk-mb</code>, Kronk drops the oldest parked snapshots first; a dropped session is rebuilt on its next request, just as an evicted session is with the <code>ram</code> store.</p>
+          <p>Spill files are opened only while they are read or written, so the number of parked sessions is not limited by file descriptors. They are temporary process-owned snapshots named <code>kronk-sess-*.kv</code>, not durable conversations: Kronk removes them when the model unloads, and files left behind by a crashed process can be deleted while no Kronk server uses the directory. Direct SDK users can select the same store with <code>disk.NewFactory</code> from <code>sdk/kronk/kvstorage/disk</code> and <code>model.WithSessionStoreFactory</code>.</p>
           <h3 id="custom-sdk-storage">Custom SDK storage</h3>
           <p>Direct SDK users can implement <code>kvstorage.Store</code>, construct a factory that captures the implementation's own dependencies and configuration, and inject that factory into the model:</p>
           <pre className="code-block"><code className="language-go">{`factory := func() (kvstorage.Store, error) {
@@ -1333,7 +1466,7 @@ krn, err := kronk.New(
 	model.WithSessionStoreFactory(factory),
 )`}</code></pre>
           <p>Kronk calls the factory independently for every Current, draft, and System store it needs. Each call must return a new store; Kronk owns that store and calls <code>Close</code> when it is no longer needed. Direct SDK use defaults to RAM when no factory is injected.</p>
-          <p>The <a href="https://github.com/ardanlabs/kronk/tree/main/examples/session-store"><code>examples/session-store</code></a> program provides a complete custom implementation and shows how to inject it. Its implementation writes snapshots to anonymous temporary files and deletes them on <code>Close</code>. It exists only to demonstrate the extension contract: it has no stable session identity, persisted request history, startup recovery, atomic commits, or reliable way to report I/O failures. <strong>Do not use the example as durable session storage.</strong> A durable implementation needs a higher level persistence design in addition to the byte-store contract.</p>
+          <p>The <a href="https://github.com/ardanlabs/kronk/tree/main/examples/session-store"><code>examples/session-store</code></a> program provides a complete custom implementation and shows how to inject it. Use the built-in <code>disk</code> store rather than the example to park sessions on disk. Its implementation writes snapshots to anonymous temporary files and deletes them on <code>Close</code>. It exists only to demonstrate the extension contract: it has no stable session identity, persisted request history, startup recovery, atomic commits, or reliable way to report I/O failures. <strong>Do not use the example as durable session storage.</strong> A durable implementation needs a higher level persistence design in addition to the byte-store contract.</p>
           <p>Some MTP configurations maintain draft-model cached state and saved hidden state in addition to the target model snapshot. Account for this extra storage when sizing memory. See <a href="https://www.kronkai.com/manual#chapter-6-speculative-decoding-and-mtp">Chapter 6</a> for MTP configuration and behavior.</p>
           <h2 id="56-invalidation-and-limitations">5.6 Invalidation and Limitations</h2>
           <p>IMC favors safe reuse over partial recovery. A session is rebuilt when Kronk cannot prove that its complete saved prefix matches the new stable prompt. Common causes include:</p>
@@ -1366,13 +1499,13 @@ krn, err := kronk.New(
           <h3 id="61-what-speculative-decoding-does">6.1 What Speculative Decoding Does</h3>
           <p>Speculative decoding uses a faster drafter to propose several continuation tokens. The target model verifies those proposals together. Accepted proposals reduce the number of target-model passes needed to produce the response; rejected proposals are discarded and the target remains authoritative.</p>
           <p>This optimization does not change the chat, Responses, or SDK request shapes. It can improve generation throughput when the drafter is inexpensive and its proposals agree frequently with the target. It can also reduce performance when draft work is expensive or acceptance is poor. Always measure with the model, sampling settings, hardware, and prompts used in production.</p>
-          <p>Kronk supports classic speculative decoding with a separate draft model and Multi-Token Prediction (MTP). MTP uses a prediction head designed for the target rather than a general-purpose smaller language model.</p>
+          <p>Kronk supports classic speculative decoding with a separate draft model, Multi-Token Prediction (MTP), and n-gram prompt lookup. MTP uses a prediction head designed for the target rather than a general-purpose smaller language model. N-gram prompt lookup uses no model at all: it copies continuations from tokens the request has already seen.</p>
           <p>This is a specialized Stage 4 generation loop. The drafter proposes multiple candidates, but the target model verifies them and remains authoritative. At a divergence Kronk accepts only the verified prefix and chooses a target replacement token; when all candidates are accepted it chooses a target bonus token. That final target token becomes input to the next decode and is not yet committed to KV state when selected.</p>
           <p><img src="https://raw.githubusercontent.com/ardanlabs/kronk/main/.manual/images/chapter-06/speculative-decoding.svg" alt="Speculative decoding proposal, target verification, acceptance, and state synchronization" /></p>
-          <p>Classic speculative sampling uses the target and draft distributions to decide acceptance and replacement. Kronk's MTP path uses exact token-match verification against the target sampler. Both paths keep the target authoritative and emit an accepted prefix of zero to <code>ndraft</code> candidates followed by a target-derived replacement or bonus token.</p>
+          <p>Classic speculative sampling uses the target and draft distributions to decide acceptance and replacement. N-gram prompt lookup uses the same classic verification, treating each copied candidate as a certain draft. Kronk's MTP path uses exact token-match verification against the target sampler. Every path keeps the target authoritative and emits an accepted prefix of zero to <code>ndraft</code> candidates followed by a target-derived replacement or bonus token.</p>
           <p>When a request supplies <code>seed</code>, Kronk derives request-local random streams for the target sampler, classic draft sampler, and speculative acceptance and replacement decisions. This prevents concurrent requests from consuming one another's random stream. See <a href="https://www.kronkai.com/manual#chapter-10-request-parameters">Chapter 10</a> for the repeatability contract and its environment constraints.</p>
           <h3 id="62-drafter-sources-and-selection">6.2 Drafter Sources and Selection</h3>
-          <p>Kronk can load a drafter from three sources:</p>
+          <p>Kronk can load a drafter from three sources, or draft without one:</p>
           <table className="flags-table">
             <thead>
               <tr>
@@ -1397,11 +1530,16 @@ krn, err := kronk.New(
                 <td>The target GGUF contains supported <code>nextn_predict_layers</code> metadata, currently used by Qwen3.5, Qwen3.6, and Qwen3.8 models.</td>
                 <td>Supports multiple slots</td>
               </tr>
+              <tr>
+                <td><strong>N-gram prompt lookup</strong></td>
+                <td><code>speculation: ngram</code> in the model configuration. Nothing is loaded.</td>
+                <td>Supports multiple slots</td>
+              </tr>
             </tbody>
           </table>
-          <p>Kronk checks these sources in that order. A <code>draft-model</code> block containing a <code>model-id</code> explicitly selects the classic separate draft and takes precedence over either MTP form. Without one, Kronk uses a compatible companion MTP file when present, then checks the target for an embedded MTP head. If no source is available, the model runs normally without speculation.</p>
+          <p>Kronk checks these sources in that order. A <code>draft-model</code> block containing a <code>model-id</code> explicitly selects the classic separate draft and takes precedence over either MTP form. Without one, Kronk uses a compatible companion MTP file when present, then checks the target for an embedded MTP head. If no source is available, the model runs normally without speculation. N-gram prompt lookup is never selected automatically; it runs only when configured.</p>
           <p>A <code>draft-model</code> block containing only <code>ndraft</code> is different: it changes the MTP draft ceiling and does not select a classic draft or disable MTP.</p>
-          <p>The model-level <code>speculation</code> setting selects the implementation. <code>auto</code> keeps the normal priority order, <code>disabled</code> runs target-only, <code>classic</code> requires a separate draft model, and <code>mtp</code> requires a compatible companion or embedded head. Explicit selection makes it possible to benchmark the same target with MTP enabled and disabled without changing downloaded model files.</p>
+          <p>The model-level <code>speculation</code> setting selects the implementation. <code>auto</code> keeps the normal priority order, <code>disabled</code> runs target-only, <code>classic</code> requires a separate draft model, <code>mtp</code> requires a compatible companion or embedded head, and <code>ngram</code> selects prompt lookup. Explicit selection makes it possible to benchmark the same target with MTP enabled and disabled without changing downloaded model files.</p>
           <p>Embedded detection happens before llama.cpp loads the target. Kronk reads the first GGUF shard, where model metadata is stored, and enables MTP tensor loading when any positive <code>nextn_predict_layers</code> metadata value is present. The lookup uses the metadata suffix rather than a hard-coded architecture name, so a supported future architecture can advertise the same contract. This early step is required because llama.cpp otherwise omits gated MTP tensors during model load; adding an <code>ndraft</code> setting after load cannot recover them.</p>
           <p>MTP also requires support from the loaded llama.cpp library. When a model advertises MTP but the required API is unavailable, Kronk reports that MTP was disabled at model load and serves the model without speculation.</p>
           <h3 id="63-choosing-a-drafter">6.3 Choosing a Drafter</h3>
@@ -1420,14 +1558,19 @@ krn, err := kronk.New(
           <p>MTP is normally the simpler choice when the downloaded model provides a supported embedded or companion head. It is architecture-matched to its target, supports multiple execution slots, and does not require a <code>model-id</code> in the <code>draft-model</code> configuration.</p>
           <p>An embedded head requires no companion file. A companion MTP assistant is an additional model-specific file, but Kronk's catalog and download flow can discover and associate it with the target automatically. It is not configured as a classic <code>draft-model</code>.</p>
           <p>MTP availability is a property of the downloaded files and the loaded llama.cpp library. Naming a model “MTP” or adding an <code>ndraft</code> override cannot create an MTP head that is not present.</p>
+          <h4 id="633-n-gram-prompt-lookup">6.3.3 N-gram prompt lookup</h4>
+          <p>N-gram prompt lookup suits workloads whose output repeats long runs of the prompt or of earlier output: code editing that rewrites most of a file, retrieval-augmented answers that quote their sources, and structured output with repeated keys. It works with any model, loads no extra weights or KV cache, and supports multiple execution slots.</p>
+          <p>Each round, Kronk takes the last four tokens of the request's history, which is the logical prompt followed by everything generated so far, and looks for their most recent earlier occurrence. Without a match it tries the last three tokens, then the last two. The tokens that followed the match become the candidates. A round with no match decodes one token as usual, so a request that never repeats itself pays only the lookup.</p>
+          <p>Lookups cost almost nothing, but every candidate occupies a target batch row. On free-form writing, where copies rarely match, adaptive throttling quickly stops proposing them. Measure before enabling it for general chat traffic.</p>
           <h3 id="64-draft-size-and-adaptive-throttling">6.4 Draft Size and Adaptive Throttling</h3>
           <p><code>ndraft</code> is the maximum number of candidates the drafter attempts in one round. Larger values can save more target passes when acceptance remains high, but they also increase wasted draft and verification work when proposals are rejected.</p>
           <p>Defaults are:</p>
           <ul>
             <li><strong>Classic separate draft:</strong> 5</li>
             <li><strong>MTP:</strong> 3</li>
+            <li><strong>N-gram prompt lookup:</strong> 8</li>
           </ul>
-          <p>MTP uses the configured draft count on every round, matching llama.cpp. Kronk adaptively reduces the count for classic separate drafts. It tracks an exponential moving average (EMA) of recent acceptance and chooses the next round's size from the configured ceiling:</p>
+          <p>MTP uses the configured draft count on every round, matching llama.cpp. Kronk adaptively reduces the count for classic separate drafts and n-gram prompt lookup. It tracks an exponential moving average (EMA) of recent acceptance and chooses the next round's size from the configured ceiling:</p>
           <table className="flags-table">
             <thead>
               <tr>
@@ -1477,6 +1620,13 @@ krn, err := kronk.New(
   draft-model:
     ndraft: 6`}</code></pre>
           <p>This form supports multiple slots. A value of 0 or an omitted value uses the MTP default of 3; a negative value is rejected. If neither a compatible companion nor an embedded MTP head is available, the override has no effect.</p>
+          <h4 id="654-n-gram-prompt-lookup">6.5.4 N-gram prompt lookup</h4>
+          <pre className="code-block"><code className="language-yaml">{`some-provider/target-model:
+  nseq-max: 4
+  speculation: ngram
+  draft-model:
+    ndraft: 12`}</code></pre>
+          <p>The <code>draft-model</code> block is optional. With <code>speculation: ngram</code>, its <code>ndraft</code> sets the candidate ceiling instead of the MTP ceiling and defaults to 8. A <code>draft-model</code> with a <code>model-id</code> cannot be combined with <code>ngram</code> and is rejected during configuration validation.</p>
           <p>See <a href="https://www.kronkai.com/manual#chapter-3-model-configuration">Chapter 3</a> for the complete model configuration format.</p>
           <h3 id="66-measuring-the-result">6.6 Measuring the Result</h3>
           <p>Do not use acceptance rate alone to decide whether speculation helps. Review acceptance, coverage, throughput, latency, and resource use together.</p>
@@ -1522,6 +1672,8 @@ krn, err := kronk.New(
           <h3 id="67-limitations-and-fallbacks">6.7 Limitations and Fallbacks</h3>
           <ul>
             <li><strong>Classic separate drafts require one slot.</strong> Set <code>nseq-max: 1</code> on the target entry.</li>
+            <li><strong>N-gram drafts come from text tokens.</strong> Media embeddings are not part of the lookup history, so a media request drafts only from its text tokens and its own output.</li>
+            <li><strong>Preemption and prefix sharing.</strong> Models with a draft model or MTP head do not preempt lower-priority generations and do not share prompt KV between <code>n &gt; 1</code> choices. N-gram prompt lookup keeps no draft state and supports both.</li>
             <li><strong>Multi-slot MTP uses one physical batch per logical decode.</strong> Kronk caps the derived internal <code>NBatch</code> and <code>NUBatch</code> at the same value because llama.cpp exposes dense NextN hidden states in physical-batch order without a complete mapping back to logical token rows.</li>
             <li><strong>Tokenizer compatibility remains the user's responsibility.</strong> Kronk rejects unequal vocabulary sizes, but that check cannot establish identical token mappings or templates.</li>
             <li><strong>MTP at nonzero temperature is an approximation.</strong> MTP proposals are greedy, while target verification uses the request's sampler and accepts exact token matches. Sampling parameters still shape output, but this does not provide strict speculative-sampling distribution equivalence.</li>
@@ -1754,6 +1906,12 @@ kronk libs --local`}</code></pre>
                 <td>Detected bundle under <code>&lt;base&gt;/bucky-libraries</code></td>
                 <td>Exact whisper.cpp library directory</td>
               </tr>
+              <tr>
+                <td><code>--malina-lib-path</code></td>
+                <td><code>KRONK_MALINA_LIB_PATH</code></td>
+                <td>Detected bundle under <code>&lt;base&gt;/malina-libraries</code></td>
+                <td>Exact stable-diffusion.cpp library directory</td>
+              </tr>
               <tr>
                 <td><code>--model-config-file</code></td>
                 <td><code>KRONK_POOL_MODEL_CONFIG_FILE</code></td>
@@ -1778,6 +1936,78 @@ kronk libs --local`}</code></pre>
                 <td><code>0m</code></td>
                 <td>Idle model retention time; <code>0</code> disables idle expiration</td>
               </tr>
+              <tr>
+                <td><code>--response-store</code></td>
+                <td><code>KRONK_RESPONSES_STORE</code></td>
+                <td><code>memory</code></td>
+                <td>Responses API storage: <code>memory</code>, <code>disk</code>, or <code>none</code></td>
+              </tr>
+              <tr>
+                <td><code>--response-ttl</code></td>
+                <td><code>KRONK_RESPONSES_TTL</code></td>
+                <td><code>24h</code></td>
+                <td>Stored response retention time; <code>0</code> disables expiration</td>
+              </tr>
+              <tr>
+                <td><code>--files-max-bytes</code></td>
+                <td><code>KRONK_FILES_MAX_BYTES</code></td>
+                <td><code>536870912</code></td>
+                <td>Maximum size of one Files API upload; <code>0</code> removes the limit</td>
+              </tr>
+              <tr>
+                <td><code>--files-quota-bytes</code></td>
+                <td><code>KRONK_FILES_QUOTA_BYTES</code></td>
+                <td><code>10737418240</code></td>
+                <td>Maximum total size of one subject's files; <code>0</code> removes the limit</td>
+              </tr>
+              <tr>
+                <td><code>--batch-concurrency</code></td>
+                <td><code>KRONK_BATCHES_CONCURRENCY</code></td>
+                <td><code>4</code></td>
+                <td>Number of Batch API lines dispatched at the same time</td>
+              </tr>
+              <tr>
+                <td><code>--media-fetch-enabled</code></td>
+                <td><code>KRONK_MEDIA_FETCH_ENABLED</code></td>
+                <td><code>false</code></td>
+                <td>Download <code>http(s)</code> media URLs in chat, Responses, and Messages requests</td>
+              </tr>
+              <tr>
+                <td><code>--media-fetch-allowed-hosts</code></td>
+                <td><code>KRONK_MEDIA_FETCH_ALLOWED_HOSTS</code></td>
+                <td>unset</td>
+                <td>Host names, <code><em>.domain&lt;/code&gt; patterns, CIDR blocks, and &lt;code&gt;</em></code> for any public host media may be fetched from; unset allows no host, so nothing is fetched</td>
+              </tr>
+              <tr>
+                <td><code>--media-fetch-allow-private</code></td>
+                <td><code>KRONK_MEDIA_FETCH_ALLOW_PRIVATE</code></td>
+                <td><code>false</code></td>
+                <td>Allow media URLs that resolve to private, loopback, or link-local addresses</td>
+              </tr>
+              <tr>
+                <td><code>--media-fetch-max-bytes</code></td>
+                <td><code>KRONK_MEDIA_FETCH_MAX_BYTES</code></td>
+                <td><code>20971520</code></td>
+                <td>Maximum size of one fetched media file; <code>0</code> removes the limit</td>
+              </tr>
+              <tr>
+                <td><code>--media-fetch-timeout</code></td>
+                <td><code>KRONK_MEDIA_FETCH_TIMEOUT</code></td>
+                <td><code>10s</code></td>
+                <td>Time allowed to fetch one media URL; <code>0</code> removes the limit</td>
+              </tr>
+              <tr>
+                <td><code>--media-fetch-cache</code></td>
+                <td><code>KRONK_MEDIA_FETCH_CACHE</code></td>
+                <td><code>false</code></td>
+                <td>Keep fetched media that has an ETag under <code>&lt;base&gt;/media-cache</code></td>
+              </tr>
+              <tr>
+                <td><code>--media-fetch-cache-max-bytes</code></td>
+                <td><code>KRONK_MEDIA_FETCH_CACHE_MAX_BYTES</code></td>
+                <td><code>1073741824</code></td>
+                <td>Maximum total size of the media cache; the least recently used media is removed first, and <code>0</code> removes the limit</td>
+              </tr>
               <tr>
                 <td><code>--web-admin-enabled</code></td>
                 <td><code>KRONK_WEB_ADMIN_ENABLED</code></td>
@@ -1831,6 +2061,7 @@ kronk libs --local`}</code></pre>
           <p>Kronk also probes the installed preferred accelerator bundle. It changes to a different installed bundle only when the preferred bundle positively reports no accelerator and a same-version alternative positively reports a device. This probe never installs another bundle. The startup log records the preferred and selected processors and the reason for the decision.</p>
           <p><code>--processor</code> and <code>--lib-path</code>, or their environment equivalents, are strict operator choices and disable automatic fallback. A custom non-empty library directory without <code>version.json</code> is treated as a read-only user-managed build; the server loads it but does not upgrade or replace it. <code>--base-path</code> moves the managed library root along with other Kronk data, while <code>--lib-path</code> selects the llama.cpp location specifically. Restart the server after changing any native library selection setting.</p>
           <p>Bucky performs separate whisper.cpp runtime selection. Its managed bundles live below <code>&lt;base&gt;/bucky-libraries</code>, and <code>KRONK_BUCKY_LIB_PATH</code> authoritatively selects a different bundle or user-managed build. It does not use <code>--lib-path</code>/<code>KRONK_LIB_PATH</code>. See <a href="https://www.kronkai.com/manual#182-install-whisper-libraries">Chapter 18 §18.2</a> for Bucky's CUDA, Vulkan, and CPU fallback rules.</p>
+          <p>Malina performs the same separate selection for stable-diffusion.cpp. Its managed bundles live below <code>&lt;base&gt;/malina-libraries</code>, and <code>KRONK_MALINA_LIB_PATH</code> selects a different bundle or user-managed build. When the library cannot be installed or loaded the server still starts, and the image endpoints return an error until it is available.</p>
           <h2 id="84-model-pool-and-resource-budgets">8.4 Model Pool and Resource Budgets</h2>
           <p>Kronk keeps loaded models in memory to avoid paying model-load latency on every request. Three settings govern retention:</p>
           <ul>
@@ -1890,9 +2121,29 @@ kms:
     budget-percent: 95
     models-in-pool: 10
     ttl: 0m
+  responses:
+    store: memory
+    ttl: 24h
+  files:
+    max-bytes: 536870912
+    quota-bytes: 10737418240
+  batches:
+    concurrency: 4
+  media-fetch:
+    enabled: false
+    allowed-hosts: []
+    allow-private: false
+    max-bytes: 20971520
+    timeout: 10s
+    cache: false
+    cache-max-bytes: 1073741824
+  scheduling:
+    priorities:
+      # chat-completions, completions, responses, messages: low, normal, or high
   base-path: ""
   lib-path: ""
   bucky-lib-path: ""
+  malina-lib-path: ""
   lib-version: ""
   arch: ""
   os: ""
@@ -1977,6 +2228,7 @@ docker rm kronk
           <p>When server authentication is enabled, inference requests require a bearer token with access to the requested endpoint:</p>
           <pre className="code-block"><code className="language-text">{`Authorization: Bearer <token>`}</code></pre>
           <p>Authentication is bypassed only when the server is configured with authentication disabled. See <a href="https://www.kronkai.com/manual#chapter-12-security-and-authentication">Chapter 12</a> for token creation, endpoint grants, and rate limits.</p>
+          <p>Chat completions, completions, responses, and messages requests may send two scheduling headers. <code>X-Kronk-Priority: low|normal|high</code> lowers the request's priority below the endpoint default or the token's priority claim; it cannot raise it. <code>X-Kronk-Tenant</code> names the tenant that shares slots fairly with other tenants when authentication is disabled; authenticated requests always use the token subject. See <a href="https://www.kronkai.com/manual#chapter-4-batch-processing">Chapter 4</a> for the scheduling rules.</p>
           <p>Application errors use a top-level code and message:</p>
           <pre className="code-block"><code className="language-json">{`{
   "code": "invalid_argument",
@@ -1998,16 +2250,91 @@ docker rm kronk
                 <td>POST</td>
                 <td>OpenAI-style chat completions</td>
               </tr>
+              <tr>
+                <td><code>/v1/completions</code></td>
+                <td>POST</td>
+                <td>Raw prompt and FIM completions</td>
+              </tr>
+              <tr>
+                <td><code>/v1/infill</code></td>
+                <td>POST</td>
+                <td>llama.cpp-style code infill</td>
+              </tr>
               <tr>
                 <td><code>/v1/responses</code></td>
                 <td>POST</td>
                 <td>OpenAI Responses API</td>
               </tr>
+              <tr>
+                <td><code>/v1/responses/&#123;id&#125;</code></td>
+                <td>GET</td>
+                <td>Retrieve a stored response</td>
+              </tr>
+              <tr>
+                <td><code>/v1/responses/&#123;id&#125;</code></td>
+                <td>DELETE</td>
+                <td>Delete a stored response</td>
+              </tr>
+              <tr>
+                <td><code>/v1/responses/&#123;id&#125;/input_items</code></td>
+                <td>GET</td>
+                <td>List the input items of a response</td>
+              </tr>
               <tr>
                 <td><code>/v1/messages</code></td>
                 <td>POST</td>
                 <td>Anthropic Messages API</td>
               </tr>
+              <tr>
+                <td><code>/v1/messages/count_tokens</code></td>
+                <td>POST</td>
+                <td>Count the input tokens of a message</td>
+              </tr>
+              <tr>
+                <td><code>/v1/files</code></td>
+                <td>POST</td>
+                <td>Upload a file</td>
+              </tr>
+              <tr>
+                <td><code>/v1/files</code></td>
+                <td>GET</td>
+                <td>List uploaded files</td>
+              </tr>
+              <tr>
+                <td><code>/v1/files/&#123;id&#125;</code></td>
+                <td>GET</td>
+                <td>Retrieve a file's metadata</td>
+              </tr>
+              <tr>
+                <td><code>/v1/files/&#123;id&#125;</code></td>
+                <td>DELETE</td>
+                <td>Delete an uploaded file</td>
+              </tr>
+              <tr>
+                <td><code>/v1/files/&#123;id&#125;/content</code></td>
+                <td>GET</td>
+                <td>Download a file's content</td>
+              </tr>
+              <tr>
+                <td><code>/v1/batches</code></td>
+                <td>POST</td>
+                <td>Queue a batch of requests</td>
+              </tr>
+              <tr>
+                <td><code>/v1/batches</code></td>
+                <td>GET</td>
+                <td>List batches</td>
+              </tr>
+              <tr>
+                <td><code>/v1/batches/&#123;id&#125;</code></td>
+                <td>GET</td>
+                <td>Retrieve a batch and its progress</td>
+              </tr>
+              <tr>
+                <td><code>/v1/batches/&#123;id&#125;/cancel</code></td>
+                <td>POST</td>
+                <td>Cancel a batch</td>
+              </tr>
               <tr>
                 <td><code>/v1/embeddings</code></td>
                 <td>POST</td>
@@ -2043,9 +2370,69 @@ docker rm kronk
                 <td>POST</td>
                 <td>Transcribe audio with Bucky</td>
               </tr>
+              <tr>
+                <td><code>/v1/audio/translations</code></td>
+                <td>POST</td>
+                <td>Translate speech to English text</td>
+              </tr>
+              <tr>
+                <td><code>/v1/realtime</code></td>
+                <td>GET</td>
+                <td>Live transcription over a WebSocket</td>
+              </tr>
+              <tr>
+                <td><code>/v1/images/generations</code></td>
+                <td>POST</td>
+                <td>Generate images with Malina</td>
+              </tr>
+              <tr>
+                <td><code>/v1/images/edits</code></td>
+                <td>POST</td>
+                <td>Image-to-image edits with Malina</td>
+              </tr>
+              <tr>
+                <td><code>/v1/images/files/&#123;id&#125;</code></td>
+                <td>GET</td>
+                <td>Download an image returned by URL</td>
+              </tr>
+              <tr>
+                <td><code>/api/chat</code></td>
+                <td>POST</td>
+                <td>Ollama chat</td>
+              </tr>
+              <tr>
+                <td><code>/api/generate</code></td>
+                <td>POST</td>
+                <td>Ollama prompt generation</td>
+              </tr>
+              <tr>
+                <td><code>/api/embed</code></td>
+                <td>POST</td>
+                <td>Ollama embeddings</td>
+              </tr>
+              <tr>
+                <td><code>/api/tags</code></td>
+                <td>GET</td>
+                <td>Ollama list of local models</td>
+              </tr>
+              <tr>
+                <td><code>/api/show</code></td>
+                <td>POST</td>
+                <td>Ollama model details</td>
+              </tr>
+              <tr>
+                <td><code>/api/ps</code></td>
+                <td>GET</td>
+                <td>Ollama list of loaded models</td>
+              </tr>
+              <tr>
+                <td><code>/api/pull</code></td>
+                <td>POST</td>
+                <td>Ollama model download</td>
+              </tr>
             </tbody>
           </table>
-          <p>Sections 9.10 through 9.13 inventory the administration, diagnostics, and evaluation endpoints used by the CLI and BUI. Administration endpoints are open when administration authentication is disabled. When it is enabled, they require an administrator token. <code>GET /v1/models</code> and <code>GET /v1/models/&#123;model&#125;</code> instead follow inference authentication and do not require a separate endpoint grant.</p>
+          <p>Sections 9.14 through 9.17 inventory the administration, diagnostics, and evaluation endpoints used by the CLI and BUI. Administration endpoints are open when administration authentication is disabled. When it is enabled, they require an administrator token. <code>GET /v1/models</code> and <code>GET /v1/models/&#123;model&#125;</code> instead follow inference authentication and do not require a separate endpoint grant.</p>
           <h2 id="93-chat-completions-and-tool-calls">9.3 Chat Completions and Tool Calls</h2>
           <p><code>POST /v1/chat/completions</code> accepts an OpenAI-style <code>model</code> and <code>messages</code> request:</p>
           <pre className="code-block"><code className="language-json">{`{
@@ -2056,17 +2443,22 @@ docker rm kronk
   ]
 }`}</code></pre>
           <p>A non-streaming response contains one or more <code>choices</code>, an assistant <code>message</code>, a <code>finish_reason</code>, and token <code>usage</code>. Thinking models can also return <code>reasoning_content</code>. Set the top-level <code>enable_thinking</code> boolean to request or suppress thinking when the model and its chat template support that option.</p>
-          <p>Kronk currently generates one choice per request. The optional <code>n</code> field may be omitted, <code>null</code>, or <code>1</code>; other values are rejected because multiple choices are not supported.</p>
+          <p>Set <code>n</code> to request several choices for the same prompt. It defaults to <code>1</code> and may not exceed the model's parallel slots (<code>nseq-max</code>). Every choice runs in its own batch slot. The additional choices copy the prompt KV cache that the first choice has just prefilled, so the prompt is processed once. Models with a draft model, speculative decoding, or hybrid recurrent layers prefill each choice separately. Image and audio requests accept only <code>n: 1</code>. When <code>seed</code> is set, choice <code>i</code> samples with <code>seed + i</code>, so the choices differ but stay repeatable.</p>
+          <p>Each choice has its own <code>index</code>, <code>finish_reason</code>, <code>logprobs</code>, and <code>usage</code>. The top-level <code>usage</code> counts the prompt once and sums the completion tokens of all choices. Streaming responses interleave chunks from every choice; use the choice <code>index</code> to tell them apart.</p>
+          <p>Set <code>prompt_cache_key</code>, or send an <code>X-Kronk-Session</code> header, to pin the request to a named incremental-message-cache session. See <a href="https://www.kronkai.com/manual#chapter-5-message-caching">Chapter 5</a>.</p>
           <p>Use <code>max_completion_tokens</code> to set the output-token limit. The legacy <code>max_tokens</code> name remains supported; if both are supplied, <code>max_completion_tokens</code> takes precedence. Use <code>stop</code> with a string or an array of up to four strings to end generation when Kronk encounters one of those sequences. The matched sequence is omitted from the response. A custom stop has <code>finish_reason: "stop"</code>; a response that reaches its output-token limit has <code>finish_reason: "length"</code>.</p>
           <p>Set <code>"stream": true</code> to receive chat completion chunks as SSE records:</p>
           <pre className="code-block"><code className="language-text">{`data: {"id":"chatcmpl-...","object":"chat.completion.chunk",...}
 
 data: [DONE]`}</code></pre>
           <p>By default, streaming chunks omit <code>usage</code>. To request usage, set <code>"stream_options": &#123;"include_usage": true&#125;</code>. Each completion chunk then has <code>"usage": null</code>, and Kronk sends one additional chunk before <code>[DONE]</code> with an empty <code>choices</code> array and the final usage totals. This option affects the streaming response; it does not change generation or server-side accounting.</p>
-          <p>Compatible tool-call parsers emit an OpenAI-style activity delta as soon as a function name is known. Once parsing completes, Kronk emits the completed arguments in a nonterminal tool-call delta followed by an empty terminal delta with <code>finish_reason: "tool_calls"</code>.</p>
+          <p>Compatible tool-call parsers emit an OpenAI-style activity delta as soon as a function name is known. The Qwen (JSON <code>&lt;tool_call&gt;</code> envelope), GPT-OSS, Llama, and Mistral parsers then stream the arguments as the model writes them, one <code>function.arguments</code> fragment per delta. Concatenating the fragments for a call index gives its arguments object. Other parsers send the arguments once the call is parsed. Once parsing completes, Kronk emits any arguments not yet streamed in a nonterminal tool-call delta followed by an empty terminal delta with <code>finish_reason: "tool_calls"</code>. When the streamed text already decodes to the parsed arguments no further arguments are sent, so key order and spacing follow the model's output.</p>
+          <p>The Responses API streams the same fragments as <code>response.function_call_arguments.delta</code> events, and the Anthropic Messages API streams them as <code>input_json_delta</code> events in the call's <code>tool_use</code> block.</p>
           <p><code>usage.completion_tokens</code> includes all generated tokens, including reasoning, control, and tool-call syntax that a parser may buffer instead of exposing as assistant text. <code>usage.completion_tokens_details.reasoning_tokens</code> reports the reasoning subset. <code>usage.total_tokens</code> is the sum of <code>prompt_tokens</code> and <code>completion_tokens</code>.</p>
           <h3 id="tool-calls">Tool calls</h3>
-          <p>Add OpenAI-style function definitions in <code>tools</code> and use <code>"tool_choice": "auto"</code> to let the model select one. Tool calling requires a compatible model, chat template, and output parser; adding <code>tools</code> cannot give an incompatible model tool-calling ability. Use <code>"none"</code> to withhold tools and prevent structured tool-call output, <code>"required"</code> to request a call from a compatible model template, or the OpenAI forced-function object to limit the request to one declared function. Required and forced selection remain subject to the model and template honoring the requested mode.</p>
+          <p>Add OpenAI-style function definitions in <code>tools</code> and use <code>"tool_choice": "auto"</code> to let the model select one. Tool calling requires a compatible model, chat template, and output parser; adding <code>tools</code> cannot give an incompatible model tool-calling ability. Use <code>"none"</code> to withhold tools and prevent structured tool-call output, <code>"required"</code> to request a call from a compatible model template, or the OpenAI forced-function object to limit the request to one declared function.</p>
+          <p>For <code>"required"</code> and forced-function requests, Kronk enforces the call with a grammar built from the declared parameter schemas in the model's native tool-call syntax. The output must then be a tool call, and its arguments parse and match the schema. The converter supports the JSON Schema keywords listed in <a href="https://www.kronkai.com/manual#106-structured-output">Chapter 10 §10.6</a>. Set <code>"strict": true</code> on a function to reject a request whose parameters use unsupported keywords. <code>"parallel_tool_calls": false</code> limits the grammar to a single call. Thinking is disabled unless <code>enable_thinking</code> is set, and a request's own <code>grammar</code>, <code>json_schema</code>, or <code>response_format</code> takes precedence.</p>
+          <p>Grammar enforcement is available for marked JSON (<code>&lt;tool_call&gt;</code>), Qwen JSON and direct XML, Mistral <code>[TOOL_CALLS]</code>, GPT-OSS Harmony, GLM, and Llama tool-call formats. Harmony and Llama grammars allow a single call. Direct-XML and GLM string arguments are written as raw text, so only <code>enum</code> and <code>const</code> constrain them. Other parsers, including DeepSeek, Kimi, Gemma, and LFM, leave required and forced selection to the model and template.</p>
           <p>When a tool is selected, the assistant message contains <code>tool_calls</code> and uses an empty string for <code>content</code>:</p>
           <pre className="code-block"><code className="language-json">{`{
   "role": "assistant",
@@ -2103,6 +2495,15 @@ data: {"type":"response.output_text.delta","delta":"...",...}
 event: response.completed
 data: {"type":"response.completed",...}`}</code></pre>
           <p>Function calls produce corresponding <code>response.function_call_arguments.delta</code> and <code>.done</code> events. A token-limited stream ends with <code>response.incomplete</code> instead of <code>response.completed</code> and its embedded response carries the same incomplete details as a non-streaming response.</p>
+          <p>Responses are stored by default, so a later request can continue the conversation by naming the earlier response instead of resending it:</p>
+          <pre className="code-block"><code className="language-json">{`{
+  "model": "unsloth/Qwen3-1.7B-UD-Q8_K_XL",
+  "previous_response_id": "resp_...",
+  "input": "And how does it differ from classical computing?"
+}`}</code></pre>
+          <p>Kronk replays the input and output items of every response in the chain before the new <code>input</code>, and the new response echoes <code>previous_response_id</code>. Send <code>"store": false</code> to keep a response out of storage. <code>previous_response_id</code> cannot be combined with <code>messages</code>, and a response can only be continued, retrieved, or deleted with the same token that created it.</p>
+          <p><code>GET /v1/responses/&#123;id&#125;</code> returns a stored response, and <code>DELETE /v1/responses/&#123;id&#125;</code> removes it. <code>GET /v1/responses/&#123;id&#125;/input_items</code> lists the request's own input items with the <code>limit</code> (1 to 100, default 20), <code>order</code> (<code>desc</code> by default, or <code>asc</code>), and <code>after</code> query parameters.</p>
+          <p>Stored responses are kept in memory for 24 hours by default. Use <code>--response-store disk</code> to keep them under <code>&lt;base&gt;/responses</code> across restarts, <code>--response-store none</code> to disable storage, and <code>--response-ttl</code> to change the retention time. With storage disabled, <code>previous_response_id</code> and the stored response endpoints fail with a precondition error.</p>
           <h2 id="95-anthropic-messages-api">9.5 Anthropic Messages API</h2>
           <p><code>POST /v1/messages</code> provides an Anthropic-style interface. <code>model</code> and a nonzero <code>max_tokens</code> are required:</p>
           <pre className="code-block"><code className="language-json">{`{
@@ -2113,17 +2514,153 @@ data: {"type":"response.completed",...}`}</code></pre>
     {"role": "user", "content": "What is the capital of France?"}
   ]
 }`}</code></pre>
-          <p><code>system</code> and message <code>content</code> may be strings or arrays of content blocks. The API supports text, image, <code>tool_use</code>, and <code>tool_result</code> blocks, subject to the selected model's capabilities. Anthropic-style tool definitions use <code>name</code>, <code>description</code>, and <code>input_schema</code>.</p>
+          <p><code>system</code> and message <code>content</code> may be strings or arrays of content blocks. The API supports text, image, <code>document</code>, <code>tool_use</code>, and <code>tool_result</code> blocks, subject to the selected model's capabilities. Documents and file sources are described in section 9.7. Anthropic-style tool definitions use <code>name</code>, <code>description</code>, and <code>input_schema</code>.</p>
+          <p><code>tool_choice</code> accepts <code>&#123;"type":"auto"&#125;</code>, <code>&#123;"type":"any"&#125;</code>, <code>&#123;"type":"none"&#125;</code>, or <code>&#123;"type":"tool","name":"get_weather"&#125;</code>. <code>any</code> and <code>tool</code> map to the chat <code>"required"</code> and forced-function modes described in section 9.3, and <code>"disable_parallel_tool_use": true</code> limits the response to one call. <code>top_k</code> is passed to the sampler, and <code>metadata</code> is accepted and ignored.</p>
+          <p><code>thinking</code> controls reasoning. <code>&#123;"type":"enabled","budget_tokens":8192&#125;</code> turns thinking on and returns the reasoning as <code>thinking</code> content blocks before the answer; streaming emits them with <code>thinking_delta</code> events. Kronk has no reasoning token budget, so <code>budget_tokens</code> selects a reasoning effort: below 4096 is <code>low</code>, below 16384 is <code>medium</code>, and larger budgets are <code>high</code>. It must be less than <code>max_tokens</code>. <code>&#123;"type":"disabled"&#125;</code> turns thinking off. When <code>thinking</code> is omitted, the model's default applies and its reasoning is not returned. Kronk does not sign thinking, so <code>signature</code> is empty, and <code>thinking</code> blocks sent back in assistant messages are replayed as reasoning content.</p>
           <p>With <code>"stream": true</code>, Kronk emits Anthropic-style named events including <code>message_start</code>, <code>content_block_start</code>, <code>content_block_delta</code>, <code>content_block_stop</code>, <code>message_delta</code>, and <code>message_stop</code>.</p>
-          <p>Custom <code>stop_sequences</code> are not supported and are rejected. A request that reaches <code>max_tokens</code> returns <code>stop_reason: "max_tokens"</code>; natural completion uses <code>end_turn</code>, and a completed tool call uses <code>tool_use</code>. Streaming and non-streaming responses use the same mapping.</p>
-          <h2 id="96-embeddings">9.6 Embeddings</h2>
+          <p><code>stop_sequences</code> accepts up to four sequences. A request that stops on one returns <code>stop_reason: "stop_sequence"</code> and the matched <code>stop_sequence</code>. A request that reaches <code>max_tokens</code> returns <code>stop_reason: "max_tokens"</code>; natural completion uses <code>end_turn</code>, and a completed tool call uses <code>tool_use</code>. Streaming and non-streaming responses use the same mapping.</p>
+          <p><code>POST /v1/messages/count_tokens</code> accepts the same body without <code>max_tokens</code> and returns <code>&#123;"input_tokens": 42&#125;</code>. The count renders the messages, system prompt, tools, and thinking setting with the model's chat template, so it matches the prompt a request would send. Image content is not counted.</p>
+          <h2 id="96-completions-and-infill">9.6 Completions and Infill</h2>
+          <p><code>POST /v1/completions</code> is the legacy OpenAI completions API. The <code>prompt</code> is tokenized as given, without the chat template, and the generated text is returned unparsed, so reasoning and tool calls are not extracted. Special tokens written in the prompt are honored. <code>prompt</code> may also be a single-string array or an array of token IDs:</p>
+          <pre className="code-block"><code className="language-json">{`{
+  "model": "unsloth/Qwen2.5-Coder-1.5B-Instruct-Q8_0",
+  "prompt": "def fibonacci(n):",
+  "max_tokens": 64,
+  "stop": ["\\ndef "]
+}`}</code></pre>
+          <p>The response has <code>object: "text_completion"</code>, and each choice carries <code>text</code>, <code>index</code>, <code>logprobs</code>, and <code>finish_reason</code>. <code>n</code>, <code>stop</code>, <code>stream</code>, <code>max_tokens</code>, and the sampling parameters of <a href="https://www.kronkai.com/manual#chapter-10-request-parameters">Chapter 10</a> behave as they do for chat completions. <code>max_tokens</code> defaults to the model's limit rather than the OpenAI default of 16.</p>
+          <ul>
+            <li><code>echo: true</code> prepends the prompt to each choice's <code>text</code>; when streaming, the prompt arrives in the first chunk of each choice. It requires a text prompt and cannot be combined with <code>suffix</code>.</li>
+            <li><code>logprobs: N</code> returns the legacy <code>tokens</code>, <code>token_logprobs</code>, <code>top_logprobs</code>, and <code>text_offset</code> arrays for the generated tokens, with up to <code>N</code> alternatives per token. Prompt tokens are not scored.</li>
+            <li><code>stream: true</code> sends <code>text_completion</code> chunks as SSE <code>data:</code> events and ends with <code>data: [DONE]</code>. Usage is sent in a final chunk with no choices when <code>stream_options.include_usage</code> is true.</li>
+          </ul>
+          <p>A <code>suffix</code> field turns the request into fill-in-the-middle (FIM). Kronk builds the prompt from the model's FIM tokens in the GGUF metadata (<code>tokenizer.ggml.fim_pre_token_id</code>, <code>fim_suf_token_id</code>, and <code>fim_mid_token_id</code>) in prefix-suffix-middle order, and the model generates the code that belongs between <code>prompt</code> and <code>suffix</code>. The prefix and suffix are not parsed for special tokens. When they do not fit in the context window beside <code>max_tokens</code>, the start of the prefix and the end of the suffix are dropped, keeping up to a quarter of the room for the suffix. A model without FIM tokens rejects the request with <code>400 Bad Request</code>.</p>
+          <p><code>POST /v1/infill</code>, also served as <code>POST /infill</code>, accepts the llama.cpp infill fields for editor plugins. <code>input_prefix</code> and <code>input_suffix</code> surround the cursor, <code>prompt</code> is text typed at the cursor and is appended to the prefix, and <code>n_predict</code> is an alias for <code>max_tokens</code>. The response uses the completions format above:</p>
+          <pre className="code-block"><code className="language-json">{`{
+  "model": "unsloth/Qwen2.5-Coder-1.5B-Instruct-Q8_0",
+  "input_prefix": "def add(a, b):\\n    ",
+  "input_suffix": "\\n\\nprint(add(1, 2))\\n",
+  "n_predict": 32
+}`}</code></pre>
+          <p>Raw completions do not use the incremental message cache. Both routes use the <code>completions</code> endpoint grant and are subject to token budgets and model scopes.</p>
+          <h2 id="97-files">9.7 Files</h2>
+          <p>The Files API stores images, audio, PDFs, and text files on the server so a conversation can refer to them by ID instead of sending their content on every turn. <code>POST /v1/files</code> takes a multipart form with a <code>file</code> and a <code>purpose</code> (<code>assistants</code>, <code>batch</code>, <code>fine-tune</code>, <code>vision</code>, <code>user_data</code>, or <code>evals</code>):</p>
+          <pre className="code-block"><code className="language-shell">{`curl http://localhost:11435/v1/files \\
+  -H "Authorization: Bearer $KRONK_TOKEN" \\
+  -F purpose=user_data \\
+  -F file=@report.pdf`}</code></pre>
+          <p>The response is an OpenAI file object:</p>
+          <pre className="code-block"><code className="language-json">{`{
+  "id": "file-6f1c...",
+  "object": "file",
+  "bytes": 48213,
+  "created_at": 1760659200,
+  "filename": "report.pdf",
+  "purpose": "user_data",
+  "status": "processed"
+}`}</code></pre>
+          <p><code>GET /v1/files</code> lists files newest first and accepts the <code>purpose</code>, <code>limit</code> (1 to 10000), <code>order</code> (<code>desc</code> or <code>asc</code>), and <code>after</code> query parameters. <code>GET /v1/files/&#123;id&#125;</code> returns one file object, <code>GET /v1/files/&#123;id&#125;/content</code> returns the original bytes, and <code>DELETE /v1/files/&#123;id&#125;</code> returns <code>&#123;"id": "...", "object": "file", "deleted": true&#125;</code>.</p>
+          <p>Files belong to the token subject that uploaded them; other subjects see them as not found. They are kept under <code>&lt;base&gt;/files</code>, where identical content is stored once. A single file is limited to 512 MiB and a subject's files to 10 GiB in total; change the limits with <code>--files-max-bytes</code> and <code>--files-quota-bytes</code> (0 removes a limit). An upload past either limit is rejected with <code>400 Bad Request</code> or <code>429 Too Many Requests</code> respectively.</p>
+          <p>Chat completions, responses, and messages requests reference a file with a content part. Inline <code>file_data</code> (a base64 string or data URI) is accepted in place of <code>file_id</code>:</p>
+          <table className="flags-table">
+            <thead>
+              <tr>
+                <th>API</th>
+                <th>Content part</th>
+              </tr>
+            </thead>
+            <tbody>
+              <tr>
+                <td>Chat Completions</td>
+                <td><code>&#123;"type":"file","file":&#123;"file_id":"file-..."&#125;&#125;</code></td>
+              </tr>
+              <tr>
+                <td>Responses</td>
+                <td><code>&#123;"type":"input_file","file_id":"file-..."&#125;</code></td>
+              </tr>
+              <tr>
+                <td>Responses</td>
+                <td><code>&#123;"type":"input_image","file_id":"file-..."&#125;</code></td>
+              </tr>
+              <tr>
+                <td>Anthropic Messages</td>
+                <td><code>&#123;"type":"document","source":&#123;"type":"file","file_id":"file-..."&#125;&#125;</code></td>
+              </tr>
+              <tr>
+                <td>Anthropic Messages</td>
+                <td><code>&#123;"type":"image","source":&#123;"type":"file","file_id":"file-..."&#125;&#125;</code></td>
+              </tr>
+            </tbody>
+          </table>
+          <p>Kronk resolves the part by its content. Images and audio join the media pipeline described in <a href="https://www.kronkai.com/manual#chapter-11-multimodal-models">Chapter 11</a> as if they were sent inline, so the model must support that media. PDFs and UTF-8 text files become a text part holding the file's text, wrapped as <code>&lt;file name="report.pdf"&gt;...&lt;/file&gt;</code>. PDF text is read from the page content streams, so scanned pages and text in fonts with custom encodings are not recovered. Other files, and <code>input_image</code> parts that are not images, are rejected with <code>400 Bad Request</code>. Anthropic <code>document</code> blocks also accept <code>base64</code> and <code>text</code> sources. Stored responses keep the <code>file_id</code> rather than the file content, so deleting a file breaks later <code>previous_response_id</code> requests that replay it.</p>
+          <p>The Files API uses the <code>files</code> endpoint grant. Referencing a file from an inference request requires only that endpoint's grant.</p>
+          <h2 id="98-batches">9.8 Batches</h2>
+          <p>The Batch API runs a large set of chat completions, embeddings, or rerank requests offline. Write one request per line to a JSONL file, where every line targets the same endpoint and carries a unique <code>custom_id</code>:</p>
+          <pre className="code-block"><code className="language-json">{`{"custom_id": "row-1", "method": "POST", "url": "/v1/chat/completions", "body": {"model": "Qwen/Qwen3-8B-Q8_0", "messages": [{"role": "user", "content": "Summarize ticket 1"}]}}
+{"custom_id": "row-2", "method": "POST", "url": "/v1/chat/completions", "body": {"model": "Qwen/Qwen3-8B-Q8_0", "messages": [{"role": "user", "content": "Summarize ticket 2"}]}}`}</code></pre>
+          <p>Upload the file with purpose <code>batch</code> and create the batch from its ID:</p>
+          <pre className="code-block"><code className="language-shell">{`curl http://localhost:11435/v1/files \\
+  -H "Authorization: Bearer $KRONK_TOKEN" \\
+  -F purpose=batch \\
+  -F file=@tickets.jsonl
+
+curl http://localhost:11435/v1/batches \\
+  -H "Authorization: Bearer $KRONK_TOKEN" \\
+  -H "Content-Type: application/json" \\
+  -d '{"input_file_id": "file-6f1c...", "endpoint": "/v1/chat/completions", "completion_window": "24h"}'`}</code></pre>
+          <p><code>endpoint</code> is <code>/v1/chat/completions</code>, <code>/v1/embeddings</code>, or <code>/v1/rerank</code>, and <code>completion_window</code> must be <code>24h</code>. An optional <code>metadata</code> object holds up to 16 string pairs. The file may hold up to 50,000 requests. Every line must use <code>POST</code>, the batch endpoint as its <code>url</code>, and a <code>body</code> with a <code>model</code>; streaming is not supported. A file with invalid lines produces a batch with status <code>failed</code> and the problems listed in <code>errors</code>, each with the line number.</p>
+          <p>The response is an OpenAI batch object:</p>
+          <pre className="code-block"><code className="language-json">{`{
+  "id": "batch_0b9a...",
+  "object": "batch",
+  "endpoint": "/v1/chat/completions",
+  "errors": null,
+  "input_file_id": "file-6f1c...",
+  "completion_window": "24h",
+  "status": "in_progress",
+  "output_file_id": null,
+  "error_file_id": null,
+  "created_at": 1760659200,
+  "in_progress_at": 1760659201,
+  "expires_at": 1760745600,
+  "request_counts": {"total": 2, "completed": 1, "failed": 0},
+  "metadata": null
+}`}</code></pre>
+          <p>A batch moves from <code>validating</code> while it waits in the queue to <code>in_progress</code>, then <code>finalizing</code> and <code>completed</code>. It ends as <code>failed</code> when its input is invalid or was deleted, <code>expired</code> when the completion window passes first, and <code>cancelling</code> then <code>cancelled</code> after <code>POST /v1/batches/&#123;id&#125;/cancel</code>. <code>GET /v1/batches/&#123;id&#125;</code> returns the current object with its <code>request_counts</code>, and <code>GET /v1/batches</code> lists batches newest first with the <code>limit</code> (1 to 100, default 20) and <code>after</code> query parameters.</p>
+          <p>Kronk runs one batch at a time in creation order. Each line is sent through the same handler as a live request, so it gets the same validation and response body, and chat lines are scheduled at <code>low</code> priority behind interactive traffic. <code>--batch-concurrency</code> (default 4) sets how many lines are in flight at once. Progress is recorded under <code>&lt;base&gt;/batches</code> after every group of lines, so a batch interrupted by a restart resumes where it stopped; lines that were in flight run again.</p>
+          <p>When a batch ends, its results are stored as files with purpose <code>batch_output</code> and their IDs set in <code>output_file_id</code> and <code>error_file_id</code>. Download them from <code>/v1/files/&#123;id&#125;/content</code>. Lines that returned 2xx go to the output file and the rest to the error file, in input order:</p>
+          <pre className="code-block"><code className="language-json">{`{"id": "batch_req_41c2...", "custom_id": "row-1", "response": {"status_code": 200, "request_id": "req_93ad...", "body": {"id": "chatcmpl-...", "object": "chat.completion", "choices": [...]}}, "error": null}`}</code></pre>
+          <p>Requests skipped because the batch expired appear in the error file with <code>response</code> set to null and an <code>error</code> of code <code>batch_expired</code>. A cancelled batch keeps the results of the lines that finished. The result files count against the subject's Files API quota.</p>
+          <p>The Batch API uses the <code>batches</code> endpoint grant. Creating a batch also requires the grant of the endpoint its lines use, and model-scoped tokens must allow every model the lines name. Token budgets are checked when the batch is created.</p>
+          <p>Each line runs with the token that created the batch, so it is authenticated, rate limited and charged to the token's budgets like any other request. Before each group of lines the token is checked again. Once it has been revoked or has expired the batch stops: the remaining lines are written to the error file with the code <code>invalid_token</code> or <code>token_expired</code>, and the batch ends as <code>failed</code>. Lines for a model whose token budget is used up fail with <code>token_budget_exceeded</code> and the batch carries on with the rest.</p>
+          <h2 id="99-embeddings">9.9 Embeddings</h2>
           <p><code>POST /v1/embeddings</code> accepts one string or an array of strings:</p>
           <pre className="code-block"><code className="language-json">{`{
   "model": "Qwen/Qwen3-Embedding-0.6B-Q8_0",
   "input": ["First document", "Second document"]
 }`}</code></pre>
           <p>The response contains <code>object</code>, <code>created</code>, <code>model</code>, a <code>data</code> array, and <code>usage</code>. Each data item has an <code>index</code> and an <code>embedding</code> vector. Use an embedding model; ordinary text-generation models do not provide useful embedding behavior.</p>
-          <h2 id="97-reranking">9.7 Reranking</h2>
+          <p><code>input</code> may also be pre-tokenized: an array of token IDs embeds one input and an array of token ID arrays embeds several. Token IDs are embedded as given, without BOS or separator tokens added.</p>
+          <p>Other options:</p>
+          <ul>
+            <li><code>dimensions</code> keeps the first N dimensions of Matryoshka models.</li>
+            <li><code>encoding_format: "base64"</code> returns each vector as base64 little-endian float32 values instead of a float array, which roughly halves the payload.</li>
+            <li><code>truncate: true</code> drops tokens past the context window, from the end unless <code>truncate_direction</code> is <code>"left"</code>. Without it, over-long inputs are rejected.</li>
+            <li><code>chunking</code> embeds over-long inputs instead of truncating them.</li>
+          </ul>
+          <p>With chunking, an input longer than the chunk size is split into overlapping token windows that are embedded together and pooled into one normalized vector. <code>chunking: true</code> uses the largest size the model accepts and mean pooling. An object sets the options:</p>
+          <pre className="code-block"><code className="language-json">{`{
+  "model": "Qwen/Qwen3-Embedding-0.6B-Q8_0",
+  "input": "A long document ...",
+  "chunking": {
+    "size": 512,
+    "overlap": 64,
+    "pooling": "mean",
+    "return_chunks": true
+  }
+}`}</code></pre>
+          <p><code>size</code> counts tokens per chunk including special tokens, and <code>overlap</code> must be smaller than it. <code>pooling</code> is <code>mean</code>, weighted by chunk length, or <code>max</code>. With <code>return_chunks</code>, each data item also has a <code>chunks</code> array of <code>index</code>, <code>start_token</code>, <code>end_token</code>, and <code>embedding</code>, where the token offsets locate the chunk in the tokenized input. <code>usage</code> counts every embedded token, including overlapping ones.</p>
+          <h2 id="910-reranking">9.10 Reranking</h2>
           <p><code>POST /v1/rerank</code> and <code>POST /v1/reranking</code> are equivalent. Supply a reranker model, a query, and a nonempty string array:</p>
           <pre className="code-block"><code className="language-json">{`{
   "model": "gpustack/bge-reranker-v2-m3-Q8_0",
@@ -2146,7 +2683,7 @@ data: {"type":"response.completed",...}`}</code></pre>
   "usage": {"prompt_tokens": 24, "total_tokens": 24}
 }`}</code></pre>
           <p>Documents are omitted from results by default. Set <code>return_documents</code> to <code>true</code> when the response should include their text. <code>top_n</code> defaults to all documents.</p>
-          <h2 id="98-tokenization">9.8 Tokenization</h2>
+          <h2 id="911-tokenization">9.11 Tokenization</h2>
           <p><code>POST /v1/tokenize</code> returns a token <strong>count</strong>, not token IDs:</p>
           <pre className="code-block"><code className="language-json">{`{
   "model": "unsloth/Qwen3-1.7B-UD-Q8_K_XL",
@@ -2155,17 +2692,137 @@ data: {"type":"response.completed",...}`}</code></pre>
   "add_generation_prompt": true
 }`}</code></pre>
           <p><code>apply_template</code> defaults to <code>false</code>. When enabled, Kronk wraps the input as a user message and includes chat-template overhead in the count. <code>add_generation_prompt</code> controls the assistant prefix when the template is applied and defaults to <code>true</code>.</p>
+          <p>Send a chat <code>messages</code> array instead of <code>input</code> to count a whole conversation. Kronk renders it with the chat template together with any <code>tools</code> and <code>enable_thinking</code> setting, as a chat request would. Media content is not counted.</p>
           <pre className="code-block"><code className="language-json">{`{
   "object": "tokenize",
   "created": 1738857600,
   "model": "unsloth/Qwen3-1.7B-UD-Q8_K_XL",
   "tokens": 11
 }`}</code></pre>
-          <h2 id="99-models-and-audio-transcription">9.9 Models and Audio Transcription</h2>
+          <h2 id="912-models-audio-and-images">9.12 Models, Audio, and Images</h2>
           <p><code>GET /v1/models</code> returns an OpenAI-style list of models and configured model extensions available locally. It is not limited to models currently loaded in memory. Each item includes <code>id</code>, <code>object</code>, <code>created</code>, and <code>owned_by</code>. The <code>id</code> uses the canonical <code>provider/modelID</code> form. <code>owned_by</code> comes from model metadata when available and otherwise defaults to <code>kronk</code>.</p>
-          <p><code>GET /v1/models/&#123;model&#125;</code> returns the corresponding OpenAI-style model object for one model ID. It returns <code>404 Not Found</code> when the model is not available.</p>
-          <p><code>POST /v1/audio/transcriptions</code> accepts multipart audio uploads and uses the Bucky speech-to-text runtime. Its request fields, formats, and administrative operations are documented in <a href="https://www.kronkai.com/manual#1861-request-and-response">Chapter 18</a>.</p>
-          <h2 id="910-kronk-administration">9.10 Kronk Administration</h2>
+          <p><code>GET /v1/models/&#123;model&#125;</code> returns the corresponding OpenAI-style model object for one model ID. It returns <code>404 Not Found</code> when the model is not available. Both routes hide models a token limited by <code>--models</code> may not use; see <a href="https://www.kronkai.com/manual#chapter-12-security-and-authentication">Chapter 12</a>.</p>
+          <p><code>POST /v1/audio/transcriptions</code> and <code>POST /v1/audio/translations</code> accept multipart audio uploads and use the Bucky speech-to-text runtime. Set <code>stream=true</code> to receive the transcript segment by segment as server-sent events. Its request fields, formats, and administrative operations are documented in <a href="https://www.kronkai.com/manual#1861-request-and-response">Chapter 18</a>.</p>
+          <p><code>GET /v1/realtime?intent=transcription</code> upgrades to a WebSocket for live transcription with OpenAI's realtime transcription session events. Its protocol is documented in <a href="https://www.kronkai.com/manual#1862-realtime-transcription">Chapter 18</a>.</p>
+          <h3 id="image-generation">Image generation</h3>
+          <p><code>POST /v1/images/generations</code> and <code>POST /v1/images/edits</code> follow the OpenAI Images API and run on the Malina stable-diffusion runtime. The <code>model</code> field names a curated Malina bundle such as <code>sd-1.5</code>, <code>sdxl-base-1.0</code>, or <code>flux2-klein-4b</code>; install bundles with <code>kronk malina model pull</code> as described in <a href="https://www.kronkai.com/manual#193-manage-model-bundles">Chapter 19</a>. Image models are loaded into the same model pool as chat and whisper models, so they share the memory budget, <code>--models-in-pool</code>, and <code>--pool-ttl</code> and are evicted by the same rules.</p>
+          <pre className="code-block"><code className="language-shell">{`curl http://localhost:11435/v1/images/generations \\
+  -H "Content-Type: application/json" \\
+  -d '{
+    "model": "sd-1.5",
+    "prompt": "a lighthouse at dusk, oil painting",
+    "size": "512x512",
+    "response_format": "b64_json"
+  }'`}</code></pre>
+          <table className="flags-table">
+            <thead>
+              <tr>
+                <th>Field</th>
+                <th>Default</th>
+                <th>Notes</th>
+              </tr>
+            </thead>
+            <tbody>
+              <tr>
+                <td><code>prompt</code></td>
+                <td>required</td>
+                <td>Text prompt</td>
+              </tr>
+              <tr>
+                <td><code>n</code></td>
+                <td><code>1</code></td>
+                <td>Number of images, 1 through 10</td>
+              </tr>
+              <tr>
+                <td><code>size</code></td>
+                <td><code>512x512</code></td>
+                <td><code>WIDTHxHEIGHT</code>; each side a multiple of 8 between 64 and 1024</td>
+              </tr>
+              <tr>
+                <td><code>response_format</code></td>
+                <td><code>url</code></td>
+                <td><code>url</code> or <code>b64_json</code></td>
+              </tr>
+              <tr>
+                <td><code>negative_prompt</code></td>
+                <td>empty</td>
+                <td>Kronk extension</td>
+              </tr>
+              <tr>
+                <td><code>steps</code></td>
+                <td><code>20</code></td>
+                <td>Kronk extension; sampling steps</td>
+              </tr>
+              <tr>
+                <td><code>cfg_scale</code></td>
+                <td><code>7</code></td>
+                <td>Kronk extension; classifier-free guidance scale</td>
+              </tr>
+              <tr>
+                <td><code>seed</code></td>
+                <td>random</td>
+                <td>Kronk extension; image <code>i</code> of <code>n</code> uses <code>seed + i</code></td>
+              </tr>
+            </tbody>
+          </table>
+          <p>The response is <code>&#123;"created": &lt;unix&gt;, "data": [&#123;"b64_json": "..."&#125;]&#125;</code> or, for <code>url</code>, <code>&#123;"url": "http://&lt;host&gt;/v1/images/files/img_&lt;id&gt;"&#125;</code>. Image URLs are unguessable, need no bearer token, and expire after one hour. The server keeps the most recent 100 URL images in memory and does not keep them across a restart.</p>
+          <p><code>POST /v1/images/edits</code> takes a multipart form with an <code>image</code> file (PNG or JPEG), the fields above, and an optional <code>strength</code> between 0 and 1 (default <code>0.75</code>) that controls how far the result may move from the source image. The output keeps the source dimensions unless <code>size</code> is set. Masks are not supported and a <code>mask</code> field is rejected.</p>
+          <h2 id="913-ollama-api">9.13 Ollama API</h2>
+          <p>The <code>/api</code> routes serve the Ollama API on top of the same model pool, so tools that only speak Ollama, such as Continue, Raycast, and n8n, can use Kronk by pointing their Ollama host at <code>http://localhost:11435</code>. Requests are translated to the chat completions, completions, and embeddings paths and use their endpoint grants, priorities, and token budgets: <code>/api/chat</code> uses <code>chat-completions</code>, <code>/api/generate</code> uses <code>completions</code>, and <code>/api/embed</code> uses <code>embeddings</code>. <code>/api/tags</code>, <code>/api/show</code>, and <code>/api/ps</code> follow model discovery access, and <code>/api/pull</code> is an administration route.</p>
+          <p>Model names are Kronk model IDs. A <code>:latest</code> tag is accepted and ignored, and listed models carry it so clients that expect tagged names match them:</p>
+          <pre className="code-block"><code className="language-shell">{`curl http://localhost:11435/api/chat -d '{
+  "model": "Qwen/Qwen3-8B-Q8_0:latest",
+  "messages": [{"role": "user", "content": "Why is the sky blue?"}],
+  "options": {"temperature": 0.2, "num_predict": 256}
+}'`}</code></pre>
+          <p>Responses stream by default as newline-delimited JSON (<code>application/x-ndjson</code>), one object per line, ending with a line whose <code>done</code> is <code>true</code>. That line holds <code>done_reason</code> (<code>stop</code> or <code>length</code>) and the <code>total_duration</code>, <code>load_duration</code>, <code>prompt_eval_count</code>, <code>prompt_eval_duration</code>, <code>eval_count</code>, and <code>eval_duration</code> metrics in nanoseconds. Set <code>"stream": false</code> for a single response. Errors use Ollama's <code>&#123;"error": "message"&#125;</code> shape; an error after streaming starts is sent as a final error line.</p>
+          <table className="flags-table">
+            <thead>
+              <tr>
+                <th>Field</th>
+                <th>Kronk mapping</th>
+              </tr>
+            </thead>
+            <tbody>
+              <tr>
+                <td><code>options.temperature</code>, <code>top_k</code>, <code>top_p</code>, <code>min_p</code>, <code>repeat_penalty</code>, <code>repeat_last_n</code>, <code>presence_penalty</code>, <code>frequency_penalty</code>, <code>stop</code></td>
+                <td>Same-named sampling parameter</td>
+              </tr>
+              <tr>
+                <td><code>options.num_predict</code></td>
+                <td><code>max_tokens</code>; a negative value means no limit</td>
+              </tr>
+              <tr>
+                <td><code>options.seed</code></td>
+                <td><code>seed</code>; a negative value means a random seed</td>
+              </tr>
+              <tr>
+                <td><code>options.num_ctx</code>, <code>num_gpu</code>, <code>num_thread</code>, and other load options</td>
+                <td>Ignored; the model configuration sets them</td>
+              </tr>
+              <tr>
+                <td><code>format</code></td>
+                <td><code>"json"</code> sets a JSON object response; a JSON schema sets <code>json_schema</code></td>
+              </tr>
+              <tr>
+                <td><code>think</code></td>
+                <td><code>true</code> or <code>false</code> sets <code>enable_thinking</code>; <code>low</code>, <code>medium</code>, or <code>high</code> also sets <code>reasoning_effort</code></td>
+              </tr>
+              <tr>
+                <td><code>images</code></td>
+                <td>Base64 images, sent before the message text</td>
+              </tr>
+              <tr>
+                <td><code>keep_alive</code></td>
+                <td>Ignored; <code>--pool-ttl</code> controls unloading</td>
+              </tr>
+            </tbody>
+          </table>
+          <p><code>/api/chat</code> accepts <code>tools</code> in the OpenAI function shape Ollama uses, and returns tool calls with object <code>arguments</code>. Tool result messages are matched to the preceding calls by <code>tool_name</code>. Reasoning is returned in <code>message.thinking</code>.</p>
+          <p><code>/api/generate</code> applies the chat template to <code>prompt</code> and <code>system</code>. Set <code>raw</code> to send the prompt as is, or send <code>suffix</code> for fill-in-the-middle completion; both use the completions path described in 9.6. Custom <code>template</code> values are not supported. A chat request without messages or a generate request without a prompt loads the model and returns <code>done_reason</code> <code>load</code>.</p>
+          <p><code>/api/embed</code> accepts a string or an array of strings in <code>input</code>, with <code>truncate</code> (default <code>true</code>) and <code>dimensions</code>, and returns <code>embeddings</code>.</p>
+          <p><code>/api/tags</code> lists the local models the token may use. <code>/api/show</code> returns the model's details, chat <code>template</code>, default <code>parameters</code>, GGUF <code>model_info</code>, and <code>capabilities</code>. <code>/api/ps</code> lists the loaded models with their memory use and expiry. <code>/api/pull</code> downloads a catalog ID or URL and streams <code>&#123;"status", "digest", "total", "completed"&#125;</code> progress lines for each file, then <code>&#123;"status": "success"&#125;</code>. Digests identify the model ID; Kronk does not store Ollama manifests.</p>
+          <h2 id="914-kronk-administration">9.14 Kronk Administration</h2>
           <p>These routes manage the llama.cpp runtime, local GGUF models, and the personal model catalog. Mutating routes may stream progress or perform network and disk operations. Clients should use the exact <code>/v1/kronk/...</code> prefix; the shorter <code>/v1/libs</code>, <code>/v1/models/pull</code>, and <code>/v1/catalog</code> forms are not aliases.</p>
           <h3 id="libraries">Libraries</h3>
           <table className="flags-table">
@@ -2228,6 +2885,18 @@ data: {"type":"response.completed",...}`}</code></pre>
                 <td><code>GET /v1/kronk/models/&#123;model&#125;</code></td>
                 <td>Show detailed metadata and effective configuration for one model</td>
               </tr>
+              <tr>
+                <td><code>GET /v1/kronk/models/adapters/&#123;model&#125;</code></td>
+                <td>List the LoRA adapters loaded against a loaded model</td>
+              </tr>
+              <tr>
+                <td><code>POST /v1/kronk/models/adapters/&#123;model&#125;</code></td>
+                <td>Load a LoRA adapter against a model, loading the model if needed</td>
+              </tr>
+              <tr>
+                <td><code>DELETE /v1/kronk/models/adapters/&#123;model&#125;/&#123;id&#125;</code></td>
+                <td>Unload an adapter loaded at runtime</td>
+              </tr>
               <tr>
                 <td><code>GET /v1/kronk/models/ps</code></td>
                 <td>List models currently loaded in the pool</td>
@@ -2236,6 +2905,14 @@ data: {"type":"response.completed",...}`}</code></pre>
                 <td><code>GET /v1/kronk/models/imc-sessions</code></td>
                 <td>List active incremental-message-cache sessions</td>
               </tr>
+              <tr>
+                <td><code>POST /v1/kronk/models/imc-sessions/export</code></td>
+                <td>Download one IMC session's snapshot and metadata as a file</td>
+              </tr>
+              <tr>
+                <td><code>POST /v1/kronk/models/imc-sessions/import?model=&#123;model&#125;</code></td>
+                <td>Restore an exported IMC session file into a loaded model</td>
+              </tr>
               <tr>
                 <td><code>POST /v1/kronk/models/index</code></td>
                 <td>Rebuild the local model index</td>
@@ -2445,7 +3122,7 @@ data: {"type":"response.completed",...}`}</code></pre>
             </tbody>
           </table>
           <p><code>POST /v1/kronk/catalog/lookup</code> accepts <code>&#123;"input":"..."&#125;</code>. The resolve route accepts <code>&#123;"source":"..."&#125;</code> and may add successfully resolved metadata to the personal catalog even though it does not download model files.</p>
-          <h2 id="911-bucky-administration">9.11 Bucky Administration</h2>
+          <h2 id="915-bucky-administration">9.15 Bucky Administration</h2>
           <p>The Bucky management API mirrors the library and model lifecycle for the whisper.cpp backend:</p>
           <table className="flags-table">
             <thead>
@@ -2498,7 +3175,7 @@ data: {"type":"response.completed",...}`}</code></pre>
             </tbody>
           </table>
           <p>See <a href="https://www.kronkai.com/manual#chapter-18-bucky-audio-transcription">Chapter 18</a> for installation, model naming, transcription formats, and Bucky-specific runtime behavior.</p>
-          <h2 id="912-operations-and-evaluation">9.12 Operations and Evaluation</h2>
+          <h2 id="916-operations-and-evaluation">9.16 Operations and Evaluation</h2>
           <table className="flags-table">
             <thead>
               <tr>
@@ -2537,7 +3214,7 @@ data: {"type":"response.completed",...}`}</code></pre>
           <ol>
             <li>These evaluation routes can load models and may take several minutes.</li>
           </ol>
-          <h2 id="913-security-administration">9.13 Security Administration</h2>
+          <h2 id="917-security-administration">9.17 Security Administration</h2>
           <table className="flags-table">
             <thead>
               <tr>
@@ -2548,7 +3225,19 @@ data: {"type":"response.completed",...}`}</code></pre>
             <tbody>
               <tr>
                 <td><code>POST /v1/security/token/create</code></td>
-                <td>Create a token with administrator status or endpoint grants and quotas</td>
+                <td>Create a token with administrator status or endpoint grants, quotas, and model scopes</td>
+              </tr>
+              <tr>
+                <td><code>POST /v1/security/token/revoke</code></td>
+                <td>Revoke a single token by its ID or by the token itself</td>
+              </tr>
+              <tr>
+                <td><code>POST /v1/security/token/introspect</code></td>
+                <td>Report a token's claims, expiry, revocation, and remaining rate limits and token budgets</td>
+              </tr>
+              <tr>
+                <td><code>GET /v1/security/tokens</code></td>
+                <td>List issued and revoked tokens that have not expired</td>
               </tr>
               <tr>
                 <td><code>GET /v1/security/keys</code></td>
@@ -2562,9 +3251,13 @@ data: {"type":"response.completed",...}`}</code></pre>
                 <td><code>POST /v1/security/keys/remove/&#123;keyid&#125;</code></td>
                 <td>Remove a non-master signing key and revoke its tokens</td>
               </tr>
+              <tr>
+                <td><code>GET /v1/security/quotas/&#123;subject&#125;</code></td>
+                <td>Show the token budget use of a token subject in the current windows</td>
+              </tr>
             </tbody>
           </table>
-          <p>These routes are authorized by the authentication service. In protected modes they require an administrator token. Token creation accepts <code>admin</code>, <code>duration</code>, and an <code>endpoints</code> map of endpoint grants and rate limits. See <a href="https://www.kronkai.com/manual#chapter-12-security-and-authentication">Chapter 12</a> for the request model, key rotation, and the effects of deleting a signing key.</p>
+          <p>These routes are authorized by the authentication service. In protected modes they require an administrator token. Token creation accepts <code>admin</code>, <code>duration</code>, an <code>endpoints</code> map of endpoint grants and rate limits, and an optional scheduling <code>priority</code>. See <a href="https://www.kronkai.com/manual#chapter-12-security-and-authentication">Chapter 12</a> for the request model, key rotation, and the effects of deleting a signing key.</p>
           <h2 id="chapter-10-request-parameters">Chapter 10: Request Parameters</h2>
           <p>This chapter covers generation parameters used by Chat Completions and the Go SDK. Other API formats expose compatible subsets or translate their own field names into these parameters. See <a href="https://www.kronkai.com/manual#chapter-9-api-endpoints">Chapter 9</a> for endpoint-specific request formats and streaming behavior.</p>
           <h2 id="101-scope-and-defaults">10.1 Scope and Defaults</h2>
@@ -2780,11 +3473,25 @@ data: {"type":"response.completed",...}`}</code></pre>
                 <td>template default</td>
                 <td>Requests a model-specific reasoning level, commonly <code>none</code>, <code>minimal</code>, <code>low</code>, <code>medium</code>, <code>high</code>, or <code>xhigh</code>.</td>
               </tr>
+              <tr>
+                <td><code>truncation</code></td>
+                <td>string</td>
+                <td><code>disabled</code></td>
+                <td>Shortens a text prompt that does not fit the context window: <code>disabled</code>, <code>auto</code>, or <code>middle-out</code>.</td>
+              </tr>
+              <tr>
+                <td><code>adapters</code></td>
+                <td>array</td>
+                <td>configured adapters</td>
+                <td>LoRA adapters to apply, as <code>&#123;"id": ..., "scale": ...&#125;</code> objects; <code>scale</code> defaults to <code>1</code>.</td>
+              </tr>
             </tbody>
           </table>
           <p>If neither the request nor model configuration supplies a positive output limit, Kronk uses the model's configured context window. The actual output can be shorter because the prompt and generated text share that window, the model can stop naturally, or another limit can end generation. See Chapter 9 for the limit and termination fields returned by each API format.</p>
           <p>Reasoning controls are model- and template-dependent. Kronk accepts any string for <code>reasoning_effort</code> so newer templates can add levels without requiring a server change. Unsupported models may ignore the value, a parser can normalize it, and a strict template can reject values it does not support.</p>
           <p>When <code>reasoning_effort</code> is omitted from both the request and model configuration, Kronk leaves it undefined so the selected chat template can apply its native default.</p>
+          <p><code>truncation</code> defaults to the model's <code>truncation</code> setting. With <code>disabled</code>, a prompt that does not fit is rejected. <code>auto</code> drops the oldest turns and <code>middle-out</code> first shortens long tool results; see <a href="https://www.kronkai.com/manual#33-core-runtime-settings">Chapter 3 §3.3</a> for the exact rules. The Responses API's <code>truncation</code> field uses the same values.</p>
+          <p><code>adapters</code> selects LoRA adapters loaded against the model by ID. Omitting it applies the adapters at their configured scales, and an empty list runs the base model alone. An unknown ID rejects the request. Requests that use different adapter sets cannot share a batch, so they take turns on the model; see <a href="https://www.kronkai.com/manual#lora-adapters">Chapter 3 §3.7</a>.</p>
           <h2 id="106-structured-output">10.6 Structured Output</h2>
           <p>Kronk can convert JSON Schema to a GBNF grammar and constrain emitted tokens. For OpenAI-compatible clients, prefer <code>response_format</code>:</p>
           <pre className="code-block"><code className="language-json">{`{
@@ -2808,6 +3515,54 @@ data: {"type":"response.completed",...}`}</code></pre>
   }
 }`}</code></pre>
           <p>Supported <code>response_format.type</code> values are <code>text</code>, <code>json_object</code>, and <code>json_schema</code>. Kronk also accepts a schema directly in the top-level <code>json_schema</code> field and accepts a custom GBNF string in <code>grammar</code>. Use one structured-output mechanism per request.</p>
+          <p>The converter covers the commonly used JSON Schema keywords:</p>
+          <table className="flags-table">
+            <thead>
+              <tr>
+                <th>Keywords</th>
+                <th>Behavior</th>
+              </tr>
+            </thead>
+            <tbody>
+              <tr>
+                <td><code>type</code>, <code>nullable</code></td>
+                <td>Type arrays such as <code>["string", "null"]</code> allow any listed type.</td>
+              </tr>
+              <tr>
+                <td><code>properties</code>, <code>required</code>, <code>additionalProperties</code></td>
+                <td>Required keys come first, then optional keys, each in sorted order. Extra keys are allowed only when <code>additionalProperties</code> is <code>true</code> or a schema.</td>
+              </tr>
+              <tr>
+                <td><code>$ref</code></td>
+                <td>Local references such as <code>#/$defs/node</code> and <code>#/definitions/node</code>, including recursive ones.</td>
+              </tr>
+              <tr>
+                <td><code>anyOf</code>, <code>oneOf</code>, <code>allOf</code></td>
+                <td><code>anyOf</code> and <code>oneOf</code> accept any alternative; <code>oneOf</code> exclusivity is not enforced. <code>allOf</code> merges its members.</td>
+              </tr>
+              <tr>
+                <td><code>enum</code>, <code>const</code></td>
+                <td>Exact JSON values.</td>
+              </tr>
+              <tr>
+                <td><code>items</code>, <code>prefixItems</code>, <code>minItems</code>, <code>maxItems</code></td>
+                <td>Item schemas, tuples, and item counts.</td>
+              </tr>
+              <tr>
+                <td><code>minLength</code>, <code>maxLength</code>, <code>pattern</code></td>
+                <td>Character counts and regular expressions without backreferences, lookaround, or word boundaries.</td>
+              </tr>
+              <tr>
+                <td><code>minimum</code>, <code>maximum</code>, <code>exclusiveMinimum</code>, <code>exclusiveMaximum</code></td>
+                <td>Exact ranges for <code>integer</code>. A <code>number</code> supports only <code>minimum: 0</code>.</td>
+              </tr>
+              <tr>
+                <td><code>format</code></td>
+                <td><code>date</code>, <code>time</code>, <code>date-time</code>, <code>uuid</code>, <code>email</code>, and <code>ipv4</code>.</td>
+              </tr>
+            </tbody>
+          </table>
+          <p>Annotations such as <code>title</code>, <code>description</code>, and <code>default</code> are ignored. By default any other keyword, format, or bound is ignored too, and the affected value is generated without that constraint. Set <code>"strict": true</code> inside <code>response_format.json_schema</code> to have Kronk reject the request with a 400 error naming the unsupported keyword and its location in the schema instead.</p>
           <p>When a constraint is present and <code>enable_thinking</code> is omitted, Kronk disables thinking automatically so free-form reasoning does not precede the structured answer. Explicitly enabling thinking overrides that default, but is generally counterproductive for constrained output.</p>
           <p>A grammar restricts which tokens can be emitted; it does not guarantee a complete result. A response cut short by <code>max_tokens</code>, context limits, or cancellation can still contain an incomplete JSON value.</p>
           <h2 id="107-token-log-probabilities">10.7 Token Log Probabilities</h2>
@@ -2857,14 +3612,14 @@ data: {"type":"response.completed",...}`}</code></pre>
               </tr>
             </tbody>
           </table>
-          <p>For REST requests, prefer an ordered content array containing text and one or more media parts. Media values can be base64 data URLs or raw base64. Despite the <code>image_url</code> and <code>video_url</code> field names, Kronk does not fetch <code>http://</code> or <code>https://</code> URLs.</p>
+          <p>For REST requests, prefer an ordered content array containing text and one or more media parts. Media values can be base64 data URLs or raw base64. Kronk downloads <code>http://</code> and <code>https://</code> URLs only when the server enables media fetching; see <a href="#115-fetching-media-urls">§11.5</a>.</p>
           <p>Kronk also recognizes a plain base64 string used as the entire message <code>content</code>. That legacy form is less useful because it cannot place text and media together in one ordered content array.</p>
           <p>Actual video containers such as MP4 and WebM are not decoded by the current media path. For video analysis, extract frames and send them as supported images in the intended order.</p>
           <h2 id="113-sending-an-image">11.3 Sending an Image</h2>
           <p>Place media before the question unless the selected model documents another order. Several multimodal templates were trained with the media token first, and Kronk preserves the order of all content parts.</p>
-          <blockquote><strong>Note:</strong> Despite the <code>image_url</code> field name, Kronk does not download images</blockquote>
-          <blockquote>from <code>http://</code> or <code>https://</code> URLs. The client must download the image and send</blockquote>
-          <blockquote>it as a base64 data URL or raw base64 value.</blockquote>
+          <blockquote><strong>Note:</strong> Unless the server enables media fetching, Kronk does not download</blockquote>
+          <blockquote>images from <code>http://</code> or <code>https://</code> URLs. The client must download the image</blockquote>
+          <blockquote>and send it as a base64 data URL or raw base64 value.</blockquote>
           <p>This shell example expands the base64 value before sending the request:</p>
           <pre className="code-block"><code className="language-shell">{`IMAGE_B64=$(base64 < photo.jpg | tr -d '\\n')
 
@@ -2900,21 +3655,67 @@ EOF`}</code></pre>
 }`}</code></pre>
           <p>Put this part before the text question. The <code>format</code> field is accepted for client compatibility, but Kronk currently determines the actual format from the decoded bytes rather than this value.</p>
           <p>Use a multimodal chat model when you need conversational questions, summaries, or reasoning about audio. For a dedicated speech-to-text API, use Bucky's <code>POST /v1/audio/transcriptions</code> endpoint described in <a href="https://www.kronkai.com/manual#1861-request-and-response">Chapter 18</a>.</p>
-          <h2 id="115-go-sdk-helpers">11.5 Go SDK Helpers</h2>
+          <h2 id="115-fetching-media-urls">11.5 Fetching Media URLs</h2>
+          <p>Most OpenAI clients send images as URLs. Media fetching is off by default; with <code>--media-fetch-enabled</code> (see <a href="https://www.kronkai.com/manual#chapter-8-model-server">Chapter 8</a>), Kronk downloads <code>http://</code> and <code>https://</code> URLs in these parts before the request reaches the model:</p>
+          <table className="flags-table">
+            <thead>
+              <tr>
+                <th>Part</th>
+                <th>URL field</th>
+              </tr>
+            </thead>
+            <tbody>
+              <tr>
+                <td><code>image_url</code></td>
+                <td><code>image_url.url</code></td>
+              </tr>
+              <tr>
+                <td><code>video_url</code></td>
+                <td><code>video_url.url</code></td>
+              </tr>
+              <tr>
+                <td><code>input_audio</code></td>
+                <td><code>input_audio.data</code></td>
+              </tr>
+              <tr>
+                <td>Responses <code>input_image</code></td>
+                <td><code>image_url</code></td>
+              </tr>
+              <tr>
+                <td>Anthropic <code>image</code> with a <code>url</code> source</td>
+                <td><code>source.url</code></td>
+              </tr>
+            </tbody>
+          </table>
+          <pre className="code-block"><code className="language-json">{`{
+  "type": "image_url",
+  "image_url": {"url": "https://example.com/chart.png"}
+}`}</code></pre>
+          <p>The downloaded bytes must match a supported container from §11.2: an image for <code>image_url</code> and <code>input_image</code>, and audio for <code>input_audio</code>. A failed download, a non-200 response, or content that is not supported media is returned as a <code>400</code> error that names the URL.</p>
+          <p>Because the server makes the request, fetching is limited to prevent server-side request forgery:</p>
+          <ul>
+            <li><code>--media-fetch-allowed-hosts</code> lists host names, <code><em>.example.com&lt;/code&gt; patterns that match subdomains, and CIDR blocks; &lt;code&gt;</em></code> allows any public host. Other hosts are refused, and when the list is empty nothing is fetched, so media URLs are rejected as if fetching were off.</li>
+            <li>Addresses in private, loopback, link-local, carrier-grade NAT, and multicast ranges are refused unless <code>--media-fetch-allow-private</code> is set or the address falls in an allowed CIDR block. The check applies to the resolved address of every connection, so it covers redirects and host names that resolve to internal addresses.</li>
+            <li>Environment proxy settings are ignored, redirects are limited to five, and only <code>http</code> and <code>https</code> are followed.</li>
+            <li><code>--media-fetch-max-bytes</code> and <code>--media-fetch-timeout</code> bound each download.</li>
+          </ul>
+          <p>With <code>--media-fetch-cache</code>, media served with an <code>ETag</code> is kept under <code>&lt;base&gt;/media-cache</code> and revalidated with <code>If-None-Match</code> on later requests. Once the cache holds more than <code>--media-fetch-cache-max-bytes</code> (1 GiB by default), the least recently used media is removed. Media larger than the limit is not cached, and the cache can be deleted at any time.</p>
+          <p>Stored Responses keep the original URL, so a chained request fetches it again.</p>
+          <h2 id="116-go-sdk-helpers">11.6 Go SDK Helpers</h2>
           <p>Go applications can read media into a byte slice and use:</p>
           <ul>
             <li><code>model.ImageMessage(question, image, format)</code>; or</li>
             <li><code>model.AudioMessage(question, audio, format)</code>.</li>
           </ul>
           <p>These helpers create one user turn with media before text. <code>model.VideoMessage</code> constructs a <code>video_url</code> part, but it does not add video-container decoding; send extracted frames with <code>ImageMessage</code> for the current media path.</p>
-          <h2 id="116-configuration-and-resources">11.6 Configuration and Resources</h2>
+          <h2 id="117-configuration-and-resources">11.7 Configuration and Resources</h2>
           <p>Multimodal requests use the same batch engine and concurrency controls as text requests. The projector adds weights and runtime buffers, while image resolution, audio duration, context length, and <code>nseq-max</code> affect resource use. Use the BUI VRAM Calculator rather than adding model, projector, and KV file sizes as a complete memory estimate. See <a href="https://www.kronkai.com/manual#36-memory-planning-and-quantization">Chapter 3 §3.6</a> for memory planning and <a href="https://www.kronkai.com/manual#chapter-4-batch-processing">Chapter 4</a> for concurrency.</p>
           <p>Most deployments should leave <code>prefill-batch-size</code> at its 2048-token default. Kronk adds the model's per-slot generation reserve when deriving llama.cpp's internal batch capacities. A multimodal encoder may require an entire media-token chunk to fit in one physical batch, so lowering <code>prefill-batch-size</code> below that capacity can break media input. <code>proj-on-cpu: true</code> can keep the projector on the CPU when accelerator memory is constrained, at a performance cost.</p>
-          <h2 id="117-message-caching">11.7 Message Caching</h2>
+          <h2 id="118-message-caching">11.8 Message Caching</h2>
           <p>Incremental Message Caching can reuse unchanged media state for text-only follow-up turns without encoding the media again. Changing, reordering, removing, or appending media rebuilds the stable media plan through the multimodal pipeline. See <a href="https://www.kronkai.com/manual#54-media-requests">Chapter 5 §5.4</a> for the cache behavior and limitations.</p>
-          <h2 id="118-limitations">11.8 Limitations</h2>
+          <h2 id="119-limitations">11.9 Limitations</h2>
           <ul>
-            <li>Media must be embedded as base64; Kronk does not fetch remote URLs.</li>
+            <li>Media must be embedded as base64 unless the server enables media fetching.</li>
             <li>The current path accepts image and audio containers, not video containers.</li>
             <li>The selected model and projector must support the detected modality.</li>
             <li>Image resolution, media count, and audio duration affect latency and memory.</li>
@@ -3000,13 +3801,17 @@ kronk server start`}</code></pre>
                 <td><code>chat-completions</code></td>
                 <td><code>POST /v1/chat/completions</code></td>
               </tr>
+              <tr>
+                <td><code>completions</code></td>
+                <td><code>POST /v1/completions</code>, <code>/v1/infill</code>, and <code>/infill</code></td>
+              </tr>
               <tr>
                 <td><code>responses</code></td>
-                <td><code>POST /v1/responses</code></td>
+                <td><code>POST /v1/responses</code> and the stored response routes under <code>/v1/responses/&#123;id&#125;</code></td>
               </tr>
               <tr>
                 <td><code>messages</code></td>
-                <td><code>POST /v1/messages</code></td>
+                <td><code>POST /v1/messages</code> and <code>/v1/messages/count_tokens</code></td>
               </tr>
               <tr>
                 <td><code>embeddings</code></td>
@@ -3022,7 +3827,23 @@ kronk server start`}</code></pre>
               </tr>
               <tr>
                 <td><code>transcriptions</code></td>
-                <td><code>POST /v1/audio/transcriptions</code></td>
+                <td><code>POST /v1/audio/transcriptions</code> and <code>GET /v1/realtime</code></td>
+              </tr>
+              <tr>
+                <td><code>translations</code></td>
+                <td><code>POST /v1/audio/translations</code></td>
+              </tr>
+              <tr>
+                <td><code>images</code></td>
+                <td><code>POST /v1/images/generations</code> and <code>/v1/images/edits</code></td>
+              </tr>
+              <tr>
+                <td><code>files</code></td>
+                <td><code>/v1/files</code> and the file routes under <code>/v1/files/&#123;id&#125;</code></td>
+              </tr>
+              <tr>
+                <td><code>batches</code></td>
+                <td><code>/v1/batches</code> and the batch routes under <code>/v1/batches/&#123;id&#125;</code>; creating a batch also needs the grant of its lines' endpoint</td>
               </tr>
             </tbody>
           </table>
@@ -3036,6 +3857,67 @@ endpoint:limit/window`}</code></pre>
   --duration 720h \\
   --endpoints "chat-completions:1000/day,embeddings:500/month,responses:unlimited"`}</code></pre>
           <p>Kronk counts admitted requests by token subject and endpoint. Counters are stored in <code>~/.kronk/badger/</code>, survive server restarts, and expire after their current window. Admin tokens do not use these counters.</p>
+          <p>Request counts treat a short chat and a 100k-token agent turn alike. To cap what a token actually consumes, give it token budgets with <code>--token-budgets</code>. Each entry has one of these forms:</p>
+          <pre className="code-block"><code className="language-text">{`input/output/window
+model=input/output/window`}</code></pre>
+          <p>An entry without a model is shared by every model; an entry with a model applies only to requests for that model ID, in addition to any shared budget. A <code>0</code> input or output leaves that direction uncapped. For example, 2M input and 500k output tokens a day across all models, plus at most 100k output tokens a month from one model:</p>
+          <pre className="code-block"><code className="language-shell">{`kronk security token create \\
+  --duration 720h \\
+  --endpoints chat-completions,responses,messages \\
+  --token-budgets "2000000/500000/day,Qwen3-8B-Q8_0=0/100000/month"`}</code></pre>
+          <p>Token budgets apply to <code>chat-completions</code>, <code>completions</code>, <code>responses</code>, and <code>messages</code>, and unlike request counts they also apply to admin tokens that carry them. Before a request runs, Kronk checks every budget that applies to its model and answers <code>429 Too Many Requests</code> with a <code>Retry-After</code> header, in seconds, once any of them is used up. After the response completes, streamed or not, the prompt and completion tokens reported in its usage are charged to those budgets. A request is admitted while any tokens remain, so the last request of a window can carry a budget past its limit; the overrun still counts, and the next request is rejected.</p>
+          <p>Admitted requests report the budget with the fewest tokens left in each direction, measured before the request is charged:</p>
+          <table className="flags-table">
+            <thead>
+              <tr>
+                <th>Header</th>
+                <th>Value</th>
+              </tr>
+            </thead>
+            <tbody>
+              <tr>
+                <td><code>x-ratelimit-limit-input-tokens</code></td>
+                <td>Input token limit of the tightest input budget</td>
+              </tr>
+              <tr>
+                <td><code>x-ratelimit-remaining-input-tokens</code></td>
+                <td>Input tokens left in its window</td>
+              </tr>
+              <tr>
+                <td><code>x-ratelimit-reset-input-tokens</code></td>
+                <td>Time until the window resets, such as <code>6h12m5s</code></td>
+              </tr>
+              <tr>
+                <td><code>x-ratelimit-limit-output-tokens</code></td>
+                <td>Output token limit of the tightest output budget</td>
+              </tr>
+              <tr>
+                <td><code>x-ratelimit-remaining-output-tokens</code></td>
+                <td>Output tokens left in its window</td>
+              </tr>
+              <tr>
+                <td><code>x-ratelimit-reset-output-tokens</code></td>
+                <td>Time until the window resets</td>
+              </tr>
+            </tbody>
+          </table>
+          <p>Usage is stored beside the request counters in <code>~/.kronk/badger/</code>. An administrator can inspect a token's remaining quota by its subject, the <code>sub</code> claim of the JWT:</p>
+          <pre className="code-block"><code className="language-shell">{`curl http://localhost:11435/v1/security/quotas/$SUBJECT \\
+  -H "Authorization: Bearer $KRONK_TOKEN"`}</code></pre>
+          <p>The response lists each budget the subject has used in its current window with its limits, used and remaining tokens, and reset time. Budgets with no usage in the current window are omitted.</p>
+          <p>Endpoint grants decide which APIs a token may call, not which models. To limit a token to some of the installed models, give it model IDs or glob patterns with <code>--models</code>:</p>
+          <pre className="code-block"><code className="language-shell">{`kronk security token create \\
+  --duration 720h \\
+  --endpoints chat-completions,embeddings \\
+  --models "*Qwen3-0.6B*,*Qwen3-8B*,embeddinggemma*"`}</code></pre>
+          <p>Patterns are matched against the <code>model</code> field exactly as the client sends it. <code><em>&lt;/code&gt; matches any run of characters including &lt;code&gt;/&lt;/code&gt; and &lt;code&gt;.&lt;/code&gt;, &lt;code&gt;?&lt;/code&gt; matches one character, and &lt;code&gt;[abc]&lt;/code&gt; and &lt;code&gt;&#123;a,b&#125;&lt;/code&gt; match sets and alternatives. Because &lt;code&gt;/v1/models&lt;/code&gt; lists IDs in &lt;code&gt;owner/name&lt;/code&gt; form while clients often send the bare name, start a pattern with &lt;code&gt;</em></code> to accept both. The <code>authorization.rego</code> policy checks the requested model once the handler has read the body, before the model is loaded, and answers <code>403 Forbidden</code> for any other model. Every inference endpoint is covered, including the realtime transcription socket.</p>
+          <p>A scoped token sees only its models in <code>GET /v1/models</code>, and <code>GET /v1/models/&#123;model&#125;</code> answers <code>404 Not Found</code> for the rest. Tokens without <code>--models</code> may use every model, and admin tokens ignore model patterns.</p>
+          <p>A token can also carry a scheduling priority of <code>low</code>, <code>normal</code>, or <code>high</code>:</p>
+          <pre className="code-block"><code className="language-shell">{`kronk security token create \\
+  --duration 720h \\
+  --endpoints chat-completions,responses \\
+  --priority low`}</code></pre>
+          <p>The claim replaces the server's per-endpoint default priority for that token's requests and is the highest priority the token can use; clients may still lower individual requests with <code>X-Kronk-Priority</code>. Tokens without the claim use the endpoint default. The token subject is also the tenant the batch engine shares slots across, so each token gets a fair share of a busy model. See <a href="https://www.kronkai.com/manual#chapter-4-batch-processing">Chapter 4</a> for how priorities and tenants are scheduled.</p>
           <h2 id="125-using-a-token">12.5 Using a Token</h2>
           <p>Send a token using the bearer authorization scheme:</p>
           <pre className="code-block"><code className="language-shell">{`export KRONK_TOKEN="<application-token>"
@@ -3047,14 +3929,29 @@ curl http://localhost:11435/v1/chat/completions \\
     "model": "unsloth/Qwen3-1.7B-UD-Q8_K_XL",
     "messages": [{"role": "user", "content": "Hello"}]
   }'`}</code></pre>
-          <p>Kronk verifies the signature, issuer, expiration, required admin status or endpoint grant, and quota before processing a protected request. Authentication, missing-grant, and exhausted-quota failures return <code>401 Unauthorized</code>, <code>403 Forbidden</code>, and <code>429 Too Many Requests</code>, respectively. Authentication service failures return <code>500 Internal Server Error</code>, while unavailable external auth services return <code>503 Service Unavailable</code>.</p>
+          <p>Kronk verifies the signature, issuer, expiration, required admin status or endpoint grant, and request quota before processing a protected request. Authentication, missing-grant, and exhausted-quota failures return <code>401 Unauthorized</code>, <code>403 Forbidden</code>, and <code>429 Too Many Requests</code>, respectively. Authentication service failures return <code>500 Internal Server Error</code>, while unavailable external auth services return <code>503 Service Unavailable</code>.</p>
           <h2 id="126-key-rotation-and-revocation">12.6 Key Rotation and Revocation</h2>
           <p>Security commands use the running server by default:</p>
           <pre className="code-block"><code className="language-shell">{`kronk security key list
 kronk security key create
-kronk security key delete --keyid "$KEY_ID"`}</code></pre>
+kronk security key delete --keyid "$KEY_ID"
+kronk security token revoke --id "$TOKEN_ID"`}</code></pre>
           <p><code>key create</code> generates a UUID-named private key. The newest key becomes the signing key for subsequently created tokens, while older public keys continue to verify existing tokens.</p>
-          <p>Kronk does not maintain an individual token revocation list. Deleting a key immediately invalidates every token signed by that key. Rotate safely by:</p>
+          <p>Every token Kronk issues carries a unique ID in its <code>jti</code> claim and is recorded, until it expires, in the Badger database beside the rate counters. List the recorded tokens, newest first, with:</p>
+          <pre className="code-block"><code className="language-shell">{`curl http://localhost:11435/v1/security/tokens \\
+  -H "Authorization: Bearer $KRONK_TOKEN"`}</code></pre>
+          <p>Each entry shows the token ID, subject, admin status, issue and expiry times, and whether it has been revoked. Tokens themselves are never stored.</p>
+          <p>To revoke a single leaked token without disturbing anyone else, pass its ID or the token itself:</p>
+          <pre className="code-block"><code className="language-shell">{`kronk security token revoke --id "$TOKEN_ID"
+kronk security token revoke --token "$LEAKED_TOKEN"`}</code></pre>
+          <p>or call <code>POST /v1/security/token/revoke</code> with <code>&#123;"id": "..."&#125;</code> or <code>&#123;"token": "..."&#125;</code>. Passing the token also revokes tokens this server did not record, such as tokens issued by another Kronk instance sharing the signing key, as long as the token still verifies. A revoked token fails authentication with <code>401 Unauthorized</code> from then on. The revocation is kept until the token would have expired anyway. Revoking the token in <code>KRONK_TOKEN</code> locks that administrator out, so keep another admin token at hand.</p>
+          <p>Tokens issued before token IDs were introduced, including a <code>master.jwt</code> created by an older release, have no <code>jti</code> and can only be revoked by deleting their signing key.</p>
+          <p>To see what a token can still do, introspect it:</p>
+          <pre className="code-block"><code className="language-shell">{`curl http://localhost:11435/v1/security/token/introspect \\
+  -H "Authorization: Bearer $KRONK_TOKEN" \\
+  -d '{"token": "<application-token>"}'`}</code></pre>
+          <p>The response reports <code>active</code>, the token ID, subject, admin status, issue and expiry times with the time left, revocation, priority, and, per endpoint grant, the requests used and remaining in the current window with its reset time, plus any token budgets as in <a href="#124-endpoint-grants-and-rate-limits">Section 12.4</a>. Introspection reads the counters without counting a request. A token that does not verify or has expired reports only <code>"active": false</code>; a revoked token reports its claims with <code>"active": false</code> and <code>"revoked": true</code>.</p>
+          <p>Deleting a key immediately invalidates every token signed by that key. Rotate safely by:</p>
           <ol>
             <li>Creating a new key.</li>
             <li>Issuing replacement tokens, which use the new key.</li>
@@ -3379,6 +4276,10 @@ llm = ChatOpenAI(
 
 response = llm.invoke("Explain quantum computing briefly.")
 print(response.content)`}</code></pre>
+          <h3 id="147-ollama-clients">14.7 Ollama Clients</h3>
+          <p>Tools that only speak the Ollama API, such as Continue, Raycast, and n8n's Ollama nodes, can use Kronk's <code>/api</code> routes. Set the tool's Ollama base URL to <code>http://localhost:11435</code> instead of Ollama's default port 11434, and pick a model from its model list. Models must be pulled with Kronk; <code>ollama pull</code> into an Ollama store is not used.</p>
+          <pre className="code-block"><code className="language-shell">{`curl http://localhost:11435/api/tags`}</code></pre>
+          <p>On a protected server, configure the tool to send the <code>Authorization: Bearer</code> header. The token needs the endpoint grants listed in <a href="https://www.kronkai.com/manual#913-ollama-api">Chapter 9</a>.</p>
           <hr />
           <p><em>Next: &lt;a href="https://www.kronkai.com/manual#chapter-15-observability"&gt;Chapter 15: Observability&lt;/a&gt;</em></p>
           <h2 id="chapter-15-observability">Chapter 15: Observability</h2>
@@ -3463,12 +4364,16 @@ curl http://localhost:11435/v1/readiness`}</code></pre>
               </tr>
               <tr>
                 <td>Requests</td>
-                <td><code>chat_requests_total</code>, <code>chat_errors_total</code>, <code>chat_request_duration_seconds</code>, <code>chat_queue_wait_seconds</code></td>
+                <td><code>chat_requests_total</code>, <code>chat_errors_total</code>, <code>chat_request_duration_seconds</code>, <code>chat_queue_wait_seconds</code>, <code>chat_queue_depth</code>, <code>chat_preemptions_total</code></td>
               </tr>
               <tr>
                 <td>Embedding/reranking</td>
                 <td><code>inference_requests_total</code>, <code>inference_request_duration_seconds</code>, <code>inference_active_requests</code></td>
               </tr>
+              <tr>
+                <td>Images</td>
+                <td><code>image_requests_total</code>, <code>image_request_duration_seconds</code>, <code>images_generated_total</code></td>
+              </tr>
               <tr>
                 <td>Sequence batching</td>
                 <td><code>batchseq_queue_wait_seconds</code>, <code>batchseq_items</code>, <code>batchseq_batches_total</code></td>
@@ -4012,7 +4917,8 @@ lsof -nP -iTCP:9000 -sTCP:LISTEN`}</code></pre>
           <h2 id="chapter-18-bucky-audio-transcription">Chapter 18: Bucky (Audio Transcription)</h2>
           <p>Bucky is Kronk's speech-to-text subsystem. It uses <a href="https://github.com/ggerganov/whisper.cpp"><code>whisper.cpp</code></a> and is available through:</p>
           <ul>
-            <li>the <code>/v1/audio/transcriptions</code> HTTP endpoint;</li>
+            <li>the <code>/v1/audio/transcriptions</code> and <code>/v1/audio/translations</code> HTTP endpoints;</li>
+            <li>the <code>/v1/realtime</code> WebSocket endpoint for live transcription;</li>
             <li>the Browser UI (BUI) Translator;</li>
             <li>the <code>kronk bucky</code> management commands; and</li>
             <li>the Go packages under <code>sdk/bucky</code>.</li>
@@ -4026,9 +4932,9 @@ lsof -nP -iTCP:9000 -sTCP:LISTEN`}</code></pre>
             <li>translate speech from a supported language into English;</li>
             <li>return plain text, JSON, SRT, or WebVTT;</li>
             <li>transcribe separate audio channels as separate speakers through the SDK; and</li>
-            <li>consume partial and final transcript events from live audio through the SDK.</li>
+            <li>consume partial and final transcript events from live audio over a WebSocket or through the SDK.</li>
           </ul>
-          <p>The HTTP endpoint follows the OpenAI audio transcription request shape. It is protected by Kronk's <code>transcriptions</code> authentication permission when server authentication is enabled.</p>
+          <p>The HTTP endpoint follows the OpenAI audio transcription request shape and the WebSocket endpoint follows OpenAI's realtime transcription session events. Both are protected by Kronk's <code>transcriptions</code> authentication permission when server authentication is enabled.</p>
           <p>Whisper models use GGML <code>.bin</code> files and are separate from the GGUF models used by Kronk's language-model backend. Bucky models and language models share the server's memory budget and pool controls.</p>
           <h3 id="182-install-whisper-libraries">18.2 Install Whisper Libraries</h3>
           <p>Install the default library bundle for the current host:</p>
@@ -4334,6 +5240,11 @@ kronk bucky model remove tiny`}</code></pre>
                 <td>No</td>
                 <td><code>json</code> (default), <code>verbose_json</code>, <code>text</code>, <code>srt</code>, or <code>vtt</code></td>
               </tr>
+              <tr>
+                <td><code>stream</code></td>
+                <td>No</td>
+                <td><code>true</code> streams the transcript as server-sent events; requires <code>json</code> or <code>text</code></td>
+              </tr>
               <tr>
                 <td><code>timestamp_granularities[]</code></td>
                 <td>No</td>
@@ -4353,7 +5264,146 @@ kronk bucky model remove tiny`}</code></pre>
           <pre className="code-block"><code className="language-json">{`{"text":"And so my fellow Americans..."}`}</code></pre>
           <p><code>verbose_json</code> adds the detected language, duration, and timestamped segments. When <code>timestamp_granularities[]=word</code> is requested, it also includes a <code>words</code> array whose entries contain <code>word</code>, <code>start</code>, and <code>end</code> fields. The <code>text</code>, <code>srt</code>, and <code>vtt</code> formats return their corresponding non-JSON media types.</p>
           <p>English-only models (<code>base.en</code>, <code>small.en</code>, and <code>medium.en</code>) only accept an empty language hint or <code>en</code>. Use a multilingual model for other languages or translation.</p>
-          <h4 id="1862-bucky-management-endpoints">18.6.2 Bucky Management Endpoints</h4>
+          <p><code>POST /v1/audio/translations</code> accepts the same fields and response formats and always translates the speech into English text. It requires a multilingual model and its own <code>translations</code> authentication permission.</p>
+          <p>With <code>stream=true</code> the server decodes the upload in 30-second chunks, cut at the quietest point near each boundary, and sends each decoded segment as soon as its chunk finishes. This lets clients show progress on long recordings:</p>
+          <pre className="code-block"><code className="language-text">{`data: {"delta":" And so my fellow Americans,","type":"transcript.text.delta"}
+
+data: {"delta":" ask not what your country can do for you.","type":"transcript.text.delta"}
+
+data: {"text":"And so my fellow Americans, ask not what your country can do for you.","type":"transcript.text.done"}`}</code></pre>
+          <p>Each <code>delta</code> is the text of one segment. Append the deltas in order; the <code>done</code> event carries the full text. A comment line is sent every 15 seconds while a chunk is decoding. An error before the first event is returned as a normal JSON error response. A later error is sent as a final <code>data:</code> event holding the error object.</p>
+          <h4 id="1862-realtime-transcription">18.6.2 Realtime Transcription</h4>
+          <p>Open a WebSocket to:</p>
+          <pre className="code-block"><code className="language-text">{`GET /v1/realtime?intent=transcription&model=tiny`}</code></pre>
+          <p>The <code>model</code> query parameter is optional when the first session update names the model. Send the token in the <code>Authorization</code> header. Browsers, which cannot set headers on a WebSocket handshake, may instead offer the subprotocols <code>realtime</code> and <code>openai-insecure-api-key.&lt;token&gt;</code>. Only <code>intent=transcription</code> is supported.</p>
+          <p>The server sends <code>transcription_session.created</code> when the socket opens. The client then sends JSON events:</p>
+          <table className="flags-table">
+            <thead>
+              <tr>
+                <th>Event</th>
+                <th>Purpose</th>
+              </tr>
+            </thead>
+            <tbody>
+              <tr>
+                <td><code>transcription_session.update</code></td>
+                <td>Change session settings; missing fields keep their value</td>
+              </tr>
+              <tr>
+                <td><code>input_audio_buffer.append</code></td>
+                <td>Append base64 <code>audio</code> in the session's input format</td>
+              </tr>
+              <tr>
+                <td><code>input_audio_buffer.commit</code></td>
+                <td>Transcribe the pending audio now as a final</td>
+              </tr>
+              <tr>
+                <td><code>input_audio_buffer.clear</code></td>
+                <td>Discard the pending audio</td>
+              </tr>
+            </tbody>
+          </table>
+          <p>Binary WebSocket messages are treated as raw audio and appended directly, which avoids the base64 overhead. The session object accepts these fields:</p>
+          <table className="flags-table">
+            <thead>
+              <tr>
+                <th>Field</th>
+                <th>Default</th>
+                <th>Purpose</th>
+              </tr>
+            </thead>
+            <tbody>
+              <tr>
+                <td><code>input_audio_format</code></td>
+                <td><code>pcm16</code></td>
+                <td><code>pcm16</code> (signed 16-bit little-endian) or <code>float32</code> (little-endian)</td>
+              </tr>
+              <tr>
+                <td><code>input_audio_sample_rate</code></td>
+                <td><code>24000</code></td>
+                <td>Kronk extension; input rate in Hz, resampled to 16 kHz</td>
+              </tr>
+              <tr>
+                <td><code>input_audio_channels</code></td>
+                <td><code>1</code></td>
+                <td>Kronk extension; interleaved channels, downmixed to mono</td>
+              </tr>
+              <tr>
+                <td><code>input_audio_transcription.model</code></td>
+                <td>query <code>model</code></td>
+                <td>Installed model ID</td>
+              </tr>
+              <tr>
+                <td><code>input_audio_transcription.language</code></td>
+                <td>auto-detect</td>
+                <td>Whisper language code</td>
+              </tr>
+              <tr>
+                <td><code>input_audio_transcription.prompt</code></td>
+                <td>empty</td>
+                <td>Biases the first decode window</td>
+              </tr>
+              <tr>
+                <td><code>turn_detection</code></td>
+                <td><code>&#123;"type":"server_vad"&#125;</code></td>
+                <td><code>server_vad</code> commits a final when the speaker pauses; <code>null</code> commits only on a fixed cadence or on <code>commit</code></td>
+              </tr>
+              <tr>
+                <td><code>turn_detection.threshold</code></td>
+                <td><code>0.6</code></td>
+                <td>Energy-ratio silence threshold between 0 and 1</td>
+              </tr>
+              <tr>
+                <td><code>partial_every_ms</code></td>
+                <td><code>1000</code></td>
+                <td>Kronk extension; partial cadence, negative disables partials</td>
+              </tr>
+              <tr>
+                <td><code>prompt_carryover</code></td>
+                <td><code>true</code></td>
+                <td>Kronk extension; carry text context across windows</td>
+              </tr>
+              <tr>
+                <td><code>translate</code></td>
+                <td><code>false</code></td>
+                <td>Kronk extension; translate to English</td>
+              </tr>
+            </tbody>
+          </table>
+          <p>The model is acquired from the pool on the first audio append and stays loaded until the socket closes. Settings are fixed while audio is buffered, so an update after audio has been appended first finalizes that audio under the old settings.</p>
+          <p>The server sends these events:</p>
+          <table className="flags-table">
+            <thead>
+              <tr>
+                <th>Event</th>
+                <th>Meaning</th>
+              </tr>
+            </thead>
+            <tbody>
+              <tr>
+                <td><code>conversation.item.input_audio_transcription.partial</code></td>
+                <td>Tentative <code>transcript</code> for the pending item; replace, do not append</td>
+              </tr>
+              <tr>
+                <td><code>input_audio_buffer.committed</code></td>
+                <td>The pending item is final</td>
+              </tr>
+              <tr>
+                <td><code>conversation.item.input_audio_transcription.completed</code></td>
+                <td>Final <code>transcript</code> for the item</td>
+              </tr>
+              <tr>
+                <td><code>input_audio_buffer.cleared</code></td>
+                <td>The buffer was emptied by <code>commit</code> or <code>clear</code></td>
+              </tr>
+              <tr>
+                <td><code>error</code></td>
+                <td>An OpenAI-shaped <code>error</code> object; the session stays open</td>
+              </tr>
+            </tbody>
+          </table>
+          <p>Partial and completed events include <code>audio_start_ms</code> and <code>audio_end_ms</code>. Unlike OpenAI's delta events, a partial carries the whole revisable hypothesis.</p>
+          <h4 id="1863-bucky-management-endpoints">18.6.3 Bucky Management Endpoints</h4>
           <p>The CLI and BUI use these management routes:</p>
           <table className="flags-table">
             <thead>
@@ -4435,6 +5485,14 @@ fmt.Println(tr.Text)`}</code></pre>
     model.WithLengthPenalty(-1),
 )`}</code></pre>
           <p>Only specify values that should override the defaults supplied by the loaded whisper.cpp library. <code>WithBeamSize</code> selects beam search; use it with <code>WithBeamSearchPatience</code> instead of <code>WithGreedyBestOf</code>. Without a positive beam size, decoding uses greedy sampling.</p>
+          <p>For long recordings, <code>WithChunkMs</code> decodes the audio in chunks and <code>WithOnSegment</code> reports each segment as soon as its chunk is decoded:</p>
+          <pre className="code-block"><code className="language-go">{`tr, err := b.TranscribeFile(ctx, f,
+    model.WithChunkMs(30000),
+    model.WithOnSegment(func(seg model.Segment) {
+        fmt.Printf("[%dms] %s\\n", seg.StartMs, seg.Text)
+    }),
+)`}</code></pre>
+          <p>Each cut lands on the quietest point near a chunk boundary. Later chunks are prompted with the end of the previous chunk and use the language detected in the first chunk. Timestamps are relative to the start of the audio.</p>
           <h4 id="1872-channel-separated-diarization">18.7.2 Channel-Separated Diarization</h4>
           <p><code>TranscribeChannelsFile</code> treats each source channel as a separate speaker and merges their timestamped segments:</p>
           <pre className="code-block"><code className="language-go">{`d, err := b.TranscribeChannelsFile(ctx, f, model.WithLanguage("en"))
@@ -4602,7 +5660,7 @@ if err := stream.FeedPCM(ctx, rawPCM, format); err != nil {
             <li>Perform work through the handle.</li>
             <li>Unload the handle.</li>
           </ol>
-          <p>Malina is currently an SDK and local tooling integration. It is <strong>not yet an inference backend in the Kronk model server</strong>. The CLI manages local libraries and model bundles, but there are no Malina HTTP generation endpoints, BUI management screens, or Malina model pool in this release. Model-server integration depends on reliable memory and VRAM planning for stable-diffusion model bundles.</p>
+          <p>The Kronk model server serves installed bundles through the OpenAI-compatible <code>/v1/images/generations</code> and <code>/v1/images/edits</code> endpoints described in <a href="https://www.kronkai.com/manual#912-models-audio-and-images">Chapter 9</a>. The server loads bundles into a Malina model pool that shares the memory budget and eviction rules of the Kronk and Bucky pools. There are no BUI management screens for Malina in this release.</p>
           <h3 id="192-install-stable-diffusion-libraries">19.2 Install Stable Diffusion Libraries</h3>
           <p>Install and validate the pinned stable-diffusion.cpp build for the current host:</p>
           <pre className="code-block"><code className="language-shell">{`kronk malina libs --local`}</code></pre>
@@ -4942,8 +6000,8 @@ fmt.Println(info.Description)`}</code></pre>
           <h3 id="1910-current-scope-and-limitations">19.10 Current Scope and Limitations</h3>
           <ul>
             <li>The public API is experimental and may change between Kronk releases.</li>
-            <li>Malina image inference is available through the Go SDK, not the Kronk model server or its HTTP API.</li>
-            <li>Malina models are not managed by the shared Kronk/Bucky model pool and do not yet participate in model-server RAM or VRAM admission and eviction.</li>
+            <li>The model server plans each bundle as the sum of its component files plus a fixed 1.5 GB generation overhead. The real footprint varies with the image size and the pipeline.</li>
+            <li>The model server loads one context per bundle, so image requests for the same bundle run one at a time.</li>
             <li>The curated catalog is intentionally small. The high-level SDK guarantees its listed component roles; arbitrary user-created bundle layouts are not a supported catalog contract.</li>
             <li>Native callbacks and backend initialization are process-wide. Model-context construction and destruction are serialized, while one handle may own multiple contexts and generate concurrently across them. Each concurrency slot loads another copy of the model and increases RAM or VRAM use.</li>
             <li>Context cancellation interrupts active native generation, waits for the native call to return, and resets the same context before reuse. It never frees a context while native code is active.</li>
@@ -5346,13 +6404,14 @@ Then one line per non-confirmed flag: \`Dismissed: <probe-id> — <reason>\`.`}<
           <p>Tracing should identify major waits and ownership boundaries: request handling, model acquisition/load, queue wait, prompt/prefill, generation, and unload when relevant. Keep spans concise. Avoid a span per token, duplicated nested timing, giant model-config attribute sets, prompt/media payloads, and unbounded IDs. Propagate the request context instead of creating unrelated roots. Logs and metrics should help distinguish queue, capacity, cancellation, and inference failures without exposing user content unless an explicit insecure-logging mode authorizes it.</p>
           <p>Embedding/reranking metrics distinguish the operation and selected runtime. Sequence- batch queue wait and batch width are engine-level measurements; request duration, active requests, status, and prompt-token usage are operation-level measurements. Resource reservations remain owned by the shared pool/resource manager and must not be duplicated inside either inference engine.</p>
           <h4 id="2066-speculative-decoding-and-mtp">20.6.6 Speculative decoding and MTP</h4>
-          <p>Speculative support has three ownership shapes:</p>
+          <p>Speculative support has four ownership shapes:</p>
           <ol>
             <li><strong>Separate GGUF draft model.</strong> The draft has its own model/context/KV and proposes tokens; the target verifies them. Loading, memory planning, sequence cleanup, and rollback must account for both models.</li>
             <li><strong>Embedded MTP.</strong> A target GGUF exposes an embedded multi-token-prediction head. Model detection and MTP construction are owned by <code>draft_mtp.go</code>/<code>batchgen_mtp.go</code>, while generic proposal verification and reconciliation remain in <code>batchgen_speculative.go</code>.</li>
             <li><strong>Separate-file Gemma4/shared-target-KV MTP.</strong> The MTP component is supplied as a separate file but shares target KV semantics rather than behaving like an ordinary independent draft model. Capabilities, not “has a draft path,” must decide whether draft KV can be trimmed or externalized.</li>
+            <li><strong>N-gram prompt lookup.</strong> <code>internal/speculation/ngram</code> proposes candidates from the slot's own prompt and output history and reuses classic verification and target rollback. There is no drafter or draft KV, so <code>m.draft</code> is nil while the controller is enabled; gate draft-resource work on <code>m.draft</code>, and speculation behavior on the controller.</li>
           </ol>
-          <p>Across all three, target output is authoritative. Proposal generation cannot expose a token until target verification accepts it or chooses the replacement/bonus token. Position counters, sampled-token history, target KV, draft/MTP state, and streamed output must describe one accepted prefix after every round.</p>
+          <p>Across all four, target output is authoritative. Proposal generation cannot expose a token until target verification accepts it or chooses the replacement/bonus token. Position counters, sampled-token history, target KV, draft/MTP state, and streamed output must describe one accepted prefix after every round.</p>
           <p>Verification in a multi-slot batch is explicitly read-before-mutate. First read all target logits/hidden-state rows and decide each slot's accepted prefix while the shared batch outputs are intact. Only then mutate KV, counters, slot buffers, stream output, or MTP mirror state. Mutating one slot during the read phase can invalidate indices or native output needed by another slot.</p>
           <p>Ordinary transformer KV can often remove a rejected suffix. Hybrid recurrent/state- space models cannot assume partial KV deletion restores prior state. Request bounded recurrent rollback through <code>NRsSeq</code> and use it only when the context reports enough effective rollback depth for the speculative round. Otherwise take a full pre-speculation per-sequence snapshot, and on rejection restore it and re-decode exactly the accepted prefix. Preserve captured target hidden-state rows needed to synchronize MTP. For own-KV MTP, rollback removes speculative draft state before mirroring accepted target state. For shared-target-KV Gemma4, do not apply independent-draft rollback to the shared target cache. If rollback, restore, or synchronization fails, fail the affected slot or safely disable MTP rather than retaining ambiguous state.</p>
           <p>Unit-level owners are the batch/speculative files and tests in <code>sdk/kronk/model/</code>. Model-backed MTP suites live in <code>sdk/kronk/tests/mtp</code> and <code>sdk/kronk/tests/gemma4mtp</code>; they are CI/human suites, not commands agents should launch from the forbidden integration-test tree.</p>
@@ -5554,6 +6613,9 @@ go test -count=1 -run 'TestSpecificBehavior' ./sdk/kronk/parsers/qwen`}</code></
             </div>
             <div className="doc-index-section">
               <a href="#53-sessions-slots-and-snapshots" className={`doc-index-header ${activeSection === '53-sessions-slots-and-snapshots' ? 'active' : ''}`}>5.3 Sessions, Slots, and Snapshots</a>
+              <ul>
+                <li><a href="#531-cache-keys-and-session-export" className={activeSection === '531-cache-keys-and-session-export' ? 'active' : ''}>5.3.1 Cache Keys and Session Export</a></li>
+              </ul>
             </div>
             <div className="doc-index-section">
               <a href="#54-media-requests" className={`doc-index-header ${activeSection === '54-media-requests' ? 'active' : ''}`}>5.4 Media Requests</a>
@@ -5562,6 +6624,7 @@ go test -count=1 -run 'TestSpecificBehavior' ./sdk/kronk/parsers/qwen`}</code></
               <a href="#55-configuration-and-storage" className={`doc-index-header ${activeSection === '55-configuration-and-storage' ? 'active' : ''}`}>5.5 Configuration and Storage</a>
               <ul>
                 <li><a href="#built-in-ram-storage" className={activeSection === 'built-in-ram-storage' ? 'active' : ''}>Built-in RAM storage</a></li>
+                <li><a href="#built-in-disk-storage" className={activeSection === 'built-in-disk-storage' ? 'active' : ''}>Built-in disk storage</a></li>
                 <li><a href="#custom-sdk-storage" className={activeSection === 'custom-sdk-storage' ? 'active' : ''}>Custom SDK storage</a></li>
               </ul>
             </div>
@@ -5656,19 +6719,34 @@ go test -count=1 -run 'TestSpecificBehavior' ./sdk/kronk/parsers/qwen`}</code></
               <a href="#95-anthropic-messages-api" className={`doc-index-header ${activeSection === '95-anthropic-messages-api' ? 'active' : ''}`}>9.5 Anthropic Messages API</a>
             </div>
             <div className="doc-index-section">
-              <a href="#96-embeddings" className={`doc-index-header ${activeSection === '96-embeddings' ? 'active' : ''}`}>9.6 Embeddings</a>
+              <a href="#96-completions-and-infill" className={`doc-index-header ${activeSection === '96-completions-and-infill' ? 'active' : ''}`}>9.6 Completions and Infill</a>
+            </div>
+            <div className="doc-index-section">
+              <a href="#97-files" className={`doc-index-header ${activeSection === '97-files' ? 'active' : ''}`}>9.7 Files</a>
+            </div>
+            <div className="doc-index-section">
+              <a href="#98-batches" className={`doc-index-header ${activeSection === '98-batches' ? 'active' : ''}`}>9.8 Batches</a>
+            </div>
+            <div className="doc-index-section">
+              <a href="#99-embeddings" className={`doc-index-header ${activeSection === '99-embeddings' ? 'active' : ''}`}>9.9 Embeddings</a>
             </div>
             <div className="doc-index-section">
-              <a href="#97-reranking" className={`doc-index-header ${activeSection === '97-reranking' ? 'active' : ''}`}>9.7 Reranking</a>
+              <a href="#910-reranking" className={`doc-index-header ${activeSection === '910-reranking' ? 'active' : ''}`}>9.10 Reranking</a>
             </div>
             <div className="doc-index-section">
-              <a href="#98-tokenization" className={`doc-index-header ${activeSection === '98-tokenization' ? 'active' : ''}`}>9.8 Tokenization</a>
+              <a href="#911-tokenization" className={`doc-index-header ${activeSection === '911-tokenization' ? 'active' : ''}`}>9.11 Tokenization</a>
             </div>
             <div className="doc-index-section">
-              <a href="#99-models-and-audio-transcription" className={`doc-index-header ${activeSection === '99-models-and-audio-transcription' ? 'active' : ''}`}>9.9 Models and Audio Transcription</a>
+              <a href="#912-models-audio-and-images" className={`doc-index-header ${activeSection === '912-models-audio-and-images' ? 'active' : ''}`}>9.12 Models, Audio, and Images</a>
+              <ul>
+                <li><a href="#image-generation" className={activeSection === 'image-generation' ? 'active' : ''}>Image generation</a></li>
+              </ul>
             </div>
             <div className="doc-index-section">
-              <a href="#910-kronk-administration" className={`doc-index-header ${activeSection === '910-kronk-administration' ? 'active' : ''}`}>9.10 Kronk Administration</a>
+              <a href="#913-ollama-api" className={`doc-index-header ${activeSection === '913-ollama-api' ? 'active' : ''}`}>9.13 Ollama API</a>
+            </div>
+            <div className="doc-index-section">
+              <a href="#914-kronk-administration" className={`doc-index-header ${activeSection === '914-kronk-administration' ? 'active' : ''}`}>9.14 Kronk Administration</a>
               <ul>
                 <li><a href="#libraries" className={activeSection === 'libraries' ? 'active' : ''}>Libraries</a></li>
                 <li><a href="#models" className={activeSection === 'models' ? 'active' : ''}>Models</a></li>
@@ -5676,13 +6754,13 @@ go test -count=1 -run 'TestSpecificBehavior' ./sdk/kronk/parsers/qwen`}</code></
               </ul>
             </div>
             <div className="doc-index-section">
-              <a href="#911-bucky-administration" className={`doc-index-header ${activeSection === '911-bucky-administration' ? 'active' : ''}`}>9.11 Bucky Administration</a>
+              <a href="#915-bucky-administration" className={`doc-index-header ${activeSection === '915-bucky-administration' ? 'active' : ''}`}>9.15 Bucky Administration</a>
             </div>
             <div className="doc-index-section">
-              <a href="#912-operations-and-evaluation" className={`doc-index-header ${activeSection === '912-operations-and-evaluation' ? 'active' : ''}`}>9.12 Operations and Evaluation</a>
+              <a href="#916-operations-and-evaluation" className={`doc-index-header ${activeSection === '916-operations-and-evaluation' ? 'active' : ''}`}>9.16 Operations and Evaluation</a>
             </div>
             <div className="doc-index-section">
-              <a href="#913-security-administration" className={`doc-index-header ${activeSection === '913-security-administration' ? 'active' : ''}`}>9.13 Security Administration</a>
+              <a href="#917-security-administration" className={`doc-index-header ${activeSection === '917-security-administration' ? 'active' : ''}`}>9.17 Security Administration</a>
             </div>
             <div className="doc-index-section">
               <a href="#chapter-10-request-parameters" className={`doc-index-header ${activeSection === 'chapter-10-request-parameters' ? 'active' : ''}`}>Chapter 10: Request Parameters</a>
@@ -5724,16 +6802,19 @@ go test -count=1 -run 'TestSpecificBehavior' ./sdk/kronk/parsers/qwen`}</code></
               <a href="#114-sending-audio" className={`doc-index-header ${activeSection === '114-sending-audio' ? 'active' : ''}`}>11.4 Sending Audio</a>
             </div>
             <div className="doc-index-section">
-              <a href="#115-go-sdk-helpers" className={`doc-index-header ${activeSection === '115-go-sdk-helpers' ? 'active' : ''}`}>11.5 Go SDK Helpers</a>
+              <a href="#115-fetching-media-urls" className={`doc-index-header ${activeSection === '115-fetching-media-urls' ? 'active' : ''}`}>11.5 Fetching Media URLs</a>
+            </div>
+            <div className="doc-index-section">
+              <a href="#116-go-sdk-helpers" className={`doc-index-header ${activeSection === '116-go-sdk-helpers' ? 'active' : ''}`}>11.6 Go SDK Helpers</a>
             </div>
             <div className="doc-index-section">
-              <a href="#116-configuration-and-resources" className={`doc-index-header ${activeSection === '116-configuration-and-resources' ? 'active' : ''}`}>11.6 Configuration and Resources</a>
+              <a href="#117-configuration-and-resources" className={`doc-index-header ${activeSection === '117-configuration-and-resources' ? 'active' : ''}`}>11.7 Configuration and Resources</a>
             </div>
             <div className="doc-index-section">
-              <a href="#117-message-caching" className={`doc-index-header ${activeSection === '117-message-caching' ? 'active' : ''}`}>11.7 Message Caching</a>
+              <a href="#118-message-caching" className={`doc-index-header ${activeSection === '118-message-caching' ? 'active' : ''}`}>11.8 Message Caching</a>
             </div>
             <div className="doc-index-section">
-              <a href="#118-limitations" className={`doc-index-header ${activeSection === '118-limitations' ? 'active' : ''}`}>11.8 Limitations</a>
+              <a href="#119-limitations" className={`doc-index-header ${activeSection === '119-limitations' ? 'active' : ''}`}>11.9 Limitations</a>
             </div>
             <div className="doc-index-section">
               <a href="#chapter-12-security-and-authentication" className={`doc-index-header ${activeSection === 'chapter-12-security-and-authentication' ? 'active' : ''}`}>Chapter 12: Security and Authentication</a>
@@ -5780,6 +6861,7 @@ go test -count=1 -run 'TestSpecificBehavior' ./sdk/kronk/parsers/qwen`}</code></
                 <li><a href="#144-python-openai-sdk" className={activeSection === '144-python-openai-sdk' ? 'active' : ''}>14.4 Python OpenAI SDK</a></li>
                 <li><a href="#145-curl-and-other-http-clients" className={activeSection === '145-curl-and-other-http-clients' ? 'active' : ''}>14.5 curl and Other HTTP Clients</a></li>
                 <li><a href="#146-langchain" className={activeSection === '146-langchain' ? 'active' : ''}>14.6 LangChain</a></li>
+                <li><a href="#147-ollama-clients" className={activeSection === '147-ollama-clients' ? 'active' : ''}>14.7 Ollama Clients</a></li>
               </ul>
             </div>
             <div className="doc-index-section">
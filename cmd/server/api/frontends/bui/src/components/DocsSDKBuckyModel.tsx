@@ -528,8 +528,17 @@ export default function DocsSDKBuckyModel() {
 
 	// OnSegment, when non-nil, is invoked once per decoded segment
 	// after Full returns. The callback is synchronous and runs on the
-	// caller's goroutine.
+	// caller's goroutine. With ChunkMs set it fires after each chunk,
+	// so callers see progress on long audio.
 	OnSegment func(Segment)
+
+	// ChunkMs, when > 0, decodes audio longer than ChunkMs as a series of
+	// consecutive chunks of about ChunkMs instead of a single Full call.
+	// Each cut lands on the quietest point near the chunk boundary, and
+	// each chunk after the first is prompted with the tail tokens of the
+	// previous one and pinned to the language detected in the first.
+	// Segment and word timestamps are relative to the start of the audio.
+	ChunkMs int
 }`}</code>
               </pre>
               <p className="doc-description">TranscribeConfig captures the per-call settings Transcribe consults. Defaults match the whisper.cpp greedy-sampling profile with progress / realtime printing disabled.</p>
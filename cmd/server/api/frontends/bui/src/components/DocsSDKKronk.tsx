@@ -41,6 +41,14 @@ export default function DocsSDKKronk() {
           <div className="card" id="functions">
             <h3>Functions</h3>
 
+            <div className="doc-section" id="func-applysessionheader">
+              <h4>ApplySessionHeader</h4>
+              <pre className="code-block">
+                <code>func ApplySessionHeader(h http.Header, d model.D)</code>
+              </pre>
+              <p className="doc-description">ApplySessionHeader copies the SessionHeader value from h into d as prompt_cache_key. A key set in the request document takes precedence.</p>
+            </div>
+
             <div className="doc-section" id="func-autotuneconfig">
               <h4>AutoTuneConfig</h4>
               <pre className="code-block">
@@ -65,6 +73,14 @@ export default function DocsSDKKronk() {
               <p className="doc-description">Initialized reports whether the Kronk backend has been successfully initialized. This can be used to determine if the server is running in a degraded state due to missing libraries.</p>
             </div>
 
+            <div className="doc-section" id="func-responseinputitems">
+              <h4>ResponseInputItems</h4>
+              <pre className="code-block">
+                <code>func ResponseInputItems(input any) ([]model.D, error)</code>
+              </pre>
+              <p className="doc-description">ResponseInputItems returns the input of a Responses API request as a list of conversation items. A string becomes a user message, and a list of content parts becomes a single user message holding them.</p>
+            </div>
+
             <div className="doc-section" id="func-setfmtloggertraceid">
               <h4>SetFmtLoggerTraceID</h4>
               <pre className="code-block">
@@ -308,6 +324,14 @@ export default function DocsSDKKronk() {
               <p className="doc-description">ActiveStreams returns the number of active streams.</p>
             </div>
 
+            <div className="doc-section" id="method-kronk-adapters">
+              <h4>Kronk.Adapters</h4>
+              <pre className="code-block">
+                <code>func (krn *Kronk) Adapters() []model.AdapterDetail</code>
+              </pre>
+              <p className="doc-description">Adapters returns the LoRA adapters loaded against the model.</p>
+            </div>
+
             <div className="doc-section" id="method-kronk-batchenginesnapshot">
               <h4>Kronk.BatchEngineSnapshot</h4>
               <pre className="code-block">
@@ -337,7 +361,23 @@ export default function DocsSDKKronk() {
               <pre className="code-block">
                 <code>func (krn *Kronk) ChatStreamingHTTP(ctx context.Context, w http.ResponseWriter, d model.D) (model.ChatResponse, error)</code>
               </pre>
-              <p className="doc-description">ChatStreamingHTTP provides http handler support for a chat/completions call. For text models, NSeqMax controls parallel sequence processing within a single model instance. For vision/audio models, NSeqMax creates multiple model instances in a pool for concurrent request handling.</p>
+              <p className="doc-description">ChatStreamingHTTP provides http handler support for a chat/completions call. For text models, NSeqMax controls parallel sequence processing within a single model instance. For vision/audio models, NSeqMax creates multiple model instances in a pool for concurrent request handling. The returned response carries the usage of the request, streamed or not, so callers can account for the tokens it used.</p>
+            </div>
+
+            <div className="doc-section" id="method-kronk-completion">
+              <h4>Kronk.Completion</h4>
+              <pre className="code-block">
+                <code>func (krn *Kronk) Completion(ctx context.Context, d model.D) (model.ChatResponse, error)</code>
+              </pre>
+              <p className="doc-description">Completion provides support for raw prompt completions. The prompt is tokenized as given without the chat template, and the generated text is returned as content without reasoning or tool call parsing. Supported options in d beyond the chat sampling parameters: - prompt (string or []int): the raw prompt or its token IDs (required) - suffix (string): the text after the insertion point, which makes the request a fill-in-the-middle request using the model's FIM tokens</p>
+            </div>
+
+            <div className="doc-section" id="method-kronk-completionstreaming">
+              <h4>Kronk.CompletionStreaming</h4>
+              <pre className="code-block">
+                <code>func (krn *Kronk) CompletionStreaming(ctx context.Context, d model.D) (&lt;-chan model.ChatResponse, error)</code>
+              </pre>
+              <p className="doc-description">CompletionStreaming provides support for streaming raw prompt completions. When stream_options.include_usage is true, the terminal choice is followed by a usage response with an empty Choices slice.</p>
             </div>
 
             <div className="doc-section" id="method-kronk-embeddings">
@@ -345,7 +385,7 @@ export default function DocsSDKKronk() {
               <pre className="code-block">
                 <code>func (krn *Kronk) Embeddings(ctx context.Context, d model.D) (model.EmbedReponse, error)</code>
               </pre>
-              <p className="doc-description">Embeddings provides support to interact with an embedding model. Supported options in d: - input (string): the text to embed (required) - truncate (bool): if true, truncate input to fit context window (default: false) - truncate_direction (string): "right" (default) or "left" - dimensions (int): reduce output to first N dimensions (for Matryoshka models) Each model instance processes calls sequentially (llama.cpp only supports sequence 0 for embedding extraction). Use NSeqMax &gt; 1 to create multiple model instances for concurrent request handling. Batch multiple texts in the input parameter for better performance within a single request.</p>
+              <p className="doc-description">Embeddings provides support to interact with an embedding model. Supported options in d: - input (string, []string, []int, or [][]int): the texts or token IDs to embed (required) - truncate (bool): if true, truncate input to fit context window (default: false) - truncate_direction (string): "right" (default) or "left" - dimensions (int): reduce output to first N dimensions (for Matryoshka models) - encoding_format (string): "float" (default) or "base64" - chunking (bool or object): split over-long inputs into overlapping chunks and pool them See model.Model.Embeddings for the chunking options. Each model instance processes calls sequentially (llama.cpp only supports sequence 0 for embedding extraction). Use NSeqMax &gt; 1 to create multiple model instances for concurrent request handling. Batch multiple texts in the input parameter for better performance within a single request.</p>
             </div>
 
             <div className="doc-section" id="method-kronk-embeddingshttp">
@@ -356,6 +396,14 @@ export default function DocsSDKKronk() {
               <p className="doc-description">EmbeddingsHTTP provides http handler support for an embeddings call.</p>
             </div>
 
+            <div className="doc-section" id="method-kronk-exportimcsession">
+              <h4>Kronk.ExportIMCSession</h4>
+              <pre className="code-block">
+                <code>func (krn *Kronk) ExportIMCSession(w io.Writer, id int) error</code>
+              </pre>
+              <p className="doc-description">ExportIMCSession writes the IMC session with the given id to w so it can be restored with ImportIMCSession on this or another server.</p>
+            </div>
+
             <div className="doc-section" id="method-kronk-imcsessions">
               <h4>Kronk.IMCSessions</h4>
               <pre className="code-block">
@@ -372,6 +420,22 @@ export default function DocsSDKKronk() {
               <p className="doc-description">IMCSystemCaches returns the model's immutable System cache pool entries.</p>
             </div>
 
+            <div className="doc-section" id="method-kronk-importimcsession">
+              <h4>Kronk.ImportIMCSession</h4>
+              <pre className="code-block">
+                <code>func (krn *Kronk) ImportIMCSession(r io.Reader) (model.IMCSessionDetail, error)</code>
+              </pre>
+              <p className="doc-description">ImportIMCSession restores a session written by ExportIMCSession into the model's IMC session pool and returns its state.</p>
+            </div>
+
+            <div className="doc-section" id="method-kronk-loadadapter">
+              <h4>Kronk.LoadAdapter</h4>
+              <pre className="code-block">
+                <code>func (krn *Kronk) LoadAdapter(ctx context.Context, adapter model.AdapterConfig) (model.AdapterDetail, error)</code>
+              </pre>
+              <p className="doc-description">LoadAdapter loads a LoRA adapter against the running model so requests can select it by ID.</p>
+            </div>
+
             <div className="doc-section" id="method-kronk-modelconfig">
               <h4>Kronk.ModelConfig</h4>
               <pre className="code-block">
@@ -449,7 +513,7 @@ export default function DocsSDKKronk() {
               <pre className="code-block">
                 <code>func (krn *Kronk) Tokenize(ctx context.Context, d model.D) (model.TokenizeResponse, error)</code>
               </pre>
-              <p className="doc-description">Tokenize returns the token count for a text input. Supported options in d: - input (string): the text to tokenize (required) - apply_template (bool): if true, wrap input as a user message and apply the model's chat template before tokenizing (default: false) - add_generation_prompt (bool): when apply_template is true, controls whether the assistant role prefix is appended to the prompt (default: true) When apply_template is true, the returned count includes all template overhead (role markers, separators, generation prompt). This reflects the actual number of tokens that would be fed to the model.</p>
+              <p className="doc-description">Tokenize returns the token count for a text input. Supported options in d: - input (string): the text to tokenize (required unless messages is set) - apply_template (bool): if true, wrap input as a user message and apply the model's chat template before tokenizing (default: false) - add_generation_prompt (bool): when apply_template is true, controls whether the assistant role prefix is appended to the prompt (default: true) - messages ([]D): a chat conversation to count instead of input, rendered with the chat template, tools, and thinking settings in d When apply_template is true, the returned count includes all template overhead (role markers, separators, generation prompt). This reflects the actual number of tokens that would be fed to the model.</p>
             </div>
 
             <div className="doc-section" id="method-kronk-tokenizehttp">
@@ -467,6 +531,22 @@ export default function DocsSDKKronk() {
               </pre>
               <p className="doc-description">Unload will close down the loaded model. You should call this only when you are completely done using Kronk.</p>
             </div>
+
+            <div className="doc-section" id="method-kronk-unloadadapter">
+              <h4>Kronk.UnloadAdapter</h4>
+              <pre className="code-block">
+                <code>func (krn *Kronk) UnloadAdapter(ctx context.Context, id string) error</code>
+              </pre>
+              <p className="doc-description">UnloadAdapter unloads an adapter loaded with LoadAdapter.</p>
+            </div>
+
+            <div className="doc-section" id="method-responseresponse-inputitems">
+              <h4>ResponseResponse.InputItems</h4>
+              <pre className="code-block">
+                <code>func (r ResponseResponse) InputItems() []model.D</code>
+              </pre>
+              <p className="doc-description">InputItems returns the output of the response as input items, so a later request can continue the conversation from it. Message text is joined into a single string so the replayed turn renders as the model produced it.</p>
+            </div>
           </div>
 
           <div className="card" id="constants">
@@ -483,6 +563,14 @@ export default function DocsSDKKronk() {
               <p className="doc-description">Set of logging levels supported by llama.cpp.</p>
             </div>
 
+            <div className="doc-section" id="const-sessionheader">
+              <h4>SessionHeader</h4>
+              <pre className="code-block">
+                <code>{`const SessionHeader = "X-Kronk-Session"`}</code>
+              </pre>
+              <p className="doc-description">SessionHeader is the HTTP header that pins a request to a named IMC session. It is the header form of the prompt_cache_key request field.</p>
+            </div>
+
             <div className="doc-section" id="const-version">
               <h4>Version</h4>
               <pre className="code-block">
@@ -534,9 +622,11 @@ export default function DocsSDKKronk() {
             <div className="doc-index-section">
               <a href="#functions" className="doc-index-header">Functions</a>
               <ul>
+                <li><a href="#func-applysessionheader">ApplySessionHeader</a></li>
                 <li><a href="#func-autotuneconfig">AutoTuneConfig</a></li>
                 <li><a href="#func-init">Init</a></li>
                 <li><a href="#func-initialized">Initialized</a></li>
+                <li><a href="#func-responseinputitems">ResponseInputItems</a></li>
                 <li><a href="#func-setfmtloggertraceid">SetFmtLoggerTraceID</a></li>
                 <li><a href="#func-new">New</a></li>
                 <li><a href="#func-newwithcontext">NewWithContext</a></li>
@@ -567,14 +657,20 @@ export default function DocsSDKKronk() {
               <a href="#methods" className="doc-index-header">Methods</a>
               <ul>
                 <li><a href="#method-kronk-activestreams">Kronk.ActiveStreams</a></li>
+                <li><a href="#method-kronk-adapters">Kronk.Adapters</a></li>
                 <li><a href="#method-kronk-batchenginesnapshot">Kronk.BatchEngineSnapshot</a></li>
                 <li><a href="#method-kronk-chat">Kronk.Chat</a></li>
                 <li><a href="#method-kronk-chatstreaming">Kronk.ChatStreaming</a></li>
                 <li><a href="#method-kronk-chatstreaminghttp">Kronk.ChatStreamingHTTP</a></li>
+                <li><a href="#method-kronk-completion">Kronk.Completion</a></li>
+                <li><a href="#method-kronk-completionstreaming">Kronk.CompletionStreaming</a></li>
                 <li><a href="#method-kronk-embeddings">Kronk.Embeddings</a></li>
                 <li><a href="#method-kronk-embeddingshttp">Kronk.EmbeddingsHTTP</a></li>
+                <li><a href="#method-kronk-exportimcsession">Kronk.ExportIMCSession</a></li>
                 <li><a href="#method-kronk-imcsessions">Kronk.IMCSessions</a></li>
                 <li><a href="#method-kronk-imcsystemcaches">Kronk.IMCSystemCaches</a></li>
+                <li><a href="#method-kronk-importimcsession">Kronk.ImportIMCSession</a></li>
+                <li><a href="#method-kronk-loadadapter">Kronk.LoadAdapter</a></li>
                 <li><a href="#method-kronk-modelconfig">Kronk.ModelConfig</a></li>
                 <li><a href="#method-kronk-modelid">Kronk.ModelID</a></li>
                 <li><a href="#method-kronk-modelinfo">Kronk.ModelInfo</a></li>
@@ -587,12 +683,15 @@ export default function DocsSDKKronk() {
                 <li><a href="#method-kronk-tokenize">Kronk.Tokenize</a></li>
                 <li><a href="#method-kronk-tokenizehttp">Kronk.TokenizeHTTP</a></li>
                 <li><a href="#method-kronk-unload">Kronk.Unload</a></li>
+                <li><a href="#method-kronk-unloadadapter">Kronk.UnloadAdapter</a></li>
+                <li><a href="#method-responseresponse-inputitems">ResponseResponse.InputItems</a></li>
               </ul>
             </div>
             <div className="doc-index-section">
               <a href="#constants" className="doc-index-header">Constants</a>
               <ul>
                 <li><a href="#const-logsilent">LogSilent</a></li>
+                <li><a href="#const-sessionheader">SessionHeader</a></li>
                 <li><a href="#const-version">Version</a></li>
               </ul>
             </div>
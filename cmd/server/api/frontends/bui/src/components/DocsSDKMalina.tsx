@@ -160,6 +160,14 @@ export default function DocsSDKMalina() {
               <p className="doc-description">ActiveGenerations returns the number of running and queued generation calls.</p>
             </div>
 
+            <div className="doc-section" id="method-malina-activestreams">
+              <h4>Malina.ActiveStreams</h4>
+              <pre className="code-block">
+                <code>func (m *Malina) ActiveStreams() int</code>
+              </pre>
+              <p className="doc-description">ActiveStreams returns the number of running and queued generation calls. It lets the model pool avoid evicting a handle that is still in use.</p>
+            </div>
+
             <div className="doc-section" id="method-malina-generate">
               <h4>Malina.Generate</h4>
               <pre className="code-block">
@@ -289,6 +297,7 @@ export default function DocsSDKMalina() {
               <a href="#methods" className="doc-index-header">Methods</a>
               <ul>
                 <li><a href="#method-malina-activegenerations">Malina.ActiveGenerations</a></li>
+                <li><a href="#method-malina-activestreams">Malina.ActiveStreams</a></li>
                 <li><a href="#method-malina-generate">Malina.Generate</a></li>
                 <li><a href="#method-malina-modelconfig">Malina.ModelConfig</a></li>
                 <li><a href="#method-malina-modelinfo">Malina.ModelInfo</a></li>
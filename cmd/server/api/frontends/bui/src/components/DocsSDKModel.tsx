@@ -49,6 +49,14 @@ export default function DocsSDKModel() {
               <p className="doc-description">AddParams adds the values from the Params struct into the provided D map. Only non-zero values are added.</p>
             </div>
 
+            <div className="doc-section" id="func-buildtoolcallgrammar">
+              <h4>BuildToolCallGrammar</h4>
+              <pre className="code-block">
+                <code>func BuildToolCallGrammar(gp ToolCallGrammarParser, tools []D, parallel bool) (string, error)</code>
+              </pre>
+              <p className="doc-description">BuildToolCallGrammar returns the complete grammar the parser produces for the declared function tools. When parallel is false the grammar allows a single call.</p>
+            </div>
+
             <div className="doc-section" id="func-detectmodeltypefromfiles">
               <h4>DetectModelTypeFromFiles</h4>
               <pre className="code-block">
@@ -81,6 +89,14 @@ export default function DocsSDKModel() {
               <p className="doc-description">InitYzmaWorkarounds loads the llama library and preps our extra FFI functions that yzma upstream doesn't bind yet. Safe to call multiple times; only the first call does any work. Pre-norm bindings are BEST-EFFORT: if the loaded llama library doesn't export them (older build, e.g. b9222), the corresponding ffi.Fun stays zero-valued and MTPAvailable() returns false. Init never fails on a missing pre-norm symbol so kronk still boots and can serve non-MTP models.</p>
             </div>
 
+            <div className="doc-section" id="func-jsonobjectprefix">
+              <h4>JSONObjectPrefix</h4>
+              <pre className="code-block">
+                <code>func JSONObjectPrefix(s string) (int, bool)</code>
+              </pre>
+              <p className="doc-description">JSONObjectPrefix reports how many leading bytes of s belong to a JSON object that starts at s[0], and whether the object is complete. It stops before a trailing partial UTF-8 sequence so each prefix can be sent as a fragment on its own. Strings are honored, so braces inside them do not count; the object is otherwise not validated.</p>
+            </div>
+
             <div className="doc-section" id="func-mtpavailable">
               <h4>MTPAvailable</h4>
               <pre className="code-block">
@@ -121,6 +137,14 @@ export default function DocsSDKModel() {
               <p className="doc-description">ParseMoEMode parses value and returns the corresponding MoEMode when it exists.</p>
             </div>
 
+            <div className="doc-section" id="func-parsepriority">
+              <h4>ParsePriority</h4>
+              <pre className="code-block">
+                <code>func ParsePriority(name string) (Priority, error)</code>
+              </pre>
+              <p className="doc-description">ParsePriority parses a priority name: "low", "normal", or "high". An empty name is PriorityNormal.</p>
+            </div>
+
             <div className="doc-section" id="func-parseropescalingtype">
               <h4>ParseRopeScalingType</h4>
               <pre className="code-block">
@@ -137,6 +161,14 @@ export default function DocsSDKModel() {
               <p className="doc-description">ParseSplitMode parses a string into a SplitMode. Supported values are "none", "layer", and "row". The legacy aliases "tensor", "tensor-parallel", and "expert-parallel" map to row mode.</p>
             </div>
 
+            <div className="doc-section" id="func-parsetruncationmode">
+              <h4>ParseTruncationMode</h4>
+              <pre className="code-block">
+                <code>func ParseTruncationMode(name string) (TruncationMode, error)</code>
+              </pre>
+              <p className="doc-description">ParseTruncationMode parses a truncation mode name. An empty name returns the empty mode, which defers to the model's configured mode.</p>
+            </div>
+
             <div className="doc-section" id="func-recurrentstatecopies">
               <h4>RecurrentStateCopies</h4>
               <pre className="code-block">
@@ -153,6 +185,22 @@ export default function DocsSDKModel() {
               <p className="doc-description">RegisterParser appends a parser factory to the registry. Call once per parser at server bootstrap, before any models are loaded. Order matters: the catch-all parser (fallback) must be registered last so the more specific parsers get first chance to claim.</p>
             </div>
 
+            <div className="doc-section" id="func-resolvefileinputs">
+              <h4>ResolveFileInputs</h4>
+              <pre className="code-block">
+                <code>func ResolveFileInputs(ctx context.Context, d D, lookup FileLookup) error</code>
+              </pre>
+              <p className="doc-description">ResolveFileInputs replaces the file content parts in the messages and Responses input of d with the parts the media pipeline consumes. Images become image_url parts, audio becomes input_audio parts, and PDF and plain text files become text parts holding the extracted text. It accepts the chat form &#123;"type":"file","file":&#123;"file_id":...&#125;&#125;, the Responses forms &#123;"type":"input_file","file_id":...&#125; and &#123;"type":"input_image","file_id":...&#125;, and inline file_data in place of a file_id. Parts are replaced copy-on-write so the documents in d that hold them are not modified. When lookup is nil, parts that reference a file_id are left in place.</p>
+            </div>
+
+            <div className="doc-section" id="func-resolvemediaurls">
+              <h4>ResolveMediaURLs</h4>
+              <pre className="code-block">
+                <code>func ResolveMediaURLs(ctx context.Context, d D, fetch MediaFetcher) error</code>
+              </pre>
+              <p className="doc-description">ResolveMediaURLs downloads the http and https URLs in the image_url, video_url, input_audio, and input_image parts of the messages and Responses input of d, and replaces each URL with the downloaded media as base64 data so the media pipeline can consume it. Downloaded media must match a supported image or audio format. Parts are replaced copy-on-write so the documents in d that hold them are not modified. When fetch is nil, URLs are left in place and the media pipeline rejects them.</p>
+            </div>
+
             <div className="doc-section" id="func-setembeddingsprenorm">
               <h4>SetEmbeddingsPreNorm</h4>
               <pre className="code-block">
@@ -161,6 +209,14 @@ export default function DocsSDKModel() {
               <p className="doc-description">SetEmbeddingsPreNorm enables (or disables) pre-norm hidden-state extraction on the given context. - value == true: the next llama_decode will produce a pre-norm embedding buffer accessible via GetEmbeddingsPreNorm / GetEmbeddingsPreNormIth. - masked == false: rows are stored densely, indexed by raw batch position. Used on the target context (caller wants every row). - masked == true: rows are stored only for batch positions whose logits flag is non-zero, indexed via the output_ids table. Used on the MTP draft context (caller only needs the output rows). Mirrors llama_set_embeddings_pre_norm in src/llama-ext.h.</p>
             </div>
 
+            <div className="doc-section" id="func-setschedule">
+              <h4>SetSchedule</h4>
+              <pre className="code-block">
+                <code>func SetSchedule(ctx context.Context, schedule Schedule) context.Context</code>
+              </pre>
+              <p className="doc-description">SetSchedule sets the schedule of requests made with the context.</p>
+            </div>
+
             <div className="doc-section" id="func-validatechatrequest">
               <h4>ValidateChatRequest</h4>
               <pre className="code-block">
@@ -169,6 +225,14 @@ export default function DocsSDKModel() {
               <p className="doc-description">ValidateChatRequest validates the fields in a chat request document.</p>
             </div>
 
+            <div className="doc-section" id="func-validatecompletionrequest">
+              <h4>ValidateCompletionRequest</h4>
+              <pre className="code-block">
+                <code>func ValidateCompletionRequest(d D) error</code>
+              </pre>
+              <p className="doc-description">ValidateCompletionRequest validates the fields in a raw completion request document.</p>
+            </div>
+
             <div className="doc-section" id="func-validatemessages">
               <h4>ValidateMessages</h4>
               <pre className="code-block">
@@ -201,11 +265,36 @@ export default function DocsSDKModel() {
               <h4>AdapterConfig</h4>
               <pre className="code-block">
                 <code>{`type AdapterConfig struct {
+	ID    string
 	Path  string
 	Scale float32
 }`}</code>
               </pre>
-              <p className="doc-description">AdapterConfig configures a local llama.cpp-compatible LoRA adapter GGUF. Scale is fixed for the lifetime of the loaded model.</p>
+              <p className="doc-description">AdapterConfig configures a local llama.cpp-compatible LoRA adapter GGUF. ID names the adapter in requests; when empty, the file name without its extension is used. Scale is the weight applied to requests that do not select adapters; an adapter with a zero scale is loaded but only applied when a request selects it.</p>
+            </div>
+
+            <div className="doc-section" id="type-adapterdetail">
+              <h4>AdapterDetail</h4>
+              <pre className="code-block">
+                <code>{`type AdapterDetail struct {
+	ID         string
+	Path       string
+	Scale      float32 // Scale applied to requests that do not select adapters.
+	Configured bool    // Loaded from the model configuration; cannot be unloaded.
+}`}</code>
+              </pre>
+              <p className="doc-description">AdapterDetail describes a LoRA adapter loaded against a model.</p>
+            </div>
+
+            <div className="doc-section" id="type-adapterselection">
+              <h4>AdapterSelection</h4>
+              <pre className="code-block">
+                <code>{`type AdapterSelection struct {
+	ID    string  \`json:"id"\`
+	Scale float32 \`json:"scale"\`
+}`}</code>
+              </pre>
+              <p className="doc-description">AdapterSelection selects a loaded adapter by ID and the scale a request applies it at.</p>
             </div>
 
             <div className="doc-section" id="type-artifactdigest">
@@ -264,6 +353,7 @@ export default function DocsSDKModel() {
 	NDraft                  int
 	QueuedRequests          int
 	PendingRequests         int
+	PreemptedRequests       int
 	PrefillSelectorStart    int
 	PrefillSelectorSelected int
 	PrefillSelectorNext     int
@@ -352,6 +442,14 @@ export default function DocsSDKModel() {
 	Delta           *ResponseMessage \`json:"delta,omitempty"\`
 	Logprobs        *Logprobs        \`json:"logprobs,omitempty"\`
 	FinishReasonPtr *string          \`json:"finish_reason"\`
+
+	// Usage reports the tokens of this choice when a request asks for more
+	// than one choice. The response usage covers every choice.
+	Usage *Usage \`json:"usage,omitempty"\`
+
+	// StopSequence is the request stop sequence that ended the choice. It is
+	// not part of the OpenAI wire format.
+	StopSequence string \`json:"-"\`
 }`}</code>
               </pre>
               <p className="doc-description">Choice represents a single choice in a response.</p>
@@ -379,6 +477,7 @@ export default function DocsSDKModel() {
 	PtrCacheMinTokens          *int
 	CacheTypeK                 GGMLType
 	CacheTypeV                 GGMLType
+	PtrContextShift            *bool
 	PtrContextWindow           *int
 	DefaultParams              Params
 	ChatTemplateKwargs         D
@@ -419,6 +518,7 @@ export default function DocsSDKModel() {
 	PtrSWAFull                 *bool
 	TensorBuftOverrides        []string
 	TensorSplit                []float32
+	Truncation                 TruncationMode
 	PtrYarnAttnFactor          *float32
 	PtrYarnBetaFast            *float32
 	PtrYarnBetaSlow            *float32
@@ -428,7 +528,7 @@ export default function DocsSDKModel() {
 	// Has unexported fields.
 }`}</code>
               </pre>
-              <p className="doc-description">Config represents model level configuration. These values if configured incorrectly can cause the system to panic. The defaults are used when these values are set to 0. Adapters contains local llama.cpp-compatible LoRA adapter GGUF files to load with the model. Adapter scales are fixed for the lifetime of the model. ArtifactIntegrity contains expected artifact verification state keyed by model file path. It is used to avoid repeating completed verification work. AdmissionTimeout limits how long a request waits for an admission permit. The timeout applies only to admission, not request processing after a permit is acquired. When unset or set to 0, the default is 3 minutes. AutoTune, when true, asks kronk.New to run a hardware-aware analysis of the model (architecture, size, and available devices) and seed unset settings (context window, KV cache type, slots, flash attention, split mode, etc.) before loading. It is off by default for backwards compatibility, and any option the caller sets explicitly always wins over the analysis. It has no effect when using the low-level model package directly (only kronk.New applies it). AutoTuned records that an upstream owner such as the Kronk Model Server has already applied AutoTune. When AutoTune and AutoTuned are both true, kronk.New preserves the enabled state for diagnostics without repeating the hardware analysis. CacheMinTokens sets the minimum token count required before caching. Messages shorter than this threshold are not cached, as the overhead of cache management may outweigh the prefill savings. When set to 0, defaults to 100 tokens. CacheTypeK is the data type for the K (key) cache. This controls the precision of the key vectors in the KV cache. Lower precision types (like Q8_0 or Q4_0) reduce memory usage but may slightly affect quality. When left as the zero value (GGMLTypeAuto), the default llama.cpp value is used. CacheTypeV is the data type for the V (value) cache. This controls the precision of the value vectors in the KV cache. When left as the zero value (GGMLTypeAuto), the default llama.cpp value is used. ContextWindow (often referred to as context length) is the maximum number of tokens that a large language model can process and consider at one time when generating a response. It defines the model's effective "memory" for a single conversation or text generation task. When set to 0, the default value is 4096. DefaultParams contains the default sampling parameters for requests. ChatTemplateKwargs contains model-level defaults passed only to the Jinja chat template. Request-level chat_template_kwargs override matching keys. Resolved first-class request parameters remain top-level template values. PtrDraftModel configures a separate speculative-decoding draft model or an nDraft override for an auto-detected MTP head. Devices is a list of device names to use for model execution. When multiple devices are specified, the model is distributed across them according to the SplitMode and TensorSplit configuration. Device names can be obtained from the output of llama-bench --list-devices (e.g., "CUDA0", "CUDA1", "Metal"). When empty, the default device selection is used. PtrFlashAttention controls Flash Attention mode. Flash Attention reduces memory usage and speeds up attention computation, especially for large context windows. When nil, FlashAttentionAuto is used so llama.cpp can decide whether the active backend supports it. Set to FlashAttentionEnabled to force it on, or FlashAttentionDisabled to force it off. IMCSessionCapacity sets the number of reusable IMC session identities. When left unset or set to 0, generation models default to NSeqMax * max(3, QueueDepth). An explicit value must be at least NSeqMax * QueueDepth so every admitted generation request can reserve a session. IncrementalCache enables Incremental Message Caching (IMC) for agentic workflows. It caches all messages except the last one (which triggers generation) and extends the cache incrementally on each turn. This is ideal for agents like Cline or OpenCode where conversations grow monotonically. The cache is rebuilt from scratch when the message prefix changes (new thread). InsecureLogging enables logging of potentially sensitive data such as message content. This should only be enabled for debugging purposes in non-production environments. JinjaFile is the path to the jinja file. This is not required and can be used if you want to override the templated provided by the model metadata. LoadMode controls how model weights are loaded. The default is LoadModeAuto, which uses mmap when every selected device supports it and otherwise uses ordinary loading. LoadModeNone disables mmap, which can improve tensor placement on multi-socket NUMA systems running MoE models with CPU experts. LoadModeMLock requests resident pages without forcing mmap, LoadModeMMapMLock combines mmap and mlock, and LoadModeDirectIO bypasses the page cache where the platform and filesystem support it. Log is the logger to use for model operations. MainGPU is the index of the GPU to use as the primary device when SplitMode is SplitModeNone. When nil, the default GPU (usually index 0) is used. PtrMoE controls expert-tensor placement for Mixture of Experts models. ModelFiles is the path to the model files. This is mandatory to provide. PrefillBatchSize is the maximum number of prompt tokens one prefill owner can contribute to a decode iteration. The default is 2048. Larger values can reduce the number of decode calls needed to reach generation, but each call takes longer before already-generating slots can run again and requires larger compute buffers. Kronk derives llama.cpp's logical and physical batch capacities from this value, the configured slot count, and the generation mode. Multimodal models may require a complete media-token chunk to fit in this capacity. NGpuLayers is the number of model layers to offload to the GPU. When set to 0, all layers are offloaded (default). Set to -1 to keep all layers on CPU. Any positive value specifies the exact number of layers to offload. NSeqMax controls concurrency behavior based on model type. For text inference models (including vision/audio), it sets the maximum number of generation slots. For supported embedding and reranking architectures, it sets the maximum sequence width of the sequence-batch engine. Other embedding and reranking architectures use it as the context-pool size. When set to 0, a default of 1 is used. NThreads is the number of threads to use for generation. When set to 0, the default llama.cpp value is used. NThreadsBatch is the number of threads to use for batch processing. When set to 0, the default llama.cpp value is used. NUMA controls the NUMA (Non-Uniform Memory Access) strategy. This matters most when expert tensors are on CPU and the system has multiple NUMA nodes. Valid values: "" (disabled), "distribute", "isolate", "numactl", "mirror". "distribute" is recommended for multi-socket MoE setups; without it, cross-socket memory access can cause significant bandwidth collapse. OffloadKQV controls whether the KV cache is offloaded to the GPU. When nil or true, the KV cache is stored on the GPU (default behavior). Set to false to keep the KV cache on the CPU, which reduces VRAM usage but may slow inference. OpOffload controls whether host tensor operations are offloaded to the device (GPU). When nil or true, operations are offloaded (default behavior). Set to false to keep operations on the CPU. OpOffloadMinBatch sets the minimum batch size at which host tensor operations are offloaded to the device. When unset or 0, llama.cpp's default is used. ProjFile is the path to the projection files. This is mandatory for media based models like vision and audio. MTPDrafterFile is the path to a separate-file MTP "assistant" drafter GGUF that ships alongside the main model (e.g. Gemma4's "mtp-gemma-4-26B-A4B-it-*.gguf"). It is NOT the main model and NOT a vocab-matched classic draft model: it is a per-model speculative head loaded as its own llama_model whose context shares the target's KV memory. Auto-wired from disk when the companion file is present; empty otherwise. Distinct from the embedded MTP head carried inside some target GGUFs (Qwen3.5/3.6), which has no separate file. ProjOnCPU forces the multimodal projector (mmproj) to run on the CPU. When nil or false, the projector runs on whichever device llama.cpp picks by default (GPU when available). Set to true to keep the projector on the CPU — equivalent to llama-mtmd-cli's --no-mmproj-offload. The LLM itself is unaffected and still runs on whatever device WithNGpuLayers selects. ProjDevice names the backend device used by the multimodal projector (mmproj), such as "CUDA1" or "MTL0". When empty, llama.cpp selects the projector device automatically. It cannot be combined with ProjOnCPU=true. The LLM device selection is unaffected. QueueDepth sets the multiplier for semaphore capacity when using the batch engine (NSeqMax &gt; 1). This controls how many requests can queue while the current batch is processing. Default is 2, meaning NSeqMax * 2 requests can be in-flight. Only applies to text inference models. RopeFreqBase overrides the RoPE base frequency. When nil, uses model default. Common values: 10000 (Llama), 1000000 (Qwen3). RopeFreqScale overrides the raw RoPE frequency multiplier. When nil, uses the value from model metadata. Kronk does not derive this value from ContextWindow; an N-times extension generally uses 1/N when the model's documentation requires explicit scaling. RecordArtifactVerification persists updated verification state after Kronk verifies a model artifact. When nil, verification state is not persisted. RopeScaling controls the RoPE scaling method for extended context support. Set to RopeScalingYaRN only when the model supports YaRN and configure the frequency scale required by that model. SessionStoreFactory constructs session stores for direct SDK use. Kronk invokes it separately for every working session store it needs and closes every successfully returned store. The factory must return a new, independent store on each call. System prompt preloads always remain in RAM. When nil, Kronk uses the built-in RAM factory. Backend-specific constructor parameters belong to the backend package and are captured by the injected factory. SplitMode controls how the model is split across multiple GPUs: - SplitModeNone (0): single GPU - SplitModeLayer (1): split layers and KV across GPUs - SplitModeRow (2): deprecated row-split tensor parallelism When nil (not set), the default is SplitModeLayer, matching llama.cpp. Layer mode distributes a single GGUF across multiple GPUs without requiring the backend-specific split buffers used by row mode. SWAFull controls whether models with sliding window attention (SWA) use a full-size KV cache for SWA layers instead of the memory-efficient small cache. When nil (default), llama.cpp's default is used. When explicitly set to false, SWA layers only cache the last n_swa tokens, saving significant VRAM but limiting context caching and shifting. When true, SWA layers use the full context window for their KV cache, preserving accuracy at the cost of higher memory usage. TensorBuftOverrides is a list of tensor buffer type override patterns that force matching tensors to execute on CPU instead of GPU. This is an expert-level configuration useful for MoE models where certain FFN expert tensors don't fit in VRAM. Supported values: - "all-ffn": offload all FFN expression tensors to CPU - "block:N": offload FFN tensors for block N to CPU (e.g., "block:12") - Any regex pattern matching tensor names (e.g., `blk\.12\.ffn_(up|down|gate)`) TensorSplit controls how model layers are proportionally distributed across multiple GPUs. Each element represents the fraction of the model assigned to the corresponding device. For example, [0.6, 0.4] splits 60%/40% across two GPUs. The length must match the number of devices. When empty, the split is determined automatically based on available VRAM. YarnAttnFactor sets the YaRN attention magnitude scaling factor. When nil, uses the model or llama.cpp default. YarnBetaFast sets the YaRN low correction dimension. When nil, uses the model or llama.cpp default. YarnBetaSlow sets the YaRN high correction dimension. When nil, uses the model or llama.cpp default. YarnExtFactor sets the YaRN extrapolation mix factor. When nil, uses the model or llama.cpp default. Set to 0 to disable extrapolation. YarnOrigCtx sets the original training context size for YaRN scaling. When nil or 0, uses the model's native training context length from metadata.</p>
+              <p className="doc-description">Config represents model level configuration. These values if configured incorrectly can cause the system to panic. The defaults are used when these values are set to 0. Adapters contains local llama.cpp-compatible LoRA adapter GGUF files to load with the model. Requests that do not select adapters run with every configured adapter at its configured scale; a request may instead select any loaded adapters and scales by ID. More adapters can be loaded and unloaded while the model runs. ArtifactIntegrity contains expected artifact verification state keyed by model file path. It is used to avoid repeating completed verification work. AdmissionTimeout limits how long a request waits for an admission permit. The timeout applies only to admission, not request processing after a permit is acquired. When unset or set to 0, the default is 3 minutes. AutoTune, when true, asks kronk.New to run a hardware-aware analysis of the model (architecture, size, and available devices) and seed unset settings (context window, KV cache type, slots, flash attention, split mode, etc.) before loading. It is off by default for backwards compatibility, and any option the caller sets explicitly always wins over the analysis. It has no effect when using the low-level model package directly (only kronk.New applies it). AutoTuned records that an upstream owner such as the Kronk Model Server has already applied AutoTune. When AutoTune and AutoTuned are both true, kronk.New preserves the enabled state for diagnostics without repeating the hardware analysis. CacheMinTokens sets the minimum token count required before caching. Messages shorter than this threshold are not cached, as the overhead of cache management may outweigh the prefill savings. When set to 0, defaults to 100 tokens. CacheTypeK is the data type for the K (key) cache. This controls the precision of the key vectors in the KV cache. Lower precision types (like Q8_0 or Q4_0) reduce memory usage but may slightly affect quality. When left as the zero value (GGMLTypeAuto), the default llama.cpp value is used. CacheTypeV is the data type for the V (value) cache. This controls the precision of the value vectors in the KV cache. When left as the zero value (GGMLTypeAuto), the default llama.cpp value is used. ContextWindow (often referred to as context length) is the maximum number of tokens that a large language model can process and consider at one time when generating a response. It defines the model's effective "memory" for a single conversation or text generation task. When set to 0, the default value is 4096. PtrContextShift enables context shifting for generation that reaches the context window. Instead of ending the request with an error, Kronk discards the older half of the sequence after its first tokens and keeps generating. It applies only to text requests on models whose KV memory supports position shifts and that run without a draft model or MTP head. When nil or false, generation that reaches the context window fails. DefaultParams contains the default sampling parameters for requests. ChatTemplateKwargs contains model-level defaults passed only to the Jinja chat template. Request-level chat_template_kwargs override matching keys. Resolved first-class request parameters remain top-level template values. PtrDraftModel configures a separate speculative-decoding draft model or an nDraft override for an auto-detected MTP head. Devices is a list of device names to use for model execution. When multiple devices are specified, the model is distributed across them according to the SplitMode and TensorSplit configuration. Device names can be obtained from the output of llama-bench --list-devices (e.g., "CUDA0", "CUDA1", "Metal"). When empty, the default device selection is used. PtrFlashAttention controls Flash Attention mode. Flash Attention reduces memory usage and speeds up attention computation, especially for large context windows. When nil, FlashAttentionAuto is used so llama.cpp can decide whether the active backend supports it. Set to FlashAttentionEnabled to force it on, or FlashAttentionDisabled to force it off. IMCSessionCapacity sets the number of reusable IMC session identities. When left unset or set to 0, generation models default to NSeqMax * max(3, QueueDepth). An explicit value must be at least NSeqMax * QueueDepth so every admitted generation request can reserve a session. IncrementalCache enables Incremental Message Caching (IMC) for agentic workflows. It caches all messages except the last one (which triggers generation) and extends the cache incrementally on each turn. This is ideal for agents like Cline or OpenCode where conversations grow monotonically. The cache is rebuilt from scratch when the message prefix changes (new thread). InsecureLogging enables logging of potentially sensitive data such as message content. This should only be enabled for debugging purposes in non-production environments. JinjaFile is the path to the jinja file. This is not required and can be used if you want to override the templated provided by the model metadata. LoadMode controls how model weights are loaded. The default is LoadModeAuto, which uses mmap when every selected device supports it and otherwise uses ordinary loading. LoadModeNone disables mmap, which can improve tensor placement on multi-socket NUMA systems running MoE models with CPU experts. LoadModeMLock requests resident pages without forcing mmap, LoadModeMMapMLock combines mmap and mlock, and LoadModeDirectIO bypasses the page cache where the platform and filesystem support it. Log is the logger to use for model operations. MainGPU is the index of the GPU to use as the primary device when SplitMode is SplitModeNone. When nil, the default GPU (usually index 0) is used. PtrMoE controls expert-tensor placement for Mixture of Experts models. ModelFiles is the path to the model files. This is mandatory to provide. PrefillBatchSize is the maximum number of prompt tokens one prefill owner can contribute to a decode iteration. The default is 2048. Larger values can reduce the number of decode calls needed to reach generation, but each call takes longer before already-generating slots can run again and requires larger compute buffers. Kronk derives llama.cpp's logical and physical batch capacities from this value, the configured slot count, and the generation mode. Multimodal models may require a complete media-token chunk to fit in this capacity. NGpuLayers is the number of model layers to offload to the GPU. When set to 0, all layers are offloaded (default). Set to -1 to keep all layers on CPU. Any positive value specifies the exact number of layers to offload. NSeqMax controls concurrency behavior based on model type. For text inference models (including vision/audio), it sets the maximum number of generation slots. For supported embedding and reranking architectures, it sets the maximum sequence width of the sequence-batch engine. Other embedding and reranking architectures use it as the context-pool size. When set to 0, a default of 1 is used. NThreads is the number of threads to use for generation. When set to 0, the default llama.cpp value is used. NThreadsBatch is the number of threads to use for batch processing. When set to 0, the default llama.cpp value is used. NUMA controls the NUMA (Non-Uniform Memory Access) strategy. This matters most when expert tensors are on CPU and the system has multiple NUMA nodes. Valid values: "" (disabled), "distribute", "isolate", "numactl", "mirror". "distribute" is recommended for multi-socket MoE setups; without it, cross-socket memory access can cause significant bandwidth collapse. OffloadKQV controls whether the KV cache is offloaded to the GPU. When nil or true, the KV cache is stored on the GPU (default behavior). Set to false to keep the KV cache on the CPU, which reduces VRAM usage but may slow inference. OpOffload controls whether host tensor operations are offloaded to the device (GPU). When nil or true, operations are offloaded (default behavior). Set to false to keep operations on the CPU. OpOffloadMinBatch sets the minimum batch size at which host tensor operations are offloaded to the device. When unset or 0, llama.cpp's default is used. ProjFile is the path to the projection files. This is mandatory for media based models like vision and audio. MTPDrafterFile is the path to a separate-file MTP "assistant" drafter GGUF that ships alongside the main model (e.g. Gemma4's "mtp-gemma-4-26B-A4B-it-*.gguf"). It is NOT the main model and NOT a vocab-matched classic draft model: it is a per-model speculative head loaded as its own llama_model whose context shares the target's KV memory. Auto-wired from disk when the companion file is present; empty otherwise. Distinct from the embedded MTP head carried inside some target GGUFs (Qwen3.5/3.6), which has no separate file. ProjOnCPU forces the multimodal projector (mmproj) to run on the CPU. When nil or false, the projector runs on whichever device llama.cpp picks by default (GPU when available). Set to true to keep the projector on the CPU — equivalent to llama-mtmd-cli's --no-mmproj-offload. The LLM itself is unaffected and still runs on whatever device WithNGpuLayers selects. ProjDevice names the backend device used by the multimodal projector (mmproj), such as "CUDA1" or "MTL0". When empty, llama.cpp selects the projector device automatically. It cannot be combined with ProjOnCPU=true. The LLM device selection is unaffected. QueueDepth sets the multiplier for semaphore capacity when using the batch engine (NSeqMax &gt; 1). This controls how many requests can queue while the current batch is processing. Default is 2, meaning NSeqMax * 2 requests can be in-flight. Only applies to text inference models. RopeFreqBase overrides the RoPE base frequency. When nil, uses model default. Common values: 10000 (Llama), 1000000 (Qwen3). RopeFreqScale overrides the raw RoPE frequency multiplier. When nil, uses the value from model metadata. Kronk does not derive this value from ContextWindow; an N-times extension generally uses 1/N when the model's documentation requires explicit scaling. RecordArtifactVerification persists updated verification state after Kronk verifies a model artifact. When nil, verification state is not persisted. RopeScaling controls the RoPE scaling method for extended context support. Set to RopeScalingYaRN only when the model supports YaRN and configure the frequency scale required by that model. SessionStoreFactory constructs session stores for direct SDK use. Kronk invokes it separately for every working session store it needs and closes every successfully returned store. The factory must return a new, independent store on each call. System prompt preloads always remain in RAM. When nil, Kronk uses the built-in RAM factory. Backend-specific constructor parameters belong to the backend package and are captured by the injected factory. SplitMode controls how the model is split across multiple GPUs: - SplitModeNone (0): single GPU - SplitModeLayer (1): split layers and KV across GPUs - SplitModeRow (2): deprecated row-split tensor parallelism When nil (not set), the default is SplitModeLayer, matching llama.cpp. Layer mode distributes a single GGUF across multiple GPUs without requiring the backend-specific split buffers used by row mode. SWAFull controls whether models with sliding window attention (SWA) use a full-size KV cache for SWA layers instead of the memory-efficient small cache. When nil (default), llama.cpp's default is used. When explicitly set to false, SWA layers only cache the last n_swa tokens, saving significant VRAM but limiting context caching and shifting. When true, SWA layers use the full context window for their KV cache, preserving accuracy at the cost of higher memory usage. Truncation is the default TruncationMode for text chat requests that do not set truncation themselves. When empty, TruncationDisabled is used and a prompt that does not fit the context window is rejected. TensorBuftOverrides is a list of tensor buffer type override patterns that force matching tensors to execute on CPU instead of GPU. This is an expert-level configuration useful for MoE models where certain FFN expert tensors don't fit in VRAM. Supported values: - "all-ffn": offload all FFN expression tensors to CPU - "block:N": offload FFN tensors for block N to CPU (e.g., "block:12") - Any regex pattern matching tensor names (e.g., `blk\.12\.ffn_(up|down|gate)`) TensorSplit controls how model layers are proportionally distributed across multiple GPUs. Each element represents the fraction of the model assigned to the corresponding device. For example, [0.6, 0.4] splits 60%/40% across two GPUs. The length must match the number of devices. When empty, the split is determined automatically based on available VRAM. YarnAttnFactor sets the YaRN attention magnitude scaling factor. When nil, uses the model or llama.cpp default. YarnBetaFast sets the YaRN low correction dimension. When nil, uses the model or llama.cpp default. YarnBetaSlow sets the YaRN high correction dimension. When nil, uses the model or llama.cpp default. YarnExtFactor sets the YaRN extrapolation mix factor. When nil, uses the model or llama.cpp default. Set to 0 to disable extrapolation. YarnOrigCtx sets the original training context size for YaRN scaling. When nil or 0, uses the model's native training context length from metadata.</p>
             </div>
 
             <div className="doc-section" id="type-contentlogprob">
@@ -457,26 +557,42 @@ export default function DocsSDKModel() {
               <pre className="code-block">
                 <code>{`type DraftModelConfig struct {
 	ModelFiles    []string  // Path to the draft model GGUF file(s); empty means MTP nDraft override
-	NDraft        int       // Number of tokens to draft per step (separate-GGUF default 5, MTP default 3)
+	NDraft        int       // Number of tokens to draft per step (separate-GGUF default 5, MTP default 3, n-gram default 8)
 	PtrNGpuLayers *int      // GPU layers for draft model (nil = all layers on GPU)
 	Devices       []string  // Devices for draft model (e.g., ["CUDA0"])
 	PtrMainGPU    *int      // Primary GPU index for draft model
 	TensorSplit   []float32 // Per-device tensor split for draft model
 }`}</code>
               </pre>
-              <p className="doc-description">DraftModelConfig configures speculative decoding for a target model. It serves two purposes depending on whether ModelFiles is set: 1. Separate-GGUF draft (ModelFiles set): a smaller, faster model generates candidate tokens that the target verifies in a single forward pass. Requires NSeqMax == 1 (single-slot mode) and a draft that shares the target's vocabulary (same tokenizer). 2. MTP nDraft override (ModelFiles empty): when the target GGUF ships an auto-detected MTP head, this block sets the number of draft tokens per round without supplying a separate model. NDraft defaults to defMTPNDraft when left unset. A model can have at most one drafter. If ModelFiles is set, the separate-GGUF drafter wins even on a target that also has an MTP head.</p>
+              <p className="doc-description">DraftModelConfig configures speculative decoding for a target model. It serves two purposes depending on whether ModelFiles is set: 1. Separate-GGUF draft (ModelFiles set): a smaller, faster model generates candidate tokens that the target verifies in a single forward pass. Requires NSeqMax == 1 (single-slot mode) and a draft that shares the target's vocabulary (same tokenizer). 2. MTP nDraft override (ModelFiles empty): when the target GGUF ships an auto-detected MTP head, this block sets the number of draft tokens per round without supplying a separate model. NDraft defaults to defMTPNDraft when left unset. With Speculation set to ngram, it sets the n-gram candidate ceiling instead, defaulting to defNGramNDraft. A model can have at most one drafter. If ModelFiles is set, the separate-GGUF drafter wins even on a target that also has an MTP head.</p>
+            </div>
+
+            <div className="doc-section" id="type-embedchunk">
+              <h4>EmbedChunk</h4>
+              <pre className="code-block">
+                <code>{`type EmbedChunk struct {
+	Index      int       \`json:"index"\`
+	StartToken int       \`json:"start_token"\`
+	EndToken   int       \`json:"end_token"\`
+	Embedding  []float32 \`json:"embedding"\`
+	// Has unexported fields.
+}`}</code>
+              </pre>
+              <p className="doc-description">EmbedChunk represents the embedding of one chunk of an input. StartToken and EndToken are the offsets of the chunk's tokens in the input's tokens.</p>
             </div>
 
             <div className="doc-section" id="type-embeddata">
               <h4>EmbedData</h4>
               <pre className="code-block">
                 <code>{`type EmbedData struct {
-	Object    string    \`json:"object"\`
-	Index     int       \`json:"index"\`
-	Embedding []float32 \`json:"embedding"\`
+	Object    string       \`json:"object"\`
+	Index     int          \`json:"index"\`
+	Embedding []float32    \`json:"embedding"\`
+	Chunks    []EmbedChunk \`json:"chunks,omitempty"\`
+	// Has unexported fields.
 }`}</code>
               </pre>
-              <p className="doc-description">EmbedData represents the data associated with an embedding call.</p>
+              <p className="doc-description">EmbedData represents the data associated with an embedding call. Chunks holds the embedding of each chunk when an input was split by chunking and the request asked for them.</p>
             </div>
 
             <div className="doc-section" id="type-embedreponse">
@@ -504,6 +620,25 @@ export default function DocsSDKModel() {
               <p className="doc-description">EmbedUsage provides token usage information for embeddings.</p>
             </div>
 
+            <div className="doc-section" id="type-filedata">
+              <h4>FileData</h4>
+              <pre className="code-block">
+                <code>{`type FileData struct {
+	Filename string
+	Data     []byte
+}`}</code>
+              </pre>
+              <p className="doc-description">FileData is the content of an uploaded file referenced by a file input.</p>
+            </div>
+
+            <div className="doc-section" id="type-filelookup">
+              <h4>FileLookup</h4>
+              <pre className="code-block">
+                <code>{`type FileLookup func(ctx context.Context, fileID string) (FileData, error)`}</code>
+              </pre>
+              <p className="doc-description">FileLookup returns the content of an uploaded file by its ID.</p>
+            </div>
+
             <div className="doc-section" id="type-fingerprint">
               <h4>Fingerprint</h4>
               <pre className="code-block">
@@ -532,11 +667,37 @@ export default function DocsSDKModel() {
               <p className="doc-description">GGMLType represents a ggml data type for the KV cache. These values correspond to the ggml_type enum in llama.cpp.</p>
             </div>
 
+            <div className="doc-section" id="type-grammarparameter">
+              <h4>GrammarParameter</h4>
+              <pre className="code-block">
+                <code>{`type GrammarParameter struct {
+	// Name is the parameter name.
+	Name string
+
+	// Required reports whether the tool schema requires the parameter.
+	Required bool
+
+	// Value is the rule matching the parameter's value as JSON.
+	Value string
+
+	// String reports whether the parameter is declared as a string, which
+	// formats such as XML arguments write without JSON quoting.
+	String bool
+
+	// Raw is the rule matching the unquoted text of a string parameter
+	// restricted by enum or const, and is empty for other parameters.
+	Raw string
+}`}</code>
+              </pre>
+              <p className="doc-description">GrammarParameter describes one declared parameter of a tool for parsers whose native format writes each argument separately.</p>
+            </div>
+
             <div className="doc-section" id="type-imcsessiondetail">
               <h4>IMCSessionDetail</h4>
               <pre className="code-block">
                 <code>{`type IMCSessionDetail struct {
 	ID             int
+	CacheKey       string
 	State          IMCSessionState
 	Context        int
 	Allocated      int
@@ -605,6 +766,14 @@ export default function DocsSDKModel() {
               <p className="doc-description">Logprobs contains log probability information for the response.</p>
             </div>
 
+            <div className="doc-section" id="type-mediafetcher">
+              <h4>MediaFetcher</h4>
+              <pre className="code-block">
+                <code>{`type MediaFetcher func(ctx context.Context, url string) ([]byte, error)`}</code>
+              </pre>
+              <p className="doc-description">MediaFetcher downloads the media a content part references by URL.</p>
+            </div>
+
             <div className="doc-section" id="type-mediatype">
               <h4>MediaType</h4>
               <pre className="code-block">
@@ -692,6 +861,12 @@ export default function DocsSDKModel() {
               <h4>Params</h4>
               <pre className="code-block">
                 <code>{`type Params struct {
+	// Adapters selects the loaded LoRA adapters, by ID, and the scale to apply
+	// each at. Nil applies the model's configured adapters; an empty list
+	// runs the base model without adapters. A selection without a scale is
+	// applied at 1.
+	Adapters []AdapterSelection \`json:"adapters,omitempty"\`
+
 	// AdaptivePDecay controls how quickly the Adaptive-P sampler adjusts.
 	// Default is 0.0.
 	AdaptivePDecay float32 \`json:"adaptive_p_decay"\`
@@ -803,6 +978,11 @@ export default function DocsSDKModel() {
 	// probability P. Default is 0.9.
 	TopP float32 \`json:"top_p"\`
 
+	// Truncation selects how a text prompt that does not fit the context
+	// window is shortened: disabled, auto, or middle-out. When empty, the
+	// model's configured mode is used, which defaults to disabled.
+	Truncation TruncationMode \`json:"truncation"\`
+
 	// XtcMinKeep is the minimum tokens to keep after XTC culling. Default is 1.
 	XtcMinKeep uint32 \`json:"xtc_min_keep"\`
 
@@ -857,6 +1037,14 @@ export default function DocsSDKModel() {
               <p className="doc-description">ParserFactory is the constructor signature each parser package's New function satisfies. The bool return reports whether this parser claims the given Fingerprint; on false, the registry continues to the next factory.</p>
             </div>
 
+            <div className="doc-section" id="type-priority">
+              <h4>Priority</h4>
+              <pre className="code-block">
+                <code>{`type Priority int`}</code>
+              </pre>
+              <p className="doc-description">Priority selects the scheduling lane of a chat request. When several requests are waiting for a slot, every request in a higher lane is assigned before any request in a lower lane. The zero value is PriorityNormal.</p>
+            </div>
+
             <div className="doc-section" id="type-prompttokensdetails">
               <h4>PromptTokensDetails</h4>
               <pre className="code-block">
@@ -986,6 +1174,17 @@ export default function DocsSDKModel() {
               <p className="doc-description">RopeScalingType controls RoPE (Rotary Position Embedding) scaling method. This enables extended context windows beyond the model's native training length. For example, Qwen3 models trained on 32k can support 131k with YaRN scaling.</p>
             </div>
 
+            <div className="doc-section" id="type-schedule">
+              <h4>Schedule</h4>
+              <pre className="code-block">
+                <code>{`type Schedule struct {
+	Priority Priority
+	Tenant   string
+}`}</code>
+              </pre>
+              <p className="doc-description">Schedule identifies how the batch engine orders a request against other waiting requests. Requests are placed in their priority lane and, within a lane, tenants share the free slots by weighted fair queuing so one tenant's burst cannot starve the others.</p>
+            </div>
+
             <div className="doc-section" id="type-sessionstore">
               <h4>SessionStore</h4>
               <pre className="code-block">
@@ -1087,6 +1286,16 @@ export default function DocsSDKModel() {
               <p className="doc-description">ToolAwareStateMachine is optionally implemented by state machines that need the request's declared tools to distinguish an unmarked tool call from ordinary answer content.</p>
             </div>
 
+            <div className="doc-section" id="type-toolcallargumentstreamer">
+              <h4>ToolCallArgumentStreamer</h4>
+              <pre className="code-block">
+                <code>{`type ToolCallArgumentStreamer interface {
+	StreamToolCallArguments()
+}`}</code>
+              </pre>
+              <p className="doc-description">ToolCallArgumentStreamer is optionally implemented by tool-call delta streamers that can also stream a call's arguments while the model writes them. Kronk enables it for streaming requests after the state machine is reset; it stays enabled until the next Reset. Once enabled, ToolCallDeltas also returns argument deltas that carry only Index and Function.Arguments. They follow the start delta for the same index, and their fragments concatenate to the JSON arguments object as the model wrote it.</p>
+            </div>
+
             <div className="doc-section" id="type-toolcallarguments">
               <h4>ToolCallArguments</h4>
               <pre className="code-block">
@@ -1109,6 +1318,16 @@ export default function DocsSDKModel() {
               <p className="doc-description">ToolCallDeltaStreamer is implemented by state machines that can translate model-native tool-call starts into OpenAI-compatible activity deltas. ToolCallDeltas drains deltas produced by the most recent Classify call.</p>
             </div>
 
+            <div className="doc-section" id="type-toolcallgrammarparser">
+              <h4>ToolCallGrammarParser</h4>
+              <pre className="code-block">
+                <code>{`type ToolCallGrammarParser interface {
+	ToolCallGrammar(tg *ToolGrammar) (string, error)
+}`}</code>
+              </pre>
+              <p className="doc-description">ToolCallGrammarParser is optionally implemented by parsers that can express their native tool-call syntax as a GBNF grammar. When a request sets tool_choice to "required" or names a function, Kronk asks the parser for the root rule of a grammar built from the declared tool schemas, so the emitted call always parses and its arguments match the schema. Parsers that do not implement it leave such requests unconstrained.</p>
+            </div>
+
             <div className="doc-section" id="type-toolcallschemaparser">
               <h4>ToolCallSchemaParser</h4>
               <pre className="code-block">
@@ -1119,6 +1338,16 @@ export default function DocsSDKModel() {
               <p className="doc-description">ToolCallSchemaParser is optionally implemented by parsers whose native tool call format does not encode argument types. The request's tool declarations are supplied so the parser can convert raw values according to their schema.</p>
             </div>
 
+            <div className="doc-section" id="type-toolgrammar">
+              <h4>ToolGrammar</h4>
+              <pre className="code-block">
+                <code>{`type ToolGrammar struct {
+	// Has unexported fields.
+}`}</code>
+              </pre>
+              <p className="doc-description">ToolGrammar builds the grammar that forces a model to emit a tool call. Kronk converts each declared tool's parameter schema into GBNF rules, and the selected parser combines them into its native tool-call syntax through ToolCallGrammarParser. Rule names returned by the methods are valid only in the grammar being built.</p>
+            </div>
+
             <div className="doc-section" id="type-toplogprob">
               <h4>TopLogprob</h4>
               <pre className="code-block">
@@ -1131,6 +1360,14 @@ export default function DocsSDKModel() {
               <p className="doc-description">TopLogprob represents a single token with its log probability.</p>
             </div>
 
+            <div className="doc-section" id="type-truncationmode">
+              <h4>TruncationMode</h4>
+              <pre className="code-block">
+                <code>{`type TruncationMode string`}</code>
+              </pre>
+              <p className="doc-description">TruncationMode selects how a text chat request whose prompt does not fit the context window is shortened before generation. The zero value uses the model's configured mode, which defaults to TruncationDisabled.</p>
+            </div>
+
             <div className="doc-section" id="type-usage">
               <h4>Usage</h4>
               <pre className="code-block">
@@ -1168,6 +1405,14 @@ export default function DocsSDKModel() {
           <div className="card" id="methods">
             <h3>Methods</h3>
 
+            <div className="doc-section" id="method-adapterconfig-adapterid">
+              <h4>AdapterConfig.AdapterID</h4>
+              <pre className="code-block">
+                <code>func (a AdapterConfig) AdapterID() string</code>
+              </pre>
+              <p className="doc-description">AdapterID returns the ID requests use to select the adapter.</p>
+            </div>
+
             <div className="doc-section" id="method-choice-finishreason">
               <h4>Choice.FinishReason</h4>
               <pre className="code-block">
@@ -1190,6 +1435,13 @@ export default function DocsSDKModel() {
               </pre>
             </div>
 
+            <div className="doc-section" id="method-config-contextshift">
+              <h4>Config.ContextShift</h4>
+              <pre className="code-block">
+                <code>func (cfg Config) ContextShift() bool</code>
+              </pre>
+            </div>
+
             <div className="doc-section" id="method-config-contextwindow">
               <h4>Config.ContextWindow</h4>
               <pre className="code-block">
@@ -1343,6 +1595,14 @@ export default function DocsSDKModel() {
               </pre>
             </div>
 
+            <div className="doc-section" id="method-config-truncationmode">
+              <h4>Config.TruncationMode</h4>
+              <pre className="code-block">
+                <code>func (cfg Config) TruncationMode() TruncationMode</code>
+              </pre>
+              <p className="doc-description">TruncationMode returns the default truncation mode for text chat requests. The zero value disables truncation.</p>
+            </div>
+
             <div className="doc-section" id="method-config-yarnattnfactor">
               <h4>Config.YarnAttnFactor</h4>
               <pre className="code-block">
@@ -1431,6 +1691,22 @@ export default function DocsSDKModel() {
               </pre>
             </div>
 
+            <div className="doc-section" id="method-embedchunk-marshaljson">
+              <h4>EmbedChunk.MarshalJSON</h4>
+              <pre className="code-block">
+                <code>func (c EmbedChunk) MarshalJSON() ([]byte, error)</code>
+              </pre>
+              <p className="doc-description">MarshalJSON encodes the embedding as base64 when the request asked for the base64 encoding format.</p>
+            </div>
+
+            <div className="doc-section" id="method-embeddata-marshaljson">
+              <h4>EmbedData.MarshalJSON</h4>
+              <pre className="code-block">
+                <code>func (e EmbedData) MarshalJSON() ([]byte, error)</code>
+              </pre>
+              <p className="doc-description">MarshalJSON encodes the embedding as base64 when the request asked for the base64 encoding format.</p>
+            </div>
+
             <div className="doc-section" id="method-flashattentiontype-marshaljson">
               <h4>FlashAttentionType.MarshalJSON</h4>
               <pre className="code-block">
@@ -1606,6 +1882,14 @@ export default function DocsSDKModel() {
               <p className="doc-description">UnmarshalText parses serialized text into a known MoEMode.</p>
             </div>
 
+            <div className="doc-section" id="method-model-adapters">
+              <h4>Model.Adapters</h4>
+              <pre className="code-block">
+                <code>func (m *Model) Adapters() []AdapterDetail</code>
+              </pre>
+              <p className="doc-description">Adapters returns the LoRA adapters loaded against the model in load order.</p>
+            </div>
+
             <div className="doc-section" id="method-model-batchenginesnapshot">
               <h4>Model.BatchEngineSnapshot</h4>
               <pre className="code-block">
@@ -1619,7 +1903,7 @@ export default function DocsSDKModel() {
               <pre className="code-block">
                 <code>func (m *Model) Chat(ctx context.Context, d D) (ChatResponse, error)</code>
               </pre>
-              <p className="doc-description">Chat performs a chat request and returns the final response. All requests (including vision/audio) use batch processing and can run concurrently based on the NSeqMax config value, which controls parallel sequence processing.</p>
+              <p className="doc-description">Chat performs a chat request and returns the final response. All requests (including vision/audio) use batch processing and can run concurrently based on the NSeqMax config value, which controls parallel sequence processing. When n requests more than one choice, the response holds one choice per index and its usage covers every choice.</p>
             </div>
 
             <div className="doc-section" id="method-model-chatstreaming">
@@ -1627,7 +1911,23 @@ export default function DocsSDKModel() {
               <pre className="code-block">
                 <code>func (m *Model) ChatStreaming(ctx context.Context, d D) (&lt;-chan ChatResponse, error)</code>
               </pre>
-              <p className="doc-description">ChatStreaming performs a chat request and streams the response. All requests (including vision/audio) use batch processing and can run concurrently based on the NSeqMax config value, which controls parallel sequence processing. When stream_options.include_usage is true, the terminal choice is followed by a usage response with an empty Choices slice. Validation failures are returned before a response channel is created.</p>
+              <p className="doc-description">ChatStreaming performs a chat request and streams the response. All requests (including vision/audio) use batch processing and can run concurrently based on the NSeqMax config value, which controls parallel sequence processing. When stream_options.include_usage is true, the terminal choice is followed by a usage response with an empty Choices slice. When n requests more than one choice, responses for every choice index are interleaved on the channel and a single usage response follows the last terminal choice. Validation failures are returned before a response channel is created.</p>
+            </div>
+
+            <div className="doc-section" id="method-model-completion">
+              <h4>Model.Completion</h4>
+              <pre className="code-block">
+                <code>func (m *Model) Completion(ctx context.Context, d D) (ChatResponse, error)</code>
+              </pre>
+              <p className="doc-description">Completion performs a raw completion request and returns the final response. The prompt is tokenized as given, without the chat template, and the generated text is returned as answer content without reasoning or tool call parsing. A request with a suffix field is a fill-in-the-middle request built from the model's FIM tokens.</p>
+            </div>
+
+            <div className="doc-section" id="method-model-completionstreaming">
+              <h4>Model.CompletionStreaming</h4>
+              <pre className="code-block">
+                <code>func (m *Model) CompletionStreaming(ctx context.Context, d D) (&lt;-chan ChatResponse, error)</code>
+              </pre>
+              <p className="doc-description">CompletionStreaming performs a raw completion request and streams the response. Responses follow the same shape as ChatStreaming with all generated text in the content deltas.</p>
             </div>
 
             <div className="doc-section" id="method-model-config">
@@ -1642,7 +1942,15 @@ export default function DocsSDKModel() {
               <pre className="code-block">
                 <code>func (m *Model) Embeddings(ctx context.Context, d D) (response EmbedReponse, err error)</code>
               </pre>
-              <p className="doc-description">Embeddings performs embedding for one or more inputs. Supported options in d: - input ([]string): the texts to embed (required) - truncate (bool): if true, truncate inputs to fit context window (default: false) - truncate_direction (string): "right" (default) or "left" - dimensions (int): reduce output to first N dimensions (for Matryoshka models) Supported models process inputs together as a multi-sequence batch. Other models use the context-pool fallback.</p>
+              <p className="doc-description">Embeddings performs embedding for one or more inputs. Supported options in d: - input (string, []string, []int, or [][]int): the texts or token IDs to embed (required) - truncate (bool): if true, truncate inputs to fit context window (default: false) - truncate_direction (string): "right" (default) or "left" - dimensions (int): reduce output to first N dimensions (for Matryoshka models) - encoding_format (string): "float" (default) or "base64" - chunking (bool or object): split over-long inputs into overlapping chunks and pool them The chunking object holds size (tokens per chunk, default: the most the model accepts), overlap (tokens shared by neighboring chunks, default: 0), pooling ("mean" weighted by chunk length, the default, or "max"), and return_chunks (include each chunk's embedding and token offsets). Supported models process inputs together as a multi-sequence batch. Other models use the context-pool fallback.</p>
+            </div>
+
+            <div className="doc-section" id="method-model-exportimcsession">
+              <h4>Model.ExportIMCSession</h4>
+              <pre className="code-block">
+                <code>func (m *Model) ExportIMCSession(w io.Writer, id int) error</code>
+              </pre>
+              <p className="doc-description">ExportIMCSession writes the snapshot and metadata of the IMC session with the given id to w in a format ImportIMCSession can restore on the same model, in this process or another one. The session is reserved while it is written, so requests that would reuse it rebuild elsewhere until the export finishes. Media sessions cannot be exported.</p>
             </div>
 
             <div className="doc-section" id="method-model-imcsessions">
@@ -1661,6 +1969,22 @@ export default function DocsSDKModel() {
               <p className="doc-description">IMCSystemCaches returns every entry in the immutable System preload pool.</p>
             </div>
 
+            <div className="doc-section" id="method-model-importimcsession">
+              <h4>Model.ImportIMCSession</h4>
+              <pre className="code-block">
+                <code>func (m *Model) ImportIMCSession(r io.Reader) (IMCSessionDetail, error)</code>
+              </pre>
+              <p className="doc-description">ImportIMCSession restores a session written by ExportIMCSession for the same model. A keyed session replaces the idle session holding the same key; otherwise an empty session, or the least recently used idle one, receives the import. It returns the state of the restored session.</p>
+            </div>
+
+            <div className="doc-section" id="method-model-loadadapter">
+              <h4>Model.LoadAdapter</h4>
+              <pre className="code-block">
+                <code>func (m *Model) LoadAdapter(ctx context.Context, adapter AdapterConfig) (AdapterDetail, error)</code>
+              </pre>
+              <p className="doc-description">LoadAdapter loads a LoRA adapter against the running model so requests can select it by ID. Unlike configured adapters, it is never applied to requests that do not select it, and it only lives as long as the loaded model. Only text generation models accept adapters at runtime.</p>
+            </div>
+
             <div className="doc-section" id="method-model-modelinfo">
               <h4>Model.ModelInfo</h4>
               <pre className="code-block">
@@ -1681,7 +2005,7 @@ export default function DocsSDKModel() {
               <pre className="code-block">
                 <code>func (m *Model) Tokenize(ctx context.Context, d D) (TokenizeResponse, error)</code>
               </pre>
-              <p className="doc-description">Tokenize returns the token count for a text input. Supported options in d: - input (string): the text to tokenize (required) - apply_template (bool): if true, wrap input as a user message and apply the model's chat template before tokenizing (default: false) - add_generation_prompt (bool): when apply_template is true, controls whether the assistant role prefix is appended to the prompt (default: true) When apply_template is true, the returned count includes all template overhead (role markers, separators, generation prompt). This reflects the actual number of tokens that would be fed to the model.</p>
+              <p className="doc-description">Tokenize returns the token count for a text input. Supported options in d: - input (string): the text to tokenize (required unless messages is set) - apply_template (bool): if true, wrap input as a user message and apply the model's chat template before tokenizing (default: false) - add_generation_prompt (bool): when apply_template is true, controls whether the assistant role prefix is appended to the prompt (default: true) - messages ([]D): a chat conversation to count instead of input. It is rendered with the model's chat template together with the tools and thinking settings in d, as a chat request would be. Media content is not counted. When apply_template is true, the returned count includes all template overhead (role markers, separators, generation prompt). This reflects the actual number of tokens that would be fed to the model.</p>
             </div>
 
             <div className="doc-section" id="method-model-unload">
@@ -1691,6 +2015,14 @@ export default function DocsSDKModel() {
               </pre>
             </div>
 
+            <div className="doc-section" id="method-model-unloadadapter">
+              <h4>Model.UnloadAdapter</h4>
+              <pre className="code-block">
+                <code>func (m *Model) UnloadAdapter(ctx context.Context, id string) error</code>
+              </pre>
+              <p className="doc-description">UnloadAdapter unloads an adapter loaded with LoadAdapter. New requests can no longer select it and queued requests that selected it fail; requests already generating with it finish first, and the batch engine frees the adapter once no context applies it.</p>
+            </div>
+
             <div className="doc-section" id="method-modelinfo-string">
               <h4>ModelInfo.String</h4>
               <pre className="code-block">
@@ -1714,6 +2046,30 @@ export default function DocsSDKModel() {
               <p className="doc-description">String returns a string representation of all resolved Params values in the format key[value]\nkey[value]\n ... Grammar contents are intentionally redacted; only whether a grammar is active is reported.</p>
             </div>
 
+            <div className="doc-section" id="method-priority-marshaltext">
+              <h4>Priority.MarshalText</h4>
+              <pre className="code-block">
+                <code>func (p Priority) MarshalText() ([]byte, error)</code>
+              </pre>
+              <p className="doc-description">MarshalText provides support for logging and serialization.</p>
+            </div>
+
+            <div className="doc-section" id="method-priority-string">
+              <h4>Priority.String</h4>
+              <pre className="code-block">
+                <code>func (p Priority) String() string</code>
+              </pre>
+              <p className="doc-description">String returns the name of the priority.</p>
+            </div>
+
+            <div className="doc-section" id="method-priority-unmarshaltext">
+              <h4>Priority.UnmarshalText</h4>
+              <pre className="code-block">
+                <code>func (p *Priority) UnmarshalText(data []byte) error</code>
+              </pre>
+              <p className="doc-description">UnmarshalText parses serialized text into a Priority.</p>
+            </div>
+
             <div className="doc-section" id="method-responsemessage-marshaljson">
               <h4>ResponseMessage.MarshalJSON</h4>
               <pre className="code-block">
@@ -1841,6 +2197,86 @@ export default function DocsSDKModel() {
                 <code>func (a *ToolCallArguments) UnmarshalJSON(data []byte) error</code>
               </pre>
             </div>
+
+            <div className="doc-section" id="method-toolgrammar-alternatives">
+              <h4>ToolGrammar.Alternatives</h4>
+              <pre className="code-block">
+                <code>func (tg *ToolGrammar) Alternatives(options []string) string</code>
+              </pre>
+              <p className="doc-description">Alternatives returns a rule matching any one of options.</p>
+            </div>
+
+            <div className="doc-section" id="method-toolgrammar-arguments">
+              <h4>ToolGrammar.Arguments</h4>
+              <pre className="code-block">
+                <code>func (tg *ToolGrammar) Arguments(name string) (string, error)</code>
+              </pre>
+              <p className="doc-description">Arguments returns the rule matching the JSON object of arguments for the named tool.</p>
+            </div>
+
+            <div className="doc-section" id="method-toolgrammar-jsonenvelope">
+              <h4>ToolGrammar.JSONEnvelope</h4>
+              <pre className="code-block">
+                <code>func (tg *ToolGrammar) JSONEnvelope(argumentsKey string) (string, error)</code>
+              </pre>
+              <p className="doc-description">JSONEnvelope returns a rule matching one call in the common JSON envelope &#123;"name": "&lt;tool&gt;", "&lt;argumentsKey&gt;": &#123;...&#125;&#125;, for any declared tool.</p>
+            </div>
+
+            <div className="doc-section" id="method-toolgrammar-literal">
+              <h4>ToolGrammar.Literal</h4>
+              <pre className="code-block">
+                <code>func (tg *ToolGrammar) Literal(s string) string</code>
+              </pre>
+              <p className="doc-description">Literal returns the grammar literal matching s exactly.</p>
+            </div>
+
+            <div className="doc-section" id="method-toolgrammar-names">
+              <h4>ToolGrammar.Names</h4>
+              <pre className="code-block">
+                <code>func (tg *ToolGrammar) Names() []string</code>
+              </pre>
+              <p className="doc-description">Names returns the names of the tools a call may use.</p>
+            </div>
+
+            <div className="doc-section" id="method-toolgrammar-parallel">
+              <h4>ToolGrammar.Parallel</h4>
+              <pre className="code-block">
+                <code>func (tg *ToolGrammar) Parallel() bool</code>
+              </pre>
+              <p className="doc-description">Parallel reports whether the request allows more than one tool call.</p>
+            </div>
+
+            <div className="doc-section" id="method-toolgrammar-parameters">
+              <h4>ToolGrammar.Parameters</h4>
+              <pre className="code-block">
+                <code>func (tg *ToolGrammar) Parameters(name string) ([]GrammarParameter, error)</code>
+              </pre>
+              <p className="doc-description">Parameters returns the declared parameters of the named tool, required parameters first and each group sorted by name.</p>
+            </div>
+
+            <div className="doc-section" id="method-toolgrammar-repeat">
+              <h4>ToolGrammar.Repeat</h4>
+              <pre className="code-block">
+                <code>func (tg *ToolGrammar) Repeat(call string, sep string) string</code>
+              </pre>
+              <p className="doc-description">Repeat returns call once, or one or more times separated by sep when the request allows parallel tool calls.</p>
+            </div>
+
+            <div className="doc-section" id="method-toolgrammar-rule">
+              <h4>ToolGrammar.Rule</h4>
+              <pre className="code-block">
+                <code>func (tg *ToolGrammar) Rule(name string, body string) string</code>
+              </pre>
+              <p className="doc-description">Rule adds a rule to the grammar and returns its name, which is derived from name and made unique.</p>
+            </div>
+
+            <div className="doc-section" id="method-toolgrammar-textuntil">
+              <h4>ToolGrammar.TextUntil</h4>
+              <pre className="code-block">
+                <code>func (tg *ToolGrammar) TextUntil(stop string, exclude string) string</code>
+              </pre>
+              <p className="doc-description">TextUntil returns a rule matching any text that does not contain stop or any character in exclude, for raw argument values closed by a marker. Such values are not checked against the parameter's schema beyond being text.</p>
+            </div>
           </div>
 
           <div className="card" id="constants">
@@ -2057,6 +2493,7 @@ export default function DocsSDKModel() {
 	SpeculationDisabled = internalspec.ModeDisabled
 	SpeculationClassic  = internalspec.ModeClassic
 	SpeculationMTP      = internalspec.ModeMTP
+	SpeculationNGram    = internalspec.ModeNGram
 )`}</code>
               </pre>
             </div>
@@ -2089,12 +2526,60 @@ export default function DocsSDKModel() {
           <div className="card" id="variables">
             <h3>Variables</h3>
 
+            <div className="doc-section" id="var-erradapterconfigured">
+              <h4>ErrAdapterConfigured</h4>
+              <pre className="code-block">
+                <code>{`var ErrAdapterConfigured = errors.New("adapter belongs to the model configuration")`}</code>
+              </pre>
+              <p className="doc-description">ErrAdapterConfigured indicates an attempt to unload an adapter that belongs to the model configuration.</p>
+            </div>
+
+            <div className="doc-section" id="var-erradapterexists">
+              <h4>ErrAdapterExists</h4>
+              <pre className="code-block">
+                <code>{`var ErrAdapterExists = errors.New("adapter already loaded")`}</code>
+              </pre>
+              <p className="doc-description">ErrAdapterExists indicates that a loaded adapter already uses the ID or file of an adapter being loaded.</p>
+            </div>
+
+            <div className="doc-section" id="var-erradapternotfound">
+              <h4>ErrAdapterNotFound</h4>
+              <pre className="code-block">
+                <code>{`var ErrAdapterNotFound = errors.New("adapter not found")`}</code>
+              </pre>
+              <p className="doc-description">ErrAdapterNotFound indicates that no loaded adapter has the requested ID.</p>
+            </div>
+
+            <div className="doc-section" id="var-errfimunsupported">
+              <h4>ErrFIMUnsupported</h4>
+              <pre className="code-block">
+                <code>{`var ErrFIMUnsupported = fmt.Errorf("%w: model does not support fill-in-the-middle", ErrInvalidRequest)`}</code>
+              </pre>
+              <p className="doc-description">ErrFIMUnsupported indicates that a fill-in-the-middle request was made to a model whose vocabulary has no FIM prefix, suffix, and middle tokens.</p>
+            </div>
+
             <div className="doc-section" id="var-errfileinputsunsupported">
               <h4>ErrFileInputsUnsupported</h4>
               <pre className="code-block">
                 <code>{`var ErrFileInputsUnsupported = errors.New("file inputs are not currently supported")`}</code>
               </pre>
-              <p className="doc-description">ErrFileInputsUnsupported indicates file content parts are not supported.</p>
+              <p className="doc-description">ErrFileInputsUnsupported indicates file content parts that were not resolved by ResolveFileInputs.</p>
+            </div>
+
+            <div className="doc-section" id="var-errimcsessionbusy">
+              <h4>ErrIMCSessionBusy</h4>
+              <pre className="code-block">
+                <code>{`var ErrIMCSessionBusy = errors.New("imc session busy")`}</code>
+              </pre>
+              <p className="doc-description">ErrIMCSessionBusy indicates that an IMC session is reserved by an in-flight request, or that no session is free to receive an import.</p>
+            </div>
+
+            <div className="doc-section" id="var-errimcsessionnotfound">
+              <h4>ErrIMCSessionNotFound</h4>
+              <pre className="code-block">
+                <code>{`var ErrIMCSessionNotFound = errors.New("imc session not found")`}</code>
+              </pre>
+              <p className="doc-description">ErrIMCSessionNotFound indicates that an IMC session does not exist or holds no snapshot to export.</p>
             </div>
 
             <div className="doc-section" id="var-errinvalidrequest">
@@ -2121,6 +2606,14 @@ export default function DocsSDKModel() {
               <p className="doc-description">ErrMessagesMissing indicates that a chat request has no messages field.</p>
             </div>
 
+            <div className="doc-section" id="var-errpromptmissing">
+              <h4>ErrPromptMissing</h4>
+              <pre className="code-block">
+                <code>{`var ErrPromptMissing = fmt.Errorf("%w: no prompt found in request", ErrInvalidRequest)`}</code>
+              </pre>
+              <p className="doc-description">ErrPromptMissing indicates that a completion request has no prompt field.</p>
+            </div>
+
             <div className="doc-section" id="var-moemodeauto">
               <h4>MoEModeAuto</h4>
               <pre className="code-block">
@@ -2160,6 +2653,14 @@ export default function DocsSDKModel() {
               </pre>
               <p className="doc-description">MoEModeKeepTopN keeps routed experts on GPU for the top N layers. All other expert layers go to CPU.</p>
             </div>
+
+            <div className="doc-section" id="var-priorities">
+              <h4>Priorities</h4>
+              <pre className="code-block">
+                <code>{`var Priorities = [...]Priority{PriorityHigh, PriorityNormal, PriorityLow}`}</code>
+              </pre>
+              <p className="doc-description">Priorities lists the scheduling priorities from highest to lowest.</p>
+            </div>
           </div>
         </div>
 
@@ -2169,21 +2670,29 @@ export default function DocsSDKModel() {
               <a href="#functions" className="doc-index-header">Functions</a>
               <ul>
                 <li><a href="#func-addparams">AddParams</a></li>
+                <li><a href="#func-buildtoolcallgrammar">BuildToolCallGrammar</a></li>
                 <li><a href="#func-detectmodeltypefromfiles">DetectModelTypeFromFiles</a></li>
                 <li><a href="#func-getembeddingsprenorm">GetEmbeddingsPreNorm</a></li>
                 <li><a href="#func-getembeddingsprenormith">GetEmbeddingsPreNormIth</a></li>
                 <li><a href="#func-inityzmaworkarounds">InitYzmaWorkarounds</a></li>
+                <li><a href="#func-jsonobjectprefix">JSONObjectPrefix</a></li>
                 <li><a href="#func-mtpavailable">MTPAvailable</a></li>
                 <li><a href="#func-newmodel">NewModel</a></li>
                 <li><a href="#func-parseggmltype">ParseGGMLType</a></li>
                 <li><a href="#func-parseloadmode">ParseLoadMode</a></li>
                 <li><a href="#func-parsemoemode">ParseMoEMode</a></li>
+                <li><a href="#func-parsepriority">ParsePriority</a></li>
                 <li><a href="#func-parseropescalingtype">ParseRopeScalingType</a></li>
                 <li><a href="#func-parsesplitmode">ParseSplitMode</a></li>
+                <li><a href="#func-parsetruncationmode">ParseTruncationMode</a></li>
                 <li><a href="#func-recurrentstatecopies">RecurrentStateCopies</a></li>
                 <li><a href="#func-registerparser">RegisterParser</a></li>
+                <li><a href="#func-resolvefileinputs">ResolveFileInputs</a></li>
+                <li><a href="#func-resolvemediaurls">ResolveMediaURLs</a></li>
                 <li><a href="#func-setembeddingsprenorm">SetEmbeddingsPreNorm</a></li>
+                <li><a href="#func-setschedule">SetSchedule</a></li>
                 <li><a href="#func-validatechatrequest">ValidateChatRequest</a></li>
+                <li><a href="#func-validatecompletionrequest">ValidateCompletionRequest</a></li>
                 <li><a href="#func-validatemessages">ValidateMessages</a></li>
                 <li><a href="#func-verifyartifact">VerifyArtifact</a></li>
                 <li><a href="#func-newgrammarsampler">NewGrammarSampler</a></li>
@@ -2193,6 +2702,8 @@ export default function DocsSDKModel() {
               <a href="#types" className="doc-index-header">Types</a>
               <ul>
                 <li><a href="#type-adapterconfig">AdapterConfig</a></li>
+                <li><a href="#type-adapterdetail">AdapterDetail</a></li>
+                <li><a href="#type-adapterselection">AdapterSelection</a></li>
                 <li><a href="#type-artifactdigest">ArtifactDigest</a></li>
                 <li><a href="#type-artifactintegrity">ArtifactIntegrity</a></li>
                 <li><a href="#type-artifactverification">ArtifactVerification</a></li>
@@ -2208,18 +2719,23 @@ export default function DocsSDKModel() {
                 <li><a href="#type-contentlogprob">ContentLogprob</a></li>
                 <li><a href="#type-d">D</a></li>
                 <li><a href="#type-draftmodelconfig">DraftModelConfig</a></li>
+                <li><a href="#type-embedchunk">EmbedChunk</a></li>
                 <li><a href="#type-embeddata">EmbedData</a></li>
                 <li><a href="#type-embedreponse">EmbedReponse</a></li>
                 <li><a href="#type-embedusage">EmbedUsage</a></li>
+                <li><a href="#type-filedata">FileData</a></li>
+                <li><a href="#type-filelookup">FileLookup</a></li>
                 <li><a href="#type-fingerprint">Fingerprint</a></li>
                 <li><a href="#type-flashattentiontype">FlashAttentionType</a></li>
                 <li><a href="#type-ggmltype">GGMLType</a></li>
+                <li><a href="#type-grammarparameter">GrammarParameter</a></li>
                 <li><a href="#type-imcsessiondetail">IMCSessionDetail</a></li>
                 <li><a href="#type-imcsessionstate">IMCSessionState</a></li>
                 <li><a href="#type-imcsystemcachedetail">IMCSystemCacheDetail</a></li>
                 <li><a href="#type-loadmode">LoadMode</a></li>
                 <li><a href="#type-logger">Logger</a></li>
                 <li><a href="#type-logprobs">Logprobs</a></li>
+                <li><a href="#type-mediafetcher">MediaFetcher</a></li>
                 <li><a href="#type-mediatype">MediaType</a></li>
                 <li><a href="#type-moeconfig">MoEConfig</a></li>
                 <li><a href="#type-moemode">MoEMode</a></li>
@@ -2231,6 +2747,7 @@ export default function DocsSDKModel() {
                 <li><a href="#type-paramsadjuster">ParamsAdjuster</a></li>
                 <li><a href="#type-parser">Parser</a></li>
                 <li><a href="#type-parserfactory">ParserFactory</a></li>
+                <li><a href="#type-priority">Priority</a></li>
                 <li><a href="#type-prompttokensdetails">PromptTokensDetails</a></li>
                 <li><a href="#type-rerankresponse">RerankResponse</a></li>
                 <li><a href="#type-rerankresult">RerankResult</a></li>
@@ -2242,6 +2759,7 @@ export default function DocsSDKModel() {
                 <li><a href="#type-responsetoolcallfunction">ResponseToolCallFunction</a></li>
                 <li><a href="#type-result">Result</a></li>
                 <li><a href="#type-ropescalingtype">RopeScalingType</a></li>
+                <li><a href="#type-schedule">Schedule</a></li>
                 <li><a href="#type-sessionstore">SessionStore</a></li>
                 <li><a href="#type-sessionstorefactory">SessionStoreFactory</a></li>
                 <li><a href="#type-speculationmode">SpeculationMode</a></li>
@@ -2252,10 +2770,14 @@ export default function DocsSDKModel() {
                 <li><a href="#type-template">Template</a></li>
                 <li><a href="#type-tokenizeresponse">TokenizeResponse</a></li>
                 <li><a href="#type-toolawarestatemachine">ToolAwareStateMachine</a></li>
+                <li><a href="#type-toolcallargumentstreamer">ToolCallArgumentStreamer</a></li>
                 <li><a href="#type-toolcallarguments">ToolCallArguments</a></li>
                 <li><a href="#type-toolcalldeltastreamer">ToolCallDeltaStreamer</a></li>
+                <li><a href="#type-toolcallgrammarparser">ToolCallGrammarParser</a></li>
                 <li><a href="#type-toolcallschemaparser">ToolCallSchemaParser</a></li>
+                <li><a href="#type-toolgrammar">ToolGrammar</a></li>
                 <li><a href="#type-toplogprob">TopLogprob</a></li>
+                <li><a href="#type-truncationmode">TruncationMode</a></li>
                 <li><a href="#type-usage">Usage</a></li>
                 <li><a href="#type-vocabeogconsumer">VocabEOGConsumer</a></li>
               </ul>
@@ -2263,9 +2785,11 @@ export default function DocsSDKModel() {
             <div className="doc-index-section">
               <a href="#methods" className="doc-index-header">Methods</a>
               <ul>
+                <li><a href="#method-adapterconfig-adapterid">AdapterConfig.AdapterID</a></li>
                 <li><a href="#method-choice-finishreason">Choice.FinishReason</a></li>
                 <li><a href="#method-config-admissiontimeout">Config.AdmissionTimeout</a></li>
                 <li><a href="#method-config-cachemintokens">Config.CacheMinTokens</a></li>
+                <li><a href="#method-config-contextshift">Config.ContextShift</a></li>
                 <li><a href="#method-config-contextwindow">Config.ContextWindow</a></li>
                 <li><a href="#method-config-effectivenbatch">Config.EffectiveNBatch</a></li>
                 <li><a href="#method-config-effectivenubatch">Config.EffectiveNUBatch</a></li>
@@ -2287,6 +2811,7 @@ export default function DocsSDKModel() {
                 <li><a href="#method-config-swafull">Config.SWAFull</a></li>
                 <li><a href="#method-config-speculationmode">Config.SpeculationMode</a></li>
                 <li><a href="#method-config-string">Config.String</a></li>
+                <li><a href="#method-config-truncationmode">Config.TruncationMode</a></li>
                 <li><a href="#method-config-yarnattnfactor">Config.YarnAttnFactor</a></li>
                 <li><a href="#method-config-yarnbetafast">Config.YarnBetaFast</a></li>
                 <li><a href="#method-config-yarnbetaslow">Config.YarnBetaSlow</a></li>
@@ -2299,6 +2824,8 @@ export default function DocsSDKModel() {
                 <li><a href="#method-draftmodelconfig-isseparate">DraftModelConfig.IsSeparate</a></li>
                 <li><a href="#method-draftmodelconfig-maingpu">DraftModelConfig.MainGPU</a></li>
                 <li><a href="#method-draftmodelconfig-ngpulayers">DraftModelConfig.NGpuLayers</a></li>
+                <li><a href="#method-embedchunk-marshaljson">EmbedChunk.MarshalJSON</a></li>
+                <li><a href="#method-embeddata-marshaljson">EmbedData.MarshalJSON</a></li>
                 <li><a href="#method-flashattentiontype-marshaljson">FlashAttentionType.MarshalJSON</a></li>
                 <li><a href="#method-flashattentiontype-marshalyaml">FlashAttentionType.MarshalYAML</a></li>
                 <li><a href="#method-flashattentiontype-string">FlashAttentionType.String</a></li>
@@ -2322,20 +2849,30 @@ export default function DocsSDKModel() {
                 <li><a href="#method-moemode-marshaltext">MoEMode.MarshalText</a></li>
                 <li><a href="#method-moemode-string">MoEMode.String</a></li>
                 <li><a href="#method-moemode-unmarshaltext">MoEMode.UnmarshalText</a></li>
+                <li><a href="#method-model-adapters">Model.Adapters</a></li>
                 <li><a href="#method-model-batchenginesnapshot">Model.BatchEngineSnapshot</a></li>
                 <li><a href="#method-model-chat">Model.Chat</a></li>
                 <li><a href="#method-model-chatstreaming">Model.ChatStreaming</a></li>
+                <li><a href="#method-model-completion">Model.Completion</a></li>
+                <li><a href="#method-model-completionstreaming">Model.CompletionStreaming</a></li>
                 <li><a href="#method-model-config">Model.Config</a></li>
                 <li><a href="#method-model-embeddings">Model.Embeddings</a></li>
+                <li><a href="#method-model-exportimcsession">Model.ExportIMCSession</a></li>
                 <li><a href="#method-model-imcsessions">Model.IMCSessions</a></li>
                 <li><a href="#method-model-imcsystemcaches">Model.IMCSystemCaches</a></li>
+                <li><a href="#method-model-importimcsession">Model.ImportIMCSession</a></li>
+                <li><a href="#method-model-loadadapter">Model.LoadAdapter</a></li>
                 <li><a href="#method-model-modelinfo">Model.ModelInfo</a></li>
                 <li><a href="#method-model-rerank">Model.Rerank</a></li>
                 <li><a href="#method-model-tokenize">Model.Tokenize</a></li>
                 <li><a href="#method-model-unload">Model.Unload</a></li>
+                <li><a href="#method-model-unloadadapter">Model.UnloadAdapter</a></li>
                 <li><a href="#method-modelinfo-string">ModelInfo.String</a></li>
                 <li><a href="#method-modeltype-string">ModelType.String</a></li>
                 <li><a href="#method-params-string">Params.String</a></li>
+                <li><a href="#method-priority-marshaltext">Priority.MarshalText</a></li>
+                <li><a href="#method-priority-string">Priority.String</a></li>
+                <li><a href="#method-priority-unmarshaltext">Priority.UnmarshalText</a></li>
                 <li><a href="#method-responsemessage-marshaljson">ResponseMessage.MarshalJSON</a></li>
                 <li><a href="#method-ropescalingtype-marshaljson">RopeScalingType.MarshalJSON</a></li>
                 <li><a href="#method-ropescalingtype-marshalyaml">RopeScalingType.MarshalYAML</a></li>
@@ -2353,6 +2890,16 @@ export default function DocsSDKModel() {
                 <li><a href="#method-streamingresponselogger-string">StreamingResponseLogger.String</a></li>
                 <li><a href="#method-toolcallarguments-marshaljson">ToolCallArguments.MarshalJSON</a></li>
                 <li><a href="#method-toolcallarguments-unmarshaljson">ToolCallArguments.UnmarshalJSON</a></li>
+                <li><a href="#method-toolgrammar-alternatives">ToolGrammar.Alternatives</a></li>
+                <li><a href="#method-toolgrammar-arguments">ToolGrammar.Arguments</a></li>
+                <li><a href="#method-toolgrammar-jsonenvelope">ToolGrammar.JSONEnvelope</a></li>
+                <li><a href="#method-toolgrammar-literal">ToolGrammar.Literal</a></li>
+                <li><a href="#method-toolgrammar-names">ToolGrammar.Names</a></li>
+                <li><a href="#method-toolgrammar-parallel">ToolGrammar.Parallel</a></li>
+                <li><a href="#method-toolgrammar-parameters">ToolGrammar.Parameters</a></li>
+                <li><a href="#method-toolgrammar-repeat">ToolGrammar.Repeat</a></li>
+                <li><a href="#method-toolgrammar-rule">ToolGrammar.Rule</a></li>
+                <li><a href="#method-toolgrammar-textuntil">ToolGrammar.TextUntil</a></li>
               </ul>
             </div>
             <div className="doc-index-section">
@@ -2374,15 +2921,23 @@ export default function DocsSDKModel() {
             <div className="doc-index-section">
               <a href="#variables" className="doc-index-header">Variables</a>
               <ul>
+                <li><a href="#var-erradapterconfigured">ErrAdapterConfigured</a></li>
+                <li><a href="#var-erradapterexists">ErrAdapterExists</a></li>
+                <li><a href="#var-erradapternotfound">ErrAdapterNotFound</a></li>
+                <li><a href="#var-errfimunsupported">ErrFIMUnsupported</a></li>
                 <li><a href="#var-errfileinputsunsupported">ErrFileInputsUnsupported</a></li>
+                <li><a href="#var-errimcsessionbusy">ErrIMCSessionBusy</a></li>
+                <li><a href="#var-errimcsessionnotfound">ErrIMCSessionNotFound</a></li>
                 <li><a href="#var-errinvalidrequest">ErrInvalidRequest</a></li>
                 <li><a href="#var-errmessagesinvalid">ErrMessagesInvalid</a></li>
                 <li><a href="#var-errmessagesmissing">ErrMessagesMissing</a></li>
+                <li><a href="#var-errpromptmissing">ErrPromptMissing</a></li>
                 <li><a href="#var-moemodeauto">MoEModeAuto</a></li>
                 <li><a href="#var-moemodecustom">MoEModeCustom</a></li>
                 <li><a href="#var-moemodeexpertscpu">MoEModeExpertsCPU</a></li>
                 <li><a href="#var-moemodeexpertsgpu">MoEModeExpertsGPU</a></li>
                 <li><a href="#var-moemodekeeptopn">MoEModeKeepTopN</a></li>
+                <li><a href="#var-priorities">Priorities</a></li>
               </ul>
             </div>
           </div>
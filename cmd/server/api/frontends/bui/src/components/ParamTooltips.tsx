@@ -181,7 +181,7 @@ export const PARAM_TOOLTIPS = {
   // Batch engine slot diagnostics
   slotIteration: 'Latest scheduler loop iteration published by this model. It advances while requests are active or waiting.',
   slotBatchSizing: 'Configured prefill contribution, effective physical micro-batch capacity (NUBatch), and effective logical batch capacity (NBatch) used by the loaded model.',
-  slotQueue: 'Requests in the engine input channel plus requests already drained into the engine that are waiting for a free slot.',
+  slotQueue: 'Requests in the engine input channel, requests already drained into the engine that are waiting for a free slot, and generations paused to free a slot for a higher priority request.',
   slotPrefillSelector: 'Prefill stage 2 decodes the remaining request-owned tokens that were not available in the IMC session. The large value is the active slot; Next always shows the next eligible slot or round-robin cursor; Waiting lists all currently eligible slots.',
   slotIMCSelector: 'Prefill stage 1 decodes new stable-prefix tokens, such as completed tool calls, to extend an IMC session. The active slot retains ownership until its extension is complete; Next always shows the next eligible slot or round-robin cursor; Waiting lists all slots with extensions to decode.',
   slotEligible: 'Slot IDs with uncached request-tail tokens waiting for prefill stage 2. They remain listed while generation priority or another stage 2 owner delays their work.',
@@ -1,15 +1,20 @@
 import { useState } from 'react';
 import { api } from '../services/api';
-import { RateLimit, RateWindow } from '../types';
+import { RateLimit, RateWindow, TokenPriority } from '../types';
 
 const AVAILABLE_ENDPOINTS = [
   { label: '/v1/chat/completions', value: 'chat-completions' },
+  { label: '/v1/completions', value: 'completions' },
   { label: '/v1/embeddings', value: 'embeddings' },
   { label: '/v1/rerank', value: 'rerank' },
   { label: '/v1/responses', value: 'responses' },
   { label: '/v1/audio/transcriptions', value: 'transcriptions' },
+  { label: '/v1/audio/translations', value: 'translations' },
+  { label: '/v1/images', value: 'images' },
   { label: '/v1/messages', value: 'messages' },
   { label: '/v1/tokenize', value: 'tokenize' },
+  { label: '/v1/files', value: 'files' },
+  { label: '/v1/batches', value: 'batches' },
 ];
 
 const RATE_WINDOWS: { label: string; value: RateWindow }[] = [
@@ -44,6 +49,7 @@ export default function SecurityTokenCreate() {
   });
   const [duration, setDuration] = useState('24');
   const [durationUnit, setDurationUnit] = useState<'h' | 'd' | 'M' | 'y'>('h');
+  const [priority, setPriority] = useState<TokenPriority | ''>('');
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
   const [newToken, setNewToken] = useState<string | null>(null);
@@ -96,6 +102,7 @@ export default function SecurityTokenCreate() {
         admin: isAdmin,
         endpoints,
         duration: durationNs,
+        ...(priority && { priority }),
       });
       setNewToken(response.token);
     } catch (err) {
@@ -259,6 +266,19 @@ export default function SecurityTokenCreate() {
                   <option value="y">Years</option>
                 </select>
               </div>
+              <div className="form-group">
+                <label htmlFor="priority">Priority</label>
+                <select
+                  id="priority"
+                  value={priority}
+                  onChange={(e) => setPriority(e.target.value as TokenPriority | '')}
+                >
+                  <option value="">Server default</option>
+                  <option value="high">High</option>
+                  <option value="normal">Normal</option>
+                  <option value="low">Low</option>
+                </select>
+              </div>
             </div>
 
             <button
@@ -61,6 +61,7 @@ export type IMCSessionState = 'active' | 'idle' | 'empty';
 export interface IMCSessionDetail {
   model_id: string;
   id: number;
+  cache_key?: string;
   state: IMCSessionState;
   context: number;
   allocated: number;
@@ -125,6 +126,7 @@ export interface BatchEngineDetail {
   ndraft: number;
   queued_requests: number;
   pending_requests: number;
+  preempted_requests: number;
   prefill_selector_start: number;
   prefill_selector_selected: number;
   prefill_selector_next: number;
@@ -538,10 +540,13 @@ export interface RateLimit {
   window: RateWindow;
 }
 
+export type TokenPriority = 'low' | 'normal' | 'high';
+
 export interface TokenRequest {
   admin: boolean;
   endpoints: Record<string, RateLimit>;
   duration: number;
+  priority?: TokenPriority;
 }
 
 export interface TokenResponse {
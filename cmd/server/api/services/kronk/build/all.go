@@ -3,11 +3,16 @@ package build
 
 import (
 	"github.com/ardanlabs/kronk/cmd/server/app/domain/audioapp"
+	"github.com/ardanlabs/kronk/cmd/server/app/domain/batchapp"
 	"github.com/ardanlabs/kronk/cmd/server/app/domain/chatapp"
 	"github.com/ardanlabs/kronk/cmd/server/app/domain/checkapp"
+	"github.com/ardanlabs/kronk/cmd/server/app/domain/complapp"
 	"github.com/ardanlabs/kronk/cmd/server/app/domain/downapp"
 	"github.com/ardanlabs/kronk/cmd/server/app/domain/embedapp"
+	"github.com/ardanlabs/kronk/cmd/server/app/domain/fileapp"
+	"github.com/ardanlabs/kronk/cmd/server/app/domain/imageapp"
 	"github.com/ardanlabs/kronk/cmd/server/app/domain/msgsapp"
+	"github.com/ardanlabs/kronk/cmd/server/app/domain/ollamaapp"
 	"github.com/ardanlabs/kronk/cmd/server/app/domain/playgroundapp"
 	"github.com/ardanlabs/kronk/cmd/server/app/domain/rerankapp"
 	"github.com/ardanlabs/kronk/cmd/server/app/domain/respapp"
@@ -48,8 +53,11 @@ func (all) Add(app *web.App, cfg mux.Config) {
 		Log:               cfg.Log,
 		AuthClient:        cfg.AuthClient,
 		Pool:              cfg.Pool,
+		Files:             cfg.FileStore,
+		Media:             cfg.MediaFetcher,
 		AuthorizationMode: cfg.AuthorizationMode,
 		InferenceTimeout:  cfg.InferenceTimeout,
+		Priorities:        cfg.Priorities,
 	})
 
 	embedapp.Routes(app, embedapp.Config{
@@ -67,6 +75,14 @@ func (all) Add(app *web.App, cfg mux.Config) {
 		AuthorizationMode: cfg.AuthorizationMode,
 	})
 
+	imageapp.Routes(app, imageapp.Config{
+		Log:               cfg.Log,
+		AuthClient:        cfg.AuthClient,
+		Pool:              cfg.Pool,
+		AuthorizationMode: cfg.AuthorizationMode,
+		InferenceTimeout:  cfg.InferenceTimeout,
+	})
+
 	rerankapp.Routes(app, rerankapp.Config{
 		Log:               cfg.Log,
 		AuthClient:        cfg.AuthClient,
@@ -87,16 +103,58 @@ func (all) Add(app *web.App, cfg mux.Config) {
 		Log:               cfg.Log,
 		AuthClient:        cfg.AuthClient,
 		Pool:              cfg.Pool,
+		Store:             cfg.ResponseStore,
+		Files:             cfg.FileStore,
+		Media:             cfg.MediaFetcher,
 		AuthorizationMode: cfg.AuthorizationMode,
 		InferenceTimeout:  cfg.InferenceTimeout,
+		Priorities:        cfg.Priorities,
 	})
 
 	msgsapp.Routes(app, msgsapp.Config{
+		Log:               cfg.Log,
+		AuthClient:        cfg.AuthClient,
+		Pool:              cfg.Pool,
+		Files:             cfg.FileStore,
+		Media:             cfg.MediaFetcher,
+		AuthorizationMode: cfg.AuthorizationMode,
+		InferenceTimeout:  cfg.InferenceTimeout,
+		Priorities:        cfg.Priorities,
+	})
+
+	fileapp.Routes(app, fileapp.Config{
+		Log:               cfg.Log,
+		AuthClient:        cfg.AuthClient,
+		Store:             cfg.FileStore,
+		AuthorizationMode: cfg.AuthorizationMode,
+	})
+
+	batchapp.Routes(app, batchapp.Config{
+		Log:               cfg.Log,
+		AuthClient:        cfg.AuthClient,
+		Files:             cfg.FileStore,
+		Queue:             cfg.BatchQueue,
+		AuthorizationMode: cfg.AuthorizationMode,
+	})
+
+	complapp.Routes(app, complapp.Config{
 		Log:               cfg.Log,
 		AuthClient:        cfg.AuthClient,
 		Pool:              cfg.Pool,
 		AuthorizationMode: cfg.AuthorizationMode,
 		InferenceTimeout:  cfg.InferenceTimeout,
+		Priorities:        cfg.Priorities,
+	})
+
+	ollamaapp.Routes(app, ollamaapp.Config{
+		Log:                    cfg.Log,
+		AuthClient:             cfg.AuthClient,
+		Pool:                   cfg.Pool,
+		Models:                 cfg.Models,
+		AuthorizationMode:      cfg.AuthorizationMode,
+		LegacyManagementAccess: cfg.AdminAuthEnabled,
+		InferenceTimeout:       cfg.InferenceTimeout,
+		Priorities:             cfg.Priorities,
 	})
 
 	playgroundapp.Routes(app, playgroundapp.Config{
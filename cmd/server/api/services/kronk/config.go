@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ardanlabs/conf/v3"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
 	"github.com/ardanlabs/kronk/sdk/tools/defaults"
 	"github.com/ardanlabs/kronk/sdk/tools/models"
 	"go.yaml.in/yaml/v2"
@@ -69,9 +72,33 @@ type config struct {
 		ModelsInPool    int           `yaml:"models-in-pool"`
 		TTL             time.Duration `yaml:"ttl"`
 	} `yaml:"pool"`
+	Responses struct {
+		Store string        `yaml:"store"`
+		TTL   time.Duration `yaml:"ttl"`
+	} `yaml:"responses"`
+	Files struct {
+		MaxBytes   int64 `yaml:"max-bytes"`
+		QuotaBytes int64 `yaml:"quota-bytes"`
+	} `yaml:"files"`
+	Batches struct {
+		Concurrency int `yaml:"concurrency"`
+	} `yaml:"batches"`
+	MediaFetch struct {
+		Enabled       bool          `yaml:"enabled"`
+		AllowedHosts  []string      `yaml:"allowed-hosts"`
+		AllowPrivate  bool          `yaml:"allow-private"`
+		MaxBytes      int64         `yaml:"max-bytes"`
+		Timeout       time.Duration `yaml:"timeout"`
+		Cache         bool          `yaml:"cache"`
+		CacheMaxBytes int64         `yaml:"cache-max-bytes"`
+	} `yaml:"media-fetch"`
+	Scheduling struct {
+		Priorities map[string]model.Priority `yaml:"priorities"`
+	} `yaml:"scheduling"`
 	BasePath        string `yaml:"base-path"`
 	LibPath         string `yaml:"lib-path"`
 	BuckyLibPath    string `yaml:"bucky-lib-path"`
+	MalinaLibPath   string `yaml:"malina-lib-path"`
 	LibVersion      string `yaml:"lib-version"`
 	Arch            string `yaml:"arch"`
 	OS              string `yaml:"os"`
@@ -105,6 +132,14 @@ func newConfig() config {
 	cfg.Tempo.Probability = 0.25
 	cfg.Pool.BudgetPercent = 95
 	cfg.Pool.ModelsInPool = 10
+	cfg.Responses.Store = "memory"
+	cfg.Responses.TTL = 24 * time.Hour
+	cfg.Files.MaxBytes = 512 << 20
+	cfg.Files.QuotaBytes = 10 << 30
+	cfg.Batches.Concurrency = 4
+	cfg.MediaFetch.MaxBytes = 20 << 20
+	cfg.MediaFetch.Timeout = 10 * time.Second
+	cfg.MediaFetch.CacheMaxBytes = 1 << 30
 
 	return cfg
 }
@@ -212,6 +247,20 @@ func validateTimeoutConfig(inferenceTimeout, writeTimeout time.Duration) error {
 	return nil
 }
 
+// scheduledEndpoints lists the endpoints whose requests are ordered by the
+// batch engine and can be given a default scheduling priority.
+var scheduledEndpoints = []string{"chat-completions", "responses", "messages", "completions"}
+
+func validateSchedulingConfig(priorities map[string]model.Priority) error {
+	for endpoint := range priorities {
+		if !slices.Contains(scheduledEndpoints, endpoint) {
+			return fmt.Errorf("configuration: scheduling priority for unknown endpoint %q: want one of %s", endpoint, strings.Join(scheduledEndpoints, ", "))
+		}
+	}
+
+	return nil
+}
+
 func validateAdminConfig(adminAuth, webAdmin bool, passwordSHA256, authHost string) error {
 	if passwordSHA256 != "" {
 		decoded, err := hex.DecodeString(passwordSHA256)
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
 	"go.yaml.in/yaml/v2"
 )
 
@@ -28,6 +29,9 @@ kms:
   pool:
     budget-percent: 80
     ttl: 5m
+  scheduling:
+    priorities:
+      responses: low
   bucky-lib-path: /yaml/bucky
   hf-token: yaml-token
   llama-log: 0
@@ -72,6 +76,9 @@ kms:
 	if cfg.Authorization.Mode.String() != "authenticated" {
 		t.Errorf("Authorization.Mode: got %q, want %q", cfg.Authorization.Mode, "authenticated")
 	}
+	if got := cfg.Scheduling.Priorities["responses"]; got != model.PriorityLow {
+		t.Errorf("Scheduling.Priorities[responses]: got %q, want %q", got, model.PriorityLow)
+	}
 	if cfg.Pool.ModelConfigFile != path {
 		t.Errorf("ModelConfigFile: got %q, want %q", cfg.Pool.ModelConfigFile, path)
 	}
@@ -190,6 +197,27 @@ func TestValidateTimeoutConfig(t *testing.T) {
 	}
 }
 
+func TestValidateSchedulingConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		priorities map[string]model.Priority
+		wantErr    bool
+	}{
+		{name: "unset"},
+		{name: "known endpoints", priorities: map[string]model.Priority{"chat-completions": model.PriorityHigh, "responses": model.PriorityLow, "messages": model.PriorityNormal}},
+		{name: "unknown endpoint", priorities: map[string]model.Priority{"embeddings": model.PriorityLow}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSchedulingConfig(tt.priorities)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateSchedulingConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateAdminConfig(t *testing.T) {
 	sha := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
 	tests := []struct {
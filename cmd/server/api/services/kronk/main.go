@@ -21,8 +21,12 @@ import (
 	"github.com/ardanlabs/kronk/cmd/server/app/domain/authapp"
 	"github.com/ardanlabs/kronk/cmd/server/app/domain/mcpapp"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/batchqueue"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/debug"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mediafetch"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mux"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/respstore"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
@@ -30,11 +34,14 @@ import (
 	"github.com/ardanlabs/kronk/sdk/kronk"
 	"github.com/ardanlabs/kronk/sdk/kronk/observ/metrics"
 	"github.com/ardanlabs/kronk/sdk/kronk/observ/otel"
+	"github.com/ardanlabs/kronk/sdk/malina"
 	"github.com/ardanlabs/kronk/sdk/pool"
 	buckylibs "github.com/ardanlabs/kronk/sdk/tools/bucky/libs"
 	buckymodels "github.com/ardanlabs/kronk/sdk/tools/bucky/models"
 	"github.com/ardanlabs/kronk/sdk/tools/defaults"
 	"github.com/ardanlabs/kronk/sdk/tools/libs"
+	malinalibs "github.com/ardanlabs/kronk/sdk/tools/malina/libs"
+	malinamodels "github.com/ardanlabs/kronk/sdk/tools/malina/models"
 	"github.com/ardanlabs/kronk/sdk/tools/models"
 	"google.golang.org/grpc/test/bufconn"
 )
@@ -98,6 +105,9 @@ func run(ctx context.Context, log *logger.Logger, showHelp bool) error {
 	if err := validateTimeoutConfig(cfg.Web.InferenceTimeout, cfg.Web.WriteTimeout); err != nil {
 		return err
 	}
+	if err := validateSchedulingConfig(cfg.Scheduling.Priorities); err != nil {
+		return err
+	}
 
 	// -------------------------------------------------------------------------
 	// App Starting
@@ -305,6 +315,43 @@ func run(ctx context.Context, log *logger.Logger, showHelp bool) error {
 		log.Info(ctx, "startup", "WARNING", "bucky build index", "ERROR", err)
 	}
 
+	// -------------------------------------------------------------------------
+	// Malina (stable-diffusion) Libs + Models
+	//
+	// The server exposes the /v1/images/* inference endpoints backed by
+	// the curated malina bundles. malina.Init wires up the
+	// stable-diffusion.cpp shared library so the malina pool can load
+	// bundles on demand; failure is non-fatal so every other endpoint
+	// still works when the runtime library has not been downloaded yet.
+
+	malinaLibs, err := malinalibs.New(
+		malinalibs.WithBasePath(cfg.BasePath),
+		malinalibs.WithLibPath(cfg.MalinaLibPath),
+		malinalibs.WithAllowUpgrade(cfg.AllowUpgrade),
+		malinalibs.WithDetect(ctx, log.Info),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to create malina libs api: %w", err)
+	}
+
+	log.Info(ctx, "startup", "status", "malina libs ready", "libPath", malinaLibs.LibsPath(), "arch", malinaLibs.Arch(), "os", malinaLibs.OS(), "processor", malinaLibs.Processor())
+
+	ctx, cancel = context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	if _, err := malinaLibs.Download(ctx, log.Info); err != nil {
+		log.Info(ctx, "startup", "WARNING", "unable to install stable-diffusion.cpp, running in degraded mode", "ERROR", err)
+	}
+
+	malinaModels, err := malinamodels.NewWithPaths(cfg.BasePath)
+	if err != nil {
+		return fmt.Errorf("unable to create malina models api: %w", err)
+	}
+
+	if err := malinaModels.BuildIndex(log.Info, false); err != nil {
+		log.Info(ctx, "startup", "WARNING", "malina build index", "ERROR", err)
+	}
+
 	// -------------------------------------------------------------------------
 	// Model Config
 
@@ -345,17 +392,22 @@ func run(ctx context.Context, log *logger.Logger, showHelp bool) error {
 		log.Info(ctx, "startup", "WARNING", "bucky init failed, running in degraded mode (use BUI to download whisper libraries)", "ERROR", err)
 	}
 
+	if err := malina.Init(malina.WithLibPath(malinaLibs.LibsPath())); err != nil {
+		log.Info(ctx, "startup", "WARNING", "malina init failed, running in degraded mode (image generation is unavailable)", "ERROR", err)
+	}
+
 	// -------------------------------------------------------------------------
 	// Pool
 	//
 	// One call to pool.New constructs the shared resource manager and
-	// every enabled backend pool (kronk + bucky). The resman is
+	// every enabled backend pool (kronk + bucky + malina). The resman is
 	// shared so VRAM/RAM budgeting is unified across backends.
 
 	p, err := pool.New(pool.Config{
 		Log:             log.Info,
 		KronkModels:     models,
 		BuckyModels:     buckyModels,
+		MalinaModels:    malinaModels,
 		ModelConfigFile: modelConfigFile,
 		BudgetPercent:   cfg.Pool.BudgetPercent,
 		ModelsInPool:    cfg.Pool.ModelsInPool,
@@ -383,6 +435,79 @@ func run(ctx context.Context, log *logger.Logger, showHelp bool) error {
 	}
 	defer unregisterIMCMetrics()
 
+	// -------------------------------------------------------------------------
+	// Response Store
+
+	respStore, err := newResponseStore(cfg.Responses.Store, cfg.Responses.TTL, defaults.BaseDir(cfg.BasePath))
+	if err != nil {
+		return fmt.Errorf("initializing response store: %w", err)
+	}
+
+	if respStore != nil {
+		defer respStore.Close()
+	}
+
+	log.Info(ctx, "startup", "status", "response store", "store", cfg.Responses.Store, "ttl", cfg.Responses.TTL)
+
+	// -------------------------------------------------------------------------
+	// File Store
+
+	fileStore, err := filestore.New(filestore.Config{
+		Dir:        filepath.Join(defaults.BaseDir(cfg.BasePath), "files"),
+		MaxBytes:   cfg.Files.MaxBytes,
+		QuotaBytes: cfg.Files.QuotaBytes,
+	})
+	if err != nil {
+		return fmt.Errorf("initializing file store: %w", err)
+	}
+
+	log.Info(ctx, "startup", "status", "file store", "max-bytes", cfg.Files.MaxBytes, "quota-bytes", cfg.Files.QuotaBytes)
+
+	// -------------------------------------------------------------------------
+	// Batch Queue
+
+	batchQueue, err := batchqueue.New(batchqueue.Config{
+		Log:         log,
+		Dir:         filepath.Join(defaults.BaseDir(cfg.BasePath), "batches"),
+		Files:       fileStore,
+		Auth:        authClient,
+		Concurrency: cfg.Batches.Concurrency,
+	})
+	if err != nil {
+		return fmt.Errorf("initializing batch queue: %w", err)
+	}
+
+	log.Info(ctx, "startup", "status", "batch queue", "concurrency", cfg.Batches.Concurrency)
+
+	// -------------------------------------------------------------------------
+	// Media Fetcher
+
+	var mediaFetcher *mediafetch.Fetcher
+	if cfg.MediaFetch.Enabled {
+		var cacheDir string
+		if cfg.MediaFetch.Cache {
+			cacheDir = filepath.Join(defaults.BaseDir(cfg.BasePath), "media-cache")
+		}
+
+		mediaFetcher, err = mediafetch.New(mediafetch.Config{
+			AllowedHosts:  cfg.MediaFetch.AllowedHosts,
+			AllowPrivate:  cfg.MediaFetch.AllowPrivate,
+			MaxBytes:      cfg.MediaFetch.MaxBytes,
+			Timeout:       cfg.MediaFetch.Timeout,
+			CacheDir:      cacheDir,
+			CacheMaxBytes: cfg.MediaFetch.CacheMaxBytes,
+		})
+		if err != nil {
+			return fmt.Errorf("initializing media fetcher: %w", err)
+		}
+
+		log.Info(ctx, "startup", "status", "media fetcher", "allowed-hosts", cfg.MediaFetch.AllowedHosts, "allow-private", cfg.MediaFetch.AllowPrivate, "max-bytes", cfg.MediaFetch.MaxBytes, "timeout", cfg.MediaFetch.Timeout, "cache-dir", cacheDir, "cache-max-bytes", cfg.MediaFetch.CacheMaxBytes)
+
+		if !mediaFetcher.AllowsAny() {
+			log.Warn(ctx, "startup", "status", "media fetching is enabled but no hosts are allowed, so media URLs are rejected")
+		}
+	}
+
 	// -------------------------------------------------------------------------
 	// Start the MCP server
 
@@ -449,6 +574,11 @@ func run(ctx context.Context, log *logger.Logger, showHelp bool) error {
 		AdminPasswordSHA256: cfg.Web.Admin.PasswordSHA256,
 		Security:            sec,
 		InferenceTimeout:    cfg.Web.InferenceTimeout,
+		Priorities:          cfg.Scheduling.Priorities,
+		ResponseStore:       respStore,
+		FileStore:           fileStore,
+		BatchQueue:          batchQueue,
+		MediaFetcher:        mediaFetcher,
 	}
 
 	options := []func(*mux.Options){mux.WithCORS(cfg.Web.CORSAllowedOrigins)}
@@ -457,6 +587,21 @@ func run(ctx context.Context, log *logger.Logger, showHelp bool) error {
 	}
 	webAPI := mux.WebAPI(cfgMux, build.Routes(), options...)
 
+	// Batch lines are dispatched through the same handler as API traffic so
+	// they get the same validation, scheduling and response shapes.
+	batchQueue.Start(webAPI)
+
+	defer func() {
+		log.Info(ctx, "shutdown", "status", "shutting down batch queue")
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Web.ShutdownTimeout)
+		defer cancel()
+
+		if err := batchQueue.Shutdown(ctx); err != nil {
+			log.Error(ctx, "batches", "ERROR", err)
+		}
+	}()
+
 	api := http.Server{
 		Addr:         cfg.Web.APIHost,
 		Handler:      webAPI,
@@ -498,6 +643,26 @@ func run(ctx context.Context, log *logger.Logger, showHelp bool) error {
 	return nil
 }
 
+// newResponseStore constructs the store used by the Responses API for
+// previous_response_id chaining. A nil store disables response storage.
+func newResponseStore(store string, ttl time.Duration, basePath string) (respstore.Storer, error) {
+	switch store {
+	case "memory":
+		return respstore.NewMemory(ttl), nil
+
+	case "disk":
+		return respstore.NewBadger(respstore.Config{
+			DBPath: filepath.Join(basePath, "responses"),
+			TTL:    ttl,
+		})
+
+	case "none":
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("configuration: unknown response store %q, expected memory, disk, or none", store)
+}
+
 func registerIMCSessionMetrics(p *pool.Pool) (func(), error) {
 	return metrics.RegisterIMCSessionsProvider(func() []metrics.IMCSession {
 		sessions := p.Kronk.IMCSessions()
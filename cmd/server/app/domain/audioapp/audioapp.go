@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
 	"github.com/ardanlabs/kronk/sdk/bucky/model"
@@ -20,10 +21,14 @@ import (
 
 // maxUploadBytes matches OpenAI's documented 25 MB file cap for the audio
 // transcriptions endpoint. The request limit allows a small amount of space
-// for multipart headers and form fields.
+// for multipart headers and form fields. Streamed requests are decoded in
+// chunks of streamChunkMs, whisper's native window, and idle streams send a
+// keep-alive comment every streamKeepAlive.
 const (
 	maxUploadBytes       = 25 << 20
 	maxMultipartOverhead = 1 << 20
+	streamChunkMs        = 30000
+	streamKeepAlive      = 15 * time.Second
 )
 
 type app struct {
@@ -39,6 +44,16 @@ func newApp(cfg Config) *app {
 }
 
 func (a *app) transcriptions(ctx context.Context, r *http.Request) web.Encoder {
+	return a.transcribe(ctx, r, "transcribe")
+}
+
+// translations translates speech into English text. It accepts the same
+// fields and response formats as transcriptions.
+func (a *app) translations(ctx context.Context, r *http.Request) web.Encoder {
+	return a.transcribe(ctx, r, "translate")
+}
+
+func (a *app) transcribe(ctx context.Context, r *http.Request, task string) web.Encoder {
 	r.Body = http.MaxBytesReader(nil, r.Body, maxUploadBytes+maxMultipartOverhead)
 	if err := r.ParseMultipartForm(maxUploadBytes); err != nil {
 		return errs.New(errs.InvalidArgument, fmt.Errorf("parse multipart form: %w", err))
@@ -61,7 +76,8 @@ func (a *app) transcriptions(ctx context.Context, r *http.Request) web.Encoder {
 
 	language := r.FormValue("language")
 	prompt := r.FormValue("prompt")
-	translate := parseBool(r.FormValue("translate"))
+	translate := task == "translate" || parseBool(r.FormValue("translate"))
+	stream := parseBool(r.FormValue("stream"))
 
 	respFmt := r.FormValue("response_format")
 	if respFmt == "" {
@@ -73,6 +89,10 @@ func (a *app) transcriptions(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Errorf(errs.InvalidArgument, "unsupported response_format[%s]", respFmt)
 	}
 
+	if stream && respFmt != "json" && respFmt != "text" {
+		return errs.Errorf(errs.InvalidArgument, "stream requires response_format json or text, got[%s]", respFmt)
+	}
+
 	wantWordTimes := false
 	for _, g := range r.Form["timestamp_granularities[]"] {
 		if g == "word" {
@@ -99,20 +119,33 @@ func (a *app) transcriptions(ctx context.Context, r *http.Request) web.Encoder {
 	}
 	opts = append(opts, whisperOpts...)
 
-	a.log.Info(ctx, "transcribe", "model", modelID, "filename", hdr.Filename, "size", hdr.Size, "language", language, "response-format", respFmt)
+	a.log.Info(ctx, task, "model", modelID, "filename", hdr.Filename, "size", hdr.Size, "language", language, "response-format", respFmt, "stream", stream)
+
+	if err := mid.AuthorizeModel(ctx, modelID); err != nil {
+		return err
+	}
 
 	b, err := a.pool.Bucky.AquireModel(ctx, modelID)
 	if err != nil {
 		return errs.FromSDK(err)
 	}
 
-	if !b.ModelInfo().IsMultilingual && language != "" && language != "en" {
-		return errs.Errorf(errs.InvalidArgument, "model[%s] is english-only but language[%s] was requested", modelID, language)
+	if !b.ModelInfo().IsMultilingual {
+		switch {
+		case translate:
+			return errs.Errorf(errs.InvalidArgument, "model[%s] is english-only and cannot translate", modelID)
+		case language != "" && language != "en":
+			return errs.Errorf(errs.InvalidArgument, "model[%s] is english-only but language[%s] was requested", modelID, language)
+		}
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
 	defer cancel()
 
+	if stream {
+		return streamTranscription(ctx, b.TranscribeFile, file, opts)
+	}
+
 	tr, err := b.TranscribeFile(ctx, file, opts...)
 	if err != nil {
 		return errs.FromSDK(fmt.Errorf("transcribe: %w", err))
@@ -0,0 +1,568 @@
+package audioapp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"uuid"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/bucky/model"
+	"github.com/ardanlabs/kronk/sdk/pool"
+	"github.com/gorilla/websocket"
+)
+
+// Realtime session limits. Clients append audio in small chunks, so a
+// message limit of 1 MB leaves plenty of room for base64 overhead while
+// bounding the memory a single message can pin. The ping and pong timings
+// detect dead peers on an otherwise idle connection.
+const (
+	realtimeIntent          = "transcription"
+	realtimeSubprotocol     = "realtime"
+	apiKeySubprotocol       = "openai-insecure-api-key."
+	maxRealtimeMessageBytes = 1 << 20
+	realtimeWriteWait       = 10 * time.Second
+	realtimePongWait        = 60 * time.Second
+	realtimePingPeriod      = 30 * time.Second
+	defaultRealtimeRate     = 24000
+)
+
+var upgrader = websocket.Upgrader{
+	Subprotocols: []string{realtimeSubprotocol},
+}
+
+// realtime upgrades the request to a WebSocket and runs a live transcription
+// session shaped like OpenAI's realtime transcription sessions.
+func (a *app) realtime(ctx context.Context, r *http.Request) web.Encoder {
+	if intent := r.URL.Query().Get("intent"); intent != realtimeIntent {
+		return errs.Errorf(errs.InvalidArgument, "unsupported intent[%s], only intent=%s is supported", intent, realtimeIntent)
+	}
+
+	if !websocket.IsWebSocketUpgrade(r) {
+		return errs.Errorf(errs.InvalidArgument, "websocket upgrade required")
+	}
+
+	conn, err := upgrader.Upgrade(web.GetWriter(ctx), r, nil)
+	if err != nil {
+		// The upgrader has already written the error response.
+		return web.NewNoResponseError(fmt.Errorf("upgrade: %w", err))
+	}
+
+	modelID := r.URL.Query().Get("model")
+
+	a.log.Info(ctx, "realtime-transcription", "status", "open", "model", modelID)
+
+	rc := newRealtimeConn(a.log, a.pool, conn, modelID)
+	if err := rc.run(ctx); err != nil {
+		return web.NewNoResponseError(err)
+	}
+
+	a.log.Info(ctx, "realtime-transcription", "status", "closed")
+
+	return web.NewNoResponse()
+}
+
+// subprotocolAuth lets browser clients, which cannot set headers on a
+// WebSocket handshake, pass their bearer token as an
+// "openai-insecure-api-key.<token>" subprotocol. It must run before the
+// authentication middleware.
+func subprotocolAuth(next web.HandlerFunc) web.HandlerFunc {
+	h := func(ctx context.Context, r *http.Request) web.Encoder {
+		if r.Header.Get("Authorization") == "" {
+			for _, protocol := range websocket.Subprotocols(r) {
+				if token, ok := strings.CutPrefix(protocol, apiKeySubprotocol); ok {
+					r.Header.Set("Authorization", "Bearer "+token)
+					break
+				}
+			}
+		}
+
+		return next(ctx, r)
+	}
+
+	return h
+}
+
+// =============================================================================
+
+// realtimeSession is the transcription session configuration. The OpenAI
+// fields come first; the remaining fields are Kronk extensions that tune the
+// bucky stream directly.
+type realtimeSession struct {
+	InputAudioFormat        string                 `json:"input_audio_format"`
+	InputAudioTranscription realtimeTranscription  `json:"input_audio_transcription"`
+	TurnDetection           *realtimeTurnDetection `json:"turn_detection"`
+
+	InputAudioSampleRate int   `json:"input_audio_sample_rate"`
+	InputAudioChannels   int   `json:"input_audio_channels"`
+	PartialEveryMs       int   `json:"partial_every_ms,omitempty"`
+	PromptCarryover      *bool `json:"prompt_carryover,omitempty"`
+	Translate            bool  `json:"translate,omitempty"`
+}
+
+type realtimeTranscription struct {
+	Model    string `json:"model"`
+	Language string `json:"language,omitempty"`
+	Prompt   string `json:"prompt,omitempty"`
+}
+
+type realtimeTurnDetection struct {
+	Type      string  `json:"type"`
+	Threshold float32 `json:"threshold,omitempty"`
+}
+
+func newRealtimeSession(modelID string) realtimeSession {
+	return realtimeSession{
+		InputAudioFormat:        "pcm16",
+		InputAudioTranscription: realtimeTranscription{Model: modelID},
+		TurnDetection:           &realtimeTurnDetection{Type: "server_vad"},
+		InputAudioSampleRate:    defaultRealtimeRate,
+		InputAudioChannels:      1,
+	}
+}
+
+// update applies a partial session update. Fields missing from data keep
+// their current value and a null turn_detection disables VAD.
+func (s realtimeSession) update(data []byte) (realtimeSession, error) {
+	next := s
+	if s.TurnDetection != nil {
+		next.TurnDetection = new(*s.TurnDetection)
+	}
+	if s.PromptCarryover != nil {
+		next.PromptCarryover = new(*s.PromptCarryover)
+	}
+
+	if err := json.Unmarshal(data, &next); err != nil {
+		return realtimeSession{}, fmt.Errorf("session: %w", err)
+	}
+
+	if err := next.validate(); err != nil {
+		return realtimeSession{}, err
+	}
+
+	return next, nil
+}
+
+func (s realtimeSession) validate() error {
+	switch s.InputAudioFormat {
+	case "pcm16", "float32":
+	default:
+		return fmt.Errorf("unsupported input_audio_format[%s], use pcm16 or float32", s.InputAudioFormat)
+	}
+
+	if s.InputAudioSampleRate < 8000 || s.InputAudioSampleRate > 192000 {
+		return fmt.Errorf("input_audio_sample_rate[%d] must be between 8000 and 192000", s.InputAudioSampleRate)
+	}
+
+	if s.InputAudioChannels < 1 || s.InputAudioChannels > 8 {
+		return fmt.Errorf("input_audio_channels[%d] must be between 1 and 8", s.InputAudioChannels)
+	}
+
+	if td := s.TurnDetection; td != nil {
+		if td.Type != "server_vad" {
+			return fmt.Errorf("unsupported turn_detection type[%s], use server_vad or null", td.Type)
+		}
+		if td.Threshold < 0 || td.Threshold > 1 {
+			return fmt.Errorf("turn_detection threshold[%g] must be between 0 and 1", td.Threshold)
+		}
+	}
+
+	return nil
+}
+
+func (s realtimeSession) audioFormat() model.AudioFormat {
+	f := model.AudioFormat{
+		SampleRate: s.InputAudioSampleRate,
+		Channels:   s.InputAudioChannels,
+		Sample:     model.Int16LE,
+	}
+
+	if s.InputAudioFormat == "float32" {
+		f.Sample = model.Float32LE
+	}
+
+	return f
+}
+
+func (s realtimeSession) streamOptions() []model.StreamOption {
+	opts := []model.StreamOption{
+		model.WithVAD(s.TurnDetection != nil),
+		model.WithEmitResetEvent(true),
+	}
+
+	if s.InputAudioTranscription.Language != "" {
+		opts = append(opts, model.WithStreamLanguage(s.InputAudioTranscription.Language))
+	}
+	if s.InputAudioTranscription.Prompt != "" {
+		opts = append(opts, model.WithStreamInitialPrompt(s.InputAudioTranscription.Prompt))
+	}
+	if s.Translate {
+		opts = append(opts, model.WithStreamTranslate(true))
+	}
+	if s.TurnDetection != nil && s.TurnDetection.Threshold > 0 {
+		opts = append(opts, model.WithVADThreshold(s.TurnDetection.Threshold))
+	}
+	if s.PartialEveryMs != 0 {
+		opts = append(opts, model.WithPartialEveryMs(s.PartialEveryMs))
+	}
+	if s.PromptCarryover != nil {
+		opts = append(opts, model.WithPromptCarryover(*s.PromptCarryover))
+	}
+
+	return opts
+}
+
+// =============================================================================
+
+type realtimeClientEvent struct {
+	Type    string          `json:"type"`
+	EventID string          `json:"event_id"`
+	Session json.RawMessage `json:"session"`
+	Audio   string          `json:"audio"`
+}
+
+// realtimeConn owns one WebSocket transcription session. The read loop runs
+// on the handler goroutine and is the only producer for the stream. Stream
+// events are forwarded by a separate goroutine, so writes are serialized.
+type realtimeConn struct {
+	log     *logger.Logger
+	pool    *pool.Pool
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+
+	session realtimeSession
+	stream  *model.Stream
+	fwdDone chan struct{}
+
+	// Only the forwarding goroutine touches the item ids. At most one
+	// forwarder runs at a time.
+	itemID     string
+	prevItemID string
+}
+
+func newRealtimeConn(log *logger.Logger, pool *pool.Pool, conn *websocket.Conn, modelID string) *realtimeConn {
+	return &realtimeConn{
+		log:     log,
+		pool:    pool,
+		conn:    conn,
+		session: newRealtimeSession(modelID),
+		itemID:  newItemID(),
+	}
+}
+
+func (rc *realtimeConn) run(ctx context.Context) error {
+	defer rc.conn.Close()
+	defer rc.closeStream()
+
+	rc.conn.SetReadLimit(maxRealtimeMessageBytes)
+	rc.conn.SetReadDeadline(time.Now().Add(realtimePongWait))
+	rc.conn.SetPongHandler(func(string) error {
+		return rc.conn.SetReadDeadline(time.Now().Add(realtimePongWait))
+	})
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go rc.ping(stop)
+
+	rc.send(ctx, map[string]any{
+		"type":    "transcription_session.created",
+		"session": rc.session,
+	})
+
+	for {
+		mt, data, err := rc.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) || ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("read: %w", err)
+		}
+
+		rc.conn.SetReadDeadline(time.Now().Add(realtimePongWait))
+
+		var eventID string
+		switch mt {
+		case websocket.BinaryMessage:
+			err = rc.appendAudio(ctx, data)
+
+		case websocket.TextMessage:
+			var ev realtimeClientEvent
+			if err = json.Unmarshal(data, &ev); err != nil {
+				err = errs.Errorf(errs.InvalidArgument, "decode event: %s", err)
+				break
+			}
+			eventID = ev.EventID
+			err = rc.handle(ctx, ev)
+		}
+
+		if err != nil {
+			rc.sendError(ctx, eventID, err)
+		}
+	}
+}
+
+func (rc *realtimeConn) handle(ctx context.Context, ev realtimeClientEvent) error {
+	switch ev.Type {
+	case "transcription_session.update", "session.update":
+		next, err := rc.session.update(ev.Session)
+		if err != nil {
+			return errs.New(errs.InvalidArgument, err)
+		}
+
+		// Stream options are fixed when the stream opens, so audio already
+		// appended is flushed under the old settings and the next append
+		// opens a stream with the new ones.
+		rc.closeStream()
+		rc.session = next
+
+		rc.send(ctx, map[string]any{
+			"type":    "transcription_session.updated",
+			"session": rc.session,
+		})
+
+		return nil
+
+	case "input_audio_buffer.append":
+		audio, err := base64.StdEncoding.DecodeString(ev.Audio)
+		if err != nil {
+			return errs.Errorf(errs.InvalidArgument, "audio must be base64 encoded: %s", err)
+		}
+		return rc.appendAudio(ctx, audio)
+
+	case "input_audio_buffer.commit":
+		if rc.stream == nil {
+			return errs.Errorf(errs.InvalidArgument, "input audio buffer is empty")
+		}
+		return rc.reset(ctx, true)
+
+	case "input_audio_buffer.clear":
+		if rc.stream == nil {
+			rc.send(ctx, map[string]any{"type": "input_audio_buffer.cleared"})
+			return nil
+		}
+		return rc.reset(ctx, false)
+
+	default:
+		return errs.Errorf(errs.InvalidArgument, "unsupported event type[%s]", ev.Type)
+	}
+}
+
+// appendAudio feeds raw PCM in the session's input format to the stream,
+// opening the stream on first use.
+func (rc *realtimeConn) appendAudio(ctx context.Context, audio []byte) error {
+	if len(audio) == 0 {
+		return nil
+	}
+
+	if err := rc.openStream(ctx); err != nil {
+		return err
+	}
+
+	if err := rc.stream.FeedPCM(ctx, audio, rc.session.audioFormat()); err != nil {
+		return errs.FromSDK(fmt.Errorf("feed: %w", err))
+	}
+
+	return nil
+}
+
+// reset commits (flush) or discards the pending audio. The stream emits the
+// resulting final and reset events, which the forwarder relays in order.
+func (rc *realtimeConn) reset(ctx context.Context, flush bool) error {
+	err := rc.stream.Reset(ctx,
+		model.WithFlushPending(flush),
+		model.WithRebaseTimestamps(false),
+		model.WithKeepPromptTokens(true),
+	)
+	if err != nil {
+		return errs.FromSDK(fmt.Errorf("reset: %w", err))
+	}
+
+	return nil
+}
+
+// openStream acquires the session's model from the pool and opens a bucky
+// stream on it. The stream holds the model's active-stream count until it
+// is closed, so the pool cannot evict the model mid-session.
+func (rc *realtimeConn) openStream(ctx context.Context) error {
+	if rc.stream != nil {
+		return nil
+	}
+
+	modelID := rc.session.InputAudioTranscription.Model
+	if modelID == "" {
+		return errs.Errorf(errs.InvalidArgument, "missing model, set session.input_audio_transcription.model or the model query parameter")
+	}
+
+	if err := mid.AuthorizeModel(ctx, modelID); err != nil {
+		return err
+	}
+
+	b, err := rc.pool.Bucky.AquireModel(ctx, modelID)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	language := rc.session.InputAudioTranscription.Language
+	if !b.ModelInfo().IsMultilingual && language != "" && language != "en" {
+		return errs.Errorf(errs.InvalidArgument, "model[%s] is english-only but language[%s] was requested", modelID, language)
+	}
+
+	s, err := b.NewStream(ctx, rc.session.streamOptions()...)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	rc.stream = s
+	rc.fwdDone = make(chan struct{})
+	go rc.forward(ctx, s, rc.fwdDone)
+
+	return nil
+}
+
+// closeStream flushes and closes the open stream, if any, and waits until
+// its final events have been forwarded.
+func (rc *realtimeConn) closeStream() {
+	if rc.stream == nil {
+		return
+	}
+
+	rc.stream.Close()
+	<-rc.fwdDone
+
+	rc.stream = nil
+	rc.fwdDone = nil
+}
+
+// forward relays stream events to the client until the stream closes. It
+// keeps draining after a write failure so the stream's worker never blocks
+// on a final.
+func (rc *realtimeConn) forward(ctx context.Context, s *model.Stream, done chan<- struct{}) {
+	defer close(done)
+
+	for ev := range s.Events() {
+		for _, out := range rc.toServerEvents(ev) {
+			rc.send(ctx, out)
+		}
+	}
+}
+
+// toServerEvents maps a bucky stream event to the realtime server events.
+// A partial carries the full, replaceable hypothesis for the pending item
+// rather than an OpenAI delta, so clients replace their pending text instead
+// of appending to it.
+func (rc *realtimeConn) toServerEvents(ev model.Event) []map[string]any {
+	switch ev.Kind {
+	case model.EventPartial:
+		return []map[string]any{{
+			"type":           "conversation.item.input_audio_transcription.partial",
+			"item_id":        rc.itemID,
+			"content_index":  0,
+			"transcript":     ev.Text,
+			"audio_start_ms": ev.StartMs,
+			"audio_end_ms":   ev.EndMs,
+		}}
+
+	case model.EventFinal:
+		committed := map[string]any{
+			"type":             "input_audio_buffer.committed",
+			"item_id":          rc.itemID,
+			"previous_item_id": nil,
+		}
+		if rc.prevItemID != "" {
+			committed["previous_item_id"] = rc.prevItemID
+		}
+
+		completed := map[string]any{
+			"type":           "conversation.item.input_audio_transcription.completed",
+			"item_id":        rc.itemID,
+			"content_index":  0,
+			"transcript":     ev.Text,
+			"audio_start_ms": ev.StartMs,
+			"audio_end_ms":   ev.EndMs,
+		}
+
+		rc.prevItemID = rc.itemID
+		rc.itemID = newItemID()
+
+		return []map[string]any{committed, completed}
+
+	case model.EventReset:
+		return []map[string]any{{"type": "input_audio_buffer.cleared"}}
+
+	default:
+		return []map[string]any{rc.errorEvent("", errs.FromSDK(fmt.Errorf("stream: %w", ev.Err)))}
+	}
+}
+
+func (rc *realtimeConn) sendError(ctx context.Context, eventID string, err error) {
+	var appErr *errs.Error
+	if !errors.As(err, &appErr) {
+		appErr = errs.New(errs.Internal, err)
+	}
+
+	rc.send(ctx, rc.errorEvent(eventID, appErr))
+}
+
+// errorEvent wraps an app error in a realtime error event, reusing the
+// error's OpenAI-compatible JSON shape.
+func (rc *realtimeConn) errorEvent(eventID string, appErr *errs.Error) map[string]any {
+	ev := map[string]any{}
+
+	data, err := json.Marshal(appErr)
+	if err == nil {
+		err = json.Unmarshal(data, &ev)
+	}
+	if err != nil {
+		ev["error"] = map[string]any{"type": "server_error", "message": appErr.Message}
+	}
+
+	ev["type"] = "error"
+	if detail, ok := ev["error"].(map[string]any); ok && eventID != "" {
+		detail["event_id"] = eventID
+	}
+
+	return ev
+}
+
+// send writes a server event, stamping it with a new event id. Write
+// failures are logged; the read loop notices the broken connection.
+func (rc *realtimeConn) send(ctx context.Context, ev map[string]any) {
+	ev["event_id"] = "event_" + uuid.New().String()
+
+	rc.writeMu.Lock()
+	defer rc.writeMu.Unlock()
+
+	rc.conn.SetWriteDeadline(time.Now().Add(realtimeWriteWait))
+	if err := rc.conn.WriteJSON(ev); err != nil {
+		rc.log.Info(ctx, "realtime-transcription", "status", "write failed", "type", ev["type"], "ERROR", err)
+	}
+}
+
+func (rc *realtimeConn) ping(stop <-chan struct{}) {
+	ticker := time.NewTicker(realtimePingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := rc.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(realtimeWriteWait)); err != nil {
+				return
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func newItemID() string {
+	return "item_" + uuid.New().String()
+}
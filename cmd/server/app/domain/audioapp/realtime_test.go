@@ -0,0 +1,210 @@
+package audioapp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/bucky/model"
+	"github.com/ardanlabs/kronk/sdk/pool"
+	"github.com/gorilla/websocket"
+)
+
+func TestRealtimeSessionUpdate(t *testing.T) {
+	base := newRealtimeSession("whisper-base")
+
+	next, err := base.update([]byte(`{
+		"input_audio_format": "float32",
+		"input_audio_transcription": {"language": "en"},
+		"turn_detection": null,
+		"input_audio_sample_rate": 48000
+	}`))
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	if next.InputAudioTranscription.Model != "whisper-base" {
+		t.Errorf("model: got %q, want the existing model to be kept", next.InputAudioTranscription.Model)
+	}
+	if next.InputAudioTranscription.Language != "en" {
+		t.Errorf("language: got %q, want en", next.InputAudioTranscription.Language)
+	}
+	if next.TurnDetection != nil {
+		t.Error("turn_detection: null should disable VAD")
+	}
+	if got := next.audioFormat(); got != (model.AudioFormat{SampleRate: 48000, Channels: 1, Sample: model.Float32LE}) {
+		t.Errorf("audio format: got %+v", got)
+	}
+
+	threshold, err := base.update([]byte(`{"turn_detection": {"threshold": 0.4}}`))
+	if err != nil {
+		t.Fatalf("update threshold: %v", err)
+	}
+	if threshold.TurnDetection.Type != "server_vad" || threshold.TurnDetection.Threshold != 0.4 {
+		t.Errorf("turn_detection: got %+v", threshold.TurnDetection)
+	}
+	if base.TurnDetection.Threshold != 0 {
+		t.Error("update should not modify the current session")
+	}
+
+	for _, body := range []string{
+		`{"input_audio_format": "g711_ulaw"}`,
+		`{"input_audio_channels": 0}`,
+		`{"turn_detection": {"type": "semantic_vad"}}`,
+		`{"turn_detection": {"threshold": 2}}`,
+	} {
+		if _, err := base.update([]byte(body)); err == nil {
+			t.Errorf("update %s: expected an error", body)
+		}
+	}
+}
+
+func TestRealtimeStreamOptions(t *testing.T) {
+	session := newRealtimeSession("whisper-base")
+	session.InputAudioTranscription.Language = "de"
+	session.InputAudioTranscription.Prompt = "Kronk"
+	session.TurnDetection = nil
+	session.PartialEveryMs = -1
+	session.PromptCarryover = new(false)
+
+	var cfg model.StreamConfig
+	for _, opt := range session.streamOptions() {
+		opt(&cfg)
+	}
+
+	if cfg.Language != "de" || cfg.InitialPrompt != "Kronk" {
+		t.Errorf("language/prompt: got %q/%q", cfg.Language, cfg.InitialPrompt)
+	}
+	if !cfg.DisableVAD {
+		t.Error("VAD should be disabled without turn detection")
+	}
+	if cfg.PartialEveryMs != -1 {
+		t.Errorf("partial every ms: got %d, want -1", cfg.PartialEveryMs)
+	}
+	if !cfg.DisablePromptCarryover {
+		t.Error("prompt carryover should be disabled")
+	}
+	if !cfg.EmitResetEvent {
+		t.Error("reset events should be emitted")
+	}
+}
+
+func TestRealtimeToServerEvents(t *testing.T) {
+	rc := &realtimeConn{itemID: "item_1"}
+
+	partial := rc.toServerEvents(model.Event{Kind: model.EventPartial, Text: "hello wor"})
+	if len(partial) != 1 || partial[0]["type"] != "conversation.item.input_audio_transcription.partial" || partial[0]["item_id"] != "item_1" {
+		t.Fatalf("partial: got %v", partial)
+	}
+
+	final := rc.toServerEvents(model.Event{Kind: model.EventFinal, Text: "hello world", EndMs: 1200})
+	if len(final) != 2 {
+		t.Fatalf("final: got %d events, want 2", len(final))
+	}
+	if final[0]["type"] != "input_audio_buffer.committed" || final[0]["previous_item_id"] != nil {
+		t.Errorf("committed: got %v", final[0])
+	}
+	if final[1]["type"] != "conversation.item.input_audio_transcription.completed" || final[1]["transcript"] != "hello world" || final[1]["item_id"] != "item_1" {
+		t.Errorf("completed: got %v", final[1])
+	}
+
+	next := rc.toServerEvents(model.Event{Kind: model.EventFinal, Text: "again"})
+	if next[0]["item_id"] == "item_1" || next[0]["previous_item_id"] != "item_1" {
+		t.Errorf("second final: got %v", next[0])
+	}
+
+	reset := rc.toServerEvents(model.Event{Kind: model.EventReset})
+	if reset[0]["type"] != "input_audio_buffer.cleared" {
+		t.Errorf("reset: got %v", reset[0])
+	}
+
+	failed := rc.toServerEvents(model.Event{Kind: model.EventError, Err: errors.New("decode failed")})
+	if failed[0]["type"] != "error" {
+		t.Errorf("error: got %v", failed[0])
+	}
+}
+
+func TestSubprotocolAuth(t *testing.T) {
+	var got string
+	h := subprotocolAuth(func(ctx context.Context, r *http.Request) web.Encoder {
+		got = r.Header.Get("Authorization")
+		return nil
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/realtime", nil)
+	r.Header.Set("Sec-WebSocket-Protocol", "realtime, openai-insecure-api-key.abc123")
+	h(t.Context(), r)
+
+	if got != "Bearer abc123" {
+		t.Errorf("authorization: got %q, want %q", got, "Bearer abc123")
+	}
+}
+
+func TestRealtimeProtocol(t *testing.T) {
+	a := &app{
+		log:  logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" }),
+		pool: &pool.Pool{},
+	}
+
+	webApp := web.NewApp(func(context.Context, string, ...any) {})
+	webApp.HandlerFunc(http.MethodGet, "v1", "/realtime", a.realtime)
+
+	srv := httptest.NewServer(webApp)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/v1/realtime"
+
+	if _, resp, err := websocket.DefaultDialer.Dial(url+"?intent=conversation", nil); err == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unsupported intent: expected a 400 handshake failure, got %v", err)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?intent=transcription", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	read := func() map[string]any {
+		t.Helper()
+		var ev map[string]any
+		if err := conn.ReadJSON(&ev); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return ev
+	}
+
+	if ev := read(); ev["type"] != "transcription_session.created" {
+		t.Fatalf("first event: got %v", ev)
+	}
+
+	conn.WriteJSON(map[string]any{"type": "input_audio_buffer.append", "event_id": "evt_1", "audio": "AAAA"})
+	ev := read()
+	detail, _ := ev["error"].(map[string]any)
+	if ev["type"] != "error" || detail["type"] != "invalid_request_error" || detail["event_id"] != "evt_1" {
+		t.Errorf("append without model: got %v", ev)
+	}
+
+	conn.WriteJSON(map[string]any{"type": "input_audio_buffer.clear"})
+	if ev := read(); ev["type"] != "input_audio_buffer.cleared" {
+		t.Errorf("clear: got %v", ev)
+	}
+
+	conn.WriteJSON(map[string]any{"type": "transcription_session.update", "session": map[string]any{"input_audio_format": "g711_alaw"}})
+	if ev := read(); ev["type"] != "error" {
+		t.Errorf("invalid update: got %v", ev)
+	}
+
+	conn.WriteJSON(map[string]any{"type": "transcription_session.update", "session": map[string]any{"input_audio_transcription": map[string]any{"model": "whisper-base"}}})
+	ev = read()
+	session, _ := ev["session"].(map[string]any)
+	transcription, _ := session["input_audio_transcription"].(map[string]any)
+	if ev["type"] != "transcription_session.updated" || transcription["model"] != "whisper-base" {
+		t.Errorf("update: got %v", ev)
+	}
+}
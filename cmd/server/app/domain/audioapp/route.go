@@ -25,7 +25,11 @@ func Routes(app *web.App, cfg Config) {
 
 	api := newApp(cfg)
 
-	inferenceAccess := mid.NewAccess(cfg.AuthClient, cfg.AuthorizationMode, false).Inference("transcriptions")
+	access := mid.NewAccess(cfg.AuthClient, cfg.AuthorizationMode, false)
+	inferenceAccess := access.Inference("transcriptions")
+	translationAccess := access.Inference("translations")
 
 	app.HandlerFunc(http.MethodPost, version, "/audio/transcriptions", api.transcriptions, inferenceAccess)
+	app.HandlerFunc(http.MethodPost, version, "/audio/translations", api.translations, translationAccess)
+	app.HandlerFunc(http.MethodGet, version, "/realtime", api.realtime, subprotocolAuth, inferenceAccess)
 }
@@ -0,0 +1,133 @@
+package audioapp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/bucky/model"
+)
+
+// transcribeFileFunc matches bucky.Bucky.TranscribeFile so tests can stream
+// without a loaded model.
+type transcribeFileFunc func(ctx context.Context, r io.Reader, opts ...model.TranscribeOption) (model.Transcription, error)
+
+// streamTranscription transcribes the upload in chunks and sends each decoded
+// segment as a transcript.text.delta server-sent event, followed by a
+// transcript.text.done event with the full text. Errors found before the
+// first event is written are returned as a normal error response.
+func streamTranscription(ctx context.Context, transcribe transcribeFileFunc, file io.Reader, opts []model.TranscribeOption) web.Encoder {
+	w := web.GetWriter(ctx)
+	rc := http.NewResponseController(w)
+
+	type result struct {
+		tr  model.Transcription
+		err error
+	}
+
+	segC := make(chan model.Segment)
+	resC := make(chan result, 1)
+
+	onSegment := func(seg model.Segment) {
+		select {
+		case segC <- seg:
+		case <-ctx.Done():
+		}
+	}
+
+	opts = append(opts, model.WithChunkMs(streamChunkMs), model.WithOnSegment(onSegment))
+
+	go func() {
+		tr, err := transcribe(ctx, file, opts...)
+		close(segC)
+		resC <- result{tr: tr, err: err}
+	}()
+
+	committed := false
+	commit := func() error {
+		if committed {
+			return nil
+		}
+		committed = true
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+
+		return rc.Flush()
+	}
+
+	write := func(data []byte) error {
+		if err := commit(); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	ticker := time.NewTicker(streamKeepAlive)
+	defer ticker.Stop()
+
+	var writeErr error
+
+	for segC != nil {
+		select {
+		case seg, ok := <-segC:
+			if !ok {
+				segC = nil
+				continue
+			}
+
+			if writeErr == nil {
+				writeErr = writeEvent(write, map[string]any{
+					"type":  "transcript.text.delta",
+					"delta": seg.Text,
+				})
+			}
+
+		case <-ticker.C:
+			if writeErr == nil {
+				writeErr = write([]byte(": keep-alive\n\n"))
+			}
+		}
+	}
+
+	res := <-resC
+
+	switch {
+	case writeErr != nil:
+		return web.NewNoResponseError(fmt.Errorf("stream-transcription: write: %w", writeErr))
+
+	case res.err != nil && !committed:
+		return errs.FromSDK(fmt.Errorf("transcribe: %w", res.err))
+
+	case res.err != nil:
+		appErr := errs.FromSDK(fmt.Errorf("transcribe: %w", res.err))
+		if data, err := json.Marshal(appErr); err == nil {
+			write(fmt.Appendf(nil, "data: %s\n\n", data))
+		}
+		return web.NewNoResponseError(appErr)
+	}
+
+	if err := writeEvent(write, map[string]any{"type": "transcript.text.done", "text": res.tr.Text}); err != nil {
+		return web.NewNoResponseError(fmt.Errorf("stream-transcription: write: %w", err))
+	}
+
+	return web.NewNoResponse()
+}
+
+func writeEvent(write func([]byte) error, event map[string]any) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return write(fmt.Appendf(nil, "data: %s\n\n", data))
+}
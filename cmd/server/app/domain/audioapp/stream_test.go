@@ -0,0 +1,83 @@
+package audioapp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/bucky/model"
+)
+
+func TestStreamTranscription(t *testing.T) {
+	var chunkMs int
+
+	transcribe := func(ctx context.Context, r io.Reader, opts ...model.TranscribeOption) (model.Transcription, error) {
+		var cfg model.TranscribeConfig
+		for _, opt := range opts {
+			opt(&cfg)
+		}
+		chunkMs = cfg.ChunkMs
+
+		cfg.OnSegment(model.Segment{Index: 0, Text: " Hello"})
+		cfg.OnSegment(model.Segment{Index: 1, Text: " world."})
+
+		return model.Transcription{Text: "Hello world."}, nil
+	}
+
+	rr := serveStream(t, transcribe)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: got %d, want 200", rr.Code)
+	}
+	if got := rr.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("content type: got %q", got)
+	}
+	if chunkMs != streamChunkMs {
+		t.Errorf("chunk ms: got %d, want %d", chunkMs, streamChunkMs)
+	}
+
+	want := `data: {"delta":" Hello","type":"transcript.text.delta"}
+
+data: {"delta":" world.","type":"transcript.text.delta"}
+
+data: {"text":"Hello world.","type":"transcript.text.done"}
+
+`
+	if got := rr.Body.String(); got != want {
+		t.Errorf("body:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestStreamTranscriptionErrorBeforeFirstEvent(t *testing.T) {
+	transcribe := func(ctx context.Context, r io.Reader, opts ...model.TranscribeOption) (model.Transcription, error) {
+		return model.Transcription{}, errors.New("decode failed")
+	}
+
+	rr := serveStream(t, transcribe)
+
+	if rr.Code == http.StatusOK {
+		t.Fatal("status: an error before the first event should not commit a 200")
+	}
+	if strings.Contains(rr.Body.String(), "data:") {
+		t.Errorf("body: got an event stream %q, want an error response", rr.Body.String())
+	}
+}
+
+func serveStream(t *testing.T, transcribe transcribeFileFunc) *httptest.ResponseRecorder {
+	t.Helper()
+
+	app := web.NewApp(func(context.Context, string, ...any) {})
+	app.HandlerFunc(http.MethodPost, "v1", "/audio/transcriptions", func(ctx context.Context, r *http.Request) web.Encoder {
+		return streamTranscription(ctx, transcribe, strings.NewReader("audio"), nil)
+	})
+
+	rr := httptest.NewRecorder()
+	app.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/v1/audio/transcriptions", nil))
+
+	return rr
+}
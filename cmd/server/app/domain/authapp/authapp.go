@@ -13,6 +13,7 @@ import (
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/rate"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/tokenstore"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
 	"github.com/ardanlabs/kronk/sdk/kronk/observ/otel"
@@ -66,24 +67,34 @@ func (a *App) Authenticate(ctx context.Context, req *AuthenticateRequest) (*Auth
 
 	a.log.Info(ctx, "auth", "method", "checking authentication")
 
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, status.Error(codes.Unauthenticated, "authentication failed")
-	}
-
-	bearerToken := md.Get("authorization")
-	if len(bearerToken) == 0 {
-		return nil, status.Error(codes.Unauthenticated, "authentication failed")
+	bearerToken, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	claims, err := a.security.Authenticate(ctx, bearerToken[0], req.GetAdmin(), req.GetEndpoint())
+	claims, err := a.security.Authenticate(ctx, bearerToken, req.GetAdmin(), req.GetEndpoint())
 	if err != nil {
 		a.log.Error(ctx, "authenticate", "err", err)
 		return nil, authenticationError(err)
 	}
 
+	if model := req.GetModel(); model != "" {
+		if err := a.security.AuthorizeModel(ctx, claims, model); err != nil {
+			a.log.Error(ctx, "authenticate", "model", model, "err", err)
+			return nil, authenticationError(err)
+		}
+	}
+
 	arb := AuthenticateResponse_builder{
-		Subject: &claims.Subject,
+		Subject:      &claims.Subject,
+		Priority:     &claims.Priority,
+		TokenBudgets: new(len(claims.TokenBudgets) > 0),
+		ModelScoped:  new(!claims.Admin && len(claims.Models) > 0),
+		TokenId:      &claims.ID,
+	}
+
+	if claims.ExpiresAt != nil {
+		arb.ExpiresAt = new(claims.ExpiresAt.Unix())
 	}
 
 	return arb.Build(), nil
@@ -109,7 +120,34 @@ func (a *App) CreateToken(ctx context.Context, req *CreateTokenRequest) (*Create
 		}
 	}
 
-	token, err := a.security.GenerateToken(req.GetAdmin(), endpoints, duration)
+	if err := auth.ValidatePriority(req.GetPriority()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	budgets := make([]auth.TokenBudget, 0, len(req.GetTokenBudgets()))
+	for _, tb := range req.GetTokenBudgets() {
+		window, err := auth.ParseRateWindow(tb.GetWindow())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid window for token budget %q: %v", tb.GetModel(), err)
+		}
+
+		budgets = append(budgets, auth.TokenBudget{
+			Model:  tb.GetModel(),
+			Input:  tb.GetInput(),
+			Output: tb.GetOutput(),
+			Window: window,
+		})
+	}
+
+	if err := auth.ValidateTokenBudgets(budgets); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := auth.ValidateModels(req.GetModels()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	token, err := a.security.GenerateToken(req.GetAdmin(), endpoints, duration, security.WithPriority(req.GetPriority()), security.WithTokenBudgets(budgets), security.WithModels(req.GetModels()))
 	if err != nil {
 		a.log.Error(ctx, "token", "err", err)
 		return nil, status.Error(codes.Internal, "failed to generate token")
@@ -171,8 +209,237 @@ func (a *App) RemoveKey(ctx context.Context, req *RemoveKeyRequest) (*RemoveKeyR
 	return &RemoveKeyResponse{}, nil
 }
 
+// CheckTokens reports the token budgets of the bearer token that apply to a
+// request for the model. An exhausted budget is reported in the response
+// rather than as an error so the caller can surface the quotas.
+func (a *App) CheckTokens(ctx context.Context, req *CheckTokensRequest) (*TokenQuotasResponse, error) {
+	if !a.enabled {
+		return &TokenQuotasResponse{}, nil
+	}
+
+	bearerToken, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas, err := a.security.CheckTokens(ctx, bearerToken, req.GetModel())
+	if err != nil && !errors.Is(err, rate.ErrTokenBudgetExceeded) {
+		a.log.Error(ctx, "checktokens", "err", err)
+		return nil, authenticationError(err)
+	}
+
+	return toTokenQuotasResponse(quotas), nil
+}
+
+// AuthorizedModels filters the requested models to those the bearer token
+// may use. With authentication disabled every model is returned.
+func (a *App) AuthorizedModels(ctx context.Context, req *AuthorizedModelsRequest) (*AuthorizedModelsResponse, error) {
+	if !a.enabled {
+		return AuthorizedModelsResponse_builder{Models: req.GetModels()}.Build(), nil
+	}
+
+	bearerToken, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	models, err := a.security.AuthorizedModels(ctx, bearerToken, req.GetModels())
+	if err != nil {
+		a.log.Error(ctx, "authorizedmodels", "err", err)
+		return nil, authenticationError(err)
+	}
+
+	return AuthorizedModelsResponse_builder{Models: models}.Build(), nil
+}
+
+// ChargeTokens charges the tokens a completed request used to the token
+// budgets of the bearer token.
+func (a *App) ChargeTokens(ctx context.Context, req *ChargeTokensRequest) (*TokenQuotasResponse, error) {
+	if !a.enabled {
+		return &TokenQuotasResponse{}, nil
+	}
+
+	bearerToken, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas, err := a.security.ChargeTokens(ctx, bearerToken, req.GetModel(), req.GetInput(), req.GetOutput())
+	if err != nil {
+		a.log.Error(ctx, "chargetokens", "err", err)
+		return nil, authenticationError(err)
+	}
+
+	return toTokenQuotasResponse(quotas), nil
+}
+
+// TokenQuotas returns the token budget use recorded for a subject.
+func (a *App) TokenQuotas(ctx context.Context, req *TokenQuotasRequest) (*TokenQuotasResponse, error) {
+	subject := req.GetSubject()
+	if subject == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing subject")
+	}
+
+	quotas, err := a.security.TokenQuotas(subject)
+	if err != nil {
+		a.log.Error(ctx, "tokenquotas", "err", err)
+		return nil, status.Error(codes.Internal, "failed to list token quotas")
+	}
+
+	return toTokenQuotasResponse(quotas), nil
+}
+
+// RevokeToken revokes a single token by ID or by the token itself.
+func (a *App) RevokeToken(ctx context.Context, req *RevokeTokenRequest) (*RevokeTokenResponse, error) {
+	if req.GetId() == "" && req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing token id or token")
+	}
+
+	token, err := a.security.RevokeToken(ctx, req.GetId(), req.GetToken())
+	if err != nil {
+		a.log.Error(ctx, "revoketoken", "err", err)
+
+		switch {
+		case errors.Is(err, tokenstore.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "token not found")
+		case errors.Is(err, security.ErrUnauthenticated):
+			return nil, status.Error(codes.InvalidArgument, "token does not verify")
+		}
+
+		return nil, status.Error(codes.Internal, "failed to revoke token")
+	}
+
+	rtrb := RevokeTokenResponse_builder{
+		Token: toTokenInfo(token),
+	}
+
+	return rtrb.Build(), nil
+}
+
+// ListTokens returns the issued and revoked tokens that have not expired.
+func (a *App) ListTokens(ctx context.Context, req *ListTokensRequest) (*ListTokensResponse, error) {
+	tokens, err := a.security.ListTokens()
+	if err != nil {
+		a.log.Error(ctx, "listtokens", "err", err)
+		return nil, status.Error(codes.Internal, "failed to list tokens")
+	}
+
+	protoTokens := make([]*TokenInfo, len(tokens))
+	for i, token := range tokens {
+		protoTokens[i] = toTokenInfo(token)
+	}
+
+	ltrb := ListTokensResponse_builder{
+		Tokens: protoTokens,
+	}
+
+	return ltrb.Build(), nil
+}
+
+// IntrospectToken reports a token's claims, expiry and remaining rate budget.
+// A token that does not verify or has expired is reported as inactive.
+func (a *App) IntrospectToken(ctx context.Context, req *IntrospectTokenRequest) (*IntrospectTokenResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing token")
+	}
+
+	in, err := a.security.IntrospectToken(ctx, req.GetToken())
+	if err != nil {
+		a.log.Error(ctx, "introspecttoken", "err", err)
+		return nil, status.Error(codes.Internal, "failed to introspect token")
+	}
+
+	if in.Token.Subject == "" {
+		return IntrospectTokenResponse_builder{Active: new(false)}.Build(), nil
+	}
+
+	endpoints := make([]*EndpointUsage, len(in.Endpoints))
+	for i, usage := range in.Endpoints {
+		eub := EndpointUsage_builder{
+			Endpoint: &usage.Endpoint,
+			Limit: RateLimit_builder{
+				Limit:  new(int32(usage.Limit.Limit)),
+				Window: new(usage.Limit.Window.String()),
+			}.Build(),
+			Used: new(int64(usage.Used)),
+		}
+		if !usage.Reset.IsZero() {
+			eub.Reset = new(usage.Reset.Unix())
+		}
+		endpoints[i] = eub.Build()
+	}
+
+	itrb := IntrospectTokenResponse_builder{
+		Active:       &in.Active,
+		Token:        toTokenInfo(in.Token),
+		Priority:     &in.Priority,
+		Endpoints:    endpoints,
+		TokenBudgets: toTokenQuotasResponse(in.TokenBudgets).GetQuotas(),
+		Models:       in.Models,
+	}
+
+	return itrb.Build(), nil
+}
+
 // =============================================================================
 
+func toTokenInfo(token tokenstore.Token) *TokenInfo {
+	tib := TokenInfo_builder{
+		Id:        &token.ID,
+		Subject:   &token.Subject,
+		Admin:     &token.Admin,
+		IssuedAt:  new(token.IssuedAt.Unix()),
+		ExpiresAt: new(token.ExpiresAt.Unix()),
+	}
+
+	if token.Revoked() {
+		tib.RevokedAt = new(token.RevokedAt.Unix())
+	}
+
+	return tib.Build()
+}
+
+func bearerToken(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "authentication failed")
+	}
+
+	bearerToken := md.Get("authorization")
+	if len(bearerToken) == 0 {
+		return "", status.Error(codes.Unauthenticated, "authentication failed")
+	}
+
+	return bearerToken[0], nil
+}
+
+func toTokenQuotasResponse(quotas []rate.TokenQuota) *TokenQuotasResponse {
+	var exceeded bool
+
+	protoQuotas := make([]*TokenQuota, len(quotas))
+	for i, q := range quotas {
+		qb := TokenQuota_builder{
+			Model:       &q.Model,
+			Window:      new(q.Window.String()),
+			InputLimit:  &q.InputLimit,
+			InputUsed:   &q.InputUsed,
+			OutputLimit: &q.OutputLimit,
+			OutputUsed:  &q.OutputUsed,
+			Reset:       new(q.Reset.Unix()),
+		}
+		protoQuotas[i] = qb.Build()
+
+		exceeded = exceeded || q.Exhausted()
+	}
+
+	tqrb := TokenQuotasResponse_builder{
+		Quotas:   protoQuotas,
+		Exceeded: &exceeded,
+	}
+
+	return tqrb.Build()
+}
+
 func authenticationError(err error) error {
 	switch {
 	case errors.Is(err, security.ErrUnauthenticated):
@@ -212,7 +479,11 @@ func (a *App) authInterceptor(ctx context.Context, req any, info *grpc.UnaryServ
 	case Auth_CreateToken_FullMethodName,
 		Auth_ListKeys_FullMethodName,
 		Auth_AddKey_FullMethodName,
-		Auth_RemoveKey_FullMethodName:
+		Auth_RemoveKey_FullMethodName,
+		Auth_TokenQuotas_FullMethodName,
+		Auth_RevokeToken_FullMethodName,
+		Auth_ListTokens_FullMethodName,
+		Auth_IntrospectToken_FullMethodName:
 		return a.requireAuth(ctx, true, "", req, handler)
 
 	default:
@@ -131,16 +131,19 @@ func (b0 RateLimit_builder) Build() *RateLimit {
 
 // Request message for generating a token.
 type CreateTokenRequest struct {
-	state                  protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Token       *string                `protobuf:"bytes,1,opt,name=token"`
-	xxx_hidden_UserName    *string                `protobuf:"bytes,2,opt,name=user_name,json=userName"`
-	xxx_hidden_Admin       bool                   `protobuf:"varint,3,opt,name=admin"`
-	xxx_hidden_Duration    *string                `protobuf:"bytes,4,opt,name=duration"`
-	xxx_hidden_Endpoints   map[string]*RateLimit  `protobuf:"bytes,5,rep,name=endpoints" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	XXX_raceDetectHookData protoimpl.RaceDetectHookData
-	XXX_presence           [1]uint32
-	unknownFields          protoimpl.UnknownFields
-	sizeCache              protoimpl.SizeCache
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Token        *string                `protobuf:"bytes,1,opt,name=token"`
+	xxx_hidden_UserName     *string                `protobuf:"bytes,2,opt,name=user_name,json=userName"`
+	xxx_hidden_Admin        bool                   `protobuf:"varint,3,opt,name=admin"`
+	xxx_hidden_Duration     *string                `protobuf:"bytes,4,opt,name=duration"`
+	xxx_hidden_Endpoints    map[string]*RateLimit  `protobuf:"bytes,5,rep,name=endpoints" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	xxx_hidden_Priority     *string                `protobuf:"bytes,6,opt,name=priority"`
+	xxx_hidden_TokenBudgets *[]*TokenBudget        `protobuf:"bytes,7,rep,name=token_budgets,json=tokenBudgets"`
+	xxx_hidden_Models       []string               `protobuf:"bytes,8,rep,name=models"`
+	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
+	XXX_presence            [1]uint32
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
 }
 
 func (x *CreateTokenRequest) Reset() {
@@ -212,30 +215,69 @@ func (x *CreateTokenRequest) GetEndpoints() map[string]*RateLimit {
 	return nil
 }
 
+func (x *CreateTokenRequest) GetPriority() string {
+	if x != nil {
+		if x.xxx_hidden_Priority != nil {
+			return *x.xxx_hidden_Priority
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *CreateTokenRequest) GetTokenBudgets() []*TokenBudget {
+	if x != nil {
+		if x.xxx_hidden_TokenBudgets != nil {
+			return *x.xxx_hidden_TokenBudgets
+		}
+	}
+	return nil
+}
+
+func (x *CreateTokenRequest) GetModels() []string {
+	if x != nil {
+		return x.xxx_hidden_Models
+	}
+	return nil
+}
+
 func (x *CreateTokenRequest) SetToken(v string) {
 	x.xxx_hidden_Token = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 5)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 8)
 }
 
 func (x *CreateTokenRequest) SetUserName(v string) {
 	x.xxx_hidden_UserName = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 5)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 8)
 }
 
 func (x *CreateTokenRequest) SetAdmin(v bool) {
 	x.xxx_hidden_Admin = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 5)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 8)
 }
 
 func (x *CreateTokenRequest) SetDuration(v string) {
 	x.xxx_hidden_Duration = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 5)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 8)
 }
 
 func (x *CreateTokenRequest) SetEndpoints(v map[string]*RateLimit) {
 	x.xxx_hidden_Endpoints = v
 }
 
+func (x *CreateTokenRequest) SetPriority(v string) {
+	x.xxx_hidden_Priority = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 8)
+}
+
+func (x *CreateTokenRequest) SetTokenBudgets(v []*TokenBudget) {
+	x.xxx_hidden_TokenBudgets = &v
+}
+
+func (x *CreateTokenRequest) SetModels(v []string) {
+	x.xxx_hidden_Models = v
+}
+
 func (x *CreateTokenRequest) HasToken() bool {
 	if x == nil {
 		return false
@@ -264,6 +306,13 @@ func (x *CreateTokenRequest) HasDuration() bool {
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
 }
 
+func (x *CreateTokenRequest) HasPriority() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
+}
+
 func (x *CreateTokenRequest) ClearToken() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
 	x.xxx_hidden_Token = nil
@@ -284,14 +333,22 @@ func (x *CreateTokenRequest) ClearDuration() {
 	x.xxx_hidden_Duration = nil
 }
 
+func (x *CreateTokenRequest) ClearPriority() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 5)
+	x.xxx_hidden_Priority = nil
+}
+
 type CreateTokenRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
-	Token     *string
-	UserName  *string
-	Admin     *bool
-	Duration  *string
-	Endpoints map[string]*RateLimit
+	Token        *string
+	UserName     *string
+	Admin        *bool
+	Duration     *string
+	Endpoints    map[string]*RateLimit
+	Priority     *string
+	TokenBudgets []*TokenBudget
+	Models       []string
 }
 
 func (b0 CreateTokenRequest_builder) Build() *CreateTokenRequest {
@@ -299,22 +356,200 @@ func (b0 CreateTokenRequest_builder) Build() *CreateTokenRequest {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.Token != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 5)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 8)
 		x.xxx_hidden_Token = b.Token
 	}
 	if b.UserName != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 5)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 8)
 		x.xxx_hidden_UserName = b.UserName
 	}
 	if b.Admin != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 5)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 8)
 		x.xxx_hidden_Admin = *b.Admin
 	}
 	if b.Duration != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 5)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 8)
 		x.xxx_hidden_Duration = b.Duration
 	}
 	x.xxx_hidden_Endpoints = b.Endpoints
+	if b.Priority != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 8)
+		x.xxx_hidden_Priority = b.Priority
+	}
+	x.xxx_hidden_TokenBudgets = &b.TokenBudgets
+	x.xxx_hidden_Models = b.Models
+	return m0
+}
+
+// TokenBudget caps the input and output tokens used within a window.
+type TokenBudget struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Model       *string                `protobuf:"bytes,1,opt,name=model"`
+	xxx_hidden_Input       int64                  `protobuf:"varint,2,opt,name=input"`
+	xxx_hidden_Output      int64                  `protobuf:"varint,3,opt,name=output"`
+	xxx_hidden_Window      *string                `protobuf:"bytes,4,opt,name=window"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *TokenBudget) Reset() {
+	*x = TokenBudget{}
+	mi := &file_authapp_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenBudget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenBudget) ProtoMessage() {}
+
+func (x *TokenBudget) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *TokenBudget) GetModel() string {
+	if x != nil {
+		if x.xxx_hidden_Model != nil {
+			return *x.xxx_hidden_Model
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *TokenBudget) GetInput() int64 {
+	if x != nil {
+		return x.xxx_hidden_Input
+	}
+	return 0
+}
+
+func (x *TokenBudget) GetOutput() int64 {
+	if x != nil {
+		return x.xxx_hidden_Output
+	}
+	return 0
+}
+
+func (x *TokenBudget) GetWindow() string {
+	if x != nil {
+		if x.xxx_hidden_Window != nil {
+			return *x.xxx_hidden_Window
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *TokenBudget) SetModel(v string) {
+	x.xxx_hidden_Model = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 4)
+}
+
+func (x *TokenBudget) SetInput(v int64) {
+	x.xxx_hidden_Input = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 4)
+}
+
+func (x *TokenBudget) SetOutput(v int64) {
+	x.xxx_hidden_Output = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
+}
+
+func (x *TokenBudget) SetWindow(v string) {
+	x.xxx_hidden_Window = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 4)
+}
+
+func (x *TokenBudget) HasModel() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *TokenBudget) HasInput() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *TokenBudget) HasOutput() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *TokenBudget) HasWindow() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *TokenBudget) ClearModel() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Model = nil
+}
+
+func (x *TokenBudget) ClearInput() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Input = 0
+}
+
+func (x *TokenBudget) ClearOutput() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Output = 0
+}
+
+func (x *TokenBudget) ClearWindow() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_Window = nil
+}
+
+type TokenBudget_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Model  *string
+	Input  *int64
+	Output *int64
+	Window *string
+}
+
+func (b0 TokenBudget_builder) Build() *TokenBudget {
+	m0 := &TokenBudget{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Model != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 4)
+		x.xxx_hidden_Model = b.Model
+	}
+	if b.Input != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 4)
+		x.xxx_hidden_Input = *b.Input
+	}
+	if b.Output != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
+		x.xxx_hidden_Output = *b.Output
+	}
+	if b.Window != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 4)
+		x.xxx_hidden_Window = b.Window
+	}
 	return m0
 }
 
@@ -330,7 +565,7 @@ type CreateTokenResponse struct {
 
 func (x *CreateTokenResponse) Reset() {
 	*x = CreateTokenResponse{}
-	mi := &file_authapp_proto_msgTypes[2]
+	mi := &file_authapp_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -342,7 +577,7 @@ func (x *CreateTokenResponse) String() string {
 func (*CreateTokenResponse) ProtoMessage() {}
 
 func (x *CreateTokenResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_authapp_proto_msgTypes[2]
+	mi := &file_authapp_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -403,6 +638,7 @@ type AuthenticateRequest struct {
 	xxx_hidden_Token       *string                `protobuf:"bytes,1,opt,name=token"`
 	xxx_hidden_Admin       bool                   `protobuf:"varint,2,opt,name=admin"`
 	xxx_hidden_Endpoint    *string                `protobuf:"bytes,3,opt,name=endpoint"`
+	xxx_hidden_Model       *string                `protobuf:"bytes,4,opt,name=model"`
 	XXX_raceDetectHookData protoimpl.RaceDetectHookData
 	XXX_presence           [1]uint32
 	unknownFields          protoimpl.UnknownFields
@@ -411,7 +647,7 @@ type AuthenticateRequest struct {
 
 func (x *AuthenticateRequest) Reset() {
 	*x = AuthenticateRequest{}
-	mi := &file_authapp_proto_msgTypes[3]
+	mi := &file_authapp_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -423,7 +659,7 @@ func (x *AuthenticateRequest) String() string {
 func (*AuthenticateRequest) ProtoMessage() {}
 
 func (x *AuthenticateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_authapp_proto_msgTypes[3]
+	mi := &file_authapp_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -461,19 +697,34 @@ func (x *AuthenticateRequest) GetEndpoint() string {
 	return ""
 }
 
+func (x *AuthenticateRequest) GetModel() string {
+	if x != nil {
+		if x.xxx_hidden_Model != nil {
+			return *x.xxx_hidden_Model
+		}
+		return ""
+	}
+	return ""
+}
+
 func (x *AuthenticateRequest) SetToken(v string) {
 	x.xxx_hidden_Token = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 4)
 }
 
 func (x *AuthenticateRequest) SetAdmin(v bool) {
 	x.xxx_hidden_Admin = v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 4)
 }
 
 func (x *AuthenticateRequest) SetEndpoint(v string) {
 	x.xxx_hidden_Endpoint = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 3)
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
+}
+
+func (x *AuthenticateRequest) SetModel(v string) {
+	x.xxx_hidden_Model = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 4)
 }
 
 func (x *AuthenticateRequest) HasToken() bool {
@@ -497,6 +748,13 @@ func (x *AuthenticateRequest) HasEndpoint() bool {
 	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
 }
 
+func (x *AuthenticateRequest) HasModel() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
 func (x *AuthenticateRequest) ClearToken() {
 	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
 	x.xxx_hidden_Token = nil
@@ -512,12 +770,18 @@ func (x *AuthenticateRequest) ClearEndpoint() {
 	x.xxx_hidden_Endpoint = nil
 }
 
+func (x *AuthenticateRequest) ClearModel() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_Model = nil
+}
+
 type AuthenticateRequest_builder struct {
 	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
 
 	Token    *string
 	Admin    *bool
 	Endpoint *string
+	Model    *string
 }
 
 func (b0 AuthenticateRequest_builder) Build() *AuthenticateRequest {
@@ -525,33 +789,42 @@ func (b0 AuthenticateRequest_builder) Build() *AuthenticateRequest {
 	b, x := &b0, m0
 	_, _ = b, x
 	if b.Token != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 4)
 		x.xxx_hidden_Token = b.Token
 	}
 	if b.Admin != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 4)
 		x.xxx_hidden_Admin = *b.Admin
 	}
 	if b.Endpoint != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 3)
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
 		x.xxx_hidden_Endpoint = b.Endpoint
 	}
+	if b.Model != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 4)
+		x.xxx_hidden_Model = b.Model
+	}
 	return m0
 }
 
 // Response message for authentication.
 type AuthenticateResponse struct {
-	state                  protoimpl.MessageState `protogen:"opaque.v1"`
-	xxx_hidden_Subject     *string                `protobuf:"bytes,1,opt,name=subject"`
-	XXX_raceDetectHookData protoimpl.RaceDetectHookData
-	XXX_presence           [1]uint32
-	unknownFields          protoimpl.UnknownFields
-	sizeCache              protoimpl.SizeCache
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Subject      *string                `protobuf:"bytes,1,opt,name=subject"`
+	xxx_hidden_Priority     *string                `protobuf:"bytes,2,opt,name=priority"`
+	xxx_hidden_TokenBudgets bool                   `protobuf:"varint,3,opt,name=token_budgets,json=tokenBudgets"`
+	xxx_hidden_ModelScoped  bool                   `protobuf:"varint,4,opt,name=model_scoped,json=modelScoped"`
+	xxx_hidden_TokenId      *string                `protobuf:"bytes,5,opt,name=token_id,json=tokenId"`
+	xxx_hidden_ExpiresAt    int64                  `protobuf:"varint,6,opt,name=expires_at,json=expiresAt"`
+	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
+	XXX_presence            [1]uint32
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
 }
 
 func (x *AuthenticateResponse) Reset() {
 	*x = AuthenticateResponse{}
-	mi := &file_authapp_proto_msgTypes[4]
+	mi := &file_authapp_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -563,7 +836,7 @@ func (x *AuthenticateResponse) String() string {
 func (*AuthenticateResponse) ProtoMessage() {}
 
 func (x *AuthenticateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_authapp_proto_msgTypes[4]
+	mi := &file_authapp_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -584,63 +857,214 @@ func (x *AuthenticateResponse) GetSubject() string {
 	return ""
 }
 
-func (x *AuthenticateResponse) SetSubject(v string) {
-	x.xxx_hidden_Subject = &v
-	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+func (x *AuthenticateResponse) GetPriority() string {
+	if x != nil {
+		if x.xxx_hidden_Priority != nil {
+			return *x.xxx_hidden_Priority
+		}
+		return ""
+	}
+	return ""
 }
 
-func (x *AuthenticateResponse) HasSubject() bool {
-	if x == nil {
-		return false
+func (x *AuthenticateResponse) GetTokenBudgets() bool {
+	if x != nil {
+		return x.xxx_hidden_TokenBudgets
 	}
-	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+	return false
 }
 
-func (x *AuthenticateResponse) ClearSubject() {
-	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
-	x.xxx_hidden_Subject = nil
+func (x *AuthenticateResponse) GetModelScoped() bool {
+	if x != nil {
+		return x.xxx_hidden_ModelScoped
+	}
+	return false
 }
 
-type AuthenticateResponse_builder struct {
-	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
-
-	Subject *string
+func (x *AuthenticateResponse) GetTokenId() string {
+	if x != nil {
+		if x.xxx_hidden_TokenId != nil {
+			return *x.xxx_hidden_TokenId
+		}
+		return ""
+	}
+	return ""
 }
 
-func (b0 AuthenticateResponse_builder) Build() *AuthenticateResponse {
-	m0 := &AuthenticateResponse{}
-	b, x := &b0, m0
-	_, _ = b, x
-	if b.Subject != nil {
-		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
-		x.xxx_hidden_Subject = b.Subject
+func (x *AuthenticateResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.xxx_hidden_ExpiresAt
 	}
-	return m0
+	return 0
 }
 
-// Request message for listing keys.
-type ListKeysRequest struct {
-	state         protoimpl.MessageState `protogen:"opaque.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *AuthenticateResponse) SetSubject(v string) {
+	x.xxx_hidden_Subject = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 6)
 }
 
-func (x *ListKeysRequest) Reset() {
-	*x = ListKeysRequest{}
-	mi := &file_authapp_proto_msgTypes[5]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *AuthenticateResponse) SetPriority(v string) {
+	x.xxx_hidden_Priority = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 6)
 }
 
-func (x *ListKeysRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *AuthenticateResponse) SetTokenBudgets(v bool) {
+	x.xxx_hidden_TokenBudgets = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 6)
 }
 
-func (*ListKeysRequest) ProtoMessage() {}
+func (x *AuthenticateResponse) SetModelScoped(v bool) {
+	x.xxx_hidden_ModelScoped = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 6)
+}
 
-func (x *ListKeysRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_authapp_proto_msgTypes[5]
-	if x != nil {
+func (x *AuthenticateResponse) SetTokenId(v string) {
+	x.xxx_hidden_TokenId = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 6)
+}
+
+func (x *AuthenticateResponse) SetExpiresAt(v int64) {
+	x.xxx_hidden_ExpiresAt = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 6)
+}
+
+func (x *AuthenticateResponse) HasSubject() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *AuthenticateResponse) HasPriority() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *AuthenticateResponse) HasTokenBudgets() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *AuthenticateResponse) HasModelScoped() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *AuthenticateResponse) HasTokenId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 4)
+}
+
+func (x *AuthenticateResponse) HasExpiresAt() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
+}
+
+func (x *AuthenticateResponse) ClearSubject() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Subject = nil
+}
+
+func (x *AuthenticateResponse) ClearPriority() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Priority = nil
+}
+
+func (x *AuthenticateResponse) ClearTokenBudgets() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_TokenBudgets = false
+}
+
+func (x *AuthenticateResponse) ClearModelScoped() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_ModelScoped = false
+}
+
+func (x *AuthenticateResponse) ClearTokenId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 4)
+	x.xxx_hidden_TokenId = nil
+}
+
+func (x *AuthenticateResponse) ClearExpiresAt() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 5)
+	x.xxx_hidden_ExpiresAt = 0
+}
+
+type AuthenticateResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Subject      *string
+	Priority     *string
+	TokenBudgets *bool
+	ModelScoped  *bool
+	TokenId      *string
+	ExpiresAt    *int64
+}
+
+func (b0 AuthenticateResponse_builder) Build() *AuthenticateResponse {
+	m0 := &AuthenticateResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Subject != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 6)
+		x.xxx_hidden_Subject = b.Subject
+	}
+	if b.Priority != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 6)
+		x.xxx_hidden_Priority = b.Priority
+	}
+	if b.TokenBudgets != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 6)
+		x.xxx_hidden_TokenBudgets = *b.TokenBudgets
+	}
+	if b.ModelScoped != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 6)
+		x.xxx_hidden_ModelScoped = *b.ModelScoped
+	}
+	if b.TokenId != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 6)
+		x.xxx_hidden_TokenId = b.TokenId
+	}
+	if b.ExpiresAt != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 6)
+		x.xxx_hidden_ExpiresAt = *b.ExpiresAt
+	}
+	return m0
+}
+
+// Request message for listing keys.
+type ListKeysRequest struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListKeysRequest) Reset() {
+	*x = ListKeysRequest{}
+	mi := &file_authapp_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListKeysRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListKeysRequest) ProtoMessage() {}
+
+func (x *ListKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[6]
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -672,7 +1096,7 @@ type ListKeysResponse struct {
 
 func (x *ListKeysResponse) Reset() {
 	*x = ListKeysResponse{}
-	mi := &file_authapp_proto_msgTypes[6]
+	mi := &file_authapp_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -684,7 +1108,7 @@ func (x *ListKeysResponse) String() string {
 func (*ListKeysResponse) ProtoMessage() {}
 
 func (x *ListKeysResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_authapp_proto_msgTypes[6]
+	mi := &file_authapp_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -735,7 +1159,7 @@ type Key struct {
 
 func (x *Key) Reset() {
 	*x = Key{}
-	mi := &file_authapp_proto_msgTypes[7]
+	mi := &file_authapp_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -747,7 +1171,7 @@ func (x *Key) String() string {
 func (*Key) ProtoMessage() {}
 
 func (x *Key) ProtoReflect() protoreflect.Message {
-	mi := &file_authapp_proto_msgTypes[7]
+	mi := &file_authapp_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -843,7 +1267,7 @@ type AddKeyRequest struct {
 
 func (x *AddKeyRequest) Reset() {
 	*x = AddKeyRequest{}
-	mi := &file_authapp_proto_msgTypes[8]
+	mi := &file_authapp_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -855,7 +1279,7 @@ func (x *AddKeyRequest) String() string {
 func (*AddKeyRequest) ProtoMessage() {}
 
 func (x *AddKeyRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_authapp_proto_msgTypes[8]
+	mi := &file_authapp_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -887,7 +1311,7 @@ type AddKeyResponse struct {
 
 func (x *AddKeyResponse) Reset() {
 	*x = AddKeyResponse{}
-	mi := &file_authapp_proto_msgTypes[9]
+	mi := &file_authapp_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -899,7 +1323,7 @@ func (x *AddKeyResponse) String() string {
 func (*AddKeyResponse) ProtoMessage() {}
 
 func (x *AddKeyResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_authapp_proto_msgTypes[9]
+	mi := &file_authapp_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -934,7 +1358,7 @@ type RemoveKeyRequest struct {
 
 func (x *RemoveKeyRequest) Reset() {
 	*x = RemoveKeyRequest{}
-	mi := &file_authapp_proto_msgTypes[10]
+	mi := &file_authapp_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -946,7 +1370,7 @@ func (x *RemoveKeyRequest) String() string {
 func (*RemoveKeyRequest) ProtoMessage() {}
 
 func (x *RemoveKeyRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_authapp_proto_msgTypes[10]
+	mi := &file_authapp_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1010,7 +1434,7 @@ type RemoveKeyResponse struct {
 
 func (x *RemoveKeyResponse) Reset() {
 	*x = RemoveKeyResponse{}
-	mi := &file_authapp_proto_msgTypes[11]
+	mi := &file_authapp_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1022,7 +1446,7 @@ func (x *RemoveKeyResponse) String() string {
 func (*RemoveKeyResponse) ProtoMessage() {}
 
 func (x *RemoveKeyResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_authapp_proto_msgTypes[11]
+	mi := &file_authapp_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1045,84 +1469,1912 @@ func (b0 RemoveKeyResponse_builder) Build() *RemoveKeyResponse {
 	return m0
 }
 
-var File_authapp_proto protoreflect.FileDescriptor
+// Request message for checking token budgets.
+type CheckTokensRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Model       *string                `protobuf:"bytes,1,opt,name=model"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
 
-const file_authapp_proto_rawDesc = "" +
-	"\n" +
-	"\rauthapp.proto\x12\aauthapp\"9\n" +
-	"\tRateLimit\x12\x14\n" +
-	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x16\n" +
-	"\x06window\x18\x02 \x01(\tR\x06window\"\x95\x02\n" +
-	"\x12CreateTokenRequest\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1b\n" +
-	"\tuser_name\x18\x02 \x01(\tR\buserName\x12\x14\n" +
-	"\x05admin\x18\x03 \x01(\bR\x05admin\x12\x1a\n" +
-	"\bduration\x18\x04 \x01(\tR\bduration\x12H\n" +
-	"\tendpoints\x18\x05 \x03(\v2*.authapp.CreateTokenRequest.EndpointsEntryR\tendpoints\x1aP\n" +
-	"\x0eEndpointsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12(\n" +
-	"\x05value\x18\x02 \x01(\v2\x12.authapp.RateLimitR\x05value:\x028\x01\"+\n" +
-	"\x13CreateTokenResponse\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\"]\n" +
-	"\x13AuthenticateRequest\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\x12\x14\n" +
-	"\x05admin\x18\x02 \x01(\bR\x05admin\x12\x1a\n" +
-	"\bendpoint\x18\x03 \x01(\tR\bendpoint\"0\n" +
-	"\x14AuthenticateResponse\x12\x18\n" +
-	"\asubject\x18\x01 \x01(\tR\asubject\"\x11\n" +
-	"\x0fListKeysRequest\"4\n" +
-	"\x10ListKeysResponse\x12 \n" +
-	"\x04keys\x18\x01 \x03(\v2\f.authapp.KeyR\x04keys\"/\n" +
-	"\x03Key\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
-	"\acreated\x18\x02 \x01(\tR\acreated\"\x0f\n" +
-	"\rAddKeyRequest\"\x10\n" +
-	"\x0eAddKeyResponse\")\n" +
-	"\x10RemoveKeyRequest\x12\x15\n" +
-	"\x06key_id\x18\x01 \x01(\tR\x05keyId\"\x13\n" +
-	"\x11RemoveKeyResponse2\xdd\x02\n" +
-	"\x04Auth\x12H\n" +
-	"\vCreateToken\x12\x1b.authapp.CreateTokenRequest\x1a\x1c.authapp.CreateTokenResponse\x12K\n" +
-	"\fAuthenticate\x12\x1c.authapp.AuthenticateRequest\x1a\x1d.authapp.AuthenticateResponse\x12?\n" +
-	"\bListKeys\x12\x18.authapp.ListKeysRequest\x1a\x19.authapp.ListKeysResponse\x129\n" +
-	"\x06AddKey\x12\x16.authapp.AddKeyRequest\x1a\x17.authapp.AddKeyResponse\x12B\n" +
-	"\tRemoveKey\x12\x19.authapp.RemoveKeyRequest\x1a\x1a.authapp.RemoveKeyResponseB:Z8github.com/ardanlabs/kronk/cmd/server/app/domain/authappb\beditionsp\xe9\a"
+func (x *CheckTokensRequest) Reset() {
+	*x = CheckTokensRequest{}
+	mi := &file_authapp_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckTokensRequest) ProtoMessage() {}
+
+func (x *CheckTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *CheckTokensRequest) GetModel() string {
+	if x != nil {
+		if x.xxx_hidden_Model != nil {
+			return *x.xxx_hidden_Model
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *CheckTokensRequest) SetModel(v string) {
+	x.xxx_hidden_Model = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *CheckTokensRequest) HasModel() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *CheckTokensRequest) ClearModel() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Model = nil
+}
+
+type CheckTokensRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Model *string
+}
+
+func (b0 CheckTokensRequest_builder) Build() *CheckTokensRequest {
+	m0 := &CheckTokensRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Model != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Model = b.Model
+	}
+	return m0
+}
+
+// Request message for charging token budgets.
+type ChargeTokensRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Model       *string                `protobuf:"bytes,1,opt,name=model"`
+	xxx_hidden_Input       int64                  `protobuf:"varint,2,opt,name=input"`
+	xxx_hidden_Output      int64                  `protobuf:"varint,3,opt,name=output"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *ChargeTokensRequest) Reset() {
+	*x = ChargeTokensRequest{}
+	mi := &file_authapp_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChargeTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChargeTokensRequest) ProtoMessage() {}
+
+func (x *ChargeTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ChargeTokensRequest) GetModel() string {
+	if x != nil {
+		if x.xxx_hidden_Model != nil {
+			return *x.xxx_hidden_Model
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *ChargeTokensRequest) GetInput() int64 {
+	if x != nil {
+		return x.xxx_hidden_Input
+	}
+	return 0
+}
+
+func (x *ChargeTokensRequest) GetOutput() int64 {
+	if x != nil {
+		return x.xxx_hidden_Output
+	}
+	return 0
+}
+
+func (x *ChargeTokensRequest) SetModel(v string) {
+	x.xxx_hidden_Model = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 3)
+}
+
+func (x *ChargeTokensRequest) SetInput(v int64) {
+	x.xxx_hidden_Input = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 3)
+}
+
+func (x *ChargeTokensRequest) SetOutput(v int64) {
+	x.xxx_hidden_Output = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 3)
+}
+
+func (x *ChargeTokensRequest) HasModel() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *ChargeTokensRequest) HasInput() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *ChargeTokensRequest) HasOutput() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *ChargeTokensRequest) ClearModel() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Model = nil
+}
+
+func (x *ChargeTokensRequest) ClearInput() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Input = 0
+}
+
+func (x *ChargeTokensRequest) ClearOutput() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Output = 0
+}
+
+type ChargeTokensRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Model  *string
+	Input  *int64
+	Output *int64
+}
+
+func (b0 ChargeTokensRequest_builder) Build() *ChargeTokensRequest {
+	m0 := &ChargeTokensRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Model != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 3)
+		x.xxx_hidden_Model = b.Model
+	}
+	if b.Input != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 3)
+		x.xxx_hidden_Input = *b.Input
+	}
+	if b.Output != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 3)
+		x.xxx_hidden_Output = *b.Output
+	}
+	return m0
+}
+
+// Request message for listing token budget use.
+type TokenQuotasRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Subject     *string                `protobuf:"bytes,1,opt,name=subject"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *TokenQuotasRequest) Reset() {
+	*x = TokenQuotasRequest{}
+	mi := &file_authapp_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenQuotasRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenQuotasRequest) ProtoMessage() {}
+
+func (x *TokenQuotasRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *TokenQuotasRequest) GetSubject() string {
+	if x != nil {
+		if x.xxx_hidden_Subject != nil {
+			return *x.xxx_hidden_Subject
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *TokenQuotasRequest) SetSubject(v string) {
+	x.xxx_hidden_Subject = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *TokenQuotasRequest) HasSubject() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *TokenQuotasRequest) ClearSubject() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Subject = nil
+}
+
+type TokenQuotasRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Subject *string
+}
+
+func (b0 TokenQuotasRequest_builder) Build() *TokenQuotasRequest {
+	m0 := &TokenQuotasRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Subject != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Subject = b.Subject
+	}
+	return m0
+}
+
+// TokenQuota reports the use of a token budget within its current window.
+type TokenQuota struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Model       *string                `protobuf:"bytes,1,opt,name=model"`
+	xxx_hidden_Window      *string                `protobuf:"bytes,2,opt,name=window"`
+	xxx_hidden_InputLimit  int64                  `protobuf:"varint,3,opt,name=input_limit,json=inputLimit"`
+	xxx_hidden_InputUsed   int64                  `protobuf:"varint,4,opt,name=input_used,json=inputUsed"`
+	xxx_hidden_OutputLimit int64                  `protobuf:"varint,5,opt,name=output_limit,json=outputLimit"`
+	xxx_hidden_OutputUsed  int64                  `protobuf:"varint,6,opt,name=output_used,json=outputUsed"`
+	xxx_hidden_Reset_      int64                  `protobuf:"varint,7,opt,name=reset"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *TokenQuota) Reset() {
+	*x = TokenQuota{}
+	mi := &file_authapp_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenQuota) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenQuota) ProtoMessage() {}
+
+func (x *TokenQuota) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *TokenQuota) GetModel() string {
+	if x != nil {
+		if x.xxx_hidden_Model != nil {
+			return *x.xxx_hidden_Model
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *TokenQuota) GetWindow() string {
+	if x != nil {
+		if x.xxx_hidden_Window != nil {
+			return *x.xxx_hidden_Window
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *TokenQuota) GetInputLimit() int64 {
+	if x != nil {
+		return x.xxx_hidden_InputLimit
+	}
+	return 0
+}
+
+func (x *TokenQuota) GetInputUsed() int64 {
+	if x != nil {
+		return x.xxx_hidden_InputUsed
+	}
+	return 0
+}
+
+func (x *TokenQuota) GetOutputLimit() int64 {
+	if x != nil {
+		return x.xxx_hidden_OutputLimit
+	}
+	return 0
+}
+
+func (x *TokenQuota) GetOutputUsed() int64 {
+	if x != nil {
+		return x.xxx_hidden_OutputUsed
+	}
+	return 0
+}
+
+func (x *TokenQuota) GetReset() int64 {
+	if x != nil {
+		return x.xxx_hidden_Reset_
+	}
+	return 0
+}
+
+func (x *TokenQuota) SetModel(v string) {
+	x.xxx_hidden_Model = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 7)
+}
+
+func (x *TokenQuota) SetWindow(v string) {
+	x.xxx_hidden_Window = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 7)
+}
+
+func (x *TokenQuota) SetInputLimit(v int64) {
+	x.xxx_hidden_InputLimit = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 7)
+}
+
+func (x *TokenQuota) SetInputUsed(v int64) {
+	x.xxx_hidden_InputUsed = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 7)
+}
+
+func (x *TokenQuota) SetOutputLimit(v int64) {
+	x.xxx_hidden_OutputLimit = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 7)
+}
+
+func (x *TokenQuota) SetOutputUsed(v int64) {
+	x.xxx_hidden_OutputUsed = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 7)
+}
+
+func (x *TokenQuota) SetReset(v int64) {
+	x.xxx_hidden_Reset_ = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 6, 7)
+}
+
+func (x *TokenQuota) HasModel() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *TokenQuota) HasWindow() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *TokenQuota) HasInputLimit() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *TokenQuota) HasInputUsed() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *TokenQuota) HasOutputLimit() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 4)
+}
+
+func (x *TokenQuota) HasOutputUsed() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
+}
+
+func (x *TokenQuota) HasReset() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 6)
+}
+
+func (x *TokenQuota) ClearModel() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Model = nil
+}
+
+func (x *TokenQuota) ClearWindow() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Window = nil
+}
+
+func (x *TokenQuota) ClearInputLimit() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_InputLimit = 0
+}
+
+func (x *TokenQuota) ClearInputUsed() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_InputUsed = 0
+}
+
+func (x *TokenQuota) ClearOutputLimit() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 4)
+	x.xxx_hidden_OutputLimit = 0
+}
+
+func (x *TokenQuota) ClearOutputUsed() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 5)
+	x.xxx_hidden_OutputUsed = 0
+}
+
+func (x *TokenQuota) ClearReset() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 6)
+	x.xxx_hidden_Reset_ = 0
+}
+
+type TokenQuota_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Model       *string
+	Window      *string
+	InputLimit  *int64
+	InputUsed   *int64
+	OutputLimit *int64
+	OutputUsed  *int64
+	Reset       *int64
+}
+
+func (b0 TokenQuota_builder) Build() *TokenQuota {
+	m0 := &TokenQuota{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Model != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 7)
+		x.xxx_hidden_Model = b.Model
+	}
+	if b.Window != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 7)
+		x.xxx_hidden_Window = b.Window
+	}
+	if b.InputLimit != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 7)
+		x.xxx_hidden_InputLimit = *b.InputLimit
+	}
+	if b.InputUsed != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 7)
+		x.xxx_hidden_InputUsed = *b.InputUsed
+	}
+	if b.OutputLimit != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 7)
+		x.xxx_hidden_OutputLimit = *b.OutputLimit
+	}
+	if b.OutputUsed != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 7)
+		x.xxx_hidden_OutputUsed = *b.OutputUsed
+	}
+	if b.Reset != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 6, 7)
+		x.xxx_hidden_Reset_ = *b.Reset
+	}
+	return m0
+}
+
+// Response message for token budget requests.
+type TokenQuotasResponse struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Quotas      *[]*TokenQuota         `protobuf:"bytes,1,rep,name=quotas"`
+	xxx_hidden_Exceeded    bool                   `protobuf:"varint,2,opt,name=exceeded"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *TokenQuotasResponse) Reset() {
+	*x = TokenQuotasResponse{}
+	mi := &file_authapp_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenQuotasResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenQuotasResponse) ProtoMessage() {}
+
+func (x *TokenQuotasResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *TokenQuotasResponse) GetQuotas() []*TokenQuota {
+	if x != nil {
+		if x.xxx_hidden_Quotas != nil {
+			return *x.xxx_hidden_Quotas
+		}
+	}
+	return nil
+}
+
+func (x *TokenQuotasResponse) GetExceeded() bool {
+	if x != nil {
+		return x.xxx_hidden_Exceeded
+	}
+	return false
+}
+
+func (x *TokenQuotasResponse) SetQuotas(v []*TokenQuota) {
+	x.xxx_hidden_Quotas = &v
+}
+
+func (x *TokenQuotasResponse) SetExceeded(v bool) {
+	x.xxx_hidden_Exceeded = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *TokenQuotasResponse) HasExceeded() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *TokenQuotasResponse) ClearExceeded() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Exceeded = false
+}
+
+type TokenQuotasResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Quotas   []*TokenQuota
+	Exceeded *bool
+}
+
+func (b0 TokenQuotasResponse_builder) Build() *TokenQuotasResponse {
+	m0 := &TokenQuotasResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Quotas = &b.Quotas
+	if b.Exceeded != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_Exceeded = *b.Exceeded
+	}
+	return m0
+}
+
+// TokenInfo describes an issued token. Times are Unix seconds and a zero
+// revoked_at means the token is not revoked.
+type TokenInfo struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	xxx_hidden_Subject     *string                `protobuf:"bytes,2,opt,name=subject"`
+	xxx_hidden_Admin       bool                   `protobuf:"varint,3,opt,name=admin"`
+	xxx_hidden_IssuedAt    int64                  `protobuf:"varint,4,opt,name=issued_at,json=issuedAt"`
+	xxx_hidden_ExpiresAt   int64                  `protobuf:"varint,5,opt,name=expires_at,json=expiresAt"`
+	xxx_hidden_RevokedAt   int64                  `protobuf:"varint,6,opt,name=revoked_at,json=revokedAt"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *TokenInfo) Reset() {
+	*x = TokenInfo{}
+	mi := &file_authapp_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenInfo) ProtoMessage() {}
+
+func (x *TokenInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *TokenInfo) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetSubject() string {
+	if x != nil {
+		if x.xxx_hidden_Subject != nil {
+			return *x.xxx_hidden_Subject
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *TokenInfo) GetAdmin() bool {
+	if x != nil {
+		return x.xxx_hidden_Admin
+	}
+	return false
+}
+
+func (x *TokenInfo) GetIssuedAt() int64 {
+	if x != nil {
+		return x.xxx_hidden_IssuedAt
+	}
+	return 0
+}
+
+func (x *TokenInfo) GetExpiresAt() int64 {
+	if x != nil {
+		return x.xxx_hidden_ExpiresAt
+	}
+	return 0
+}
+
+func (x *TokenInfo) GetRevokedAt() int64 {
+	if x != nil {
+		return x.xxx_hidden_RevokedAt
+	}
+	return 0
+}
+
+func (x *TokenInfo) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 6)
+}
+
+func (x *TokenInfo) SetSubject(v string) {
+	x.xxx_hidden_Subject = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 6)
+}
+
+func (x *TokenInfo) SetAdmin(v bool) {
+	x.xxx_hidden_Admin = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 6)
+}
+
+func (x *TokenInfo) SetIssuedAt(v int64) {
+	x.xxx_hidden_IssuedAt = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 6)
+}
+
+func (x *TokenInfo) SetExpiresAt(v int64) {
+	x.xxx_hidden_ExpiresAt = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 4, 6)
+}
+
+func (x *TokenInfo) SetRevokedAt(v int64) {
+	x.xxx_hidden_RevokedAt = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 5, 6)
+}
+
+func (x *TokenInfo) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *TokenInfo) HasSubject() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *TokenInfo) HasAdmin() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *TokenInfo) HasIssuedAt() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *TokenInfo) HasExpiresAt() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 4)
+}
+
+func (x *TokenInfo) HasRevokedAt() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 5)
+}
+
+func (x *TokenInfo) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+func (x *TokenInfo) ClearSubject() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Subject = nil
+}
+
+func (x *TokenInfo) ClearAdmin() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Admin = false
+}
+
+func (x *TokenInfo) ClearIssuedAt() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_IssuedAt = 0
+}
+
+func (x *TokenInfo) ClearExpiresAt() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 4)
+	x.xxx_hidden_ExpiresAt = 0
+}
+
+func (x *TokenInfo) ClearRevokedAt() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 5)
+	x.xxx_hidden_RevokedAt = 0
+}
+
+type TokenInfo_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id        *string
+	Subject   *string
+	Admin     *bool
+	IssuedAt  *int64
+	ExpiresAt *int64
+	RevokedAt *int64
+}
+
+func (b0 TokenInfo_builder) Build() *TokenInfo {
+	m0 := &TokenInfo{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 6)
+		x.xxx_hidden_Id = b.Id
+	}
+	if b.Subject != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 6)
+		x.xxx_hidden_Subject = b.Subject
+	}
+	if b.Admin != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 6)
+		x.xxx_hidden_Admin = *b.Admin
+	}
+	if b.IssuedAt != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 6)
+		x.xxx_hidden_IssuedAt = *b.IssuedAt
+	}
+	if b.ExpiresAt != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 4, 6)
+		x.xxx_hidden_ExpiresAt = *b.ExpiresAt
+	}
+	if b.RevokedAt != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 5, 6)
+		x.xxx_hidden_RevokedAt = *b.RevokedAt
+	}
+	return m0
+}
+
+// Request message for revoking a token.
+type RevokeTokenRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Id          *string                `protobuf:"bytes,1,opt,name=id"`
+	xxx_hidden_Token       *string                `protobuf:"bytes,2,opt,name=token"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *RevokeTokenRequest) Reset() {
+	*x = RevokeTokenRequest{}
+	mi := &file_authapp_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeTokenRequest) ProtoMessage() {}
+
+func (x *RevokeTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *RevokeTokenRequest) GetId() string {
+	if x != nil {
+		if x.xxx_hidden_Id != nil {
+			return *x.xxx_hidden_Id
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *RevokeTokenRequest) GetToken() string {
+	if x != nil {
+		if x.xxx_hidden_Token != nil {
+			return *x.xxx_hidden_Token
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *RevokeTokenRequest) SetId(v string) {
+	x.xxx_hidden_Id = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 2)
+}
+
+func (x *RevokeTokenRequest) SetToken(v string) {
+	x.xxx_hidden_Token = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 1, 2)
+}
+
+func (x *RevokeTokenRequest) HasId() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *RevokeTokenRequest) HasToken() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 1)
+}
+
+func (x *RevokeTokenRequest) ClearId() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Id = nil
+}
+
+func (x *RevokeTokenRequest) ClearToken() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 1)
+	x.xxx_hidden_Token = nil
+}
+
+type RevokeTokenRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Id    *string
+	Token *string
+}
+
+func (b0 RevokeTokenRequest_builder) Build() *RevokeTokenRequest {
+	m0 := &RevokeTokenRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Id != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 2)
+		x.xxx_hidden_Id = b.Id
+	}
+	if b.Token != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 1, 2)
+		x.xxx_hidden_Token = b.Token
+	}
+	return m0
+}
+
+// Response message for revoking a token.
+type RevokeTokenResponse struct {
+	state            protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Token *TokenInfo             `protobuf:"bytes,1,opt,name=token"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *RevokeTokenResponse) Reset() {
+	*x = RevokeTokenResponse{}
+	mi := &file_authapp_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeTokenResponse) ProtoMessage() {}
+
+func (x *RevokeTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *RevokeTokenResponse) GetToken() *TokenInfo {
+	if x != nil {
+		return x.xxx_hidden_Token
+	}
+	return nil
+}
+
+func (x *RevokeTokenResponse) SetToken(v *TokenInfo) {
+	x.xxx_hidden_Token = v
+}
+
+func (x *RevokeTokenResponse) HasToken() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Token != nil
+}
+
+func (x *RevokeTokenResponse) ClearToken() {
+	x.xxx_hidden_Token = nil
+}
+
+type RevokeTokenResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Token *TokenInfo
+}
+
+func (b0 RevokeTokenResponse_builder) Build() *RevokeTokenResponse {
+	m0 := &RevokeTokenResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Token = b.Token
+	return m0
+}
+
+// Request message for listing tokens.
+type ListTokensRequest struct {
+	state         protoimpl.MessageState `protogen:"opaque.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTokensRequest) Reset() {
+	*x = ListTokensRequest{}
+	mi := &file_authapp_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTokensRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTokensRequest) ProtoMessage() {}
+
+func (x *ListTokensRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+type ListTokensRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+}
+
+func (b0 ListTokensRequest_builder) Build() *ListTokensRequest {
+	m0 := &ListTokensRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	return m0
+}
+
+// Response message for listing tokens.
+type ListTokensResponse struct {
+	state             protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Tokens *[]*TokenInfo          `protobuf:"bytes,1,rep,name=tokens"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ListTokensResponse) Reset() {
+	*x = ListTokensResponse{}
+	mi := &file_authapp_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTokensResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTokensResponse) ProtoMessage() {}
+
+func (x *ListTokensResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *ListTokensResponse) GetTokens() []*TokenInfo {
+	if x != nil {
+		if x.xxx_hidden_Tokens != nil {
+			return *x.xxx_hidden_Tokens
+		}
+	}
+	return nil
+}
+
+func (x *ListTokensResponse) SetTokens(v []*TokenInfo) {
+	x.xxx_hidden_Tokens = &v
+}
+
+type ListTokensResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Tokens []*TokenInfo
+}
+
+func (b0 ListTokensResponse_builder) Build() *ListTokensResponse {
+	m0 := &ListTokensResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Tokens = &b.Tokens
+	return m0
+}
+
+// Request message for introspecting a token.
+type IntrospectTokenRequest struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Token       *string                `protobuf:"bytes,1,opt,name=token"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *IntrospectTokenRequest) Reset() {
+	*x = IntrospectTokenRequest{}
+	mi := &file_authapp_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IntrospectTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IntrospectTokenRequest) ProtoMessage() {}
+
+func (x *IntrospectTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *IntrospectTokenRequest) GetToken() string {
+	if x != nil {
+		if x.xxx_hidden_Token != nil {
+			return *x.xxx_hidden_Token
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *IntrospectTokenRequest) SetToken(v string) {
+	x.xxx_hidden_Token = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 1)
+}
+
+func (x *IntrospectTokenRequest) HasToken() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *IntrospectTokenRequest) ClearToken() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Token = nil
+}
+
+type IntrospectTokenRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Token *string
+}
+
+func (b0 IntrospectTokenRequest_builder) Build() *IntrospectTokenRequest {
+	m0 := &IntrospectTokenRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Token != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 1)
+		x.xxx_hidden_Token = b.Token
+	}
+	return m0
+}
+
+// EndpointUsage reports the requests counted against an endpoint's rate
+// limit within its current window.
+type EndpointUsage struct {
+	state                  protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Endpoint    *string                `protobuf:"bytes,1,opt,name=endpoint"`
+	xxx_hidden_Limit       *RateLimit             `protobuf:"bytes,2,opt,name=limit"`
+	xxx_hidden_Used        int64                  `protobuf:"varint,3,opt,name=used"`
+	xxx_hidden_Reset_      int64                  `protobuf:"varint,4,opt,name=reset"`
+	XXX_raceDetectHookData protoimpl.RaceDetectHookData
+	XXX_presence           [1]uint32
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *EndpointUsage) Reset() {
+	*x = EndpointUsage{}
+	mi := &file_authapp_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EndpointUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EndpointUsage) ProtoMessage() {}
+
+func (x *EndpointUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *EndpointUsage) GetEndpoint() string {
+	if x != nil {
+		if x.xxx_hidden_Endpoint != nil {
+			return *x.xxx_hidden_Endpoint
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *EndpointUsage) GetLimit() *RateLimit {
+	if x != nil {
+		return x.xxx_hidden_Limit
+	}
+	return nil
+}
+
+func (x *EndpointUsage) GetUsed() int64 {
+	if x != nil {
+		return x.xxx_hidden_Used
+	}
+	return 0
+}
+
+func (x *EndpointUsage) GetReset() int64 {
+	if x != nil {
+		return x.xxx_hidden_Reset_
+	}
+	return 0
+}
+
+func (x *EndpointUsage) SetEndpoint(v string) {
+	x.xxx_hidden_Endpoint = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 4)
+}
+
+func (x *EndpointUsage) SetLimit(v *RateLimit) {
+	x.xxx_hidden_Limit = v
+}
+
+func (x *EndpointUsage) SetUsed(v int64) {
+	x.xxx_hidden_Used = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 4)
+}
+
+func (x *EndpointUsage) SetReset(v int64) {
+	x.xxx_hidden_Reset_ = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 3, 4)
+}
+
+func (x *EndpointUsage) HasEndpoint() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *EndpointUsage) HasLimit() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Limit != nil
+}
+
+func (x *EndpointUsage) HasUsed() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *EndpointUsage) HasReset() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 3)
+}
+
+func (x *EndpointUsage) ClearEndpoint() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Endpoint = nil
+}
+
+func (x *EndpointUsage) ClearLimit() {
+	x.xxx_hidden_Limit = nil
+}
+
+func (x *EndpointUsage) ClearUsed() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Used = 0
+}
+
+func (x *EndpointUsage) ClearReset() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 3)
+	x.xxx_hidden_Reset_ = 0
+}
+
+type EndpointUsage_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Endpoint *string
+	Limit    *RateLimit
+	Used     *int64
+	Reset    *int64
+}
+
+func (b0 EndpointUsage_builder) Build() *EndpointUsage {
+	m0 := &EndpointUsage{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Endpoint != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 4)
+		x.xxx_hidden_Endpoint = b.Endpoint
+	}
+	x.xxx_hidden_Limit = b.Limit
+	if b.Used != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 4)
+		x.xxx_hidden_Used = *b.Used
+	}
+	if b.Reset != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 3, 4)
+		x.xxx_hidden_Reset_ = *b.Reset
+	}
+	return m0
+}
+
+// Response message for introspecting a token.
+type IntrospectTokenResponse struct {
+	state                   protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Active       bool                   `protobuf:"varint,1,opt,name=active"`
+	xxx_hidden_Token        *TokenInfo             `protobuf:"bytes,2,opt,name=token"`
+	xxx_hidden_Priority     *string                `protobuf:"bytes,3,opt,name=priority"`
+	xxx_hidden_Endpoints    *[]*EndpointUsage      `protobuf:"bytes,4,rep,name=endpoints"`
+	xxx_hidden_TokenBudgets *[]*TokenQuota         `protobuf:"bytes,5,rep,name=token_budgets,json=tokenBudgets"`
+	xxx_hidden_Models       []string               `protobuf:"bytes,6,rep,name=models"`
+	XXX_raceDetectHookData  protoimpl.RaceDetectHookData
+	XXX_presence            [1]uint32
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *IntrospectTokenResponse) Reset() {
+	*x = IntrospectTokenResponse{}
+	mi := &file_authapp_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IntrospectTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IntrospectTokenResponse) ProtoMessage() {}
+
+func (x *IntrospectTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *IntrospectTokenResponse) GetActive() bool {
+	if x != nil {
+		return x.xxx_hidden_Active
+	}
+	return false
+}
+
+func (x *IntrospectTokenResponse) GetToken() *TokenInfo {
+	if x != nil {
+		return x.xxx_hidden_Token
+	}
+	return nil
+}
+
+func (x *IntrospectTokenResponse) GetPriority() string {
+	if x != nil {
+		if x.xxx_hidden_Priority != nil {
+			return *x.xxx_hidden_Priority
+		}
+		return ""
+	}
+	return ""
+}
+
+func (x *IntrospectTokenResponse) GetEndpoints() []*EndpointUsage {
+	if x != nil {
+		if x.xxx_hidden_Endpoints != nil {
+			return *x.xxx_hidden_Endpoints
+		}
+	}
+	return nil
+}
+
+func (x *IntrospectTokenResponse) GetTokenBudgets() []*TokenQuota {
+	if x != nil {
+		if x.xxx_hidden_TokenBudgets != nil {
+			return *x.xxx_hidden_TokenBudgets
+		}
+	}
+	return nil
+}
+
+func (x *IntrospectTokenResponse) GetModels() []string {
+	if x != nil {
+		return x.xxx_hidden_Models
+	}
+	return nil
+}
+
+func (x *IntrospectTokenResponse) SetActive(v bool) {
+	x.xxx_hidden_Active = v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 0, 6)
+}
+
+func (x *IntrospectTokenResponse) SetToken(v *TokenInfo) {
+	x.xxx_hidden_Token = v
+}
+
+func (x *IntrospectTokenResponse) SetPriority(v string) {
+	x.xxx_hidden_Priority = &v
+	protoimpl.X.SetPresent(&(x.XXX_presence[0]), 2, 6)
+}
+
+func (x *IntrospectTokenResponse) SetEndpoints(v []*EndpointUsage) {
+	x.xxx_hidden_Endpoints = &v
+}
+
+func (x *IntrospectTokenResponse) SetTokenBudgets(v []*TokenQuota) {
+	x.xxx_hidden_TokenBudgets = &v
+}
+
+func (x *IntrospectTokenResponse) SetModels(v []string) {
+	x.xxx_hidden_Models = v
+}
+
+func (x *IntrospectTokenResponse) HasActive() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 0)
+}
+
+func (x *IntrospectTokenResponse) HasToken() bool {
+	if x == nil {
+		return false
+	}
+	return x.xxx_hidden_Token != nil
+}
+
+func (x *IntrospectTokenResponse) HasPriority() bool {
+	if x == nil {
+		return false
+	}
+	return protoimpl.X.Present(&(x.XXX_presence[0]), 2)
+}
+
+func (x *IntrospectTokenResponse) ClearActive() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 0)
+	x.xxx_hidden_Active = false
+}
+
+func (x *IntrospectTokenResponse) ClearToken() {
+	x.xxx_hidden_Token = nil
+}
+
+func (x *IntrospectTokenResponse) ClearPriority() {
+	protoimpl.X.ClearPresent(&(x.XXX_presence[0]), 2)
+	x.xxx_hidden_Priority = nil
+}
+
+type IntrospectTokenResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Active       *bool
+	Token        *TokenInfo
+	Priority     *string
+	Endpoints    []*EndpointUsage
+	TokenBudgets []*TokenQuota
+	Models       []string
+}
+
+func (b0 IntrospectTokenResponse_builder) Build() *IntrospectTokenResponse {
+	m0 := &IntrospectTokenResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	if b.Active != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 0, 6)
+		x.xxx_hidden_Active = *b.Active
+	}
+	x.xxx_hidden_Token = b.Token
+	if b.Priority != nil {
+		protoimpl.X.SetPresentNonAtomic(&(x.XXX_presence[0]), 2, 6)
+		x.xxx_hidden_Priority = b.Priority
+	}
+	x.xxx_hidden_Endpoints = &b.Endpoints
+	x.xxx_hidden_TokenBudgets = &b.TokenBudgets
+	x.xxx_hidden_Models = b.Models
+	return m0
+}
+
+// Request message for filtering models.
+type AuthorizedModelsRequest struct {
+	state             protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Models []string               `protobuf:"bytes,1,rep,name=models"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *AuthorizedModelsRequest) Reset() {
+	*x = AuthorizedModelsRequest{}
+	mi := &file_authapp_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthorizedModelsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthorizedModelsRequest) ProtoMessage() {}
+
+func (x *AuthorizedModelsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *AuthorizedModelsRequest) GetModels() []string {
+	if x != nil {
+		return x.xxx_hidden_Models
+	}
+	return nil
+}
+
+func (x *AuthorizedModelsRequest) SetModels(v []string) {
+	x.xxx_hidden_Models = v
+}
+
+type AuthorizedModelsRequest_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Models []string
+}
+
+func (b0 AuthorizedModelsRequest_builder) Build() *AuthorizedModelsRequest {
+	m0 := &AuthorizedModelsRequest{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Models = b.Models
+	return m0
+}
+
+// Response message for filtering models.
+type AuthorizedModelsResponse struct {
+	state             protoimpl.MessageState `protogen:"opaque.v1"`
+	xxx_hidden_Models []string               `protobuf:"bytes,1,rep,name=models"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *AuthorizedModelsResponse) Reset() {
+	*x = AuthorizedModelsResponse{}
+	mi := &file_authapp_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthorizedModelsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthorizedModelsResponse) ProtoMessage() {}
+
+func (x *AuthorizedModelsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_authapp_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+func (x *AuthorizedModelsResponse) GetModels() []string {
+	if x != nil {
+		return x.xxx_hidden_Models
+	}
+	return nil
+}
+
+func (x *AuthorizedModelsResponse) SetModels(v []string) {
+	x.xxx_hidden_Models = v
+}
+
+type AuthorizedModelsResponse_builder struct {
+	_ [0]func() // Prevents comparability and use of unkeyed literals for the builder.
+
+	Models []string
+}
+
+func (b0 AuthorizedModelsResponse_builder) Build() *AuthorizedModelsResponse {
+	m0 := &AuthorizedModelsResponse{}
+	b, x := &b0, m0
+	_, _ = b, x
+	x.xxx_hidden_Models = b.Models
+	return m0
+}
+
+var File_authapp_proto protoreflect.FileDescriptor
+
+const file_authapp_proto_rawDesc = "" +
+	"\n" +
+	"\rauthapp.proto\x12\aauthapp\"9\n" +
+	"\tRateLimit\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06window\x18\x02 \x01(\tR\x06window\"\x84\x03\n" +
+	"\x12CreateTokenRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1b\n" +
+	"\tuser_name\x18\x02 \x01(\tR\buserName\x12\x14\n" +
+	"\x05admin\x18\x03 \x01(\bR\x05admin\x12\x1a\n" +
+	"\bduration\x18\x04 \x01(\tR\bduration\x12H\n" +
+	"\tendpoints\x18\x05 \x03(\v2*.authapp.CreateTokenRequest.EndpointsEntryR\tendpoints\x12\x1a\n" +
+	"\bpriority\x18\x06 \x01(\tR\bpriority\x129\n" +
+	"\rtoken_budgets\x18\a \x03(\v2\x14.authapp.TokenBudgetR\ftokenBudgets\x12\x16\n" +
+	"\x06models\x18\b \x03(\tR\x06models\x1aP\n" +
+	"\x0eEndpointsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12(\n" +
+	"\x05value\x18\x02 \x01(\v2\x12.authapp.RateLimitR\x05value:\x028\x01\"i\n" +
+	"\vTokenBudget\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x12\x14\n" +
+	"\x05input\x18\x02 \x01(\x03R\x05input\x12\x16\n" +
+	"\x06output\x18\x03 \x01(\x03R\x06output\x12\x16\n" +
+	"\x06window\x18\x04 \x01(\tR\x06window\"+\n" +
+	"\x13CreateTokenResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"s\n" +
+	"\x13AuthenticateRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x14\n" +
+	"\x05admin\x18\x02 \x01(\bR\x05admin\x12\x1a\n" +
+	"\bendpoint\x18\x03 \x01(\tR\bendpoint\x12\x14\n" +
+	"\x05model\x18\x04 \x01(\tR\x05model\"\xce\x01\n" +
+	"\x14AuthenticateResponse\x12\x18\n" +
+	"\asubject\x18\x01 \x01(\tR\asubject\x12\x1a\n" +
+	"\bpriority\x18\x02 \x01(\tR\bpriority\x12#\n" +
+	"\rtoken_budgets\x18\x03 \x01(\bR\ftokenBudgets\x12!\n" +
+	"\fmodel_scoped\x18\x04 \x01(\bR\vmodelScoped\x12\x19\n" +
+	"\btoken_id\x18\x05 \x01(\tR\atokenId\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x06 \x01(\x03R\texpiresAt\"\x11\n" +
+	"\x0fListKeysRequest\"4\n" +
+	"\x10ListKeysResponse\x12 \n" +
+	"\x04keys\x18\x01 \x03(\v2\f.authapp.KeyR\x04keys\"/\n" +
+	"\x03Key\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\tR\acreated\"\x0f\n" +
+	"\rAddKeyRequest\"\x10\n" +
+	"\x0eAddKeyResponse\")\n" +
+	"\x10RemoveKeyRequest\x12\x15\n" +
+	"\x06key_id\x18\x01 \x01(\tR\x05keyId\"\x13\n" +
+	"\x11RemoveKeyResponse\"*\n" +
+	"\x12CheckTokensRequest\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\"Y\n" +
+	"\x13ChargeTokensRequest\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x12\x14\n" +
+	"\x05input\x18\x02 \x01(\x03R\x05input\x12\x16\n" +
+	"\x06output\x18\x03 \x01(\x03R\x06output\".\n" +
+	"\x12TokenQuotasRequest\x12\x18\n" +
+	"\asubject\x18\x01 \x01(\tR\asubject\"\xd4\x01\n" +
+	"\n" +
+	"TokenQuota\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x12\x16\n" +
+	"\x06window\x18\x02 \x01(\tR\x06window\x12\x1f\n" +
+	"\vinput_limit\x18\x03 \x01(\x03R\n" +
+	"inputLimit\x12\x1d\n" +
+	"\n" +
+	"input_used\x18\x04 \x01(\x03R\tinputUsed\x12!\n" +
+	"\foutput_limit\x18\x05 \x01(\x03R\voutputLimit\x12\x1f\n" +
+	"\voutput_used\x18\x06 \x01(\x03R\n" +
+	"outputUsed\x12\x14\n" +
+	"\x05reset\x18\a \x01(\x03R\x05reset\"^\n" +
+	"\x13TokenQuotasResponse\x12+\n" +
+	"\x06quotas\x18\x01 \x03(\v2\x13.authapp.TokenQuotaR\x06quotas\x12\x1a\n" +
+	"\bexceeded\x18\x02 \x01(\bR\bexceeded\"\xa6\x01\n" +
+	"\tTokenInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\asubject\x18\x02 \x01(\tR\asubject\x12\x14\n" +
+	"\x05admin\x18\x03 \x01(\bR\x05admin\x12\x1b\n" +
+	"\tissued_at\x18\x04 \x01(\x03R\bissuedAt\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\x03R\texpiresAt\x12\x1d\n" +
+	"\n" +
+	"revoked_at\x18\x06 \x01(\x03R\trevokedAt\":\n" +
+	"\x12RevokeTokenRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\"?\n" +
+	"\x13RevokeTokenResponse\x12(\n" +
+	"\x05token\x18\x01 \x01(\v2\x12.authapp.TokenInfoR\x05token\"\x13\n" +
+	"\x11ListTokensRequest\"@\n" +
+	"\x12ListTokensResponse\x12*\n" +
+	"\x06tokens\x18\x01 \x03(\v2\x12.authapp.TokenInfoR\x06tokens\".\n" +
+	"\x16IntrospectTokenRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"\x7f\n" +
+	"\rEndpointUsage\x12\x1a\n" +
+	"\bendpoint\x18\x01 \x01(\tR\bendpoint\x12(\n" +
+	"\x05limit\x18\x02 \x01(\v2\x12.authapp.RateLimitR\x05limit\x12\x12\n" +
+	"\x04used\x18\x03 \x01(\x03R\x04used\x12\x14\n" +
+	"\x05reset\x18\x04 \x01(\x03R\x05reset\"\xff\x01\n" +
+	"\x17IntrospectTokenResponse\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12(\n" +
+	"\x05token\x18\x02 \x01(\v2\x12.authapp.TokenInfoR\x05token\x12\x1a\n" +
+	"\bpriority\x18\x03 \x01(\tR\bpriority\x124\n" +
+	"\tendpoints\x18\x04 \x03(\v2\x16.authapp.EndpointUsageR\tendpoints\x128\n" +
+	"\rtoken_budgets\x18\x05 \x03(\v2\x13.authapp.TokenQuotaR\ftokenBudgets\x12\x16\n" +
+	"\x06models\x18\x06 \x03(\tR\x06models\"1\n" +
+	"\x17AuthorizedModelsRequest\x12\x16\n" +
+	"\x06models\x18\x01 \x03(\tR\x06models\"2\n" +
+	"\x18AuthorizedModelsResponse\x12\x16\n" +
+	"\x06models\x18\x01 \x03(\tR\x06models2\xfd\x06\n" +
+	"\x04Auth\x12H\n" +
+	"\vCreateToken\x12\x1b.authapp.CreateTokenRequest\x1a\x1c.authapp.CreateTokenResponse\x12K\n" +
+	"\fAuthenticate\x12\x1c.authapp.AuthenticateRequest\x1a\x1d.authapp.AuthenticateResponse\x12?\n" +
+	"\bListKeys\x12\x18.authapp.ListKeysRequest\x1a\x19.authapp.ListKeysResponse\x129\n" +
+	"\x06AddKey\x12\x16.authapp.AddKeyRequest\x1a\x17.authapp.AddKeyResponse\x12B\n" +
+	"\tRemoveKey\x12\x19.authapp.RemoveKeyRequest\x1a\x1a.authapp.RemoveKeyResponse\x12H\n" +
+	"\vCheckTokens\x12\x1b.authapp.CheckTokensRequest\x1a\x1c.authapp.TokenQuotasResponse\x12J\n" +
+	"\fChargeTokens\x12\x1c.authapp.ChargeTokensRequest\x1a\x1c.authapp.TokenQuotasResponse\x12H\n" +
+	"\vTokenQuotas\x12\x1b.authapp.TokenQuotasRequest\x1a\x1c.authapp.TokenQuotasResponse\x12H\n" +
+	"\vRevokeToken\x12\x1b.authapp.RevokeTokenRequest\x1a\x1c.authapp.RevokeTokenResponse\x12E\n" +
+	"\n" +
+	"ListTokens\x12\x1a.authapp.ListTokensRequest\x1a\x1b.authapp.ListTokensResponse\x12T\n" +
+	"\x0fIntrospectToken\x12\x1f.authapp.IntrospectTokenRequest\x1a .authapp.IntrospectTokenResponse\x12W\n" +
+	"\x10AuthorizedModels\x12 .authapp.AuthorizedModelsRequest\x1a!.authapp.AuthorizedModelsResponseB:Z8github.com/ardanlabs/kronk/cmd/server/app/domain/authappb\beditionsp\xe9\a"
 
-var file_authapp_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_authapp_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
 var file_authapp_proto_goTypes = []any{
-	(*RateLimit)(nil),            // 0: authapp.RateLimit
-	(*CreateTokenRequest)(nil),   // 1: authapp.CreateTokenRequest
-	(*CreateTokenResponse)(nil),  // 2: authapp.CreateTokenResponse
-	(*AuthenticateRequest)(nil),  // 3: authapp.AuthenticateRequest
-	(*AuthenticateResponse)(nil), // 4: authapp.AuthenticateResponse
-	(*ListKeysRequest)(nil),      // 5: authapp.ListKeysRequest
-	(*ListKeysResponse)(nil),     // 6: authapp.ListKeysResponse
-	(*Key)(nil),                  // 7: authapp.Key
-	(*AddKeyRequest)(nil),        // 8: authapp.AddKeyRequest
-	(*AddKeyResponse)(nil),       // 9: authapp.AddKeyResponse
-	(*RemoveKeyRequest)(nil),     // 10: authapp.RemoveKeyRequest
-	(*RemoveKeyResponse)(nil),    // 11: authapp.RemoveKeyResponse
-	nil,                          // 12: authapp.CreateTokenRequest.EndpointsEntry
+	(*RateLimit)(nil),                // 0: authapp.RateLimit
+	(*CreateTokenRequest)(nil),       // 1: authapp.CreateTokenRequest
+	(*TokenBudget)(nil),              // 2: authapp.TokenBudget
+	(*CreateTokenResponse)(nil),      // 3: authapp.CreateTokenResponse
+	(*AuthenticateRequest)(nil),      // 4: authapp.AuthenticateRequest
+	(*AuthenticateResponse)(nil),     // 5: authapp.AuthenticateResponse
+	(*ListKeysRequest)(nil),          // 6: authapp.ListKeysRequest
+	(*ListKeysResponse)(nil),         // 7: authapp.ListKeysResponse
+	(*Key)(nil),                      // 8: authapp.Key
+	(*AddKeyRequest)(nil),            // 9: authapp.AddKeyRequest
+	(*AddKeyResponse)(nil),           // 10: authapp.AddKeyResponse
+	(*RemoveKeyRequest)(nil),         // 11: authapp.RemoveKeyRequest
+	(*RemoveKeyResponse)(nil),        // 12: authapp.RemoveKeyResponse
+	(*CheckTokensRequest)(nil),       // 13: authapp.CheckTokensRequest
+	(*ChargeTokensRequest)(nil),      // 14: authapp.ChargeTokensRequest
+	(*TokenQuotasRequest)(nil),       // 15: authapp.TokenQuotasRequest
+	(*TokenQuota)(nil),               // 16: authapp.TokenQuota
+	(*TokenQuotasResponse)(nil),      // 17: authapp.TokenQuotasResponse
+	(*TokenInfo)(nil),                // 18: authapp.TokenInfo
+	(*RevokeTokenRequest)(nil),       // 19: authapp.RevokeTokenRequest
+	(*RevokeTokenResponse)(nil),      // 20: authapp.RevokeTokenResponse
+	(*ListTokensRequest)(nil),        // 21: authapp.ListTokensRequest
+	(*ListTokensResponse)(nil),       // 22: authapp.ListTokensResponse
+	(*IntrospectTokenRequest)(nil),   // 23: authapp.IntrospectTokenRequest
+	(*EndpointUsage)(nil),            // 24: authapp.EndpointUsage
+	(*IntrospectTokenResponse)(nil),  // 25: authapp.IntrospectTokenResponse
+	(*AuthorizedModelsRequest)(nil),  // 26: authapp.AuthorizedModelsRequest
+	(*AuthorizedModelsResponse)(nil), // 27: authapp.AuthorizedModelsResponse
+	nil,                              // 28: authapp.CreateTokenRequest.EndpointsEntry
 }
 var file_authapp_proto_depIdxs = []int32{
-	12, // 0: authapp.CreateTokenRequest.endpoints:type_name -> authapp.CreateTokenRequest.EndpointsEntry
-	7,  // 1: authapp.ListKeysResponse.keys:type_name -> authapp.Key
-	0,  // 2: authapp.CreateTokenRequest.EndpointsEntry.value:type_name -> authapp.RateLimit
-	1,  // 3: authapp.Auth.CreateToken:input_type -> authapp.CreateTokenRequest
-	3,  // 4: authapp.Auth.Authenticate:input_type -> authapp.AuthenticateRequest
-	5,  // 5: authapp.Auth.ListKeys:input_type -> authapp.ListKeysRequest
-	8,  // 6: authapp.Auth.AddKey:input_type -> authapp.AddKeyRequest
-	10, // 7: authapp.Auth.RemoveKey:input_type -> authapp.RemoveKeyRequest
-	2,  // 8: authapp.Auth.CreateToken:output_type -> authapp.CreateTokenResponse
-	4,  // 9: authapp.Auth.Authenticate:output_type -> authapp.AuthenticateResponse
-	6,  // 10: authapp.Auth.ListKeys:output_type -> authapp.ListKeysResponse
-	9,  // 11: authapp.Auth.AddKey:output_type -> authapp.AddKeyResponse
-	11, // 12: authapp.Auth.RemoveKey:output_type -> authapp.RemoveKeyResponse
-	8,  // [8:13] is the sub-list for method output_type
-	3,  // [3:8] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	28, // 0: authapp.CreateTokenRequest.endpoints:type_name -> authapp.CreateTokenRequest.EndpointsEntry
+	2,  // 1: authapp.CreateTokenRequest.token_budgets:type_name -> authapp.TokenBudget
+	8,  // 2: authapp.ListKeysResponse.keys:type_name -> authapp.Key
+	16, // 3: authapp.TokenQuotasResponse.quotas:type_name -> authapp.TokenQuota
+	18, // 4: authapp.RevokeTokenResponse.token:type_name -> authapp.TokenInfo
+	18, // 5: authapp.ListTokensResponse.tokens:type_name -> authapp.TokenInfo
+	0,  // 6: authapp.EndpointUsage.limit:type_name -> authapp.RateLimit
+	18, // 7: authapp.IntrospectTokenResponse.token:type_name -> authapp.TokenInfo
+	24, // 8: authapp.IntrospectTokenResponse.endpoints:type_name -> authapp.EndpointUsage
+	16, // 9: authapp.IntrospectTokenResponse.token_budgets:type_name -> authapp.TokenQuota
+	0,  // 10: authapp.CreateTokenRequest.EndpointsEntry.value:type_name -> authapp.RateLimit
+	1,  // 11: authapp.Auth.CreateToken:input_type -> authapp.CreateTokenRequest
+	4,  // 12: authapp.Auth.Authenticate:input_type -> authapp.AuthenticateRequest
+	6,  // 13: authapp.Auth.ListKeys:input_type -> authapp.ListKeysRequest
+	9,  // 14: authapp.Auth.AddKey:input_type -> authapp.AddKeyRequest
+	11, // 15: authapp.Auth.RemoveKey:input_type -> authapp.RemoveKeyRequest
+	13, // 16: authapp.Auth.CheckTokens:input_type -> authapp.CheckTokensRequest
+	14, // 17: authapp.Auth.ChargeTokens:input_type -> authapp.ChargeTokensRequest
+	15, // 18: authapp.Auth.TokenQuotas:input_type -> authapp.TokenQuotasRequest
+	19, // 19: authapp.Auth.RevokeToken:input_type -> authapp.RevokeTokenRequest
+	21, // 20: authapp.Auth.ListTokens:input_type -> authapp.ListTokensRequest
+	23, // 21: authapp.Auth.IntrospectToken:input_type -> authapp.IntrospectTokenRequest
+	26, // 22: authapp.Auth.AuthorizedModels:input_type -> authapp.AuthorizedModelsRequest
+	3,  // 23: authapp.Auth.CreateToken:output_type -> authapp.CreateTokenResponse
+	5,  // 24: authapp.Auth.Authenticate:output_type -> authapp.AuthenticateResponse
+	7,  // 25: authapp.Auth.ListKeys:output_type -> authapp.ListKeysResponse
+	10, // 26: authapp.Auth.AddKey:output_type -> authapp.AddKeyResponse
+	12, // 27: authapp.Auth.RemoveKey:output_type -> authapp.RemoveKeyResponse
+	17, // 28: authapp.Auth.CheckTokens:output_type -> authapp.TokenQuotasResponse
+	17, // 29: authapp.Auth.ChargeTokens:output_type -> authapp.TokenQuotasResponse
+	17, // 30: authapp.Auth.TokenQuotas:output_type -> authapp.TokenQuotasResponse
+	20, // 31: authapp.Auth.RevokeToken:output_type -> authapp.RevokeTokenResponse
+	22, // 32: authapp.Auth.ListTokens:output_type -> authapp.ListTokensResponse
+	25, // 33: authapp.Auth.IntrospectToken:output_type -> authapp.IntrospectTokenResponse
+	27, // 34: authapp.Auth.AuthorizedModels:output_type -> authapp.AuthorizedModelsResponse
+	23, // [23:35] is the sub-list for method output_type
+	11, // [11:23] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_authapp_proto_init() }
@@ -1136,7 +3388,7 @@ func file_authapp_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_authapp_proto_rawDesc), len(file_authapp_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   13,
+			NumMessages:   29,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
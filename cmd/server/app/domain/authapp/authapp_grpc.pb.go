@@ -19,11 +19,18 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Auth_CreateToken_FullMethodName  = "/authapp.Auth/CreateToken"
-	Auth_Authenticate_FullMethodName = "/authapp.Auth/Authenticate"
-	Auth_ListKeys_FullMethodName     = "/authapp.Auth/ListKeys"
-	Auth_AddKey_FullMethodName       = "/authapp.Auth/AddKey"
-	Auth_RemoveKey_FullMethodName    = "/authapp.Auth/RemoveKey"
+	Auth_CreateToken_FullMethodName      = "/authapp.Auth/CreateToken"
+	Auth_Authenticate_FullMethodName     = "/authapp.Auth/Authenticate"
+	Auth_ListKeys_FullMethodName         = "/authapp.Auth/ListKeys"
+	Auth_AddKey_FullMethodName           = "/authapp.Auth/AddKey"
+	Auth_RemoveKey_FullMethodName        = "/authapp.Auth/RemoveKey"
+	Auth_CheckTokens_FullMethodName      = "/authapp.Auth/CheckTokens"
+	Auth_ChargeTokens_FullMethodName     = "/authapp.Auth/ChargeTokens"
+	Auth_TokenQuotas_FullMethodName      = "/authapp.Auth/TokenQuotas"
+	Auth_RevokeToken_FullMethodName      = "/authapp.Auth/RevokeToken"
+	Auth_ListTokens_FullMethodName       = "/authapp.Auth/ListTokens"
+	Auth_IntrospectToken_FullMethodName  = "/authapp.Auth/IntrospectToken"
+	Auth_AuthorizedModels_FullMethodName = "/authapp.Auth/AuthorizedModels"
 )
 
 // AuthClient is the client API for Auth service.
@@ -42,6 +49,20 @@ type AuthClient interface {
 	AddKey(ctx context.Context, in *AddKeyRequest, opts ...grpc.CallOption) (*AddKeyResponse, error)
 	// Remove a private key by ID.
 	RemoveKey(ctx context.Context, in *RemoveKeyRequest, opts ...grpc.CallOption) (*RemoveKeyResponse, error)
+	// Check the token budgets that apply to a request for a model.
+	CheckTokens(ctx context.Context, in *CheckTokensRequest, opts ...grpc.CallOption) (*TokenQuotasResponse, error)
+	// Charge the tokens a completed request used to its token budgets.
+	ChargeTokens(ctx context.Context, in *ChargeTokensRequest, opts ...grpc.CallOption) (*TokenQuotasResponse, error)
+	// List the token budget use of a subject.
+	TokenQuotas(ctx context.Context, in *TokenQuotasRequest, opts ...grpc.CallOption) (*TokenQuotasResponse, error)
+	// Revoke a single token by ID or by the token itself.
+	RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...grpc.CallOption) (*RevokeTokenResponse, error)
+	// List the issued and revoked tokens that have not expired.
+	ListTokens(ctx context.Context, in *ListTokensRequest, opts ...grpc.CallOption) (*ListTokensResponse, error)
+	// Report a token's claims, expiry and remaining rate budget.
+	IntrospectToken(ctx context.Context, in *IntrospectTokenRequest, opts ...grpc.CallOption) (*IntrospectTokenResponse, error)
+	// Filter a list of models to those a token may use.
+	AuthorizedModels(ctx context.Context, in *AuthorizedModelsRequest, opts ...grpc.CallOption) (*AuthorizedModelsResponse, error)
 }
 
 type authClient struct {
@@ -102,6 +123,76 @@ func (c *authClient) RemoveKey(ctx context.Context, in *RemoveKeyRequest, opts .
 	return out, nil
 }
 
+func (c *authClient) CheckTokens(ctx context.Context, in *CheckTokensRequest, opts ...grpc.CallOption) (*TokenQuotasResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TokenQuotasResponse)
+	err := c.cc.Invoke(ctx, Auth_CheckTokens_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) ChargeTokens(ctx context.Context, in *ChargeTokensRequest, opts ...grpc.CallOption) (*TokenQuotasResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TokenQuotasResponse)
+	err := c.cc.Invoke(ctx, Auth_ChargeTokens_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) TokenQuotas(ctx context.Context, in *TokenQuotasRequest, opts ...grpc.CallOption) (*TokenQuotasResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TokenQuotasResponse)
+	err := c.cc.Invoke(ctx, Auth_TokenQuotas_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...grpc.CallOption) (*RevokeTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeTokenResponse)
+	err := c.cc.Invoke(ctx, Auth_RevokeToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) ListTokens(ctx context.Context, in *ListTokensRequest, opts ...grpc.CallOption) (*ListTokensResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTokensResponse)
+	err := c.cc.Invoke(ctx, Auth_ListTokens_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) IntrospectToken(ctx context.Context, in *IntrospectTokenRequest, opts ...grpc.CallOption) (*IntrospectTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IntrospectTokenResponse)
+	err := c.cc.Invoke(ctx, Auth_IntrospectToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authClient) AuthorizedModels(ctx context.Context, in *AuthorizedModelsRequest, opts ...grpc.CallOption) (*AuthorizedModelsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AuthorizedModelsResponse)
+	err := c.cc.Invoke(ctx, Auth_AuthorizedModels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AuthServer is the server API for Auth service.
 // All implementations must embed UnimplementedAuthServer
 // for forward compatibility.
@@ -118,6 +209,20 @@ type AuthServer interface {
 	AddKey(context.Context, *AddKeyRequest) (*AddKeyResponse, error)
 	// Remove a private key by ID.
 	RemoveKey(context.Context, *RemoveKeyRequest) (*RemoveKeyResponse, error)
+	// Check the token budgets that apply to a request for a model.
+	CheckTokens(context.Context, *CheckTokensRequest) (*TokenQuotasResponse, error)
+	// Charge the tokens a completed request used to its token budgets.
+	ChargeTokens(context.Context, *ChargeTokensRequest) (*TokenQuotasResponse, error)
+	// List the token budget use of a subject.
+	TokenQuotas(context.Context, *TokenQuotasRequest) (*TokenQuotasResponse, error)
+	// Revoke a single token by ID or by the token itself.
+	RevokeToken(context.Context, *RevokeTokenRequest) (*RevokeTokenResponse, error)
+	// List the issued and revoked tokens that have not expired.
+	ListTokens(context.Context, *ListTokensRequest) (*ListTokensResponse, error)
+	// Report a token's claims, expiry and remaining rate budget.
+	IntrospectToken(context.Context, *IntrospectTokenRequest) (*IntrospectTokenResponse, error)
+	// Filter a list of models to those a token may use.
+	AuthorizedModels(context.Context, *AuthorizedModelsRequest) (*AuthorizedModelsResponse, error)
 	mustEmbedUnimplementedAuthServer()
 }
 
@@ -143,6 +248,27 @@ func (UnimplementedAuthServer) AddKey(context.Context, *AddKeyRequest) (*AddKeyR
 func (UnimplementedAuthServer) RemoveKey(context.Context, *RemoveKeyRequest) (*RemoveKeyResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method RemoveKey not implemented")
 }
+func (UnimplementedAuthServer) CheckTokens(context.Context, *CheckTokensRequest) (*TokenQuotasResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CheckTokens not implemented")
+}
+func (UnimplementedAuthServer) ChargeTokens(context.Context, *ChargeTokensRequest) (*TokenQuotasResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ChargeTokens not implemented")
+}
+func (UnimplementedAuthServer) TokenQuotas(context.Context, *TokenQuotasRequest) (*TokenQuotasResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method TokenQuotas not implemented")
+}
+func (UnimplementedAuthServer) RevokeToken(context.Context, *RevokeTokenRequest) (*RevokeTokenResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RevokeToken not implemented")
+}
+func (UnimplementedAuthServer) ListTokens(context.Context, *ListTokensRequest) (*ListTokensResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTokens not implemented")
+}
+func (UnimplementedAuthServer) IntrospectToken(context.Context, *IntrospectTokenRequest) (*IntrospectTokenResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method IntrospectToken not implemented")
+}
+func (UnimplementedAuthServer) AuthorizedModels(context.Context, *AuthorizedModelsRequest) (*AuthorizedModelsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AuthorizedModels not implemented")
+}
 func (UnimplementedAuthServer) mustEmbedUnimplementedAuthServer() {}
 func (UnimplementedAuthServer) testEmbeddedByValue()              {}
 
@@ -254,6 +380,132 @@ func _Auth_RemoveKey_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Auth_CheckTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).CheckTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Auth_CheckTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).CheckTokens(ctx, req.(*CheckTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_ChargeTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChargeTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).ChargeTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Auth_ChargeTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).ChargeTokens(ctx, req.(*ChargeTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_TokenQuotas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TokenQuotasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).TokenQuotas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Auth_TokenQuotas_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).TokenQuotas(ctx, req.(*TokenQuotasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_RevokeToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).RevokeToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Auth_RevokeToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).RevokeToken(ctx, req.(*RevokeTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_ListTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).ListTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Auth_ListTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).ListTokens(ctx, req.(*ListTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_IntrospectToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IntrospectTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).IntrospectToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Auth_IntrospectToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).IntrospectToken(ctx, req.(*IntrospectTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Auth_AuthorizedModels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthorizedModelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServer).AuthorizedModels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Auth_AuthorizedModels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServer).AuthorizedModels(ctx, req.(*AuthorizedModelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Auth_ServiceDesc is the grpc.ServiceDesc for Auth service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -281,6 +533,34 @@ var Auth_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RemoveKey",
 			Handler:    _Auth_RemoveKey_Handler,
 		},
+		{
+			MethodName: "CheckTokens",
+			Handler:    _Auth_CheckTokens_Handler,
+		},
+		{
+			MethodName: "ChargeTokens",
+			Handler:    _Auth_ChargeTokens_Handler,
+		},
+		{
+			MethodName: "TokenQuotas",
+			Handler:    _Auth_TokenQuotas_Handler,
+		},
+		{
+			MethodName: "RevokeToken",
+			Handler:    _Auth_RevokeToken_Handler,
+		},
+		{
+			MethodName: "ListTokens",
+			Handler:    _Auth_ListTokens_Handler,
+		},
+		{
+			MethodName: "IntrospectToken",
+			Handler:    _Auth_IntrospectToken_Handler,
+		},
+		{
+			MethodName: "AuthorizedModels",
+			Handler:    _Auth_AuthorizedModels_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "authapp.proto",
@@ -52,6 +52,61 @@ func TestCreateTokenRejectsInvalidRateWindow(t *testing.T) {
 	}
 }
 
+func TestCreateTokenRejectsInvalidModels(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	tests := []struct {
+		name   string
+		models []string
+	}{
+		{name: "empty", models: []string{""}},
+		{name: "unbalanced", models: []string{"Qwen3-[0-9"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := CreateTokenRequest_builder{
+				Duration: new("1h"),
+				Models:   tt.models,
+			}.Build()
+
+			app := newApp(Config{Log: log})
+			_, err := app.CreateToken(context.Background(), req)
+			if got, want := status.Code(err), codes.InvalidArgument; got != want {
+				t.Errorf("CreateToken() code = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestCreateTokenRejectsInvalidTokenBudget(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	tests := []struct {
+		name   string
+		budget *TokenBudget
+	}{
+		{name: "unlimited window", budget: TokenBudget_builder{Input: new(int64(1000)), Window: new("unlimited")}.Build()},
+		{name: "no limit", budget: TokenBudget_builder{Window: new("day")}.Build()},
+		{name: "negative", budget: TokenBudget_builder{Output: new(int64(-1)), Window: new("day")}.Build()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := CreateTokenRequest_builder{
+				Duration:     new("1h"),
+				TokenBudgets: []*TokenBudget{tt.budget},
+			}.Build()
+
+			app := newApp(Config{Log: log})
+			_, err := app.CreateToken(context.Background(), req)
+			if got, want := status.Code(err), codes.InvalidArgument; got != want {
+				t.Errorf("CreateToken() code = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
 func TestAuthenticationError(t *testing.T) {
 	tests := []struct {
 		name string
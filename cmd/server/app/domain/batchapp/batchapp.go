@@ -0,0 +1,195 @@
+// Package batchapp provides the batch api endpoints.
+package batchapp
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/batchqueue"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+)
+
+// endpointGrants maps the endpoints a batch can target to the token grant
+// needed to call them.
+var endpointGrants = map[string]string{
+	"/v1/chat/completions": "chat-completions",
+	"/v1/embeddings":       "embeddings",
+	"/v1/rerank":           "rerank",
+}
+
+func endpoints() []string {
+	return slices.Sorted(maps.Keys(endpointGrants))
+}
+
+type app struct {
+	log            *logger.Logger
+	files          *filestore.Store
+	queue          *batchqueue.Queue
+	endpointAccess map[string]web.MidFunc
+}
+
+func newApp(cfg Config) *app {
+	access := mid.NewAccess(cfg.AuthClient, cfg.AuthorizationMode, false)
+
+	endpointAccess := make(map[string]web.MidFunc)
+	for endpoint, grant := range endpointGrants {
+		endpointAccess[endpoint] = access.Inference(grant)
+	}
+
+	return &app{
+		log:            cfg.Log,
+		files:          cfg.Files,
+		queue:          cfg.Queue,
+		endpointAccess: endpointAccess,
+	}
+}
+
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	var req BatchRequest
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	// The lines run later as the caller without a token, so check now that
+	// the caller may use the endpoint and every model the lines name.
+	allowed := func(context.Context, *http.Request) web.Encoder { return nil }
+	if resp := a.endpointAccess[req.Endpoint](allowed)(ctx, r); resp != nil {
+		return resp
+	}
+
+	subject := mid.GetSubject(ctx)
+
+	f, content, err := a.files.Content(ctx, subject, req.InputFileID)
+	if err != nil {
+		if errors.Is(err, filestore.ErrNotFound) {
+			return errs.Errorf(errs.InvalidArgument, "input file %q not found", req.InputFileID)
+		}
+		return errs.New(errs.Internal, err)
+	}
+	defer content.Close()
+
+	if f.Purpose != "batch" {
+		return errs.Errorf(errs.InvalidArgument, "input file %q must be uploaded with purpose batch", req.InputFileID)
+	}
+
+	input := batchqueue.ParseInput(content, req.Endpoint)
+
+	for _, modelID := range input.Models {
+		if err := mid.AuthorizeModel(ctx, modelID); err != nil {
+			return err
+		}
+
+		if err := mid.CheckTokenBudget(ctx, modelID); err != nil {
+			return err
+		}
+	}
+
+	token := mid.GetToken(ctx)
+
+	b, err := a.queue.Create(ctx, batchqueue.NewBatch{
+		Subject:     subject,
+		Endpoint:    req.Endpoint,
+		InputFileID: req.InputFileID,
+		Metadata:    req.Metadata,
+		Token:       batchqueue.Token(token),
+		Input:       input,
+	})
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	a.log.Info(ctx, "batches", "status", b.Status, "id", b.ID, "endpoint", b.Endpoint, "requests", b.RequestCounts.Total)
+
+	return toBatch(b)
+}
+
+func (a *app) list(ctx context.Context, r *http.Request) web.Encoder {
+	q := r.URL.Query()
+
+	limit := 20
+	if v := q.Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 100 {
+			return errs.Errorf(errs.InvalidArgument, "limit must be between 1 and 100")
+		}
+	}
+
+	batches := a.queue.List(ctx, mid.GetSubject(ctx))
+
+	if after := q.Get("after"); after != "" {
+		at := slices.IndexFunc(batches, func(b batchqueue.Batch) bool {
+			return b.ID == after
+		})
+		if at == -1 {
+			return errs.Errorf(errs.InvalidArgument, "batch %q not found", after)
+		}
+		batches = batches[at+1:]
+	}
+
+	return pageBatches(batches, limit)
+}
+
+func (a *app) retrieve(ctx context.Context, r *http.Request) web.Encoder {
+	b, err := a.queue.QueryByID(ctx, mid.GetSubject(ctx), web.Param(r, "batch_id"))
+	if err != nil {
+		return toError(err)
+	}
+
+	return toBatch(b)
+}
+
+func (a *app) cancel(ctx context.Context, r *http.Request) web.Encoder {
+	b, err := a.queue.Cancel(ctx, mid.GetSubject(ctx), web.Param(r, "batch_id"))
+	if err != nil {
+		return toError(err)
+	}
+
+	a.log.Info(ctx, "batches", "status", b.Status, "id", b.ID)
+
+	return toBatch(b)
+}
+
+// =============================================================================
+
+func pageBatches(batches []batchqueue.Batch, limit int) BatchList {
+	list := BatchList{
+		Object: "list",
+		Data:   []Batch{},
+	}
+
+	if len(batches) > limit {
+		batches = batches[:limit]
+		list.HasMore = true
+	}
+
+	for _, b := range batches {
+		list.Data = append(list.Data, toBatch(b))
+	}
+
+	if len(list.Data) > 0 {
+		list.FirstID = &list.Data[0].ID
+		list.LastID = &list.Data[len(list.Data)-1].ID
+	}
+
+	return list
+}
+
+func toError(err error) *errs.Error {
+	switch {
+	case errors.Is(err, batchqueue.ErrNotFound):
+		return errs.New(errs.NotFound, err)
+	case errors.Is(err, batchqueue.ErrFinished):
+		return errs.New(errs.FailedPrecondition, err)
+	}
+
+	return errs.New(errs.Internal, err)
+}
@@ -0,0 +1,130 @@
+package batchapp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/batchqueue"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+)
+
+const inputLine = `{"custom_id":"a","method":"POST","url":"/v1/embeddings","body":{"model":"embed","input":"hello"}}`
+
+func TestBatchesLifecycle(t *testing.T) {
+	a, files := newTestApp(t)
+
+	input, err := files.Create(t.Context(), "", "input.jsonl", "batch", strings.NewReader(inputLine))
+	if err != nil {
+		t.Fatalf("should be able to upload input: %s", err)
+	}
+
+	body := `{"input_file_id":"` + input.ID + `","endpoint":"/v1/embeddings","completion_window":"24h","metadata":{"job":"nightly"}}`
+
+	created, ok := a.create(t.Context(), httptest.NewRequest(http.MethodPost, "/v1/batches", strings.NewReader(body))).(Batch)
+	if !ok {
+		t.Fatal("create: expected a batch object")
+	}
+
+	if created.Object != "batch" || created.Status != batchqueue.StatusValidating || created.RequestCounts.Total != 1 || created.Metadata["job"] != "nightly" {
+		t.Errorf("create: got %+v", created)
+	}
+
+	if created.OutputFileID != nil || created.Errors != nil || created.ExpiresAt == nil {
+		t.Errorf("create: got output file %v, errors %v and expiry %v", created.OutputFileID, created.Errors, created.ExpiresAt)
+	}
+
+	list, ok := a.list(t.Context(), httptest.NewRequest(http.MethodGet, "/v1/batches?limit=1", nil)).(BatchList)
+	if !ok || len(list.Data) != 1 || list.Data[0].ID != created.ID || list.HasMore {
+		t.Fatalf("list: got %+v", list)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/batches/"+created.ID+"/cancel", nil)
+	req.SetPathValue("batch_id", created.ID)
+
+	cancelled, ok := a.cancel(t.Context(), req).(Batch)
+	if !ok || cancelled.Status != batchqueue.StatusCancelled || cancelled.CancelledAt == nil {
+		t.Errorf("cancel: got %+v", cancelled)
+	}
+
+	appErr, ok := a.cancel(t.Context(), req).(*errs.Error)
+	if !ok || !appErr.Code.Equal(errs.FailedPrecondition) {
+		t.Errorf("cancel twice: got %v, want %s", appErr, errs.FailedPrecondition)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/batches/batch_missing", nil)
+	req.SetPathValue("batch_id", "batch_missing")
+
+	appErr, ok = a.retrieve(t.Context(), req).(*errs.Error)
+	if !ok || !appErr.Code.Equal(errs.NotFound) {
+		t.Errorf("retrieve missing: got %v, want %s", appErr, errs.NotFound)
+	}
+}
+
+func TestCreateRejectsInvalidBatches(t *testing.T) {
+	a, files := newTestApp(t)
+
+	batchInput, err := files.Create(t.Context(), "", "input.jsonl", "batch", strings.NewReader(inputLine))
+	if err != nil {
+		t.Fatalf("should be able to upload input: %s", err)
+	}
+
+	userData, err := files.Create(t.Context(), "", "notes.jsonl", "user_data", strings.NewReader(inputLine))
+	if err != nil {
+		t.Fatalf("should be able to upload input: %s", err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "missing input file", body: `{"endpoint":"/v1/embeddings","completion_window":"24h"}`},
+		{name: "unknown input file", body: `{"input_file_id":"file-missing","endpoint":"/v1/embeddings","completion_window":"24h"}`},
+		{name: "wrong purpose", body: `{"input_file_id":"` + userData.ID + `","endpoint":"/v1/embeddings","completion_window":"24h"}`},
+		{name: "unsupported endpoint", body: `{"input_file_id":"` + batchInput.ID + `","endpoint":"/v1/responses","completion_window":"24h"}`},
+		{name: "completion window", body: `{"input_file_id":"` + batchInput.ID + `","endpoint":"/v1/embeddings","completion_window":"1h"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appErr, ok := a.create(t.Context(), httptest.NewRequest(http.MethodPost, "/v1/batches", strings.NewReader(tt.body))).(*errs.Error)
+			if !ok {
+				t.Fatal("create: expected an error")
+			}
+			if !appErr.Code.Equal(errs.InvalidArgument) {
+				t.Errorf("Code: got %s, want %s", appErr.Code, errs.InvalidArgument)
+			}
+		})
+	}
+}
+
+func newTestApp(t *testing.T) (*app, *filestore.Store) {
+	t.Helper()
+
+	files, err := filestore.New(filestore.Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("should be able to construct file store: %s", err)
+	}
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	queue, err := batchqueue.New(batchqueue.Config{Log: log, Dir: t.TempDir(), Files: files})
+	if err != nil {
+		t.Fatalf("should be able to construct queue: %s", err)
+	}
+
+	a := newApp(Config{
+		Log:               log,
+		Files:             files,
+		Queue:             queue,
+		AuthorizationMode: auth.Open,
+	})
+
+	return a, files
+}
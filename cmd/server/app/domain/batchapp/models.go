@@ -0,0 +1,157 @@
+package batchapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/batchqueue"
+)
+
+// Limits OpenAI places on batch metadata.
+const (
+	maxMetadataPairs = 16
+	maxMetadataKey   = 64
+	maxMetadataValue = 512
+)
+
+// BatchRequest creates a batch from an uploaded input file.
+type BatchRequest struct {
+	InputFileID      string            `json:"input_file_id"`
+	Endpoint         string            `json:"endpoint"`
+	CompletionWindow string            `json:"completion_window"`
+	Metadata         map[string]string `json:"metadata"`
+}
+
+// Decode implements the decoder interface.
+func (app *BatchRequest) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the request is valid.
+func (app *BatchRequest) Validate() error {
+	if app.InputFileID == "" {
+		return fmt.Errorf("input_file_id is required")
+	}
+
+	if _, exists := endpointGrants[app.Endpoint]; !exists {
+		return fmt.Errorf("endpoint must be one of %v", endpoints())
+	}
+
+	if app.CompletionWindow != batchqueue.CompletionWindow {
+		return fmt.Errorf("completion_window must be %q", batchqueue.CompletionWindow)
+	}
+
+	if len(app.Metadata) > maxMetadataPairs {
+		return fmt.Errorf("metadata can have at most %d pairs", maxMetadataPairs)
+	}
+
+	for k, v := range app.Metadata {
+		if len(k) > maxMetadataKey || len(v) > maxMetadataValue {
+			return fmt.Errorf("metadata keys are limited to %d characters and values to %d", maxMetadataKey, maxMetadataValue)
+		}
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// Batch is the OpenAI batch object describing a batch job.
+type Batch struct {
+	ID               string                   `json:"id"`
+	Object           string                   `json:"object"`
+	Endpoint         string                   `json:"endpoint"`
+	Errors           *BatchErrors             `json:"errors"`
+	InputFileID      string                   `json:"input_file_id"`
+	CompletionWindow string                   `json:"completion_window"`
+	Status           string                   `json:"status"`
+	OutputFileID     *string                  `json:"output_file_id"`
+	ErrorFileID      *string                  `json:"error_file_id"`
+	CreatedAt        int64                    `json:"created_at"`
+	InProgressAt     *int64                   `json:"in_progress_at"`
+	ExpiresAt        *int64                   `json:"expires_at"`
+	FinalizingAt     *int64                   `json:"finalizing_at"`
+	CompletedAt      *int64                   `json:"completed_at"`
+	FailedAt         *int64                   `json:"failed_at"`
+	ExpiredAt        *int64                   `json:"expired_at"`
+	CancellingAt     *int64                   `json:"cancelling_at"`
+	CancelledAt      *int64                   `json:"cancelled_at"`
+	RequestCounts    batchqueue.RequestCounts `json:"request_counts"`
+	Metadata         map[string]string        `json:"metadata"`
+}
+
+// BatchErrors lists the problems found with a batch's input file.
+type BatchErrors struct {
+	Object string                 `json:"object"`
+	Data   []batchqueue.LineError `json:"data"`
+}
+
+// Encode implements web.Encoder.
+func (b Batch) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(b)
+	return data, "application/json", err
+}
+
+func toBatch(b batchqueue.Batch) Batch {
+	batch := Batch{
+		ID:               b.ID,
+		Object:           "batch",
+		Endpoint:         b.Endpoint,
+		InputFileID:      b.InputFileID,
+		CompletionWindow: b.CompletionWindow,
+		Status:           b.Status,
+		OutputFileID:     optionalString(b.OutputFileID),
+		ErrorFileID:      optionalString(b.ErrorFileID),
+		CreatedAt:        b.CreatedAt.Unix(),
+		InProgressAt:     optionalTime(b.InProgressAt),
+		ExpiresAt:        optionalTime(b.ExpiresAt),
+		FinalizingAt:     optionalTime(b.FinalizingAt),
+		CompletedAt:      optionalTime(b.CompletedAt),
+		FailedAt:         optionalTime(b.FailedAt),
+		ExpiredAt:        optionalTime(b.ExpiredAt),
+		CancellingAt:     optionalTime(b.CancellingAt),
+		CancelledAt:      optionalTime(b.CancelledAt),
+		RequestCounts:    b.RequestCounts,
+		Metadata:         b.Metadata,
+	}
+
+	if len(b.Errors) > 0 {
+		batch.Errors = &BatchErrors{
+			Object: "list",
+			Data:   b.Errors,
+		}
+	}
+
+	return batch
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func optionalTime(t time.Time) *int64 {
+	if t.IsZero() {
+		return nil
+	}
+	unix := t.Unix()
+	return &unix
+}
+
+// BatchList is a page of the caller's batches.
+type BatchList struct {
+	Object  string  `json:"object"`
+	Data    []Batch `json:"data"`
+	FirstID *string `json:"first_id"`
+	LastID  *string `json:"last_id"`
+	HasMore bool    `json:"has_more"`
+}
+
+// Encode implements web.Encoder.
+func (l BatchList) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(l)
+	return data, "application/json", err
+}
@@ -0,0 +1,37 @@
+package batchapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/batchqueue"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log               *logger.Logger
+	AuthClient        *authclient.Client
+	Files             *filestore.Store
+	Queue             *batchqueue.Queue
+	AuthorizationMode auth.Mode
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	api := newApp(cfg)
+
+	inferenceAccess := mid.NewAccess(cfg.AuthClient, cfg.AuthorizationMode, false).Inference("batches")
+	tokenBudget := mid.TokenBudget(cfg.Log, cfg.AuthClient)
+
+	app.HandlerFunc(http.MethodPost, version, "/batches", api.create, inferenceAccess, tokenBudget)
+	app.HandlerFunc(http.MethodGet, version, "/batches", api.list, inferenceAccess)
+	app.HandlerFunc(http.MethodGet, version, "/batches/{batch_id}", api.retrieve, inferenceAccess)
+	app.HandlerFunc(http.MethodPost, version, "/batches/{batch_id}/cancel", api.cancel, inferenceAccess)
+}
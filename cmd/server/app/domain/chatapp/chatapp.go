@@ -8,6 +8,9 @@ import (
 	"net/http"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mediafetch"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
 	"github.com/ardanlabs/kronk/sdk/kronk"
@@ -15,15 +18,30 @@ import (
 	"github.com/ardanlabs/kronk/sdk/pool"
 )
 
+// chatModel is the part of a loaded model the handler uses.
+type chatModel interface {
+	ChatStreamingHTTP(ctx context.Context, w http.ResponseWriter, d model.D) (model.ChatResponse, error)
+}
+
 type app struct {
-	log  *logger.Logger
-	pool *pool.Pool
+	log     *logger.Logger
+	acquire func(ctx context.Context, modelID string) (chatModel, error)
+	files   *filestore.Store
+	media   *mediafetch.Fetcher
 }
 
 func newApp(cfg Config) *app {
 	return &app{
-		log:  cfg.Log,
-		pool: cfg.Pool,
+		log:     cfg.Log,
+		acquire: acquireModel(cfg.Pool),
+		files:   cfg.Files,
+		media:   cfg.Media,
+	}
+}
+
+func acquireModel(p *pool.Pool) func(ctx context.Context, modelID string) (chatModel, error) {
+	return func(ctx context.Context, modelID string) (chatModel, error) {
+		return p.Kronk.AquireModel(ctx, modelID)
 	}
 }
 
@@ -45,16 +63,38 @@ func (a *app) chatCompletions(ctx context.Context, r *http.Request) web.Encoder
 		return errs.Errorf(errs.InvalidArgument, "model name must be a string")
 	}
 
-	krn, err := a.pool.Kronk.AquireModel(ctx, modelID)
-	if err != nil {
-		return errs.FromSDK(err)
+	if err := mid.AuthorizeModel(ctx, modelID); err != nil {
+		return err
+	}
+
+	if err := mid.CheckTokenBudget(ctx, modelID); err != nil {
+		return err
 	}
 
 	a.log.Info(ctx, "chat-completions", "REQUEST-PARAMS", req.String())
 
 	d := model.MapToModelD(req)
+	kronk.ApplySessionHeader(r.Header, d)
+
+	if err := model.ResolveFileInputs(ctx, d, a.files.Lookup(mid.GetSubject(ctx))); err != nil {
+		return errs.FromSDK(err)
+	}
+
+	if err := model.ResolveMediaURLs(ctx, d, a.media.MediaFetcher()); err != nil {
+		return errs.FromSDK(err)
+	}
 
-	if _, err := krn.ChatStreamingHTTP(ctx, web.GetWriter(ctx), d); err != nil {
+	krn, err := a.acquire(ctx, modelID)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	resp, err := krn.ChatStreamingHTTP(ctx, web.GetWriter(ctx), d)
+	if resp.Usage != nil {
+		mid.RecordTokenUsage(ctx, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	}
+
+	if err != nil {
 		if errors.Is(err, kronk.ErrResponseCommitted) {
 			return web.NewNoResponseError(errs.FromSDK(err))
 		}
@@ -0,0 +1,65 @@
+package chatapp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authtest"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+)
+
+type fakeModel struct {
+	usage model.Usage
+}
+
+func (m fakeModel) ChatStreamingHTTP(ctx context.Context, w http.ResponseWriter, d model.D) (model.ChatResponse, error) {
+	if stream, _ := d["stream"].(bool); stream {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "data: {}\n\ndata: [DONE]\n\n")
+	}
+
+	return model.ChatResponse{Usage: &m.usage}, nil
+}
+
+func TestChatCompletionsChargesStreamedUsage(t *testing.T) {
+	a := authtest.Start(t)
+
+	bearer := a.Token(t, "chat-completions", []auth.TokenBudget{
+		{Input: 1000, Output: 1000, Window: auth.RateDay},
+	})
+
+	api := &app{
+		log: a.Log,
+		acquire: func(context.Context, string) (chatModel, error) {
+			return fakeModel{usage: model.Usage{PromptTokens: 12, CompletionTokens: 5}}, nil
+		},
+	}
+
+	webApp := web.NewApp(func(context.Context, string, ...any) {})
+	inferenceAccess := mid.NewAccess(a.Client, auth.FullProtected, false).Inference("chat-completions")
+	webApp.HandlerFunc(http.MethodPost, "v1", "/chat/completions", api.chatCompletions, inferenceAccess, mid.TokenBudget(a.Log, a.Client))
+
+	body := `{"model":"test-model","stream":true,"messages":[{"role":"user","content":"hello"}]}`
+	r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	r.Header.Set("authorization", bearer)
+	w := httptest.NewRecorder()
+
+	webApp.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("should get status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	input, output := a.Used(t, bearer, "test-model")
+	if input != 12 || output != 5 {
+		t.Errorf("should charge 12 input and 5 output tokens, got %d and %d", input, output)
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"time"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mediafetch"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
 	"github.com/ardanlabs/kronk/sdk/pool"
 )
 
@@ -17,17 +20,23 @@ type Config struct {
 	Log               *logger.Logger
 	AuthClient        *authclient.Client
 	Pool              *pool.Pool
+	Files             *filestore.Store
+	Media             *mediafetch.Fetcher
 	AuthorizationMode auth.Mode
 	InferenceTimeout  time.Duration
+	Priorities        map[string]model.Priority
 }
 
 // Routes adds specific routes for this group.
 func Routes(app *web.App, cfg Config) {
 	const version = "v1"
+	const endpoint = "chat-completions"
 
 	api := newApp(cfg)
 
-	inferenceAccess := mid.NewAccess(cfg.AuthClient, cfg.AuthorizationMode, false).Inference("chat-completions")
+	inferenceAccess := mid.NewAccess(cfg.AuthClient, cfg.AuthorizationMode, false).Inference(endpoint)
+	schedule := mid.Schedule(cfg.Priorities[endpoint])
+	tokenBudget := mid.TokenBudget(cfg.Log, cfg.AuthClient)
 
-	app.HandlerFunc(http.MethodPost, version, "/chat/completions", api.chatCompletions, mid.Timeout(cfg.InferenceTimeout), inferenceAccess)
+	app.HandlerFunc(http.MethodPost, version, "/chat/completions", api.chatCompletions, mid.Timeout(cfg.InferenceTimeout), inferenceAccess, schedule, tokenBudget)
 }
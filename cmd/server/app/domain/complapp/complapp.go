@@ -0,0 +1,278 @@
+// Package complapp provides the legacy completions and infill api endpoints.
+package complapp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/kronk"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+	"github.com/ardanlabs/kronk/sdk/pool"
+)
+
+type app struct {
+	log  *logger.Logger
+	pool *pool.Pool
+}
+
+func newApp(cfg Config) *app {
+	return &app{
+		log:  cfg.Log,
+		pool: cfg.Pool,
+	}
+}
+
+func (a *app) completions(ctx context.Context, r *http.Request) web.Encoder {
+	return a.complete(ctx, r, "completions", toCompletionDocument)
+}
+
+func (a *app) infill(ctx context.Context, r *http.Request) web.Encoder {
+	return a.complete(ctx, r, "infill", toInfillDocument)
+}
+
+func (a *app) complete(ctx context.Context, r *http.Request, name string, toDocument func(model.D) (model.D, completionOptions, error)) web.Encoder {
+	var req model.D
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	modelIDReq, exists := req["model"]
+	if !exists {
+		return errs.Errorf(errs.InvalidArgument, "missing model field")
+	}
+
+	modelID, ok := modelIDReq.(string)
+	if !ok {
+		return errs.Errorf(errs.InvalidArgument, "model name must be a string")
+	}
+
+	d, opts, err := toDocument(req)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := model.ValidateCompletionRequest(d); err != nil {
+		return errs.FromSDK(err)
+	}
+
+	if err := mid.AuthorizeModel(ctx, modelID); err != nil {
+		return err
+	}
+
+	if err := mid.CheckTokenBudget(ctx, modelID); err != nil {
+		return err
+	}
+
+	krn, err := a.pool.Kronk.AquireModel(ctx, modelID)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	a.log.Info(ctx, name, "REQUEST-PARAMS", req.String())
+
+	if opts.stream {
+		committed, err := a.handleStreaming(ctx, krn, d, opts)
+		if err != nil {
+			if committed {
+				return web.NewNoResponseError(errs.FromSDK(err))
+			}
+			return errs.FromSDK(err)
+		}
+
+		return web.NewNoResponse()
+	}
+
+	resp, err := krn.Completion(ctx, d)
+	if resp.Usage != nil {
+		mid.RecordTokenUsage(ctx, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	}
+
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	return toCompletionResponse(resp, opts)
+}
+
+func (a *app) handleStreaming(ctx context.Context, krn *kronk.Kronk, d model.D, opts completionOptions) (bool, error) {
+	w := web.GetWriter(ctx)
+
+	if !supportsResponseFlush(w) {
+		return false, fmt.Errorf("streaming not supported")
+	}
+
+	ch, err := krn.CompletionStreaming(ctx, d)
+	if err != nil {
+		return false, fmt.Errorf("completion streaming: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if err := http.NewResponseController(w).Flush(); err != nil {
+		return true, fmt.Errorf("flush streaming headers: %w", err)
+	}
+
+	state := streamState{
+		w:       w,
+		opts:    opts,
+		offsets: make(map[int]int),
+	}
+
+	// The usage of the last chunk seen is charged even when the stream ends
+	// early, since those tokens were generated.
+	defer func() {
+		mid.RecordTokenUsage(ctx, state.inputTokens, state.outputTokens)
+	}()
+
+	for resp := range ch {
+		if err := ctx.Err(); err != nil {
+			return true, fmt.Errorf("completion-streaming: context canceled, do not send response: %w", err)
+		}
+
+		done, err := state.processChunk(resp)
+		if err != nil {
+			return true, err
+		}
+		if done {
+			return true, nil
+		}
+	}
+
+	return true, state.sendData([]byte("[DONE]"))
+}
+
+// =============================================================================
+
+type streamState struct {
+	w            http.ResponseWriter
+	opts         completionOptions
+	offsets      map[int]int // Text offset of the next token of each choice.
+	inputTokens  int
+	outputTokens int
+}
+
+// processChunk sends a chat response chunk as a text_completion chunk. It
+// reports true when the chunk ended the stream with an error event.
+func (s *streamState) processChunk(resp model.ChatResponse) (bool, error) {
+	if resp.Usage != nil {
+		s.inputTokens = resp.Usage.PromptTokens
+		s.outputTokens = resp.Usage.CompletionTokens
+	}
+
+	if len(resp.Choices) == 0 {
+		if !s.opts.includeUsage {
+			return false, nil
+		}
+
+		return false, s.sendEvent(CompletionResponse{
+			ID:                resp.ID,
+			Object:            "text_completion",
+			Created:           resp.Created,
+			Model:             resp.Model,
+			SystemFingerprint: resp.SystemFingerprint,
+			Choices:           []CompletionChoice{},
+			Usage:             resp.Usage,
+		})
+	}
+
+	choice := resp.Choices[0]
+
+	if choice.FinishReason() == model.FinishReasonError {
+		var message string
+		if choice.Delta != nil {
+			message = choice.Delta.Content
+		}
+
+		event := model.D{
+			"error": model.D{
+				"message": message,
+				"type":    "server_error",
+				"code":    "server_error",
+			},
+		}
+
+		return true, s.sendEvent(event)
+	}
+
+	offset, started := s.offsets[choice.Index]
+	if !started {
+		offset = utf8.RuneCountInString(s.opts.echo)
+	}
+
+	out := CompletionChoice{
+		Index:        choice.Index,
+		FinishReason: choice.FinishReasonPtr,
+	}
+
+	// The terminal chunk repeats the whole text in its message, which was
+	// already streamed in the deltas.
+	if choice.FinishReason() == "" && choice.Delta != nil {
+		out.Text = choice.Delta.Content
+		if s.opts.logprobs {
+			out.Logprobs = toCompletionLogprobs(choice.Logprobs, offset)
+		}
+	}
+
+	if !started {
+		out.Text = s.opts.echo + out.Text
+	}
+	s.offsets[choice.Index] = offset + utf8.RuneCountInString(out.Text)
+
+	if started && out.Text == "" && out.FinishReason == nil {
+		return false, nil
+	}
+
+	return false, s.sendEvent(CompletionResponse{
+		ID:                resp.ID,
+		Object:            "text_completion",
+		Created:           resp.Created,
+		Model:             resp.Model,
+		SystemFingerprint: resp.SystemFingerprint,
+		Choices:           []CompletionChoice{out},
+	})
+}
+
+func (s *streamState) sendEvent(event any) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	return s.sendData(data)
+}
+
+func (s *streamState) sendData(data []byte) error {
+	if _, err := fmt.Fprintf(s.w, "data: %s\n\n", data); err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+
+	return http.NewResponseController(s.w).Flush()
+}
+
+func supportsResponseFlush(w http.ResponseWriter) bool {
+	for w != nil {
+		switch v := w.(type) {
+		case interface{ FlushError() error }:
+			return true
+		case http.Flusher:
+			return true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = v.Unwrap()
+		default:
+			return false
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,194 @@
+package complapp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompletionsRejectsInvalidRequestsBeforeModelAcquisition(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{name: "missing model", path: "/v1/completions", body: `{"prompt":"hello"}`},
+		{name: "missing prompt", path: "/v1/completions", body: `{"model":"test"}`},
+		{name: "messages", path: "/v1/completions", body: `{"model":"test","prompt":"hello","messages":[]}`},
+		{name: "echo with suffix", path: "/v1/completions", body: `{"model":"test","prompt":"a","suffix":"b","echo":true}`},
+		{name: "echo with tokens", path: "/v1/completions", body: `{"model":"test","prompt":[1,2],"echo":true}`},
+		{name: "negative logprobs", path: "/v1/completions", body: `{"model":"test","prompt":"hello","logprobs":-1}`},
+		{name: "infill prefix type", path: "/v1/infill", body: `{"model":"test","input_prefix":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+
+			a := &app{}
+			handler := a.completions
+			if tt.path == "/v1/infill" {
+				handler = a.infill
+			}
+
+			resp := handler(t.Context(), req)
+			appErr, ok := resp.(*errs.Error)
+			if !ok {
+				t.Fatalf("handler: got %T, want *errs.Error", resp)
+			}
+			if !appErr.Code.Equal(errs.InvalidArgument) {
+				t.Errorf("Code: got %s, want %s", appErr.Code, errs.InvalidArgument)
+			}
+		})
+	}
+}
+
+func TestToCompletionDocument(t *testing.T) {
+	req := model.D{
+		"model":          "test",
+		"prompt":         "def add(a, b):",
+		"echo":           true,
+		"logprobs":       json.Number("3"),
+		"stream":         true,
+		"stream_options": map[string]any{"include_usage": false},
+	}
+
+	d, opts, err := toCompletionDocument(req)
+	if err != nil {
+		t.Fatalf("toCompletionDocument: %v", err)
+	}
+
+	wantOpts := completionOptions{stream: true, echo: "def add(a, b):", logprobs: true}
+	if diff := cmp.Diff(wantOpts, opts, cmp.AllowUnexported(completionOptions{})); diff != "" {
+		t.Errorf("options mismatch (-want +got):\n%s", diff)
+	}
+
+	wantDoc := model.D{
+		"model":          "test",
+		"prompt":         "def add(a, b):",
+		"logprobs":       true,
+		"top_logprobs":   3,
+		"stream":         true,
+		"stream_options": model.D{"include_usage": true},
+	}
+	if diff := cmp.Diff(wantDoc, d); diff != "" {
+		t.Errorf("document mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestToInfillDocument(t *testing.T) {
+	req := model.D{
+		"model":        "test",
+		"input_prefix": "def add(a, b):\n    ",
+		"input_suffix": "\n\nprint(add(1, 2))\n",
+		"prompt":       "return",
+		"n_predict":    json.Number("16"),
+	}
+
+	d, _, err := toInfillDocument(req)
+	if err != nil {
+		t.Fatalf("toInfillDocument: %v", err)
+	}
+
+	wantDoc := model.D{
+		"model":          "test",
+		"prompt":         "def add(a, b):\n    return",
+		"suffix":         "\n\nprint(add(1, 2))\n",
+		"max_tokens":     json.Number("16"),
+		"stream_options": model.D{"include_usage": true},
+	}
+	if diff := cmp.Diff(wantDoc, d); diff != "" {
+		t.Errorf("document mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestToCompletionResponse(t *testing.T) {
+	stop := model.FinishReasonStop
+	resp := model.ChatResponse{
+		ID:     "cmpl-1",
+		Object: model.ObjectChatText,
+		Model:  "test",
+		Choices: []model.Choice{
+			{
+				Index:   0,
+				Message: &model.ResponseMessage{Content: " world"},
+				Logprobs: &model.Logprobs{Content: []model.ContentLogprob{
+					{Token: " wo", Logprob: -0.5, TopLogprobs: []model.TopLogprob{{Token: " wo", Logprob: -0.5}}},
+					{Token: "rld", Logprob: -0.25, TopLogprobs: []model.TopLogprob{{Token: "rld", Logprob: -0.25}}},
+				}},
+				FinishReasonPtr: &stop,
+			},
+		},
+	}
+
+	got := toCompletionResponse(resp, completionOptions{echo: "héllo", logprobs: true})
+
+	want := CompletionResponse{
+		ID:     "cmpl-1",
+		Object: "text_completion",
+		Model:  "test",
+		Choices: []CompletionChoice{
+			{
+				Text:  "héllo world",
+				Index: 0,
+				Logprobs: &CompletionLogprobs{
+					Tokens:        []string{" wo", "rld"},
+					TokenLogprobs: []float32{-0.5, -0.25},
+					TopLogprobs:   []map[string]float32{{" wo": -0.5}, {"rld": -0.25}},
+					TextOffset:    []int{5, 8},
+				},
+				FinishReason: &stop,
+			},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("response mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStreamStateEchoesPromptInFirstChunk(t *testing.T) {
+	stop := model.FinishReasonStop
+	chunks := []model.ChatResponse{
+		{ID: "cmpl-1", Choices: []model.Choice{{Index: 0, Delta: &model.ResponseMessage{Content: " world"}}}},
+		{ID: "cmpl-1", Choices: []model.Choice{{Index: 0, Delta: &model.ResponseMessage{}, Message: &model.ResponseMessage{Content: " world"}, FinishReasonPtr: &stop}}},
+		{ID: "cmpl-1", Choices: []model.Choice{}, Usage: &model.Usage{PromptTokens: 2, CompletionTokens: 1}},
+	}
+
+	w := httptest.NewRecorder()
+	state := streamState{
+		w:       w,
+		opts:    completionOptions{stream: true, echo: "hello"},
+		offsets: make(map[int]int),
+	}
+
+	for _, chunk := range chunks {
+		if _, err := state.processChunk(chunk); err != nil {
+			t.Fatalf("processChunk: %v", err)
+		}
+	}
+
+	var texts []string
+	for event := range strings.SplitSeq(strings.TrimSpace(w.Body.String()), "\n\n") {
+		var resp CompletionResponse
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(event, "data: ")), &resp); err != nil {
+			t.Fatalf("unmarshal %q: %v", event, err)
+		}
+		if resp.Object != "text_completion" || len(resp.Choices) != 1 {
+			t.Fatalf("event: got %+v, want one text_completion choice", resp)
+		}
+		texts = append(texts, resp.Choices[0].Text)
+	}
+
+	if diff := cmp.Diff([]string{"hello world", ""}, texts); diff != "" {
+		t.Errorf("texts mismatch (-want +got):\n%s", diff)
+	}
+	if state.inputTokens != 2 || state.outputTokens != 1 {
+		t.Errorf("usage: got %d/%d, want 2/1", state.inputTokens, state.outputTokens)
+	}
+}
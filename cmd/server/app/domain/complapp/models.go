@@ -0,0 +1,260 @@
+package complapp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+)
+
+// completionOptions holds the request fields that shape the response rather
+// than the generation.
+type completionOptions struct {
+	stream       bool
+	echo         string // The prompt text to prepend to every choice.
+	logprobs     bool
+	includeUsage bool
+}
+
+// toCompletionDocument converts a legacy completions request into the
+// document accepted by the raw completion path of the SDK. An integer
+// logprobs is mapped onto the logprobs and top_logprobs fields.
+func toCompletionDocument(req model.D) (model.D, completionOptions, error) {
+	d := model.MapToModelD(req)
+
+	var opts completionOptions
+
+	if val, exists := d["stream"]; exists {
+		stream, ok := val.(bool)
+		if !ok {
+			return nil, opts, errors.New("stream must be a boolean")
+		}
+		opts.stream = stream
+	}
+
+	if val, exists := d["echo"]; exists {
+		echo, ok := val.(bool)
+		if !ok {
+			return nil, opts, errors.New("echo must be a boolean")
+		}
+
+		if echo {
+			if suffix, exists := d["suffix"]; exists && suffix != nil {
+				return nil, opts, errors.New("echo is not supported with suffix")
+			}
+
+			prompt, ok := promptText(d["prompt"])
+			if !ok {
+				return nil, opts, errors.New("echo requires a text prompt")
+			}
+			opts.echo = prompt
+		}
+
+		delete(d, "echo")
+	}
+
+	switch val := d["logprobs"].(type) {
+	case nil:
+		delete(d, "logprobs")
+
+	case bool:
+		opts.logprobs = val
+
+	case json.Number:
+		n, err := val.Int64()
+		if err != nil || n < 0 {
+			return nil, opts, errors.New("logprobs must be a non-negative integer")
+		}
+
+		opts.logprobs = true
+		d["logprobs"] = true
+		if n > 0 {
+			d["top_logprobs"] = int(n)
+		}
+
+	default:
+		return nil, opts, errors.New("logprobs must be a non-negative integer")
+	}
+
+	// Usage is always requested from the model so it can be charged against
+	// the token budget. It is only sent to clients that asked for it.
+	if so, ok := d["stream_options"].(model.D); ok {
+		if includeUsage, ok := so["include_usage"].(bool); ok {
+			opts.includeUsage = includeUsage
+		}
+	}
+	d["stream_options"] = model.D{"include_usage": true}
+
+	return d, opts, nil
+}
+
+// toInfillDocument converts a llama.cpp style infill request into a legacy
+// completions request. The prompt is the text typed at the cursor and is
+// appended to input_prefix.
+func toInfillDocument(req model.D) (model.D, completionOptions, error) {
+	d := model.MapToModelD(req)
+
+	prefix, err := infillField(d, "input_prefix")
+	if err != nil {
+		return nil, completionOptions{}, err
+	}
+
+	suffix, err := infillField(d, "input_suffix")
+	if err != nil {
+		return nil, completionOptions{}, err
+	}
+
+	prompt, err := infillField(d, "prompt")
+	if err != nil {
+		return nil, completionOptions{}, err
+	}
+
+	if _, exists := d["max_tokens"]; !exists {
+		if nPredict, exists := d["n_predict"]; exists {
+			d["max_tokens"] = nPredict
+		}
+	}
+
+	delete(d, "input_prefix")
+	delete(d, "input_suffix")
+	delete(d, "n_predict")
+
+	d["prompt"] = prefix + prompt
+	d["suffix"] = suffix
+
+	return toCompletionDocument(d)
+}
+
+func infillField(d model.D, name string) (string, error) {
+	val, exists := d[name]
+	if !exists || val == nil {
+		return "", nil
+	}
+
+	s, ok := val.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", name)
+	}
+
+	return s, nil
+}
+
+// promptText returns the prompt of a request when it is given as text.
+func promptText(val any) (string, bool) {
+	switch prompt := val.(type) {
+	case string:
+		return prompt, true
+
+	case []any:
+		if len(prompt) == 1 {
+			s, ok := prompt[0].(string)
+			return s, ok
+		}
+	}
+
+	return "", false
+}
+
+// =============================================================================
+
+// CompletionResponse is the response for a legacy completions request.
+type CompletionResponse struct {
+	ID                string             `json:"id"`
+	Object            string             `json:"object"`
+	Created           int64              `json:"created"`
+	Model             string             `json:"model"`
+	SystemFingerprint string             `json:"system_fingerprint"`
+	Choices           []CompletionChoice `json:"choices"`
+	Usage             *model.Usage       `json:"usage,omitempty"`
+}
+
+// Encode implements web.Encoder.
+func (r CompletionResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, "application/json", nil
+}
+
+// CompletionChoice is a single generated text of a completions response.
+type CompletionChoice struct {
+	Text         string              `json:"text"`
+	Index        int                 `json:"index"`
+	Logprobs     *CompletionLogprobs `json:"logprobs"`
+	FinishReason *string             `json:"finish_reason"`
+}
+
+// CompletionLogprobs holds the legacy log probability format. Each slice
+// has one entry per generated token, and TextOffset is the character offset
+// of the token in the choice text.
+type CompletionLogprobs struct {
+	Tokens        []string             `json:"tokens"`
+	TokenLogprobs []float32            `json:"token_logprobs"`
+	TopLogprobs   []map[string]float32 `json:"top_logprobs"`
+	TextOffset    []int                `json:"text_offset"`
+}
+
+func toCompletionResponse(resp model.ChatResponse, opts completionOptions) CompletionResponse {
+	choices := make([]CompletionChoice, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		var text string
+		if choice.Message != nil {
+			text = choice.Message.Content
+		}
+
+		choices[i] = CompletionChoice{
+			Text:         opts.echo + text,
+			Index:        choice.Index,
+			FinishReason: choice.FinishReasonPtr,
+		}
+
+		if opts.logprobs {
+			choices[i].Logprobs = toCompletionLogprobs(choice.Logprobs, utf8.RuneCountInString(opts.echo))
+		}
+	}
+
+	return CompletionResponse{
+		ID:                resp.ID,
+		Object:            "text_completion",
+		Created:           resp.Created,
+		Model:             resp.Model,
+		SystemFingerprint: resp.SystemFingerprint,
+		Choices:           choices,
+		Usage:             resp.Usage,
+	}
+}
+
+// toCompletionLogprobs converts chat logprobs to the legacy format with text
+// offsets counted from offset.
+func toCompletionLogprobs(logprobs *model.Logprobs, offset int) *CompletionLogprobs {
+	lp := CompletionLogprobs{
+		Tokens:        []string{},
+		TokenLogprobs: []float32{},
+		TopLogprobs:   []map[string]float32{},
+		TextOffset:    []int{},
+	}
+
+	if logprobs == nil {
+		return &lp
+	}
+
+	for _, content := range logprobs.Content {
+		top := make(map[string]float32, len(content.TopLogprobs))
+		for _, t := range content.TopLogprobs {
+			top[t.Token] = t.Logprob
+		}
+
+		lp.Tokens = append(lp.Tokens, content.Token)
+		lp.TokenLogprobs = append(lp.TokenLogprobs, content.Logprob)
+		lp.TopLogprobs = append(lp.TopLogprobs, top)
+		lp.TextOffset = append(lp.TextOffset, offset)
+
+		offset += utf8.RuneCountInString(content.Token)
+	}
+
+	return &lp
+}
@@ -0,0 +1,42 @@
+package complapp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+	"github.com/ardanlabs/kronk/sdk/pool"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log               *logger.Logger
+	AuthClient        *authclient.Client
+	Pool              *pool.Pool
+	AuthorizationMode auth.Mode
+	InferenceTimeout  time.Duration
+	Priorities        map[string]model.Priority
+}
+
+// Routes adds specific routes for this group. The infill route is also
+// served without the version prefix for editor plugins written against the
+// llama.cpp server.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+	const endpoint = "completions"
+
+	api := newApp(cfg)
+
+	inferenceAccess := mid.NewAccess(cfg.AuthClient, cfg.AuthorizationMode, false).Inference(endpoint)
+	schedule := mid.Schedule(cfg.Priorities[endpoint])
+	tokenBudget := mid.TokenBudget(cfg.Log, cfg.AuthClient)
+
+	app.HandlerFunc(http.MethodPost, version, "/completions", api.completions, mid.Timeout(cfg.InferenceTimeout), inferenceAccess, schedule, tokenBudget)
+	app.HandlerFunc(http.MethodPost, version, "/infill", api.infill, mid.Timeout(cfg.InferenceTimeout), inferenceAccess, schedule, tokenBudget)
+	app.HandlerFunc(http.MethodPost, "", "/infill", api.infill, mid.Timeout(cfg.InferenceTimeout), inferenceAccess, schedule, tokenBudget)
+}
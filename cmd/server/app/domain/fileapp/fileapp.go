@@ -0,0 +1,219 @@
+// Package fileapp provides the files api endpoints.
+package fileapp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+)
+
+// maxMemoryBytes bounds how much of an upload is held in memory while the
+// multipart form is parsed; the rest is spooled to a temporary file.
+// maxMultipartOverhead allows a small amount of space for multipart headers
+// and form fields on top of the file size limit.
+const (
+	maxMemoryBytes       = 8 << 20
+	maxMultipartOverhead = 1 << 20
+)
+
+// purposes lists the purposes OpenAI clients send with an upload.
+var purposes = []string{"assistants", "batch", "fine-tune", "vision", "user_data", "evals"}
+
+type app struct {
+	log   *logger.Logger
+	store *filestore.Store
+}
+
+func newApp(cfg Config) *app {
+	return &app{
+		log:   cfg.Log,
+		store: cfg.Store,
+	}
+}
+
+func (a *app) create(ctx context.Context, r *http.Request) web.Encoder {
+	if a.store == nil {
+		return errs.Errorf(errs.FailedPrecondition, "file storage is disabled")
+	}
+
+	if maxBytes := a.store.MaxBytes(); maxBytes > 0 {
+		r.Body = http.MaxBytesReader(nil, r.Body, maxBytes+maxMultipartOverhead)
+	}
+
+	if err := r.ParseMultipartForm(maxMemoryBytes); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return errs.New(errs.InvalidArgument, filestore.ErrTooLarge)
+		}
+		return errs.New(errs.InvalidArgument, fmt.Errorf("parse multipart form: %w", err))
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	purpose := r.FormValue("purpose")
+	if !slices.Contains(purposes, purpose) {
+		return errs.Errorf(errs.InvalidArgument, "purpose must be one of %v", purposes)
+	}
+
+	file, hdr, err := r.FormFile("file")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, fmt.Errorf("file form field: %w", err))
+	}
+	defer file.Close()
+
+	f, err := a.store.Create(ctx, mid.GetSubject(ctx), hdr.Filename, purpose, file)
+	if err != nil {
+		return toError(err)
+	}
+
+	a.log.Info(ctx, "files", "status", "created", "id", f.ID, "filename", f.Filename, "bytes", f.Bytes, "purpose", f.Purpose)
+
+	return toFile(f)
+}
+
+func (a *app) list(ctx context.Context, r *http.Request) web.Encoder {
+	if a.store == nil {
+		return errs.Errorf(errs.FailedPrecondition, "file storage is disabled")
+	}
+
+	q := r.URL.Query()
+
+	limit := 10000
+	if v := q.Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 10000 {
+			return errs.Errorf(errs.InvalidArgument, "limit must be between 1 and 10000")
+		}
+	}
+
+	files := a.store.List(ctx, mid.GetSubject(ctx), q.Get("purpose"))
+	switch q.Get("order") {
+	case "", "desc":
+	case "asc":
+		slices.Reverse(files)
+	default:
+		return errs.Errorf(errs.InvalidArgument, "order must be asc or desc")
+	}
+
+	if after := q.Get("after"); after != "" {
+		at := slices.IndexFunc(files, func(f filestore.File) bool {
+			return f.ID == after
+		})
+		if at == -1 {
+			return errs.Errorf(errs.InvalidArgument, "file %q not found", after)
+		}
+		files = files[at+1:]
+	}
+
+	return pageFiles(files, limit)
+}
+
+func (a *app) retrieve(ctx context.Context, r *http.Request) web.Encoder {
+	if a.store == nil {
+		return errs.Errorf(errs.FailedPrecondition, "file storage is disabled")
+	}
+
+	f, err := a.store.QueryByID(ctx, mid.GetSubject(ctx), web.Param(r, "file_id"))
+	if err != nil {
+		return toError(err)
+	}
+
+	return toFile(f)
+}
+
+func (a *app) content(ctx context.Context, r *http.Request) web.Encoder {
+	if a.store == nil {
+		return errs.Errorf(errs.FailedPrecondition, "file storage is disabled")
+	}
+
+	f, content, err := a.store.Content(ctx, mid.GetSubject(ctx), web.Param(r, "file_id"))
+	if err != nil {
+		return toError(err)
+	}
+	defer content.Close()
+
+	// The content is streamed from disk rather than held in memory. Its
+	// type is sniffed from the first bytes.
+	br := bufio.NewReader(content)
+	head, _ := br.Peek(512)
+
+	w := web.GetWriter(ctx)
+	w.Header().Set("Content-Type", http.DetectContentType(head))
+	w.Header().Set("Content-Length", strconv.FormatInt(f.Bytes, 10))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, br); err != nil {
+		return web.NewNoResponseError(fmt.Errorf("content: write: %w", err))
+	}
+
+	return web.NewNoResponse()
+}
+
+func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
+	if a.store == nil {
+		return errs.Errorf(errs.FailedPrecondition, "file storage is disabled")
+	}
+
+	id := web.Param(r, "file_id")
+
+	if err := a.store.Delete(ctx, mid.GetSubject(ctx), id); err != nil {
+		return toError(err)
+	}
+
+	a.log.Info(ctx, "files", "status", "deleted", "id", id)
+
+	return DeletedFile{
+		ID:      id,
+		Object:  "file",
+		Deleted: true,
+	}
+}
+
+// =============================================================================
+
+func pageFiles(files []filestore.File, limit int) FileList {
+	list := FileList{
+		Object: "list",
+		Data:   []File{},
+	}
+
+	if len(files) > limit {
+		files = files[:limit]
+		list.HasMore = true
+	}
+
+	for _, f := range files {
+		list.Data = append(list.Data, toFile(f))
+	}
+
+	if len(list.Data) > 0 {
+		list.FirstID = &list.Data[0].ID
+		list.LastID = &list.Data[len(list.Data)-1].ID
+	}
+
+	return list
+}
+
+func toError(err error) *errs.Error {
+	switch {
+	case errors.Is(err, filestore.ErrNotFound):
+		return errs.New(errs.NotFound, err)
+	case errors.Is(err, filestore.ErrTooLarge), errors.Is(err, filestore.ErrEmpty):
+		return errs.New(errs.InvalidArgument, err)
+	case errors.Is(err, filestore.ErrQuotaExceeded):
+		return errs.New(errs.ResourceExhausted, err)
+	default:
+		return errs.New(errs.Internal, err)
+	}
+}
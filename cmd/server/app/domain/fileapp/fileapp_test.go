@@ -0,0 +1,123 @@
+package fileapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+)
+
+func TestFilesLifecycle(t *testing.T) {
+	store, err := filestore.New(filestore.Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("should be able to construct store: %s", err)
+	}
+
+	a := &app{log: logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" }), store: store}
+
+	created, ok := a.create(t.Context(), uploadRequest(t, "user_data", "notes.txt", "hello")).(File)
+	if !ok {
+		t.Fatal("create: expected a file object")
+	}
+
+	if created.Object != "file" || created.Bytes != 5 || created.Filename != "notes.txt" || created.Purpose != "user_data" {
+		t.Errorf("create: got %+v", created)
+	}
+
+	list, ok := a.list(t.Context(), httptest.NewRequest(http.MethodGet, "/v1/files?purpose=user_data", nil)).(FileList)
+	if !ok || len(list.Data) != 1 || list.Data[0].ID != created.ID {
+		t.Fatalf("list: got %+v", list)
+	}
+
+	webApp := web.NewApp(func(context.Context, string, ...any) {})
+	webApp.HandlerFunc(http.MethodGet, "v1", "/files/{file_id}/content", a.content)
+
+	rr := httptest.NewRecorder()
+	webApp.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/v1/files/"+created.ID+"/content", nil))
+
+	if rr.Code != http.StatusOK || rr.Body.String() != "hello" || rr.Header().Get("Content-Type") != "text/plain; charset=utf-8" || rr.Header().Get("Content-Length") != "5" {
+		t.Errorf("content: got %d %q %q", rr.Code, rr.Body.String(), rr.Header())
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/files/"+created.ID, nil)
+	req.SetPathValue("file_id", created.ID)
+
+	data, _, _ := a.delete(t.Context(), req).Encode()
+
+	var deleted DeletedFile
+	if err := json.Unmarshal(data, &deleted); err != nil || !deleted.Deleted || deleted.ID != created.ID {
+		t.Errorf("delete: got %s", data)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/files/"+created.ID, nil)
+	req.SetPathValue("file_id", created.ID)
+
+	appErr, ok := a.retrieve(t.Context(), req).(*errs.Error)
+	if !ok || !appErr.Code.Equal(errs.NotFound) {
+		t.Errorf("retrieve deleted: got %v, want %s", appErr, errs.NotFound)
+	}
+}
+
+func TestCreateRejectsInvalidUploads(t *testing.T) {
+	store, err := filestore.New(filestore.Config{Dir: t.TempDir(), MaxBytes: 4})
+	if err != nil {
+		t.Fatalf("should be able to construct store: %s", err)
+	}
+
+	tests := []struct {
+		name    string
+		purpose string
+		content string
+	}{
+		{name: "missing purpose", content: "abc"},
+		{name: "unknown purpose", purpose: "training", content: "abc"},
+		{name: "empty file", purpose: "user_data"},
+		{name: "too large", purpose: "user_data", content: "12345"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &app{store: store}
+
+			appErr, ok := a.create(t.Context(), uploadRequest(t, tt.purpose, "a.txt", tt.content)).(*errs.Error)
+			if !ok {
+				t.Fatal("create: expected an error")
+			}
+			if !appErr.Code.Equal(errs.InvalidArgument) {
+				t.Errorf("Code: got %s, want %s", appErr.Code, errs.InvalidArgument)
+			}
+		})
+	}
+}
+
+func uploadRequest(t *testing.T, purpose string, filename string, content string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	if purpose != "" {
+		w.WriteField("purpose", purpose)
+	}
+
+	part, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("create form file: %s", err)
+	}
+	part.Write([]byte(content))
+	w.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/files", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	return req
+}
@@ -0,0 +1,64 @@
+package fileapp
+
+import (
+	"encoding/json"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+)
+
+// File is the OpenAI file object describing an uploaded file.
+type File struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+	Status    string `json:"status"`
+}
+
+// Encode implements web.Encoder.
+func (f File) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(f)
+	return data, "application/json", err
+}
+
+func toFile(f filestore.File) File {
+	return File{
+		ID:        f.ID,
+		Object:    "file",
+		Bytes:     f.Bytes,
+		CreatedAt: f.CreatedAt.Unix(),
+		Filename:  f.Filename,
+		Purpose:   f.Purpose,
+		Status:    "processed",
+	}
+}
+
+// FileList is a page of the caller's files.
+type FileList struct {
+	Object  string  `json:"object"`
+	Data    []File  `json:"data"`
+	FirstID *string `json:"first_id"`
+	LastID  *string `json:"last_id"`
+	HasMore bool    `json:"has_more"`
+}
+
+// Encode implements web.Encoder.
+func (l FileList) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(l)
+	return data, "application/json", err
+}
+
+// DeletedFile confirms that a file was deleted.
+type DeletedFile struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+}
+
+// Encode implements web.Encoder.
+func (d DeletedFile) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(d)
+	return data, "application/json", err
+}
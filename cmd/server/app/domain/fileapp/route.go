@@ -0,0 +1,35 @@
+package fileapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log               *logger.Logger
+	AuthClient        *authclient.Client
+	Store             *filestore.Store
+	AuthorizationMode auth.Mode
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	api := newApp(cfg)
+
+	inferenceAccess := mid.NewAccess(cfg.AuthClient, cfg.AuthorizationMode, false).Inference("files")
+
+	app.HandlerFunc(http.MethodPost, version, "/files", api.create, inferenceAccess)
+	app.HandlerFunc(http.MethodGet, version, "/files", api.list, inferenceAccess)
+	app.HandlerFunc(http.MethodGet, version, "/files/{file_id}", api.retrieve, inferenceAccess)
+	app.HandlerFunc(http.MethodGet, version, "/files/{file_id}/content", api.content, inferenceAccess)
+	app.HandlerFunc(http.MethodDelete, version, "/files/{file_id}", api.delete, inferenceAccess)
+}
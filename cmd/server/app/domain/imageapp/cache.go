@@ -0,0 +1,77 @@
+package imageapp
+
+import (
+	"sync"
+	"time"
+
+	"uuid"
+)
+
+// Generated images returned by URL are held in memory for an hour, the
+// same lifetime OpenAI gives its image URLs. The cache is bounded so a
+// burst of url requests cannot grow memory without limit.
+const (
+	imageURLTTL     = time.Hour
+	maxCachedImages = 100
+)
+
+type cachedImage struct {
+	png     []byte
+	expires time.Time
+}
+
+// imageCache holds generated images served by the url response format.
+type imageCache struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	images map[string]cachedImage
+	order  []string
+}
+
+func newImageCache(ttl time.Duration) *imageCache {
+	return &imageCache{
+		ttl:    ttl,
+		images: make(map[string]cachedImage),
+	}
+}
+
+// add stores the image and returns the id it can be retrieved by.
+func (c *imageCache) add(png []byte) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	// Ids are stored in insertion order, so expired images are always at
+	// the front.
+	for len(c.order) > 0 {
+		img, exists := c.images[c.order[0]]
+		if exists && now.Before(img.expires) && len(c.order) < maxCachedImages {
+			break
+		}
+		delete(c.images, c.order[0])
+		c.order = c.order[1:]
+	}
+
+	id := "img_" + uuid.New().String()
+	c.images[id] = cachedImage{
+		png:     png,
+		expires: now.Add(c.ttl),
+	}
+	c.order = append(c.order, id)
+
+	return id
+}
+
+// get returns the image stored under id if it has not expired.
+func (c *imageCache) get(id string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	img, exists := c.images[id]
+	if !exists || !time.Now().Before(img.expires) {
+		return nil, false
+	}
+
+	return img.png, true
+}
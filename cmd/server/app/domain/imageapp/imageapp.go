@@ -0,0 +1,327 @@
+// Package imageapp provides the image generation api endpoints.
+package imageapp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/kronk/observ/metrics"
+	"github.com/ardanlabs/kronk/sdk/malina/model"
+	"github.com/ardanlabs/kronk/sdk/pool"
+)
+
+// maxImages matches OpenAI's documented limit on n. maxUploadBytes bounds
+// the source image accepted by the edits endpoint; the request limit allows
+// a small amount of space for multipart headers and form fields.
+const (
+	maxImages            = 10
+	maxUploadBytes       = 25 << 20
+	maxMultipartOverhead = 1 << 20
+)
+
+type app struct {
+	log   *logger.Logger
+	pool  *pool.Pool
+	cache *imageCache
+}
+
+func newApp(cfg Config) *app {
+	return &app{
+		log:   cfg.Log,
+		pool:  cfg.Pool,
+		cache: newImageCache(imageURLTTL),
+	}
+}
+
+func (a *app) generations(ctx context.Context, r *http.Request) web.Encoder {
+	var req ImageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	params, n, err := toGenerateParams(req)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	a.log.Info(ctx, "image-generations", "model", req.Model, "n", n, "width", params.Width, "height", params.Height, "response-format", req.ResponseFormat)
+
+	return a.generate(ctx, r, "generation", req.Model, req.ResponseFormat, params, n)
+}
+
+func (a *app) edits(ctx context.Context, r *http.Request) web.Encoder {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxUploadBytes+maxMultipartOverhead)
+	if err := r.ParseMultipartForm(maxUploadBytes); err != nil {
+		return errs.New(errs.InvalidArgument, fmt.Errorf("parse multipart form: %w", err))
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	if _, exists := r.MultipartForm.File["mask"]; exists {
+		return errs.Errorf(errs.InvalidArgument, "mask is not supported")
+	}
+
+	field := "image"
+	if _, exists := r.MultipartForm.File["image[]"]; exists {
+		field = "image[]"
+	}
+
+	file, hdr, err := r.FormFile(field)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, fmt.Errorf("image form field: %w", err))
+	}
+	defer file.Close()
+
+	if hdr.Size > maxUploadBytes {
+		return errs.Errorf(errs.InvalidArgument, "image exceeds 25 MB limit")
+	}
+
+	src, _, err := image.Decode(file)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, fmt.Errorf("decode image: %w", err))
+	}
+
+	req, err := formImageRequest(r)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	// Unless the caller asks for a size, the edit keeps the dimensions of
+	// the source image.
+	if req.Size == "" || req.Size == "auto" {
+		bounds := src.Bounds()
+		req.Size = fmt.Sprintf("%dx%d", bounds.Dx(), bounds.Dy())
+	}
+
+	params, n, err := toGenerateParams(req)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	params.InitImage = src
+	if v := r.FormValue("strength"); v != "" {
+		strength, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return errs.Errorf(errs.InvalidArgument, "strength must be a number")
+		}
+		params.Strength = float32(strength)
+	}
+
+	a.log.Info(ctx, "image-edits", "model", req.Model, "n", n, "filename", hdr.Filename, "size", hdr.Size, "width", params.Width, "height", params.Height, "response-format", req.ResponseFormat)
+
+	return a.generate(ctx, r, "edit", req.Model, req.ResponseFormat, params, n)
+}
+
+func (a *app) file(ctx context.Context, r *http.Request) web.Encoder {
+	png, exists := a.cache.get(web.Param(r, "image_id"))
+	if !exists {
+		return errs.Errorf(errs.NotFound, "image not found or expired")
+	}
+
+	return pngResponse(png)
+}
+
+// =============================================================================
+
+// generate runs n generations on the requested model and encodes each image
+// in the requested response format.
+func (a *app) generate(ctx context.Context, r *http.Request, operation string, modelID string, respFmt string, params model.GenerateParams, n int) (resp web.Encoder) {
+	if err := params.Validate(); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if a.pool.Malina == nil {
+		return errs.Errorf(errs.FailedPrecondition, "image generation is not enabled on this server")
+	}
+
+	start := time.Now()
+	var images int
+	defer func() {
+		status := "ok"
+		if appErr, ok := resp.(*errs.Error); ok {
+			status = "error"
+			if appErr.Code == errs.Canceled {
+				status = "cancel"
+			}
+		}
+		metrics.ObserveImageRequest(modelID, operation, status, time.Since(start), images)
+	}()
+
+	if err := mid.AuthorizeModel(ctx, modelID); err != nil {
+		return err
+	}
+
+	m, err := a.pool.Malina.AquireModel(ctx, modelID)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	out := ImageResponse{
+		Created: time.Now().Unix(),
+		Data:    make([]ImageData, 0, n),
+	}
+
+	for i := range n {
+		p := params
+		if p.Seed >= 0 {
+			p.Seed += int64(i)
+		}
+
+		img, err := m.Generate(ctx, p)
+		if err != nil {
+			return errs.FromSDK(fmt.Errorf("generate: %w", err))
+		}
+
+		switch respFmt {
+		case "b64_json":
+			out.Data = append(out.Data, ImageData{B64JSON: base64.StdEncoding.EncodeToString(img.PNG)})
+		default:
+			out.Data = append(out.Data, ImageData{URL: imageURL(r, a.cache.add(img.PNG))})
+		}
+
+		images++
+	}
+
+	return out
+}
+
+// toGenerateParams validates the OpenAI request fields and returns the
+// generation parameters along with the number of images to generate.
+func toGenerateParams(req ImageRequest) (model.GenerateParams, int, error) {
+	if req.Model == "" {
+		return model.GenerateParams{}, 0, errors.New("missing model field")
+	}
+	if strings.TrimSpace(req.Prompt) == "" {
+		return model.GenerateParams{}, 0, errors.New("missing prompt field")
+	}
+
+	n := 1
+	if req.N != nil {
+		n = *req.N
+	}
+	if n < 1 || n > maxImages {
+		return model.GenerateParams{}, 0, fmt.Errorf("n must be between 1 and %d", maxImages)
+	}
+
+	switch req.ResponseFormat {
+	case "", "url", "b64_json":
+	default:
+		return model.GenerateParams{}, 0, fmt.Errorf("unsupported response_format[%s]", req.ResponseFormat)
+	}
+
+	params := model.NewGenerateParams()
+	params.Prompt = req.Prompt
+	params.NegativePrompt = req.NegativePrompt
+
+	if req.Size != "" && req.Size != "auto" {
+		width, height, err := parseSize(req.Size)
+		if err != nil {
+			return model.GenerateParams{}, 0, err
+		}
+		params.Width = width
+		params.Height = height
+	}
+
+	if req.Steps != nil {
+		params.Steps = *req.Steps
+	}
+	if req.CFGScale != nil {
+		params.CFGScale = *req.CFGScale
+	}
+	if req.Seed != nil {
+		params.Seed = *req.Seed
+	}
+
+	return params, n, nil
+}
+
+// formImageRequest reads the OpenAI fields of a multipart edits request.
+func formImageRequest(r *http.Request) (ImageRequest, error) {
+	req := ImageRequest{
+		Model:          r.FormValue("model"),
+		Prompt:         r.FormValue("prompt"),
+		Size:           r.FormValue("size"),
+		ResponseFormat: r.FormValue("response_format"),
+		NegativePrompt: r.FormValue("negative_prompt"),
+	}
+
+	if v := r.FormValue("n"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return ImageRequest{}, errors.New("n must be an integer")
+		}
+		req.N = &n
+	}
+
+	if v := r.FormValue("steps"); v != "" {
+		steps, err := strconv.Atoi(v)
+		if err != nil {
+			return ImageRequest{}, errors.New("steps must be an integer")
+		}
+		req.Steps = &steps
+	}
+
+	if v := r.FormValue("cfg_scale"); v != "" {
+		scale, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return ImageRequest{}, errors.New("cfg_scale must be a number")
+		}
+		req.CFGScale = new(float32(scale))
+	}
+
+	if v := r.FormValue("seed"); v != "" {
+		seed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return ImageRequest{}, errors.New("seed must be an integer")
+		}
+		req.Seed = &seed
+	}
+
+	return req, nil
+}
+
+// parseSize parses an OpenAI "WIDTHxHEIGHT" size.
+func parseSize(size string) (int, int, error) {
+	w, h, ok := strings.Cut(size, "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("size[%s] must be WIDTHxHEIGHT", size)
+	}
+
+	width, err := strconv.Atoi(w)
+	if err != nil {
+		return 0, 0, fmt.Errorf("size[%s] must be WIDTHxHEIGHT", size)
+	}
+
+	height, err := strconv.Atoi(h)
+	if err != nil {
+		return 0, 0, fmt.Errorf("size[%s] must be WIDTHxHEIGHT", size)
+	}
+
+	return width, height, nil
+}
+
+// imageURL returns the absolute URL a cached image is served from.
+func imageURL(r *http.Request, id string) string {
+	scheme := "http"
+	switch {
+	case r.Header.Get("X-Forwarded-Proto") != "":
+		scheme = r.Header.Get("X-Forwarded-Proto")
+	case r.TLS != nil:
+		scheme = "https"
+	}
+
+	return fmt.Sprintf("%s://%s/v1/images/files/%s", scheme, r.Host, id)
+}
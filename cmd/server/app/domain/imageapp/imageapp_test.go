@@ -0,0 +1,163 @@
+package imageapp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/sdk/pool"
+)
+
+func TestToGenerateParams(t *testing.T) {
+	n := 2
+	steps := 4
+	seed := int64(42)
+
+	params, gotN, err := toGenerateParams(ImageRequest{
+		Model:          "sd-1.5",
+		Prompt:         "a lighthouse",
+		N:              &n,
+		Size:           "768x512",
+		ResponseFormat: "b64_json",
+		NegativePrompt: "blurry",
+		Steps:          &steps,
+		Seed:           &seed,
+	})
+	if err != nil {
+		t.Fatalf("should be able to convert: %s", err)
+	}
+
+	if gotN != 2 {
+		t.Errorf("n: got %d, want 2", gotN)
+	}
+	if params.Width != 768 || params.Height != 512 {
+		t.Errorf("size: got %dx%d, want 768x512", params.Width, params.Height)
+	}
+	if params.Steps != 4 || params.Seed != 42 || params.NegativePrompt != "blurry" {
+		t.Errorf("params: got %+v", params)
+	}
+}
+
+func TestToGenerateParamsValidation(t *testing.T) {
+	zero := 0
+
+	tests := []struct {
+		name string
+		req  ImageRequest
+	}{
+		{name: "missing model", req: ImageRequest{Prompt: "p"}},
+		{name: "missing prompt", req: ImageRequest{Model: "m"}},
+		{name: "n out of range", req: ImageRequest{Model: "m", Prompt: "p", N: &zero}},
+		{name: "response format", req: ImageRequest{Model: "m", Prompt: "p", ResponseFormat: "gif"}},
+		{name: "size", req: ImageRequest{Model: "m", Prompt: "p", Size: "large"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := toGenerateParams(tt.req); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestGenerationsRejectsBeforeModelAcquisition(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want errs.ErrCode
+	}{
+		{name: "invalid dimensions", body: `{"model":"sd-1.5","prompt":"p","size":"100x100"}`, want: errs.InvalidArgument},
+		{name: "backend disabled", body: `{"model":"sd-1.5","prompt":"p"}`, want: errs.FailedPrecondition},
+	}
+
+	a := newTestApp()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/images/generations", strings.NewReader(tt.body))
+
+			appErr, ok := a.generations(t.Context(), req).(*errs.Error)
+			if !ok {
+				t.Fatal("generations: expected an *errs.Error")
+			}
+			if appErr.Code != tt.want {
+				t.Errorf("code: got %v, want %v", appErr.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditsRejectsMask(t *testing.T) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("model", "sd-1.5")
+	w.WriteField("prompt", "p")
+	part, _ := w.CreateFormFile("mask", "mask.png")
+	part.Write([]byte("mask"))
+	w.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/images/edits", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	a := newTestApp()
+
+	appErr, ok := a.edits(t.Context(), req).(*errs.Error)
+	if !ok {
+		t.Fatal("edits: expected an *errs.Error")
+	}
+	if appErr.Code != errs.InvalidArgument {
+		t.Errorf("code: got %v, want %v", appErr.Code, errs.InvalidArgument)
+	}
+}
+
+func TestImageCache(t *testing.T) {
+	c := newImageCache(time.Minute)
+
+	id := c.add([]byte("png"))
+
+	got, exists := c.get(id)
+	if !exists || string(got) != "png" {
+		t.Fatalf("get: got %q %t, want %q true", got, exists, "png")
+	}
+
+	for range maxCachedImages {
+		c.add([]byte("more"))
+	}
+
+	if _, exists := c.get(id); exists {
+		t.Error("oldest image should be evicted once the cache is full")
+	}
+
+	expired := newImageCache(-time.Second)
+	if _, exists := expired.get(expired.add([]byte("png"))); exists {
+		t.Error("expired image should not be returned")
+	}
+}
+
+func TestImageURL(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:11435/v1/images/generations", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+
+	got := imageURL(req, "img_1")
+	want := "https://localhost:11435/v1/images/files/img_1"
+	if got != want {
+		t.Errorf("url: got %q, want %q", got, want)
+	}
+}
+
+func newTestApp() *app {
+	return &app{
+		log:   logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" }),
+		pool:  &pool.Pool{},
+		cache: newImageCache(time.Minute),
+	}
+}
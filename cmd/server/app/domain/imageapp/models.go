@@ -0,0 +1,50 @@
+package imageapp
+
+import (
+	"encoding/json"
+)
+
+// ImageRequest represents the body of an image generation request. The
+// negative_prompt, steps, cfg_scale and seed fields are stable-diffusion
+// extensions to the OpenAI request.
+type ImageRequest struct {
+	Model          string   `json:"model"`
+	Prompt         string   `json:"prompt"`
+	N              *int     `json:"n"`
+	Size           string   `json:"size"`
+	ResponseFormat string   `json:"response_format"`
+	NegativePrompt string   `json:"negative_prompt"`
+	Steps          *int     `json:"steps"`
+	CFGScale       *float32 `json:"cfg_scale"`
+	Seed           *int64   `json:"seed"`
+	User           string   `json:"user"`
+}
+
+// ImageResponse represents the response for an image request.
+type ImageResponse struct {
+	Created int64       `json:"created"`
+	Data    []ImageData `json:"data"`
+}
+
+// Encode implements web.Encoder.
+func (r ImageResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// ImageData represents one generated image. Exactly one of B64JSON or URL
+// is set based on the requested response_format.
+type ImageData struct {
+	B64JSON string `json:"b64_json,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// =============================================================================
+
+// pngResponse implements web.Encoder for a generated image file.
+type pngResponse []byte
+
+// Encode implements web.Encoder.
+func (p pngResponse) Encode() ([]byte, string, error) {
+	return p, "image/png", nil
+}
@@ -0,0 +1,38 @@
+package imageapp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/pool"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log               *logger.Logger
+	AuthClient        *authclient.Client
+	Pool              *pool.Pool
+	AuthorizationMode auth.Mode
+	InferenceTimeout  time.Duration
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	api := newApp(cfg)
+
+	inferenceAccess := mid.NewAccess(cfg.AuthClient, cfg.AuthorizationMode, false).Inference("images")
+
+	app.HandlerFunc(http.MethodPost, version, "/images/generations", api.generations, mid.Timeout(cfg.InferenceTimeout), inferenceAccess)
+	app.HandlerFunc(http.MethodPost, version, "/images/edits", api.edits, mid.Timeout(cfg.InferenceTimeout), inferenceAccess)
+
+	// Generated image URLs are unguessable, short-lived capabilities so
+	// they can be used directly in an <img> tag without a bearer token.
+	app.HandlerFunc(http.MethodGet, version, "/images/files/{image_id}", api.file)
+}
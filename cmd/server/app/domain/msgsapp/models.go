@@ -23,6 +23,39 @@ type MessagesRequest struct {
 	TopP          *float64      `json:"top_p,omitempty"`
 	TopK          *int          `json:"top_k,omitempty"`
 	StopSequences []string      `json:"stop_sequences,omitempty"`
+	ToolChoice    *ToolChoice   `json:"tool_choice,omitempty"`
+	Thinking      *Thinking     `json:"thinking,omitempty"`
+	Metadata      *Metadata     `json:"metadata,omitempty"`
+}
+
+// ToolChoice controls how the model uses the provided tools.
+type ToolChoice struct {
+	Type                   string `json:"type"` // "auto", "any", "tool", "none"
+	Name                   string `json:"name,omitempty"`
+	DisableParallelToolUse bool   `json:"disable_parallel_tool_use,omitempty"`
+}
+
+// Thinking configures extended thinking.
+type Thinking struct {
+	Type         string `json:"type"` // "enabled", "disabled"
+	BudgetTokens int    `json:"budget_tokens,omitempty"`
+}
+
+// Metadata describes the request. It is accepted for compatibility and not
+// used by the model.
+type Metadata struct {
+	UserID string `json:"user_id,omitempty"`
+}
+
+// CountTokensResponse reports the number of input tokens a request uses.
+type CountTokensResponse struct {
+	InputTokens int `json:"input_tokens"`
+}
+
+// Encode implements web.Encoder.
+func (r CountTokensResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
 }
 
 // SystemContent can be a string or array of content blocks.
@@ -131,13 +164,18 @@ func (c *Content) UnmarshalJSON(data []byte) error {
 
 // ContentBlock represents a single content block in a message.
 type ContentBlock struct {
-	Type string `json:"type"` // "text", "image", "tool_use", "tool_result"
+	Type string `json:"type"` // "text", "image", "document", "tool_use", "tool_result", "thinking"
 
 	// Text block fields
 	Text string `json:"text,omitempty"`
 
-	// Image block fields
+	// Thinking block fields (in assistant messages)
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
+
+	// Image and document block fields
 	Source *ImageSource `json:"source,omitempty"`
+	Title  string       `json:"title,omitempty"`
 
 	// Tool use block fields (in assistant messages)
 	ID    string `json:"id,omitempty"`
@@ -149,12 +187,13 @@ type ContentBlock struct {
 	Content   string `json:"content,omitempty"`
 }
 
-// ImageSource represents an image source.
+// ImageSource represents the source of an image or document block.
 type ImageSource struct {
-	Type      string `json:"type"` // "base64" or "url"
+	Type      string `json:"type"` // "base64", "url", "text", or "file"
 	MediaType string `json:"media_type,omitempty"`
 	Data      string `json:"data,omitempty"`
 	URL       string `json:"url,omitempty"`
+	FileID    string `json:"file_id,omitempty"`
 }
 
 // Tool represents a tool definition.
@@ -203,13 +242,112 @@ func toOpenAI(req MessagesRequest) model.D {
 		d["top_p"] = *req.TopP
 	}
 	switch {
+	case req.TopK != nil:
+		d["top_k"] = *req.TopK
+	}
+	switch {
+	case len(req.StopSequences) > 0:
+		d["stop"] = req.StopSequences
+	}
+	switch {
 	case len(req.Tools) > 0:
 		d["tools"] = convertTools(req.Tools)
 	}
+	switch {
+	case req.ToolChoice != nil:
+		d["tool_choice"] = convertToolChoice(*req.ToolChoice)
+		switch {
+		case req.ToolChoice.DisableParallelToolUse:
+			d["parallel_tool_calls"] = false
+		}
+	}
+	switch {
+	case req.Thinking != nil:
+		switch req.Thinking.Type {
+		case "enabled":
+			d["enable_thinking"] = true
+			switch {
+			case req.Thinking.BudgetTokens > 0:
+				d["reasoning_effort"] = reasoningEffort(req.Thinking.BudgetTokens)
+			}
+
+		case "disabled":
+			d["enable_thinking"] = false
+		}
+	}
 
 	return d
 }
 
+// thinkingEnabled reports whether the request asks for thinking content
+// blocks in the response.
+func (req MessagesRequest) thinkingEnabled() bool {
+	return req.Thinking != nil && req.Thinking.Type == "enabled"
+}
+
+// validateExtensions checks the request fields that map onto optional chat
+// features.
+func (req MessagesRequest) validateExtensions() error {
+	switch {
+	case req.ToolChoice != nil:
+		switch req.ToolChoice.Type {
+		case "auto", "any", "none":
+		case "tool":
+			switch {
+			case req.ToolChoice.Name == "":
+				return fmt.Errorf("tool_choice name is required for type tool")
+			}
+		default:
+			return fmt.Errorf("unsupported tool_choice type %q", req.ToolChoice.Type)
+		}
+	}
+
+	switch {
+	case req.Thinking != nil:
+		switch req.Thinking.Type {
+		case "enabled":
+			switch {
+			case req.Thinking.BudgetTokens < 0:
+				return fmt.Errorf("thinking budget_tokens must not be negative")
+			case req.MaxTokens > 0 && req.Thinking.BudgetTokens >= req.MaxTokens:
+				return fmt.Errorf("thinking budget_tokens must be less than max_tokens")
+			}
+		case "disabled":
+		default:
+			return fmt.Errorf("unsupported thinking type %q", req.Thinking.Type)
+		}
+	}
+
+	return nil
+}
+
+// reasoningEffort maps a thinking token budget onto the reasoning effort
+// levels chat templates understand.
+func reasoningEffort(budgetTokens int) string {
+	switch {
+	case budgetTokens < 4096:
+		return model.ReasoningEffortLow
+	case budgetTokens < 16384:
+		return model.ReasoningEffortMedium
+	}
+	return model.ReasoningEffortHigh
+}
+
+func convertToolChoice(choice ToolChoice) any {
+	switch choice.Type {
+	case "any":
+		return "required"
+	case "tool":
+		return model.D{
+			"type": "function",
+			"function": model.D{
+				"name": choice.Name,
+			},
+		}
+	}
+	return choice.Type
+}
+
 func convertMessage(msg Message) []model.D {
 	// Simple text content - return single message
 	switch {
@@ -239,12 +377,19 @@ func convertMessage(msg Message) []model.D {
 }
 
 func convertAssistantMessage(blocks []ContentBlock) []model.D {
-	// Separate tool_use blocks from content blocks
+	// Separate tool_use and thinking blocks from content blocks
 	var contentBlocks []ContentBlock
 	var toolCalls []model.D
+	var reasoning strings.Builder
 
 	for _, block := range blocks {
 		switch block.Type {
+		case "thinking":
+			reasoning.WriteString(block.Thinking)
+
+		case "redacted_thinking":
+			// Redacted thinking is opaque to Kronk and is not replayed.
+
 		case "tool_use":
 			// Convert to OpenAI tool_call format
 			// Note: Arguments need to be JSON-encoded as a string per OpenAI spec
@@ -273,6 +418,11 @@ func convertAssistantMessage(blocks []ContentBlock) []model.D {
 		"role": "assistant",
 	}
 
+	switch {
+	case reasoning.Len() > 0:
+		msg["reasoning_content"] = reasoning.String()
+	}
+
 	// Add content if there are content blocks
 	switch {
 	case len(contentBlocks) > 0:
@@ -394,6 +544,41 @@ func convertContentBlocks(blocks []ContentBlock) []model.D {
 							"url": block.Source.URL,
 						},
 					})
+				case "file":
+					result = append(result, model.D{
+						"type": "file",
+						"file": model.D{"file_id": block.Source.FileID},
+					})
+				}
+			}
+
+		// Documents become chat file parts, which are resolved to extracted
+		// text before the request reaches the model.
+		case "document":
+			switch {
+			case block.Source != nil:
+				switch block.Source.Type {
+				case "base64":
+					result = append(result, model.D{
+						"type": "file",
+						"file": model.D{
+							"filename":  block.Title,
+							"file_data": fmt.Sprintf("data:%s;base64,%s", block.Source.MediaType, block.Source.Data),
+						},
+					})
+				case "text":
+					result = append(result, model.D{
+						"type": "text",
+						"text": block.Source.Data,
+					})
+				case "file":
+					result = append(result, model.D{
+						"type": "file",
+						"file": model.D{
+							"filename": block.Title,
+							"file_id":  block.Source.FileID,
+						},
+					})
 				}
 			}
 
@@ -449,11 +634,15 @@ func (r MessagesResponse) Encode() ([]byte, string, error) {
 
 // ResponseContentBlock represents a content block in the response.
 type ResponseContentBlock struct {
-	Type string `json:"type"` // "text", "tool_use"
+	Type string `json:"type"` // "text", "tool_use", "thinking"
 
 	// Text block
 	Text string `json:"text,omitempty"`
 
+	// Thinking block. Kronk does not sign thinking, so the signature is empty.
+	Thinking  *string `json:"thinking,omitempty"`
+	Signature *string `json:"signature,omitempty"`
+
 	// Tool use block
 	ID    string `json:"id,omitempty"`
 	Name  string `json:"name,omitempty"`
@@ -496,11 +685,15 @@ type ContentBlockStartEvent struct {
 
 // ContentBlockMetadata contains initial content block info.
 type ContentBlockMetadata struct {
-	Type string `json:"type"` // "text", "tool_use"
+	Type string `json:"type"` // "text", "tool_use", "thinking"
 
 	// For text blocks
 	Text string `json:"text,omitempty"`
 
+	// For thinking blocks
+	Thinking  *string `json:"thinking,omitempty"`
+	Signature *string `json:"signature,omitempty"`
+
 	// For tool_use blocks
 	ID    string `json:"id,omitempty"`
 	Name  string `json:"name,omitempty"`
@@ -516,11 +709,14 @@ type ContentBlockDeltaEvent struct {
 
 // ContentDelta represents the delta payload.
 type ContentDelta struct {
-	Type string `json:"type"` // "text_delta", "input_json_delta"
+	Type string `json:"type"` // "text_delta", "input_json_delta", "thinking_delta"
 
 	// For text_delta
 	Text string `json:"text,omitempty"`
 
+	// For thinking_delta
+	Thinking string `json:"thinking,omitempty"`
+
 	// For input_json_delta (tool arguments)
 	PartialJSON string `json:"partial_json,omitempty"`
 }
@@ -558,7 +754,7 @@ type MessageStopEvent struct {
 	Type string `json:"type"` // "message_stop"
 }
 
-func toMessagesResponse(resp model.ChatResponse) *MessagesResponse {
+func toMessagesResponse(resp model.ChatResponse, thinking bool) *MessagesResponse {
 	content := make([]ResponseContentBlock, 0)
 
 	switch {
@@ -566,6 +762,15 @@ func toMessagesResponse(resp model.ChatResponse) *MessagesResponse {
 		choice := resp.Choices[0]
 		switch {
 		case choice.Message != nil:
+			switch {
+			case thinking && choice.Message.Reasoning != "":
+				content = append(content, ResponseContentBlock{
+					Type:      "thinking",
+					Thinking:  new(choice.Message.Reasoning),
+					Signature: new(""),
+				})
+			}
+
 			switch {
 			case choice.Message.Content != "":
 				content = append(content, ResponseContentBlock{
@@ -586,7 +791,11 @@ func toMessagesResponse(resp model.ChatResponse) *MessagesResponse {
 	}
 
 	var stopReason string
+	var stopSequence *string
 	switch {
+	case len(resp.Choices) > 0 && resp.Choices[0].StopSequence != "":
+		stopReason = "stop_sequence"
+		stopSequence = new(resp.Choices[0].StopSequence)
 	case len(resp.Choices) > 0:
 		stopReason = toAnthropicStopReason(resp.Choices[0].FinishReason())
 	default:
@@ -603,13 +812,14 @@ func toMessagesResponse(resp model.ChatResponse) *MessagesResponse {
 	}
 
 	return &MessagesResponse{
-		ID:         resp.ID,
-		Type:       "message",
-		Role:       "assistant",
-		Content:    content,
-		Model:      resp.Model,
-		StopReason: stopReason,
-		Usage:      usage,
+		ID:           resp.ID,
+		Type:         "message",
+		Role:         "assistant",
+		Content:      content,
+		Model:        resp.Model,
+		StopReason:   stopReason,
+		StopSequence: stopSequence,
+		Usage:        usage,
 	}
 }
 
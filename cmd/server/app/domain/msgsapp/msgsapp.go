@@ -8,6 +8,9 @@ import (
 	"net/http"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mediafetch"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
 	"github.com/ardanlabs/kronk/sdk/kronk"
@@ -16,14 +19,18 @@ import (
 )
 
 type app struct {
-	log  *logger.Logger
-	pool *pool.Pool
+	log   *logger.Logger
+	pool  *pool.Pool
+	files *filestore.Store
+	media *mediafetch.Fetcher
 }
 
 func newApp(cfg Config) *app {
 	return &app{
-		log:  cfg.Log,
-		pool: cfg.Pool,
+		log:   cfg.Log,
+		pool:  cfg.Pool,
+		files: cfg.Files,
+		media: cfg.Media,
 	}
 }
 
@@ -40,12 +47,31 @@ func (a *app) messages(ctx context.Context, r *http.Request) web.Encoder {
 	if req.MaxTokens == 0 {
 		return errs.Errorf(errs.InvalidArgument, "missing max_tokens field")
 	}
-	if len(req.StopSequences) > 0 {
-		return errs.Errorf(errs.InvalidArgument, "stop_sequences is not supported")
-	}
 	if len(req.Messages) == 0 {
 		return errs.FromSDK(model.ErrMessagesMissing)
 	}
+	if err := req.validateExtensions(); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := mid.AuthorizeModel(ctx, req.Model); err != nil {
+		return err
+	}
+
+	if err := mid.CheckTokenBudget(ctx, req.Model); err != nil {
+		return err
+	}
+
+	d := toOpenAI(req)
+	kronk.ApplySessionHeader(r.Header, d)
+
+	if err := model.ResolveFileInputs(ctx, d, a.files.Lookup(mid.GetSubject(ctx))); err != nil {
+		return errs.FromSDK(err)
+	}
+
+	if err := model.ResolveMediaURLs(ctx, d, a.media.MediaFetcher()); err != nil {
+		return errs.FromSDK(err)
+	}
 
 	krn, err := a.pool.Kronk.AquireModel(ctx, req.Model)
 	if err != nil {
@@ -54,10 +80,8 @@ func (a *app) messages(ctx context.Context, r *http.Request) web.Encoder {
 
 	a.log.Info(ctx, "messages", "model", req.Model)
 
-	d := toOpenAI(req)
-
 	if req.Stream {
-		committed, err := a.handleStreaming(ctx, krn, d)
+		committed, err := a.handleStreaming(ctx, krn, d, req.thinkingEnabled())
 		if err != nil {
 			if committed {
 				return web.NewNoResponseError(errs.FromSDK(err))
@@ -73,16 +97,70 @@ func (a *app) messages(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.FromSDK(err)
 	}
 
+	if resp.Usage != nil {
+		mid.RecordTokenUsage(ctx, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	}
+
 	// Set anthropic-request-id header for API compatibility
 	w := web.GetWriter(ctx)
 	if w != nil {
 		w.Header().Set("anthropic-request-id", resp.ID)
 	}
 
-	return toMessagesResponse(resp)
+	return toMessagesResponse(resp, req.thinkingEnabled())
 }
 
-func (a *app) handleStreaming(ctx context.Context, krn *kronk.Kronk, d model.D) (bool, error) {
+func (a *app) countTokens(ctx context.Context, r *http.Request) web.Encoder {
+	var req MessagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if req.Model == "" {
+		return errs.Errorf(errs.InvalidArgument, "missing model field")
+	}
+	if len(req.Messages) == 0 {
+		return errs.FromSDK(model.ErrMessagesMissing)
+	}
+	if err := req.validateExtensions(); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := mid.AuthorizeModel(ctx, req.Model); err != nil {
+		return err
+	}
+
+	// The chat document carries no generation limits when only counting.
+	d := toOpenAI(req)
+	delete(d, "max_tokens")
+	delete(d, "stream")
+
+	if err := model.ResolveFileInputs(ctx, d, a.files.Lookup(mid.GetSubject(ctx))); err != nil {
+		return errs.FromSDK(err)
+	}
+
+	if err := model.ResolveMediaURLs(ctx, d, a.media.MediaFetcher()); err != nil {
+		return errs.FromSDK(err)
+	}
+
+	krn, err := a.pool.Kronk.AquireModel(ctx, req.Model)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	a.log.Info(ctx, "count-tokens", "model", req.Model)
+
+	resp, err := krn.Tokenize(ctx, d)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	return CountTokensResponse{
+		InputTokens: resp.Tokens,
+	}
+}
+
+func (a *app) handleStreaming(ctx context.Context, krn *kronk.Kronk, d model.D, thinking bool) (bool, error) {
 	w := web.GetWriter(ctx)
 
 	if !supportsResponseFlush(w) {
@@ -100,10 +178,17 @@ func (a *app) handleStreaming(ctx context.Context, krn *kronk.Kronk, d model.D)
 	w.Header().Set("Connection", "keep-alive")
 
 	state := streamState{
-		w: w,
+		w:        w,
+		thinking: thinking,
 	}
 	committed := false
 
+	// The usage of the last chunk seen is charged even when the stream ends
+	// early, since those tokens were generated.
+	defer func() {
+		mid.RecordTokenUsage(ctx, state.inputTokens, state.outputTokens)
+	}()
+
 	for resp := range ch {
 		if err := ctx.Err(); err != nil {
 			return committed, fmt.Errorf("chat-streaming-http: context canceled, do not send response: %w", err)
@@ -132,13 +217,17 @@ func (a *app) handleStreaming(ctx context.Context, krn *kronk.Kronk, d model.D)
 
 type streamState struct {
 	w            http.ResponseWriter
+	thinking     bool // Emit reasoning as thinking blocks.
 	messageID    string
 	started      bool
-	blockStarted bool
+	blockType    string // The type of the open block, empty when none is open.
 	blockIndex   int
+	toolIndex    int // The call streamed in the open tool_use block.
+	toolCallIDs  map[string]bool
 	inputTokens  int
 	outputTokens int
 	finishReason string
+	stopSequence string
 }
 
 func (s *streamState) processChunk(resp model.ChatResponse) error {
@@ -164,12 +253,19 @@ func (s *streamState) processChunk(resp model.ChatResponse) error {
 	choice := resp.Choices[0]
 
 	// Skip delta content on final chunk (FinishReason set) - it duplicates previous content
+	if s.thinking && choice.FinishReason() == "" && choice.Delta != nil && choice.Delta.Reasoning != "" {
+		if err := s.openBlock("thinking", "", ""); err != nil {
+			return err
+		}
+
+		if err := s.sendThinkingDelta(choice.Delta.Reasoning); err != nil {
+			return err
+		}
+	}
+
 	if choice.FinishReason() == "" && choice.Delta != nil && choice.Delta.Content != "" {
-		if !s.blockStarted {
-			if err := s.sendContentBlockStart("text", "", ""); err != nil {
-				return err
-			}
-			s.blockStarted = true
+		if err := s.openBlock("text", "", ""); err != nil {
+			return err
 		}
 
 		if err := s.sendTextDelta(choice.Delta.Content); err != nil {
@@ -177,22 +273,36 @@ func (s *streamState) processChunk(resp model.ChatResponse) error {
 		}
 	}
 
-	if choice.Delta != nil && len(choice.Delta.ToolCalls) > 0 {
-		for _, tc := range choice.Delta.ToolCalls {
-			if s.blockStarted {
-				if err := s.sendContentBlockStop(); err != nil {
+	if choice.Delta != nil {
+		for _, delta := range choice.Delta.ToolCallDeltas {
+			if delta.ID != "" && !s.toolCallIDs[delta.ID] {
+				if err := s.startToolUseBlock(delta.ID, delta.Function.Name, delta.Index); err != nil {
 					return err
 				}
+			}
 
-				s.blockIndex++
-				s.blockStarted = false
+			// Arguments can only extend the open block. A fragment for a call
+			// whose block has closed cannot be delivered.
+			if delta.Function.Arguments == "" || s.blockType != "tool_use" || s.toolIndex != delta.Index {
+				continue
 			}
 
-			if err := s.sendContentBlockStart("tool_use", tc.ID, tc.Function.Name); err != nil {
+			if err := s.sendInputJSONDelta(delta.Function.Arguments); err != nil {
 				return err
 			}
+		}
+	}
 
-			s.blockStarted = true
+	// Tool calls that were not streamed arrive only in the final message.
+	if choice.Message != nil {
+		for _, tc := range choice.Message.ToolCalls {
+			if s.toolCallIDs[tc.ID] {
+				continue
+			}
+
+			if err := s.startToolUseBlock(tc.ID, tc.Function.Name, -1); err != nil {
+				return err
+			}
 
 			// Marshal the underlying map directly to avoid double-encoding.
 			// ToolCallArguments.MarshalJSON() wraps as JSON string per OpenAI spec,
@@ -213,19 +323,66 @@ func (s *streamState) processChunk(resp model.ChatResponse) error {
 	// for every request (which masked tool_use completions).
 	if fr := choice.FinishReason(); fr != "" {
 		s.finishReason = fr
+		s.stopSequence = choice.StopSequence
+	}
+
+	return nil
+}
+
+// openBlock makes a block of blockType the open block. An open block of the
+// same type is extended, except a tool_use block which always starts anew.
+func (s *streamState) openBlock(blockType string, toolID string, toolName string) error {
+	if s.blockType == blockType && blockType != "tool_use" {
+		return nil
+	}
+
+	if s.blockType != "" {
+		if err := s.sendContentBlockStop(); err != nil {
+			return err
+		}
+
+		s.blockIndex++
+		s.blockType = ""
+	}
+
+	if err := s.sendContentBlockStart(blockType, toolID, toolName); err != nil {
+		return err
 	}
 
+	s.blockType = blockType
+
+	return nil
+}
+
+// startToolUseBlock closes the open content block and starts a tool_use block
+// for the call streamed at index.
+func (s *streamState) startToolUseBlock(id string, name string, index int) error {
+	if err := s.openBlock("tool_use", id, name); err != nil {
+		return err
+	}
+
+	if s.toolCallIDs == nil {
+		s.toolCallIDs = make(map[string]bool)
+	}
+	s.toolCallIDs[id] = true
+	s.toolIndex = index
+
 	return nil
 }
 
 func (s *streamState) finish() error {
-	if s.blockStarted {
+	if s.blockType != "" {
 		if err := s.sendContentBlockStop(); err != nil {
 			return err
 		}
 	}
 
-	if err := s.sendMessageDelta(toAnthropicStopReason(s.finishReason)); err != nil {
+	stopReason := toAnthropicStopReason(s.finishReason)
+	if s.stopSequence != "" {
+		stopReason = "stop_sequence"
+	}
+
+	if err := s.sendMessageDelta(stopReason); err != nil {
 		return err
 	}
 
@@ -341,6 +498,10 @@ func (s *streamState) sendContentBlockStart(blockType, toolID, toolName string)
 	case "text":
 		event.ContentBlock.Text = ""
 
+	case "thinking":
+		event.ContentBlock.Thinking = new("")
+		event.ContentBlock.Signature = new("")
+
 	case "tool_use":
 		event.ContentBlock.ID = toolID
 		event.ContentBlock.Name = toolName
@@ -363,6 +524,19 @@ func (s *streamState) sendTextDelta(text string) error {
 	return s.sendEvent("content_block_delta", event)
 }
 
+func (s *streamState) sendThinkingDelta(thinking string) error {
+	event := ContentBlockDeltaEvent{
+		Type:  "content_block_delta",
+		Index: s.blockIndex,
+		Delta: ContentDelta{
+			Type:     "thinking_delta",
+			Thinking: thinking,
+		},
+	}
+
+	return s.sendEvent("content_block_delta", event)
+}
+
 func (s *streamState) sendInputJSONDelta(partialJSON string) error {
 	event := ContentBlockDeltaEvent{
 		Type:  "content_block_delta",
@@ -397,6 +571,10 @@ func (s *streamState) sendMessageDelta(stopReason string) error {
 		},
 	}
 
+	if s.stopSequence != "" {
+		event.Delta.StopSequence = new(s.stopSequence)
+	}
+
 	return s.sendEvent("message_delta", event)
 }
 
@@ -1,15 +1,19 @@
 package msgsapp
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
 	"github.com/ardanlabs/kronk/sdk/kronk/model"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestMessagesRejectsMissingMessagesBeforeModelAcquisition(t *testing.T) {
@@ -25,19 +29,49 @@ func TestMessagesRejectsMissingMessagesBeforeModelAcquisition(t *testing.T) {
 	}
 }
 
-func TestMessagesRejectsStopSequencesBeforeModelAcquisition(t *testing.T) {
-	req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(`{"model":"test","max_tokens":1,"messages":[{"role":"user","content":"hello"}],"stop_sequences":["END"]}`))
-
-	resp := (&app{}).messages(t.Context(), req)
-	appErr, ok := resp.(*errs.Error)
-	if !ok {
-		t.Fatalf("messages: got %T, want *errs.Error", resp)
-	}
-	if !appErr.Code.Equal(errs.InvalidArgument) {
-		t.Errorf("Code: got %s, want %s", appErr.Code, errs.InvalidArgument)
+func TestMessagesRejectsInvalidExtensionsBeforeModelAcquisition(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "tool choice type",
+			body: `"tool_choice":{"type":"function"}`,
+			want: `unsupported tool_choice type "function"`,
+		},
+		{
+			name: "tool choice name",
+			body: `"tool_choice":{"type":"tool"}`,
+			want: "tool_choice name is required for type tool",
+		},
+		{
+			name: "thinking budget",
+			body: `"thinking":{"type":"enabled","budget_tokens":64}`,
+			want: "thinking budget_tokens must be less than max_tokens",
+		},
 	}
-	if got, want := appErr.Message, "stop_sequences is not supported"; got != want {
-		t.Errorf("Message: got %q, want %q", got, want)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"model":"test","max_tokens":32,"messages":[{"role":"user","content":"hello"}],` + tt.body + `}`
+
+			for _, handler := range []func(*app, context.Context, *http.Request) web.Encoder{(*app).messages, (*app).countTokens} {
+				req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
+
+				resp := handler(&app{}, t.Context(), req)
+				appErr, ok := resp.(*errs.Error)
+				if !ok {
+					t.Fatalf("handler: got %T, want *errs.Error", resp)
+				}
+				if !appErr.Code.Equal(errs.InvalidArgument) {
+					t.Errorf("Code: got %s, want %s", appErr.Code, errs.InvalidArgument)
+				}
+				if appErr.Message != tt.want {
+					t.Errorf("Message: got %q, want %q", appErr.Message, tt.want)
+				}
+			}
+		})
 	}
 }
 
@@ -73,6 +107,173 @@ func TestStreamStateSendEventReportsTransportErrors(t *testing.T) {
 	}
 }
 
+func TestStreamStateStreamsToolUseInput(t *testing.T) {
+	deltaResp := func(delta model.ResponseToolCallDelta) model.ChatResponse {
+		return model.ChatResponse{ID: "msg-1", Choices: []model.Choice{{
+			Delta: &model.ResponseMessage{ToolCallDeltas: []model.ResponseToolCallDelta{delta}},
+		}}}
+	}
+
+	finishReason := model.FinishReasonTool
+	chunks := []model.ChatResponse{
+		{ID: "msg-1", Choices: []model.Choice{{Delta: &model.ResponseMessage{Content: "Checking."}}}},
+		deltaResp(model.ResponseToolCallDelta{ID: "call-1", Type: "function", Function: model.ResponseToolCallDeltaFunction{Name: "get_weather"}}),
+		deltaResp(model.ResponseToolCallDelta{Function: model.ResponseToolCallDeltaFunction{Arguments: `{"location": `}}),
+		deltaResp(model.ResponseToolCallDelta{Function: model.ResponseToolCallDeltaFunction{Arguments: `"Paris"}`}}),
+		{ID: "msg-1", Choices: []model.Choice{{
+			Delta: &model.ResponseMessage{},
+			Message: &model.ResponseMessage{ToolCalls: []model.ResponseToolCall{
+				{ID: "call-1", Type: "function", Function: model.ResponseToolCallFunction{Name: "get_weather", Arguments: model.ToolCallArguments{"location": "Paris"}}},
+				{ID: "call-2", Type: "function", Function: model.ResponseToolCallFunction{Name: "get_time", Arguments: model.ToolCallArguments{}}},
+			}},
+			FinishReasonPtr: &finishReason,
+		}}},
+	}
+
+	w := httptest.NewRecorder()
+	state := streamState{w: w}
+	for _, chunk := range chunks {
+		if err := state.processChunk(chunk); err != nil {
+			t.Fatalf("processChunk: %v", err)
+		}
+	}
+	if err := state.finish(); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+
+	got := streamEvents(t, w.Body.String())
+
+	want := []string{
+		"start 0 text ",
+		"delta 0 Checking.",
+		"stop 0",
+		"start 1 tool_use call-1",
+		`delta 1 {"location": `,
+		`delta 1 "Paris"}`,
+		"stop 1",
+		"start 2 tool_use call-2",
+		"delta 2 {}",
+		"stop 2",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("events:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestStreamStateStreamsThinking(t *testing.T) {
+	finishReason := model.FinishReasonStop
+	chunks := []model.ChatResponse{
+		{ID: "msg-1", Choices: []model.Choice{{Delta: &model.ResponseMessage{Reasoning: "Let me "}}}},
+		{ID: "msg-1", Choices: []model.Choice{{Delta: &model.ResponseMessage{Reasoning: "think."}}}},
+		{ID: "msg-1", Choices: []model.Choice{{Delta: &model.ResponseMessage{Content: "Paris"}}}},
+		{ID: "msg-1", Choices: []model.Choice{{
+			Delta:           &model.ResponseMessage{},
+			Message:         &model.ResponseMessage{Reasoning: "Let me think.", Content: "Paris"},
+			FinishReasonPtr: &finishReason,
+			StopSequence:    "END",
+		}}},
+	}
+
+	tests := []struct {
+		name     string
+		thinking bool
+		want     []string
+	}{
+		{
+			name:     "enabled",
+			thinking: true,
+			want: []string{
+				"start 0 thinking ",
+				"delta 0 Let me ",
+				"delta 0 think.",
+				"stop 0",
+				"start 1 text ",
+				"delta 1 Paris",
+				"stop 1",
+				"message stop_sequence END",
+			},
+		},
+		{
+			name: "not requested",
+			want: []string{
+				"start 0 text ",
+				"delta 0 Paris",
+				"stop 0",
+				"message stop_sequence END",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			state := streamState{w: w, thinking: tt.thinking}
+			for _, chunk := range chunks {
+				if err := state.processChunk(chunk); err != nil {
+					t.Fatalf("processChunk: %v", err)
+				}
+			}
+			if err := state.finish(); err != nil {
+				t.Fatalf("finish: %v", err)
+			}
+
+			got := streamEvents(t, w.Body.String())
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("events:\ngot  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// streamEvents summarizes the content block and message delta events of an
+// SSE body, one line per event.
+func streamEvents(t *testing.T, body string) []string {
+	t.Helper()
+
+	var got []string
+	for line := range strings.SplitSeq(body, "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+
+		var event struct {
+			Type         string `json:"type"`
+			Index        int    `json:"index"`
+			ContentBlock struct {
+				Type string `json:"type"`
+				ID   string `json:"id"`
+			} `json:"content_block"`
+			Delta struct {
+				Type         string `json:"type"`
+				Text         string `json:"text"`
+				Thinking     string `json:"thinking"`
+				PartialJSON  string `json:"partial_json"`
+				StopReason   string `json:"stop_reason"`
+				StopSequence string `json:"stop_sequence"`
+			} `json:"delta"`
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+
+		switch event.Type {
+		case "content_block_start":
+			got = append(got, fmt.Sprintf("start %d %s %s", event.Index, event.ContentBlock.Type, event.ContentBlock.ID))
+		case "content_block_delta":
+			got = append(got, fmt.Sprintf("delta %d %s%s%s", event.Index, event.Delta.Text, event.Delta.Thinking, event.Delta.PartialJSON))
+		case "content_block_stop":
+			got = append(got, fmt.Sprintf("stop %d", event.Index))
+		case "message_delta":
+			if event.Delta.StopSequence != "" {
+				got = append(got, fmt.Sprintf("message %s %s", event.Delta.StopReason, event.Delta.StopSequence))
+			}
+		}
+	}
+
+	return got
+}
+
 type eventResponseWriter struct {
 	header   http.Header
 	writeErr error
@@ -104,6 +305,134 @@ func TestToOpenAIMaxTokens(t *testing.T) {
 	}
 }
 
+func TestToOpenAIExtensions(t *testing.T) {
+	topK := 20
+
+	tests := []struct {
+		name string
+		req  MessagesRequest
+		want model.D
+	}{
+		{
+			name: "tool choice any",
+			req:  MessagesRequest{ToolChoice: &ToolChoice{Type: "any", DisableParallelToolUse: true}},
+			want: model.D{"tool_choice": "required", "parallel_tool_calls": false},
+		},
+		{
+			name: "tool choice tool",
+			req:  MessagesRequest{ToolChoice: &ToolChoice{Type: "tool", Name: "get_weather"}},
+			want: model.D{"tool_choice": model.D{"type": "function", "function": model.D{"name": "get_weather"}}},
+		},
+		{
+			name: "tool choice none",
+			req:  MessagesRequest{ToolChoice: &ToolChoice{Type: "none"}},
+			want: model.D{"tool_choice": "none"},
+		},
+		{
+			name: "thinking enabled",
+			req:  MessagesRequest{Thinking: &Thinking{Type: "enabled", BudgetTokens: 8192}},
+			want: model.D{"enable_thinking": true, "reasoning_effort": model.ReasoningEffortMedium},
+		},
+		{
+			name: "thinking disabled",
+			req:  MessagesRequest{Thinking: &Thinking{Type: "disabled"}},
+			want: model.D{"enable_thinking": false},
+		},
+		{
+			name: "sampling and stops",
+			req:  MessagesRequest{TopK: &topK, StopSequences: []string{"END"}},
+			want: model.D{"top_k": 20, "stop": []string{"END"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := toOpenAI(tt.req)
+
+			for key, want := range tt.want {
+				if diff := cmp.Diff(want, d[key]); diff != "" {
+					t.Errorf("%s mismatch (-want +got):\n%s", key, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertAssistantThinking(t *testing.T) {
+	got := convertAssistantMessage([]ContentBlock{
+		{Type: "thinking", Thinking: "It is sunny.", Signature: "sig"},
+		{Type: "redacted_thinking"},
+		{Type: "text", Text: "Sunny."},
+	})
+
+	want := []model.D{{"role": "assistant", "reasoning_content": "It is sunny.", "content": "Sunny."}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("message mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConvertContentBlocksFilesAndDocuments(t *testing.T) {
+	got := convertContentBlocks([]ContentBlock{
+		{Type: "image", Source: &ImageSource{Type: "file", FileID: "file-1"}},
+		{Type: "document", Title: "report.pdf", Source: &ImageSource{Type: "file", FileID: "file-2"}},
+		{Type: "document", Title: "memo.pdf", Source: &ImageSource{Type: "base64", MediaType: "application/pdf", Data: "JVBERi0="}},
+		{Type: "document", Source: &ImageSource{Type: "text", MediaType: "text/plain", Data: "plain text"}},
+	})
+
+	want := []model.D{
+		{"type": "file", "file": model.D{"file_id": "file-1"}},
+		{"type": "file", "file": model.D{"filename": "report.pdf", "file_id": "file-2"}},
+		{"type": "file", "file": model.D{"filename": "memo.pdf", "file_data": "data:application/pdf;base64,JVBERi0="}},
+		{"type": "text", "text": "plain text"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("content mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMessagesRejectsUnresolvedFilesBeforeModelAcquisition(t *testing.T) {
+	body := `{"model":"test","max_tokens":32,"messages":[{"role":"user","content":[{"type":"document","source":{"type":"base64","media_type":"application/octet-stream","data":"AP7/gA=="}}]}]}`
+
+	for _, handler := range []func(*app, context.Context, *http.Request) web.Encoder{(*app).messages, (*app).countTokens} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body))
+
+		resp := handler(&app{}, t.Context(), req)
+		appErr, ok := resp.(*errs.Error)
+		if !ok {
+			t.Fatalf("handler: got %T, want *errs.Error", resp)
+		}
+		if !appErr.Code.Equal(errs.InvalidArgument) {
+			t.Errorf("Code: got %s, want %s", appErr.Code, errs.InvalidArgument)
+		}
+	}
+}
+
+func TestToMessagesResponseThinkingAndStopSequence(t *testing.T) {
+	finishReason := model.FinishReasonStop
+	resp := model.ChatResponse{
+		Choices: []model.Choice{
+			{
+				Message:         &model.ResponseMessage{Reasoning: "Hmm.", Content: "Paris"},
+				FinishReasonPtr: &finishReason,
+				StopSequence:    "END",
+			},
+		},
+	}
+
+	got := toMessagesResponse(resp, true)
+
+	if len(got.Content) != 2 || got.Content[0].Type != "thinking" || *got.Content[0].Thinking != "Hmm." {
+		t.Fatalf("content: got %+v, want thinking then text", got.Content)
+	}
+	if got.StopReason != "stop_sequence" || got.StopSequence == nil || *got.StopSequence != "END" {
+		t.Errorf("stop: got %q %v, want stop_sequence END", got.StopReason, got.StopSequence)
+	}
+
+	if got := toMessagesResponse(resp, false); len(got.Content) != 1 {
+		t.Errorf("content without thinking: got %+v, want text only", got.Content)
+	}
+}
+
 func TestToMessagesResponseToolInputIsObject(t *testing.T) {
 	resp := model.ChatResponse{
 		Choices: []model.Choice{
@@ -125,7 +454,7 @@ func TestToMessagesResponseToolInputIsObject(t *testing.T) {
 		},
 	}
 
-	data, err := json.Marshal(toMessagesResponse(resp))
+	data, err := json.Marshal(toMessagesResponse(resp, false))
 	if err != nil {
 		t.Fatalf("marshal response: %v", err)
 	}
@@ -0,0 +1,797 @@
+package ollamaapp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+	"github.com/ardanlabs/kronk/sdk/tools/models"
+)
+
+// latestTag is the tag Ollama clients append to model names without one.
+const latestTag = ":latest"
+
+// toModelID returns the Kronk model id for an Ollama model name.
+func toModelID(name string) string {
+	return strings.TrimSuffix(name, latestTag)
+}
+
+// toModelName returns the Ollama model name for a Kronk model id.
+func toModelName(id string) string {
+	return id + latestTag
+}
+
+// digest returns a stable digest for a model id. Kronk models have no
+// manifest, so the digest identifies the model rather than its content.
+func digest(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+// =============================================================================
+// Request Types
+
+// ChatRequest represents an Ollama chat request.
+type ChatRequest struct {
+	Model     string          `json:"model"`
+	Messages  []Message       `json:"messages"`
+	Tools     []model.D       `json:"tools,omitempty"`
+	Format    json.RawMessage `json:"format,omitempty"`
+	Options   map[string]any  `json:"options,omitempty"`
+	Stream    *bool           `json:"stream,omitempty"`
+	Think     any             `json:"think,omitempty"`
+	KeepAlive any             `json:"keep_alive,omitempty"`
+}
+
+// GenerateRequest represents an Ollama generate request.
+type GenerateRequest struct {
+	Model     string          `json:"model"`
+	Prompt    string          `json:"prompt"`
+	Suffix    string          `json:"suffix,omitempty"`
+	System    string          `json:"system,omitempty"`
+	Template  string          `json:"template,omitempty"`
+	Images    []string        `json:"images,omitempty"`
+	Raw       bool            `json:"raw,omitempty"`
+	Format    json.RawMessage `json:"format,omitempty"`
+	Options   map[string]any  `json:"options,omitempty"`
+	Stream    *bool           `json:"stream,omitempty"`
+	Think     any             `json:"think,omitempty"`
+	KeepAlive any             `json:"keep_alive,omitempty"`
+}
+
+// EmbedRequest represents an Ollama embed request.
+type EmbedRequest struct {
+	Model      string         `json:"model"`
+	Input      any            `json:"input"`
+	Truncate   *bool          `json:"truncate,omitempty"`
+	Dimensions int            `json:"dimensions,omitempty"`
+	Options    map[string]any `json:"options,omitempty"`
+	KeepAlive  any            `json:"keep_alive,omitempty"`
+}
+
+// ShowRequest represents an Ollama show request. Name is the field used by
+// older clients.
+type ShowRequest struct {
+	Model   string `json:"model"`
+	Name    string `json:"name"`
+	Verbose bool   `json:"verbose,omitempty"`
+}
+
+// PullRequest represents an Ollama pull request. Name is the field used by
+// older clients.
+type PullRequest struct {
+	Model    string `json:"model"`
+	Name     string `json:"name"`
+	Insecure bool   `json:"insecure,omitempty"`
+	Stream   *bool  `json:"stream,omitempty"`
+}
+
+// Message represents a message in an Ollama conversation.
+type Message struct {
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	Thinking  string     `json:"thinking,omitempty"`
+	Images    []string   `json:"images,omitempty"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	ToolName  string     `json:"tool_name,omitempty"`
+}
+
+// ToolCall represents a tool call made by the model.
+type ToolCall struct {
+	Function ToolCallFunction `json:"function"`
+}
+
+// ToolCallFunction is the function of a tool call. Arguments is a JSON
+// object.
+type ToolCallFunction struct {
+	Index     int             `json:"index,omitempty"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// stream reports whether a response is streamed, which is the default.
+func stream(s *bool) bool {
+	return s == nil || *s
+}
+
+// =============================================================================
+// Request Conversion
+
+// optionParams maps the Ollama options to the Kronk params they set. Options
+// that configure a model when it loads, such as num_ctx, num_gpu and
+// num_thread, come from the model config in Kronk and are ignored, as are
+// sampling options Kronk does not implement.
+var optionParams = map[string]string{
+	"num_predict":       "max_tokens",
+	"temperature":       "temperature",
+	"top_k":             "top_k",
+	"top_p":             "top_p",
+	"min_p":             "min_p",
+	"repeat_penalty":    "repeat_penalty",
+	"repeat_last_n":     "repeat_last_n",
+	"presence_penalty":  "presence_penalty",
+	"frequency_penalty": "frequency_penalty",
+	"seed":              "seed",
+	"stop":              "stop",
+}
+
+// toChatDocument converts an Ollama chat request into a chat completions
+// document.
+func toChatDocument(req ChatRequest) (model.D, error) {
+	messages, err := toChatMessages(req.Messages)
+	if err != nil {
+		return nil, err
+	}
+
+	d := model.D{
+		"model":    toModelID(req.Model),
+		"messages": messages,
+	}
+
+	if len(req.Tools) > 0 {
+		d["tools"] = req.Tools
+	}
+
+	if err := applyParams(d, req.Options, req.Format, req.Think); err != nil {
+		return nil, err
+	}
+
+	if stream(req.Stream) {
+		d["stream"] = true
+		d["stream_options"] = model.D{"include_usage": true}
+	}
+
+	return model.MapToModelD(d), nil
+}
+
+// toGenerateDocument converts an Ollama generate request. A raw request or a
+// request with a suffix becomes a raw completion document and reports true;
+// otherwise the prompt is sent as a user turn through the chat template.
+func toGenerateDocument(req GenerateRequest) (model.D, bool, error) {
+	if req.Template != "" {
+		return nil, false, errors.New("template is not supported, the model's chat template is used")
+	}
+
+	raw := req.Raw || req.Suffix != ""
+
+	d := model.D{
+		"model": toModelID(req.Model),
+	}
+
+	switch {
+	case raw:
+		if len(req.Images) > 0 {
+			return nil, false, errors.New("images are not supported with raw or suffix")
+		}
+
+		d["prompt"] = req.Prompt
+		if req.Suffix != "" {
+			d["suffix"] = req.Suffix
+		}
+
+	default:
+		var messages []model.D
+		if req.System != "" {
+			messages = append(messages, model.D{"role": "system", "content": req.System})
+		}
+		messages = append(messages, model.D{"role": "user", "content": toContent(req.Prompt, req.Images)})
+
+		d["messages"] = messages
+	}
+
+	if err := applyParams(d, req.Options, req.Format, req.Think); err != nil {
+		return nil, false, err
+	}
+
+	if stream(req.Stream) {
+		d["stream"] = true
+		d["stream_options"] = model.D{"include_usage": true}
+	}
+
+	return model.MapToModelD(d), raw, nil
+}
+
+// toEmbedDocument converts an Ollama embed request into an embeddings
+// document. Inputs are truncated to the context window unless truncate is
+// false, matching Ollama.
+func toEmbedDocument(req EmbedRequest) (model.D, error) {
+	switch input := req.Input.(type) {
+	case string:
+		if input == "" {
+			return nil, errors.New("missing input field")
+		}
+
+	case []any:
+		if len(input) == 0 {
+			return nil, errors.New("missing input field")
+		}
+		for i, item := range input {
+			if _, ok := item.(string); !ok {
+				return nil, fmt.Errorf("input[%d] must be a string", i)
+			}
+		}
+
+	case nil:
+		return nil, errors.New("missing input field")
+
+	default:
+		return nil, errors.New("input must be a string or an array of strings")
+	}
+
+	d := model.D{
+		"model":    toModelID(req.Model),
+		"input":    req.Input,
+		"truncate": req.Truncate == nil || *req.Truncate,
+	}
+
+	if req.Dimensions > 0 {
+		d["dimensions"] = float64(req.Dimensions)
+	}
+
+	return d, nil
+}
+
+// toChatMessages converts Ollama messages into chat completions messages.
+// Ollama tool results name the tool rather than the call, so each tool
+// message is linked to the first unanswered call of that tool made by the
+// preceding assistant message.
+func toChatMessages(msgs []Message) ([]model.D, error) {
+	type call struct {
+		id   string
+		name string
+	}
+
+	messages := make([]model.D, 0, len(msgs))
+	var pending []call
+
+	for i, msg := range msgs {
+		m := model.D{
+			"role":    msg.Role,
+			"content": toContent(msg.Content, msg.Images),
+		}
+
+		switch msg.Role {
+		case "assistant":
+			if msg.Thinking != "" {
+				m["reasoning_content"] = msg.Thinking
+			}
+
+			pending = pending[:0]
+			if len(msg.ToolCalls) == 0 {
+				break
+			}
+
+			toolCalls := make([]model.D, len(msg.ToolCalls))
+			for j, tc := range msg.ToolCalls {
+				args, err := toolArguments(tc.Function.Arguments)
+				if err != nil {
+					return nil, fmt.Errorf("messages[%d].tool_calls[%d]: %w", i, j, err)
+				}
+
+				id := fmt.Sprintf("call_%d_%d", i, j)
+				pending = append(pending, call{id: id, name: tc.Function.Name})
+
+				toolCalls[j] = model.D{
+					"id":   id,
+					"type": "function",
+					"function": model.D{
+						"name":      tc.Function.Name,
+						"arguments": args,
+					},
+				}
+			}
+			m["tool_calls"] = toolCalls
+
+		case "tool":
+			if msg.ToolName != "" {
+				m["name"] = msg.ToolName
+			}
+
+			idx := slices.IndexFunc(pending, func(c call) bool {
+				return msg.ToolName == "" || c.name == msg.ToolName
+			})
+			if idx >= 0 {
+				m["tool_call_id"] = pending[idx].id
+				pending = slices.Delete(pending, idx, idx+1)
+			}
+		}
+
+		messages = append(messages, m)
+	}
+
+	return messages, nil
+}
+
+// toContent returns the content of a message. Images are placed before the
+// text, which is the order most multimodal templates were trained with.
+func toContent(text string, images []string) any {
+	if len(images) == 0 {
+		return text
+	}
+
+	parts := make([]model.D, 0, len(images)+1)
+	for _, image := range images {
+		parts = append(parts, model.D{
+			"type":      "image_url",
+			"image_url": model.D{"url": image},
+		})
+	}
+
+	if text != "" {
+		parts = append(parts, model.D{"type": "text", "text": text})
+	}
+
+	return parts
+}
+
+// toolArguments returns the arguments of a tool call as the JSON string the
+// chat templates expect.
+func toolArguments(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+
+	switch {
+	case len(raw) == 0 || string(raw) == "null":
+		return "{}", nil
+
+	case raw[0] == '{':
+		return string(raw), nil
+
+	case raw[0] == '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+
+	return "", errors.New("arguments must be an object")
+}
+
+// applyParams sets the Kronk params for the options, format and think fields
+// shared by the chat and generate requests.
+func applyParams(d model.D, options map[string]any, format json.RawMessage, think any) error {
+	for name, val := range options {
+		param, ok := optionParams[name]
+		if !ok || val == nil {
+			continue
+		}
+
+		// Ollama uses negative values for an unlimited num_predict and a
+		// random seed, which are the Kronk defaults.
+		if (name == "num_predict" || name == "seed") && negative(val) {
+			continue
+		}
+
+		d[param] = val
+	}
+
+	if err := applyFormat(d, format); err != nil {
+		return err
+	}
+
+	return applyThink(d, think)
+}
+
+func negative(val any) bool {
+	switch v := val.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return err == nil && f < 0
+	case float64:
+		return v < 0
+	}
+
+	return false
+}
+
+// applyFormat maps the format field, which is "json" or a JSON schema, onto
+// the structured output params.
+func applyFormat(d model.D, format json.RawMessage) error {
+	format = bytes.TrimSpace(format)
+	if len(format) == 0 || string(format) == "null" || string(format) == `""` {
+		return nil
+	}
+
+	switch format[0] {
+	case '"':
+		var s string
+		if err := json.Unmarshal(format, &s); err != nil || s != "json" {
+			return errors.New(`format must be "json" or a JSON schema`)
+		}
+		d["response_format"] = model.D{"type": "json_object"}
+
+	case '{':
+		var schema map[string]any
+		if err := json.Unmarshal(format, &schema); err != nil {
+			return fmt.Errorf("format: %w", err)
+		}
+		d["json_schema"] = schema
+
+	default:
+		return errors.New(`format must be "json" or a JSON schema`)
+	}
+
+	return nil
+}
+
+// applyThink maps the think field, which is a boolean or a reasoning level of
+// "low", "medium" or "high", onto the reasoning params.
+func applyThink(d model.D, think any) error {
+	switch v := think.(type) {
+	case nil:
+
+	case bool:
+		d["enable_thinking"] = v
+
+	case string:
+		switch v {
+		case "low", "medium", "high":
+			d["enable_thinking"] = true
+			d["reasoning_effort"] = v
+		default:
+			return fmt.Errorf("think must be a boolean or one of low, medium or high, got %q", v)
+		}
+
+	default:
+		return errors.New("think must be a boolean or one of low, medium or high")
+	}
+
+	return nil
+}
+
+// =============================================================================
+// Response Types
+
+// Metrics holds the timings and token counts of a finished request. The
+// durations are in nanoseconds.
+type Metrics struct {
+	TotalDuration      int64 `json:"total_duration,omitempty"`
+	LoadDuration       int64 `json:"load_duration,omitempty"`
+	PromptEvalCount    int   `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration int64 `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int   `json:"eval_count,omitempty"`
+	EvalDuration       int64 `json:"eval_duration,omitempty"`
+}
+
+// ChatResponse represents an Ollama chat response or stream chunk.
+type ChatResponse struct {
+	Model      string    `json:"model"`
+	CreatedAt  time.Time `json:"created_at"`
+	Message    Message   `json:"message"`
+	Done       bool      `json:"done"`
+	DoneReason string    `json:"done_reason,omitempty"`
+	Metrics
+}
+
+// Encode implements web.Encoder.
+func (r ChatResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// GenerateResponse represents an Ollama generate response or stream chunk.
+type GenerateResponse struct {
+	Model      string    `json:"model"`
+	CreatedAt  time.Time `json:"created_at"`
+	Response   string    `json:"response"`
+	Thinking   string    `json:"thinking,omitempty"`
+	Done       bool      `json:"done"`
+	DoneReason string    `json:"done_reason,omitempty"`
+	Metrics
+}
+
+// Encode implements web.Encoder.
+func (r GenerateResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// EmbedResponse represents an Ollama embed response.
+type EmbedResponse struct {
+	Model           string      `json:"model"`
+	Embeddings      [][]float32 `json:"embeddings"`
+	TotalDuration   int64       `json:"total_duration,omitempty"`
+	LoadDuration    int64       `json:"load_duration,omitempty"`
+	PromptEvalCount int         `json:"prompt_eval_count,omitempty"`
+}
+
+// Encode implements web.Encoder.
+func (r EmbedResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// ModelDetails describes the format and size of a model.
+type ModelDetails struct {
+	ParentModel       string   `json:"parent_model"`
+	Format            string   `json:"format"`
+	Family            string   `json:"family"`
+	Families          []string `json:"families"`
+	ParameterSize     string   `json:"parameter_size"`
+	QuantizationLevel string   `json:"quantization_level"`
+}
+
+// ListModel is a model in the tags response.
+type ListModel struct {
+	Name       string       `json:"name"`
+	Model      string       `json:"model"`
+	ModifiedAt time.Time    `json:"modified_at"`
+	Size       int64        `json:"size"`
+	Digest     string       `json:"digest"`
+	Details    ModelDetails `json:"details"`
+}
+
+// ListResponse represents the response for the installed models.
+type ListResponse struct {
+	Models []ListModel `json:"models"`
+}
+
+// Encode implements web.Encoder.
+func (r ListResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// ProcessModel is a model in the ps response.
+type ProcessModel struct {
+	Name      string       `json:"name"`
+	Model     string       `json:"model"`
+	Size      int64        `json:"size"`
+	Digest    string       `json:"digest"`
+	Details   ModelDetails `json:"details"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	SizeVRAM  int64        `json:"size_vram"`
+}
+
+// ProcessResponse represents the response for the loaded models.
+type ProcessResponse struct {
+	Models []ProcessModel `json:"models"`
+}
+
+// Encode implements web.Encoder.
+func (r ProcessResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// ShowResponse represents the details of a model.
+type ShowResponse struct {
+	Parameters   string         `json:"parameters"`
+	Template     string         `json:"template"`
+	Details      ModelDetails   `json:"details"`
+	ModelInfo    map[string]any `json:"model_info"`
+	Capabilities []string       `json:"capabilities"`
+	ModifiedAt   time.Time      `json:"modified_at"`
+}
+
+// Encode implements web.Encoder.
+func (r ShowResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// PullResponse represents a pull status update.
+type PullResponse struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+}
+
+// Encode implements web.Encoder.
+func (r PullResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// ErrorResponse represents an error in the shape Ollama clients read.
+type ErrorResponse struct {
+	Error  string `json:"error"`
+	status int
+}
+
+// Encode implements web.Encoder.
+func (r ErrorResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// HTTPStatus implements the web package httpStatus interface.
+func (r ErrorResponse) HTTPStatus() int {
+	return r.status
+}
+
+// =============================================================================
+// Response Conversion
+
+// toDoneReason maps a model.FinishReason* value to the Ollama done_reason.
+// Ollama reports tool calls as a stop.
+func toDoneReason(finishReason string) string {
+	switch finishReason {
+	case model.FinishReasonLength:
+		return "length"
+	default:
+		return "stop"
+	}
+}
+
+// toMetrics converts the usage of a request. The prompt is evaluated until
+// the first token, and generation is timed from the token rate.
+func toMetrics(usage *model.Usage, started time.Time, load time.Duration) Metrics {
+	m := Metrics{
+		TotalDuration: time.Since(started).Nanoseconds(),
+		LoadDuration:  load.Nanoseconds(),
+	}
+
+	if usage == nil {
+		return m
+	}
+
+	m.PromptEvalCount = usage.PromptTokens
+	m.PromptEvalDuration = int64(usage.TimeToFirstTokenMS * float64(time.Millisecond))
+	m.EvalCount = usage.CompletionTokens
+	if usage.TokensPerSecond > 0 {
+		m.EvalDuration = int64(float64(usage.CompletionTokens) / usage.TokensPerSecond * float64(time.Second))
+	}
+
+	return m
+}
+
+// toToolCalls converts the tool calls of a chat response. Arguments are
+// sent as a JSON object rather than the string OpenAI uses.
+func toToolCalls(calls []model.ResponseToolCall) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	toolCalls := make([]ToolCall, len(calls))
+	for i, tc := range calls {
+		args, err := json.Marshal(map[string]any(tc.Function.Arguments))
+		if err != nil || tc.Function.Arguments == nil {
+			args = []byte("{}")
+		}
+
+		toolCalls[i] = ToolCall{
+			Function: ToolCallFunction{
+				Index:     i,
+				Name:      tc.Function.Name,
+				Arguments: args,
+			},
+		}
+	}
+
+	return toolCalls
+}
+
+// toModelInfo converts GGUF metadata into the model_info object. Numbers and
+// booleans are restored from their string form, and the tokenizer
+// vocabulary and chat template are left out as Ollama does.
+func toModelInfo(metadata map[string]string) map[string]any {
+	info := make(map[string]any, len(metadata))
+
+	for key, val := range metadata {
+		if strings.HasPrefix(key, "tokenizer.ggml.") || key == "tokenizer.chat_template" {
+			continue
+		}
+
+		if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+			info[key] = n
+			continue
+		}
+
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			info[key] = f
+			continue
+		}
+
+		if b, err := strconv.ParseBool(val); err == nil && (val == "true" || val == "false") {
+			info[key] = b
+			continue
+		}
+
+		info[key] = val
+	}
+
+	return info
+}
+
+// toDetails returns the details of a model. Kronk serves GGUF models only.
+func toDetails(family string, parameterSize string, quantization string) ModelDetails {
+	var families []string
+	if family != "" {
+		families = []string{family}
+	}
+
+	return ModelDetails{
+		Format:            "gguf",
+		Family:            family,
+		Families:          families,
+		ParameterSize:     parameterSize,
+		QuantizationLevel: quantization,
+	}
+}
+
+// toParameters formats the context window and sampling defaults of a model
+// in the Modelfile parameter form Ollama reports.
+func toParameters(contextWindow int, s models.SamplingConfig) string {
+	var b strings.Builder
+
+	add := func(name string, val any) {
+		fmt.Fprintf(&b, "%-30s %v\n", name, val)
+	}
+
+	if contextWindow > 0 {
+		add("num_ctx", contextWindow)
+	}
+	if s.MaxTokens > 0 {
+		add("num_predict", s.MaxTokens)
+	}
+	add("temperature", s.Temperature)
+	if s.TopK > 0 {
+		add("top_k", s.TopK)
+	}
+	add("top_p", s.TopP)
+	if s.MinP > 0 {
+		add("min_p", s.MinP)
+	}
+	if s.RepeatPenalty > 0 {
+		add("repeat_penalty", s.RepeatPenalty)
+	}
+	if s.RepeatLastN != 0 {
+		add("repeat_last_n", s.RepeatLastN)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// toCapabilities converts the capabilities of a model into the Ollama
+// capability names.
+func toCapabilities(caps models.CatalogCapabilities) []string {
+	if caps.Embedding {
+		return []string{"embedding"}
+	}
+
+	capabilities := []string{"completion"}
+	if caps.Tooling {
+		capabilities = append(capabilities, "tools")
+	}
+	if caps.Reasoning {
+		capabilities = append(capabilities, "thinking")
+	}
+	if caps.Images {
+		capabilities = append(capabilities, "vision")
+	}
+	if caps.Audio {
+		capabilities = append(capabilities, "audio")
+	}
+
+	return capabilities
+}
@@ -0,0 +1,778 @@
+// Package ollamaapp provides an Ollama-compatible api so tools written
+// against the Ollama api can use Kronk without changes.
+package ollamaapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/kronk/gguf"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+	"github.com/ardanlabs/kronk/sdk/pool"
+	"github.com/ardanlabs/kronk/sdk/tools/models"
+)
+
+var reDownloadProgress = regexp.MustCompile(`download-model: Downloading ([^ ]+)\.\.\. (\d+) MB of (\d+) MB \(([\d.]+) MB/s\)`)
+
+type app struct {
+	log    *logger.Logger
+	pool   *pool.Pool
+	models *models.Models
+}
+
+func newApp(cfg Config) *app {
+	return &app{
+		log:    cfg.Log,
+		pool:   cfg.Pool,
+		models: cfg.Models,
+	}
+}
+
+func (a *app) chat(ctx context.Context, r *http.Request) web.Encoder {
+	var req ChatRequest
+	if err := decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if req.Model == "" {
+		return errs.Errorf(errs.InvalidArgument, "missing model field")
+	}
+
+	// A request without messages only loads the model.
+	load := len(req.Messages) == 0
+
+	var d model.D
+	if !load {
+		var err error
+		if d, err = toChatDocument(req); err != nil {
+			return errs.New(errs.InvalidArgument, err)
+		}
+
+		if err := model.ValidateChatRequest(d); err != nil {
+			return errs.FromSDK(err)
+		}
+	}
+
+	modelID := toModelID(req.Model)
+
+	if err := mid.AuthorizeModel(ctx, modelID); err != nil {
+		return err
+	}
+
+	if err := mid.CheckTokenBudget(ctx, modelID); err != nil {
+		return err
+	}
+
+	started := time.Now()
+
+	krn, err := a.pool.Kronk.AquireModel(ctx, modelID)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	loadDuration := time.Since(started)
+
+	if load {
+		return ChatResponse{
+			Model:      req.Model,
+			CreatedAt:  time.Now().UTC(),
+			Message:    Message{Role: "assistant"},
+			Done:       true,
+			DoneReason: "load",
+		}
+	}
+
+	a.log.Info(ctx, "ollama-chat", "REQUEST-PARAMS", d.String())
+
+	if stream(req.Stream) {
+		if !supportsResponseFlush(web.GetWriter(ctx)) {
+			return errs.Errorf(errs.Internal, "streaming not supported")
+		}
+
+		ch, err := krn.ChatStreaming(ctx, d)
+		if err != nil {
+			return errs.FromSDK(err)
+		}
+
+		toChunk := func(msg Message, done bool, doneReason string, metrics Metrics) any {
+			msg.Role = "assistant"
+			return ChatResponse{
+				Model:      req.Model,
+				CreatedAt:  time.Now().UTC(),
+				Message:    msg,
+				Done:       done,
+				DoneReason: doneReason,
+				Metrics:    metrics,
+			}
+		}
+
+		if err := a.streamResponse(ctx, web.GetWriter(ctx), ch, started, loadDuration, toChunk); err != nil {
+			return web.NewNoResponseError(errs.FromSDK(err))
+		}
+
+		return web.NewNoResponse()
+	}
+
+	resp, err := krn.Chat(ctx, d)
+	if resp.Usage != nil {
+		mid.RecordTokenUsage(ctx, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	}
+
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	out := ChatResponse{
+		Model:     req.Model,
+		CreatedAt: time.Now().UTC(),
+		Message:   Message{Role: "assistant"},
+		Done:      true,
+		Metrics:   toMetrics(resp.Usage, started, loadDuration),
+	}
+
+	if len(resp.Choices) > 0 {
+		choice := resp.Choices[0]
+		if choice.Message != nil {
+			out.Message.Content = choice.Message.Content
+			out.Message.Thinking = choice.Message.Reasoning
+			out.Message.ToolCalls = toToolCalls(choice.Message.ToolCalls)
+		}
+		out.DoneReason = toDoneReason(choice.FinishReason())
+	}
+
+	return out
+}
+
+func (a *app) generate(ctx context.Context, r *http.Request) web.Encoder {
+	var req GenerateRequest
+	if err := decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if req.Model == "" {
+		return errs.Errorf(errs.InvalidArgument, "missing model field")
+	}
+
+	// A request without a prompt only loads the model.
+	load := req.Prompt == "" && req.Suffix == "" && len(req.Images) == 0
+
+	var d model.D
+	var raw bool
+	if !load {
+		var err error
+		if d, raw, err = toGenerateDocument(req); err != nil {
+			return errs.New(errs.InvalidArgument, err)
+		}
+
+		validate := model.ValidateChatRequest
+		if raw {
+			validate = model.ValidateCompletionRequest
+		}
+
+		if err := validate(d); err != nil {
+			return errs.FromSDK(err)
+		}
+	}
+
+	modelID := toModelID(req.Model)
+
+	if err := mid.AuthorizeModel(ctx, modelID); err != nil {
+		return err
+	}
+
+	if err := mid.CheckTokenBudget(ctx, modelID); err != nil {
+		return err
+	}
+
+	started := time.Now()
+
+	krn, err := a.pool.Kronk.AquireModel(ctx, modelID)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	loadDuration := time.Since(started)
+
+	if load {
+		return GenerateResponse{
+			Model:      req.Model,
+			CreatedAt:  time.Now().UTC(),
+			Done:       true,
+			DoneReason: "load",
+		}
+	}
+
+	a.log.Info(ctx, "ollama-generate", "raw", raw, "REQUEST-PARAMS", d.String())
+
+	if stream(req.Stream) {
+		if !supportsResponseFlush(web.GetWriter(ctx)) {
+			return errs.Errorf(errs.Internal, "streaming not supported")
+		}
+
+		streaming := krn.ChatStreaming
+		if raw {
+			streaming = krn.CompletionStreaming
+		}
+
+		ch, err := streaming(ctx, d)
+		if err != nil {
+			return errs.FromSDK(err)
+		}
+
+		toChunk := func(msg Message, done bool, doneReason string, metrics Metrics) any {
+			return GenerateResponse{
+				Model:      req.Model,
+				CreatedAt:  time.Now().UTC(),
+				Response:   msg.Content,
+				Thinking:   msg.Thinking,
+				Done:       done,
+				DoneReason: doneReason,
+				Metrics:    metrics,
+			}
+		}
+
+		if err := a.streamResponse(ctx, web.GetWriter(ctx), ch, started, loadDuration, toChunk); err != nil {
+			return web.NewNoResponseError(errs.FromSDK(err))
+		}
+
+		return web.NewNoResponse()
+	}
+
+	generate := krn.Chat
+	if raw {
+		generate = krn.Completion
+	}
+
+	resp, err := generate(ctx, d)
+	if resp.Usage != nil {
+		mid.RecordTokenUsage(ctx, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	}
+
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	out := GenerateResponse{
+		Model:     req.Model,
+		CreatedAt: time.Now().UTC(),
+		Done:      true,
+		Metrics:   toMetrics(resp.Usage, started, loadDuration),
+	}
+
+	if len(resp.Choices) > 0 {
+		choice := resp.Choices[0]
+		if choice.Message != nil {
+			out.Response = choice.Message.Content
+			out.Thinking = choice.Message.Reasoning
+		}
+		out.DoneReason = toDoneReason(choice.FinishReason())
+	}
+
+	return out
+}
+
+func (a *app) embed(ctx context.Context, r *http.Request) web.Encoder {
+	var req EmbedRequest
+	if err := decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if req.Model == "" {
+		return errs.Errorf(errs.InvalidArgument, "missing model field")
+	}
+
+	d, err := toEmbedDocument(req)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	modelID := toModelID(req.Model)
+
+	if err := mid.AuthorizeModel(ctx, modelID); err != nil {
+		return err
+	}
+
+	started := time.Now()
+
+	krn, err := a.pool.Kronk.AquireModel(ctx, modelID)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	loadDuration := time.Since(started)
+
+	if !krn.ModelInfo().IsEmbedModel {
+		return errs.Errorf(errs.InvalidArgument, "model doesn't support embedding")
+	}
+
+	a.log.Info(ctx, "ollama-embed", "model", modelID)
+
+	resp, err := krn.Embeddings(ctx, d)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	embeddings := make([][]float32, len(resp.Data))
+	for i, data := range resp.Data {
+		idx := data.Index
+		if idx < 0 || idx >= len(embeddings) {
+			idx = i
+		}
+		embeddings[idx] = data.Embedding
+	}
+
+	return EmbedResponse{
+		Model:           req.Model,
+		Embeddings:      embeddings,
+		TotalDuration:   time.Since(started).Nanoseconds(),
+		LoadDuration:    loadDuration.Nanoseconds(),
+		PromptEvalCount: resp.Usage.PromptTokens,
+	}
+}
+
+// tags lists the installed models. It reads only the model index, so the
+// parameter size and quantization that need the GGUF header are left to
+// show.
+func (a *app) tags(ctx context.Context, r *http.Request) web.Encoder {
+	modelFiles, err := a.collectModelFiles()
+	if err != nil {
+		return errs.Errorf(errs.Internal, "unable to retrieve model list: %s", err)
+	}
+
+	ids := make([]string, len(modelFiles))
+	for i, mf := range modelFiles {
+		ids[i] = mf.ID
+	}
+
+	allowed, authErr := mid.AuthorizedModels(ctx, ids)
+	if authErr != nil {
+		return authErr
+	}
+
+	resp := ListResponse{
+		Models: make([]ListModel, 0, len(modelFiles)),
+	}
+
+	for _, mf := range modelFiles {
+		if !slices.Contains(allowed, mf.ID) {
+			continue
+		}
+
+		resp.Models = append(resp.Models, ListModel{
+			Name:       toModelName(mf.ID),
+			Model:      toModelName(mf.ID),
+			ModifiedAt: mf.Modified,
+			Size:       mf.Size,
+			Digest:     digest(mf.ID),
+			Details:    toDetails(mf.ModelFamily, "", ""),
+		})
+	}
+
+	return resp
+}
+
+func (a *app) show(ctx context.Context, r *http.Request) web.Encoder {
+	var req ShowRequest
+	if err := decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	name := req.Model
+	if name == "" {
+		name = req.Name
+	}
+
+	if name == "" {
+		return errs.Errorf(errs.InvalidArgument, "missing model field")
+	}
+
+	modelID := toModelID(name)
+
+	// A model the token may not use is reported as missing.
+	if err := mid.AuthorizeModel(ctx, modelID); err != nil {
+		if err.Code == errs.PermissionDenied {
+			return errs.FromSDK(fmt.Errorf("%w: %q", models.ErrModelNotFound, modelID))
+		}
+
+		return err
+	}
+
+	fi, err := a.models.FileInformation(modelID)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	mi, err := a.models.ModelInformation(modelID)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	var parameters string
+	if rmc, err := a.pool.Kronk.ResolvedModelConfig(modelID); err == nil {
+		sampling := rmc.Sampling.WithMetadataDefaults(mi.Metadata)
+		parameters = toParameters(rmc.ToKronkConfig().ContextWindow(), sampling)
+	} else {
+		a.log.Info(ctx, "ollama-show: resolved-model-config", "id", modelID, "ERROR", err)
+	}
+
+	return ShowResponse{
+		Parameters:   parameters,
+		Template:     gguf.ChatTemplate(mi.Metadata),
+		Details:      toDetails(fi.ModelFamily, models.ParametersLabel(mi.Metadata), mi.Quantization),
+		ModelInfo:    toModelInfo(mi.Metadata),
+		Capabilities: toCapabilities(models.CapabilitiesFor(mi.Metadata, mi.HasProjection)),
+		ModifiedAt:   time.UnixMilli(fi.Created),
+	}
+}
+
+func (a *app) ps(ctx context.Context, r *http.Request) web.Encoder {
+	details, err := a.pool.Kronk.ModelStatus()
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	details = slices.DeleteFunc(details, func(md pool.ModelDetail) bool {
+		return md.Status != pool.ModelStatusLoaded
+	})
+
+	ids := make([]string, len(details))
+	for i, md := range details {
+		ids[i] = md.ID
+	}
+
+	allowed, authErr := mid.AuthorizedModels(ctx, ids)
+	if authErr != nil {
+		return authErr
+	}
+
+	resp := ProcessResponse{
+		Models: make([]ProcessModel, 0, len(details)),
+	}
+
+	for _, md := range details {
+		if !slices.Contains(allowed, md.ID) {
+			continue
+		}
+
+		resp.Models = append(resp.Models, ProcessModel{
+			Name:      toModelName(md.ID),
+			Model:     toModelName(md.ID),
+			Size:      max(md.Size, md.VRAMTotal),
+			Digest:    digest(md.ID),
+			Details:   toDetails(md.ModelFamily, "", ""),
+			ExpiresAt: md.ExpiresAt,
+			SizeVRAM:  md.VRAMTotal,
+		})
+	}
+
+	return resp
+}
+
+// pull downloads a model from the catalog or a URL. Progress is streamed as
+// Ollama status lines, with each file of the model reported as a layer.
+func (a *app) pull(ctx context.Context, r *http.Request) web.Encoder {
+	var req PullRequest
+	if err := decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	name := req.Model
+	if name == "" {
+		name = req.Name
+	}
+
+	if name == "" {
+		return errs.Errorf(errs.InvalidArgument, "missing model field")
+	}
+
+	source := toModelID(name)
+
+	a.log.Info(ctx, "ollama-pull", "model", source)
+
+	w := web.GetWriter(ctx)
+
+	// Extend the per-connection write deadline so large model downloads
+	// are not killed by the server-wide WriteTimeout.
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(6 * time.Hour)); err != nil {
+		a.log.Info(ctx, "ollama-pull", "set-write-deadline", "ERROR", err)
+	}
+
+	if !stream(req.Stream) {
+		logger := func(ctx context.Context, msg string, args ...any) {
+			a.log.Info(ctx, "ollama-pull", append([]any{"status", strings.TrimPrefix(msg, "\r\x1b[K")}, args...)...)
+		}
+
+		if _, err := a.models.Download(ctx, logger, source); err != nil {
+			return errs.FromSDK(err)
+		}
+
+		return PullResponse{Status: "success"}
+	}
+
+	if !supportsResponseFlush(w) {
+		return errs.Errorf(errs.Internal, "streaming not supported")
+	}
+
+	s := ndjsonWriter{w: w}
+	if err := s.start(); err != nil {
+		return web.NewNoResponseError(errs.New(errs.Internal, err))
+	}
+
+	s.send(PullResponse{Status: "pulling manifest"})
+
+	logger := func(ctx context.Context, msg string, args ...any) {
+		clean := strings.TrimPrefix(msg, "\r\x1b[K")
+
+		m := reDownloadProgress.FindStringSubmatch(clean)
+		if m == nil {
+			a.log.Info(ctx, "ollama-pull", append([]any{"status", clean}, args...)...)
+			return
+		}
+
+		cur, _ := strconv.ParseInt(m[2], 10, 64)
+		total, _ := strconv.ParseInt(m[3], 10, 64)
+
+		s.send(PullResponse{
+			Status:    "pulling " + m[1],
+			Digest:    m[1],
+			Total:     total * 1000 * 1000,
+			Completed: cur * 1000 * 1000,
+		})
+	}
+
+	if _, err := a.models.Download(ctx, logger, source); err != nil {
+		s.send(ErrorResponse{Error: err.Error()})
+		return web.NewNoResponseError(errs.FromSDK(err))
+	}
+
+	s.send(PullResponse{Status: "success"})
+
+	return web.NewNoResponse()
+}
+
+// =============================================================================
+
+// streamResponse sends the chunks of a streaming chat or completion as NDJSON
+// lines and ends with a done line that holds the done reason and metrics.
+// toChunk builds the line for the text and tool calls of a chunk.
+func (a *app) streamResponse(ctx context.Context, w http.ResponseWriter, ch <-chan model.ChatResponse, started time.Time, load time.Duration, toChunk func(msg Message, done bool, doneReason string, metrics Metrics) any) error {
+	s := ndjsonWriter{w: w}
+	if err := s.start(); err != nil {
+		return err
+	}
+
+	var usage *model.Usage
+	var doneReason string
+
+	// The usage of the last chunk seen is charged even when the stream ends
+	// early, since those tokens were generated.
+	defer func() {
+		if usage != nil {
+			mid.RecordTokenUsage(ctx, usage.PromptTokens, usage.CompletionTokens)
+		}
+	}()
+
+	for resp := range ch {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("ollama-streaming: context canceled, do not send response: %w", err)
+		}
+
+		if resp.Usage != nil {
+			usage = resp.Usage
+		}
+
+		if len(resp.Choices) == 0 {
+			continue
+		}
+
+		choice := resp.Choices[0]
+
+		var msg Message
+		switch choice.FinishReason() {
+		case model.FinishReasonError:
+			var message string
+			if choice.Delta != nil {
+				message = choice.Delta.Content
+			}
+
+			if err := s.send(ErrorResponse{Error: message}); err != nil {
+				return err
+			}
+
+			return errors.New(message)
+
+		case "":
+			if choice.Delta != nil {
+				msg.Content = choice.Delta.Content
+				msg.Thinking = choice.Delta.Reasoning
+			}
+
+		default:
+			// The terminal chunk repeats the whole text in its message,
+			// which was already streamed in the deltas. Tool calls are
+			// only complete here.
+			doneReason = toDoneReason(choice.FinishReason())
+			if choice.Message != nil {
+				msg.ToolCalls = toToolCalls(choice.Message.ToolCalls)
+			}
+		}
+
+		if msg.Content == "" && msg.Thinking == "" && len(msg.ToolCalls) == 0 {
+			continue
+		}
+
+		if err := s.send(toChunk(msg, false, "", Metrics{})); err != nil {
+			return err
+		}
+	}
+
+	if doneReason == "" {
+		doneReason = "stop"
+	}
+
+	return s.send(toChunk(Message{}, true, doneReason, toMetrics(usage, started, load)))
+}
+
+// ndjsonWriter writes newline delimited JSON, flushing every line so
+// clients see each chunk as it is generated.
+type ndjsonWriter struct {
+	w http.ResponseWriter
+}
+
+func (s *ndjsonWriter) start() error {
+	s.w.Header().Set("Content-Type", "application/x-ndjson")
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.WriteHeader(http.StatusOK)
+
+	if err := http.NewResponseController(s.w).Flush(); err != nil {
+		return fmt.Errorf("flush streaming headers: %w", err)
+	}
+
+	return nil
+}
+
+func (s *ndjsonWriter) send(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshal line: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(s.w, "%s\n", data); err != nil {
+		return fmt.Errorf("write line: %w", err)
+	}
+
+	return http.NewResponseController(s.w).Flush()
+}
+
+// collectModelFiles returns all on-disk model files plus any profiles declared
+// in the model config that inherit from a base model.
+func (a *app) collectModelFiles() ([]models.File, error) {
+	modelFiles, err := a.models.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]models.File, len(modelFiles))
+	for i, mf := range modelFiles {
+		mf.ID = mf.OwnedBy + "/" + mf.ID
+		modelFiles[i] = mf
+		existing[mf.ID] = mf
+	}
+
+	modelConfig := a.pool.Kronk.ModelConfig()
+	for modelID := range modelConfig {
+		if _, exists := existing[modelID]; exists {
+			continue
+		}
+
+		parsed, err := models.ParseModelID(modelID)
+		if err != nil || parsed.Profile == "" {
+			continue
+		}
+
+		baseModel, exists := existing[parsed.Base()]
+		if !exists {
+			continue
+		}
+
+		baseModel.ID = modelID
+		modelFiles = append(modelFiles, baseModel)
+	}
+
+	return modelFiles, nil
+}
+
+// errorFormat writes errors as the {"error": "message"} object Ollama clients
+// read instead of the OpenAI error object. The error is still returned as a
+// committed response so the errors middleware logs it.
+func errorFormat(next web.HandlerFunc) web.HandlerFunc {
+	h := func(ctx context.Context, r *http.Request) web.Encoder {
+		resp := next(ctx, r)
+
+		err, isError := resp.(error)
+		if !isError {
+			return resp
+		}
+
+		var appErr *errs.Error
+		if !errors.As(err, &appErr) || appErr.Code == errs.InternalOnlyLog {
+			appErr = errs.Errorf(errs.Internal, "Internal Server Error")
+		}
+
+		out := ErrorResponse{
+			Error:  appErr.Message,
+			status: appErr.HTTPStatus(),
+		}
+
+		if respErr := web.Respond(ctx, web.GetWriter(ctx), out); respErr != nil {
+			return web.NewNoResponseError(errors.Join(err, respErr))
+		}
+
+		return web.NewNoResponseError(err)
+	}
+
+	return h
+}
+
+// decode reads a JSON request body, keeping numbers as json.Number so
+// integer params such as seed keep their precision.
+func decode(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("unable to decode payload: %w", err)
+	}
+
+	return nil
+}
+
+func supportsResponseFlush(w http.ResponseWriter) bool {
+	for w != nil {
+		switch v := w.(type) {
+		case interface{ FlushError() error }:
+			return true
+		case http.Flusher:
+			return true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = v.Unwrap()
+		default:
+			return false
+		}
+	}
+
+	return false
+}
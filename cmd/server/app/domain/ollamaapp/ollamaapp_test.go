@@ -0,0 +1,312 @@
+package ollamaapp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+	"github.com/ardanlabs/kronk/sdk/tools/models"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRejectsInvalidRequestsBeforeModelAcquisition(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{name: "chat missing model", path: "/api/chat", body: `{"messages":[{"role":"user","content":"hi"}]}`},
+		{name: "chat bad format", path: "/api/chat", body: `{"model":"test","messages":[{"role":"user","content":"hi"}],"format":"xml"}`},
+		{name: "chat bad think", path: "/api/chat", body: `{"model":"test","messages":[{"role":"user","content":"hi"}],"think":"max"}`},
+		{name: "chat bad tool arguments", path: "/api/chat", body: `{"model":"test","messages":[{"role":"assistant","content":"","tool_calls":[{"function":{"name":"f","arguments":[1]}}]}]}`},
+		{name: "generate template", path: "/api/generate", body: `{"model":"test","prompt":"hi","template":"{{ .Prompt }}"}`},
+		{name: "generate raw images", path: "/api/generate", body: `{"model":"test","prompt":"hi","raw":true,"images":["aGk="]}`},
+		{name: "embed missing input", path: "/api/embed", body: `{"model":"test"}`},
+		{name: "embed input type", path: "/api/embed", body: `{"model":"test","input":["a",1]}`},
+		{name: "show missing model", path: "/api/show", body: `{}`},
+		{name: "pull missing model", path: "/api/pull", body: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+
+			a := &app{}
+			handler := map[string]func() any{
+				"/api/chat":     func() any { return a.chat(t.Context(), req) },
+				"/api/generate": func() any { return a.generate(t.Context(), req) },
+				"/api/embed":    func() any { return a.embed(t.Context(), req) },
+				"/api/show":     func() any { return a.show(t.Context(), req) },
+				"/api/pull":     func() any { return a.pull(t.Context(), req) },
+			}[tt.path]
+
+			resp := handler()
+			appErr, ok := resp.(*errs.Error)
+			if !ok {
+				t.Fatalf("handler: got %T, want *errs.Error", resp)
+			}
+			if !appErr.Code.Equal(errs.InvalidArgument) {
+				t.Errorf("Code: got %s, want %s", appErr.Code, errs.InvalidArgument)
+			}
+		})
+	}
+}
+
+func TestToChatDocument(t *testing.T) {
+	body := `{
+		"model": "Qwen3-8B-Q8_0:latest",
+		"messages": [
+			{"role": "system", "content": "Be brief."},
+			{"role": "user", "content": "What is in this image?", "images": ["aGk="]},
+			{"role": "assistant", "content": "", "thinking": "Need the weather.", "tool_calls": [
+				{"function": {"name": "get_weather", "arguments": {"city": "Miami"}}},
+				{"function": {"name": "get_time", "arguments": {"city": "Miami"}}}
+			]},
+			{"role": "tool", "content": "10:00", "tool_name": "get_time"},
+			{"role": "tool", "content": "Sunny", "tool_name": "get_weather"}
+		],
+		"format": "json",
+		"think": "high",
+		"stream": false,
+		"keep_alive": "5m",
+		"options": {"num_ctx": 8192, "num_predict": 128, "temperature": 0.2, "seed": -1, "stop": ["\n"], "mirostat": 1}
+	}`
+
+	var req ChatRequest
+	if err := decode(httptest.NewRequest(http.MethodPost, "/api/chat", strings.NewReader(body)), &req); err != nil {
+		t.Fatalf("decode: %s", err)
+	}
+
+	got, err := toChatDocument(req)
+	if err != nil {
+		t.Fatalf("toChatDocument: %s", err)
+	}
+
+	want := model.D{
+		"model": "Qwen3-8B-Q8_0",
+		"messages": []model.D{
+			{"role": "system", "content": "Be brief."},
+			{"role": "user", "content": []model.D{
+				{"type": "image_url", "image_url": model.D{"url": "aGk="}},
+				{"type": "text", "text": "What is in this image?"},
+			}},
+			{"role": "assistant", "content": "", "reasoning_content": "Need the weather.", "tool_calls": []model.D{
+				{"id": "call_2_0", "type": "function", "function": model.D{"name": "get_weather", "arguments": `{"city": "Miami"}`}},
+				{"id": "call_2_1", "type": "function", "function": model.D{"name": "get_time", "arguments": `{"city": "Miami"}`}},
+			}},
+			{"role": "tool", "content": "10:00", "name": "get_time", "tool_call_id": "call_2_1"},
+			{"role": "tool", "content": "Sunny", "name": "get_weather", "tool_call_id": "call_2_0"},
+		},
+		"max_tokens":       json.Number("128"),
+		"temperature":      json.Number("0.2"),
+		"stop":             []any{"\n"},
+		"response_format":  model.D{"type": "json_object"},
+		"enable_thinking":  true,
+		"reasoning_effort": "high",
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("document mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestToGenerateDocument(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     GenerateRequest
+		want    model.D
+		wantRaw bool
+	}{
+		{
+			name: "chat template",
+			req:  GenerateRequest{Model: "test", System: "Be brief.", Prompt: "Why is the sky blue?", Format: json.RawMessage(`{"type":"object"}`)},
+			want: model.D{
+				"model": "test",
+				"messages": []model.D{
+					{"role": "system", "content": "Be brief."},
+					{"role": "user", "content": "Why is the sky blue?"},
+				},
+				"json_schema": model.D{"type": "object"},
+				"stream":      true,
+				"stream_options": model.D{
+					"include_usage": true,
+				},
+			},
+		},
+		{
+			name:    "raw",
+			req:     GenerateRequest{Model: "test:latest", Prompt: "<s>[INST] hi [/INST]", Raw: true, Stream: new(bool), Think: false},
+			want:    model.D{"model": "test", "prompt": "<s>[INST] hi [/INST]", "enable_thinking": false},
+			wantRaw: true,
+		},
+		{
+			name:    "suffix",
+			req:     GenerateRequest{Model: "test", Prompt: "def add(a, b):", Suffix: "\treturn c", Stream: new(bool)},
+			want:    model.D{"model": "test", "prompt": "def add(a, b):", "suffix": "\treturn c"},
+			wantRaw: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, raw, err := toGenerateDocument(tt.req)
+			if err != nil {
+				t.Fatalf("toGenerateDocument: %s", err)
+			}
+
+			if raw != tt.wantRaw {
+				t.Errorf("raw: got %t, want %t", raw, tt.wantRaw)
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("document mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToEmbedDocument(t *testing.T) {
+	truncate := false
+
+	got, err := toEmbedDocument(EmbedRequest{Model: "embed:latest", Input: []any{"a", "b"}, Truncate: &truncate, Dimensions: 256})
+	if err != nil {
+		t.Fatalf("toEmbedDocument: %s", err)
+	}
+
+	want := model.D{"model": "embed", "input": []any{"a", "b"}, "truncate": false, "dimensions": float64(256)}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("document mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = toEmbedDocument(EmbedRequest{Model: "embed", Input: "a"})
+	if err != nil {
+		t.Fatalf("toEmbedDocument: %s", err)
+	}
+
+	if got["truncate"] != true {
+		t.Errorf("truncate: got %v, want true by default", got["truncate"])
+	}
+}
+
+func TestStreamResponse(t *testing.T) {
+	stop := model.FinishReasonTool
+	ch := make(chan model.ChatResponse, 4)
+	ch <- model.ChatResponse{Choices: []model.Choice{{Delta: &model.ResponseMessage{Reasoning: "Checking."}}}}
+	ch <- model.ChatResponse{Choices: []model.Choice{{Delta: &model.ResponseMessage{Content: "Let me look."}}}}
+	ch <- model.ChatResponse{Choices: []model.Choice{{
+		FinishReasonPtr: &stop,
+		Message: &model.ResponseMessage{
+			Content: "Let me look.",
+			ToolCalls: []model.ResponseToolCall{{
+				ID:       "call_1",
+				Function: model.ResponseToolCallFunction{Name: "get_weather", Arguments: model.ToolCallArguments{"city": "Miami"}},
+			}},
+		},
+	}}}
+	ch <- model.ChatResponse{Usage: &model.Usage{PromptTokens: 12, CompletionTokens: 8, TokensPerSecond: 4, TimeToFirstTokenMS: 50}}
+	close(ch)
+
+	toChunk := func(msg Message, done bool, doneReason string, metrics Metrics) any {
+		return ChatResponse{Model: "test", Message: msg, Done: done, DoneReason: doneReason, Metrics: metrics}
+	}
+
+	w := httptest.NewRecorder()
+	a := &app{}
+	if err := a.streamResponse(t.Context(), w, ch, time.Now(), time.Second, toChunk); err != nil {
+		t.Fatalf("streamResponse: %s", err)
+	}
+
+	if got := w.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("Content-Type: got %q, want application/x-ndjson", got)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("lines: got %d, want 4:\n%s", len(lines), w.Body.String())
+	}
+
+	var chunks []ChatResponse
+	for _, line := range lines {
+		var chunk ChatResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			t.Fatalf("unmarshal %s: %s", line, err)
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	if chunks[0].Message.Thinking != "Checking." || chunks[1].Message.Content != "Let me look." {
+		t.Errorf("deltas: got %+v and %+v", chunks[0].Message, chunks[1].Message)
+	}
+
+	if calls := chunks[2].Message.ToolCalls; len(calls) != 1 || calls[0].Function.Name != "get_weather" || string(calls[0].Function.Arguments) != `{"city":"Miami"}` {
+		t.Errorf("tool calls: got %+v", calls)
+	}
+
+	done := chunks[3]
+	if !done.Done || done.DoneReason != "stop" {
+		t.Errorf("done: got done %t reason %q, want true and stop", done.Done, done.DoneReason)
+	}
+
+	if done.PromptEvalCount != 12 || done.EvalCount != 8 || done.EvalDuration != int64(2*time.Second) || done.PromptEvalDuration != int64(50*time.Millisecond) || done.LoadDuration != int64(time.Second) {
+		t.Errorf("metrics: got %+v", done.Metrics)
+	}
+}
+
+func TestStreamResponseError(t *testing.T) {
+	failed := model.FinishReasonError
+	ch := make(chan model.ChatResponse, 1)
+	ch <- model.ChatResponse{Choices: []model.Choice{{FinishReasonPtr: &failed, Delta: &model.ResponseMessage{Content: "out of memory"}}}}
+	close(ch)
+
+	toChunk := func(msg Message, done bool, doneReason string, metrics Metrics) any {
+		return GenerateResponse{Response: msg.Content, Done: done}
+	}
+
+	w := httptest.NewRecorder()
+	a := &app{}
+	if err := a.streamResponse(t.Context(), w, ch, time.Now(), 0, toChunk); err == nil {
+		t.Fatal("streamResponse: want an error")
+	}
+
+	if got := strings.TrimSpace(w.Body.String()); got != `{"error":"out of memory"}` {
+		t.Errorf("body: got %s", got)
+	}
+}
+
+func TestShowConversions(t *testing.T) {
+	info := toModelInfo(map[string]string{
+		"general.architecture":    "qwen3",
+		"qwen3.context_length":    "40960",
+		"qwen3.rope.freq_base":    "1000000.5",
+		"general.quantized":       "true",
+		"tokenizer.ggml.tokens":   "[...]",
+		"tokenizer.chat_template": "{{ messages }}",
+	})
+
+	wantInfo := map[string]any{
+		"general.architecture": "qwen3",
+		"qwen3.context_length": int64(40960),
+		"qwen3.rope.freq_base": 1000000.5,
+		"general.quantized":    true,
+	}
+	if diff := cmp.Diff(wantInfo, info); diff != "" {
+		t.Errorf("model info mismatch (-want +got):\n%s", diff)
+	}
+
+	caps := toCapabilities(models.CatalogCapabilities{Tooling: true, Reasoning: true, Images: true})
+	if diff := cmp.Diff([]string{"completion", "tools", "thinking", "vision"}, caps); diff != "" {
+		t.Errorf("capabilities mismatch (-want +got):\n%s", diff)
+	}
+
+	params := toParameters(8192, models.SamplingConfig{Temperature: 0.6, TopK: 20, TopP: 0.95})
+	for _, want := range []string{"num_ctx", "8192", "temperature", "0.6", "top_k", "20", "top_p", "0.95"} {
+		if !strings.Contains(params, want) {
+			t.Errorf("parameters: %q missing %q", params, want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package ollamaapp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+	"github.com/ardanlabs/kronk/sdk/pool"
+	"github.com/ardanlabs/kronk/sdk/tools/models"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log                    *logger.Logger
+	AuthClient             *authclient.Client
+	Pool                   *pool.Pool
+	Models                 *models.Models
+	AuthorizationMode      auth.Mode
+	LegacyManagementAccess bool
+	InferenceTimeout       time.Duration
+	Priorities             map[string]model.Priority
+}
+
+// Routes adds specific routes for this group. The inference routes share the
+// endpoint grants, priorities and token budgets of the OpenAI-compatible
+// routes they translate to.
+func Routes(app *web.App, cfg Config) {
+	const group = "api"
+
+	api := newApp(cfg)
+
+	access := mid.NewAccess(cfg.AuthClient, cfg.AuthorizationMode, cfg.LegacyManagementAccess)
+	modelDiscoveryAccess := access.ModelDiscovery()
+	administrationAccess := access.Administration()
+	chatAccess := access.Inference("chat-completions")
+	generateAccess := access.Inference("completions")
+	embedAccess := access.Inference("embeddings")
+
+	timeout := mid.Timeout(cfg.InferenceTimeout)
+	tokenBudget := mid.TokenBudget(cfg.Log, cfg.AuthClient)
+
+	app.HandlerFunc(http.MethodPost, group, "/chat", api.chat, errorFormat, timeout, chatAccess, mid.Schedule(cfg.Priorities["chat-completions"]), tokenBudget)
+	app.HandlerFunc(http.MethodPost, group, "/generate", api.generate, errorFormat, timeout, generateAccess, mid.Schedule(cfg.Priorities["completions"]), tokenBudget)
+	app.HandlerFunc(http.MethodPost, group, "/embed", api.embed, errorFormat, timeout, embedAccess)
+
+	app.HandlerFunc(http.MethodGet, group, "/tags", api.tags, errorFormat, modelDiscoveryAccess)
+	app.HandlerFunc(http.MethodPost, group, "/show", api.show, errorFormat, modelDiscoveryAccess)
+	app.HandlerFunc(http.MethodGet, group, "/ps", api.ps, errorFormat, modelDiscoveryAccess)
+	app.HandlerFunc(http.MethodPost, group, "/pull", api.pull, errorFormat, administrationAccess)
+}
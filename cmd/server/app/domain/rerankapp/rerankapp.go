@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
 	"github.com/ardanlabs/kronk/sdk/kronk/model"
@@ -41,6 +42,10 @@ func (a *app) rerank(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Errorf(errs.InvalidArgument, "model name must be a string")
 	}
 
+	if err := mid.AuthorizeModel(ctx, modelID); err != nil {
+		return err
+	}
+
 	krn, err := a.pool.Kronk.AquireModel(ctx, modelID)
 	if err != nil {
 		return errs.FromSDK(err)
@@ -0,0 +1,45 @@
+package respapp
+
+import (
+	"encoding/json"
+
+	"github.com/ardanlabs/kronk/sdk/kronk"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+)
+
+// Response is a stored Responses API response.
+type Response kronk.ResponseResponse
+
+// Encode implements web.Encoder.
+func (r Response) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// DeletedResponse confirms that a stored response was deleted.
+type DeletedResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Deleted bool   `json:"deleted"`
+}
+
+// Encode implements web.Encoder.
+func (r DeletedResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+// InputItemList is a page of the input items of a stored response.
+type InputItemList struct {
+	Object  string    `json:"object"`
+	Data    []model.D `json:"data"`
+	FirstID *string   `json:"first_id"`
+	LastID  *string   `json:"last_id"`
+	HasMore bool      `json:"has_more"`
+}
+
+// Encode implements web.Encoder.
+func (l InputItemList) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(l)
+	return data, "application/json", err
+}
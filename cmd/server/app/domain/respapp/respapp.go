@@ -6,8 +6,16 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"slices"
+	"strconv"
+
+	"uuid"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mediafetch"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/respstore"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
 	"github.com/ardanlabs/kronk/sdk/kronk"
@@ -15,15 +23,36 @@ import (
 	"github.com/ardanlabs/kronk/sdk/pool"
 )
 
+// maxChainLength bounds how many stored responses a previous_response_id
+// chain may walk.
+const maxChainLength = 1000
+
+// responseModel is the part of a loaded model the handler uses.
+type responseModel interface {
+	ResponseStreamingHTTP(ctx context.Context, w http.ResponseWriter, d model.D) (kronk.ResponseResponse, error)
+}
+
 type app struct {
-	log  *logger.Logger
-	pool *pool.Pool
+	log     *logger.Logger
+	acquire func(ctx context.Context, modelID string) (responseModel, error)
+	store   respstore.Storer
+	files   *filestore.Store
+	media   *mediafetch.Fetcher
 }
 
 func newApp(cfg Config) *app {
 	return &app{
-		log:  cfg.Log,
-		pool: cfg.Pool,
+		log:     cfg.Log,
+		acquire: acquireModel(cfg.Pool),
+		store:   cfg.Store,
+		files:   cfg.Files,
+		media:   cfg.Media,
+	}
+}
+
+func acquireModel(p *pool.Pool) func(ctx context.Context, modelID string) (responseModel, error) {
+	return func(ctx context.Context, modelID string) (responseModel, error) {
+		return p.Kronk.AquireModel(ctx, modelID)
 	}
 }
 
@@ -43,21 +72,250 @@ func (a *app) responses(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.Errorf(errs.InvalidArgument, "model name must be a string")
 	}
 
-	krn, err := a.pool.Kronk.AquireModel(ctx, modelID)
-	if err != nil {
-		return errs.FromSDK(err)
+	if err := mid.AuthorizeModel(ctx, modelID); err != nil {
+		return err
+	}
+
+	if err := mid.CheckTokenBudget(ctx, modelID); err != nil {
+		return err
 	}
 
 	a.log.Info(ctx, "response", "REQUEST-INPUT", req.String())
 
 	d := model.MapToModelD(req)
+	kronk.ApplySessionHeader(r.Header, d)
+
+	// The request's own input items are stored with the response, and the
+	// items of any previous responses are placed before them.
+	var input []model.D
+	if _, exists := d["input"]; exists {
+		items, err := kronk.ResponseInputItems(d["input"])
+		if err != nil {
+			return errs.New(errs.InvalidArgument, err)
+		}
+		input = items
+		d["input"] = items
+	}
+
+	subject := mid.GetSubject(ctx)
+
+	if prevID, _ := d["previous_response_id"].(string); prevID != "" {
+		if _, exists := d["messages"]; exists {
+			return errs.Errorf(errs.InvalidArgument, "previous_response_id cannot be used with messages")
+		}
+
+		history, err := a.history(ctx, prevID)
+		if err != nil {
+			return toError(err)
+		}
+
+		d["input"] = append(history, input...)
+	}
+
+	// File references are resolved on a copy of the items, so stored input
+	// keeps its file_id references rather than the file content.
+	if err := model.ResolveFileInputs(ctx, d, a.files.Lookup(subject)); err != nil {
+		return errs.FromSDK(err)
+	}
+
+	if err := model.ResolveMediaURLs(ctx, d, a.media.MediaFetcher()); err != nil {
+		return errs.FromSDK(err)
+	}
 
-	if _, err := krn.ResponseStreamingHTTP(ctx, web.GetWriter(ctx), d); err != nil {
+	krn, err := a.acquire(ctx, modelID)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	resp, err := krn.ResponseStreamingHTTP(ctx, web.GetWriter(ctx), d)
+	mid.RecordTokenUsage(ctx, resp.Usage.InputTokens, resp.Usage.OutputTokens)
+
+	if err != nil {
 		if errors.Is(err, kronk.ErrResponseCommitted) {
 			return web.NewNoResponseError(errs.FromSDK(err))
 		}
 		return errs.FromSDK(err)
 	}
 
+	if a.store != nil && resp.Store && resp.ID != "" {
+		rec := respstore.Record{
+			Subject:  subject,
+			Input:    withItemIDs(input),
+			Response: resp,
+		}
+
+		if err := a.store.Create(ctx, rec); err != nil {
+			a.log.Error(ctx, "response", "status", "store response", "id", resp.ID, "ERROR", err)
+		}
+	}
+
 	return web.NewNoResponse()
 }
+
+func (a *app) retrieve(ctx context.Context, r *http.Request) web.Encoder {
+	rec, err := a.queryByID(ctx, web.Param(r, "response_id"))
+	if err != nil {
+		return toError(err)
+	}
+
+	return Response(rec.Response)
+}
+
+func (a *app) delete(ctx context.Context, r *http.Request) web.Encoder {
+	id := web.Param(r, "response_id")
+
+	if _, err := a.queryByID(ctx, id); err != nil {
+		return toError(err)
+	}
+
+	if err := a.store.Delete(ctx, id); err != nil {
+		return toError(err)
+	}
+
+	return DeletedResponse{
+		ID:      id,
+		Object:  "response",
+		Deleted: true,
+	}
+}
+
+func (a *app) inputItems(ctx context.Context, r *http.Request) web.Encoder {
+	rec, err := a.queryByID(ctx, web.Param(r, "response_id"))
+	if err != nil {
+		return toError(err)
+	}
+
+	q := r.URL.Query()
+
+	limit := 20
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 100 {
+			return errs.Errorf(errs.InvalidArgument, "limit must be between 1 and 100")
+		}
+	}
+
+	items := slices.Clone(rec.Input)
+	switch q.Get("order") {
+	case "", "desc":
+		slices.Reverse(items)
+	case "asc":
+	default:
+		return errs.Errorf(errs.InvalidArgument, "order must be asc or desc")
+	}
+
+	if after := q.Get("after"); after != "" {
+		at := slices.IndexFunc(items, func(item model.D) bool {
+			return item["id"] == after
+		})
+		if at == -1 {
+			return errs.Errorf(errs.InvalidArgument, "input item %q not found", after)
+		}
+		items = items[at+1:]
+	}
+
+	return pageInputItems(items, limit)
+}
+
+// =============================================================================
+
+// history returns the input and output items of the response chain ending at
+// id, oldest first.
+func (a *app) history(ctx context.Context, id string) ([]model.D, error) {
+	var turns [][]model.D
+	for id != "" {
+		if len(turns) == maxChainLength {
+			return nil, errs.Errorf(errs.InvalidArgument, "previous_response_id chain is longer than %d responses", maxChainLength)
+		}
+
+		rec, err := a.queryByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		turns = append(turns, slices.Concat(rec.Input, rec.Response.InputItems()))
+
+		id = ""
+		if rec.Response.PrevResponseID != nil {
+			id = *rec.Response.PrevResponseID
+		}
+	}
+
+	slices.Reverse(turns)
+
+	return slices.Concat(turns...), nil
+}
+
+// queryByID returns the stored response for the caller. Responses stored for
+// another subject are reported as not found.
+func (a *app) queryByID(ctx context.Context, id string) (respstore.Record, error) {
+	if a.store == nil {
+		return respstore.Record{}, errs.Errorf(errs.FailedPrecondition, "response storage is disabled")
+	}
+
+	rec, err := a.store.QueryByID(ctx, id)
+	if err != nil {
+		return respstore.Record{}, err
+	}
+
+	if rec.Subject != mid.GetSubject(ctx) {
+		return respstore.Record{}, respstore.ErrNotFound
+	}
+
+	return rec, nil
+}
+
+// withItemIDs returns a copy of items where every item has an id, so stored
+// input items can be listed and paged.
+func withItemIDs(items []model.D) []model.D {
+	result := make([]model.D, len(items))
+	for i, item := range items {
+		if _, exists := item["id"]; exists {
+			result[i] = item
+			continue
+		}
+
+		item = item.ShallowClone()
+		item["id"] = "msg_" + uuid.New().String()
+		result[i] = item
+	}
+
+	return result
+}
+
+func pageInputItems(items []model.D, limit int) InputItemList {
+	list := InputItemList{
+		Object: "list",
+		Data:   items,
+	}
+
+	if len(items) > limit {
+		list.Data = items[:limit]
+		list.HasMore = true
+	}
+
+	if len(list.Data) > 0 {
+		first, _ := list.Data[0]["id"].(string)
+		last, _ := list.Data[len(list.Data)-1]["id"].(string)
+		list.FirstID = &first
+		list.LastID = &last
+	}
+
+	if list.Data == nil {
+		list.Data = []model.D{}
+	}
+
+	return list
+}
+
+func toError(err error) *errs.Error {
+	var appErr *errs.Error
+	switch {
+	case errors.As(err, &appErr):
+		return appErr
+	case errors.Is(err, respstore.ErrNotFound):
+		return errs.New(errs.NotFound, err)
+	default:
+		return errs.New(errs.Internal, err)
+	}
+}
@@ -0,0 +1,210 @@
+package respapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authtest"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/respstore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/kronk"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHistoryWalksResponseChain(t *testing.T) {
+	store := respstore.NewMemory(0)
+	a := &app{store: store}
+
+	first := "resp_1"
+	records := []respstore.Record{
+		{
+			Input: []model.D{{"id": "msg_1", "role": "user", "content": "hello"}},
+			Response: kronk.ResponseResponse{
+				ID:     "resp_1",
+				Output: []kronk.ResponseOutputItem{outputMessage("hi")},
+			},
+		},
+		{
+			Input: []model.D{{"id": "msg_2", "role": "user", "content": "again"}},
+			Response: kronk.ResponseResponse{
+				ID:             "resp_2",
+				PrevResponseID: &first,
+				Output:         []kronk.ResponseOutputItem{outputMessage("hi again")},
+			},
+		},
+	}
+
+	for _, rec := range records {
+		if err := store.Create(t.Context(), rec); err != nil {
+			t.Fatalf("should be able to create: %s", err)
+		}
+	}
+
+	got, err := a.history(t.Context(), "resp_2")
+	if err != nil {
+		t.Fatalf("should be able to walk history: %s", err)
+	}
+
+	want := []model.D{
+		{"id": "msg_1", "role": "user", "content": "hello"},
+		{"type": "message", "role": "assistant", "content": "hi"},
+		{"id": "msg_2", "role": "user", "content": "again"},
+		{"type": "message", "role": "assistant", "content": "hi again"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("history mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHistoryHidesOtherSubjects(t *testing.T) {
+	store := respstore.NewMemory(0)
+	a := &app{store: store}
+
+	rec := respstore.Record{
+		Subject:  "someone-else",
+		Response: kronk.ResponseResponse{ID: "resp_1"},
+	}
+	if err := store.Create(t.Context(), rec); err != nil {
+		t.Fatalf("should be able to create: %s", err)
+	}
+
+	if _, err := a.history(t.Context(), "resp_1"); !errors.Is(err, respstore.ErrNotFound) {
+		t.Errorf("history: got %v, want %v", err, respstore.ErrNotFound)
+	}
+}
+
+func TestStoredResponsesRequireStore(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/v1/responses/resp_1", nil)
+	req.SetPathValue("response_id", "resp_1")
+
+	resp := (&app{}).retrieve(t.Context(), req)
+
+	appErr, ok := resp.(*errs.Error)
+	if !ok {
+		t.Fatalf("retrieve: got %T, want *errs.Error", resp)
+	}
+	if appErr.Code != errs.FailedPrecondition {
+		t.Errorf("code: got %v, want %v", appErr.Code, errs.FailedPrecondition)
+	}
+}
+
+func TestInputItemsPaging(t *testing.T) {
+	store := respstore.NewMemory(0)
+	a := &app{store: store}
+
+	rec := respstore.Record{
+		Input: withItemIDs([]model.D{
+			{"id": "msg_1", "role": "user", "content": "one"},
+			{"id": "msg_2", "role": "user", "content": "two"},
+			{"role": "user", "content": "three"},
+		}),
+		Response: kronk.ResponseResponse{ID: "resp_1"},
+	}
+	if err := store.Create(t.Context(), rec); err != nil {
+		t.Fatalf("should be able to create: %s", err)
+	}
+
+	if id, _ := rec.Input[2]["id"].(string); id == "" {
+		t.Fatalf("withItemIDs: missing id on %v", rec.Input[2])
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		wantIDs []string
+		hasMore bool
+	}{
+		{name: "default order", query: "limit=2", wantIDs: []string{rec.Input[2]["id"].(string), "msg_2"}, hasMore: true},
+		{name: "asc after", query: "order=asc&after=msg_1", wantIDs: []string{"msg_2", rec.Input[2]["id"].(string)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/responses/resp_1/input_items?"+tt.query, nil)
+			req.SetPathValue("response_id", "resp_1")
+
+			list, ok := a.inputItems(t.Context(), req).(InputItemList)
+			if !ok {
+				t.Fatalf("inputItems: got %T, want InputItemList", list)
+			}
+
+			ids := make([]string, len(list.Data))
+			for i, item := range list.Data {
+				ids[i], _ = item["id"].(string)
+			}
+
+			if diff := cmp.Diff(tt.wantIDs, ids); diff != "" {
+				t.Errorf("ids mismatch (-want +got):\n%s", diff)
+			}
+			if list.HasMore != tt.hasMore {
+				t.Errorf("has_more: got %t, want %t", list.HasMore, tt.hasMore)
+			}
+		})
+	}
+}
+
+type fakeModel struct {
+	usage kronk.ResponseUsage
+}
+
+func (m fakeModel) ResponseStreamingHTTP(ctx context.Context, w http.ResponseWriter, d model.D) (kronk.ResponseResponse, error) {
+	if stream, _ := d["stream"].(bool); stream {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "event: response.completed\ndata: {}\n\n")
+	}
+
+	return kronk.ResponseResponse{Usage: m.usage}, nil
+}
+
+func TestResponsesChargesStreamedUsage(t *testing.T) {
+	a := authtest.Start(t)
+
+	bearer := a.Token(t, "responses", []auth.TokenBudget{
+		{Input: 1000, Output: 1000, Window: auth.RateDay},
+	})
+
+	api := &app{
+		log: a.Log,
+		acquire: func(context.Context, string) (responseModel, error) {
+			return fakeModel{usage: kronk.ResponseUsage{InputTokens: 12, OutputTokens: 5}}, nil
+		},
+	}
+
+	webApp := web.NewApp(func(context.Context, string, ...any) {})
+	inferenceAccess := mid.NewAccess(a.Client, auth.FullProtected, false).Inference("responses")
+	webApp.HandlerFunc(http.MethodPost, "v1", "/responses", api.responses, inferenceAccess, mid.TokenBudget(a.Log, a.Client))
+
+	body := `{"model":"test-model","stream":true,"input":"hello"}`
+	r := httptest.NewRequest(http.MethodPost, "/v1/responses", strings.NewReader(body))
+	r.Header.Set("authorization", bearer)
+	w := httptest.NewRecorder()
+
+	webApp.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("should get status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	input, output := a.Used(t, bearer, "test-model")
+	if input != 12 || output != 5 {
+		t.Errorf("should charge 12 input and 5 output tokens, got %d and %d", input, output)
+	}
+}
+
+func outputMessage(text string) kronk.ResponseOutputItem {
+	return kronk.ResponseOutputItem{
+		Type:    "message",
+		Role:    "assistant",
+		Content: []kronk.ResponseContentItem{{Type: "output_text", Text: text}},
+	}
+}
@@ -5,10 +5,14 @@ import (
 	"time"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mediafetch"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/respstore"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
 	"github.com/ardanlabs/kronk/sdk/pool"
 )
 
@@ -17,17 +21,27 @@ type Config struct {
 	Log               *logger.Logger
 	AuthClient        *authclient.Client
 	Pool              *pool.Pool
+	Store             respstore.Storer
+	Files             *filestore.Store
+	Media             *mediafetch.Fetcher
 	AuthorizationMode auth.Mode
 	InferenceTimeout  time.Duration
+	Priorities        map[string]model.Priority
 }
 
 // Routes adds specific routes for this group.
 func Routes(app *web.App, cfg Config) {
 	const version = "v1"
+	const endpoint = "responses"
 
 	api := newApp(cfg)
 
-	inferenceAccess := mid.NewAccess(cfg.AuthClient, cfg.AuthorizationMode, false).Inference("responses")
+	inferenceAccess := mid.NewAccess(cfg.AuthClient, cfg.AuthorizationMode, false).Inference(endpoint)
+	schedule := mid.Schedule(cfg.Priorities[endpoint])
+	tokenBudget := mid.TokenBudget(cfg.Log, cfg.AuthClient)
 
-	app.HandlerFunc(http.MethodPost, version, "/responses", api.responses, mid.Timeout(cfg.InferenceTimeout), inferenceAccess)
+	app.HandlerFunc(http.MethodPost, version, "/responses", api.responses, mid.Timeout(cfg.InferenceTimeout), inferenceAccess, schedule, tokenBudget)
+	app.HandlerFunc(http.MethodGet, version, "/responses/{response_id}", api.retrieve, inferenceAccess)
+	app.HandlerFunc(http.MethodDelete, version, "/responses/{response_id}", api.delete, inferenceAccess)
+	app.HandlerFunc(http.MethodGet, version, "/responses/{response_id}/input_items", api.inputItems, inferenceAccess)
 }
@@ -3,6 +3,7 @@ package toolapp
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"slices"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/ardanlabs/kronk/sdk/kronk/kvstorage"
 	"github.com/ardanlabs/kronk/sdk/kronk/model"
 	"github.com/ardanlabs/kronk/sdk/kronk/vram"
+	malinapool "github.com/ardanlabs/kronk/sdk/malina/pool"
 	"github.com/ardanlabs/kronk/sdk/pool"
 	"github.com/ardanlabs/kronk/sdk/pool/engine/resman"
 	"github.com/ardanlabs/kronk/sdk/tools/devices"
@@ -442,42 +444,45 @@ func toModelInfo(fi models.FileInfo, mi models.ModelInfo, rmc models.ModelConfig
 		Template:      rmc.Template,
 		Metadata:      metadata,
 		ModelConfig: &ModelConfig{
-			PtrContextWindow:      rmc.PtrContextWindow,
-			PtrPrefillBatchSize:   rmc.PtrPrefillBatchSize,
-			PtrNThreads:           rmc.PtrNThreads,
-			PtrNThreadsBatch:      rmc.PtrNThreadsBatch,
-			CacheTypeK:            rmc.CacheTypeK,
-			CacheTypeV:            rmc.CacheTypeV,
-			LoadMode:              model.DerefLoadMode(rmc.PtrLoadMode),
-			NUMA:                  rmc.NUMA,
-			FlashAttention:        model.DerefFlashAttention(rmc.FlashAttention),
-			PtrNSeqMax:            rmc.PtrNSeqMax,
-			QueueDepth:            queueDepth,
-			AdmissionCapacity:     admissionCapacity,
-			PtrIMCSessionCapacity: rmc.PtrIMCSessionCapacity,
-			PtrOffloadKQV:         rmc.PtrOffloadKQV,
-			PtrOpOffload:          rmc.PtrOpOffload,
-			PtrProjOnCPU:          rmc.PtrProjOnCPU,
-			ProjDevice:            rmc.ProjDevice,
-			PtrNGpuLayers:         rmc.PtrNGpuLayers,
-			PtrSplitMode:          rmc.PtrSplitMode,
-			TensorSplit:           rmc.TensorSplit,
-			TensorBuftOverrides:   rmc.TensorBuftOverrides,
-			PtrMainGPU:            rmc.PtrMainGPU,
-			Devices:               rmc.Devices,
-			MoE:                   toAppMoEConfig(rmc.MoE),
-			PtrSWAFull:            rmc.PtrSWAFull,
-			PtrIncrementalCache:   rmc.PtrIncrementalCache,
-			PtrCacheMinTokens:     rmc.PtrCacheMinTokens,
-			SessionStoreKind:      rmc.SessionStoreKind,
-			RopeScaling:           rmc.RopeScaling,
-			PtrRopeFreqBase:       rmc.PtrRopeFreqBase,
-			PtrRopeFreqScale:      rmc.PtrRopeFreqScale,
-			PtrYarnExtFactor:      rmc.PtrYarnExtFactor,
-			PtrYarnAttnFactor:     rmc.PtrYarnAttnFactor,
-			PtrYarnBetaFast:       rmc.PtrYarnBetaFast,
-			PtrYarnBetaSlow:       rmc.PtrYarnBetaSlow,
-			PtrYarnOrigCtx:        rmc.PtrYarnOrigCtx,
+			PtrContextWindow:       rmc.PtrContextWindow,
+			PtrPrefillBatchSize:    rmc.PtrPrefillBatchSize,
+			PtrNThreads:            rmc.PtrNThreads,
+			PtrNThreadsBatch:       rmc.PtrNThreadsBatch,
+			CacheTypeK:             rmc.CacheTypeK,
+			CacheTypeV:             rmc.CacheTypeV,
+			LoadMode:               model.DerefLoadMode(rmc.PtrLoadMode),
+			NUMA:                   rmc.NUMA,
+			FlashAttention:         model.DerefFlashAttention(rmc.FlashAttention),
+			PtrNSeqMax:             rmc.PtrNSeqMax,
+			QueueDepth:             queueDepth,
+			AdmissionCapacity:      admissionCapacity,
+			PtrIMCSessionCapacity:  rmc.PtrIMCSessionCapacity,
+			PtrOffloadKQV:          rmc.PtrOffloadKQV,
+			PtrOpOffload:           rmc.PtrOpOffload,
+			PtrProjOnCPU:           rmc.PtrProjOnCPU,
+			ProjDevice:             rmc.ProjDevice,
+			PtrNGpuLayers:          rmc.PtrNGpuLayers,
+			PtrSplitMode:           rmc.PtrSplitMode,
+			TensorSplit:            rmc.TensorSplit,
+			TensorBuftOverrides:    rmc.TensorBuftOverrides,
+			PtrMainGPU:             rmc.PtrMainGPU,
+			Devices:                rmc.Devices,
+			MoE:                    toAppMoEConfig(rmc.MoE),
+			PtrSWAFull:             rmc.PtrSWAFull,
+			PtrIncrementalCache:    rmc.PtrIncrementalCache,
+			PtrCacheMinTokens:      rmc.PtrCacheMinTokens,
+			SessionStoreKind:       rmc.SessionStoreKind,
+			SessionStoreDir:        rmc.SessionStoreDir,
+			PtrSessionStoreMaxRAM:  rmc.PtrSessionStoreMaxRAM,
+			PtrSessionStoreMaxDisk: rmc.PtrSessionStoreMaxDisk,
+			RopeScaling:            rmc.RopeScaling,
+			PtrRopeFreqBase:        rmc.PtrRopeFreqBase,
+			PtrRopeFreqScale:       rmc.PtrRopeFreqScale,
+			PtrYarnExtFactor:       rmc.PtrYarnExtFactor,
+			PtrYarnAttnFactor:      rmc.PtrYarnAttnFactor,
+			PtrYarnBetaFast:        rmc.PtrYarnBetaFast,
+			PtrYarnBetaSlow:        rmc.PtrYarnBetaSlow,
+			PtrYarnOrigCtx:         rmc.PtrYarnOrigCtx,
 			Sampling: SamplingConfig{
 				Temperature:      rmc.Sampling.Temperature,
 				TopK:             rmc.Sampling.TopK,
@@ -531,8 +536,8 @@ func formatMetadataValue(key string, value string) string {
 // ModelDetail provides details for the models in the cache.
 //
 // Backend distinguishes kronk (llama.cpp) entries from bucky
-// (whisper) entries so the BUI can label rows and route Unload to the
-// right pool.
+// (whisper) and malina (stable-diffusion) entries so the BUI can label
+// rows and route Unload to the right pool.
 type ModelDetail struct {
 	ID            string    `json:"id"`
 	Backend       string    `json:"backend"`
@@ -583,6 +588,7 @@ func toModelDetails(models []pool.ModelDetail) ModelDetailsResponse {
 type IMCSessionDetail struct {
 	ModelID        string    `json:"model_id"`
 	ID             int       `json:"id"`
+	CacheKey       string    `json:"cache_key,omitempty"`
 	State          string    `json:"state"`
 	Context        int       `json:"context"`
 	Allocated      int       `json:"allocated"`
@@ -598,6 +604,12 @@ type IMCSessionDetail struct {
 	HasMedia       bool      `json:"has_media"`
 }
 
+// Encode implements the encoder interface.
+func (app IMCSessionDetail) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
 // IMCSessionsResponse is the current set of allocated IMC cache entries.
 type IMCSessionsResponse []IMCSessionDetail
 
@@ -614,6 +626,7 @@ func toIMCSessions(sessions []pool.IMCSessionDetail) IMCSessionsResponse {
 		details[i] = IMCSessionDetail{
 			ModelID:        session.ModelID,
 			ID:             session.ID,
+			CacheKey:       session.CacheKey,
 			State:          string(session.State),
 			Context:        session.Context,
 			Allocated:      session.Allocated,
@@ -633,6 +646,137 @@ func toIMCSessions(sessions []pool.IMCSessionDetail) IMCSessionsResponse {
 	return details
 }
 
+// AdapterDetail describes a LoRA adapter loaded against a model.
+type AdapterDetail struct {
+	ID         string  `json:"id"`
+	Path       string  `json:"path"`
+	Scale      float32 `json:"scale"`
+	Configured bool    `json:"configured"`
+}
+
+// Encode implements the encoder interface.
+func (app AdapterDetail) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+// AdaptersResponse lists the LoRA adapters loaded against a model.
+type AdaptersResponse []AdapterDetail
+
+// Encode implements the encoder interface.
+func (app AdaptersResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAdapterDetail(adapter model.AdapterDetail) AdapterDetail {
+	return AdapterDetail{
+		ID:         adapter.ID,
+		Path:       adapter.Path,
+		Scale:      adapter.Scale,
+		Configured: adapter.Configured,
+	}
+}
+
+func toAdapters(adapters []model.AdapterDetail) AdaptersResponse {
+	details := make(AdaptersResponse, len(adapters))
+	for i, adapter := range adapters {
+		details[i] = toAdapterDetail(adapter)
+	}
+
+	return details
+}
+
+// AdapterLoadRequest identifies a LoRA adapter GGUF to load against a running
+// model. Set exactly one of ID, which resolves beneath the Kronk lora folder,
+// or Path, an absolute file elsewhere on disk.
+type AdapterLoadRequest struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+}
+
+// Decode implements the decoder interface.
+func (app *AdapterLoadRequest) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the request is valid.
+func (app *AdapterLoadRequest) Validate() error {
+	if (app.ID == "") == (app.Path == "") {
+		return fmt.Errorf("exactly one of id or path is required")
+	}
+	return nil
+}
+
+func (app *AdapterLoadRequest) toAdapterConfig() models.AdapterConfig {
+	return models.AdapterConfig{ID: app.ID, Path: app.Path}
+}
+
+// IMCSessionExportRequest identifies the IMC session to export. Set either
+// SessionID or CacheKey; a cache key selects the most recently used session
+// committed under it.
+type IMCSessionExportRequest struct {
+	Model     string `json:"model"`
+	SessionID *int   `json:"session_id"`
+	CacheKey  string `json:"cache_key"`
+}
+
+// Decode implements the decoder interface.
+func (app *IMCSessionExportRequest) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// Validate checks the request is valid.
+func (app *IMCSessionExportRequest) Validate() error {
+	if app.Model == "" {
+		return fmt.Errorf("model is required")
+	}
+	if (app.SessionID == nil) == (app.CacheKey == "") {
+		return fmt.Errorf("exactly one of session_id or cache_key is required")
+	}
+	return nil
+}
+
+func (app *IMCSessionExportRequest) sessionID(sessions []model.IMCSessionDetail) (int, error) {
+	if app.SessionID != nil {
+		return *app.SessionID, nil
+	}
+
+	id := -1
+	var lastUsed time.Time
+	for _, session := range sessions {
+		if session.CacheKey == app.CacheKey && session.LastUsed.After(lastUsed) {
+			id = session.ID
+			lastUsed = session.LastUsed
+		}
+	}
+	if id < 0 {
+		return 0, fmt.Errorf("%w: cache key %q", model.ErrIMCSessionNotFound, app.CacheKey)
+	}
+
+	return id, nil
+}
+
+// attachmentWriter sends the export as a file download. Headers are written
+// with the first byte so an error found before then can still be returned as
+// a normal error response.
+type attachmentWriter struct {
+	w       http.ResponseWriter
+	name    string
+	started bool
+}
+
+func (aw *attachmentWriter) Write(p []byte) (int, error) {
+	if !aw.started {
+		aw.started = true
+		aw.w.Header().Set("Content-Type", "application/octet-stream")
+		aw.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", aw.name))
+		aw.w.WriteHeader(http.StatusOK)
+	}
+
+	return aw.w.Write(p)
+}
+
 // IMCSystemCacheDetail provides one immutable System preload entry.
 type IMCSystemCacheDetail struct {
 	ModelID        string    `json:"model_id"`
@@ -710,6 +854,7 @@ type BatchEngineDetail struct {
 	NDraft                  int                           `json:"ndraft"`
 	QueuedRequests          int                           `json:"queued_requests"`
 	PendingRequests         int                           `json:"pending_requests"`
+	PreemptedRequests       int                           `json:"preempted_requests"`
 	PrefillSelectorStart    int                           `json:"prefill_selector_start"`
 	PrefillSelectorSelected int                           `json:"prefill_selector_selected"`
 	PrefillSelectorNext     int                           `json:"prefill_selector_next"`
@@ -748,6 +893,7 @@ func toBatchEngineSnapshots(snapshots []pool.BatchEngineDetail) BatchEngineSlots
 			NDraft:                  snapshot.NDraft,
 			QueuedRequests:          snapshot.QueuedRequests,
 			PendingRequests:         snapshot.PendingRequests,
+			PreemptedRequests:       snapshot.PreemptedRequests,
 			PrefillSelectorStart:    snapshot.PrefillSelectorStart,
 			PrefillSelectorSelected: snapshot.PrefillSelectorSelected,
 			PrefillSelectorNext:     snapshot.PrefillSelectorNext,
@@ -835,6 +981,36 @@ func fromBuckyDetails(models []buckypool.ModelDetail) ModelDetailsResponse {
 	return details
 }
 
+// fromMalinaDetails converts malina pool ModelDetail entries into the
+// shared API response shape. Stable-diffusion has no KV/Slots concept,
+// so KVCache stays zero and Slots is reported as 1 for parity with
+// kronk's display.
+func fromMalinaDetails(models []malinapool.ModelDetail) ModelDetailsResponse {
+	details := make(ModelDetailsResponse, len(models))
+
+	for i, m := range models {
+		slots := 0
+		if m.Status == malinapool.ModelStatusLoaded {
+			slots = 1
+		}
+
+		details[i] = ModelDetail{
+			ID:            m.ID,
+			Backend:       m.Backend,
+			OwnedBy:       "stable-diffusion",
+			ModelFamily:   "stable-diffusion",
+			Size:          m.Size,
+			VRAMTotal:     m.VRAMTotal,
+			Slots:         slots,
+			ExpiresAt:     m.ExpiresAt,
+			ActiveStreams: m.ActiveStreams,
+			Status:        m.Status,
+		}
+	}
+
+	return details
+}
+
 // =============================================================================
 
 // DeviceBudget describes the budget accounting for a single device.
@@ -1042,43 +1218,46 @@ func toAppMoEConfig(m *model.MoEConfig) *MoEConfig {
 
 // ModelConfig represents the model configuration the model will use by default.
 type ModelConfig struct {
-	PtrContextWindow      *int                     `json:"context-window"`
-	PtrPrefillBatchSize   *int                     `json:"prefill-batch-size"`
-	PtrNThreads           *int                     `json:"nthreads"`
-	PtrNThreadsBatch      *int                     `json:"nthreads-batch"`
-	CacheTypeK            model.GGMLType           `json:"cache-type-k"`
-	CacheTypeV            model.GGMLType           `json:"cache-type-v"`
-	LoadMode              model.LoadMode           `json:"load-mode"`
-	NUMA                  string                   `json:"numa,omitempty"`
-	FlashAttention        model.FlashAttentionType `json:"flash-attention"`
-	PtrNSeqMax            *int                     `json:"nseq-max"`
-	QueueDepth            int                      `json:"queue-depth"`
-	AdmissionCapacity     int                      `json:"admission-capacity"`
-	PtrIMCSessionCapacity *int                     `json:"imc-session-capacity"`
-	PtrOffloadKQV         *bool                    `json:"offload-kqv"`
-	PtrOpOffload          *bool                    `json:"op-offload"`
-	PtrProjOnCPU          *bool                    `json:"proj-on-cpu"`
-	ProjDevice            string                   `json:"proj-device,omitempty"`
-	PtrNGpuLayers         *int                     `json:"ngpu-layers"`
-	PtrSplitMode          *model.SplitMode         `json:"split-mode"`
-	TensorSplit           []float32                `json:"tensor-split"`
-	TensorBuftOverrides   []string                 `json:"tensor-buft-overrides"`
-	PtrMainGPU            *int                     `json:"main-gpu"`
-	Devices               []string                 `json:"devices"`
-	MoE                   *MoEConfig               `json:"moe,omitempty"`
-	PtrSWAFull            *bool                    `json:"swa-full"`
-	PtrIncrementalCache   *bool                    `json:"incremental-cache"`
-	PtrCacheMinTokens     *int                     `json:"cache-min-tokens"`
-	SessionStoreKind      kvstorage.Kind           `json:"session-store-kind,omitzero"`
-	Sampling              SamplingConfig           `json:"sampling-parameters"`
-	RopeScaling           model.RopeScalingType    `json:"rope-scaling-type"`
-	PtrRopeFreqBase       *float32                 `json:"rope-freq-base"`
-	PtrRopeFreqScale      *float32                 `json:"rope-freq-scale"`
-	PtrYarnExtFactor      *float32                 `json:"yarn-ext-factor"`
-	PtrYarnAttnFactor     *float32                 `json:"yarn-attn-factor"`
-	PtrYarnBetaFast       *float32                 `json:"yarn-beta-fast"`
-	PtrYarnBetaSlow       *float32                 `json:"yarn-beta-slow"`
-	PtrYarnOrigCtx        *int                     `json:"yarn-orig-ctx"`
+	PtrContextWindow       *int                     `json:"context-window"`
+	PtrPrefillBatchSize    *int                     `json:"prefill-batch-size"`
+	PtrNThreads            *int                     `json:"nthreads"`
+	PtrNThreadsBatch       *int                     `json:"nthreads-batch"`
+	CacheTypeK             model.GGMLType           `json:"cache-type-k"`
+	CacheTypeV             model.GGMLType           `json:"cache-type-v"`
+	LoadMode               model.LoadMode           `json:"load-mode"`
+	NUMA                   string                   `json:"numa,omitempty"`
+	FlashAttention         model.FlashAttentionType `json:"flash-attention"`
+	PtrNSeqMax             *int                     `json:"nseq-max"`
+	QueueDepth             int                      `json:"queue-depth"`
+	AdmissionCapacity      int                      `json:"admission-capacity"`
+	PtrIMCSessionCapacity  *int                     `json:"imc-session-capacity"`
+	PtrOffloadKQV          *bool                    `json:"offload-kqv"`
+	PtrOpOffload           *bool                    `json:"op-offload"`
+	PtrProjOnCPU           *bool                    `json:"proj-on-cpu"`
+	ProjDevice             string                   `json:"proj-device,omitempty"`
+	PtrNGpuLayers          *int                     `json:"ngpu-layers"`
+	PtrSplitMode           *model.SplitMode         `json:"split-mode"`
+	TensorSplit            []float32                `json:"tensor-split"`
+	TensorBuftOverrides    []string                 `json:"tensor-buft-overrides"`
+	PtrMainGPU             *int                     `json:"main-gpu"`
+	Devices                []string                 `json:"devices"`
+	MoE                    *MoEConfig               `json:"moe,omitempty"`
+	PtrSWAFull             *bool                    `json:"swa-full"`
+	PtrIncrementalCache    *bool                    `json:"incremental-cache"`
+	PtrCacheMinTokens      *int                     `json:"cache-min-tokens"`
+	SessionStoreKind       kvstorage.Kind           `json:"session-store-kind,omitzero"`
+	SessionStoreDir        string                   `json:"session-store-dir,omitempty"`
+	PtrSessionStoreMaxRAM  *int                     `json:"session-store-max-ram-mb,omitempty"`
+	PtrSessionStoreMaxDisk *int                     `json:"session-store-max-disk-mb,omitempty"`
+	Sampling               SamplingConfig           `json:"sampling-parameters"`
+	RopeScaling            model.RopeScalingType    `json:"rope-scaling-type"`
+	PtrRopeFreqBase        *float32                 `json:"rope-freq-base"`
+	PtrRopeFreqScale       *float32                 `json:"rope-freq-scale"`
+	PtrYarnExtFactor       *float32                 `json:"yarn-ext-factor"`
+	PtrYarnAttnFactor      *float32                 `json:"yarn-attn-factor"`
+	PtrYarnBetaFast        *float32                 `json:"yarn-beta-fast"`
+	PtrYarnBetaSlow        *float32                 `json:"yarn-beta-slow"`
+	PtrYarnOrigCtx         *int                     `json:"yarn-orig-ctx"`
 }
 
 // =============================================================================
@@ -1119,11 +1298,23 @@ type RateLimit struct {
 	Window string `json:"window"`
 }
 
+// TokenBudget caps the input and output tokens a token's requests may use
+// within a window. An empty model shares the budget across all models.
+type TokenBudget struct {
+	Model  string `json:"model,omitempty"`
+	Input  int64  `json:"input,omitempty"`
+	Output int64  `json:"output,omitempty"`
+	Window string `json:"window"`
+}
+
 // TokenRequest represents the input for the create token command.
 type TokenRequest struct {
-	Admin     bool                 `json:"admin"`
-	Endpoints map[string]RateLimit `json:"endpoints"`
-	Duration  time.Duration        `json:"duration"`
+	Admin        bool                 `json:"admin"`
+	Endpoints    map[string]RateLimit `json:"endpoints"`
+	Duration     time.Duration        `json:"duration"`
+	Priority     string               `json:"priority,omitempty"`
+	TokenBudgets []TokenBudget        `json:"token_budgets,omitempty"`
+	Models       []string             `json:"models,omitempty"`
 }
 
 // Decode implements the decoder interface.
@@ -1144,6 +1335,206 @@ func (app TokenResponse) Encode() ([]byte, string, error) {
 
 // =============================================================================
 
+// RevokeTokenRequest identifies the token to revoke by its ID, the jti claim,
+// or by the token itself.
+type RevokeTokenRequest struct {
+	ID    string `json:"id,omitempty"`
+	Token string `json:"token,omitempty"`
+}
+
+// Decode implements the decoder interface.
+func (app *RevokeTokenRequest) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// TokenInfoResponse describes an issued token.
+type TokenInfoResponse struct {
+	ID        string    `json:"id"`
+	Subject   string    `json:"subject"`
+	Admin     bool      `json:"admin"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Revoked   bool      `json:"revoked"`
+	RevokedAt time.Time `json:"revoked_at,omitzero"`
+}
+
+// Encode implements the encoder interface.
+func (app TokenInfoResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toTokenInfo(token authclient.TokenInfo) TokenInfoResponse {
+	return TokenInfoResponse{
+		ID:        token.ID,
+		Subject:   token.Subject,
+		Admin:     token.Admin,
+		IssuedAt:  token.IssuedAt,
+		ExpiresAt: token.ExpiresAt,
+		Revoked:   !token.RevokedAt.IsZero(),
+		RevokedAt: token.RevokedAt,
+	}
+}
+
+// TokenInfosResponse is a collection of issued tokens.
+type TokenInfosResponse []TokenInfoResponse
+
+// Encode implements the encoder interface.
+func (app TokenInfosResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toTokenInfos(tokens []authclient.TokenInfo) TokenInfosResponse {
+	resp := make(TokenInfosResponse, len(tokens))
+	for i, token := range tokens {
+		resp[i] = toTokenInfo(token)
+	}
+
+	return resp
+}
+
+// IntrospectTokenRequest carries the token to introspect.
+type IntrospectTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// Decode implements the decoder interface.
+func (app *IntrospectTokenRequest) Decode(data []byte) error {
+	return json.Unmarshal(data, app)
+}
+
+// EndpointUsageResponse reports the requests counted against an endpoint's
+// rate limit within its current window.
+type EndpointUsageResponse struct {
+	Endpoint  string     `json:"endpoint"`
+	Limit     int        `json:"limit"`
+	Window    string     `json:"window"`
+	Used      int64      `json:"used"`
+	Remaining *int64     `json:"remaining,omitempty"`
+	Reset     *time.Time `json:"reset,omitempty"`
+}
+
+// IntrospectionResponse reports a token's claims, expiry and what remains of
+// its rate limits and token budgets. Only Active is set for a token that does
+// not verify or has expired.
+type IntrospectionResponse struct {
+	Active       bool                    `json:"active"`
+	ID           string                  `json:"id,omitempty"`
+	Subject      string                  `json:"subject,omitempty"`
+	Admin        bool                    `json:"admin,omitempty"`
+	IssuedAt     *time.Time              `json:"issued_at,omitempty"`
+	ExpiresAt    *time.Time              `json:"expires_at,omitempty"`
+	ExpiresIn    string                  `json:"expires_in,omitempty"`
+	Revoked      bool                    `json:"revoked,omitempty"`
+	RevokedAt    *time.Time              `json:"revoked_at,omitempty"`
+	Priority     string                  `json:"priority,omitempty"`
+	Endpoints    []EndpointUsageResponse `json:"endpoints,omitempty"`
+	TokenBudgets []TokenQuotaResponse    `json:"token_budgets,omitempty"`
+	Models       []string                `json:"models,omitempty"`
+}
+
+// Encode implements the encoder interface.
+func (app IntrospectionResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toIntrospection(resp authclient.IntrospectTokenResponse, now time.Time) IntrospectionResponse {
+	if resp.Token.Subject == "" {
+		return IntrospectionResponse{Active: resp.Active}
+	}
+
+	token := resp.Token
+
+	in := IntrospectionResponse{
+		Active:       resp.Active,
+		ID:           token.ID,
+		Subject:      token.Subject,
+		Admin:        token.Admin,
+		IssuedAt:     &token.IssuedAt,
+		ExpiresAt:    &token.ExpiresAt,
+		ExpiresIn:    max(token.ExpiresAt.Sub(now), 0).Round(time.Second).String(),
+		Revoked:      !token.RevokedAt.IsZero(),
+		Priority:     resp.Priority,
+		TokenBudgets: toTokenQuotas("", resp.TokenBudgets).Quotas,
+		Models:       resp.Models,
+	}
+
+	if in.Revoked {
+		in.RevokedAt = &token.RevokedAt
+	}
+
+	for _, e := range resp.Endpoints {
+		usage := EndpointUsageResponse{
+			Endpoint: e.Endpoint,
+			Limit:    e.Limit,
+			Window:   e.Window,
+			Used:     e.Used,
+		}
+
+		if !e.Reset.IsZero() {
+			usage.Remaining = new(max(int64(e.Limit)-e.Used, 0))
+			usage.Reset = &e.Reset
+		}
+
+		in.Endpoints = append(in.Endpoints, usage)
+	}
+
+	return in
+}
+
+// TokenQuotaResponse reports the use of a token budget within its current
+// window. A zero limit leaves that direction uncapped.
+type TokenQuotaResponse struct {
+	Model           string    `json:"model,omitempty"`
+	Window          string    `json:"window"`
+	InputLimit      int64     `json:"input_limit"`
+	InputUsed       int64     `json:"input_used"`
+	InputRemaining  int64     `json:"input_remaining"`
+	OutputLimit     int64     `json:"output_limit"`
+	OutputUsed      int64     `json:"output_used"`
+	OutputRemaining int64     `json:"output_remaining"`
+	Reset           time.Time `json:"reset"`
+}
+
+// TokenQuotasResponse lists the token budget use of a subject.
+type TokenQuotasResponse struct {
+	Subject string               `json:"subject"`
+	Quotas  []TokenQuotaResponse `json:"quotas"`
+}
+
+// Encode implements the encoder interface.
+func (app TokenQuotasResponse) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toTokenQuotas(subject string, quotas []authclient.TokenQuota) TokenQuotasResponse {
+	resp := TokenQuotasResponse{
+		Subject: subject,
+		Quotas:  make([]TokenQuotaResponse, len(quotas)),
+	}
+
+	for i, q := range quotas {
+		resp.Quotas[i] = TokenQuotaResponse{
+			Model:           q.Model,
+			Window:          q.Window,
+			InputLimit:      q.InputLimit,
+			InputUsed:       q.InputUsed,
+			InputRemaining:  max(q.InputLimit-q.InputUsed, 0),
+			OutputLimit:     q.OutputLimit,
+			OutputUsed:      q.OutputUsed,
+			OutputRemaining: max(q.OutputLimit-q.OutputUsed, 0),
+			Reset:           q.Reset,
+		}
+	}
+
+	return resp
+}
+
+// =============================================================================
+
 // VRAMRequest represents the input for VRAM calculation.
 //
 // ModelURL (a HuggingFace URL or owner/repo path), ModelURLs (resolved
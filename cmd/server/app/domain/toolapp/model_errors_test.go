@@ -2,6 +2,7 @@ package toolapp
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
 	"github.com/ardanlabs/kronk/sdk/kronk/vram"
 	buckymodels "github.com/ardanlabs/kronk/sdk/tools/bucky/models"
 	llamamodels "github.com/ardanlabs/kronk/sdk/tools/models"
@@ -309,3 +311,31 @@ func assertNotFound(t *testing.T, resp any) {
 		t.Errorf("Code: got %s, want %s", appErr.Code, errs.NotFound)
 	}
 }
+
+func TestIMCSessionExportRequestSessionID(t *testing.T) {
+	now := time.Now()
+	sessions := []model.IMCSessionDetail{
+		{ID: 0, CacheKey: "a", LastUsed: now.Add(-time.Minute)},
+		{ID: 1, CacheKey: "a", LastUsed: now},
+		{ID: 2, LastUsed: now},
+	}
+
+	req := IMCSessionExportRequest{Model: "m", CacheKey: "a"}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	id, err := req.sessionID(sessions)
+	if err != nil || id != 1 {
+		t.Errorf("sessionID: got %d, %v; want 1, nil", id, err)
+	}
+
+	req.CacheKey = "missing"
+	if _, err := req.sessionID(sessions); !errors.Is(err, model.ErrIMCSessionNotFound) {
+		t.Errorf("sessionID: got %v, want %v", err, model.ErrIMCSessionNotFound)
+	}
+
+	req.SessionID = new(2)
+	if err := req.Validate(); err == nil {
+		t.Error("Validate: got nil, want error when both session_id and cache_key are set")
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
 	"github.com/ardanlabs/kronk/sdk/kronk"
 	"github.com/ardanlabs/kronk/sdk/kronk/gguf"
@@ -41,14 +43,33 @@ func (a *app) indexModels(ctx context.Context, r *http.Request) web.Encoder {
 // listModelsOpenAI returns the OpenAI-compatible model list served at
 // GET /v1/models. Apps like OpenWebUI call this endpoint to discover the
 // available models. The native, Kronk-specific listing (with sizes,
-// sampling config, etc.) lives at GET /v1/kronk/models.
+// sampling config, etc.) lives at GET /v1/kronk/models. A token limited to a
+// set of models only sees the models it may use.
 func (a *app) listModelsOpenAI(ctx context.Context, r *http.Request) web.Encoder {
 	modelFiles, err := a.collectModelFiles()
 	if err != nil {
 		return errs.Errorf(errs.Internal, "unable to retrieve model list: %s", err)
 	}
 
-	return toOpenAIModels(modelFiles)
+	resp := toOpenAIModels(modelFiles)
+
+	ids := make([]string, len(resp.Data))
+	for i, m := range resp.Data {
+		ids[i] = m.ID
+	}
+
+	allowed, authErr := mid.AuthorizedModels(ctx, ids)
+	if authErr != nil {
+		return authErr
+	}
+
+	if len(allowed) != len(ids) {
+		resp.Data = slices.DeleteFunc(resp.Data, func(m OpenAIModel) bool {
+			return !slices.Contains(allowed, m.ID)
+		})
+	}
+
+	return resp
 }
 
 // retrieveModelOpenAI returns the OpenAI-compatible model object served at
@@ -56,6 +77,15 @@ func (a *app) listModelsOpenAI(ctx context.Context, r *http.Request) web.Encoder
 func (a *app) retrieveModelOpenAI(ctx context.Context, r *http.Request) web.Encoder {
 	modelID := web.Param(r, "model")
 
+	// A model the token may not use is reported as missing.
+	if err := mid.AuthorizeModel(ctx, modelID); err != nil {
+		if err.Code == errs.PermissionDenied {
+			return errs.FromSDK(fmt.Errorf("%w: %q", models.ErrModelNotFound, modelID))
+		}
+
+		return err
+	}
+
 	modelFiles, err := a.collectModelFiles()
 	if err != nil {
 		return errs.Errorf(errs.Internal, "unable to retrieve model list: %s", err)
@@ -536,7 +566,17 @@ func (a *app) modelPS(ctx context.Context, r *http.Request) web.Encoder {
 		resp = append(resp, fromBuckyDetails(buckyModels)...)
 	}
 
-	a.log.Info(ctx, "models", "len", len(resp), "kronk", len(kronkModels), "bucky", len(resp)-len(kronkModels))
+	var malinaModels int
+	if a.pool.Malina != nil {
+		mdls, err := a.pool.Malina.ModelStatus()
+		if err != nil {
+			return errs.New(errs.Internal, err)
+		}
+		resp = append(resp, fromMalinaDetails(mdls)...)
+		malinaModels = len(mdls)
+	}
+
+	a.log.Info(ctx, "models", "len", len(resp), "kronk", len(kronkModels), "bucky", len(resp)-len(kronkModels)-malinaModels, "malina", malinaModels)
 
 	return resp
 }
@@ -549,6 +589,121 @@ func (a *app) imcSessions(ctx context.Context, r *http.Request) web.Encoder {
 	return toIMCSessions(sessions)
 }
 
+func (a *app) exportIMCSession(ctx context.Context, r *http.Request) web.Encoder {
+	var req IMCSessionExportRequest
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	krn, exists := a.pool.Kronk.GetExisting(req.Model)
+	if !exists {
+		return errs.Errorf(errs.NotFound, "model %q is not loaded", req.Model)
+	}
+
+	id, err := req.sessionID(krn.IMCSessions())
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	a.log.Info(ctx, "imc-session-export", "model", req.Model, "session", id)
+
+	w := attachmentWriter{
+		w:    web.GetWriter(ctx),
+		name: fmt.Sprintf("imc-session-%d.kimc", id),
+	}
+
+	if err := krn.ExportIMCSession(&w, id); err != nil {
+		if w.started {
+			return web.NewNoResponseError(errs.FromSDK(err))
+		}
+		return errs.FromSDK(err)
+	}
+
+	return web.NewNoResponse()
+}
+
+func (a *app) importIMCSession(ctx context.Context, r *http.Request) web.Encoder {
+	modelID := r.URL.Query().Get("model")
+	if modelID == "" {
+		return errs.Errorf(errs.InvalidArgument, "missing model query parameter")
+	}
+
+	krn, err := a.pool.Kronk.AquireModel(ctx, modelID)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	detail, err := krn.ImportIMCSession(r.Body)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	a.log.Info(ctx, "imc-session-import", "model", modelID, "session", detail.ID, "tokens", detail.Context)
+
+	return toIMCSessions([]pool.IMCSessionDetail{{ModelID: modelID, IMCSessionDetail: detail}})[0]
+}
+
+func (a *app) listAdapters(ctx context.Context, r *http.Request) web.Encoder {
+	modelID := web.Param(r, "model")
+
+	krn, exists := a.pool.Kronk.GetExisting(modelID)
+	if !exists {
+		return errs.Errorf(errs.NotFound, "model %q is not loaded", modelID)
+	}
+
+	adapters := krn.Adapters()
+
+	a.log.Info(ctx, "list-adapters", "model", modelID, "len", len(adapters))
+
+	return toAdapters(adapters)
+}
+
+func (a *app) loadAdapter(ctx context.Context, r *http.Request) web.Encoder {
+	modelID := web.Param(r, "model")
+
+	var req AdapterLoadRequest
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	adapter, err := a.models.ResolveAdapter(req.toAdapterConfig())
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	krn, err := a.pool.Kronk.AquireModel(ctx, modelID)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	detail, err := krn.LoadAdapter(ctx, adapter)
+	if err != nil {
+		return errs.FromSDK(err)
+	}
+
+	a.log.Info(ctx, "load-adapter", "model", modelID, "adapter", detail.ID, "path", detail.Path)
+
+	return toAdapterDetail(detail)
+}
+
+func (a *app) unloadAdapter(ctx context.Context, r *http.Request) web.Encoder {
+	modelID := web.Param(r, "model")
+	adapterID := web.Param(r, "id")
+
+	krn, exists := a.pool.Kronk.GetExisting(modelID)
+	if !exists {
+		return errs.Errorf(errs.NotFound, "model %q is not loaded", modelID)
+	}
+
+	if err := krn.UnloadAdapter(ctx, adapterID); err != nil {
+		return errs.FromSDK(err)
+	}
+
+	a.log.Info(ctx, "unload-adapter", "model", modelID, "adapter", adapterID)
+
+	return UnloadResponse{Status: "unloaded", ID: adapterID}
+}
+
 func (a *app) imcSystemCaches(ctx context.Context, r *http.Request) web.Encoder {
 	caches := a.pool.Kronk.IMCSystemCaches()
 
@@ -593,10 +748,11 @@ func (a *app) unloadModel(ctx context.Context, r *http.Request) web.Encoder {
 
 	a.log.Info(ctx, "tool-unload", "modelID", req.ID)
 
-	// Look in the kronk pool first, then bucky. The two pools never
-	// share a cache key in practice (whisper short names like
-	// "ggml-tiny.bin" don't collide with llama model ids), but checking
-	// kronk first matches the historical behavior of this endpoint.
+	// Look in the kronk pool first, then bucky, then malina. The pools
+	// never share a cache key in practice (whisper short names like
+	// "ggml-tiny.bin" and bundle names like "sd-1.5" don't collide with
+	// llama model ids), but checking kronk first matches the historical
+	// behavior of this endpoint.
 	if krn, exists := a.pool.Kronk.GetExisting(req.ID); exists {
 		if n := krn.ActiveStreams(); n > 0 {
 			return errs.Errorf(errs.FailedPrecondition, "model has %d active stream(s); cannot unload", n)
@@ -628,6 +784,20 @@ func (a *app) unloadModel(ctx context.Context, r *http.Request) web.Encoder {
 		}
 	}
 
+	if a.pool.Malina != nil {
+		if m, exists := a.pool.Malina.GetExisting(req.ID); exists {
+			if n := m.ActiveStreams(); n > 0 {
+				return errs.Errorf(errs.FailedPrecondition, "model has %d active stream(s); cannot unload", n)
+			}
+
+			if err := a.pool.Malina.InvalidateSync(ctx, req.ID); err != nil {
+				return errs.FromSDK(fmt.Errorf("unload: %w", err))
+			}
+
+			return UnloadResponse{Status: "unloaded", ID: req.ID}
+		}
+	}
+
 	return errs.Errorf(errs.NotFound, "model %q is not loaded", req.ID)
 }
 
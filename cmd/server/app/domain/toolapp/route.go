@@ -61,9 +61,14 @@ func Routes(app *web.App, cfg Config) {
 	app.HandlerFunc(http.MethodGet, version, "/kronk/models/integrity", api.listModelsIntegrity, managementAccess)
 	app.HandlerFunc(http.MethodGet, version, "/kronk/models/integrity/{model}", api.retrieveModelIntegrity, managementAccess)
 	app.HandlerFunc(http.MethodGet, version, "/kronk/models/{model}", api.showModel, managementAccess)
+	app.HandlerFunc(http.MethodGet, version, "/kronk/models/adapters/{model}", api.listAdapters, managementAccess)
+	app.HandlerFunc(http.MethodPost, version, "/kronk/models/adapters/{model}", api.loadAdapter, administrationAccess)
+	app.HandlerFunc(http.MethodDelete, version, "/kronk/models/adapters/{model}/{id...}", api.unloadAdapter, administrationAccess)
 	app.HandlerFunc(http.MethodGet, version, "/kronk/models/ps", api.modelPS, managementAccess)
 	app.HandlerFunc(http.MethodGet, version, "/kronk/models/slots", api.batchEngineSlots, managementAccess)
 	app.HandlerFunc(http.MethodGet, version, "/kronk/models/imc-sessions", api.imcSessions, managementAccess)
+	app.HandlerFunc(http.MethodPost, version, "/kronk/models/imc-sessions/export", api.exportIMCSession, administrationAccess)
+	app.HandlerFunc(http.MethodPost, version, "/kronk/models/imc-sessions/import", api.importIMCSession, administrationAccess)
 	app.HandlerFunc(http.MethodGet, version, "/kronk/models/imc-system-caches", api.imcSystemCaches, managementAccess)
 	app.HandlerFunc(http.MethodPost, version, "/kronk/models/index", api.indexModels, administrationAccess)
 	app.HandlerFunc(http.MethodPost, version, "/kronk/models/pull", api.pullModels, administrationAccess)
@@ -116,7 +121,11 @@ func Routes(app *web.App, cfg Config) {
 
 	// Auth is handled by the auth service for these calls.
 	app.HandlerFunc(http.MethodPost, version, "/security/token/create", api.createToken, managementAccess)
+	app.HandlerFunc(http.MethodPost, version, "/security/token/revoke", api.revokeToken, managementAccess)
+	app.HandlerFunc(http.MethodPost, version, "/security/token/introspect", api.introspectToken, managementAccess)
+	app.HandlerFunc(http.MethodGet, version, "/security/tokens", api.listTokens, managementAccess)
 	app.HandlerFunc(http.MethodGet, version, "/security/keys", api.listKeys, managementAccess)
 	app.HandlerFunc(http.MethodPost, version, "/security/keys/add", api.addKey, managementAccess)
 	app.HandlerFunc(http.MethodPost, version, "/security/keys/remove/{keyid}", api.removeKey, managementAccess)
+	app.HandlerFunc(http.MethodGet, version, "/security/quotas/{subject}", api.tokenQuotas, managementAccess)
 }
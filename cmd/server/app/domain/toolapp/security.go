@@ -3,10 +3,14 @@ package toolapp
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/domain/authapp"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func (a *app) listKeys(ctx context.Context, r *http.Request) web.Encoder {
@@ -26,6 +30,10 @@ func (a *app) createToken(ctx context.Context, r *http.Request) web.Encoder {
 		return errs.New(errs.InvalidArgument, err)
 	}
 
+	if err := auth.ValidatePriority(req.Priority); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
 	bearerToken := r.Header.Get("Authorization")
 
 	endpoints := make(map[string]*authapp.RateLimit)
@@ -37,7 +45,32 @@ func (a *app) createToken(ctx context.Context, r *http.Request) web.Encoder {
 		}.Build()
 	}
 
-	resp, err := a.authClient.CreateToken(ctx, bearerToken, req.Admin, endpoints, req.Duration)
+	budgets := make([]auth.TokenBudget, len(req.TokenBudgets))
+	tokenBudgets := make([]*authapp.TokenBudget, len(req.TokenBudgets))
+	for i, tb := range req.TokenBudgets {
+		window, err := auth.ParseRateWindow(tb.Window)
+		if err != nil {
+			return errs.Errorf(errs.InvalidArgument, "token budget %q: %s", tb.Model, err)
+		}
+
+		budgets[i] = auth.TokenBudget{Model: tb.Model, Input: tb.Input, Output: tb.Output, Window: window}
+		tokenBudgets[i] = authapp.TokenBudget_builder{
+			Model:  &tb.Model,
+			Input:  &tb.Input,
+			Output: &tb.Output,
+			Window: &tb.Window,
+		}.Build()
+	}
+
+	if err := auth.ValidateTokenBudgets(budgets); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := auth.ValidateModels(req.Models); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	resp, err := a.authClient.CreateToken(ctx, bearerToken, req.Admin, endpoints, req.Duration, req.Priority, tokenBudgets, req.Models)
 	if err != nil {
 		return errs.New(errs.Internal, err)
 	}
@@ -47,6 +80,73 @@ func (a *app) createToken(ctx context.Context, r *http.Request) web.Encoder {
 	}
 }
 
+func (a *app) revokeToken(ctx context.Context, r *http.Request) web.Encoder {
+	var req RevokeTokenRequest
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if req.ID == "" && req.Token == "" {
+		return errs.Errorf(errs.InvalidArgument, "missing id or token")
+	}
+
+	bearerToken := r.Header.Get("Authorization")
+
+	token, err := a.authClient.RevokeToken(ctx, bearerToken, req.ID, req.Token)
+	if err != nil {
+		return authServiceError(err)
+	}
+
+	return toTokenInfo(token)
+}
+
+func (a *app) listTokens(ctx context.Context, r *http.Request) web.Encoder {
+	bearerToken := r.Header.Get("Authorization")
+
+	tokens, err := a.authClient.ListTokens(ctx, bearerToken)
+	if err != nil {
+		return authServiceError(err)
+	}
+
+	return toTokenInfos(tokens)
+}
+
+func (a *app) introspectToken(ctx context.Context, r *http.Request) web.Encoder {
+	var req IntrospectTokenRequest
+	if err := web.Decode(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if req.Token == "" {
+		return errs.Errorf(errs.InvalidArgument, "missing token")
+	}
+
+	bearerToken := r.Header.Get("Authorization")
+
+	resp, err := a.authClient.IntrospectToken(ctx, bearerToken, req.Token)
+	if err != nil {
+		return authServiceError(err)
+	}
+
+	return toIntrospection(resp, time.Now())
+}
+
+func (a *app) tokenQuotas(ctx context.Context, r *http.Request) web.Encoder {
+	subject := web.Param(r, "subject")
+	if subject == "" {
+		return errs.Errorf(errs.InvalidArgument, "missing subject")
+	}
+
+	bearerToken := r.Header.Get("Authorization")
+
+	resp, err := a.authClient.TokenQuotas(ctx, bearerToken, subject)
+	if err != nil {
+		return errs.New(errs.Internal, err)
+	}
+
+	return toTokenQuotas(subject, resp.Quotas)
+}
+
 func (a *app) addKey(ctx context.Context, r *http.Request) web.Encoder {
 	bearerToken := r.Header.Get("Authorization")
 
@@ -71,3 +171,24 @@ func (a *app) removeKey(ctx context.Context, r *http.Request) web.Encoder {
 
 	return nil
 }
+
+// authServiceError maps the status of a failed auth service call to the
+// matching API error.
+func authServiceError(err error) *errs.Error {
+	code := errs.Internal
+
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		code = errs.InvalidArgument
+	case codes.NotFound:
+		code = errs.NotFound
+	case codes.Unauthenticated:
+		code = errs.Unauthenticated
+	case codes.PermissionDenied:
+		code = errs.PermissionDenied
+	case codes.Unavailable:
+		code = errs.Unavailable
+	}
+
+	return errs.New(code, err)
+}
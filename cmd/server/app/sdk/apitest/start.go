@@ -11,6 +11,7 @@ import (
 	"github.com/ardanlabs/kronk/cmd/server/app/domain/authapp"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mux"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/respstore"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
@@ -18,11 +19,13 @@ import (
 	"github.com/ardanlabs/kronk/sdk/bucky"
 	"github.com/ardanlabs/kronk/sdk/kronk"
 	"github.com/ardanlabs/kronk/sdk/kronk/observ/otel"
+	"github.com/ardanlabs/kronk/sdk/malina"
 	"github.com/ardanlabs/kronk/sdk/pool"
 	buckylibs "github.com/ardanlabs/kronk/sdk/tools/bucky/libs"
 	buckymodels "github.com/ardanlabs/kronk/sdk/tools/bucky/models"
 	"github.com/ardanlabs/kronk/sdk/tools/defaults"
 	"github.com/ardanlabs/kronk/sdk/tools/libs"
+	malinamodels "github.com/ardanlabs/kronk/sdk/tools/malina/models"
 	"github.com/ardanlabs/kronk/sdk/tools/models"
 	"google.golang.org/grpc/test/bufconn"
 )
@@ -157,6 +160,15 @@ func New(t *testing.T, testName string) *Test {
 		t.Fatal(err)
 	}
 
+	malinaModels, err := malinamodels.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := malinaModels.BuildIndex(log.Info, false); err != nil {
+		t.Fatal(err)
+	}
+
 	// -------------------------------------------------------------------------
 	// Jinja Templates
 	//
@@ -169,7 +181,7 @@ func New(t *testing.T, testName string) *Test {
 	}
 
 	// -------------------------------------------------------------------------
-	// Init Kronk + Bucky
+	// Init Kronk + Bucky + Malina
 
 	if err := kronk.Init(); err != nil {
 		t.Fatal(err)
@@ -179,10 +191,15 @@ func New(t *testing.T, testName string) *Test {
 		log.Info(ctx, "startup", "WARNING", "bucky init failed, audio transcription tests will fail", "ERROR", err)
 	}
 
+	if err := malina.Init(); err != nil {
+		log.Info(ctx, "startup", "WARNING", "malina init failed, image generation tests will fail", "ERROR", err)
+	}
+
 	p, err := pool.New(pool.Config{
 		Log:             log.Info,
 		KronkModels:     models,
 		BuckyModels:     buckyModels,
+		MalinaModels:    malinaModels,
 		ModelConfigFile: "../../../../../../zarf/kms/model_config.yaml",
 		BudgetPercent:   95,
 		ModelsInPool:    10,
@@ -225,6 +242,7 @@ func New(t *testing.T, testName string) *Test {
 		BuckyLibs:        buckyLibs,
 		BuckyModels:      buckyModels,
 		InferenceTimeout: 60 * time.Minute,
+		ResponseStore:    respstore.NewMemory(0),
 	}
 
 	mux := mux.WebAPI(cfgMux,
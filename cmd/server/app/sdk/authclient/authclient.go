@@ -171,8 +171,50 @@ func (cln *Client) AuthenticateRequired(ctx context.Context, bearerToken string,
 	return resp, nil
 }
 
+// AuthorizeModel calls the auth service to check the bearer token may make
+// requests for the model. No endpoint is counted against the rate limits.
+func (cln *Client) AuthorizeModel(ctx context.Context, bearerToken string, model string) error {
+	if cln.localAuth && !cln.authEnabled {
+		return nil
+	}
+
+	arb := authapp.AuthenticateRequest_builder{
+		Admin:    new(false),
+		Endpoint: new(""),
+		Model:    &model,
+	}
+
+	ctx = injectTrace(ctx)
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", bearerToken)
+
+	_, err := cln.grpc.Authenticate(ctx, arb.Build())
+	return err
+}
+
+// AuthorizedModels calls the auth service to filter the models to those the
+// bearer token may use.
+func (cln *Client) AuthorizedModels(ctx context.Context, bearerToken string, models []string) ([]string, error) {
+	if cln.localAuth && !cln.authEnabled {
+		return models, nil
+	}
+
+	amrb := authapp.AuthorizedModelsRequest_builder{
+		Models: models,
+	}
+
+	ctx = injectTrace(ctx)
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", bearerToken)
+
+	req, err := cln.grpc.AuthorizedModels(ctx, amrb.Build())
+	if err != nil {
+		return nil, err
+	}
+
+	return req.GetModels(), nil
+}
+
 // CreateToken calls the auth service to create a new token.
-func (cln *Client) CreateToken(ctx context.Context, bearerToken string, admin bool, endpoints map[string]*authapp.RateLimit, duration time.Duration) (CreateTokenResponse, error) {
+func (cln *Client) CreateToken(ctx context.Context, bearerToken string, admin bool, endpoints map[string]*authapp.RateLimit, duration time.Duration, priority string, tokenBudgets []*authapp.TokenBudget, models []string) (CreateTokenResponse, error) {
 	protoEndpoints := make(map[string]*authapp.RateLimit)
 	for name, rl := range endpoints {
 		protoEndpoints[name] = authapp.RateLimit_builder{
@@ -182,9 +224,12 @@ func (cln *Client) CreateToken(ctx context.Context, bearerToken string, admin bo
 	}
 
 	arb := authapp.CreateTokenRequest_builder{
-		Admin:     &admin,
-		Endpoints: protoEndpoints,
-		Duration:  new(duration.String()),
+		Admin:        &admin,
+		Endpoints:    protoEndpoints,
+		Duration:     new(duration.String()),
+		Priority:     &priority,
+		TokenBudgets: tokenBudgets,
+		Models:       models,
 	}
 
 	ctx = injectTrace(ctx)
@@ -232,3 +277,114 @@ func (cln *Client) RemoveKey(ctx context.Context, bearerToken string, keyID stri
 	_, err := cln.grpc.RemoveKey(ctx, rkb.Build())
 	return err
 }
+
+// CheckTokens calls the auth service to check the token budgets that apply to
+// a request for the model.
+func (cln *Client) CheckTokens(ctx context.Context, bearerToken string, model string) (TokenQuotasResponse, error) {
+	ctrb := authapp.CheckTokensRequest_builder{
+		Model: &model,
+	}
+
+	ctx = injectTrace(ctx)
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", bearerToken)
+
+	req, err := cln.grpc.CheckTokens(ctx, ctrb.Build())
+	if err != nil {
+		return TokenQuotasResponse{}, err
+	}
+
+	return toTokenQuotasResponse(req), nil
+}
+
+// ChargeTokens calls the auth service to charge the tokens a completed
+// request for the model used.
+func (cln *Client) ChargeTokens(ctx context.Context, bearerToken string, model string, input int64, output int64) (TokenQuotasResponse, error) {
+	ctrb := authapp.ChargeTokensRequest_builder{
+		Model:  &model,
+		Input:  &input,
+		Output: &output,
+	}
+
+	ctx = injectTrace(ctx)
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", bearerToken)
+
+	req, err := cln.grpc.ChargeTokens(ctx, ctrb.Build())
+	if err != nil {
+		return TokenQuotasResponse{}, err
+	}
+
+	return toTokenQuotasResponse(req), nil
+}
+
+// TokenQuotas calls the auth service to list the token budget use of a
+// subject.
+func (cln *Client) TokenQuotas(ctx context.Context, bearerToken string, subject string) (TokenQuotasResponse, error) {
+	tqrb := authapp.TokenQuotasRequest_builder{
+		Subject: &subject,
+	}
+
+	ctx = injectTrace(ctx)
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", bearerToken)
+
+	req, err := cln.grpc.TokenQuotas(ctx, tqrb.Build())
+	if err != nil {
+		return TokenQuotasResponse{}, err
+	}
+
+	return toTokenQuotasResponse(req), nil
+}
+
+// RevokeToken calls the auth service to revoke a token by ID or by the token
+// itself.
+func (cln *Client) RevokeToken(ctx context.Context, bearerToken string, id string, token string) (TokenInfo, error) {
+	rtrb := authapp.RevokeTokenRequest_builder{
+		Id:    &id,
+		Token: &token,
+	}
+
+	ctx = injectTrace(ctx)
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", bearerToken)
+
+	req, err := cln.grpc.RevokeToken(ctx, rtrb.Build())
+	if err != nil {
+		return TokenInfo{}, err
+	}
+
+	return toTokenInfo(req.GetToken()), nil
+}
+
+// ListTokens calls the auth service to list the issued and revoked tokens.
+func (cln *Client) ListTokens(ctx context.Context, bearerToken string) ([]TokenInfo, error) {
+	ctx = injectTrace(ctx)
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", bearerToken)
+
+	req, err := cln.grpc.ListTokens(ctx, &authapp.ListTokensRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]TokenInfo, len(req.GetTokens()))
+	for i, token := range req.GetTokens() {
+		tokens[i] = toTokenInfo(token)
+	}
+
+	return tokens, nil
+}
+
+// IntrospectToken calls the auth service to report a token's claims, expiry
+// and remaining rate budget.
+func (cln *Client) IntrospectToken(ctx context.Context, bearerToken string, token string) (IntrospectTokenResponse, error) {
+	itrb := authapp.IntrospectTokenRequest_builder{
+		Token: &token,
+	}
+
+	ctx = injectTrace(ctx)
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", bearerToken)
+
+	req, err := cln.grpc.IntrospectToken(ctx, itrb.Build())
+	if err != nil {
+		return IntrospectTokenResponse{}, err
+	}
+
+	return toIntrospectTokenResponse(req), nil
+}
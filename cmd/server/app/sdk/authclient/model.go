@@ -1,16 +1,38 @@
 package authclient
 
-import "github.com/ardanlabs/kronk/cmd/server/app/domain/authapp"
+import (
+	"time"
 
-// AuthenticateReponse is the response for the auth service.
+	"github.com/ardanlabs/kronk/cmd/server/app/domain/authapp"
+)
+
+// AuthenticateReponse is the response for the auth service. TokenBudgets
+// reports whether the token carries token budgets and ModelScoped whether it
+// is limited to a set of models. TokenID and ExpiresAt identify the token and
+// are empty when authentication is disabled.
 type AuthenticateReponse struct {
-	Subject string
+	Subject      string
+	Priority     string
+	TokenBudgets bool
+	ModelScoped  bool
+	TokenID      string
+	ExpiresAt    time.Time
 }
 
 func toAuthenticateReponse(req *authapp.AuthenticateResponse) AuthenticateReponse {
-	return AuthenticateReponse{
-		Subject: req.GetSubject(),
+	resp := AuthenticateReponse{
+		Subject:      req.GetSubject(),
+		Priority:     req.GetPriority(),
+		TokenBudgets: req.GetTokenBudgets(),
+		ModelScoped:  req.GetModelScoped(),
+		TokenID:      req.GetTokenId(),
+	}
+
+	if req.GetExpiresAt() != 0 {
+		resp.ExpiresAt = time.Unix(req.GetExpiresAt(), 0).UTC()
 	}
+
+	return resp
 }
 
 // CreateTokenResponse is the response for the auth service.
@@ -45,3 +67,124 @@ func toListKeysResponse(req *authapp.ListKeysResponse) ListKeysResponse {
 	}
 	return ListKeysResponse{Keys: keys}
 }
+
+// TokenQuota reports the use of a token budget within its current window. A
+// zero limit leaves that direction uncapped.
+type TokenQuota struct {
+	Model       string
+	Window      string
+	InputLimit  int64
+	InputUsed   int64
+	OutputLimit int64
+	OutputUsed  int64
+	Reset       time.Time
+}
+
+// TokenQuotasResponse is the response for token budget requests. Exceeded
+// reports whether any of the quotas is exhausted.
+type TokenQuotasResponse struct {
+	Quotas   []TokenQuota
+	Exceeded bool
+}
+
+func toTokenQuotasResponse(req *authapp.TokenQuotasResponse) TokenQuotasResponse {
+	return TokenQuotasResponse{
+		Quotas:   toTokenQuotas(req.GetQuotas()),
+		Exceeded: req.GetExceeded(),
+	}
+}
+
+func toTokenQuotas(protoQuotas []*authapp.TokenQuota) []TokenQuota {
+	quotas := make([]TokenQuota, len(protoQuotas))
+	for i, q := range protoQuotas {
+		quotas[i] = TokenQuota{
+			Model:       q.GetModel(),
+			Window:      q.GetWindow(),
+			InputLimit:  q.GetInputLimit(),
+			InputUsed:   q.GetInputUsed(),
+			OutputLimit: q.GetOutputLimit(),
+			OutputUsed:  q.GetOutputUsed(),
+			Reset:       time.Unix(q.GetReset(), 0).UTC(),
+		}
+	}
+
+	return quotas
+}
+
+// TokenInfo describes an issued token. A zero RevokedAt means the token is
+// not revoked.
+type TokenInfo struct {
+	ID        string
+	Subject   string
+	Admin     bool
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+func toTokenInfo(req *authapp.TokenInfo) TokenInfo {
+	info := TokenInfo{
+		ID:        req.GetId(),
+		Subject:   req.GetSubject(),
+		Admin:     req.GetAdmin(),
+		IssuedAt:  time.Unix(req.GetIssuedAt(), 0).UTC(),
+		ExpiresAt: time.Unix(req.GetExpiresAt(), 0).UTC(),
+	}
+
+	if req.GetRevokedAt() != 0 {
+		info.RevokedAt = time.Unix(req.GetRevokedAt(), 0).UTC()
+	}
+
+	return info
+}
+
+// EndpointUsage reports the requests counted against an endpoint's rate
+// limit within its current window. A zero Reset means the endpoint is
+// unlimited.
+type EndpointUsage struct {
+	Endpoint string
+	Limit    int
+	Window   string
+	Used     int64
+	Reset    time.Time
+}
+
+// IntrospectTokenResponse is the response for token introspection. Only
+// Active is set for a token that does not verify or has expired. An empty
+// Models means the token may use every model.
+type IntrospectTokenResponse struct {
+	Active       bool
+	Token        TokenInfo
+	Priority     string
+	Endpoints    []EndpointUsage
+	TokenBudgets []TokenQuota
+	Models       []string
+}
+
+func toIntrospectTokenResponse(req *authapp.IntrospectTokenResponse) IntrospectTokenResponse {
+	if !req.HasToken() {
+		return IntrospectTokenResponse{Active: req.GetActive()}
+	}
+
+	endpoints := make([]EndpointUsage, len(req.GetEndpoints()))
+	for i, e := range req.GetEndpoints() {
+		endpoints[i] = EndpointUsage{
+			Endpoint: e.GetEndpoint(),
+			Limit:    int(e.GetLimit().GetLimit()),
+			Window:   e.GetLimit().GetWindow(),
+			Used:     e.GetUsed(),
+		}
+		if e.GetReset() != 0 {
+			endpoints[i].Reset = time.Unix(e.GetReset(), 0).UTC()
+		}
+	}
+
+	return IntrospectTokenResponse{
+		Active:       req.GetActive(),
+		Token:        toTokenInfo(req.GetToken()),
+		Priority:     req.GetPriority(),
+		Endpoints:    endpoints,
+		TokenBudgets: toTokenQuotas(req.GetTokenBudgets()),
+		Models:       req.GetModels(),
+	}
+}
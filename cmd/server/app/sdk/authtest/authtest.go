@@ -0,0 +1,101 @@
+// Package authtest runs the auth service in process so handler tests can
+// authenticate requests and charge token budgets the way the server does.
+package authtest
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/domain/authapp"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Auth is an auth service running over an in-memory listener.
+type Auth struct {
+	Log    *logger.Logger
+	Client *authclient.Client
+	Sec    *security.Security
+}
+
+// Start runs the auth service for the duration of the test.
+func Start(t *testing.T) Auth {
+	t.Helper()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	sec, err := security.New(security.Config{
+		OverrideBaseKeysFolder: t.TempDir(),
+		Issuer:                 "kronk project",
+	})
+	if err != nil {
+		t.Fatalf("should be able to construct security: %s", err)
+	}
+	t.Cleanup(func() { sec.Close() })
+
+	lis := bufconn.Listen(1024 * 1024)
+
+	authApp := authapp.Start(t.Context(), authapp.Config{
+		Log:      log,
+		Security: sec,
+		Listener: lis,
+		Tracer:   noop.NewTracerProvider().Tracer("authtest"),
+		Enabled:  true,
+	})
+	t.Cleanup(func() { authApp.Shutdown(context.Background()) })
+
+	client, err := authclient.New(log, "passthrough:///bufnet", authclient.WithDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	if err != nil {
+		t.Fatalf("should be able to construct auth client: %s", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	return Auth{
+		Log:    log,
+		Client: client,
+		Sec:    sec,
+	}
+}
+
+// Token issues a bearer token for the endpoint, without a rate limit, with
+// the specified token budgets.
+func (a Auth) Token(t *testing.T, endpoint string, budgets []auth.TokenBudget) string {
+	t.Helper()
+
+	endpoints := map[string]auth.RateLimit{
+		endpoint: {Window: auth.RateUnlimited},
+	}
+
+	token, err := a.Sec.GenerateToken(false, endpoints, time.Hour, security.WithTokenBudgets(budgets))
+	if err != nil {
+		t.Fatalf("should be able to generate token: %s", err)
+	}
+
+	return "Bearer " + token
+}
+
+// Used returns the input and output tokens charged to the first budget of
+// the token that applies to model.
+func (a Auth) Used(t *testing.T, bearer string, model string) (int64, int64) {
+	t.Helper()
+
+	resp, err := a.Client.CheckTokens(t.Context(), bearer, model)
+	if err != nil {
+		t.Fatalf("should be able to check tokens: %s", err)
+	}
+
+	if len(resp.Quotas) == 0 {
+		t.Fatalf("token has no budget for model %q", model)
+	}
+
+	return resp.Quotas[0].InputUsed, resp.Quotas[0].OutputUsed
+}
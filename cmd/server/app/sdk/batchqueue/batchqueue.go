@@ -0,0 +1,443 @@
+// Package batchqueue provides a persistent queue of batch jobs. Each job
+// replays the lines of an uploaded JSONL file through the server's own
+// handlers at low priority and collects the responses into output and error
+// files.
+package batchqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"uuid"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+)
+
+var (
+	// ErrNotFound is returned when a batch is not stored for the subject.
+	ErrNotFound = errors.New("batch not found")
+
+	// ErrFinished is returned when cancelling a batch that already finished.
+	ErrFinished = errors.New("batch already finished")
+)
+
+// The statuses a batch moves through. Validating batches are waiting for
+// the queue to reach them.
+const (
+	StatusValidating = "validating"
+	StatusInProgress = "in_progress"
+	StatusFinalizing = "finalizing"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+	StatusExpired    = "expired"
+	StatusCancelling = "cancelling"
+	StatusCancelled  = "cancelled"
+)
+
+// CompletionWindow is the only completion window supported.
+const CompletionWindow = "24h"
+
+// purposeOutput is the purpose of the output and error files the queue
+// creates in the file store.
+const purposeOutput = "batch_output"
+
+// LineError describes a problem with a batch or one of its input lines.
+type LineError struct {
+	Code    string  `json:"code"`
+	Message string  `json:"message"`
+	Param   *string `json:"param"`
+	Line    *int    `json:"line"`
+}
+
+// RequestCounts reports the progress of a batch.
+type RequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// Token is the bearer token that created a batch. Each line is dispatched
+// with it, so the lines are authenticated, rate limited and charged to the
+// token's budgets like any other request, and the batch stops once the token
+// is revoked or expires. ID and ExpiresAt are empty when authentication is
+// disabled.
+type Token struct {
+	ID        string    `json:"id,omitempty"`
+	Bearer    string    `json:"bearer,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+}
+
+// Batch is the persisted record of a batch job.
+type Batch struct {
+	ID               string            `json:"id"`
+	Subject          string            `json:"subject"`
+	Endpoint         string            `json:"endpoint"`
+	InputFileID      string            `json:"input_file_id"`
+	CompletionWindow string            `json:"completion_window"`
+	Status           string            `json:"status"`
+	OutputFileID     string            `json:"output_file_id,omitempty"`
+	ErrorFileID      string            `json:"error_file_id,omitempty"`
+	Errors           []LineError       `json:"errors,omitempty"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+	RequestCounts    RequestCounts     `json:"request_counts"`
+	CreatedAt        time.Time         `json:"created_at"`
+	InProgressAt     time.Time         `json:"in_progress_at,omitzero"`
+	ExpiresAt        time.Time         `json:"expires_at,omitzero"`
+	FinalizingAt     time.Time         `json:"finalizing_at,omitzero"`
+	CompletedAt      time.Time         `json:"completed_at,omitzero"`
+	FailedAt         time.Time         `json:"failed_at,omitzero"`
+	ExpiredAt        time.Time         `json:"expired_at,omitzero"`
+	CancellingAt     time.Time         `json:"cancelling_at,omitzero"`
+	CancelledAt      time.Time         `json:"cancelled_at,omitzero"`
+	Token            Token             `json:"token,omitzero"`
+
+	// Progress through the input file. Offset counts the requests whose
+	// results are in the partial output and error files, and the byte counts
+	// are the sizes of those files at that point, so a batch interrupted by a
+	// restart resumes from the last recorded request.
+	Offset      int   `json:"offset"`
+	OutputBytes int64 `json:"output_bytes"`
+	ErrorBytes  int64 `json:"error_bytes"`
+}
+
+// Finished reports whether the batch reached a final status.
+func (b Batch) Finished() bool {
+	switch b.Status {
+	case StatusCompleted, StatusFailed, StatusExpired, StatusCancelled:
+		return true
+	}
+
+	return false
+}
+
+// NewBatch contains the information needed to queue a batch.
+type NewBatch struct {
+	Subject     string
+	Endpoint    string
+	InputFileID string
+	Metadata    map[string]string
+	Token       Token
+	Input       Input
+}
+
+// Authenticator checks the token that created a batch before each round of
+// its lines is dispatched.
+type Authenticator interface {
+	Authenticate(ctx context.Context, bearerToken string, admin bool, endpoint string) (authclient.AuthenticateReponse, error)
+	CheckTokens(ctx context.Context, bearerToken string, model string) (authclient.TokenQuotasResponse, error)
+}
+
+// Config holds the configuration for the queue. Auth is optional; without
+// it the lines are still authenticated by the handler they are dispatched
+// to, but the batch is not stopped when its token stops being valid.
+type Config struct {
+	Log         *logger.Logger
+	Dir         string
+	Files       *filestore.Store
+	Auth        Authenticator
+	Concurrency int
+}
+
+// Queue stores batch records on disk and runs queued batches one at a time,
+// in the order they were created.
+type Queue struct {
+	log         *logger.Logger
+	dir         string
+	files       *filestore.Store
+	auth        Authenticator
+	concurrency int
+	wake        chan struct{}
+
+	mu      sync.Mutex
+	batches map[string]Batch
+
+	handler http.Handler
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// New opens the queue rooted at the configured directory and loads the
+// batches already stored there. Batches that were running when the server
+// stopped continue once Start is called. Concurrency is the number of lines
+// of a batch dispatched at the same time.
+func New(cfg Config) (*Queue, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("new: create %s: %w", cfg.Dir, err)
+	}
+
+	q := Queue{
+		log:         cfg.Log,
+		dir:         cfg.Dir,
+		files:       cfg.Files,
+		auth:        cfg.Auth,
+		concurrency: max(cfg.Concurrency, 1),
+		wake:        make(chan struct{}, 1),
+		batches:     make(map[string]Batch),
+	}
+
+	entries, err := os.ReadDir(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("new: read %s: %w", cfg.Dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(cfg.Dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("new: read %s: %w", entry.Name(), err)
+		}
+
+		var b Batch
+		if err := json.Unmarshal(data, &b); err != nil {
+			return nil, fmt.Errorf("new: unmarshal %s: %w", entry.Name(), err)
+		}
+
+		q.batches[b.ID] = b
+	}
+
+	return &q, nil
+}
+
+// Start begins running queued batches, dispatching each line to handler.
+func (q *Queue) Start(handler http.Handler) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q.handler = handler
+	q.cancel = cancel
+	q.done = make(chan struct{})
+
+	go q.run(ctx)
+}
+
+// Shutdown stops the queue. Lines being dispatched are abandoned and run
+// again when the queue is next started.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	if q.cancel == nil {
+		return nil
+	}
+
+	q.cancel()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("shutdown: %w", ctx.Err())
+	}
+}
+
+// Create stores a new batch for the parsed input. A batch whose input has
+// line errors is stored as failed and never runs.
+func (q *Queue) Create(ctx context.Context, nb NewBatch) (Batch, error) {
+	now := time.Now().UTC()
+
+	b := Batch{
+		ID:               "batch_" + uuid.New().String(),
+		Subject:          nb.Subject,
+		Endpoint:         nb.Endpoint,
+		InputFileID:      nb.InputFileID,
+		CompletionWindow: CompletionWindow,
+		Status:           StatusValidating,
+		Metadata:         nb.Metadata,
+		Token:            nb.Token,
+		RequestCounts:    RequestCounts{Total: nb.Input.Total},
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(24 * time.Hour),
+	}
+
+	if len(nb.Input.Errors) > 0 {
+		b.Status = StatusFailed
+		b.FailedAt = now
+		b.Errors = nb.Input.Errors
+		b.RequestCounts.Total = 0
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.save(b); err != nil {
+		return Batch{}, fmt.Errorf("create: %w", err)
+	}
+
+	q.batches[b.ID] = b
+
+	if !b.Finished() {
+		q.signal()
+	}
+
+	return b, nil
+}
+
+// QueryByID returns the subject's batch with the specified ID. Batches owned
+// by another subject are reported as not found.
+func (q *Queue) QueryByID(ctx context.Context, subject string, id string) (Batch, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b, exists := q.batches[id]
+	if !exists || b.Subject != subject {
+		return Batch{}, fmt.Errorf("query-by-id: %s: %w", id, ErrNotFound)
+	}
+
+	return b, nil
+}
+
+// List returns the subject's batches, newest first.
+func (q *Queue) List(ctx context.Context, subject string) []Batch {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var batches []Batch
+	for _, b := range q.batches {
+		if b.Subject == subject {
+			batches = append(batches, b)
+		}
+	}
+
+	slices.SortFunc(batches, func(a, b Batch) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return batches
+}
+
+// Cancel asks the queue to stop the subject's batch. A batch that has not
+// started is cancelled at once; a running batch moves to cancelling and is
+// cancelled once the lines being dispatched finish, keeping their results.
+func (q *Queue) Cancel(ctx context.Context, subject string, id string) (Batch, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b, exists := q.batches[id]
+	if !exists || b.Subject != subject {
+		return Batch{}, fmt.Errorf("cancel: %s: %w", id, ErrNotFound)
+	}
+
+	switch {
+	case b.Finished():
+		return Batch{}, fmt.Errorf("cancel: %s: %w with status %s", id, ErrFinished, b.Status)
+
+	case b.Status == StatusCancelling:
+		return b, nil
+	}
+
+	now := time.Now().UTC()
+
+	b.CancellingAt = now
+	b.Status = StatusCancelling
+
+	if b.InProgressAt.IsZero() {
+		b.Status = StatusCancelled
+		b.CancelledAt = now
+	}
+
+	if err := q.save(b); err != nil {
+		return Batch{}, fmt.Errorf("cancel: %w", err)
+	}
+
+	q.batches[id] = b
+
+	return b, nil
+}
+
+// =============================================================================
+
+// signal wakes the runner without blocking when it is already awake.
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// get returns a copy of the batch with the specified ID.
+func (q *Queue) get(id string) (Batch, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b, exists := q.batches[id]
+	return b, exists
+}
+
+// update applies fn to the stored batch and persists the result. The batch
+// is read under the lock so a concurrent Cancel is never overwritten.
+func (q *Queue) update(id string, fn func(b *Batch)) (Batch, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b, exists := q.batches[id]
+	if !exists {
+		return Batch{}, fmt.Errorf("update: %s: %w", id, ErrNotFound)
+	}
+
+	fn(&b)
+
+	if err := q.save(b); err != nil {
+		return Batch{}, fmt.Errorf("update: %w", err)
+	}
+
+	q.batches[id] = b
+
+	return b, nil
+}
+
+// next returns the ID of the oldest batch that has not finished.
+func (q *Queue) next() (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var next Batch
+	for _, b := range q.batches {
+		if b.Finished() {
+			continue
+		}
+
+		if next.ID == "" || b.CreatedAt.Before(next.CreatedAt) || (b.CreatedAt.Equal(next.CreatedAt) && b.ID < next.ID) {
+			next = b
+		}
+	}
+
+	return next.ID, next.ID != ""
+}
+
+// save writes the batch record to disk, replacing the previous record
+// atomically. The caller must hold the lock.
+func (q *Queue) save(b Batch) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("save: marshal: %w", err)
+	}
+
+	// The record holds the bearer token the batch runs with.
+	tmp := q.path(b.ID, ".json.tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("save: write: %w", err)
+	}
+
+	if err := os.Rename(tmp, q.path(b.ID, ".json")); err != nil {
+		return fmt.Errorf("save: rename: %w", err)
+	}
+
+	return nil
+}
+
+func (q *Queue) path(id string, ext string) string {
+	return filepath.Join(q.dir, id+ext)
+}
@@ -0,0 +1,474 @@
+package batchqueue_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/batchqueue"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	endpoint = "/v1/chat/completions"
+	bearer   = "Bearer batch-token"
+)
+
+func Test_ParseInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		code  string
+	}{
+		{name: "empty", input: "\n\n", code: "empty_file"},
+		{name: "invalid json", input: `{"custom_id":`, code: "invalid_json_line"},
+		{name: "missing custom id", input: line("", endpoint, `{"model":"m"}`), code: "missing_required_parameter"},
+		{name: "duplicate custom id", input: line("a", endpoint, `{"model":"m"}`) + "\n" + line("a", endpoint, `{"model":"m"}`), code: "duplicate_custom_id"},
+		{name: "other endpoint", input: line("a", "/v1/embeddings", `{"model":"m"}`), code: "mismatched_endpoint"},
+		{name: "missing model", input: line("a", endpoint, `{"messages":[]}`), code: "missing_required_parameter"},
+		{name: "body not an object", input: line("a", endpoint, `"hello"`), code: "invalid_request"},
+		{name: "stream", input: line("a", endpoint, `{"model":"m","stream":true}`), code: "invalid_request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := batchqueue.ParseInput(strings.NewReader(tt.input), endpoint)
+			if len(input.Errors) != 1 || input.Errors[0].Code != tt.code {
+				t.Fatalf("errors: got %+v, want one %s error", input.Errors, tt.code)
+			}
+		})
+	}
+
+	input := batchqueue.ParseInput(strings.NewReader(lines("a", "b", "c")), endpoint)
+	if len(input.Errors) != 0 || input.Total != 3 {
+		t.Fatalf("valid input: got %d requests and errors %+v", input.Total, input.Errors)
+	}
+
+	if len(input.Models) != 1 || input.Models[0] != "model-a" {
+		t.Errorf("models: got %v, want [model-a]", input.Models)
+	}
+}
+
+func Test_Queue(t *testing.T) {
+	files, queue := newQueue(t, t.TempDir(), nil)
+
+	queue.Start(handlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get(mid.PriorityHeader); got != "low" {
+			t.Errorf("priority: got %q, want low", got)
+		}
+
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		if body.Messages[0].Content == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"bad request"}}`))
+			return
+		}
+
+		w.Write([]byte(`{"object":"chat.completion"}`))
+	}))
+	t.Cleanup(func() { queue.Shutdown(context.Background()) })
+
+	b := createBatch(t, files, queue, lines("ok-1", "bad", "ok-2"))
+	b = waitFor(t, queue, b.ID, batchqueue.StatusCompleted)
+
+	if b.RequestCounts != (batchqueue.RequestCounts{Total: 3, Completed: 2, Failed: 1}) {
+		t.Errorf("counts: got %+v", b.RequestCounts)
+	}
+
+	if got := customIDs(t, files, b.OutputFileID); got != "ok-1,ok-2" {
+		t.Errorf("output file: got %s, want ok-1,ok-2", got)
+	}
+
+	if got := customIDs(t, files, b.ErrorFileID); got != "bad" {
+		t.Errorf("error file: got %s, want bad", got)
+	}
+
+	failed := createBatch(t, files, queue, line("a", "/v1/embeddings", `{"model":"m"}`))
+	if failed.Status != batchqueue.StatusFailed || len(failed.Errors) != 1 {
+		t.Errorf("invalid input: got status %s and errors %+v", failed.Status, failed.Errors)
+	}
+
+	if _, err := queue.QueryByID(t.Context(), "user-2", b.ID); !errors.Is(err, batchqueue.ErrNotFound) {
+		t.Errorf("query other subject: got %v, want %v", err, batchqueue.ErrNotFound)
+	}
+
+	if got := queue.List(t.Context(), "user-1"); len(got) != 2 || got[0].ID != failed.ID {
+		t.Errorf("list: got %d batches, want the newest first", len(got))
+	}
+}
+
+func Test_QueueResume(t *testing.T) {
+	dir := t.TempDir()
+
+	files, queue := newQueue(t, dir, nil)
+
+	// The first server answers one request and then stops while the second
+	// is being handled.
+	var handled atomic.Int32
+	queue.Start(handlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if handled.Add(1) > 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+
+	b := createBatch(t, files, queue, lines("a", "b", "c"))
+	waitUntil(t, func() bool {
+		got, _ := queue.QueryByID(t.Context(), "user-1", b.ID)
+		return got.RequestCounts.Completed == 1 && handled.Load() > 1
+	})
+
+	if err := queue.Shutdown(t.Context()); err != nil {
+		t.Fatalf("should be able to shut down: %s", err)
+	}
+
+	files, restarted := newQueue(t, dir, nil)
+
+	var resumed []string
+	restarted.Start(handlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		resumed = append(resumed, body.Messages[0].Content)
+
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(func() { restarted.Shutdown(context.Background()) })
+
+	b = waitFor(t, restarted, b.ID, batchqueue.StatusCompleted)
+
+	if strings.Join(resumed, ",") != "b,c" {
+		t.Errorf("resumed: got %v, want the requests after the last recorded one", resumed)
+	}
+
+	if got := customIDs(t, files, b.OutputFileID); got != "a,b,c" {
+		t.Errorf("output file: got %s, want a,b,c", got)
+	}
+}
+
+func Test_QueueCancel(t *testing.T) {
+	files, queue := newQueue(t, t.TempDir(), nil)
+
+	queued := createBatch(t, files, queue, lines("a", "b", "c"))
+
+	cancelled, err := queue.Cancel(t.Context(), "user-1", queued.ID)
+	if err != nil {
+		t.Fatalf("should be able to cancel: %s", err)
+	}
+
+	if cancelled.Status != batchqueue.StatusCancelled {
+		t.Errorf("queued batch: got status %s, want %s", cancelled.Status, batchqueue.StatusCancelled)
+	}
+
+	if _, err := queue.Cancel(t.Context(), "user-1", queued.ID); !errors.Is(err, batchqueue.ErrFinished) {
+		t.Errorf("cancel finished batch: got %v, want %v", err, batchqueue.ErrFinished)
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	queue.Start(handlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(func() { queue.Shutdown(context.Background()) })
+
+	running := createBatch(t, files, queue, lines("a", "b", "c"))
+	<-started
+
+	cancelling, err := queue.Cancel(t.Context(), "user-1", running.ID)
+	if err != nil {
+		t.Fatalf("should be able to cancel: %s", err)
+	}
+
+	if cancelling.Status != batchqueue.StatusCancelling {
+		t.Errorf("running batch: got status %s, want %s", cancelling.Status, batchqueue.StatusCancelling)
+	}
+
+	close(release)
+
+	b := waitFor(t, queue, running.ID, batchqueue.StatusCancelled)
+
+	if b.RequestCounts != (batchqueue.RequestCounts{Total: 3, Completed: 1}) {
+		t.Errorf("counts: got %+v, want the request in flight kept", b.RequestCounts)
+	}
+
+	if got := customIDs(t, files, b.OutputFileID); got != "a" {
+		t.Errorf("output file: got %s, want a", got)
+	}
+}
+
+func Test_QueueTokenRevoked(t *testing.T) {
+	auth := authStub{}
+	files, queue := newQueue(t, t.TempDir(), &auth)
+
+	// The token is revoked while the second line is being handled, so the
+	// lines after it are never sent.
+	var sent []string
+	queue.Start(handlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != bearer {
+			t.Errorf("authorization: got %q, want %q", got, bearer)
+		}
+
+		var body struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		sent = append(sent, body.Messages[0].Content)
+
+		if body.Messages[0].Content == "b" {
+			auth.revoked.Store(true)
+		}
+
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(func() { queue.Shutdown(context.Background()) })
+
+	b := createBatch(t, files, queue, lines("a", "b", "c", "d"))
+	b = waitFor(t, queue, b.ID, batchqueue.StatusFailed)
+
+	if strings.Join(sent, ",") != "a,b" {
+		t.Errorf("sent: got %v, want only the lines before the revocation", sent)
+	}
+
+	if b.RequestCounts != (batchqueue.RequestCounts{Total: 4, Completed: 2, Failed: 2}) {
+		t.Errorf("counts: got %+v", b.RequestCounts)
+	}
+
+	if len(b.Errors) != 1 || b.Errors[0].Code != "invalid_token" || b.FailedAt.IsZero() {
+		t.Errorf("errors: got %+v failed at %v, want one invalid_token error", b.Errors, b.FailedAt)
+	}
+
+	if got := customIDs(t, files, b.OutputFileID); got != "a,b" {
+		t.Errorf("output file: got %s, want a,b", got)
+	}
+
+	if got := resultCodes(t, files, b.ErrorFileID); got != "c:invalid_token,d:invalid_token" {
+		t.Errorf("error file: got %s, want c and d failed with invalid_token", got)
+	}
+}
+
+func Test_QueueTokenBudget(t *testing.T) {
+	auth := authStub{budgets: true}
+	auth.exceeded.Store(true)
+
+	files, queue := newQueue(t, t.TempDir(), &auth)
+
+	var sent atomic.Int32
+	queue.Start(handlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent.Add(1)
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(func() { queue.Shutdown(context.Background()) })
+
+	b := createBatch(t, files, queue, lines("a", "b"))
+	b = waitFor(t, queue, b.ID, batchqueue.StatusCompleted)
+
+	if sent.Load() != 0 {
+		t.Errorf("sent: got %d requests, want none once the budget is used up", sent.Load())
+	}
+
+	if b.RequestCounts != (batchqueue.RequestCounts{Total: 2, Failed: 2}) {
+		t.Errorf("counts: got %+v", b.RequestCounts)
+	}
+
+	if got := resultCodes(t, files, b.ErrorFileID); got != "a:token_budget_exceeded,b:token_budget_exceeded" {
+		t.Errorf("error file: got %s, want both lines over budget", got)
+	}
+}
+
+// =============================================================================
+
+type authStub struct {
+	budgets  bool
+	revoked  atomic.Bool
+	exceeded atomic.Bool
+}
+
+func (as *authStub) Authenticate(_ context.Context, bearerToken string, _ bool, _ string) (authclient.AuthenticateReponse, error) {
+	if bearerToken != bearer || as.revoked.Load() {
+		return authclient.AuthenticateReponse{}, status.Error(codes.Unauthenticated, "token has been revoked")
+	}
+
+	return authclient.AuthenticateReponse{Subject: "user-1", TokenBudgets: as.budgets}, nil
+}
+
+func (as *authStub) CheckTokens(context.Context, string, string) (authclient.TokenQuotasResponse, error) {
+	return authclient.TokenQuotasResponse{Exceeded: as.exceeded.Load()}, nil
+}
+
+type handlerFunc func(w http.ResponseWriter, r *http.Request)
+
+func (f handlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f(w, r)
+}
+
+func newQueue(t *testing.T, dir string, auth batchqueue.Authenticator) (*filestore.Store, *batchqueue.Queue) {
+	t.Helper()
+
+	files, err := filestore.New(filestore.Config{Dir: dir + "/files"})
+	if err != nil {
+		t.Fatalf("should be able to construct file store: %s", err)
+	}
+
+	queue, err := batchqueue.New(batchqueue.Config{
+		Log:         logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" }),
+		Dir:         dir + "/batches",
+		Files:       files,
+		Auth:        auth,
+		Concurrency: 1,
+	})
+	if err != nil {
+		t.Fatalf("should be able to construct queue: %s", err)
+	}
+
+	return files, queue
+}
+
+func line(customID string, url string, body string) string {
+	return fmt.Sprintf(`{"custom_id":%q,"method":"POST","url":%q,"body":%s}`, customID, url, body)
+}
+
+func lines(customIDs ...string) string {
+	var b strings.Builder
+	for _, id := range customIDs {
+		b.WriteString(line(id, endpoint, fmt.Sprintf(`{"model":"model-a","messages":[{"role":"user","content":%q}]}`, id)))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// createBatch uploads content as a batch input file and queues a batch
+// for it.
+func createBatch(t *testing.T, files *filestore.Store, queue *batchqueue.Queue, content string) batchqueue.Batch {
+	t.Helper()
+
+	f, err := files.Create(t.Context(), "user-1", "input.jsonl", "batch", strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("should be able to upload input: %s", err)
+	}
+
+	b, err := queue.Create(t.Context(), batchqueue.NewBatch{
+		Subject:     "user-1",
+		Endpoint:    endpoint,
+		InputFileID: f.ID,
+		Token:       batchqueue.Token{ID: "token-1", Bearer: bearer},
+		Input:       batchqueue.ParseInput(strings.NewReader(content), endpoint),
+	})
+	if err != nil {
+		t.Fatalf("should be able to create: %s", err)
+	}
+
+	return b
+}
+
+func waitFor(t *testing.T, queue *batchqueue.Queue, id string, status string) batchqueue.Batch {
+	t.Helper()
+
+	var b batchqueue.Batch
+	waitUntil(t, func() bool {
+		b, _ = queue.QueryByID(t.Context(), "user-1", id)
+		return b.Status == status
+	})
+
+	return b
+}
+
+func waitUntil(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the batch")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func customIDs(t *testing.T, files *filestore.Store, fileID string) string {
+	t.Helper()
+
+	_, content, err := files.Content(t.Context(), "user-1", fileID)
+	if err != nil {
+		t.Fatalf("should be able to open result file %q: %s", fileID, err)
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		t.Fatalf("should be able to read result file %q: %s", fileID, err)
+	}
+
+	var ids []string
+	for l := range strings.Lines(string(data)) {
+		var out struct {
+			CustomID string `json:"custom_id"`
+		}
+		if err := json.Unmarshal([]byte(l), &out); err != nil {
+			t.Fatalf("result line %q: %s", l, err)
+		}
+		ids = append(ids, out.CustomID)
+	}
+
+	return strings.Join(ids, ",")
+}
+
+// resultCodes returns the custom ID and error code of each line of a result
+// file.
+func resultCodes(t *testing.T, files *filestore.Store, fileID string) string {
+	t.Helper()
+
+	_, content, err := files.Content(t.Context(), "user-1", fileID)
+	if err != nil {
+		t.Fatalf("should be able to open result file %q: %s", fileID, err)
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		t.Fatalf("should be able to read result file %q: %s", fileID, err)
+	}
+
+	var results []string
+	for l := range strings.Lines(string(data)) {
+		var out struct {
+			CustomID string `json:"custom_id"`
+			Error    struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(l), &out); err != nil {
+			t.Fatalf("result line %q: %s", l, err)
+		}
+		results = append(results, out.CustomID+":"+out.Error.Code)
+	}
+
+	return strings.Join(results, ",")
+}
@@ -0,0 +1,218 @@
+package batchqueue
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+)
+
+// Limits applied to a batch input file. Validation stops collecting line
+// errors once maxLineErrors have been found.
+const (
+	MaxRequests   = 50000
+	maxLineErrors = 100
+)
+
+// Request is one line of a batch input file.
+type Request struct {
+	CustomID string          `json:"custom_id"`
+	Method   string          `json:"method"`
+	URL      string          `json:"url"`
+	Body     json.RawMessage `json:"body"`
+
+	// model is the model the request body names, filled in as the runner
+	// reads the request.
+	model string
+}
+
+// Input is the result of parsing a batch input file. Total counts the valid
+// requests.
+type Input struct {
+	Total  int
+	Models []string
+	Errors []LineError
+}
+
+// ParseInput parses the JSONL content of a batch input file whose lines all
+// target endpoint. Problems with individual lines are reported in Errors
+// rather than as an error, so they can be returned on the batch object the
+// way OpenAI does. Models lists the distinct models the lines use.
+func ParseInput(r io.Reader, endpoint string) Input {
+	var input Input
+
+	addError := func(le LineError) {
+		if len(input.Errors) < maxLineErrors {
+			input.Errors = append(input.Errors, le)
+		}
+	}
+
+	ir := newInputReader(r, endpoint)
+
+	for {
+		_, model, lineErr, err := ir.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			addError(newLineError(ir.line+1, "invalid_json_line", "", "read input file: %s", err))
+			break
+		}
+
+		if lineErr != nil {
+			addError(*lineErr)
+			continue
+		}
+
+		if input.Total == MaxRequests {
+			addError(newLineError(ir.line, "too_many_requests", "", "the input file can contain at most %d requests", MaxRequests))
+			break
+		}
+
+		if !slices.Contains(input.Models, model) {
+			input.Models = append(input.Models, model)
+		}
+
+		input.Total++
+	}
+
+	if input.Total == 0 && len(input.Errors) == 0 {
+		addError(newLineError(0, "empty_file", "", "the input file does not contain any requests"))
+	}
+
+	return input
+}
+
+// =============================================================================
+
+// inputReader reads the requests of a batch input file one line at a time,
+// so a file is never held in memory as a whole.
+type inputReader struct {
+	r         *bufio.Reader
+	endpoint  string
+	line      int
+	customIDs map[string]struct{}
+}
+
+func newInputReader(r io.Reader, endpoint string) *inputReader {
+	return &inputReader{
+		r:         bufio.NewReader(r),
+		endpoint:  endpoint,
+		customIDs: make(map[string]struct{}),
+	}
+}
+
+// next returns the next request and the model it names, skipping blank
+// lines. A line that is not a valid request is returned as a line error, and
+// io.EOF marks the end of the file.
+func (ir *inputReader) next() (Request, string, *LineError, error) {
+	for {
+		raw, err := ir.r.ReadBytes('\n')
+		if len(raw) == 0 && err != nil {
+			return Request{}, "", nil, err
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return Request{}, "", nil, err
+		}
+
+		ir.line++
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			continue
+		}
+
+		req, model, lineErr := ir.parse(raw)
+
+		return req, model, lineErr, nil
+	}
+}
+
+func (ir *inputReader) parse(raw []byte) (Request, string, *LineError) {
+	fail := func(code string, param string, format string, args ...any) (Request, string, *LineError) {
+		le := newLineError(ir.line, code, param, format, args...)
+		return Request{}, "", &le
+	}
+
+	var req Request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return fail("invalid_json_line", "", "line is not a valid JSON request: %s", err)
+	}
+
+	if req.CustomID == "" {
+		return fail("missing_required_parameter", "custom_id", "custom_id is required")
+	}
+
+	if _, exists := ir.customIDs[req.CustomID]; exists {
+		return fail("duplicate_custom_id", "custom_id", "custom_id %q is used by an earlier line", req.CustomID)
+	}
+	ir.customIDs[req.CustomID] = struct{}{}
+
+	if req.Method != http.MethodPost {
+		return fail("invalid_request", "method", "method must be POST")
+	}
+
+	if req.URL != ir.endpoint {
+		return fail("mismatched_endpoint", "url", "url %q does not match the batch endpoint %q", req.URL, ir.endpoint)
+	}
+
+	var body struct {
+		Model  string `json:"model"`
+		Stream bool   `json:"stream"`
+	}
+	if len(req.Body) == 0 || req.Body[0] != '{' || json.Unmarshal(req.Body, &body) != nil {
+		return fail("invalid_request", "body", "body must be a JSON object")
+	}
+
+	if body.Model == "" {
+		return fail("missing_required_parameter", "body.model", "body.model is required")
+	}
+
+	if body.Stream {
+		return fail("invalid_request", "body.stream", "streaming is not supported in a batch")
+	}
+
+	return req, body.Model, nil
+}
+
+func newLineError(line int, code string, param string, format string, args ...any) LineError {
+	le := LineError{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+	if line > 0 {
+		le.Line = &line
+	}
+	if param != "" {
+		le.Param = &param
+	}
+
+	return le
+}
+
+// read returns up to n of the next requests, fewer at the end of the file.
+// It stops at the first line that is not a valid request and returns it as
+// a line error.
+func (ir *inputReader) read(n int) ([]Request, *LineError, error) {
+	var reqs []Request
+	for len(reqs) < n {
+		req, model, lineErr, err := ir.next()
+		switch {
+		case errors.Is(err, io.EOF):
+			return reqs, nil, nil
+		case err != nil:
+			return nil, nil, err
+		case lineErr != nil:
+			return nil, lineErr, nil
+		}
+
+		req.model = model
+		reqs = append(reqs, req)
+	}
+
+	return reqs, nil, nil
+}
@@ -0,0 +1,517 @@
+package batchqueue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"uuid"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// outputLine is one line of a batch output or error file.
+type outputLine struct {
+	ID       string        `json:"id"`
+	CustomID string        `json:"custom_id"`
+	Response *lineResponse `json:"response"`
+	Error    *LineError    `json:"error"`
+}
+
+type lineResponse struct {
+	StatusCode int             `json:"status_code"`
+	RequestID  string          `json:"request_id"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// result is the outcome of dispatching one request.
+type result struct {
+	line      outputLine
+	succeeded bool
+}
+
+func (q *Queue) run(ctx context.Context) {
+	defer close(q.done)
+
+	for {
+		if id, ok := q.next(); ok {
+			if err := q.process(ctx, id); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+
+				q.log.Error(ctx, "batches", "status", "process", "id", id, "ERROR", err)
+				q.fail(id, LineError{Code: "internal_error", Message: err.Error()})
+			}
+
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		}
+	}
+}
+
+// process runs the batch from the last recorded request until it finishes,
+// is cancelled, or expires.
+func (q *Queue) process(ctx context.Context, id string) error {
+	b, exists := q.get(id)
+	if !exists {
+		return nil
+	}
+
+	_, content, err := q.files.Content(ctx, b.Subject, b.InputFileID)
+	if err != nil {
+		if errors.Is(err, filestore.ErrNotFound) {
+			q.fail(id, LineError{Code: "input_file_not_found", Message: fmt.Sprintf("input file %q was deleted", b.InputFileID)})
+			return nil
+		}
+		return fmt.Errorf("process: read input file: %w", err)
+	}
+	defer content.Close()
+
+	// The input was validated when the batch was created, so the requests
+	// are read as they run and those already recorded are skipped.
+	requests := newInputReader(content, b.Endpoint)
+
+	if _, lineErr, err := requests.read(b.Offset); err != nil || lineErr != nil {
+		return q.failInput(id, lineErr, err)
+	}
+
+	if b.Status == StatusValidating {
+		b, err = q.update(id, func(b *Batch) {
+			b.Status = StatusInProgress
+			b.InProgressAt = time.Now().UTC()
+		})
+		if err != nil {
+			return fmt.Errorf("process: %w", err)
+		}
+
+		q.log.Info(ctx, "batches", "status", "started", "id", id, "endpoint", b.Endpoint, "requests", b.RequestCounts.Total)
+	}
+
+	out, err := openPartial(q.path(id, ".output.jsonl"), b.OutputBytes)
+	if err != nil {
+		return fmt.Errorf("process: %w", err)
+	}
+	defer out.Close()
+
+	errOut, err := openPartial(q.path(id, ".error.jsonl"), b.ErrorBytes)
+	if err != nil {
+		return fmt.Errorf("process: %w", err)
+	}
+	defer errOut.Close()
+
+	final := StatusCompleted
+
+	// stopped is the error the remaining requests fail with once the token
+	// that created the batch is no longer valid.
+	var stopped *LineError
+
+	for b.Offset < b.RequestCounts.Total {
+		if b.Status == StatusCancelling {
+			final = StatusCancelled
+			break
+		}
+
+		next, lineErr, err := requests.read(q.concurrency)
+		if err != nil || lineErr != nil {
+			return q.failInput(id, lineErr, err)
+		}
+		if len(next) == 0 {
+			return q.failInput(id, &LineError{Code: "invalid_input_file", Message: "the input file ended before its last request"}, nil)
+		}
+
+		var results []result
+
+		switch {
+		case stopped != nil:
+			results = rejectAll(next, *stopped)
+
+		case time.Now().After(b.ExpiresAt):
+			final = StatusExpired
+			results = rejectAll(next, LineError{
+				Code:    "batch_expired",
+				Message: "this request could not be executed before the completion window expired",
+			})
+
+		default:
+			var exhausted map[string]bool
+			stopped, exhausted, err = q.checkToken(ctx, b.Token, next)
+			if err != nil {
+				return fmt.Errorf("process: %w", err)
+			}
+
+			if stopped != nil {
+				q.log.Info(ctx, "batches", "status", "token rejected", "id", id, "code", stopped.Code)
+
+				if b, err = q.update(id, func(b *Batch) { b.Errors = []LineError{*stopped} }); err != nil {
+					return fmt.Errorf("process: %w", err)
+				}
+
+				final = StatusFailed
+				results = rejectAll(next, *stopped)
+				break
+			}
+
+			results = q.dispatch(ctx, b.Token.Bearer, next, exhausted)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+
+		var completed, failed int
+		for _, res := range results {
+			data, err := json.Marshal(res.line)
+			if err != nil {
+				return fmt.Errorf("process: marshal: %w", err)
+			}
+
+			w := errOut
+			if res.succeeded {
+				w = out
+				completed++
+			} else {
+				failed++
+			}
+
+			if _, err := w.Write(append(data, '\n')); err != nil {
+				return fmt.Errorf("process: write: %w", err)
+			}
+		}
+
+		outputBytes, err := out.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return fmt.Errorf("process: %w", err)
+		}
+
+		errorBytes, err := errOut.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return fmt.Errorf("process: %w", err)
+		}
+
+		b, err = q.update(id, func(b *Batch) {
+			b.Offset += len(results)
+			b.OutputBytes = outputBytes
+			b.ErrorBytes = errorBytes
+			b.RequestCounts.Completed += completed
+			b.RequestCounts.Failed += failed
+		})
+		if err != nil {
+			return fmt.Errorf("process: %w", err)
+		}
+	}
+
+	if b.Status == StatusCancelling {
+		final = StatusCancelled
+	}
+
+	return q.finalize(ctx, id, final, out, errOut)
+}
+
+// failInput fails the batch when its input file can no longer be read as
+// it was when the batch was created.
+func (q *Queue) failInput(id string, lineErr *LineError, err error) error {
+	if err != nil {
+		return fmt.Errorf("process: read input file: %w", err)
+	}
+
+	q.fail(id, *lineErr)
+
+	return nil
+}
+
+// finalize stores the partial output and error files in the file store and
+// records the final status of the batch.
+func (q *Queue) finalize(ctx context.Context, id string, final string, out *os.File, errOut *os.File) error {
+	b, err := q.update(id, func(b *Batch) {
+		if final == StatusCompleted {
+			b.Status = StatusFinalizing
+			b.FinalizingAt = time.Now().UTC()
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("finalize: %w", err)
+	}
+
+	if b.OutputFileID == "" && b.OutputBytes > 0 {
+		f, err := q.files.Create(ctx, b.Subject, b.ID+"_output.jsonl", purposeOutput, io.NewSectionReader(out, 0, b.OutputBytes))
+		if err != nil {
+			return fmt.Errorf("finalize: store output file: %w", err)
+		}
+
+		if b, err = q.update(id, func(b *Batch) { b.OutputFileID = f.ID }); err != nil {
+			return fmt.Errorf("finalize: %w", err)
+		}
+	}
+
+	if b.ErrorFileID == "" && b.ErrorBytes > 0 {
+		f, err := q.files.Create(ctx, b.Subject, b.ID+"_error.jsonl", purposeOutput, io.NewSectionReader(errOut, 0, b.ErrorBytes))
+		if err != nil {
+			return fmt.Errorf("finalize: store error file: %w", err)
+		}
+
+		if b, err = q.update(id, func(b *Batch) { b.ErrorFileID = f.ID }); err != nil {
+			return fmt.Errorf("finalize: %w", err)
+		}
+	}
+
+	b, err = q.update(id, func(b *Batch) {
+		now := time.Now().UTC()
+
+		b.Status = final
+		switch final {
+		case StatusCompleted:
+			b.CompletedAt = now
+		case StatusFailed:
+			b.FailedAt = now
+		case StatusExpired:
+			b.ExpiredAt = now
+		case StatusCancelled:
+			b.CancelledAt = now
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("finalize: %w", err)
+	}
+
+	q.removePartials(id)
+
+	q.log.Info(ctx, "batches", "status", b.Status, "id", id, "completed", b.RequestCounts.Completed, "failed", b.RequestCounts.Failed)
+
+	return nil
+}
+
+// fail records the batch as failed with the specified errors. The failure
+// is kept in memory even when it cannot be saved so the runner moves on.
+func (q *Queue) fail(id string, lineErrs ...LineError) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b, exists := q.batches[id]
+	if !exists {
+		return
+	}
+
+	b.Status = StatusFailed
+	b.FailedAt = time.Now().UTC()
+	b.Errors = lineErrs
+
+	if err := q.save(b); err != nil {
+		q.log.Error(context.Background(), "batches", "status", "save failed batch", "id", id, "ERROR", err)
+	}
+
+	q.batches[id] = b
+
+	q.removePartials(id)
+}
+
+// checkToken checks the token that created the batch before a round of
+// requests is dispatched. It returns the error the remaining requests fail
+// with once the token is revoked or expired, and which models of the round
+// have a used up token budget. Failures to reach the auth service are
+// returned as errors.
+func (q *Queue) checkToken(ctx context.Context, token Token, reqs []Request) (*LineError, map[string]bool, error) {
+	if !token.ExpiresAt.IsZero() && time.Now().After(token.ExpiresAt) {
+		return &LineError{Code: "token_expired", Message: "the token that created the batch has expired"}, nil, nil
+	}
+
+	if q.auth == nil {
+		return nil, nil, nil
+	}
+
+	ar, err := q.auth.Authenticate(ctx, token.Bearer, false, "")
+	if err != nil {
+		switch status.Code(err) {
+		case codes.Unauthenticated, codes.PermissionDenied:
+			return &LineError{
+				Code:    "invalid_token",
+				Message: fmt.Sprintf("the token that created the batch is no longer valid: %s", status.Convert(err).Message()),
+			}, nil, nil
+		}
+
+		return nil, nil, fmt.Errorf("check token: %w", err)
+	}
+
+	if !ar.TokenBudgets {
+		return nil, nil, nil
+	}
+
+	exhausted := make(map[string]bool)
+	for _, req := range reqs {
+		if _, checked := exhausted[req.model]; checked {
+			continue
+		}
+
+		resp, err := q.auth.CheckTokens(ctx, token.Bearer, req.model)
+		if err != nil {
+			return nil, nil, fmt.Errorf("check token budget: %w", err)
+		}
+
+		exhausted[req.model] = resp.Exceeded
+	}
+
+	return nil, exhausted, nil
+}
+
+// dispatch sends the requests through the handler at the same time and
+// returns their results in order. Requests for a model whose token budget
+// is used up fail without being sent.
+func (q *Queue) dispatch(ctx context.Context, bearer string, reqs []Request, exhausted map[string]bool) []result {
+	results := make([]result, len(reqs))
+
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		if exhausted[req.model] {
+			results[i] = reject(req, LineError{
+				Code:    "token_budget_exceeded",
+				Message: fmt.Sprintf("the token budget for model %q is used up", req.model),
+			})
+			continue
+		}
+
+		wg.Go(func() {
+			results[i] = q.send(ctx, bearer, req)
+		})
+	}
+	wg.Wait()
+
+	return results
+}
+
+// send dispatches one request through the handler with the batch's bearer
+// token, at low priority, and captures the response.
+func (q *Queue) send(ctx context.Context, bearer string, req Request) result {
+	line := outputLine{
+		ID:       "batch_req_" + uuid.New().String(),
+		CustomID: req.CustomID,
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		line.Error = &LineError{Code: "invalid_request", Message: err.Error()}
+		return result{line: line}
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(mid.PriorityHeader, "low")
+
+	if bearer != "" {
+		r.Header.Set("Authorization", bearer)
+	}
+
+	w := responseBuffer{header: make(http.Header)}
+	q.handler.ServeHTTP(&w, r)
+
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	body := w.body.Bytes()
+	if !json.Valid(body) {
+		body, _ = json.Marshal(w.body.String())
+	}
+
+	line.Response = &lineResponse{
+		StatusCode: status,
+		RequestID:  "req_" + uuid.New().String(),
+		Body:       body,
+	}
+
+	return result{
+		line:      line,
+		succeeded: status >= 200 && status < 300,
+	}
+}
+
+// rejectAll returns error results for requests that were not dispatched.
+func rejectAll(reqs []Request, lineErr LineError) []result {
+	results := make([]result, len(reqs))
+	for i, req := range reqs {
+		results[i] = reject(req, lineErr)
+	}
+
+	return results
+}
+
+// reject returns an error result for a request that was not dispatched.
+func reject(req Request, lineErr LineError) result {
+	return result{
+		line: outputLine{
+			ID:       "batch_req_" + uuid.New().String(),
+			CustomID: req.CustomID,
+			Error:    &lineErr,
+		},
+	}
+}
+
+// openPartial opens a partial result file for appending after its first
+// size bytes, dropping anything written after the last recorded request.
+func openPartial(path string, size int64) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+
+	if err := f.Truncate(size); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("truncate %s: %w", path, err)
+	}
+
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("seek %s: %w", path, err)
+	}
+
+	return f, nil
+}
+
+func (q *Queue) removePartials(id string) {
+	os.Remove(q.path(id, ".output.jsonl"))
+	os.Remove(q.path(id, ".error.jsonl"))
+}
+
+// =============================================================================
+
+// responseBuffer is an http.ResponseWriter that keeps the response in
+// memory.
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseBuffer) Header() http.Header {
+	return w.header
+}
+
+func (w *responseBuffer) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *responseBuffer) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.body.Write(p)
+}
+
+// Flush implements http.Flusher for handlers that flush as they write.
+func (w *responseBuffer) Flush() {}
@@ -0,0 +1,326 @@
+// Package filestore provides storage for files uploaded through the Files API
+// so requests can reference them by file_id instead of inlining their content.
+package filestore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"uuid"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+)
+
+var (
+	// ErrNotFound is returned when a file is not stored for the subject.
+	ErrNotFound = errors.New("file not found")
+
+	// ErrTooLarge is returned when a file exceeds the per-file size limit.
+	ErrTooLarge = errors.New("file exceeds the maximum file size")
+
+	// ErrQuotaExceeded is returned when storing a file would exceed the
+	// subject's storage quota.
+	ErrQuotaExceeded = errors.New("file storage quota exceeded")
+
+	// ErrEmpty is returned when an uploaded file has no content.
+	ErrEmpty = errors.New("file is empty")
+)
+
+// File describes a stored file.
+type File struct {
+	ID        string    `json:"id"`
+	Subject   string    `json:"subject"`
+	Filename  string    `json:"filename"`
+	Purpose   string    `json:"purpose"`
+	Bytes     int64     `json:"bytes"`
+	SHA256    string    `json:"sha256"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Config holds the configuration for the store.
+type Config struct {
+	Dir        string
+	MaxBytes   int64
+	QuotaBytes int64
+}
+
+// Store keeps file content on disk addressed by its SHA-256 digest, so the
+// same content uploaded several times is stored once, and keeps one metadata
+// record per file ID.
+type Store struct {
+	blobDir    string
+	metaDir    string
+	maxBytes   int64
+	quotaBytes int64
+
+	mu    sync.RWMutex
+	files map[string]File
+}
+
+// New opens the store rooted at the configured directory and loads the
+// metadata of the files already stored there. MaxBytes limits the size of a
+// single file and QuotaBytes the total size of a subject's files; zero means
+// unlimited for both.
+func New(cfg Config) (*Store, error) {
+	s := Store{
+		blobDir:    filepath.Join(cfg.Dir, "blobs"),
+		metaDir:    filepath.Join(cfg.Dir, "meta"),
+		maxBytes:   cfg.MaxBytes,
+		quotaBytes: cfg.QuotaBytes,
+		files:      make(map[string]File),
+	}
+
+	for _, dir := range []string{s.blobDir, s.metaDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("new: create %s: %w", dir, err)
+		}
+	}
+
+	entries, err := os.ReadDir(s.metaDir)
+	if err != nil {
+		return nil, fmt.Errorf("new: read %s: %w", s.metaDir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.metaDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("new: read %s: %w", entry.Name(), err)
+		}
+
+		var f File
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("new: unmarshal %s: %w", entry.Name(), err)
+		}
+
+		s.files[f.ID] = f
+	}
+
+	return &s, nil
+}
+
+// Create stores the content read from r as a new file owned by subject.
+func (s *Store) Create(ctx context.Context, subject string, filename string, purpose string, r io.Reader) (File, error) {
+	tmp, err := os.CreateTemp(s.blobDir, ".upload-*")
+	if err != nil {
+		return File{}, fmt.Errorf("create: temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if s.maxBytes > 0 {
+		r = io.LimitReader(r, s.maxBytes+1)
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if err != nil {
+		return File{}, fmt.Errorf("create: write: %w", err)
+	}
+
+	switch {
+	case n == 0:
+		return File{}, fmt.Errorf("create: %w", ErrEmpty)
+	case s.maxBytes > 0 && n > s.maxBytes:
+		return File{}, fmt.Errorf("create: %w: limit is %d bytes", ErrTooLarge, s.maxBytes)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return File{}, fmt.Errorf("create: close: %w", err)
+	}
+
+	f := File{
+		ID:        "file-" + uuid.New().String(),
+		Subject:   subject,
+		Filename:  filepath.Base(filename),
+		Purpose:   purpose,
+		Bytes:     n,
+		SHA256:    hex.EncodeToString(hash.Sum(nil)),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.quotaBytes > 0 && s.usage(subject)+n > s.quotaBytes {
+		return File{}, fmt.Errorf("create: %w: quota is %d bytes", ErrQuotaExceeded, s.quotaBytes)
+	}
+
+	blob := s.blobPath(f.SHA256)
+	if _, err := os.Stat(blob); errors.Is(err, os.ErrNotExist) {
+		if err := os.Rename(tmp.Name(), blob); err != nil {
+			return File{}, fmt.Errorf("create: store blob: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return File{}, fmt.Errorf("create: marshal: %w", err)
+	}
+
+	if err := os.WriteFile(s.metaPath(f.ID), data, 0o644); err != nil {
+		s.removeBlobIfUnused(f.SHA256)
+		return File{}, fmt.Errorf("create: write metadata: %w", err)
+	}
+
+	s.files[f.ID] = f
+
+	return f, nil
+}
+
+// MaxBytes returns the per-file size limit, or zero when unlimited.
+func (s *Store) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// List returns the subject's files, newest first. An empty purpose returns
+// files of every purpose.
+func (s *Store) List(ctx context.Context, subject string, purpose string) []File {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var files []File
+	for _, f := range s.files {
+		if f.Subject != subject || (purpose != "" && f.Purpose != purpose) {
+			continue
+		}
+		files = append(files, f)
+	}
+
+	slices.SortFunc(files, func(a, b File) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return files
+}
+
+// QueryByID returns the subject's file with the specified ID. Files owned
+// by another subject are reported as not found.
+func (s *Store) QueryByID(ctx context.Context, subject string, id string) (File, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, exists := s.files[id]
+	if !exists || f.Subject != subject {
+		return File{}, fmt.Errorf("query-by-id: %s: %w", id, ErrNotFound)
+	}
+
+	return f, nil
+}
+
+// Content returns the metadata of the subject's file with the specified ID
+// and a reader over its content, which the caller must close. The content
+// stays readable after the file is deleted until the reader is closed.
+func (s *Store) Content(ctx context.Context, subject string, id string) (File, io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, exists := s.files[id]
+	if !exists || f.Subject != subject {
+		return File{}, nil, fmt.Errorf("content: %s: %w", id, ErrNotFound)
+	}
+
+	// Opening the blob under the lock keeps Delete from removing it first;
+	// reading happens after the lock is released.
+	blob, err := os.Open(s.blobPath(f.SHA256))
+	if err != nil {
+		return File{}, nil, fmt.Errorf("content: open: %w", err)
+	}
+
+	return f, blob, nil
+}
+
+// Delete removes the subject's file with the specified ID. The content is
+// removed once no other file references it.
+func (s *Store) Delete(ctx context.Context, subject string, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, exists := s.files[id]
+	if !exists || f.Subject != subject {
+		return fmt.Errorf("delete: %s: %w", id, ErrNotFound)
+	}
+
+	if err := os.Remove(s.metaPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete: remove metadata: %w", err)
+	}
+
+	delete(s.files, id)
+	s.removeBlobIfUnused(f.SHA256)
+
+	return nil
+}
+
+// Lookup returns a model.FileLookup that resolves file_id references to the
+// subject's files, or nil when s is nil so file_id references are rejected.
+func (s *Store) Lookup(subject string) model.FileLookup {
+	if s == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, fileID string) (model.FileData, error) {
+		f, content, err := s.Content(ctx, subject, fileID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return model.FileData{}, fmt.Errorf("%w: file %q not found", model.ErrInvalidRequest, fileID)
+			}
+			return model.FileData{}, err
+		}
+		defer content.Close()
+
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return model.FileData{}, fmt.Errorf("lookup: read %s: %w", fileID, err)
+		}
+
+		return model.FileData{Filename: f.Filename, Data: data}, nil
+	}
+}
+
+// =============================================================================
+
+func (s *Store) usage(subject string) int64 {
+	var total int64
+	for _, f := range s.files {
+		if f.Subject == subject {
+			total += f.Bytes
+		}
+	}
+
+	return total
+}
+
+func (s *Store) removeBlobIfUnused(sha string) {
+	for _, f := range s.files {
+		if f.SHA256 == sha {
+			return
+		}
+	}
+
+	os.Remove(s.blobPath(sha))
+}
+
+func (s *Store) blobPath(sha string) string {
+	return filepath.Join(s.blobDir, sha)
+}
+
+func (s *Store) metaPath(id string) string {
+	return filepath.Join(s.metaDir, id+".json")
+}
@@ -0,0 +1,192 @@
+package filestore_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+)
+
+func Test_Store(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := filestore.New(filestore.Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("should be able to construct store: %s", err)
+	}
+
+	first, err := store.Create(t.Context(), "user-1", "notes.txt", "user_data", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("should be able to create: %s", err)
+	}
+
+	if first.Bytes != 5 || first.Filename != "notes.txt" || first.Purpose != "user_data" || !strings.HasPrefix(first.ID, "file-") {
+		t.Errorf("create: got %+v", first)
+	}
+
+	second, err := store.Create(t.Context(), "user-1", "../copy.txt", "batch", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("should be able to create a duplicate: %s", err)
+	}
+
+	if second.Filename != "copy.txt" {
+		t.Errorf("filename: got %q, want the base name", second.Filename)
+	}
+
+	blobs, _ := os.ReadDir(filepath.Join(dir, "blobs"))
+	if len(blobs) != 1 {
+		t.Errorf("blobs: got %d, want identical content stored once", len(blobs))
+	}
+
+	if got := store.List(t.Context(), "user-1", ""); len(got) != 2 {
+		t.Errorf("list: got %d files, want 2", len(got))
+	}
+
+	if got := store.List(t.Context(), "user-1", "batch"); len(got) != 1 || got[0].ID != second.ID {
+		t.Errorf("list by purpose: got %+v, want the batch file", got)
+	}
+
+	if got := store.List(t.Context(), "user-2", ""); len(got) != 0 {
+		t.Errorf("list other subject: got %d files, want 0", len(got))
+	}
+
+	if _, err := store.QueryByID(t.Context(), "user-2", first.ID); !errors.Is(err, filestore.ErrNotFound) {
+		t.Errorf("query other subject: got %v, want %v", err, filestore.ErrNotFound)
+	}
+
+	if err := store.Delete(t.Context(), "user-2", first.ID); !errors.Is(err, filestore.ErrNotFound) {
+		t.Errorf("delete other subject: got %v, want %v", err, filestore.ErrNotFound)
+	}
+
+	if err := store.Delete(t.Context(), "user-1", first.ID); err != nil {
+		t.Fatalf("should be able to delete: %s", err)
+	}
+
+	_, content, err := store.Content(t.Context(), "user-1", second.ID)
+	if err != nil {
+		t.Fatalf("shared content should survive deleting one file: %s", err)
+	}
+
+	data, err := io.ReadAll(content)
+	content.Close()
+	if err != nil {
+		t.Fatalf("should be able to read content: %s", err)
+	}
+
+	if string(data) != "hello" {
+		t.Errorf("content: got %q, want %q", data, "hello")
+	}
+
+	reopened, err := filestore.New(filestore.Config{Dir: dir})
+	if err != nil {
+		t.Fatalf("should be able to reopen store: %s", err)
+	}
+
+	if _, err := reopened.QueryByID(t.Context(), "user-1", second.ID); err != nil {
+		t.Errorf("reopened query: %s", err)
+	}
+
+	if err := reopened.Delete(t.Context(), "user-1", second.ID); err != nil {
+		t.Fatalf("should be able to delete: %s", err)
+	}
+
+	blobs, _ = os.ReadDir(filepath.Join(dir, "blobs"))
+	if len(blobs) != 0 {
+		t.Errorf("blobs: got %d, want unreferenced content removed", len(blobs))
+	}
+}
+
+func Test_StoreContentAfterDelete(t *testing.T) {
+	store, err := filestore.New(filestore.Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("should be able to construct store: %s", err)
+	}
+
+	f, err := store.Create(t.Context(), "user-1", "a.txt", "user_data", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("should be able to create: %s", err)
+	}
+
+	_, content, err := store.Content(t.Context(), "user-1", f.ID)
+	if err != nil {
+		t.Fatalf("should be able to open content: %s", err)
+	}
+	defer content.Close()
+
+	// Reading happens without the store's lock, so the file can be deleted
+	// meanwhile. The open reader keeps its content readable.
+	if err := store.Delete(t.Context(), "user-1", f.ID); err != nil {
+		t.Fatalf("should be able to delete while reading: %s", err)
+	}
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		t.Fatalf("should be able to read content: %s", err)
+	}
+
+	if string(data) != "hello" {
+		t.Errorf("content: got %q, want %q", data, "hello")
+	}
+}
+
+func Test_StoreLimits(t *testing.T) {
+	store, err := filestore.New(filestore.Config{Dir: t.TempDir(), MaxBytes: 4, QuotaBytes: 6})
+	if err != nil {
+		t.Fatalf("should be able to construct store: %s", err)
+	}
+
+	if _, err := store.Create(t.Context(), "user-1", "big.txt", "user_data", strings.NewReader("12345")); !errors.Is(err, filestore.ErrTooLarge) {
+		t.Errorf("too large: got %v, want %v", err, filestore.ErrTooLarge)
+	}
+
+	if _, err := store.Create(t.Context(), "user-1", "empty.txt", "user_data", strings.NewReader("")); !errors.Is(err, filestore.ErrEmpty) {
+		t.Errorf("empty: got %v, want %v", err, filestore.ErrEmpty)
+	}
+
+	if _, err := store.Create(t.Context(), "user-1", "a.txt", "user_data", strings.NewReader("1234")); err != nil {
+		t.Fatalf("should be able to create: %s", err)
+	}
+
+	if _, err := store.Create(t.Context(), "user-1", "b.txt", "user_data", strings.NewReader("123")); !errors.Is(err, filestore.ErrQuotaExceeded) {
+		t.Errorf("quota: got %v, want %v", err, filestore.ErrQuotaExceeded)
+	}
+
+	if _, err := store.Create(t.Context(), "user-2", "b.txt", "user_data", strings.NewReader("123")); err != nil {
+		t.Errorf("quota is per subject: %s", err)
+	}
+}
+
+func Test_StoreLookup(t *testing.T) {
+	store, err := filestore.New(filestore.Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("should be able to construct store: %s", err)
+	}
+
+	f, err := store.Create(t.Context(), "user-1", "notes.txt", "user_data", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("should be able to create: %s", err)
+	}
+
+	got, err := store.Lookup("user-1")(t.Context(), f.ID)
+	if err != nil {
+		t.Fatalf("should be able to look up: %s", err)
+	}
+
+	if got.Filename != "notes.txt" || string(got.Data) != "hello" {
+		t.Errorf("lookup: got %+v", got)
+	}
+
+	if _, err := store.Lookup("user-2")(t.Context(), f.ID); !errors.Is(err, model.ErrInvalidRequest) {
+		t.Errorf("lookup other subject: got %v, want %v", err, model.ErrInvalidRequest)
+	}
+
+	var disabled *filestore.Store
+	if disabled.Lookup("user-1") != nil {
+		t.Error("lookup on a nil store should be nil")
+	}
+}
@@ -0,0 +1,520 @@
+// Package mediafetch downloads media that requests reference by URL. Hosts
+// are limited to an allowlist, which fetches nothing when empty, private
+// networks are refused unless allowed, and downloads are bounded in size and
+// time.
+package mediafetch
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+)
+
+var (
+	// ErrNotAllowed is returned when a URL's host is not allowed.
+	ErrNotAllowed = errors.New("media host not allowed")
+
+	// ErrTooLarge is returned when the media exceeds the size limit.
+	ErrTooLarge = errors.New("media exceeds the maximum size")
+)
+
+// maxRedirects bounds how many redirects a download follows. Every hop is
+// checked against the same rules as the original URL.
+const maxRedirects = 5
+
+// sharedAddressSpace is the carrier-grade NAT range, which is not public
+// but is not reported by netip.Addr.IsPrivate.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Config holds the configuration for the fetcher.
+type Config struct {
+	AllowedHosts  []string
+	AllowPrivate  bool
+	MaxBytes      int64
+	Timeout       time.Duration
+	CacheDir      string
+	CacheMaxBytes int64
+}
+
+// Fetcher downloads media over http and https.
+type Fetcher struct {
+	client       *http.Client
+	hosts        []string
+	prefixes     []netip.Prefix
+	allowPrivate bool
+	maxBytes     int64
+	cache        *cache
+}
+
+// New constructs a fetcher. AllowedHosts holds host names, "*.example.com"
+// patterns that match subdomains, CIDR blocks, and "*" for any host; an
+// empty list allows no host, so nothing is fetched. Addresses in private,
+// loopback, and link-local networks are refused unless AllowPrivate is set or
+// they fall in an allowed CIDR block. MaxBytes and Timeout bound each
+// download; zero means unlimited. A CacheDir keeps downloads that carry an
+// ETag and revalidates them on later requests, removing the least recently
+// used once they take more than CacheMaxBytes; zero means unlimited.
+func New(cfg Config) (*Fetcher, error) {
+	f := Fetcher{
+		allowPrivate: cfg.AllowPrivate,
+		maxBytes:     cfg.MaxBytes,
+	}
+
+	for _, host := range cfg.AllowedHosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			continue
+		}
+
+		if strings.Contains(host, "/") {
+			prefix, err := netip.ParsePrefix(host)
+			if err != nil {
+				return nil, fmt.Errorf("new: allowed host %q: %w", host, err)
+			}
+			f.prefixes = append(f.prefixes, prefix.Masked())
+			continue
+		}
+
+		f.hosts = append(f.hosts, host)
+	}
+
+	if cfg.CacheDir != "" {
+		c, err := newCache(cfg.CacheDir, cfg.CacheMaxBytes)
+		if err != nil {
+			return nil, fmt.Errorf("new: %w", err)
+		}
+		f.cache = c
+	}
+
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+
+	transport := http.Transport{
+		Proxy: nil,
+		DialContext: func(ctx context.Context, network string, addr string) (net.Conn, error) {
+			return f.dial(ctx, &dialer, network, addr)
+		},
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: cfg.Timeout,
+		MaxIdleConnsPerHost:   4,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	f.client = &http.Client{
+		Transport: &transport,
+		Timeout:   cfg.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+
+	return &f, nil
+}
+
+// MediaFetcher returns a model.MediaFetcher backed by f, or nil when f is
+// nil or allows no host so media URLs are rejected.
+func (f *Fetcher) MediaFetcher() model.MediaFetcher {
+	if f == nil || !f.AllowsAny() {
+		return nil
+	}
+
+	return f.Fetch
+}
+
+// AllowsAny reports whether the allowlist has any entries. A fetcher with
+// an empty allowlist refuses every URL.
+func (f *Fetcher) AllowsAny() bool {
+	return len(f.hosts) > 0 || len(f.prefixes) > 0
+}
+
+// Fetch downloads the content at rawURL.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("fetch: unsupported scheme %q", u.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+
+	cached, cachedData, hasCached := f.cache.get(rawURL)
+	if hasCached {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hasCached:
+		return cachedData, nil
+
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("fetch: status %d", resp.StatusCode)
+	}
+
+	if f.maxBytes > 0 && resp.ContentLength > f.maxBytes {
+		return nil, fmt.Errorf("fetch: %w: limit is %d bytes", ErrTooLarge, f.maxBytes)
+	}
+
+	body := io.Reader(resp.Body)
+	if f.maxBytes > 0 {
+		body = io.LimitReader(resp.Body, f.maxBytes+1)
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: read: %w", err)
+	}
+
+	if f.maxBytes > 0 && int64(len(data)) > f.maxBytes {
+		return nil, fmt.Errorf("fetch: %w: limit is %d bytes", ErrTooLarge, f.maxBytes)
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		f.cache.store(rawURL, etag, data)
+	}
+
+	return data, nil
+}
+
+// =============================================================================
+
+// dial connects to the first address of the host that the rules allow.
+// Checking the resolved address at dial time, rather than the URL, also
+// covers redirects and host names that resolve to private addresses.
+func (f *Fetcher) dial(ctx context.Context, dialer *net.Dialer, network string, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []netip.Addr
+	if ip, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{ip}
+	} else {
+		ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+		addrs = ips
+	}
+
+	var lastErr error = fmt.Errorf("%w: %s", ErrNotAllowed, host)
+	for _, ip := range addrs {
+		if !f.allowed(host, ip.Unmap()) {
+			continue
+		}
+
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.Unmap().String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+// allowed reports whether a connection to ip for host is permitted.
+func (f *Fetcher) allowed(host string, ip netip.Addr) bool {
+	inPrefix := false
+	for _, prefix := range f.prefixes {
+		if prefix.Contains(ip) {
+			inPrefix = true
+			break
+		}
+	}
+
+	if !inPrefix && !f.hostAllowed(host) {
+		return false
+	}
+
+	if !f.allowPrivate && !inPrefix && private(ip) {
+		return false
+	}
+
+	return true
+}
+
+func (f *Fetcher) hostAllowed(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	for _, allowed := range f.hosts {
+		if allowed == "*" {
+			return true
+		}
+
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+
+		if host == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// private reports whether ip is not a public unicast address.
+func private(ip netip.Addr) bool {
+	return ip.IsPrivate() ||
+		ip.IsLoopback() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// =============================================================================
+
+// cacheEntry is the metadata kept next to a cached download.
+type cacheEntry struct {
+	URL  string `json:"url"`
+	ETag string `json:"etag"`
+}
+
+// cacheFile is the index entry of a cached download.
+type cacheFile struct {
+	key  string
+	size int64
+}
+
+// cache keeps downloads that carry an ETag on disk. The index of cached
+// downloads is kept in memory, most recently used first, and the content is
+// only read outside the lock. Files that vanish between the two are treated
+// as not cached.
+type cache struct {
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	used  list.List
+	files map[string]*list.Element
+	bytes int64
+}
+
+// newCache opens the cache in dir. Downloads already there are ordered by
+// when they were last used, and the least recently used are removed if they
+// take more than maxBytes.
+func newCache(dir string, maxBytes int64) (*cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create %s: %w", dir, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", dir, err)
+	}
+
+	type found struct {
+		cacheFile
+		used time.Time
+	}
+
+	var files []found
+	for _, entry := range entries {
+		key, ok := strings.CutSuffix(entry.Name(), ".data")
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		files = append(files, found{cacheFile: cacheFile{key: key, size: info.Size()}, used: info.ModTime()})
+	}
+
+	slices.SortFunc(files, func(a found, b found) int {
+		return b.used.Compare(a.used)
+	})
+
+	c := cache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		files:    make(map[string]*list.Element),
+	}
+
+	for _, file := range files {
+		c.files[file.key] = c.used.PushBack(&file.cacheFile)
+		c.bytes += file.size
+	}
+
+	c.evict()
+
+	return &c, nil
+}
+
+// get returns the cached download of rawURL and marks it as used.
+func (c *cache) get(rawURL string) (cacheEntry, []byte, bool) {
+	if c == nil {
+		return cacheEntry{}, nil, false
+	}
+
+	key := cacheKey(rawURL)
+
+	c.mu.Lock()
+	elem, exists := c.files[key]
+	if exists {
+		c.used.MoveToFront(elem)
+	}
+	c.mu.Unlock()
+
+	if !exists {
+		return cacheEntry{}, nil, false
+	}
+
+	meta, err := os.ReadFile(c.path(key, ".json"))
+	if err != nil {
+		return cacheEntry{}, nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(meta, &entry); err != nil || entry.URL != rawURL || entry.ETag == "" {
+		return cacheEntry{}, nil, false
+	}
+
+	data, err := os.ReadFile(c.path(key, ".data"))
+	if err != nil {
+		return cacheEntry{}, nil, false
+	}
+
+	// The modification time records the use so the order survives a
+	// restart.
+	now := time.Now()
+	os.Chtimes(c.path(key, ".data"), now, now)
+
+	return entry, data, true
+}
+
+// store caches a download and removes the least recently used downloads
+// the cache no longer has room for. Failures are ignored since the cache
+// only saves a later download.
+func (c *cache) store(rawURL string, etag string, data []byte) {
+	if c == nil {
+		return
+	}
+
+	size := int64(len(data))
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return
+	}
+
+	meta, err := json.Marshal(cacheEntry{URL: rawURL, ETag: etag})
+	if err != nil {
+		return
+	}
+
+	key := cacheKey(rawURL)
+
+	// The content is written before the metadata so an entry is never
+	// visible without its content.
+	if err := writeFile(c.path(key, ".data"), data); err != nil {
+		return
+	}
+
+	if err := writeFile(c.path(key, ".json"), meta); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, exists := c.files[key]; exists {
+		c.bytes -= c.used.Remove(elem).(*cacheFile).size
+	}
+
+	c.files[key] = c.used.PushFront(&cacheFile{key: key, size: size})
+	c.bytes += size
+
+	c.evict()
+}
+
+// evict removes the least recently used downloads until the cache fits in
+// maxBytes. The caller must hold the lock unless the cache is not shared
+// yet.
+func (c *cache) evict() {
+	for c.maxBytes > 0 && c.bytes > c.maxBytes {
+		elem := c.used.Back()
+		if elem == nil {
+			return
+		}
+
+		file := c.used.Remove(elem).(*cacheFile)
+		delete(c.files, file.key)
+		c.bytes -= file.size
+
+		// The metadata goes first so an entry is never visible without its
+		// content.
+		os.Remove(c.path(file.key, ".json"))
+		os.Remove(c.path(file.key, ".data"))
+	}
+}
+
+func (c *cache) path(key string, ext string) string {
+	return filepath.Join(c.dir, key+ext)
+}
+
+func cacheKey(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// writeFile replaces the file at path atomically.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cache-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
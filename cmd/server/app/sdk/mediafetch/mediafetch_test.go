@@ -0,0 +1,242 @@
+package mediafetch
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+var png = []byte("\x89PNG\r\n\x1a\n0000")
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/chart.png":
+			w.Write(png)
+		case "/large.png":
+			w.Write(append(png, make([]byte, 64)...))
+		case "/redirect":
+			http.Redirect(w, r, "/chart.png", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	denied, err := New(Config{AllowedHosts: []string{"*"}})
+	if err != nil {
+		t.Fatalf("should be able to construct fetcher: %s", err)
+	}
+
+	if _, err := denied.Fetch(t.Context(), srv.URL+"/chart.png"); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("private address: got %v, want %v", err, ErrNotAllowed)
+	}
+
+	empty, err := New(Config{AllowPrivate: true})
+	if err != nil {
+		t.Fatalf("should be able to construct fetcher: %s", err)
+	}
+
+	if empty.MediaFetcher() != nil {
+		t.Error("media fetcher with an empty allowlist should be nil")
+	}
+
+	if _, err := empty.Fetch(t.Context(), srv.URL+"/chart.png"); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("empty allowlist: got %v, want %v", err, ErrNotAllowed)
+	}
+
+	f, err := New(Config{AllowedHosts: []string{"127.0.0.0/8"}, MaxBytes: 32})
+	if err != nil {
+		t.Fatalf("should be able to construct fetcher: %s", err)
+	}
+
+	for _, path := range []string{"/chart.png", "/redirect"} {
+		data, err := f.Fetch(t.Context(), srv.URL+path)
+		if err != nil {
+			t.Fatalf("fetch %s: %s", path, err)
+		}
+		if string(data) != string(png) {
+			t.Errorf("fetch %s: got %q", path, data)
+		}
+	}
+
+	if _, err := f.Fetch(t.Context(), srv.URL+"/large.png"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("large: got %v, want %v", err, ErrTooLarge)
+	}
+
+	if _, err := f.Fetch(t.Context(), srv.URL+"/missing.png"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("missing: got %v, want a 404 error", err)
+	}
+
+	if _, err := f.Fetch(t.Context(), "file:///etc/passwd"); err == nil {
+		t.Error("file url: want an error")
+	}
+
+	var disabled *Fetcher
+	if disabled.MediaFetcher() != nil {
+		t.Error("media fetcher on a nil fetcher should be nil")
+	}
+}
+
+func TestFetchCache(t *testing.T) {
+	var downloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		w.Write(png)
+	}))
+	defer srv.Close()
+
+	f, err := New(Config{AllowedHosts: []string{"*"}, AllowPrivate: true, CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("should be able to construct fetcher: %s", err)
+	}
+
+	for range 2 {
+		data, err := f.Fetch(t.Context(), srv.URL+"/chart.png")
+		if err != nil {
+			t.Fatalf("fetch: %s", err)
+		}
+		if string(data) != string(png) {
+			t.Errorf("fetch: got %q", data)
+		}
+	}
+
+	if got := downloads.Load(); got != 1 {
+		t.Errorf("downloads: got %d, want the cached copy revalidated", got)
+	}
+}
+
+func TestFetchCacheEviction(t *testing.T) {
+	var mu sync.Mutex
+	downloads := make(map[string]int)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		mu.Lock()
+		downloads[r.URL.Path]++
+		mu.Unlock()
+
+		w.Write(png)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	// The cache has room for two downloads.
+	f, err := New(Config{AllowedHosts: []string{"*"}, AllowPrivate: true, CacheDir: dir, CacheMaxBytes: 2 * int64(len(png))})
+	if err != nil {
+		t.Fatalf("should be able to construct fetcher: %s", err)
+	}
+
+	// Using a again makes b the least recently used, so c replaces it.
+	for _, path := range []string{"/a", "/b", "/a", "/c", "/a", "/b"} {
+		if _, err := f.Fetch(t.Context(), srv.URL+path); err != nil {
+			t.Fatalf("fetch %s: %s", path, err)
+		}
+	}
+
+	want := map[string]int{"/a": 1, "/b": 2, "/c": 1}
+	for path, n := range want {
+		if downloads[path] != n {
+			t.Errorf("downloads %s: got %d, want %d", path, downloads[path], n)
+		}
+	}
+
+	if got := cachedFiles(t, dir); got != 2 {
+		t.Errorf("cached files: got %d, want 2", got)
+	}
+
+	// Reopening the cache with less room removes downloads until it fits.
+	if _, err := New(Config{AllowedHosts: []string{"*"}, CacheDir: dir, CacheMaxBytes: int64(len(png))}); err != nil {
+		t.Fatalf("should be able to construct fetcher: %s", err)
+	}
+
+	if got := cachedFiles(t, dir); got != 1 {
+		t.Errorf("cached files after reopen: got %d, want 1", got)
+	}
+
+	// A download larger than the cache is not kept.
+	small, err := New(Config{AllowedHosts: []string{"*"}, AllowPrivate: true, CacheDir: t.TempDir(), CacheMaxBytes: 4})
+	if err != nil {
+		t.Fatalf("should be able to construct fetcher: %s", err)
+	}
+
+	if _, err := small.Fetch(t.Context(), srv.URL+"/d"); err != nil {
+		t.Fatalf("fetch: %s", err)
+	}
+
+	if got := cachedFiles(t, small.cache.dir); got != 0 {
+		t.Errorf("cached files over the limit: got %d, want 0", got)
+	}
+}
+
+// cachedFiles counts the downloads kept in the cache directory.
+func cachedFiles(t *testing.T, dir string) int {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.data"))
+	if err != nil {
+		t.Fatalf("should be able to list the cache: %s", err)
+	}
+
+	return len(matches)
+}
+
+func TestAllowed(t *testing.T) {
+	public := netip.MustParseAddr("93.184.216.34")
+	anyHost := Config{AllowedHosts: []string{"*"}}
+
+	tests := []struct {
+		name  string
+		cfg   Config
+		host  string
+		ip    netip.Addr
+		allow bool
+	}{
+		{name: "empty allowlist", host: "example.com", ip: public},
+		{name: "empty allowlist private allowed", cfg: Config{AllowPrivate: true}, host: "intranet", ip: netip.MustParseAddr("10.1.2.3")},
+		{name: "any public host", cfg: anyHost, host: "example.com", ip: public, allow: true},
+		{name: "loopback", cfg: anyHost, host: "localhost", ip: netip.MustParseAddr("127.0.0.1")},
+		{name: "private", cfg: anyHost, host: "intranet", ip: netip.MustParseAddr("10.1.2.3")},
+		{name: "link local metadata", cfg: anyHost, host: "169.254.169.254", ip: netip.MustParseAddr("169.254.169.254")},
+		{name: "shared address space", cfg: anyHost, host: "cgnat", ip: netip.MustParseAddr("100.64.0.1")},
+		{name: "ipv6 loopback", cfg: anyHost, host: "::1", ip: netip.MustParseAddr("::1")},
+		{name: "private allowed", cfg: Config{AllowedHosts: []string{"*"}, AllowPrivate: true}, host: "intranet", ip: netip.MustParseAddr("10.1.2.3"), allow: true},
+		{name: "listed host", cfg: Config{AllowedHosts: []string{"Example.com"}}, host: "example.com", ip: public, allow: true},
+		{name: "unlisted host", cfg: Config{AllowedHosts: []string{"example.com"}}, host: "other.com", ip: public},
+		{name: "wildcard subdomain", cfg: Config{AllowedHosts: []string{"*.example.com"}}, host: "cdn.example.com", ip: public, allow: true},
+		{name: "wildcard apex", cfg: Config{AllowedHosts: []string{"*.example.com"}}, host: "example.com", ip: public},
+		{name: "listed host resolving privately", cfg: Config{AllowedHosts: []string{"example.com"}}, host: "example.com", ip: netip.MustParseAddr("192.168.1.1")},
+		{name: "cidr allows private", cfg: Config{AllowedHosts: []string{"10.0.0.0/8"}}, host: "media.internal", ip: netip.MustParseAddr("10.1.2.3"), allow: true},
+		{name: "outside cidr", cfg: Config{AllowedHosts: []string{"10.0.0.0/8"}}, host: "example.com", ip: public},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.cfg)
+			if err != nil {
+				t.Fatalf("should be able to construct fetcher: %s", err)
+			}
+
+			if got := f.allowed(tt.host, tt.ip); got != tt.allow {
+				t.Errorf("allowed: got %t, want %t", got, tt.allow)
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ type Access struct {
 
 type authenticator interface {
 	Authenticate(ctx context.Context, bearerToken string, admin bool, endpoint string) (authclient.AuthenticateReponse, error)
+	modelAuthorizer
 }
 
 // NewAccess constructs route access middleware for an authorization mode.
@@ -106,12 +107,28 @@ func publicAccess() web.MidFunc {
 func authenticate(client authenticator, admin bool, endpoint string) web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
-			ar, err := client.Authenticate(ctx, r.Header.Get("authorization"), admin, endpoint)
+			bearer := r.Header.Get("authorization")
+
+			ar, err := client.Authenticate(ctx, bearer, admin, endpoint)
 			if err != nil {
 				return authenticationError(err)
 			}
 
 			ctx = setSubject(ctx, ar.Subject)
+			ctx = setPriority(ctx, ar.Priority)
+			ctx = setTokenBudgets(ctx, ar.TokenBudgets)
+			ctx = setToken(ctx, Token{
+				ID:        ar.TokenID,
+				Bearer:    bearer,
+				ExpiresAt: ar.ExpiresAt,
+			})
+
+			if ar.ModelScoped {
+				ctx = setModelScope(ctx, &modelScope{
+					authorizer: client,
+					bearer:     bearer,
+				})
+			}
 
 			return next(ctx, r)
 		}
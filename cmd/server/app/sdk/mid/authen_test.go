@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
@@ -15,6 +16,8 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+var stubExpiresAt = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
 type authenticatorStub struct {
 	calls    int
 	admin    bool
@@ -25,7 +28,15 @@ func (as *authenticatorStub) Authenticate(_ context.Context, _ string, admin boo
 	as.calls++
 	as.admin = admin
 	as.endpoint = endpoint
-	return authclient.AuthenticateReponse{Subject: "subject"}, nil
+	return authclient.AuthenticateReponse{Subject: "subject", TokenID: "token-id", ExpiresAt: stubExpiresAt}, nil
+}
+
+func (as *authenticatorStub) AuthorizeModel(context.Context, string, string) error {
+	return nil
+}
+
+func (as *authenticatorStub) AuthorizedModels(_ context.Context, _ string, models []string) ([]string, error) {
+	return models, nil
 }
 
 func TestAccess(t *testing.T) {
@@ -111,3 +122,23 @@ func TestAuthenticationError(t *testing.T) {
 		})
 	}
 }
+
+func TestAccessToken(t *testing.T) {
+	stub := &authenticatorStub{}
+	access := Access{client: stub, mode: auth.FullProtected}
+
+	var token Token
+	handler := access.Inference("chat-completions")(func(ctx context.Context, _ *http.Request) web.Encoder {
+		token = GetToken(ctx)
+		return nil
+	})
+
+	r := httptest.NewRequest("POST", "/", nil)
+	r.Header.Set("authorization", "Bearer token")
+	handler(context.Background(), r)
+
+	want := Token{ID: "token-id", Bearer: "Bearer token", ExpiresAt: stubExpiresAt}
+	if token != want {
+		t.Errorf("token: got %+v, want %+v", token, want)
+	}
+}
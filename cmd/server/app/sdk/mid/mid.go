@@ -3,6 +3,7 @@ package mid
 
 import (
 	"context"
+	"time"
 
 	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
 )
@@ -30,12 +31,71 @@ type ctxKey int
 
 const (
 	subjectKey ctxKey = iota + 1
+	priorityKey
+	tokenBudgetsKey
+	tokenBudgetKey
+	modelScopeKey
+	tokenKey
 )
 
 func setSubject(ctx context.Context, subject string) context.Context {
 	return context.WithValue(ctx, subjectKey, subject)
 }
 
+func setPriority(ctx context.Context, priority string) context.Context {
+	return context.WithValue(ctx, priorityKey, priority)
+}
+
+func getPriority(ctx context.Context) string {
+	v, _ := ctx.Value(priorityKey).(string)
+	return v
+}
+
+func setTokenBudgets(ctx context.Context, budgets bool) context.Context {
+	return context.WithValue(ctx, tokenBudgetsKey, budgets)
+}
+
+func getTokenBudgets(ctx context.Context) bool {
+	v, _ := ctx.Value(tokenBudgetsKey).(bool)
+	return v
+}
+
+func setTokenBudget(ctx context.Context, b *tokenBudget) context.Context {
+	return context.WithValue(ctx, tokenBudgetKey, b)
+}
+
+func getTokenBudget(ctx context.Context) *tokenBudget {
+	v, _ := ctx.Value(tokenBudgetKey).(*tokenBudget)
+	return v
+}
+
+func setModelScope(ctx context.Context, s *modelScope) context.Context {
+	return context.WithValue(ctx, modelScopeKey, s)
+}
+
+func getModelScope(ctx context.Context) *modelScope {
+	v, _ := ctx.Value(modelScopeKey).(*modelScope)
+	return v
+}
+
+// Token identifies the bearer token that authenticated a request. ID and
+// ExpiresAt are empty when authentication is disabled.
+type Token struct {
+	ID        string
+	Bearer    string
+	ExpiresAt time.Time
+}
+
+func setToken(ctx context.Context, token Token) context.Context {
+	return context.WithValue(ctx, tokenKey, token)
+}
+
+// GetToken returns the bearer token that authenticated the request.
+func GetToken(ctx context.Context) Token {
+	v, _ := ctx.Value(tokenKey).(Token)
+	return v
+}
+
 // GetSubject returns the subject from the context.
 func GetSubject(ctx context.Context) string {
 	v, ok := ctx.Value(subjectKey).(string)
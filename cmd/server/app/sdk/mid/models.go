@@ -0,0 +1,59 @@
+package mid
+
+import (
+	"context"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type modelAuthorizer interface {
+	AuthorizeModel(ctx context.Context, bearerToken string, model string) error
+	AuthorizedModels(ctx context.Context, bearerToken string, models []string) ([]string, error)
+}
+
+// modelScope carries what is needed to check the models of a request whose
+// token is limited to a set of models.
+type modelScope struct {
+	authorizer modelAuthorizer
+	bearer     string
+}
+
+// AuthorizeModel checks the request's token may use modelID and returns a
+// PermissionDenied error when it may not. Requests whose token is not
+// limited to a set of models, including all requests when authentication is
+// disabled, are always allowed.
+func AuthorizeModel(ctx context.Context, modelID string) *errs.Error {
+	s := getModelScope(ctx)
+	if s == nil {
+		return nil
+	}
+
+	if err := s.authorizer.AuthorizeModel(ctx, s.bearer, modelID); err != nil {
+		if status.Code(err) == codes.PermissionDenied {
+			return errs.Errorf(errs.PermissionDenied, "model %q not authorized", modelID)
+		}
+
+		return authenticationError(err)
+	}
+
+	return nil
+}
+
+// AuthorizedModels filters modelIDs to the models the request's token may
+// use, keeping their order. The list is returned untouched for tokens that
+// are not limited to a set of models.
+func AuthorizedModels(ctx context.Context, modelIDs []string) ([]string, *errs.Error) {
+	s := getModelScope(ctx)
+	if s == nil {
+		return modelIDs, nil
+	}
+
+	allowed, err := s.authorizer.AuthorizedModels(ctx, s.bearer, modelIDs)
+	if err != nil {
+		return nil, authenticationError(err)
+	}
+
+	return allowed, nil
+}
@@ -0,0 +1,91 @@
+package mid
+
+import (
+	"context"
+	"path"
+	"slices"
+	"testing"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeAuthorizer struct {
+	patterns []string
+}
+
+func (a *fakeAuthorizer) allowed(model string) bool {
+	for _, p := range a.patterns {
+		if ok, _ := path.Match(p, model); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *fakeAuthorizer) AuthorizeModel(ctx context.Context, bearerToken string, model string) error {
+	if !a.allowed(model) {
+		return status.Error(codes.PermissionDenied, "permission denied")
+	}
+
+	return nil
+}
+
+func (a *fakeAuthorizer) AuthorizedModels(ctx context.Context, bearerToken string, models []string) ([]string, error) {
+	var allowed []string
+	for _, m := range models {
+		if a.allowed(m) {
+			allowed = append(allowed, m)
+		}
+	}
+
+	return allowed, nil
+}
+
+func TestAuthorizeModel(t *testing.T) {
+	models := []string{"Qwen3-8B-Q8_0", "Llama-3.3-70B-Instruct-Q4_K_M", "Qwen3-0.6B-Q8_0"}
+
+	t.Run("unscoped", func(t *testing.T) {
+		ctx := context.Background()
+
+		if err := AuthorizeModel(ctx, models[1]); err != nil {
+			t.Fatalf("AuthorizeModel: got %v, want nil", err)
+		}
+
+		got, err := AuthorizedModels(ctx, models)
+		if err != nil {
+			t.Fatalf("AuthorizedModels: %v", err)
+		}
+		if !slices.Equal(got, models) {
+			t.Errorf("AuthorizedModels: got %v, want %v", got, models)
+		}
+	})
+
+	t.Run("scoped", func(t *testing.T) {
+		ctx := setModelScope(context.Background(), &modelScope{
+			authorizer: &fakeAuthorizer{patterns: []string{"Qwen3-*"}},
+			bearer:     "Bearer token",
+		})
+
+		if err := AuthorizeModel(ctx, models[0]); err != nil {
+			t.Fatalf("AuthorizeModel allowed: got %v, want nil", err)
+		}
+
+		err := AuthorizeModel(ctx, models[1])
+		if err == nil || err.Code != errs.PermissionDenied {
+			t.Fatalf("AuthorizeModel denied: got %v, want PermissionDenied", err)
+		}
+
+		got, aerr := AuthorizedModels(ctx, models)
+		if aerr != nil {
+			t.Fatalf("AuthorizedModels: %v", aerr)
+		}
+
+		want := []string{"Qwen3-8B-Q8_0", "Qwen3-0.6B-Q8_0"}
+		if !slices.Equal(got, want) {
+			t.Errorf("AuthorizedModels: got %v, want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"uuid"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+)
+
+const (
+	// PriorityHeader requests a scheduling priority: low, normal, or high.
+	PriorityHeader = "X-Kronk-Priority"
+
+	// TenantHeader names the tenant of a request when authentication is
+	// disabled. Authenticated requests are always scheduled as the token's
+	// subject.
+	TenantHeader = "X-Kronk-Tenant"
+)
+
+// Schedule sets the priority and tenant the batch engine uses to order the
+// request against other waiting requests. The token's priority claim, or
+// else priority, is the highest priority the request may use and its
+// default. A PriorityHeader value can lower the priority but never raise it.
+func Schedule(priority model.Priority) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			schedule, err := resolveSchedule(ctx, r, priority)
+			if err != nil {
+				return errs.New(errs.InvalidArgument, err)
+			}
+
+			ctx = model.SetSchedule(ctx, schedule)
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+func resolveSchedule(ctx context.Context, r *http.Request, priority model.Priority) (model.Schedule, error) {
+	if claim := getPriority(ctx); claim != "" {
+		p, err := model.ParsePriority(claim)
+		if err != nil {
+			return model.Schedule{}, err
+		}
+		priority = p
+	}
+
+	if v := r.Header.Get(PriorityHeader); v != "" {
+		p, err := model.ParsePriority(v)
+		if err != nil {
+			return model.Schedule{}, err
+		}
+		priority = min(priority, p)
+	}
+
+	tenant := GetSubject(ctx)
+	if tenant == "" || tenant == uuid.Nil().String() {
+		tenant = r.Header.Get(TenantHeader)
+	}
+
+	return model.Schedule{Priority: priority, Tenant: tenant}, nil
+}
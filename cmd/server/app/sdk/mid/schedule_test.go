@@ -0,0 +1,73 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"uuid"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+)
+
+func TestSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority model.Priority
+		subject  string
+		claim    string
+		header   string
+		tenant   string
+		want     model.Schedule
+		wantErr  bool
+	}{
+		{name: "default", want: model.Schedule{Priority: model.PriorityNormal}},
+		{name: "endpoint default", priority: model.PriorityLow, want: model.Schedule{Priority: model.PriorityLow}},
+		{name: "header lowers", header: "low", want: model.Schedule{Priority: model.PriorityLow}},
+		{name: "header cannot raise", header: "high", want: model.Schedule{Priority: model.PriorityNormal}},
+		{name: "claim raises ceiling", claim: "high", header: "high", want: model.Schedule{Priority: model.PriorityHigh}},
+		{name: "claim overrides endpoint", priority: model.PriorityHigh, claim: "low", want: model.Schedule{Priority: model.PriorityLow}},
+		{name: "invalid header", header: "urgent", wantErr: true},
+		{name: "subject is tenant", subject: "subject", tenant: "other", want: model.Schedule{Tenant: "subject"}},
+		{name: "tenant header without auth", tenant: "team-a", want: model.Schedule{Tenant: "team-a"}},
+		{name: "tenant header with public access", subject: uuid.Nil().String(), tenant: "team-a", want: model.Schedule{Tenant: "team-a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got model.Schedule
+			handler := Schedule(tt.priority)(func(ctx context.Context, _ *http.Request) web.Encoder {
+				got = model.GetSchedule(ctx)
+				return nil
+			})
+
+			r := httptest.NewRequest("POST", "/", nil)
+			if tt.header != "" {
+				r.Header.Set(PriorityHeader, tt.header)
+			}
+			if tt.tenant != "" {
+				r.Header.Set(TenantHeader, tt.tenant)
+			}
+
+			ctx := setPriority(setSubject(context.Background(), tt.subject), tt.claim)
+			resp := handler(ctx, r)
+
+			if tt.wantErr {
+				appErr, ok := resp.(*errs.Error)
+				if !ok || appErr.Code != errs.InvalidArgument {
+					t.Fatalf("response: got %v, want invalid argument error", resp)
+				}
+				return
+			}
+			if resp != nil {
+				t.Fatalf("response: got %v, want nil", resp)
+			}
+			if got != tt.want {
+				t.Errorf("schedule: got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
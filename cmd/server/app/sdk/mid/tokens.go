@@ -0,0 +1,180 @@
+package mid
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+)
+
+// chargeTimeout bounds the call that charges a completed request's tokens,
+// which runs after the request context may already be canceled.
+const chargeTimeout = 5 * time.Second
+
+type tokenMeter interface {
+	CheckTokens(ctx context.Context, bearerToken string, model string) (authclient.TokenQuotasResponse, error)
+	ChargeTokens(ctx context.Context, bearerToken string, model string, input int64, output int64) (authclient.TokenQuotasResponse, error)
+}
+
+// tokenBudget carries a request's token accounting from the handler back to
+// the TokenBudget middleware.
+type tokenBudget struct {
+	meter  tokenMeter
+	bearer string
+	model  string
+	input  int64
+	output int64
+}
+
+// TokenBudget charges the input and output tokens of a request to the token
+// budgets of its bearer token once the handler returns. Handlers call
+// CheckTokenBudget when the model is known and RecordTokenUsage when the
+// usage is known. Requests whose token carries no budgets, including all
+// requests when authentication is disabled, pass through untouched.
+func TokenBudget(log *logger.Logger, client *authclient.Client) web.MidFunc {
+	return tokenBudgetMid(log, client)
+}
+
+func tokenBudgetMid(log *logger.Logger, meter tokenMeter) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			if !getTokenBudgets(ctx) {
+				return next(ctx, r)
+			}
+
+			b := tokenBudget{
+				meter:  meter,
+				bearer: r.Header.Get("authorization"),
+			}
+
+			resp := next(setTokenBudget(ctx, &b), r)
+
+			if b.model == "" || (b.input == 0 && b.output == 0) {
+				return resp
+			}
+
+			// The request context is canceled once a streamed response ends or
+			// the client goes away, but the tokens were still used.
+			chargeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), chargeTimeout)
+			defer cancel()
+
+			if _, err := meter.ChargeTokens(chargeCtx, b.bearer, b.model, b.input, b.output); err != nil {
+				log.Error(ctx, "token-budget", "status", "charge failed", "model", b.model, "input", b.input, "output", b.output, "err", err)
+			}
+
+			return resp
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// CheckTokenBudget checks the token budgets that apply to a request for
+// modelID and sets the x-ratelimit headers from the tightest of them. It
+// returns a TooManyRequests error with a Retry-After header when a budget is
+// used up. It does nothing outside the TokenBudget middleware.
+func CheckTokenBudget(ctx context.Context, modelID string) *errs.Error {
+	b := getTokenBudget(ctx)
+	if b == nil {
+		return nil
+	}
+
+	resp, err := b.meter.CheckTokens(ctx, b.bearer, modelID)
+	if err != nil {
+		return authenticationError(err)
+	}
+
+	b.model = modelID
+
+	now := time.Now()
+
+	w := web.GetWriter(ctx)
+	if w != nil {
+		setRateLimitHeaders(w.Header(), resp.Quotas, now)
+	}
+
+	if !resp.Exceeded {
+		return nil
+	}
+
+	if w != nil {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter(resp.Quotas, now)))
+	}
+
+	return errs.Errorf(errs.TooManyRequests, "token budget exceeded for model %q", modelID)
+}
+
+// RecordTokenUsage adds the input and output tokens a request used so the
+// TokenBudget middleware can charge them. It does nothing outside the
+// middleware.
+func RecordTokenUsage(ctx context.Context, input int, output int) {
+	b := getTokenBudget(ctx)
+	if b == nil {
+		return
+	}
+
+	b.input += int64(max(input, 0))
+	b.output += int64(max(output, 0))
+}
+
+// =============================================================================
+
+// setRateLimitHeaders reports, for each capped direction, the budget with the
+// fewest tokens remaining.
+func setRateLimitHeaders(h http.Header, quotas []authclient.TokenQuota, now time.Time) {
+	setDirection := func(direction string, limit func(authclient.TokenQuota) int64, used func(authclient.TokenQuota) int64) {
+		var tightest *authclient.TokenQuota
+		var remaining int64 = math.MaxInt64
+
+		for i, q := range quotas {
+			if limit(q) <= 0 {
+				continue
+			}
+
+			if left := max(limit(q)-used(q), 0); left < remaining {
+				tightest = &quotas[i]
+				remaining = left
+			}
+		}
+
+		if tightest == nil {
+			return
+		}
+
+		h.Set("x-ratelimit-limit-"+direction+"-tokens", strconv.FormatInt(limit(*tightest), 10))
+		h.Set("x-ratelimit-remaining-"+direction+"-tokens", strconv.FormatInt(remaining, 10))
+		h.Set("x-ratelimit-reset-"+direction+"-tokens", max(tightest.Reset.Sub(now), 0).Round(time.Second).String())
+	}
+
+	setDirection("input",
+		func(q authclient.TokenQuota) int64 { return q.InputLimit },
+		func(q authclient.TokenQuota) int64 { return q.InputUsed })
+
+	setDirection("output",
+		func(q authclient.TokenQuota) int64 { return q.OutputLimit },
+		func(q authclient.TokenQuota) int64 { return q.OutputUsed })
+}
+
+// retryAfter returns the seconds until every exhausted budget resets.
+func retryAfter(quotas []authclient.TokenQuota, now time.Time) int {
+	var wait time.Duration
+
+	for _, q := range quotas {
+		exhausted := (q.InputLimit > 0 && q.InputUsed >= q.InputLimit) ||
+			(q.OutputLimit > 0 && q.OutputUsed >= q.OutputLimit)
+
+		if exhausted {
+			wait = max(wait, q.Reset.Sub(now))
+		}
+	}
+
+	return int(math.Ceil(wait.Seconds()))
+}
@@ -0,0 +1,138 @@
+package mid
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/errs"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
+	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+)
+
+type fakeMeter struct {
+	quotas  authclient.TokenQuotasResponse
+	charged []int64
+	model   string
+}
+
+func (m *fakeMeter) CheckTokens(ctx context.Context, bearerToken string, model string) (authclient.TokenQuotasResponse, error) {
+	return m.quotas, nil
+}
+
+func (m *fakeMeter) ChargeTokens(ctx context.Context, bearerToken string, model string, input int64, output int64) (authclient.TokenQuotasResponse, error) {
+	m.model = model
+	m.charged = []int64{input, output}
+	return m.quotas, nil
+}
+
+func TestTokenBudget(t *testing.T) {
+	reset := time.Now().Add(time.Hour)
+	log := logger.New(io.Discard, logger.LevelInfo, "test", func(context.Context) string { return "" })
+
+	// The handler runs behind a web.App so CheckTokenBudget can reach the
+	// response writer.
+	run := func(meter *fakeMeter, budgets bool, handler web.HandlerFunc) (*httptest.ResponseRecorder, web.Encoder) {
+		authenticated := func(next web.HandlerFunc) web.HandlerFunc {
+			return func(ctx context.Context, r *http.Request) web.Encoder {
+				return next(setTokenBudgets(ctx, budgets), r)
+			}
+		}
+
+		var resp web.Encoder
+		capture := func(next web.HandlerFunc) web.HandlerFunc {
+			return func(ctx context.Context, r *http.Request) web.Encoder {
+				resp = next(ctx, r)
+				return resp
+			}
+		}
+
+		app := web.NewApp(func(context.Context, string, ...any) {})
+		app.HandlerFunc(http.MethodPost, "", "/tokens", handler, authenticated, capture, tokenBudgetMid(log, meter))
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/tokens", nil))
+
+		return w, resp
+	}
+
+	t.Run("charges recorded usage", func(t *testing.T) {
+		meter := fakeMeter{
+			quotas: authclient.TokenQuotasResponse{
+				Quotas: []authclient.TokenQuota{
+					{InputLimit: 1000, InputUsed: 200, Reset: reset},
+					{Model: "m", InputLimit: 500, InputUsed: 400, OutputLimit: 100, OutputUsed: 10, Reset: reset},
+				},
+			},
+		}
+
+		w, resp := run(&meter, true, func(ctx context.Context, _ *http.Request) web.Encoder {
+			if err := CheckTokenBudget(ctx, "m"); err != nil {
+				return err
+			}
+			RecordTokenUsage(ctx, 30, 7)
+			return nil
+		})
+
+		if resp != nil {
+			t.Fatalf("response: got %v, want nil", resp)
+		}
+		if got := w.Header().Get("x-ratelimit-remaining-input-tokens"); got != "100" {
+			t.Errorf("remaining input: got %q, want 100", got)
+		}
+		if got := w.Header().Get("x-ratelimit-limit-output-tokens"); got != "100" {
+			t.Errorf("output limit: got %q, want 100", got)
+		}
+		if meter.model != "m" || len(meter.charged) != 2 || meter.charged[0] != 30 || meter.charged[1] != 7 {
+			t.Errorf("charge: got %q %v, want m [30 7]", meter.model, meter.charged)
+		}
+	})
+
+	t.Run("rejects exhausted budget", func(t *testing.T) {
+		meter := fakeMeter{
+			quotas: authclient.TokenQuotasResponse{
+				Quotas:   []authclient.TokenQuota{{OutputLimit: 100, OutputUsed: 120, Reset: reset}},
+				Exceeded: true,
+			},
+		}
+
+		w, resp := run(&meter, true, func(ctx context.Context, _ *http.Request) web.Encoder {
+			if err := CheckTokenBudget(ctx, "m"); err != nil {
+				return err
+			}
+			t.Fatal("handler should not continue past an exhausted budget")
+			return nil
+		})
+
+		appErr, ok := resp.(*errs.Error)
+		if !ok || appErr.Code != errs.TooManyRequests {
+			t.Fatalf("response: got %v, want TooManyRequests", resp)
+		}
+		if got := w.Header().Get("Retry-After"); got == "" || got == "0" {
+			t.Errorf("Retry-After: got %q, want seconds until reset", got)
+		}
+		if meter.charged != nil {
+			t.Errorf("charge: got %v, want none", meter.charged)
+		}
+	})
+
+	t.Run("skips tokens without budgets", func(t *testing.T) {
+		var meter fakeMeter
+
+		w, _ := run(&meter, false, func(ctx context.Context, _ *http.Request) web.Encoder {
+			if err := CheckTokenBudget(ctx, "m"); err != nil {
+				return err
+			}
+			RecordTokenUsage(ctx, 30, 7)
+			return nil
+		})
+
+		if got := w.Header().Get("x-ratelimit-limit-input-tokens"); got != "" || meter.charged != nil {
+			t.Errorf("got limit header %q and charge %v, want none", got, meter.charged)
+		}
+	})
+}
@@ -8,11 +8,16 @@ import (
 	"time"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/authclient"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/batchqueue"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/filestore"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mediafetch"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/mid"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/respstore"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/logger"
 	"github.com/ardanlabs/kronk/cmd/server/foundation/web"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
 	"github.com/ardanlabs/kronk/sdk/pool"
 	buckylibs "github.com/ardanlabs/kronk/sdk/tools/bucky/libs"
 	buckymodels "github.com/ardanlabs/kronk/sdk/tools/bucky/models"
@@ -71,7 +76,12 @@ type Config struct {
 	WebAdminEnabled     bool
 	AdminPasswordSHA256 string
 	Security            *security.Security
+	ResponseStore       respstore.Storer
+	FileStore           *filestore.Store
+	BatchQueue          *batchqueue.Queue
+	MediaFetcher        *mediafetch.Fetcher
 	InferenceTimeout    time.Duration
+	Priorities          map[string]model.Priority
 }
 
 // RouteAdder defines behavior that sets the routes to bind for an instance
@@ -0,0 +1,129 @@
+package respstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+	"github.com/dgraph-io/badger/v4"
+)
+
+// Config holds the configuration for the disk store.
+type Config struct {
+	DBPath string
+	TTL    time.Duration
+}
+
+// Badger stores responses in an embedded badger database so they survive a
+// restart.
+type Badger struct {
+	db  *badger.DB
+	ttl time.Duration
+}
+
+// NewBadger opens the disk store with the specified configuration. Responses
+// expire TTL after they are stored, or never when TTL is zero.
+func NewBadger(cfg Config) (*Badger, error) {
+	opts := badger.DefaultOptions(cfg.DBPath)
+	opts.Logger = nil
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("new: unable to open badger db: %w", err)
+	}
+
+	b := Badger{
+		db:  db,
+		ttl: cfg.TTL,
+	}
+
+	return &b, nil
+}
+
+// Create stores the record under its response ID.
+func (b *Badger) Create(ctx context.Context, rec Record) error {
+	if rec.Response.ID == "" {
+		return fmt.Errorf("create: response id is required")
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("create: marshal: %w", err)
+	}
+
+	entry := badger.NewEntry(recordKey(rec.Response.ID), data)
+	if b.ttl > 0 {
+		entry = entry.WithTTL(b.ttl)
+	}
+
+	if err := b.db.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(entry)
+	}); err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByID returns the record stored for the response ID.
+func (b *Badger) QueryByID(ctx context.Context, id string) (Record, error) {
+	var rec Record
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(recordKey(id))
+		if err != nil {
+			return err
+		}
+
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &rec)
+		})
+	})
+
+	switch {
+	case errors.Is(err, badger.ErrKeyNotFound):
+		return Record{}, fmt.Errorf("query: %s: %w", id, ErrNotFound)
+	case err != nil:
+		return Record{}, fmt.Errorf("query: %s: %w", id, err)
+	}
+
+	// Decoded items hold plain maps; restore the document types the
+	// Responses conversion expects for nested content.
+	for i, item := range rec.Input {
+		rec.Input[i] = model.MapToModelD(item)
+	}
+
+	return rec, nil
+}
+
+// Delete removes the record stored for the response ID.
+func (b *Badger) Delete(ctx context.Context, id string) error {
+	err := b.db.Update(func(txn *badger.Txn) error {
+		if _, err := txn.Get(recordKey(id)); err != nil {
+			return err
+		}
+
+		return txn.Delete(recordKey(id))
+	})
+
+	switch {
+	case errors.Is(err, badger.ErrKeyNotFound):
+		return fmt.Errorf("delete: %s: %w", id, ErrNotFound)
+	case err != nil:
+		return fmt.Errorf("delete: %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// Close closes the underlying database.
+func (b *Badger) Close() error {
+	return b.db.Close()
+}
+
+func recordKey(id string) []byte {
+	return fmt.Appendf(nil, "response:%s", id)
+}
@@ -0,0 +1,105 @@
+package respstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the number of creates between scans for expired records.
+const sweepInterval = 64
+
+type memoryRecord struct {
+	rec       Record
+	expiresAt time.Time
+}
+
+// Memory stores responses in process memory. Stored responses are lost when
+// the process exits.
+type Memory struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	records map[string]memoryRecord
+	creates int
+}
+
+// NewMemory constructs a memory store. Responses expire ttl after they are
+// stored, or never when ttl is zero.
+func NewMemory(ttl time.Duration) *Memory {
+	return &Memory{
+		ttl:     ttl,
+		records: make(map[string]memoryRecord),
+	}
+}
+
+// Create stores the record under its response ID.
+func (m *Memory) Create(ctx context.Context, rec Record) error {
+	if rec.Response.ID == "" {
+		return fmt.Errorf("create: response id is required")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	m.creates++
+	if m.creates%sweepInterval == 0 {
+		for id, mr := range m.records {
+			if mr.expired(now) {
+				delete(m.records, id)
+			}
+		}
+	}
+
+	mr := memoryRecord{rec: rec}
+	if m.ttl > 0 {
+		mr.expiresAt = now.Add(m.ttl)
+	}
+	m.records[rec.Response.ID] = mr
+
+	return nil
+}
+
+// QueryByID returns the record stored for the response ID.
+func (m *Memory) QueryByID(ctx context.Context, id string) (Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mr, exists := m.records[id]
+	if !exists || mr.expired(time.Now()) {
+		return Record{}, fmt.Errorf("query: %s: %w", id, ErrNotFound)
+	}
+
+	return mr.rec, nil
+}
+
+// Delete removes the record stored for the response ID.
+func (m *Memory) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mr, exists := m.records[id]
+	if !exists || mr.expired(time.Now()) {
+		return fmt.Errorf("delete: %s: %w", id, ErrNotFound)
+	}
+
+	delete(m.records, id)
+
+	return nil
+}
+
+// Close releases the stored records.
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clear(m.records)
+
+	return nil
+}
+
+func (mr memoryRecord) expired(now time.Time) bool {
+	return !mr.expiresAt.IsZero() && now.After(mr.expiresAt)
+}
@@ -0,0 +1,30 @@
+// Package respstore provides storage for completed Responses API responses so
+// later requests can continue them with previous_response_id.
+package respstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ardanlabs/kronk/sdk/kronk"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+)
+
+// ErrNotFound is returned when a response is not stored.
+var ErrNotFound = errors.New("response not found")
+
+// Record is a stored response together with the input items of the request
+// that produced it.
+type Record struct {
+	Subject  string                 `json:"subject"`
+	Input    []model.D              `json:"input"`
+	Response kronk.ResponseResponse `json:"response"`
+}
+
+// Storer defines the behavior of a response store.
+type Storer interface {
+	Create(ctx context.Context, rec Record) error
+	QueryByID(ctx context.Context, id string) (Record, error)
+	Delete(ctx context.Context, id string) error
+	Close() error
+}
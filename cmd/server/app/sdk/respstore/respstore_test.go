@@ -0,0 +1,81 @@
+package respstore_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/respstore"
+	"github.com/ardanlabs/kronk/sdk/kronk"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+)
+
+func Test_Store(t *testing.T) {
+	disk, err := respstore.NewBadger(respstore.Config{
+		DBPath: t.TempDir(),
+	})
+
+	if err != nil {
+		t.Fatalf("should be able to construct disk store: %s", err)
+	}
+
+	defer disk.Close()
+
+	t.Run("memory", crud(respstore.NewMemory(0)))
+	t.Run("disk", crud(disk))
+}
+
+func Test_MemoryExpires(t *testing.T) {
+	store := respstore.NewMemory(time.Nanosecond)
+
+	if err := store.Create(t.Context(), respstore.Record{Response: kronk.ResponseResponse{ID: "resp_1"}}); err != nil {
+		t.Fatalf("should be able to create: %s", err)
+	}
+
+	time.Sleep(time.Millisecond)
+
+	if _, err := store.QueryByID(t.Context(), "resp_1"); !errors.Is(err, respstore.ErrNotFound) {
+		t.Errorf("expired query: got %v, want %v", err, respstore.ErrNotFound)
+	}
+}
+
+func crud(store respstore.Storer) func(t *testing.T) {
+	return func(t *testing.T) {
+		rec := respstore.Record{
+			Subject: "user-1",
+			Input: []model.D{
+				{"role": "user", "content": model.DocumentArray(model.D{"type": "input_text", "text": "hello"})},
+			},
+			Response: kronk.ResponseResponse{ID: "resp_1", Object: "response", Status: "completed"},
+		}
+
+		if _, err := store.QueryByID(t.Context(), "resp_1"); !errors.Is(err, respstore.ErrNotFound) {
+			t.Fatalf("missing query: got %v, want %v", err, respstore.ErrNotFound)
+		}
+
+		if err := store.Create(t.Context(), rec); err != nil {
+			t.Fatalf("should be able to create: %s", err)
+		}
+
+		got, err := store.QueryByID(t.Context(), "resp_1")
+		if err != nil {
+			t.Fatalf("should be able to query: %s", err)
+		}
+
+		if got.Subject != rec.Subject || got.Response.Status != rec.Response.Status {
+			t.Errorf("record: got %+v, want %+v", got, rec)
+		}
+
+		if _, ok := got.Input[0]["content"].([]model.D); !ok {
+			t.Errorf("input content: got %T, want []model.D", got.Input[0]["content"])
+		}
+
+		if err := store.Delete(t.Context(), "resp_1"); err != nil {
+			t.Fatalf("should be able to delete: %s", err)
+		}
+
+		if err := store.Delete(t.Context(), "resp_1"); !errors.Is(err, respstore.ErrNotFound) {
+			t.Errorf("second delete: got %v, want %v", err, respstore.ErrNotFound)
+		}
+	}
+}
@@ -164,14 +164,31 @@ func (a *Auth) Authenticate(ctx context.Context, bearerToken string) (Claims, er
 
 // Authorize checks if the claims have the required admin and endpoint permissions.
 func (a *Auth) Authorize(ctx context.Context, claims Claims, requireAdmin bool, endpoint string) error {
+	return a.authorize(ctx, claims, requireAdmin, endpoint, "")
+}
+
+// AuthorizeModel checks if the claims permit requests for the model. Tokens
+// without model patterns may use every model.
+func (a *Auth) AuthorizeModel(ctx context.Context, claims Claims, model string) error {
+	return a.authorize(ctx, claims, false, "", model)
+}
+
+func (a *Auth) authorize(ctx context.Context, claims Claims, requireAdmin bool, endpoint string, model string) error {
+	models := claims.Models
+	if models == nil {
+		models = []string{}
+	}
+
 	input := map[string]any{
 		"Claim": map[string]any{
 			"Admin":     claims.Admin,
 			"Endpoints": claims.Endpoints,
+			"Models":    models,
 		},
 		"Requires": map[string]any{
 			"Admin":    requireAdmin,
 			"Endpoint": endpoint,
+			"Model":    model,
 		},
 	}
 
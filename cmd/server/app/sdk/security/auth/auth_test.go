@@ -231,6 +231,45 @@ func authorize(ath *auth.Auth) func(t *testing.T) {
 				t.Fatalf("admin should be authorized without endpoint permission: %s", err)
 			}
 		})
+
+		// Model tests
+		scopedClaims := userClaims
+		scopedClaims.Models = []string{"Qwen3-8B-Q8_0", "unsloth/*-1.7B-*", "gpt-oss-{20b,mini}", "Qwen2.5*", "*-GGUF"}
+
+		t.Run("user without models may use any model", func(t *testing.T) {
+			if err := ath.AuthorizeModel(ctx, userClaims, "Llama-3.3-70B-Instruct-Q8_0"); err != nil {
+				t.Fatalf("user without models should be authorized: %s", err)
+			}
+		})
+
+		t.Run("scoped user models", func(t *testing.T) {
+			for _, model := range []string{"Qwen3-8B-Q8_0", "unsloth/Qwen3-1.7B-UD-Q8_K_XL", "gpt-oss-20b", "Qwen2.5-7B-Instruct", "bartowski/Llama-3.1-8B-Instruct-GGUF"} {
+				if err := ath.AuthorizeModel(ctx, scopedClaims, model); err != nil {
+					t.Errorf("scoped user should be authorized for %s: %s", model, err)
+				}
+			}
+
+			for _, model := range []string{"Llama-3.3-70B-Instruct-Q8_0", "Qwen3-8B-Q4_K_M", "gpt-oss-120b"} {
+				if err := ath.AuthorizeModel(ctx, scopedClaims, model); !errors.Is(err, auth.ErrForbidden) {
+					t.Errorf("scoped user should not be authorized for %s: %v", model, err)
+				}
+			}
+		})
+
+		t.Run("scoped user endpoint check ignores models", func(t *testing.T) {
+			if err := ath.Authorize(ctx, scopedClaims, false, "chat-completions"); err != nil {
+				t.Fatalf("scoped user should be authorized for chat-completions: %s", err)
+			}
+		})
+
+		t.Run("admin bypasses model restrictions", func(t *testing.T) {
+			scopedAdmin := adminClaims
+			scopedAdmin.Models = []string{"Qwen3-8B-Q8_0"}
+
+			if err := ath.AuthorizeModel(ctx, scopedAdmin, "Llama-3.3-70B-Instruct-Q8_0"); err != nil {
+				t.Fatalf("admin should be authorized for any model: %s", err)
+			}
+		})
 	}
 
 	return f
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
 )
@@ -101,9 +102,99 @@ type RateLimit struct {
 	Window RateWindow `json:"window"`
 }
 
-// Claims represents the authorization claims transmitted via a JWT.
+// TokenBudget caps the input and output tokens a token's requests may use
+// within a Window. An empty Model shares the budget across all models,
+// otherwise it applies only to requests for that model. A zero Input or
+// Output leaves that direction uncapped.
+type TokenBudget struct {
+	Model  string     `json:"model,omitempty"`
+	Input  int64      `json:"input,omitempty"`
+	Output int64      `json:"output,omitempty"`
+	Window RateWindow `json:"window"`
+}
+
+// Applies reports whether the budget covers requests for model.
+func (tb TokenBudget) Applies(model string) bool {
+	return tb.Model == "" || tb.Model == model
+}
+
+// Claims represents the authorization claims transmitted via a JWT. Priority
+// is the highest scheduling priority the token's requests may use and
+// TokenBudgets caps the tokens they may use. Models lists the model IDs or
+// glob patterns the token may use; an empty list allows every model.
 type Claims struct {
 	jwt.RegisteredClaims
-	Admin     bool                 `json:"admin"`
-	Endpoints map[string]RateLimit `json:"endpoints"`
+	Admin        bool                 `json:"admin"`
+	Endpoints    map[string]RateLimit `json:"endpoints"`
+	Priority     string               `json:"priority,omitempty"`
+	TokenBudgets []TokenBudget        `json:"token_budgets,omitempty"`
+	Models       []string             `json:"models,omitempty"`
+}
+
+// =============================================================================
+
+// ValidatePriority checks that priority names a scheduling priority a token
+// can carry: low, normal, or high. An empty priority leaves the choice to the
+// server's per-endpoint configuration.
+func ValidatePriority(priority string) error {
+	switch priority {
+	case "", "low", "normal", "high":
+		return nil
+	}
+
+	return fmt.Errorf("invalid priority %q: must be low, normal, or high", priority)
+}
+
+// ValidateTokenBudgets checks that every budget uses a day, month, or year
+// window, caps at least one direction without negative values, and that no
+// two budgets share a model and window.
+func ValidateTokenBudgets(budgets []TokenBudget) error {
+	type budgetKey struct {
+		model  string
+		window string
+	}
+
+	seen := make(map[budgetKey]struct{}, len(budgets))
+
+	for _, tb := range budgets {
+		switch tb.Window {
+		case RateDay, RateMonth, RateYear:
+		default:
+			return fmt.Errorf("token budget %q: invalid window %q: must be day, month, or year", tb.Model, tb.Window)
+		}
+
+		if tb.Input < 0 || tb.Output < 0 {
+			return fmt.Errorf("token budget %q: input and output must not be negative", tb.Model)
+		}
+
+		if tb.Input == 0 && tb.Output == 0 {
+			return fmt.Errorf("token budget %q: input or output must be set", tb.Model)
+		}
+
+		key := budgetKey{model: tb.Model, window: tb.Window.String()}
+		if _, exists := seen[key]; exists {
+			return fmt.Errorf("token budget %q: duplicate %s window", tb.Model, tb.Window)
+		}
+		seen[key] = struct{}{}
+	}
+
+	return nil
+}
+
+// ValidateModels checks that every model pattern is a non-empty glob: "*"
+// matches any run of characters, including "/" and ".", "?" matches one
+// character, and "[...]" and "{a,b}" match a character class and
+// alternatives.
+func ValidateModels(models []string) error {
+	for _, pattern := range models {
+		if strings.TrimSpace(pattern) != pattern || pattern == "" {
+			return fmt.Errorf("invalid model pattern %q: must be non-empty without surrounding spaces", pattern)
+		}
+
+		if strings.Count(pattern, "[") != strings.Count(pattern, "]") || strings.Count(pattern, "{") != strings.Count(pattern, "}") {
+			return fmt.Errorf("invalid model pattern %q: unbalanced brackets", pattern)
+		}
+	}
+
+	return nil
 }
@@ -12,6 +12,7 @@ auth := {"Authorized": true, "Reason": ""} if {
 auth := {"Authorized": true, "Reason": ""} if {
 	not input.Requires.Admin
 	endpoint_match
+	model_match
 }
 
 auth := {"Authorized": false, "Reason": "admin access required"} if {
@@ -24,6 +25,12 @@ auth := {"Authorized": false, "Reason": sprintf("endpoint %q not authorized", [i
 	not endpoint_match
 }
 
+auth := {"Authorized": false, "Reason": sprintf("model %q not authorized", [input.Requires.Model])} if {
+	not input.Requires.Admin
+	endpoint_match
+	not model_match
+}
+
 endpoint_match if {
 	input.Claim.Admin
 }
@@ -35,3 +42,23 @@ endpoint_match if {
 endpoint_match if {
 	input.Claim.Endpoints[input.Requires.Endpoint]
 }
+
+# A token without model patterns may use every model. Patterns are globs
+# without delimiters: a null delimiter list disables them, while an empty
+# list would fall back to ".", so "*" matches across "/" and "." alike.
+model_match if {
+	input.Claim.Admin
+}
+
+model_match if {
+	input.Requires.Model == ""
+}
+
+model_match if {
+	count(input.Claim.Models) == 0
+}
+
+model_match if {
+	some pattern in input.Claim.Models
+	glob.match(pattern, null, input.Requires.Model)
+}
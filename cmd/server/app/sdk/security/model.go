@@ -1,9 +1,27 @@
 package security
 
-import "time"
+import (
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/rate"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/tokenstore"
+)
 
 // Key represents a key in the system.
 type Key struct {
 	ID      string
 	Created time.Time
 }
+
+// Introspection describes a token and what remains of its rate limits and
+// token budgets in the current windows. Models lists the model patterns the
+// token is limited to, empty when any model is allowed. Active is false for a revoked token,
+// and an invalid or expired token reports nothing else.
+type Introspection struct {
+	Active       bool
+	Token        tokenstore.Token
+	Priority     string
+	Endpoints    []rate.RateUsage
+	TokenBudgets []rate.TokenQuota
+	Models       []string
+}
@@ -43,6 +43,14 @@ func New(cfg Config) (*Limiter, error) {
 	return &l, nil
 }
 
+// NewWithDB creates a rate limiter over an open badger database that the
+// caller owns and closes.
+func NewWithDB(db *badger.DB) *Limiter {
+	return &Limiter{
+		db: db,
+	}
+}
+
 // Close closes the underlying database.
 func (l *Limiter) Close() error {
 	return l.db.Close()
@@ -111,6 +119,54 @@ func (l *Limiter) Check(subject string, endpoint string, limit auth.RateLimit) e
 	return fmt.Errorf("check: unable to update rate limit after %d conflicts: %w", conflictRetries, badger.ErrConflict)
 }
 
+// RateUsage reports the requests a subject made to an endpoint within the
+// current window of its rate limit.
+type RateUsage struct {
+	Endpoint string
+	Limit    auth.RateLimit
+	Used     int
+	Reset    time.Time
+}
+
+// Usage returns the requests counted for the subject and endpoint in the
+// current window without counting a new one. Unlimited endpoints report no
+// usage.
+func (l *Limiter) Usage(subject string, endpoint string, limit auth.RateLimit) (RateUsage, error) {
+	usage := RateUsage{
+		Endpoint: endpoint,
+		Limit:    limit,
+	}
+
+	if limit.Window == auth.RateUnlimited {
+		return usage, nil
+	}
+
+	windowStart, windowEnd := windowBounds(limit.Window, time.Now().UTC())
+	key := fmt.Appendf(nil, "rate:%s:%s:%d", subject, endpoint, windowStart.Unix())
+	usage.Reset = windowEnd
+
+	view := func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		switch {
+		case errors.Is(err, badger.ErrKeyNotFound):
+			return nil
+		case err != nil:
+			return err
+		}
+
+		return item.Value(func(val []byte) error {
+			usage.Used = int(binary.BigEndian.Uint64(val))
+			return nil
+		})
+	}
+
+	if err := l.db.View(view); err != nil {
+		return RateUsage{}, fmt.Errorf("usage: unable to read rate limit: %w", err)
+	}
+
+	return usage, nil
+}
+
 func windowBounds(window auth.RateWindow, now time.Time) (time.Time, time.Time) {
 	switch window {
 	case auth.RateDay:
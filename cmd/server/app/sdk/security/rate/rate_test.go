@@ -5,6 +5,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/rate"
@@ -164,3 +165,75 @@ func year(limiter *rate.Limiter) func(t *testing.T) {
 		}
 	}
 }
+
+func Test_TokenBudgets(t *testing.T) {
+	limiter, err := rate.New(rate.Config{
+		DBPath: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("should be able to construct rate limiter: %s", err)
+	}
+	defer limiter.Close()
+
+	budgets := []auth.TokenBudget{
+		{Input: 1000, Window: auth.RateDay},
+		{Model: "Qwen3-8B-Q8_0", Output: 100, Window: auth.RateMonth},
+	}
+
+	quotas, err := limiter.CheckTokens("user-tokens", "Qwen3-8B-Q8_0", budgets)
+	if err != nil {
+		t.Fatalf("should not exceed unused budgets: %s", err)
+	}
+	if len(quotas) != 2 {
+		t.Fatalf("quotas: got %d, want 2", len(quotas))
+	}
+
+	if _, err := limiter.ChargeTokens("user-tokens", "Qwen3-8B-Q8_0", budgets, 400, 60); err != nil {
+		t.Fatalf("should be able to charge tokens: %s", err)
+	}
+
+	quotas, err = limiter.CheckTokens("user-tokens", "Qwen3-8B-Q8_0", budgets)
+	if err != nil {
+		t.Fatalf("should not exceed partly used budgets: %s", err)
+	}
+	if got := quotas[0].InputRemaining(); got != 600 {
+		t.Errorf("input remaining: got %d, want 600", got)
+	}
+	if got := quotas[1].OutputRemaining(); got != 40 {
+		t.Errorf("output remaining: got %d, want 40", got)
+	}
+
+	if _, err := limiter.ChargeTokens("user-tokens", "Qwen3-8B-Q8_0", budgets, 100, 60); err != nil {
+		t.Fatalf("should be able to charge past a limit: %s", err)
+	}
+
+	quotas, err = limiter.CheckTokens("user-tokens", "Qwen3-8B-Q8_0", budgets)
+	if !errors.Is(err, rate.ErrTokenBudgetExceeded) {
+		t.Fatalf("should return ErrTokenBudgetExceeded: %v", err)
+	}
+	if !quotas[1].Exhausted() || quotas[0].Exhausted() {
+		t.Errorf("exhausted: got %t/%t, want false/true", quotas[0].Exhausted(), quotas[1].Exhausted())
+	}
+
+	quotas, err = limiter.CheckTokens("user-tokens", "gpt-oss-20b", budgets)
+	if err != nil {
+		t.Fatalf("should not apply a model budget to another model: %s", err)
+	}
+	if len(quotas) != 1 || quotas[0].InputUsed != 500 {
+		t.Errorf("other model quotas: got %+v, want shared budget with 500 input used", quotas)
+	}
+
+	quotas, err = limiter.TokenQuotas("user-tokens")
+	if err != nil {
+		t.Fatalf("should be able to list quotas: %s", err)
+	}
+	if len(quotas) != 2 {
+		t.Fatalf("listed quotas: got %d, want 2", len(quotas))
+	}
+	if quotas[1].Model != "Qwen3-8B-Q8_0" || quotas[1].OutputLimit != 100 || quotas[1].OutputUsed != 120 {
+		t.Errorf("listed model quota: got %+v", quotas[1])
+	}
+	if !quotas[0].Reset.After(time.Now()) {
+		t.Errorf("reset: got %s, want a time in the future", quotas[0].Reset)
+	}
+}
@@ -0,0 +1,301 @@
+package rate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
+	"github.com/dgraph-io/badger/v4"
+)
+
+// ErrTokenBudgetExceeded is returned when a token budget has been used up.
+var ErrTokenBudgetExceeded = errors.New("token budget exceeded")
+
+// TokenQuota reports the use of a token budget within its current window.
+// A zero limit leaves that direction uncapped.
+type TokenQuota struct {
+	Model       string
+	Window      auth.RateWindow
+	InputLimit  int64
+	InputUsed   int64
+	OutputLimit int64
+	OutputUsed  int64
+	Reset       time.Time
+}
+
+// InputRemaining returns the input tokens left in the window.
+func (q TokenQuota) InputRemaining() int64 {
+	return max(q.InputLimit-q.InputUsed, 0)
+}
+
+// OutputRemaining returns the output tokens left in the window.
+func (q TokenQuota) OutputRemaining() int64 {
+	return max(q.OutputLimit-q.OutputUsed, 0)
+}
+
+// Exhausted reports whether a capped direction of the budget is used up.
+func (q TokenQuota) Exhausted() bool {
+	return (q.InputLimit > 0 && q.InputUsed >= q.InputLimit) ||
+		(q.OutputLimit > 0 && q.OutputUsed >= q.OutputLimit)
+}
+
+// =============================================================================
+
+// CheckTokens returns the quotas of the budgets that apply to requests for
+// model. It returns ErrTokenBudgetExceeded along with the quotas when any of
+// them is exhausted.
+func (l *Limiter) CheckTokens(subject string, model string, budgets []auth.TokenBudget) ([]TokenQuota, error) {
+	applies := slices.DeleteFunc(slices.Clone(budgets), func(tb auth.TokenBudget) bool {
+		return !tb.Applies(model)
+	})
+
+	quotas, err := l.BudgetQuotas(subject, applies)
+	if err != nil {
+		return nil, fmt.Errorf("check-tokens: %w", err)
+	}
+
+	if slices.ContainsFunc(quotas, TokenQuota.Exhausted) {
+		return quotas, ErrTokenBudgetExceeded
+	}
+
+	return quotas, nil
+}
+
+// BudgetQuotas returns the quotas of every budget in the current window,
+// including budgets with no usage yet.
+func (l *Limiter) BudgetQuotas(subject string, budgets []auth.TokenBudget) ([]TokenQuota, error) {
+	now := time.Now().UTC()
+
+	var quotas []TokenQuota
+
+	view := func(txn *badger.Txn) error {
+		for _, tb := range budgets {
+			key, quota := tokenKey(subject, tb, now)
+
+			if err := readQuota(txn, key, &quota); err != nil {
+				return err
+			}
+
+			quotas = append(quotas, quota)
+		}
+
+		return nil
+	}
+
+	if err := l.db.View(view); err != nil {
+		return nil, fmt.Errorf("budget-quotas: unable to read token budgets: %w", err)
+	}
+
+	return quotas, nil
+}
+
+// ChargeTokens adds the input and output tokens of a completed request for
+// model to the budgets that apply to it and returns their updated quotas.
+// Usage is charged even when it runs past a limit, since the tokens were
+// already generated; the next CheckTokens call then rejects the subject.
+func (l *Limiter) ChargeTokens(subject string, model string, budgets []auth.TokenBudget, input int64, output int64) ([]TokenQuota, error) {
+	now := time.Now().UTC()
+
+	var quotas []TokenQuota
+
+	update := func(txn *badger.Txn) error {
+		quotas = quotas[:0]
+
+		for _, tb := range budgets {
+			if !tb.Applies(model) {
+				continue
+			}
+
+			key, quota := tokenKey(subject, tb, now)
+
+			if err := readQuota(txn, key, &quota); err != nil {
+				return err
+			}
+
+			quota.InputLimit = tb.Input
+			quota.OutputLimit = tb.Output
+			quota.InputUsed += max(input, 0)
+			quota.OutputUsed += max(output, 0)
+
+			entry := badger.NewEntry(key, encodeQuota(quota))
+			entry.ExpiresAt = uint64(quota.Reset.Unix())
+			if err := txn.SetEntry(entry); err != nil {
+				return err
+			}
+
+			quotas = append(quotas, quota)
+		}
+
+		return nil
+	}
+
+	for range conflictRetries {
+		err := l.db.Update(update)
+		switch {
+		case err == nil:
+			return quotas, nil
+		case !errors.Is(err, badger.ErrConflict):
+			return nil, fmt.Errorf("charge-tokens: unable to update token budgets: %w", err)
+		}
+	}
+
+	return nil, fmt.Errorf("charge-tokens: unable to update token budgets after %d conflicts: %w", conflictRetries, badger.ErrConflict)
+}
+
+// TokenQuotas returns the quotas recorded for subject in their current
+// windows, ordered by model and window. Budgets with no usage in their
+// current window are not recorded and so are not returned.
+func (l *Limiter) TokenQuotas(subject string) ([]TokenQuota, error) {
+	prefix := fmt.Appendf(nil, "tokens:%s:", subject)
+	now := time.Now().UTC()
+
+	var quotas []TokenQuota
+
+	view := func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+
+			quota, ok := parseTokenKey(bytes.TrimPrefix(item.Key(), prefix))
+			if !ok || !quota.Reset.After(now) {
+				continue
+			}
+
+			err := item.Value(func(val []byte) error {
+				return decodeQuota(val, &quota)
+			})
+			if err != nil {
+				return err
+			}
+
+			quotas = append(quotas, quota)
+		}
+
+		return nil
+	}
+
+	if err := l.db.View(view); err != nil {
+		return nil, fmt.Errorf("token-quotas: unable to read token budgets: %w", err)
+	}
+
+	slices.SortFunc(quotas, func(a, b TokenQuota) int {
+		if c := strings.Compare(a.Model, b.Model); c != 0 {
+			return c
+		}
+		return a.Reset.Compare(b.Reset)
+	})
+
+	return quotas, nil
+}
+
+// =============================================================================
+
+// tokenKey returns the database key of budget tb for the window containing
+// now, along with an unused quota for that window. The model is the last
+// segment of the key since model IDs may contain colons.
+func tokenKey(subject string, tb auth.TokenBudget, now time.Time) ([]byte, TokenQuota) {
+	windowStart, windowEnd := windowBounds(tb.Window, now)
+	key := fmt.Appendf(nil, "tokens:%s:%s:%d:%s", subject, tb.Window, windowStart.Unix(), tb.Model)
+
+	quota := TokenQuota{
+		Model:       tb.Model,
+		Window:      tb.Window,
+		InputLimit:  tb.Input,
+		OutputLimit: tb.Output,
+		Reset:       windowEnd,
+	}
+
+	return key, quota
+}
+
+// parseTokenKey parses the window and model segments of a token key with the
+// subject prefix removed.
+func parseTokenKey(key []byte) (TokenQuota, bool) {
+	parts := strings.SplitN(string(key), ":", 3)
+	if len(parts) != 3 {
+		return TokenQuota{}, false
+	}
+
+	window, err := auth.ParseRateWindow(parts[0])
+	if err != nil {
+		return TokenQuota{}, false
+	}
+
+	start, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return TokenQuota{}, false
+	}
+
+	_, windowEnd := windowBounds(window, time.Unix(start, 0).UTC())
+
+	quota := TokenQuota{
+		Model:  parts[2],
+		Window: window,
+		Reset:  windowEnd,
+	}
+
+	return quota, true
+}
+
+// readQuota loads the recorded usage of key into quota, keeping the limits
+// of the budget. A missing key leaves quota unused.
+func readQuota(txn *badger.Txn, key []byte, quota *TokenQuota) error {
+	item, err := txn.Get(key)
+	switch {
+	case errors.Is(err, badger.ErrKeyNotFound):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	inLimit, outLimit := quota.InputLimit, quota.OutputLimit
+
+	err = item.Value(func(val []byte) error {
+		return decodeQuota(val, quota)
+	})
+	if err != nil {
+		return err
+	}
+
+	quota.InputLimit, quota.OutputLimit = inLimit, outLimit
+
+	return nil
+}
+
+// The value of a token key holds the input limit, input used, output limit,
+// and output used as big-endian 64-bit integers.
+const quotaValueSize = 32
+
+func encodeQuota(quota TokenQuota) []byte {
+	val := make([]byte, quotaValueSize)
+	binary.BigEndian.PutUint64(val[0:], uint64(quota.InputLimit))
+	binary.BigEndian.PutUint64(val[8:], uint64(quota.InputUsed))
+	binary.BigEndian.PutUint64(val[16:], uint64(quota.OutputLimit))
+	binary.BigEndian.PutUint64(val[24:], uint64(quota.OutputUsed))
+
+	return val
+}
+
+func decodeQuota(val []byte, quota *TokenQuota) error {
+	if len(val) != quotaValueSize {
+		return fmt.Errorf("invalid token quota value size %d", len(val))
+	}
+
+	quota.InputLimit = int64(binary.BigEndian.Uint64(val[0:]))
+	quota.InputUsed = int64(binary.BigEndian.Uint64(val[8:]))
+	quota.OutputLimit = int64(binary.BigEndian.Uint64(val[16:]))
+	quota.OutputUsed = int64(binary.BigEndian.Uint64(val[24:]))
+
+	return nil
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,7 +17,9 @@ import (
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/auth"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/keystore"
 	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/rate"
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/tokenstore"
 	"github.com/ardanlabs/kronk/sdk/tools/defaults"
+	"github.com/dgraph-io/badger/v4"
 	"github.com/golang-jwt/jwt/v4"
 )
 
@@ -27,6 +31,10 @@ var (
 // ErrUnauthenticated is returned when bearer token authentication fails.
 var ErrUnauthenticated = errors.New("authentication failed")
 
+// ErrTokenRevoked is returned when a bearer token has been revoked. It also
+// matches ErrUnauthenticated.
+var ErrTokenRevoked = fmt.Errorf("%w: token revoked", ErrUnauthenticated)
+
 // Config represents the config needed to construct the security API.
 type Config struct {
 	OverrideBaseKeysFolder string
@@ -36,7 +44,9 @@ type Config struct {
 // Security provides security support APIs.
 type Security struct {
 	auth    *auth.Auth
+	db      *badger.DB
 	limiter *rate.Limiter
+	tokens  *tokenstore.Store
 	cfg     Config
 	ks      *keystore.KeyStore
 }
@@ -59,24 +69,28 @@ func New(cfg Config) (*Security, error) {
 	basePath := defaults.BaseDir(cfg.OverrideBaseKeysFolder)
 	dbPath := filepath.Join(basePath, "badger")
 
-	limiter, err := rate.New(rate.Config{
-		DBPath: dbPath,
-	})
+	// The rate limiter and token store share one database.
+	opts := badger.DefaultOptions(dbPath)
+	opts.Logger = nil
 
+	db, err := badger.Open(opts)
 	if err != nil {
-		return nil, fmt.Errorf("new: rate limiter: %w", err)
+		return nil, fmt.Errorf("new: unable to open badger db: %w", err)
 	}
 
 	// -------------------------------------------------------------------------
 
 	sec := Security{
 		auth:    a,
-		limiter: limiter,
+		db:      db,
+		limiter: rate.NewWithDB(db),
+		tokens:  tokenstore.New(db),
 		cfg:     cfg,
 		ks:      ks,
 	}
 
 	if err := sec.addSystemKeys(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("new: unable to add system keys: %w", err)
 	}
 
@@ -85,7 +99,7 @@ func New(cfg Config) (*Security, error) {
 
 // Close shutdown the security system.
 func (sec *Security) Close() error {
-	return sec.limiter.Close()
+	return sec.db.Close()
 }
 
 // BaseKeysFolder returns the location of the base keys folder being used.
@@ -95,13 +109,9 @@ func (sec *Security) BaseKeysFolder() string {
 
 // Authenticate tests the token against the requirements.
 func (sec *Security) Authenticate(ctx context.Context, bearerToken string, admin bool, endpoint string) (auth.Claims, error) {
-	claims, err := sec.auth.Authenticate(ctx, bearerToken)
+	claims, err := sec.tokenClaims(ctx, bearerToken)
 	if err != nil {
-		if errors.Is(err, auth.ErrInvalidToken) {
-			return auth.Claims{}, fmt.Errorf("%w: invalid token: %w", ErrUnauthenticated, err)
-		}
-
-		return auth.Claims{}, fmt.Errorf("token authentication failed: %w", err)
+		return auth.Claims{}, err
 	}
 
 	err = sec.auth.Authorize(ctx, claims, admin, endpoint)
@@ -130,15 +140,295 @@ func (sec *Security) Authenticate(ctx context.Context, bearerToken string, admin
 	return claims, nil
 }
 
+// AuthorizeModel checks that the claims of an authenticated token permit
+// requests for the model.
+func (sec *Security) AuthorizeModel(ctx context.Context, claims auth.Claims, model string) error {
+	if err := sec.auth.AuthorizeModel(ctx, claims, model); err != nil {
+		if errors.Is(err, auth.ErrForbidden) {
+			return fmt.Errorf("not authorized: %w", err)
+		}
+
+		return fmt.Errorf("authorization failed: %w", err)
+	}
+
+	return nil
+}
+
+// AuthorizedModels returns the models in the list the bearer token may use,
+// in their original order.
+func (sec *Security) AuthorizedModels(ctx context.Context, bearerToken string, models []string) ([]string, error) {
+	claims, err := sec.tokenClaims(ctx, bearerToken)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make([]string, 0, len(models))
+	for _, model := range models {
+		err := sec.auth.AuthorizeModel(ctx, claims, model)
+		switch {
+		case err == nil:
+			allowed = append(allowed, model)
+		case !errors.Is(err, auth.ErrForbidden):
+			return nil, fmt.Errorf("authorization failed: %w", err)
+		}
+	}
+
+	return allowed, nil
+}
+
+// CheckTokens returns the quotas of the token budgets that apply to requests
+// for model. It returns rate.ErrTokenBudgetExceeded along with the quotas
+// when any of them is exhausted.
+func (sec *Security) CheckTokens(ctx context.Context, bearerToken string, model string) ([]rate.TokenQuota, error) {
+	claims, err := sec.tokenClaims(ctx, bearerToken)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas, err := sec.limiter.CheckTokens(claims.Subject, model, claims.TokenBudgets)
+	if err != nil {
+		if errors.Is(err, rate.ErrTokenBudgetExceeded) {
+			return quotas, fmt.Errorf("token budget exceeded: %w", err)
+		}
+
+		return nil, fmt.Errorf("token budget check failed: %w", err)
+	}
+
+	return quotas, nil
+}
+
+// ChargeTokens charges the input and output tokens of a completed request for
+// model to the token budgets that apply to it.
+func (sec *Security) ChargeTokens(ctx context.Context, bearerToken string, model string, input int64, output int64) ([]rate.TokenQuota, error) {
+	claims, err := sec.tokenClaims(ctx, bearerToken)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas, err := sec.limiter.ChargeTokens(claims.Subject, model, claims.TokenBudgets, input, output)
+	if err != nil {
+		return nil, fmt.Errorf("token budget charge failed: %w", err)
+	}
+
+	return quotas, nil
+}
+
+// TokenQuotas returns the token budget use recorded for subject in the
+// current windows.
+func (sec *Security) TokenQuotas(subject string) ([]rate.TokenQuota, error) {
+	return sec.limiter.TokenQuotas(subject)
+}
+
+// tokenClaims verifies the bearer token and rejects it when revoked.
+func (sec *Security) tokenClaims(ctx context.Context, bearerToken string) (auth.Claims, error) {
+	claims, err := sec.verifyToken(ctx, bearerToken)
+	if err != nil {
+		return auth.Claims{}, err
+	}
+
+	if claims.ID != "" {
+		revoked, err := sec.tokens.IsRevoked(claims.ID)
+		if err != nil {
+			return auth.Claims{}, fmt.Errorf("token revocation check failed: %w", err)
+		}
+
+		if revoked {
+			return auth.Claims{}, ErrTokenRevoked
+		}
+	}
+
+	return claims, nil
+}
+
+func (sec *Security) verifyToken(ctx context.Context, bearerToken string) (auth.Claims, error) {
+	claims, err := sec.auth.Authenticate(ctx, bearerToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return auth.Claims{}, fmt.Errorf("%w: invalid token: %w", ErrUnauthenticated, err)
+		}
+
+		return auth.Claims{}, fmt.Errorf("token authentication failed: %w", err)
+	}
+
+	return claims, nil
+}
+
+// RevokeToken revokes a single token so it no longer authenticates, without
+// affecting other tokens signed by the same key. The token is identified by
+// its ID, the jti claim, or by the token itself, which must still verify and
+// also revokes tokens this server did not record. Tokens issued without an ID
+// can only be revoked by deleting their signing key.
+func (sec *Security) RevokeToken(ctx context.Context, id string, token string) (tokenstore.Token, error) {
+	var fallback tokenstore.Token
+
+	if token != "" {
+		claims, err := sec.verifyToken(ctx, "Bearer "+token)
+		if err != nil {
+			return tokenstore.Token{}, fmt.Errorf("revoke-token: %w", err)
+		}
+
+		if claims.ID == "" {
+			return tokenstore.Token{}, fmt.Errorf("revoke-token: %w: token has no id", tokenstore.ErrNotFound)
+		}
+
+		if id != "" && id != claims.ID {
+			return tokenstore.Token{}, fmt.Errorf("revoke-token: id %q does not match the token", id)
+		}
+
+		id = claims.ID
+		fallback = toStoreToken(claims)
+	}
+
+	if id == "" {
+		return tokenstore.Token{}, errors.New("revoke-token: missing token id")
+	}
+
+	revoked, err := sec.tokens.Revoke(id, fallback)
+	if err != nil {
+		return tokenstore.Token{}, fmt.Errorf("revoke-token: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// ListTokens returns the tokens this server issued or revoked that have not
+// yet expired, newest first.
+func (sec *Security) ListTokens() ([]tokenstore.Token, error) {
+	return sec.tokens.List()
+}
+
+// IntrospectToken reports the claims of a token, whether it is revoked, and
+// what remains of its rate limits and token budgets. Usage is read without
+// counting a request.
+func (sec *Security) IntrospectToken(ctx context.Context, token string) (Introspection, error) {
+	claims, err := sec.verifyToken(ctx, "Bearer "+token)
+	if err != nil {
+		if errors.Is(err, ErrUnauthenticated) {
+			return Introspection{}, nil
+		}
+
+		return Introspection{}, fmt.Errorf("introspect-token: %w", err)
+	}
+
+	info := toStoreToken(claims)
+	if claims.ID != "" {
+		recorded, err := sec.tokens.Query(claims.ID)
+		switch {
+		case err == nil:
+			info.RevokedAt = recorded.RevokedAt
+		case !errors.Is(err, tokenstore.ErrNotFound):
+			return Introspection{}, fmt.Errorf("introspect-token: %w", err)
+		}
+	}
+
+	endpoints := make([]rate.RateUsage, 0, len(claims.Endpoints))
+	if !claims.Admin {
+		for _, name := range slices.Sorted(maps.Keys(claims.Endpoints)) {
+			usage, err := sec.limiter.Usage(claims.Subject, name, claims.Endpoints[name])
+			if err != nil {
+				return Introspection{}, fmt.Errorf("introspect-token: %w", err)
+			}
+
+			endpoints = append(endpoints, usage)
+		}
+	}
+
+	quotas, err := sec.limiter.BudgetQuotas(claims.Subject, claims.TokenBudgets)
+	if err != nil {
+		return Introspection{}, fmt.Errorf("introspect-token: %w", err)
+	}
+
+	in := Introspection{
+		Active:       !info.Revoked(),
+		Token:        info,
+		Priority:     claims.Priority,
+		Endpoints:    endpoints,
+		TokenBudgets: quotas,
+		Models:       claims.Models,
+	}
+
+	return in, nil
+}
+
+func toStoreToken(claims auth.Claims) tokenstore.Token {
+	token := tokenstore.Token{
+		ID:      claims.ID,
+		Subject: claims.Subject,
+		Admin:   claims.Admin,
+	}
+
+	if claims.IssuedAt != nil {
+		token.IssuedAt = claims.IssuedAt.UTC()
+	}
+
+	if claims.ExpiresAt != nil {
+		token.ExpiresAt = claims.ExpiresAt.UTC()
+	}
+
+	return token
+}
+
+// TokenOptions represent optional claims of a generated token.
+type TokenOptions struct {
+	priority     string
+	tokenBudgets []auth.TokenBudget
+	models       []string
+}
+
+// WithPriority sets the highest scheduling priority the token's requests may
+// use: low, normal, or high.
+func WithPriority(priority string) func(opts *TokenOptions) {
+	return func(opts *TokenOptions) {
+		opts.priority = priority
+	}
+}
+
+// WithTokenBudgets caps the input and output tokens the token's requests may
+// use per window.
+func WithTokenBudgets(budgets []auth.TokenBudget) func(opts *TokenOptions) {
+	return func(opts *TokenOptions) {
+		opts.tokenBudgets = budgets
+	}
+}
+
+// WithModels limits the token to the model IDs or glob patterns. Without it
+// the token may use every model.
+func WithModels(models []string) func(opts *TokenOptions) {
+	return func(opts *TokenOptions) {
+		opts.models = models
+	}
+}
+
 // GenerateToken generates a new token with the specified claims.
-func (sec *Security) GenerateToken(admin bool, endpoints map[string]auth.RateLimit, duration time.Duration) (string, error) {
+func (sec *Security) GenerateToken(admin bool, endpoints map[string]auth.RateLimit, duration time.Duration, options ...func(opts *TokenOptions)) (string, error) {
+	var opts TokenOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	if err := auth.ValidatePriority(opts.priority); err != nil {
+		return "", fmt.Errorf("generate-token: %w", err)
+	}
+
+	if err := auth.ValidateTokenBudgets(opts.tokenBudgets); err != nil {
+		return "", fmt.Errorf("generate-token: %w", err)
+	}
+
+	if err := auth.ValidateModels(opts.models); err != nil {
+		return "", fmt.Errorf("generate-token: %w", err)
+	}
+
 	claims := auth.Claims{
-		Issuer:    sec.cfg.Issuer,
-		Subject:   uuid.New().String(),
-		ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(duration)),
-		IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
-		Admin:     admin,
-		Endpoints: endpoints,
+		ID:           uuid.New().String(),
+		Issuer:       sec.cfg.Issuer,
+		Subject:      uuid.New().String(),
+		ExpiresAt:    jwt.NewNumericDate(time.Now().UTC().Add(duration)),
+		IssuedAt:     jwt.NewNumericDate(time.Now().UTC()),
+		Admin:        admin,
+		Endpoints:    endpoints,
+		Priority:     opts.priority,
+		TokenBudgets: opts.tokenBudgets,
+		Models:       opts.models,
 	}
 
 	token, err := sec.auth.GenerateToken(claims)
@@ -146,6 +436,10 @@ func (sec *Security) GenerateToken(admin bool, endpoints map[string]auth.RateLim
 		return "", fmt.Errorf("generate-token: unable to generate token: %w", err)
 	}
 
+	if err := sec.tokens.Add(toStoreToken(claims)); err != nil {
+		return "", fmt.Errorf("generate-token: %w", err)
+	}
+
 	return token, nil
 }
 
@@ -306,8 +600,13 @@ func (sec *Security) generateAdminToken(keysPath string) error {
 		"rerank":           {Limit: 0, Window: auth.RateUnlimited},
 		"responses":        {Limit: 0, Window: auth.RateUnlimited},
 		"transcriptions":   {Limit: 0, Window: auth.RateUnlimited},
+		"translations":     {Limit: 0, Window: auth.RateUnlimited},
 		"messages":         {Limit: 0, Window: auth.RateUnlimited},
 		"tokenize":         {Limit: 0, Window: auth.RateUnlimited},
+		"images":           {Limit: 0, Window: auth.RateUnlimited},
+		"completions":      {Limit: 0, Window: auth.RateUnlimited},
+		"files":            {Limit: 0, Window: auth.RateUnlimited},
+		"batches":          {Limit: 0, Window: auth.RateUnlimited},
 	}
 
 	const tenYears = 10 * 365 * 24 * time.Hour
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -65,6 +66,40 @@ func TestAuthenticateWithoutEndpointRestriction(t *testing.T) {
 	}
 }
 
+func TestGenerateTokenPriority(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	sec, err := security.New(security.Config{
+		OverrideBaseKeysFolder: tmpDir,
+		Issuer:                 "test-issuer",
+	})
+	if err != nil {
+		t.Fatalf("failed to create security: %v", err)
+	}
+	defer sec.Close()
+
+	endpoints := map[string]auth.RateLimit{
+		"chat-completions": {Limit: 0, Window: auth.RateUnlimited},
+	}
+
+	token, err := sec.GenerateToken(false, endpoints, time.Hour, security.WithPriority("low"))
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	claims, err := sec.Authenticate(context.Background(), "Bearer "+token, false, "chat-completions")
+	if err != nil {
+		t.Fatalf("failed to authenticate: %v", err)
+	}
+	if claims.Priority != "low" {
+		t.Errorf("priority: got %q, want %q", claims.Priority, "low")
+	}
+
+	if _, err := sec.GenerateToken(false, endpoints, time.Hour, security.WithPriority("urgent")); err == nil {
+		t.Error("expected an invalid priority to be rejected")
+	}
+}
+
 func TestAuthenticateClassifiesInternalFailure(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -292,3 +327,153 @@ func findNonMasterKey(t *testing.T, keysPath string) string {
 
 	return ""
 }
+
+func TestRevokeToken(t *testing.T) {
+	sec, err := security.New(security.Config{
+		OverrideBaseKeysFolder: t.TempDir(),
+		Issuer:                 "test-issuer",
+	})
+	if err != nil {
+		t.Fatalf("failed to create security: %v", err)
+	}
+	defer sec.Close()
+
+	endpoints := map[string]auth.RateLimit{
+		"chat-completions": {Limit: 10, Window: auth.RateDay},
+	}
+
+	revokedToken, err := sec.GenerateToken(false, endpoints, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	keptToken, err := sec.GenerateToken(false, endpoints, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if _, err := sec.Authenticate(ctx, "Bearer "+revokedToken, false, "chat-completions"); err != nil {
+		t.Fatalf("should authenticate before revocation: %v", err)
+	}
+
+	in, err := sec.IntrospectToken(ctx, revokedToken)
+	if err != nil {
+		t.Fatalf("failed to introspect token: %v", err)
+	}
+	if !in.Active || in.Token.ID == "" {
+		t.Fatalf("introspection: got active %t id %q, want an active token with an id", in.Active, in.Token.ID)
+	}
+	if len(in.Endpoints) != 1 || in.Endpoints[0].Used != 1 {
+		t.Errorf("endpoint usage: got %+v, want 1 request used", in.Endpoints)
+	}
+
+	revoked, err := sec.RevokeToken(ctx, in.Token.ID, "")
+	if err != nil {
+		t.Fatalf("failed to revoke token: %v", err)
+	}
+	if !revoked.Revoked() {
+		t.Error("revoked token should report its revocation time")
+	}
+
+	_, err = sec.Authenticate(ctx, "Bearer "+revokedToken, false, "chat-completions")
+	if !errors.Is(err, security.ErrTokenRevoked) || !errors.Is(err, security.ErrUnauthenticated) {
+		t.Errorf("revoked token: got %v, want ErrTokenRevoked", err)
+	}
+
+	if _, err := sec.Authenticate(ctx, "Bearer "+keptToken, false, "chat-completions"); err != nil {
+		t.Errorf("other tokens should still authenticate: %v", err)
+	}
+
+	in, err = sec.IntrospectToken(ctx, revokedToken)
+	if err != nil {
+		t.Fatalf("failed to introspect token: %v", err)
+	}
+	if in.Active || !in.Token.Revoked() {
+		t.Errorf("introspection: got active %t revoked %t, want inactive and revoked", in.Active, in.Token.Revoked())
+	}
+
+	tokens, err := sec.ListTokens()
+	if err != nil {
+		t.Fatalf("failed to list tokens: %v", err)
+	}
+
+	var listed int
+	for _, tok := range tokens {
+		if tok.Revoked() {
+			listed++
+		}
+	}
+	if listed != 1 {
+		t.Errorf("revoked tokens listed: got %d, want 1", listed)
+	}
+
+	if _, err := sec.RevokeToken(ctx, "unknown", ""); err == nil {
+		t.Error("revoking an unknown id should fail")
+	}
+}
+
+func TestAuthorizedModels(t *testing.T) {
+	sec, err := security.New(security.Config{
+		OverrideBaseKeysFolder: t.TempDir(),
+		Issuer:                 "test-issuer",
+	})
+	if err != nil {
+		t.Fatalf("failed to create security: %v", err)
+	}
+	defer sec.Close()
+
+	endpoints := map[string]auth.RateLimit{
+		"chat-completions": {Limit: 10, Window: auth.RateDay},
+	}
+
+	if _, err := sec.GenerateToken(false, endpoints, time.Hour, security.WithModels([]string{""})); err == nil {
+		t.Fatal("expected an empty model pattern to be rejected")
+	}
+
+	token, err := sec.GenerateToken(false, endpoints, time.Hour, security.WithModels([]string{"*Qwen3-0.6B*", "*Qwen3-8B*"}))
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	ctx := context.Background()
+
+	claims, err := sec.Authenticate(ctx, "Bearer "+token, false, "chat-completions")
+	if err != nil {
+		t.Fatalf("failed to authenticate: %v", err)
+	}
+
+	if err := sec.AuthorizeModel(ctx, claims, "unsloth/Qwen3-8B-Q8_0"); err != nil {
+		t.Errorf("should authorize a matching model: %v", err)
+	}
+
+	if err := sec.AuthorizeModel(ctx, claims, "Llama-3.3-70B-Instruct-Q4_K_M"); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("AuthorizeModel: got %v, want %v", err, auth.ErrForbidden)
+	}
+
+	models := []string{"unsloth/Llama-3.3-70B-Instruct-Q4_K_M", "unsloth/Qwen3-8B-Q8_0", "Qwen3-0.6B-Q8_0"}
+
+	allowed, err := sec.AuthorizedModels(ctx, "Bearer "+token, models)
+	if err != nil {
+		t.Fatalf("failed to filter models: %v", err)
+	}
+
+	want := []string{"unsloth/Qwen3-8B-Q8_0", "Qwen3-0.6B-Q8_0"}
+	if !slices.Equal(allowed, want) {
+		t.Errorf("AuthorizedModels: got %v, want %v", allowed, want)
+	}
+
+	unscoped, err := sec.GenerateToken(false, endpoints, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	allowed, err = sec.AuthorizedModels(ctx, "Bearer "+unscoped, models)
+	if err != nil {
+		t.Fatalf("failed to filter models: %v", err)
+	}
+	if !slices.Equal(allowed, models) {
+		t.Errorf("AuthorizedModels unscoped: got %v, want %v", allowed, models)
+	}
+}
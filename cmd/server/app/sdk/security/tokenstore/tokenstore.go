@@ -0,0 +1,216 @@
+// Package tokenstore records issued tokens and their revocation using an
+// embedded database.
+package tokenstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// ErrNotFound is returned when no token is recorded under an ID.
+var ErrNotFound = errors.New("token not found")
+
+const conflictRetries = 32
+
+// Token describes an issued token by its ID, the JWT jti claim. Tokens are
+// only recorded until they expire, after which they no longer authenticate.
+type Token struct {
+	ID        string    `json:"id"`
+	Subject   string    `json:"subject"`
+	Admin     bool      `json:"admin"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	RevokedAt time.Time `json:"revoked_at,omitzero"`
+}
+
+// Revoked reports whether the token has been revoked.
+func (t Token) Revoked() bool {
+	return !t.RevokedAt.IsZero()
+}
+
+// Store records issued and revoked tokens in a badger database it shares
+// with the rate limiter.
+type Store struct {
+	db *badger.DB
+}
+
+// New constructs a Store over an open badger database.
+func New(db *badger.DB) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+// Add records an issued token until it expires.
+func (s *Store) Add(token Token) error {
+	if err := s.db.Update(func(txn *badger.Txn) error {
+		return setToken(txn, token)
+	}); err != nil {
+		return fmt.Errorf("add: unable to record token: %w", err)
+	}
+
+	return nil
+}
+
+// Query returns the token recorded under id.
+func (s *Store) Query(id string) (Token, error) {
+	var token Token
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		var err error
+		token, err = getToken(txn, id)
+		return err
+	})
+
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Token{}, ErrNotFound
+		}
+		return Token{}, fmt.Errorf("query: unable to read token: %w", err)
+	}
+
+	return token, nil
+}
+
+// IsRevoked reports whether the token recorded under id has been revoked.
+// Tokens that were never recorded are not revoked.
+func (s *Store) IsRevoked(id string) (bool, error) {
+	token, err := s.Query(id)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	return token.Revoked(), nil
+}
+
+// Revoke marks the token recorded under id as revoked and returns it. When
+// the token is not recorded, fallback is recorded as revoked instead, which
+// lets a token issued elsewhere be revoked from its verified claims. A zero
+// fallback returns ErrNotFound. Revoking a token twice keeps the first
+// revocation time.
+func (s *Store) Revoke(id string, fallback Token) (Token, error) {
+	var token Token
+
+	update := func(txn *badger.Txn) error {
+		var err error
+		token, err = getToken(txn, id)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			if fallback.ID != id {
+				return ErrNotFound
+			}
+			token = fallback
+		case err != nil:
+			return err
+		}
+
+		if token.Revoked() {
+			return nil
+		}
+
+		token.RevokedAt = time.Now().UTC()
+
+		return setToken(txn, token)
+	}
+
+	for range conflictRetries {
+		err := s.db.Update(update)
+		switch {
+		case err == nil:
+			return token, nil
+		case errors.Is(err, ErrNotFound):
+			return Token{}, ErrNotFound
+		case !errors.Is(err, badger.ErrConflict):
+			return Token{}, fmt.Errorf("revoke: unable to revoke token: %w", err)
+		}
+	}
+
+	return Token{}, fmt.Errorf("revoke: unable to revoke token after %d conflicts: %w", conflictRetries, badger.ErrConflict)
+}
+
+// List returns the recorded tokens that have not expired, newest first.
+func (s *Store) List() ([]Token, error) {
+	prefix := []byte(keyPrefix)
+
+	var tokens []Token
+
+	view := func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.ValidForPrefix(prefix); it.Next() {
+			var token Token
+
+			err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &token)
+			})
+			if err != nil {
+				return err
+			}
+
+			tokens = append(tokens, token)
+		}
+
+		return nil
+	}
+
+	if err := s.db.View(view); err != nil {
+		return nil, fmt.Errorf("list: unable to read tokens: %w", err)
+	}
+
+	slices.SortFunc(tokens, func(a, b Token) int {
+		return b.IssuedAt.Compare(a.IssuedAt)
+	})
+
+	return tokens, nil
+}
+
+// =============================================================================
+
+const keyPrefix = "token:"
+
+func tokenKey(id string) []byte {
+	return []byte(keyPrefix + id)
+}
+
+func getToken(txn *badger.Txn, id string) (Token, error) {
+	item, err := txn.Get(tokenKey(id))
+	if err != nil {
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return Token{}, ErrNotFound
+		}
+		return Token{}, err
+	}
+
+	var token Token
+	err = item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &token)
+	})
+
+	return token, err
+}
+
+func setToken(txn *badger.Txn, token Token) error {
+	val, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	entry := badger.NewEntry(tokenKey(token.ID), val)
+	if !token.ExpiresAt.IsZero() {
+		entry.ExpiresAt = uint64(token.ExpiresAt.Unix())
+	}
+
+	return txn.SetEntry(entry)
+}
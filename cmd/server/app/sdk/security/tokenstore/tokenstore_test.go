@@ -0,0 +1,190 @@
+package tokenstore_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/kronk/cmd/server/app/sdk/security/tokenstore"
+	"github.com/dgraph-io/badger/v4"
+)
+
+func openDB(t *testing.T, path string) *badger.DB {
+	t.Helper()
+
+	opts := badger.DefaultOptions(path)
+	opts.Logger = nil
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		t.Fatalf("should be able to open badger db: %s", err)
+	}
+
+	return db
+}
+
+func Test_TokenStore(t *testing.T) {
+	db := openDB(t, t.TempDir())
+	defer db.Close()
+
+	store := tokenstore.New(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	older := tokenstore.Token{ID: "older", Subject: "alice", IssuedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)}
+	newer := tokenstore.Token{ID: "newer", Subject: "bob", Admin: true, IssuedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	for _, token := range []tokenstore.Token{older, newer} {
+		if err := store.Add(token); err != nil {
+			t.Fatalf("should be able to add token %s: %s", token.ID, err)
+		}
+	}
+
+	got, err := store.Query("newer")
+	if err != nil {
+		t.Fatalf("should be able to query token: %s", err)
+	}
+	if got.Subject != "bob" || !got.Admin || !got.ExpiresAt.Equal(newer.ExpiresAt) || got.Revoked() {
+		t.Errorf("query: got %+v, want %+v", got, newer)
+	}
+
+	if _, err := store.Query("missing"); !errors.Is(err, tokenstore.ErrNotFound) {
+		t.Errorf("query missing: got %v, want ErrNotFound", err)
+	}
+
+	tokens, err := store.List()
+	if err != nil {
+		t.Fatalf("should be able to list tokens: %s", err)
+	}
+	if len(tokens) != 2 || tokens[0].ID != "newer" || tokens[1].ID != "older" {
+		t.Errorf("list: got %+v, want newer then older", tokens)
+	}
+
+	// -------------------------------------------------------------------------
+
+	revoked, err := store.Revoke("older", tokenstore.Token{})
+	if err != nil {
+		t.Fatalf("should be able to revoke token: %s", err)
+	}
+	if !revoked.Revoked() || revoked.Subject != "alice" {
+		t.Errorf("revoke: got %+v, want the revoked older token", revoked)
+	}
+
+	again, err := store.Revoke("older", tokenstore.Token{})
+	if err != nil {
+		t.Fatalf("should be able to revoke token twice: %s", err)
+	}
+	if !again.RevokedAt.Equal(revoked.RevokedAt) {
+		t.Errorf("revoke twice: revoked at %v, want the first revocation at %v", again.RevokedAt, revoked.RevokedAt)
+	}
+
+	if _, err := store.Revoke("missing", tokenstore.Token{}); !errors.Is(err, tokenstore.ErrNotFound) {
+		t.Errorf("revoke missing: got %v, want ErrNotFound", err)
+	}
+
+	fallback := tokenstore.Token{ID: "external", Subject: "carol", IssuedAt: now, ExpiresAt: now.Add(time.Hour)}
+	if _, err := store.Revoke("external", fallback); err != nil {
+		t.Fatalf("should be able to revoke an unrecorded token from its claims: %s", err)
+	}
+
+	for id, want := range map[string]bool{"older": true, "newer": false, "external": true, "missing": false} {
+		got, err := store.IsRevoked(id)
+		if err != nil {
+			t.Fatalf("should be able to check revocation of %s: %s", id, err)
+		}
+		if got != want {
+			t.Errorf("is revoked %s: got %t, want %t", id, got, want)
+		}
+	}
+}
+
+func Test_TokenStoreReopen(t *testing.T) {
+	path := t.TempDir()
+	now := time.Now().UTC()
+
+	db := openDB(t, path)
+	store := tokenstore.New(db)
+
+	for _, token := range []tokenstore.Token{
+		{ID: "kept", Subject: "alice", IssuedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "revoked", Subject: "bob", IssuedAt: now, ExpiresAt: now.Add(time.Hour)},
+	} {
+		if err := store.Add(token); err != nil {
+			t.Fatalf("should be able to add token %s: %s", token.ID, err)
+		}
+	}
+
+	if _, err := store.Revoke("revoked", tokenstore.Token{}); err != nil {
+		t.Fatalf("should be able to revoke token: %s", err)
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatalf("should be able to close badger db: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	db = openDB(t, path)
+	defer db.Close()
+
+	store = tokenstore.New(db)
+
+	tokens, err := store.List()
+	if err != nil {
+		t.Fatalf("should be able to list tokens: %s", err)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("list after reopen: got %d tokens, want 2", len(tokens))
+	}
+
+	revoked, err := store.IsRevoked("revoked")
+	if err != nil || !revoked {
+		t.Errorf("is revoked after reopen: got %t, %v, want true", revoked, err)
+	}
+
+	kept, err := store.IsRevoked("kept")
+	if err != nil || kept {
+		t.Errorf("is revoked after reopen: got %t, %v, want false", kept, err)
+	}
+}
+
+func Test_TokenStorePrunesExpired(t *testing.T) {
+	db := openDB(t, t.TempDir())
+	defer db.Close()
+
+	store := tokenstore.New(db)
+
+	now := time.Now().UTC()
+
+	for _, token := range []tokenstore.Token{
+		{ID: "live", Subject: "alice", IssuedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "expired", Subject: "bob", IssuedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+	} {
+		if err := store.Add(token); err != nil {
+			t.Fatalf("should be able to add token %s: %s", token.ID, err)
+		}
+	}
+
+	if _, err := store.Query("expired"); !errors.Is(err, tokenstore.ErrNotFound) {
+		t.Errorf("query expired: got %v, want ErrNotFound", err)
+	}
+
+	tokens, err := store.List()
+	if err != nil {
+		t.Fatalf("should be able to list tokens: %s", err)
+	}
+	if len(tokens) != 1 || tokens[0].ID != "live" {
+		t.Errorf("list: got %+v, want only the live token", tokens)
+	}
+
+	// An expired token no longer authenticates, so there is nothing left to
+	// revoke.
+	if _, err := store.Revoke("expired", tokenstore.Token{}); !errors.Is(err, tokenstore.ErrNotFound) {
+		t.Errorf("revoke expired: got %v, want ErrNotFound", err)
+	}
+
+	revoked, err := store.IsRevoked("expired")
+	if err != nil || revoked {
+		t.Errorf("is revoked expired: got %t, %v, want false", revoked, err)
+	}
+}
@@ -0,0 +1,56 @@
+package model
+
+import "testing"
+
+// chunkEnd decides where long audio is cut for chunked transcription. The
+// decode itself needs the native library, so these tests cover the cut
+// placement only.
+
+func TestChunkEndCutsAtQuietestFrame(t *testing.T) {
+	chunk := samplesForMs(10000)
+	samples := make([]float32, samplesForMs(25000))
+	for i := range samples {
+		samples[i] = 0.5
+	}
+
+	// A quiet 100 ms gap two seconds before the first boundary.
+	gap := samplesForMs(8000)
+	for i := gap; i < gap+samplesForMs(100); i++ {
+		samples[i] = 0
+	}
+
+	end := chunkEnd(samples, 0, chunk)
+	if end < gap || end > gap+samplesForMs(100) {
+		t.Errorf("end: got %dms, want inside the gap at 8000ms", msForSamples(end))
+	}
+}
+
+func TestChunkEndLoudAudioCutsNearBoundary(t *testing.T) {
+	chunk := samplesForMs(10000)
+	samples := make([]float32, samplesForMs(25000))
+	for i := range samples {
+		samples[i] = 0.5
+	}
+
+	end := chunkEnd(samples, 0, chunk)
+	if end <= chunk-samplesForMs(chunkSearchMs) || end > chunk {
+		t.Errorf("end: got %dms, want within %dms before the 10000ms boundary", msForSamples(end), chunkSearchMs)
+	}
+}
+
+func TestChunkEndLastChunk(t *testing.T) {
+	samples := make([]float32, samplesForMs(12000))
+
+	if end := chunkEnd(samples, samplesForMs(5000), samplesForMs(10000)); end != len(samples) {
+		t.Errorf("end: got %d, want %d", end, len(samples))
+	}
+}
+
+func TestWithChunkMs(t *testing.T) {
+	var cfg TranscribeConfig
+	WithChunkMs(30000)(&cfg)
+
+	if cfg.ChunkMs != 30000 {
+		t.Errorf("ChunkMs: got %d, want 30000", cfg.ChunkMs)
+	}
+}
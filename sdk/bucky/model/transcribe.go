@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"sort"
 	"strings"
 	"unicode"
@@ -150,8 +151,17 @@ type TranscribeConfig struct {
 
 	// OnSegment, when non-nil, is invoked once per decoded segment
 	// after Full returns. The callback is synchronous and runs on the
-	// caller's goroutine.
+	// caller's goroutine. With ChunkMs set it fires after each chunk,
+	// so callers see progress on long audio.
 	OnSegment func(Segment)
+
+	// ChunkMs, when > 0, decodes audio longer than ChunkMs as a series of
+	// consecutive chunks of about ChunkMs instead of a single Full call.
+	// Each cut lands on the quietest point near the chunk boundary, and
+	// each chunk after the first is prompted with the tail tokens of the
+	// previous one and pinned to the language detected in the first.
+	// Segment and word timestamps are relative to the start of the audio.
+	ChunkMs int
 }
 
 // TranscribeOption is a functional option for TranscribeConfig.
@@ -245,6 +255,12 @@ func WithOnSegment(fn func(Segment)) TranscribeOption {
 	return func(c *TranscribeConfig) { c.OnSegment = fn }
 }
 
+// WithChunkMs decodes long audio in chunks of about v milliseconds so
+// OnSegment reports progress as each chunk completes.
+func WithChunkMs(v int) TranscribeOption {
+	return func(c *TranscribeConfig) { c.ChunkMs = v }
+}
+
 // =============================================================================
 
 // Transcribe runs the whisper.cpp pipeline on the provided 16 kHz
@@ -280,6 +296,14 @@ func (m *Model) Transcribe(ctx context.Context, samples []float32, opts ...Trans
 	}
 	defer m.pool.release(ps)
 
+	if tcfg.ChunkMs > 0 && len(samples) > samplesForMs(tcfg.ChunkMs) {
+		tr, err := m.transcribeChunks(ctx, ps.state, samples, tcfg)
+		if err != nil {
+			return Transcription{}, fmt.Errorf("transcribe: %w", err)
+		}
+		return tr, nil
+	}
+
 	if err := whisper.FullWithState(m.handle, ps.state, params, samples); err != nil {
 		return Transcription{}, fmt.Errorf("transcribe: %w", err)
 	}
@@ -362,6 +386,113 @@ func (m *Model) TranscribeChannelsFile(ctx context.Context, r io.Reader, opts ..
 
 // =============================================================================
 
+// chunkSearchMs bounds how far back from a chunk boundary transcribeChunks
+// looks for a quiet cut point, and chunkFrameMs is the energy frame used to
+// find it.
+const (
+	chunkSearchMs = 5000
+	chunkFrameMs  = 100
+)
+
+// transcribeChunks decodes samples as consecutive chunks on one state,
+// reporting each chunk's segments through OnSegment as soon as the chunk is
+// decoded.
+func (m *Model) transcribeChunks(ctx context.Context, state whisper.State, samples []float32, tcfg TranscribeConfig) (Transcription, error) {
+	var (
+		out    Transcription
+		sb     strings.Builder
+		prompt []whisper.Token
+	)
+
+	chunk := samplesForMs(tcfg.ChunkMs)
+
+	for start := 0; start < len(samples); {
+		if err := ctx.Err(); err != nil {
+			return Transcription{}, err
+		}
+
+		end := chunkEnd(samples, start, chunk)
+
+		ccfg := tcfg
+		ccfg.PromptTokens = prompt
+		if start > 0 {
+			ccfg.Language = out.Language
+			ccfg.InitialPrompt = ""
+		}
+
+		params, refs, err := m.buildFullParams(ccfg)
+		if err != nil {
+			return Transcription{}, err
+		}
+
+		err = whisper.FullWithState(m.handle, state, params, samples[start:end])
+		refs.KeepAlive()
+		if err != nil {
+			return Transcription{}, err
+		}
+
+		tr := collectTranscription(m.handle, state, ccfg.Language, tcfg.WordTimestamps, nil)
+		if start == 0 {
+			out.Language = tr.Language
+		}
+
+		offsetMs := msForSamples(start)
+		for _, seg := range tr.Segments {
+			seg.Index = int32(len(out.Segments))
+			seg.StartMs += offsetMs
+			seg.EndMs += offsetMs
+			out.Segments = append(out.Segments, seg)
+			sb.WriteString(seg.Text)
+
+			if tcfg.OnSegment != nil {
+				tcfg.OnSegment(seg)
+			}
+		}
+
+		for _, w := range tr.Words {
+			w.StartMs += offsetMs
+			w.EndMs += offsetMs
+			out.Words = append(out.Words, w)
+		}
+
+		prompt = harvestPromptTokens(state, maxPromptTokens)
+		start = end
+	}
+
+	out.Text = strings.TrimSpace(sb.String())
+	out.Duration = float64(len(samples)) / float64(whisper.SampleRate)
+
+	return out, nil
+}
+
+// chunkEnd returns the end of the chunk that starts at start. The last
+// chunk runs to the end of samples; otherwise the cut is placed in the
+// middle of the quietest frame within chunkSearchMs before the boundary,
+// so words are less likely to be split across chunks.
+func chunkEnd(samples []float32, start int, chunk int) int {
+	boundary := start + chunk
+	if boundary >= len(samples) {
+		return len(samples)
+	}
+
+	frame := samplesForMs(chunkFrameMs)
+	from := max(boundary-samplesForMs(chunkSearchMs), start+frame)
+
+	best, bestEnergy := boundary, math.MaxFloat64
+	for at := from; at+frame <= boundary; at += frame {
+		var energy float64
+		for _, v := range samples[at : at+frame] {
+			energy += float64(v) * float64(v)
+		}
+
+		if energy < bestEnergy {
+			best, bestEnergy = at+frame/2, energy
+		}
+	}
+
+	return best
+}
+
 func (m *Model) buildFullParams(tcfg TranscribeConfig) (whisper.WhisperFullParams, whisper.StringRefs, error) {
 	params := whisper.FullDefaultParams(transcribeSamplingStrategy(tcfg))
 
@@ -15,6 +15,23 @@ import (
 // committed before the operation failed.
 var ErrResponseCommitted = errors.New("response already committed")
 
+// SessionHeader is the HTTP header that pins a request to a named IMC session.
+// It is the header form of the prompt_cache_key request field.
+const SessionHeader = "X-Kronk-Session"
+
+// ApplySessionHeader copies the SessionHeader value from h into d as
+// prompt_cache_key. A key set in the request document takes precedence.
+func ApplySessionHeader(h http.Header, d model.D) {
+	key := h.Get(SessionHeader)
+	if key == "" {
+		return
+	}
+
+	if _, exists := d["prompt_cache_key"]; !exists {
+		d["prompt_cache_key"] = key
+	}
+}
+
 // Chat provides support to interact with an inference model.
 // For text models, NSeqMax controls parallel sequence processing within a single
 // model instance. For vision/audio models, NSeqMax creates multiple model
@@ -56,7 +73,9 @@ func (krn *Kronk) ChatStreaming(ctx context.Context, d model.D) (<-chan model.Ch
 // ChatStreamingHTTP provides http handler support for a chat/completions call.
 // For text models, NSeqMax controls parallel sequence processing within a single
 // model instance. For vision/audio models, NSeqMax creates multiple model
-// instances in a pool for concurrent request handling.
+// instances in a pool for concurrent request handling. The returned response
+// carries the usage of the request, streamed or not, so callers can account
+// for the tokens it used.
 func (krn *Kronk) ChatStreamingHTTP(ctx context.Context, w http.ResponseWriter, d model.D) (model.ChatResponse, error) {
 	// [DEBUG]: Show raw input content.
 	// fmt.Printf("[DEBUG]: {\"req\":%s}\n", debugChatRequest(d))
@@ -96,6 +115,11 @@ func (krn *Kronk) ChatStreamingHTTP(ctx context.Context, w http.ResponseWriter,
 		return model.ChatResponse{}, fmt.Errorf("chat-streaming-http: streaming not supported")
 	}
 
+	// Usage is always requested from the model so it can be returned to the
+	// caller. The usage event is only sent to clients that asked for it.
+	d = d.ShallowClone()
+	d["stream_options"] = model.D{"include_usage": true}
+
 	ch, err := krn.ChatStreaming(ctx, d)
 	if err != nil {
 		return model.ChatResponse{}, fmt.Errorf("chat-streaming-http: stream-response: %w", err)
@@ -108,6 +132,13 @@ func (krn *Kronk) ChatStreamingHTTP(ctx context.Context, w http.ResponseWriter,
 		return model.ChatResponse{}, fmt.Errorf("chat-streaming-http: %w: flush headers: %w", ErrResponseCommitted, err)
 	}
 
+	return krn.writeChatStream(ctx, w, ch, includeUsage)
+}
+
+// writeChatStream writes the chunks of a streamed chat call as server-sent
+// events and returns the last response, carrying the usage of the request.
+// The usage event is only written when includeUsage is set.
+func (krn *Kronk) writeChatStream(ctx context.Context, w http.ResponseWriter, ch <-chan model.ChatResponse, includeUsage bool) (model.ChatResponse, error) {
 	// Every 15 seconds we will send a SSE keep alive for responses
 	// that are taking a long time to process. We won't reset this
 	// in the processing loop to eliminate overhead.
@@ -131,6 +162,11 @@ func (krn *Kronk) ChatStreamingHTTP(ctx context.Context, w http.ResponseWriter,
 			}
 
 			if len(resp.Choices) == 0 {
+				lr.Usage = resp.Usage
+				if !includeUsage {
+					continue
+				}
+
 				d, err := marshalChatStreamResponse(resp, includeUsage)
 				if err != nil {
 					return lr, fmt.Errorf("chat-streaming-http: %w: marshal usage event: %w", ErrResponseCommitted, err)
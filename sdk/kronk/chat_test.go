@@ -3,7 +3,10 @@ package kronk
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/ardanlabs/kronk/sdk/kronk/model"
@@ -144,6 +147,66 @@ func TestMarshalChatStreamResponseUsage(t *testing.T) {
 	}
 }
 
+func TestWriteChatStreamUsage(t *testing.T) {
+	stop := model.FinishReasonStop
+	u := model.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}
+
+	chunks := []model.ChatResponse{
+		{ID: "chatcmpl-id", Choices: []model.Choice{{Delta: &model.ResponseMessage{Content: "hi"}}}},
+		{ID: "chatcmpl-id", Choices: []model.Choice{{Delta: &model.ResponseMessage{}, FinishReasonPtr: &stop}}},
+		{ID: "chatcmpl-id", Choices: []model.Choice{}, Usage: &u},
+	}
+
+	for _, includeUsage := range []bool{false, true} {
+		t.Run(fmt.Sprintf("include usage %t", includeUsage), func(t *testing.T) {
+			ch := make(chan model.ChatResponse, len(chunks))
+			for _, chunk := range chunks {
+				ch <- chunk
+			}
+			close(ch)
+
+			var krn Kronk
+			w := httptest.NewRecorder()
+
+			resp, err := krn.writeChatStream(t.Context(), w, ch, includeUsage)
+			if err != nil {
+				t.Fatalf("writeChatStream: %v", err)
+			}
+
+			if resp.Usage == nil || *resp.Usage != u {
+				t.Errorf("usage: got %+v, want %+v", resp.Usage, u)
+			}
+
+			var events, usageEvents int
+			for line := range strings.Lines(w.Body.String()) {
+				data, ok := strings.CutPrefix(strings.TrimSpace(line), "data: ")
+				if !ok || data == "[DONE]" {
+					continue
+				}
+				events++
+
+				var wire struct {
+					Usage *model.Usage `json:"usage"`
+				}
+				if err := json.Unmarshal([]byte(data), &wire); err != nil {
+					t.Fatalf("Unmarshal %s: %v", data, err)
+				}
+				if wire.Usage != nil {
+					usageEvents++
+				}
+			}
+
+			wantEvents, wantUsage := 2, 0
+			if includeUsage {
+				wantEvents, wantUsage = 3, 1
+			}
+			if events != wantEvents || usageEvents != wantUsage {
+				t.Errorf("events: got %d with %d usage, want %d with %d usage", events, usageEvents, wantEvents, wantUsage)
+			}
+		})
+	}
+}
+
 func TestChatValidatesRequestBeforeAdmission(t *testing.T) {
 	tests := []struct {
 		name string
@@ -303,3 +366,31 @@ func (w *unwrapResponseWriter) Header() http.Header {
 func (w *unwrapResponseWriter) Unwrap() http.ResponseWriter {
 	return w.ResponseWriter
 }
+
+func TestApplySessionHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		d      model.D
+		want   any
+	}{
+		{name: "no header", d: model.D{}, want: nil},
+		{name: "header sets key", header: "user-42", d: model.D{}, want: "user-42"},
+		{name: "body key wins", header: "user-42", d: model.D{"prompt_cache_key": "body"}, want: "body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			if tt.header != "" {
+				h.Set(SessionHeader, tt.header)
+			}
+
+			ApplySessionHeader(h, tt.d)
+
+			if got := tt.d["prompt_cache_key"]; got != tt.want {
+				t.Errorf("prompt_cache_key: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package kronk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+)
+
+// Completion provides support for raw prompt completions. The prompt is
+// tokenized as given without the chat template, and the generated text is
+// returned as content without reasoning or tool call parsing.
+//
+// Supported options in d beyond the chat sampling parameters:
+//   - prompt (string or []int): the raw prompt or its token IDs (required)
+//   - suffix (string): the text after the insertion point, which makes the
+//     request a fill-in-the-middle request using the model's FIM tokens
+func (krn *Kronk) Completion(ctx context.Context, d model.D) (model.ChatResponse, error) {
+	if err := model.ValidateCompletionRequest(d); err != nil {
+		return model.ChatResponse{}, fmt.Errorf("completion: %w", err)
+	}
+
+	f := func(m *model.Model) (model.ChatResponse, error) {
+		return m.Completion(ctx, d)
+	}
+
+	return nonStreaming(ctx, krn, f)
+}
+
+// CompletionStreaming provides support for streaming raw prompt completions.
+// When stream_options.include_usage is true, the terminal choice is followed
+// by a usage response with an empty Choices slice.
+func (krn *Kronk) CompletionStreaming(ctx context.Context, d model.D) (<-chan model.ChatResponse, error) {
+	if err := model.ValidateCompletionRequest(d); err != nil {
+		return nil, fmt.Errorf("completion-streaming: %w", err)
+	}
+
+	f := func(m *model.Model) (<-chan model.ChatResponse, error) {
+		return m.CompletionStreaming(ctx, d)
+	}
+
+	ef := func(err error) model.ChatResponse {
+		return model.ChatResponseErr("panic", model.ObjectChatUnknown, krn.ModelID(), 0, err, model.Usage{})
+	}
+
+	return streaming(ctx, krn, f, ef)
+}
@@ -12,10 +12,14 @@ import (
 // Embeddings provides support to interact with an embedding model.
 //
 // Supported options in d:
-//   - input (string): the text to embed (required)
+//   - input (string, []string, []int, or [][]int): the texts or token IDs to embed (required)
 //   - truncate (bool): if true, truncate input to fit context window (default: false)
 //   - truncate_direction (string): "right" (default) or "left"
 //   - dimensions (int): reduce output to first N dimensions (for Matryoshka models)
+//   - encoding_format (string): "float" (default) or "base64"
+//   - chunking (bool or object): split over-long inputs into overlapping chunks and pool them
+//
+// See model.Model.Embeddings for the chunking options.
 //
 // Each model instance processes calls sequentially (llama.cpp only supports
 // sequence 0 for embedding extraction). Use NSeqMax > 1 to create multiple
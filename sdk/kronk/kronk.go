@@ -4,6 +4,7 @@ package kronk
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -175,6 +176,69 @@ func (krn *Kronk) IMCSessions() []model.IMCSessionDetail {
 	return krn.model.IMCSessions()
 }
 
+// ExportIMCSession writes the IMC session with the given id to w so it can be
+// restored with ImportIMCSession on this or another server.
+func (krn *Kronk) ExportIMCSession(w io.Writer, id int) error {
+	krn.shutdown.Lock()
+	defer krn.shutdown.Unlock()
+
+	if krn.shutdownFlag {
+		return fmt.Errorf("export-imc-session: %w: model is unloading", model.ErrIMCSessionNotFound)
+	}
+
+	return krn.model.ExportIMCSession(w, id)
+}
+
+// ImportIMCSession restores a session written by ExportIMCSession into the
+// model's IMC session pool and returns its state.
+func (krn *Kronk) ImportIMCSession(r io.Reader) (model.IMCSessionDetail, error) {
+	krn.shutdown.Lock()
+	defer krn.shutdown.Unlock()
+
+	if krn.shutdownFlag {
+		return model.IMCSessionDetail{}, fmt.Errorf("import-imc-session: %w: model is unloading", model.ErrIMCSessionBusy)
+	}
+
+	return krn.model.ImportIMCSession(r)
+}
+
+// Adapters returns the LoRA adapters loaded against the model.
+func (krn *Kronk) Adapters() []model.AdapterDetail {
+	krn.shutdown.Lock()
+	defer krn.shutdown.Unlock()
+
+	if krn.shutdownFlag {
+		return nil
+	}
+
+	return krn.model.Adapters()
+}
+
+// LoadAdapter loads a LoRA adapter against the running model so requests can
+// select it by ID.
+func (krn *Kronk) LoadAdapter(ctx context.Context, adapter model.AdapterConfig) (model.AdapterDetail, error) {
+	krn.shutdown.Lock()
+	defer krn.shutdown.Unlock()
+
+	if krn.shutdownFlag {
+		return model.AdapterDetail{}, fmt.Errorf("load-adapter: model is unloading")
+	}
+
+	return krn.model.LoadAdapter(ctx, adapter)
+}
+
+// UnloadAdapter unloads an adapter loaded with LoadAdapter.
+func (krn *Kronk) UnloadAdapter(ctx context.Context, id string) error {
+	krn.shutdown.Lock()
+	defer krn.shutdown.Unlock()
+
+	if krn.shutdownFlag {
+		return fmt.Errorf("unload-adapter: %w: model is unloading", model.ErrAdapterNotFound)
+	}
+
+	return krn.model.UnloadAdapter(ctx, id)
+}
+
 // IMCSystemCaches returns the model's immutable System cache pool entries.
 func (krn *Kronk) IMCSystemCaches() []model.IMCSystemCacheDetail {
 	krn.shutdown.Lock()
@@ -0,0 +1,309 @@
+// Package disk provides the disk-backed implementation of the
+// kvstorage.Store contract used by IMC (Incremental Message Cache) to park
+// per-session KV cache bytes on local storage between requests.
+//
+// Stores created by one factory share a tier. Each store keeps its snapshot
+// in a Go-allocated []byte exactly like the RAM backend until the tier's RAM
+// budget is exceeded. The least recently used snapshots are then spilled to a
+// per-session file under the configured directory and their RAM is released.
+// A spilled snapshot is read back into RAM the next time Bytes is called and
+// its file is removed. This lets thousands of IMC sessions stay warm on NVMe
+// while only the most recently used ones occupy process memory.
+//
+// The tier also enforces an optional disk budget. When a spill would exceed
+// it, the least recently used spilled snapshots are dropped first; a dropped
+// store reports Len() == 0 and IMC rebuilds that session from scratch, which
+// is what happens to an evicted session with the RAM backend.
+//
+// Files are opened only while they are read or written, so the number of
+// parked sessions is not bounded by the process file-descriptor limit.
+// Files are temporary process-owned snapshots, not durable conversations:
+// they are named "kronk-sess-*.kv", removed on Close, and leak on process
+// crash. Stale files can be removed while no Kronk process uses the
+// directory.
+package disk
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/kvstorage"
+)
+
+// filePattern is the os.CreateTemp pattern used for per-session files. The
+// "*" is replaced by a random suffix that makes the name unique within the
+// configured directory.
+const filePattern = "kronk-sess-*.kv"
+
+// Config configures a disk-backed store factory.
+type Config struct {
+	// Dir is the directory that holds spilled session files. It is created
+	// when it does not exist.
+	Dir string
+
+	// MaxRAMBytes caps the snapshot bytes the factory's stores keep in RAM.
+	// Zero spills every snapshot as soon as another store is used.
+	MaxRAMBytes int64
+
+	// MaxDiskBytes caps the snapshot bytes parked in Dir. Zero means no cap.
+	MaxDiskBytes int64
+}
+
+// NewFactory constructs a factory for disk stores that share one RAM and
+// disk budget. The directory is created when it does not exist.
+func NewFactory(cfg Config) (kvstorage.Factory, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("disk: directory is required")
+	}
+	if cfg.MaxRAMBytes < 0 {
+		return nil, fmt.Errorf("disk: max RAM bytes must be >= 0, got %d", cfg.MaxRAMBytes)
+	}
+	if cfg.MaxDiskBytes < 0 {
+		return nil, fmt.Errorf("disk: max disk bytes must be >= 0, got %d", cfg.MaxDiskBytes)
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("disk: create directory %q: %w", cfg.Dir, err)
+	}
+
+	fi, err := os.Stat(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("disk: stat directory %q: %w", cfg.Dir, err)
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("disk: path %q is not a directory", cfg.Dir)
+	}
+
+	t := newTier(cfg)
+
+	return func() (kvstorage.Store, error) {
+		return t.newStore(), nil
+	}, nil
+}
+
+// =============================================================================
+
+// Store is a disk-backed session store. Kronk serializes the caller-facing
+// methods for a given store, but the shared tier may spill an idle store from
+// another goroutine, so the store state is guarded by mu.
+type Store struct {
+	tier *tier
+	elem *list.Element // position in the tier LRU; guarded by tier.mu
+
+	mu      sync.Mutex
+	buf     []byte // RAM snapshot; nil while spilled
+	length  int    // committed snapshot size, resident or spilled
+	path    string // spill file; empty while resident
+	pinned  bool   // between Prepare and Commit; never spilled
+	charged int    // RAM bytes charged to the tier; guarded by tier.mu
+	parked  int    // disk bytes charged to the tier; guarded by tier.mu
+}
+
+var _ kvstorage.Store = (*Store)(nil)
+
+// Len returns the size of the most recently committed snapshot, whether it
+// is held in RAM or spilled to disk.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.length
+}
+
+// Cap returns the current RAM backing-array capacity. It is zero while the
+// snapshot is spilled.
+func (s *Store) Cap() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return cap(s.buf)
+}
+
+// Bytes returns the committed snapshot for read access, reading it back from
+// disk first when it was spilled. The returned slice stays valid until the
+// next Prepare, Commit, Reset, or Close call even if the tier spills the store
+// again in the meantime.
+//
+// Returns nil when the store is empty or the spill file cannot be read; IMC
+// treats that as nothing to restore and rebuilds from scratch.
+func (s *Store) Bytes() []byte {
+	s.mu.Lock()
+	if s.path != "" {
+		s.load()
+	}
+	buf := s.buf
+	s.mu.Unlock()
+
+	s.tier.touch(s)
+
+	if len(buf) == 0 {
+		return nil
+	}
+
+	return buf
+}
+
+// Prepare returns a writable slice of length size. A spilled snapshot is
+// discarded rather than read back because the previous contents are not
+// preserved. The store is pinned in RAM until Commit.
+//
+// On grow, the new capacity is max(size, oldCap + oldCap/4), the same 25%
+// headroom policy as the RAM backend.
+//
+// A negative size is treated as 0.
+func (s *Store) Prepare(size int) []byte {
+	if size < 0 {
+		size = 0
+	}
+
+	s.mu.Lock()
+	s.removeFile()
+	s.pinned = true
+
+	oldCap := cap(s.buf)
+	if oldCap < size {
+		newCap := max(oldCap+oldCap/4, size)
+		s.buf = make([]byte, size, newCap)
+	} else {
+		s.buf = s.buf[:size]
+	}
+	s.length = size
+	buf := s.buf
+	s.mu.Unlock()
+
+	s.tier.touch(s)
+
+	return buf
+}
+
+// Commit publishes the first n bytes written to the prepared slice and
+// unpins the store. n is clamped to [0, cap(buf)].
+func (s *Store) Commit(n int) {
+	s.mu.Lock()
+	switch {
+	case n < 0:
+		n = 0
+	case n > cap(s.buf):
+		n = cap(s.buf)
+	}
+	s.buf = s.buf[:n]
+	s.length = n
+	s.pinned = false
+	s.mu.Unlock()
+
+	s.tier.touch(s)
+}
+
+// Reset zeroes the retained RAM capacity, removes any spill file, and clears
+// the committed snapshot so no bytes from the prior conversation survive
+// reuse. The RAM capacity is retained for the next Prepare.
+func (s *Store) Reset() {
+	s.mu.Lock()
+	clear(s.buf[:cap(s.buf)])
+	s.buf = s.buf[:0]
+	s.length = 0
+	s.removeFile()
+	s.mu.Unlock()
+
+	s.tier.touch(s)
+}
+
+// Close removes the store from its tier, releases its RAM, and removes any
+// spill file. The store must not be used again.
+func (s *Store) Close() error {
+	s.tier.remove(s)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf = nil
+	s.length = 0
+
+	if s.path == "" {
+		return nil
+	}
+
+	path := s.path
+	s.path = ""
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("disk: remove session file %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// spill writes the resident snapshot to a new file under dir and releases the
+// RAM buffer. An empty snapshot only releases the buffer. On write failure
+// the snapshot is dropped. The caller holds s.mu.
+func (s *Store) spill(dir string) {
+	if s.length == 0 {
+		s.buf = nil
+		return
+	}
+
+	f, err := os.CreateTemp(dir, filePattern)
+	if err != nil {
+		s.drop()
+		return
+	}
+
+	_, werr := f.Write(s.buf[:s.length])
+	cerr := f.Close()
+	if werr != nil || cerr != nil {
+		os.Remove(f.Name())
+		s.drop()
+		return
+	}
+
+	s.path = f.Name()
+	s.buf = nil
+}
+
+// load reads a spilled snapshot back into RAM and removes its file. On read
+// failure the snapshot is dropped. The caller holds s.mu.
+func (s *Store) load() {
+	buf := make([]byte, s.length)
+
+	err := func() error {
+		f, err := os.Open(s.path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.ReadFull(f, buf)
+		return err
+	}()
+
+	s.removeFile()
+
+	if err != nil {
+		s.drop()
+		return
+	}
+
+	s.buf = buf
+}
+
+// drop discards the snapshot wherever it lives. The caller holds s.mu.
+func (s *Store) drop() {
+	s.removeFile()
+	s.buf = nil
+	s.length = 0
+}
+
+// removeFile deletes the spill file, if any. The caller holds s.mu.
+func (s *Store) removeFile() {
+	if s.path == "" {
+		return
+	}
+
+	os.Remove(s.path)
+	s.path = ""
+}
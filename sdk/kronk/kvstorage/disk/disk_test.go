@@ -0,0 +1,297 @@
+package disk
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestFactory returns a factory rooted under t.TempDir() along with its
+// tier so tests can inspect the shared accounting.
+func newTestFactory(t *testing.T, maxRAM, maxDisk int64) (func() *Store, *tier) {
+	t.Helper()
+
+	factory, err := NewFactory(Config{Dir: t.TempDir(), MaxRAMBytes: maxRAM, MaxDiskBytes: maxDisk})
+	if err != nil {
+		t.Fatalf("NewFactory() error = %v, want nil", err)
+	}
+
+	var tr *tier
+	newStore := func() *Store {
+		t.Helper()
+
+		store, err := factory()
+		if err != nil {
+			t.Fatalf("factory() error = %v, want nil", err)
+		}
+		t.Cleanup(func() {
+			if err := store.Close(); err != nil {
+				t.Errorf("store.Close() error = %v, want nil", err)
+			}
+		})
+
+		s := store.(*Store)
+		tr = s.tier
+		return s
+	}
+
+	// Materialize the tier so callers can read usage before the first store.
+	newStore()
+
+	return newStore, tr
+}
+
+// snapshot fills a store with size bytes of value b and commits it.
+func snapshot(s *Store, size int, b byte) {
+	buf := s.Prepare(size)
+	for i := range buf {
+		buf[i] = b
+	}
+	s.Commit(size)
+}
+
+// sessionFiles returns the spill files currently in dir.
+func sessionFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, filePattern))
+	if err != nil {
+		t.Fatalf("Glob() error = %v, want nil", err)
+	}
+	return files
+}
+
+func TestNewFactoryValidation(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v, want nil", err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"missing dir", Config{}},
+		{"file path", Config{Dir: file}},
+		{"negative RAM", Config{Dir: t.TempDir(), MaxRAMBytes: -1}},
+		{"negative disk", Config{Dir: t.TempDir(), MaxDiskBytes: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewFactory(tt.cfg); err == nil {
+				t.Fatal("NewFactory() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestNewFactoryCreatesDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sessions", "model")
+
+	if _, err := NewFactory(Config{Dir: dir}); err != nil {
+		t.Fatalf("NewFactory() error = %v, want nil", err)
+	}
+
+	fi, err := os.Stat(dir)
+	if err != nil || !fi.IsDir() {
+		t.Fatalf("Stat(%q) = %v, %v; want a directory", dir, fi, err)
+	}
+}
+
+func TestStore_PrepareCommitBytes(t *testing.T) {
+	newStore, _ := newTestFactory(t, 1<<20, 0)
+	s := newStore()
+
+	if got := s.Bytes(); got != nil {
+		t.Errorf("empty Bytes() = %v, want nil", got)
+	}
+
+	buf := s.Prepare(16)
+	copy(buf, "0123456789abcdef")
+	s.Commit(10)
+
+	if got := s.Len(); got != 10 {
+		t.Errorf("Len() = %d, want 10", got)
+	}
+	if got := string(s.Bytes()); got != "0123456789" {
+		t.Errorf("Bytes() = %q, want %q", got, "0123456789")
+	}
+}
+
+func TestStore_CommitClamps(t *testing.T) {
+	newStore, _ := newTestFactory(t, 1<<20, 0)
+	s := newStore()
+
+	s.Prepare(8)
+	s.Commit(100)
+	if got := s.Len(); got != 8 {
+		t.Errorf("Commit(100) Len() = %d, want 8", got)
+	}
+
+	s.Prepare(8)
+	s.Commit(-1)
+	if got := s.Len(); got != 0 {
+		t.Errorf("Commit(-1) Len() = %d, want 0", got)
+	}
+}
+
+func TestStore_SpillsLeastRecentlyUsed(t *testing.T) {
+	newStore, tr := newTestFactory(t, 256, 0)
+	a, b, c := newStore(), newStore(), newStore()
+
+	snapshot(a, 128, 'a')
+	snapshot(b, 128, 'b')
+	snapshot(c, 128, 'c')
+
+	// a is the least recently used store and must be the one spilled.
+	if got := a.Cap(); got != 0 {
+		t.Errorf("a.Cap() = %d, want 0 after spill", got)
+	}
+	if got := a.Len(); got != 128 {
+		t.Errorf("a.Len() = %d, want 128 while spilled", got)
+	}
+	if b.Cap() == 0 || c.Cap() == 0 {
+		t.Errorf("b.Cap() = %d, c.Cap() = %d, want both resident", b.Cap(), c.Cap())
+	}
+	if files := sessionFiles(t, tr.dir); len(files) != 1 {
+		t.Errorf("spill files = %d, want 1", len(files))
+	}
+
+	ram, disk := tr.usage()
+	if ram != 256 || disk != 128 {
+		t.Errorf("usage() = %d RAM, %d disk; want 256, 128", ram, disk)
+	}
+
+	// Reading a back restores it, removes its file, and spills b instead.
+	if got := a.Bytes(); !bytes.Equal(got, bytes.Repeat([]byte{'a'}, 128)) {
+		t.Errorf("a.Bytes() did not round-trip the spilled snapshot")
+	}
+	if got := b.Cap(); got != 0 {
+		t.Errorf("b.Cap() = %d, want 0 after a was reloaded", got)
+	}
+	if got := b.Bytes(); !bytes.Equal(got, bytes.Repeat([]byte{'b'}, 128)) {
+		t.Errorf("b.Bytes() did not round-trip the spilled snapshot")
+	}
+}
+
+func TestStore_PinnedStoreIsNotSpilled(t *testing.T) {
+	newStore, _ := newTestFactory(t, 0, 0)
+	a, b := newStore(), newStore()
+
+	buf := a.Prepare(64)
+	snapshot(b, 64, 'b')
+
+	if got := a.Cap(); got < 64 {
+		t.Fatalf("a.Cap() = %d, want the prepared buffer kept while pinned", got)
+	}
+
+	copy(buf, bytes.Repeat([]byte{'a'}, 64))
+	a.Commit(64)
+
+	if got := a.Bytes(); !bytes.Equal(got, bytes.Repeat([]byte{'a'}, 64)) {
+		t.Errorf("a.Bytes() lost bytes written while pinned")
+	}
+}
+
+func TestStore_DiskCapDropsOldest(t *testing.T) {
+	newStore, tr := newTestFactory(t, 0, 200)
+	a, b, c := newStore(), newStore(), newStore()
+
+	snapshot(a, 100, 'a')
+	snapshot(b, 100, 'b')
+	snapshot(c, 100, 'c')
+	snapshot(newStore(), 100, 'd')
+
+	// a, b, and c must be parked but only two fit; a is dropped.
+	if got := a.Len(); got != 0 {
+		t.Errorf("a.Len() = %d, want 0 after being dropped", got)
+	}
+	if b.Len() != 100 || c.Len() != 100 {
+		t.Errorf("b.Len() = %d, c.Len() = %d, want 100 each", b.Len(), c.Len())
+	}
+	if _, disk := tr.usage(); disk != 200 {
+		t.Errorf("disk usage = %d, want 200", disk)
+	}
+
+	if got := a.Bytes(); got != nil {
+		t.Errorf("a.Bytes() = %v, want nil after being dropped", got)
+	}
+}
+
+func TestStore_DiskCapDropsOversizedSnapshot(t *testing.T) {
+	newStore, tr := newTestFactory(t, 0, 64)
+	a := newStore()
+
+	snapshot(a, 128, 'a')
+	snapshot(newStore(), 8, 'b')
+
+	if got := a.Len(); got != 0 {
+		t.Errorf("a.Len() = %d, want 0 for a snapshot larger than the disk cap", got)
+	}
+	if files := sessionFiles(t, tr.dir); len(files) != 0 {
+		t.Errorf("spill files = %d, want 0", len(files))
+	}
+}
+
+func TestStore_ResetRemovesSpillFile(t *testing.T) {
+	newStore, tr := newTestFactory(t, 32, 0)
+	a := newStore()
+
+	snapshot(a, 32, 'a')
+	snapshot(newStore(), 32, 'b')
+
+	if files := sessionFiles(t, tr.dir); len(files) != 1 {
+		t.Fatalf("spill files = %d, want 1", len(files))
+	}
+
+	a.Reset()
+
+	if got := a.Len(); got != 0 {
+		t.Errorf("Len() after Reset = %d, want 0", got)
+	}
+	if files := sessionFiles(t, tr.dir); len(files) != 0 {
+		t.Errorf("spill files after Reset = %d, want 0", len(files))
+	}
+	if _, disk := tr.usage(); disk != 0 {
+		t.Errorf("disk usage after Reset = %d, want 0", disk)
+	}
+}
+
+func TestStore_ResetZeroesRetainedCapacity(t *testing.T) {
+	newStore, _ := newTestFactory(t, 1<<20, 0)
+	s := newStore()
+
+	buf := s.Prepare(32)
+	snapshot(s, 32, 0xff)
+	s.Reset()
+
+	for i, b := range buf[:cap(buf)] {
+		if b != 0 {
+			t.Fatalf("byte %d = %#x after Reset, want 0", i, b)
+		}
+	}
+}
+
+func TestStore_CloseReleasesUsage(t *testing.T) {
+	newStore, tr := newTestFactory(t, 0, 0)
+	a, b := newStore(), newStore()
+
+	snapshot(a, 32, 'a')
+	snapshot(b, 32, 'b')
+
+	if err := a.Close(); err != nil {
+		t.Fatalf("a.Close() error = %v, want nil", err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("b.Close() error = %v, want nil", err)
+	}
+
+	if files := sessionFiles(t, tr.dir); len(files) != 0 {
+		t.Errorf("spill files after Close = %d, want 0", len(files))
+	}
+	if ram, disk := tr.usage(); ram != 0 || disk != 0 {
+		t.Errorf("usage() after Close = %d RAM, %d disk; want 0, 0", ram, disk)
+	}
+}
@@ -0,0 +1,153 @@
+package disk
+
+import (
+	"container/list"
+	"sync"
+)
+
+// tier tracks the RAM and disk bytes held by the stores of one factory and
+// spills or drops the least recently used stores to stay within budget.
+//
+// Lock order is tier.mu before Store.mu. Store methods release their own
+// lock before calling into the tier.
+type tier struct {
+	dir     string
+	maxRAM  int64
+	maxDisk int64
+
+	mu   sync.Mutex
+	lru  *list.List // *Store, most recently used at the front
+	ram  int64
+	disk int64
+}
+
+func newTier(cfg Config) *tier {
+	return &tier{
+		dir:     cfg.Dir,
+		maxRAM:  cfg.MaxRAMBytes,
+		maxDisk: cfg.MaxDiskBytes,
+		lru:     list.New(),
+	}
+}
+
+func (t *tier) newStore() *Store {
+	s := Store{tier: t}
+
+	t.mu.Lock()
+	s.elem = t.lru.PushFront(&s)
+	t.mu.Unlock()
+
+	return &s
+}
+
+// touch marks s as the most recently used store, refreshes its accounting,
+// and spills other stores until the RAM budget is met.
+func (t *tier) touch(s *Store) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if s.elem == nil {
+		return
+	}
+
+	t.lru.MoveToFront(s.elem)
+
+	s.mu.Lock()
+	t.account(s)
+	s.mu.Unlock()
+
+	t.spillLRU(s)
+}
+
+// remove releases everything s has charged to the tier.
+func (t *tier) remove(s *Store) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if s.elem == nil {
+		return
+	}
+
+	t.lru.Remove(s.elem)
+	s.elem = nil
+
+	t.ram -= int64(s.charged)
+	t.disk -= int64(s.parked)
+	s.charged = 0
+	s.parked = 0
+}
+
+// usage returns the RAM and disk bytes currently charged to the tier.
+func (t *tier) usage() (ram int64, disk int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.ram, t.disk
+}
+
+// =============================================================================
+
+// spillLRU spills resident stores, least recently used first, until the RAM
+// budget is met. The store being touched and pinned stores are skipped. The
+// caller holds t.mu.
+func (t *tier) spillLRU(touched *Store) {
+	for e := t.lru.Back(); e != nil && t.ram > t.maxRAM; e = e.Prev() {
+		s := e.Value.(*Store)
+		if s == touched {
+			continue
+		}
+
+		s.mu.Lock()
+		if !s.pinned && cap(s.buf) > 0 {
+			if t.makeRoom(s) {
+				s.spill(t.dir)
+			} else {
+				s.drop()
+			}
+			t.account(s)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// makeRoom drops spilled stores used less recently than s, oldest first,
+// until s fits in the disk budget. It reports whether s now fits. The caller
+// holds t.mu and s.mu.
+func (t *tier) makeRoom(s *Store) bool {
+	need := int64(s.length)
+	if t.maxDisk == 0 || need == 0 {
+		return true
+	}
+	if need > t.maxDisk {
+		return false
+	}
+
+	for e := t.lru.Back(); e != s.elem && t.disk+need > t.maxDisk; e = e.Prev() {
+		victim := e.Value.(*Store)
+
+		victim.mu.Lock()
+		if victim.path != "" {
+			victim.drop()
+			t.account(victim)
+		}
+		victim.mu.Unlock()
+	}
+
+	return t.disk+need <= t.maxDisk
+}
+
+// account refreshes the bytes s charges to the tier. The caller holds t.mu
+// and s.mu.
+func (t *tier) account(s *Store) {
+	charged := cap(s.buf)
+
+	var parked int
+	if s.path != "" {
+		parked = s.length
+	}
+
+	t.ram += int64(charged - s.charged)
+	t.disk += int64(parked - s.parked)
+	s.charged = charged
+	s.parked = parked
+}
@@ -11,6 +11,10 @@ var kinds = make(map[string]Kind)
 var (
 	// RAM identifies the built-in in-process RAM backend.
 	RAM = newKind("ram")
+
+	// Disk identifies the built-in backend that spills least recently used
+	// snapshots from RAM to local files.
+	Disk = newKind("disk")
 )
 
 // =============================================================================
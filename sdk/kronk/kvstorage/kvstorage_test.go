@@ -13,6 +13,7 @@ func TestKind(t *testing.T) {
 		wantErr bool
 	}{
 		{"RAM", "ram", RAM, false},
+		{"disk", "disk", Disk, false},
 		{"unknown", "unknown", Kind{}, true},
 	}
 
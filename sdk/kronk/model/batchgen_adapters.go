@@ -0,0 +1,202 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+// llama.cpp registers LoRA adapters on a whole context rather than per
+// sequence, so every row of a decode runs under the same adapters. The engine
+// therefore generates for one adapter set at a time: jobs selecting the set
+// applied to the context start as slots free up, and a job selecting another
+// set waits until the running generations finish. While such a job waits,
+// jobs for the current set are still admitted, but no more than one per slot,
+// so a steady stream of requests for the current set cannot starve it.
+
+// wake signals the processing loop without blocking.
+func (e *batchEngine) wake() {
+	select {
+	case e.wakeCh <- struct{}{}:
+	default:
+	}
+}
+
+// admitAdapters reports whether job can start under the adapter set applied
+// to the context, switching to the job's set when no slot is active. It
+// returns an error when the job selected an adapter that has since been
+// unloaded or the switch fails.
+func (e *batchEngine) admitAdapters(job *chatJob) (bool, error) {
+	if err := e.model.checkAdapters(job.adapters); err != nil {
+		return false, err
+	}
+
+	if job.adapters.key == e.adapters.key {
+		return true, nil
+	}
+
+	if e.hasActiveSlots() {
+		return false, nil
+	}
+
+	if err := e.useAdapters(job.adapters); err != nil {
+		return false, err
+	}
+
+	e.adapterSwitch = nil
+	e.adapterSwitchAdmitted = 0
+
+	return true, nil
+}
+
+// nextForAdapterSwitch returns the index in pendingJobs of the job to admit
+// while waiting selects another adapter set, or -1 when none can start. It
+// picks the next job for the current set until one job per slot has been
+// admitted during the wait.
+func (e *batchEngine) nextForAdapterSwitch(waiting *chatJob) int {
+	if e.adapterSwitch != waiting {
+		e.adapterSwitch = waiting
+		e.adapterSwitchAdmitted = 0
+	}
+
+	if e.adapterSwitchAdmitted >= e.nSlots {
+		return -1
+	}
+
+	var matching []*chatJob
+	for _, job := range e.pendingJobs {
+		if job.adapters.key == e.adapters.key && e.model.checkAdapters(job.adapters) == nil {
+			matching = append(matching, job)
+		}
+	}
+
+	i := e.fair.next(matching)
+	if i < 0 {
+		return -1
+	}
+
+	return slices.Index(e.pendingJobs, matching[i])
+}
+
+// useAdapters registers set on the target context and, when the draft is an
+// embedded MTP head, on the draft context. Callers must ensure no slot is
+// active.
+func (e *batchEngine) useAdapters(set adapterSet) error {
+	if set.key == e.adapters.key {
+		return nil
+	}
+
+	return e.switchAdapters(set)
+}
+
+// switchAdapters registers set on the contexts even when the key matches the
+// current set, which replaces a fallback adapter that was unloaded.
+func (e *batchEngine) switchAdapters(set adapterSet) error {
+	fallback := e.adapterFallback
+	if len(set.adapters) == 0 {
+		fallback = e.adapterFallbackFor()
+	}
+
+	e.model.decodeMu.Lock()
+	err := setContextAdapters(e.model.lctx, set, fallback)
+	if draft := adapterDraftContext(e.model.draft); err == nil && draft != 0 {
+		err = setContextAdapters(draft, set, fallback)
+	}
+	e.model.decodeMu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("use-adapters: %w", err)
+	}
+
+	from := e.adapters
+	e.adapters = set
+	e.adapterFallback = nil
+	if len(set.adapters) == 0 {
+		e.adapterFallback = fallback
+	}
+
+	e.model.log(context.Background(), "batch-engine", "status", "adapters-switched",
+		"from", adapterIDs(from), "to", adapterIDs(set))
+
+	return nil
+}
+
+// adapterFallbackFor returns the adapter to register at a zero scale when the
+// context must apply no adapters: a loaded adapter when one exists, otherwise
+// one the context already holds so nothing new is registered.
+func (e *batchEngine) adapterFallbackFor() *loraAdapter {
+	e.model.adapterMu.Lock()
+	defer e.model.adapterMu.Unlock()
+
+	if len(e.model.adapters) > 0 {
+		return e.model.adapters[0]
+	}
+	if e.adapterFallback != nil {
+		return e.adapterFallback
+	}
+	if len(e.adapters.adapters) > 0 {
+		return e.adapters.adapters[0]
+	}
+
+	return nil
+}
+
+// holdsAdapter reports whether the context currently registers adapter.
+func (e *batchEngine) holdsAdapter(adapter *loraAdapter) bool {
+	return e.adapters.contains(adapter) || e.adapterFallback == adapter
+}
+
+// releaseRetiredAdapters frees unloaded adapters the context no longer
+// registers. When the engine is idle, a retired adapter still registered is
+// replaced by the configured adapters first.
+func (e *batchEngine) releaseRetiredAdapters() {
+	m := e.model
+
+	m.adapterMu.Lock()
+	retired := len(m.retiredAdapters)
+	held := slices.ContainsFunc(m.retiredAdapters, e.holdsAdapter)
+	replaceable := len(m.defaultAdapters.adapters) > 0 || len(m.adapters) > 0
+	m.adapterMu.Unlock()
+
+	if retired == 0 {
+		return
+	}
+
+	// Without a loaded adapter to register in its place, a retired adapter
+	// held as the zero-scale fallback stays until the model is unloaded.
+	if held && replaceable && !e.hasActiveSlots() {
+		if err := e.switchAdapters(m.defaultAdapters); err != nil {
+			m.log(context.Background(), "batch-engine", "status", "adapter-release-failed", "err", err)
+		}
+	}
+
+	m.adapterMu.Lock()
+	defer m.adapterMu.Unlock()
+
+	remaining := m.retiredAdapters[:0]
+	for _, adapter := range m.retiredAdapters {
+		if e.holdsAdapter(adapter) {
+			remaining = append(remaining, adapter)
+			continue
+		}
+
+		if err := llama.AdapterLoraFree(adapter.handle); err != nil {
+			m.log(context.Background(), "batch-engine", "status", "adapter-free-failed", "id", adapter.cfg.AdapterID(), "err", err)
+		}
+		m.log(context.Background(), "batch-engine", "status", "adapter-freed", "id", adapter.cfg.AdapterID())
+	}
+	clear(m.retiredAdapters[len(remaining):])
+	m.retiredAdapters = remaining
+}
+
+// adapterIDs returns the IDs of the adapters in set for logging.
+func adapterIDs(set adapterSet) []string {
+	ids := make([]string, len(set.adapters))
+	for i, adapter := range set.adapters {
+		ids[i] = adapter.cfg.AdapterID()
+	}
+
+	return ids
+}
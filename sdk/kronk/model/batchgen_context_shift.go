@@ -0,0 +1,63 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+// contextShiftKeepDivisor bounds the prompt prefix a context shift keeps to a
+// fraction of the context window. The start of the prompt carries the system
+// prompt and instructions, which the rest of the generation depends on.
+const contextShiftKeepDivisor = 4
+
+// contextShiftEnabled reports whether generation that reaches the context
+// window shifts the context instead of failing. A draft model or MTP head
+// keeps draft KV whose positions would have to move in step with the target,
+// so those models never shift.
+func (m *Model) contextShiftEnabled() bool {
+	return m.contextShift && m.draft == nil
+}
+
+// canShiftContext reports whether the generation in s may shift its context.
+// Media embeddings and M-RoPE positions are never moved.
+func (e *batchEngine) canShiftContext(s *slot) bool {
+	return e.model.contextShiftEnabled() && s.job.object == ObjectChatText && !s.useMRoPE
+}
+
+// shiftContext makes room in the sequence of s. It keeps the first tokens of
+// the prompt, discards the older half of everything after them, and moves the
+// remaining cells back so generation continues at a lower position. The
+// discarded tokens are gone for good: the model no longer attends to them.
+func (e *batchEngine) shiftContext(s *slot) error {
+	nKeep := min(s.nPrompt, e.model.cfg.ContextWindow()/contextShiftKeepDivisor)
+	nDiscard := (int(s.nPast) - nKeep) / 2
+	if nDiscard <= 0 {
+		return fmt.Errorf("context-shift: no tokens to discard: n_past[%d] n_keep[%d]", s.nPast, nKeep)
+	}
+
+	start := llama.Pos(nKeep)
+	end := llama.Pos(nKeep + nDiscard)
+
+	e.model.decodeMu.Lock()
+	removed, err := llama.MemorySeqRm(e.model.mem, s.seqID, start, end)
+	if err == nil && removed {
+		err = llama.MemorySeqAdd(e.model.mem, s.seqID, end, s.nPast, -llama.Pos(nDiscard))
+	}
+	e.model.decodeMu.Unlock()
+
+	switch {
+	case err != nil:
+		return fmt.Errorf("context-shift: %w", err)
+	case !removed:
+		return fmt.Errorf("context-shift: unable to remove positions [%d, %d)", start, end)
+	}
+
+	s.nPast -= llama.Pos(nDiscard)
+
+	e.model.log(s.job.ctx, "batch-engine", "status", "context-shifted",
+		"slot", s.id, "seq", s.seqID, "id", s.job.id,
+		"kept_tokens", nKeep, "discarded_tokens", nDiscard, "n_past", s.nPast)
+
+	return nil
+}
@@ -42,6 +42,7 @@ type BatchEngineSnapshot struct {
 	NDraft                  int
 	QueuedRequests          int
 	PendingRequests         int
+	PreemptedRequests       int
 	PrefillSelectorStart    int
 	PrefillSelectorSelected int
 	PrefillSelectorNext     int
@@ -70,6 +71,7 @@ func (e *batchEngine) publishDiagnostics(force bool) {
 		NUBatch:                 e.model.cfg.EffectiveNUBatch(),
 		QueuedRequests:          len(e.requestQ),
 		PendingRequests:         len(e.pendingJobs),
+		PreemptedRequests:       len(e.preempted),
 		PrefillSelectorStart:    e.diagnosticPrefillStart,
 		PrefillSelectorSelected: e.diagnosticPrefillSelected,
 		PrefillSelectorNext:     e.prefillNext,
@@ -85,9 +87,12 @@ func (e *batchEngine) publishDiagnostics(force bool) {
 	}
 	snapshot.PrefillRows = max(snapshot.TotalRows-snapshot.GenerationRows, 0)
 
-	if e.model.draft != nil {
+	switch {
+	case e.model.draft != nil:
 		snapshot.MTP = e.model.draft.mtp()
 		snapshot.NDraft = e.model.draft.core().nDraft
+	case e.model.cfg.speculationPlan.Active():
+		snapshot.NDraft = e.model.cfg.speculationPlan.NDraft
 	}
 
 	for i, s := range e.slots {
@@ -159,6 +164,8 @@ func generationMode(e *batchEngine, s *slot) string {
 		return ""
 	case s.useMRoPE:
 		return "mrope"
+	case e.model.cfg.speculationPlan.Mode == SpeculationNGram:
+		return "ngram"
 	case e.model.draft == nil:
 		return "ordinary"
 	case e.model.draft.mtp() && s.mtp.Disabled:
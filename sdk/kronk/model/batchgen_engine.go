@@ -31,6 +31,29 @@ type batchEngine struct {
 	// Checked before reading requestQ in fillSlots.
 	pendingJobs []*chatJob
 
+	// fair orders pendingJobs by priority lane and per-tenant fair share.
+	// queueDepth is the per-lane pending count last published to metrics,
+	// indexed like Priorities.
+	fair       fairQueue
+	queueDepth [len(Priorities)]int
+
+	// preempted holds generations paused to free their slot for a higher
+	// priority job, in the order they were paused. They resume ahead of
+	// pending jobs of the same or lower priority.
+	preempted []*preemptedSlot
+
+	// adapters is the LoRA adapter set registered on the context, which every
+	// active slot runs under. adapterFallback is the adapter registered at a
+	// zero scale when the set is empty.
+	adapters        adapterSet
+	adapterFallback *loraAdapter
+
+	// adapterSwitch is the job waiting for the running generations to drain
+	// so the context can switch to its adapter set. adapterSwitchAdmitted
+	// counts the jobs for the current set admitted while it waits.
+	adapterSwitch         *chatJob
+	adapterSwitchAdmitted int
+
 	// batchReleased quarantines slots released after the current shared batch
 	// starts assembling. Their staged rows remain in batch until decode, so the
 	// slot's stable seqID must not be reassigned in the same iteration. After
@@ -45,6 +68,10 @@ type batchEngine struct {
 	diagnostics     atomic.Pointer[BatchEngineSnapshot]
 	speculation     speculation.Controller
 
+	// ngramScratch holds the target distribution buffers for n-gram
+	// verification, which has no draft core to own them.
+	ngramScratch samplingScratch
+
 	// Diagnostics below are owned by processLoop and copied into diagnostics
 	// at batch-loop boundaries for concurrent observers.
 	diagnosticPrefillStart    int
@@ -75,14 +102,7 @@ func newBatchEngine(m *Model, nSlots int) *batchEngine {
 	// per-slot — never share one across slots.
 	slots := make([]*slot, nSlots)
 	for i := range slots {
-		seqID := llama.SeqId(i)
-		slots[i] = &slot{
-			id:           i,
-			seqID:        seqID,
-			seqIDs:       []llama.SeqId{seqID}, // Pre-allocate for batchAdd
-			stateMachine: m.parser.NewStateMachine(),
-		}
-		slots[i].classic.Reset()
+		slots[i] = newSlot(m, i)
 	}
 
 	e := batchEngine{
@@ -96,6 +116,8 @@ func newBatchEngine(m *Model, nSlots int) *batchEngine {
 		shutdownCh:                make(chan struct{}),
 		loopDone:                  make(chan struct{}),
 		batchReleased:             make([]bool, nSlots),
+		fair:                      newFairQueue(),
+		adapters:                  m.defaultAdapters,
 		diagnosticPrefillSelected: -1,
 		diagnosticIMCSelected:     -1,
 	}
@@ -114,6 +136,21 @@ func newBatchEngine(m *Model, nSlots int) *batchEngine {
 	return &e
 }
 
+// newSlot creates the idle slot with the given index and its stable KV
+// sequence.
+func newSlot(m *Model, id int) *slot {
+	seqID := llama.SeqId(id)
+	s := slot{
+		id:           id,
+		seqID:        seqID,
+		seqIDs:       []llama.SeqId{seqID}, // Pre-allocate for batchAdd
+		stateMachine: m.parser.NewStateMachine(),
+	}
+	s.classic.Reset()
+
+	return &s
+}
+
 // start begins the batch processing loop.
 func (e *batchEngine) start(ctx context.Context) {
 	go e.processLoop(ctx)
@@ -217,7 +254,9 @@ func (e *batchEngine) processLoop(ctx context.Context) {
 		default:
 		}
 
-		if e.hasActiveSlots() || len(e.requestQ) > 0 || len(e.pendingJobs) > 0 {
+		e.releaseRetiredAdapters()
+
+		if e.hasActiveSlots() || len(e.requestQ) > 0 || len(e.pendingJobs) > 0 || len(e.preempted) > 0 {
 			e.processBatch(ctx, buf)
 			continue
 		}
@@ -269,6 +308,7 @@ func (e *batchEngine) processBatch(ctx context.Context, buf []byte) {
 	if e.batch.NTokens == 0 && e.hasIMCPreparation() {
 		e.advanceIMCPreparation(buf)
 	}
+	e.releaseSharedPrefixWaits()
 
 	e.speculation.Prepare()
 
@@ -294,8 +334,14 @@ func (e *batchEngine) processBatch(ctx context.Context, buf []byte) {
 		}
 
 		if int(s.nPast) >= e.model.cfg.ContextWindow() {
-			e.finishSlot(s, fmt.Errorf("generation reached context window of %d tokens", e.model.cfg.ContextWindow()))
-			continue
+			if !e.canShiftContext(s) {
+				e.finishSlot(s, fmt.Errorf("generation reached context window of %d tokens", e.model.cfg.ContextWindow()))
+				continue
+			}
+			if err := e.shiftContext(s); err != nil {
+				e.finishSlot(s, err)
+				continue
+			}
 		}
 
 		// A newly admitted request may have filled the logical batch during
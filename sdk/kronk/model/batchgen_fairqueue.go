@@ -0,0 +1,74 @@
+package model
+
+// fairQueue orders pending jobs by priority lane and, within a lane, by
+// start-time fair queuing across tenants.
+//
+// Each job is stamped with a virtual start time when it is queued: the later
+// of its lane's virtual clock and the virtual finish time of the tenant's
+// previous job. The job's cost is its prompt token count, so a tenant's
+// finish time advances by the work it asks for rather than by request count.
+// Picking the lowest start time gives each tenant with waiting work an equal
+// share of the slots, in proportion to prompt size, while a tenant's own jobs
+// keep their arrival order.
+type fairQueue struct {
+	clock  map[Priority]float64
+	finish map[fairTenant]float64
+}
+
+type fairTenant struct {
+	priority Priority
+	tenant   string
+}
+
+func newFairQueue() fairQueue {
+	return fairQueue{
+		clock:  make(map[Priority]float64),
+		finish: make(map[fairTenant]float64),
+	}
+}
+
+// enqueue stamps job with its virtual start time.
+func (fq *fairQueue) enqueue(job *chatJob) {
+	key := fairTenant{priority: job.schedule.Priority, tenant: job.schedule.Tenant}
+
+	start := max(fq.clock[key.priority], fq.finish[key])
+	fq.finish[key] = start + float64(max(len(job.textTokens), 1))
+	job.fairStart = start
+}
+
+// next returns the index of the job to assign next, or -1 when jobs is empty.
+// Ties keep the order of jobs.
+func (fq *fairQueue) next(jobs []*chatJob) int {
+	best := -1
+	for i, job := range jobs {
+		if best < 0 {
+			best = i
+			continue
+		}
+
+		b := jobs[best]
+		switch {
+		case job.schedule.Priority > b.schedule.Priority:
+			best = i
+		case job.schedule.Priority == b.schedule.Priority && job.fairStart < b.fairStart:
+			best = i
+		}
+	}
+
+	return best
+}
+
+// dequeue advances the lane's virtual clock to the start time of the job
+// being assigned and forgets tenants with no work left ahead of the clock.
+func (fq *fairQueue) dequeue(job *chatJob) {
+	priority := job.schedule.Priority
+
+	clock := max(fq.clock[priority], job.fairStart)
+	fq.clock[priority] = clock
+
+	for key, finish := range fq.finish {
+		if key.priority == priority && finish <= clock {
+			delete(fq.finish, key)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+func newFairTestJob(id string, priority Priority, tenant string, tokens int) *chatJob {
+	return &chatJob{
+		id:         id,
+		schedule:   Schedule{Priority: priority, Tenant: tenant},
+		textTokens: make([]llama.Token, tokens),
+	}
+}
+
+// drainFairQueue assigns every job one at a time and returns the job ids in
+// assignment order.
+func drainFairQueue(fq *fairQueue, jobs []*chatJob) []string {
+	var order []string
+	for len(jobs) > 0 {
+		i := fq.next(jobs)
+		fq.dequeue(jobs[i])
+		order = append(order, jobs[i].id)
+		jobs = slices.Delete(jobs, i, i+1)
+	}
+	return order
+}
+
+func TestFairQueueInterleavesTenants(t *testing.T) {
+	fq := newFairQueue()
+
+	// Tenant a bursts four requests before tenant b sends two.
+	var jobs []*chatJob
+	for _, job := range []*chatJob{
+		newFairTestJob("a1", PriorityNormal, "a", 100),
+		newFairTestJob("a2", PriorityNormal, "a", 100),
+		newFairTestJob("a3", PriorityNormal, "a", 100),
+		newFairTestJob("a4", PriorityNormal, "a", 100),
+		newFairTestJob("b1", PriorityNormal, "b", 100),
+		newFairTestJob("b2", PriorityNormal, "b", 100),
+	} {
+		fq.enqueue(job)
+		jobs = append(jobs, job)
+	}
+
+	got := drainFairQueue(&fq, jobs)
+	want := []string{"a1", "b1", "a2", "b2", "a3", "a4"}
+	if !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestFairQueueChargesPromptTokens(t *testing.T) {
+	fq := newFairQueue()
+
+	// Tenant a's long agent prompts cost four of tenant b's short prompts.
+	var jobs []*chatJob
+	for _, job := range []*chatJob{
+		newFairTestJob("a1", PriorityNormal, "a", 400),
+		newFairTestJob("a2", PriorityNormal, "a", 400),
+		newFairTestJob("b1", PriorityNormal, "b", 100),
+		newFairTestJob("b2", PriorityNormal, "b", 100),
+		newFairTestJob("b3", PriorityNormal, "b", 100),
+		newFairTestJob("b4", PriorityNormal, "b", 100),
+		newFairTestJob("b5", PriorityNormal, "b", 100),
+	} {
+		fq.enqueue(job)
+		jobs = append(jobs, job)
+	}
+
+	got := drainFairQueue(&fq, jobs)
+	want := []string{"a1", "b1", "b2", "b3", "b4", "a2", "b5"}
+	if !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestFairQueuePriorityLanes(t *testing.T) {
+	fq := newFairQueue()
+
+	var jobs []*chatJob
+	for _, job := range []*chatJob{
+		newFairTestJob("low", PriorityLow, "a", 1),
+		newFairTestJob("normal", PriorityNormal, "a", 1),
+		newFairTestJob("high1", PriorityHigh, "b", 1),
+		newFairTestJob("high2", PriorityHigh, "b", 1),
+	} {
+		fq.enqueue(job)
+		jobs = append(jobs, job)
+	}
+
+	got := drainFairQueue(&fq, jobs)
+	want := []string{"high1", "high2", "normal", "low"}
+	if !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestFairQueueIdleTenantDoesNotBankCredit(t *testing.T) {
+	fq := newFairQueue()
+
+	// Tenant a keeps the model busy while tenant b is idle.
+	var jobs []*chatJob
+	for i := range 3 {
+		job := newFairTestJob(fmt.Sprintf("a%d", i+1), PriorityNormal, "a", 100)
+		fq.enqueue(job)
+		jobs = append(jobs, job)
+	}
+	order := drainFairQueue(&fq, jobs[:2])
+	jobs = jobs[2:]
+
+	// Tenant b arrives late and starts at the lane clock, not at zero, so it
+	// shares with a instead of running its whole backlog first.
+	for _, job := range []*chatJob{
+		newFairTestJob("b1", PriorityNormal, "b", 100),
+		newFairTestJob("b2", PriorityNormal, "b", 100),
+		newFairTestJob("b3", PriorityNormal, "b", 100),
+	} {
+		fq.enqueue(job)
+		jobs = append(jobs, job)
+	}
+	order = append(order, drainFairQueue(&fq, jobs)...)
+
+	want := []string{"a1", "a2", "b1", "a3", "b2", "b3"}
+	if !slices.Equal(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Priority
+		wantErr bool
+	}{
+		{name: "", want: PriorityNormal},
+		{name: "low", want: PriorityLow},
+		{name: "normal", want: PriorityNormal},
+		{name: "high", want: PriorityHigh},
+		{name: "urgent", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePriority(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePriority(%q) err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParsePriority(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,7 +20,7 @@ func (e *batchEngine) finishSlot(s *slot, err error) {
 	if !s.active {
 		return
 	}
-	if e.batchAssembling {
+	if e.batchAssembling && !s.preempted {
 		e.batchReleased[s.id] = true
 	}
 
@@ -112,15 +113,17 @@ func (e *batchEngine) finishSlot(s *slot, err error) {
 			"active_streams", remaining,
 		}
 
-		// When a draft model is configured, always emit draft metrics so
+		// When speculation is configured, always emit draft metrics so
 		// the log schema stays stable for scrapers/dashboards even when
 		// speculation was disabled mid-request (adaptive sizing returned 0
-		// due to a collapsed acceptance EMA). Models without a draft
-		// model omit the fields entirely.
-		if e.model.draft != nil {
+		// due to a collapsed acceptance EMA). Models without speculation
+		// omit the fields entirely.
+		if e.model.cfg.speculationPlan.Active() {
 			switch {
 			case disableReason != "":
 				mtpResumeSource = "disabled"
+			case e.model.draft == nil:
+				mtpResumeSource = "none"
 			case mtpResumeSource == "":
 				mtpResumeSource = "fresh-prefill"
 			}
@@ -164,7 +167,7 @@ func (e *batchEngine) finishSlot(s *slot, err error) {
 
 	// Trim generated tokens from draft KV, keeping the cached prompt prefix
 	// for incremental reuse on the next request.
-	if e.model.draft != nil {
+	if e.model.draft != nil && !s.preempted {
 		_, sharedMTP := e.model.draft.(*sharedMTPDrafter)
 		if !sharedMTP {
 			trimPos := llama.Pos(len(s.draftCachedTokens))
@@ -196,10 +199,15 @@ func (e *batchEngine) finishSlot(s *slot, err error) {
 	// of whether they were produced by text tokens or media embeddings.
 	//
 	// Non-IMC: always clear.
-	e.model.decodeMu.Lock()
-	llama.MemorySeqRm(e.model.mem, s.seqID, -1, -1)
-	e.model.decodeMu.Unlock()
-	e.model.log(ctx, "finish-slot", "status", "seq-cleared", "slot", slotID, "seq", seqID)
+	//
+	// A preempted slot gave its sequence to another job when it was paused,
+	// so there is nothing of its own left to clear.
+	if !s.preempted {
+		e.model.decodeMu.Lock()
+		llama.MemorySeqRm(e.model.mem, s.seqID, -1, -1)
+		e.model.decodeMu.Unlock()
+		e.model.log(ctx, "finish-slot", "status", "seq-cleared", "slot", slotID, "seq", seqID)
+	}
 
 	// Unbind the IMC session from this slot's KV sequence. The session
 	// is now externalized (its bytes live in session.kvState in host
@@ -246,7 +254,7 @@ func (e *batchEngine) finishSlot(s *slot, err error) {
 	if usage.DraftTokens > 0 {
 		usage.DraftAcceptanceRate = float64(usage.DraftAcceptedTokens) / float64(usage.DraftTokens)
 	}
-	if outputTokens > 0 && e.model.draft != nil {
+	if outputTokens > 0 && e.model.cfg.speculationPlan.Active() {
 		usage.DraftCoverage = float64(s.specCoveredTotal) / float64(outputTokens)
 	}
 
@@ -277,7 +285,7 @@ func (e *batchEngine) finishSlot(s *slot, err error) {
 			metrics.ObserveChatRequestDuration(e.model.modelInfo.ID, time.Since(s.job.requestStart))
 		}
 
-		e.model.sendErrorResponse(ctx, s.job.ch, s.job.id, s.job.object, 0, err, usage)
+		e.model.sendErrorResponse(ctx, s.job.ch, s.job.id, s.job.object, s.job.choiceIndex, err, usage)
 
 		return
 	}
@@ -294,13 +302,13 @@ func (e *batchEngine) finishSlot(s *slot, err error) {
 			if outcome.err != nil {
 				outputTokens := s.reasonTokens + s.completionTokens
 				usage := Usage{PromptTokens: s.nPrompt, CompletionTokens: outputTokens, TotalTokens: s.nPrompt + outputTokens}
-				e.model.sendErrorResponse(ctx, s.job.ch, s.job.id, s.job.object, 0, outcome.err, usage)
+				e.model.sendErrorResponse(ctx, s.job.ch, s.job.id, s.job.object, s.job.choiceIndex, outcome.err, usage)
 				return
 			}
 		}
 	}
 
-	if flusher, ok := s.stateMachine.(StateMachineFlusher); ok {
+	if flusher, ok := s.stateMachine.(StateMachineFlusher); ok && !s.job.raw {
 		e.flushAllStateMachine(s, flusher)
 	}
 
@@ -414,7 +422,7 @@ func (e *batchEngine) finishSlot(s *slot, err error) {
 		if !s.job.requestStart.IsZero() {
 			metrics.ObserveChatRequestDuration(e.model.modelInfo.ID, time.Since(s.job.requestStart))
 		}
-		e.model.sendErrorResponse(ctx, s.job.ch, s.job.id, s.job.object, 0, err, usage)
+		e.model.sendErrorResponse(ctx, s.job.ch, s.job.id, s.job.object, s.job.choiceIndex, err, usage)
 		return
 	}
 
@@ -424,7 +432,7 @@ func (e *batchEngine) finishSlot(s *slot, err error) {
 	}
 	var deliveryErr error
 	if s.job.params.Stream && lengthTerminatedToolContent != "" {
-		deliveryErr = e.model.sendDeltaResponse(ctx, s.job.ch, s.job.id, s.job.object, 0, lengthTerminatedToolContent, ChannelAnswer, s.reasonTokens, outputTokens, nil)
+		deliveryErr = e.model.sendDeltaResponse(ctx, s.job.ch, s.job.id, s.job.object, s.job.choiceIndex, lengthTerminatedToolContent, ChannelAnswer, s.reasonTokens, outputTokens, nil)
 	}
 	var terminalToolCallDeltas []ResponseToolCallDelta
 	if s.job.params.Stream {
@@ -432,15 +440,19 @@ func (e *batchEngine) finishSlot(s *slot, err error) {
 		if streamer, ok := s.stateMachine.(ToolCallDeltaStreamer); ok {
 			started = streamer.StartedToolCalls()
 		}
-		terminalToolCallDeltas = reconcileStartedToolCalls(s.respToolCalls, started)
+		terminalToolCallDeltas = reconcileStartedToolCalls(s.respToolCalls, started, s.streamedToolArgs)
 	}
 	finalChannel := slotChannel(s)
 	if lengthTerminatedToolOutput {
 		finalChannel = ChannelAnswer
 	}
+	var stopSequence string
+	if s.stopSource == "request-stop" {
+		stopSequence = s.stopGate.matched
+	}
 	if deliveryErr == nil {
-		deliveryErr = e.model.sendFinalResponse(ctx, s.job.ch, s.job.id, s.job.object, 0,
-			&s.finalContent, &s.finalReasoning, s.respToolCalls, terminalToolCallDeltas, s.logprobsData, s.finishReason, s.stopSource, finalChannel, bufferedToolBytes, s.job.params.Stream, !s.job.params.Stream || s.job.params.IncludeUsage, usage)
+		deliveryErr = e.model.sendFinalResponse(ctx, s.job.ch, s.job.id, s.job.object, s.job.choiceIndex,
+			&s.finalContent, &s.finalReasoning, s.respToolCalls, terminalToolCallDeltas, s.logprobsData, s.finishReason, s.stopSource, stopSequence, finalChannel, bufferedToolBytes, s.job.params.Stream, !s.job.params.Stream || s.job.params.IncludeUsage, usage)
 	}
 	if deliveryErr != nil {
 		err = deliveryErr
@@ -480,7 +492,10 @@ func retainLengthTerminatedToolOutput(finishReason string, finalContent *strings
 	return lengthTerminatedToolMessage, true
 }
 
-func reconcileStartedToolCalls(toolCalls []ResponseToolCall, started []ResponseToolCallDelta) []ResponseToolCallDelta {
+// reconcileStartedToolCalls builds the deltas that complete the streamed tool
+// calls. Calls whose starts were streamed keep their streamed identity and
+// receive only the arguments not yet sent in streamed.
+func reconcileStartedToolCalls(toolCalls []ResponseToolCall, started []ResponseToolCallDelta, streamed map[int]string) []ResponseToolCallDelta {
 	if len(toolCalls) == 0 {
 		return nil
 	}
@@ -535,9 +550,25 @@ func reconcileStartedToolCalls(toolCalls []ResponseToolCall, started []ResponseT
 		terminal[i].Index = started[startAt].Index
 		terminal[i].Type = ""
 		terminal[i].Function.Name = ""
+		terminal[i].Function.Arguments = remainingToolCallArguments(streamed[started[startAt].Index], arguments)
+	}
+
+	return slices.DeleteFunc(terminal, func(delta ResponseToolCallDelta) bool {
+		return delta.ID == "" && delta.Function.Arguments == ""
+	})
+}
+
+// remainingToolCallArguments returns the part of arguments a client has not
+// received after streamed was sent. Streamed text that is not a prefix of
+// arguments either decodes to the same value, typically differing only in key
+// order or spacing, or diverged from the parsed call. Neither can be extended,
+// so nothing more is sent and the final message carries the parsed arguments.
+func remainingToolCallArguments(streamed string, arguments string) string {
+	if !strings.HasPrefix(arguments, streamed) {
+		return ""
 	}
 
-	return terminal
+	return arguments[len(streamed):]
 }
 
 func (e *batchEngine) flushAllStateMachine(s *slot, flusher StateMachineFlusher) {
@@ -573,7 +604,7 @@ func (e *batchEngine) failJob(job *chatJob, err error) {
 		job.queueWaitSpan.End()
 	}
 	if !job.queuedAt.IsZero() {
-		metrics.ObserveChatQueueWait(e.model.modelInfo.ID, time.Since(job.queuedAt))
+		metrics.ObserveChatQueueWait(e.model.modelInfo.ID, job.schedule.Priority.String(), time.Since(job.queuedAt))
 	}
 
 	status := "error"
@@ -614,7 +645,7 @@ func (e *batchEngine) failJob(job *chatJob, err error) {
 		"imc_cache_hit", job.imcSnapshotReused,
 		"err", err, "active_streams", remaining)
 
-	e.model.sendErrorResponse(job.ctx, job.ch, job.id, job.object, 0, err, Usage{})
+	e.model.sendErrorResponse(job.ctx, job.ch, job.id, job.object, job.choiceIndex, err, Usage{})
 	close(job.ch)
 }
 
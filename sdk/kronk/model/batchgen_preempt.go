@@ -0,0 +1,223 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/observ/metrics"
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+// preemptedSlot is a generation paused to give its slot to a higher priority
+// job. The slot keeps everything the request owns: its sampler, parser state,
+// accumulated output, and open stream. Only the KV sequence is handed over, so
+// its state is held in kv until the job resumes in whichever slot frees up
+// next.
+type preemptedSlot struct {
+	slot     *slot
+	kv       SessionStore
+	pausedAt time.Time
+}
+
+// canPreempt reports whether the generation running in s can be paused. Only
+// slots producing output are paused: a slot still preparing or prefilling its
+// prompt holds work that does not survive in the sequence state alone.
+// A draft model or MTP head keeps draft KV and per-slot drafting state that
+// a target snapshot does not capture, so those models never preempt. N-gram
+// drafting keeps its history in the slot, which travels with the paused job.
+func (e *batchEngine) canPreempt(s *slot) bool {
+	switch {
+	case !s.active || !s.prefillDone || s.job == nil:
+		return false
+	case s.imcPrep != nil || s.imcRestoring || s.mediaPrefilling || s.prefixTokens != nil:
+		return false
+	case e.model.draft != nil:
+		return false
+	}
+
+	return true
+}
+
+// preemptFor pauses a generation running below priority so a job of that
+// priority can take its slot. It reports whether a slot was freed.
+func (e *batchEngine) preemptFor(priority Priority) bool {
+	victim := e.preemptionVictim(priority)
+	if victim == nil {
+		return false
+	}
+
+	return e.preemptSlot(victim)
+}
+
+// preemptionVictim returns the slot to pause for a job of the given priority,
+// or nil when none runs below it. The lowest priority is chosen first and,
+// among equal priorities, the slot with the shortest sequence, since it is the
+// cheapest to spill and restore.
+func (e *batchEngine) preemptionVictim(priority Priority) *slot {
+	var victim *slot
+	for _, s := range e.slots {
+		if !e.canPreempt(s) || s.job.schedule.Priority >= priority {
+			continue
+		}
+
+		switch {
+		case victim == nil:
+			victim = s
+		case s.job.schedule.Priority < victim.job.schedule.Priority:
+			victim = s
+		case s.job.schedule.Priority == victim.job.schedule.Priority && s.nPast < victim.nPast:
+			victim = s
+		}
+	}
+
+	return victim
+}
+
+// preemptSlot snapshots the sequence of s into a session store, clears the
+// sequence, and parks s in preempted. A fresh idle slot takes its place in
+// the engine so the sequence can be assigned to another job. When the
+// snapshot fails, s keeps running and preemptSlot reports false.
+func (e *batchEngine) preemptSlot(s *slot) bool {
+	job := s.job
+
+	store, err := newSessionStore(e.model.cfg)
+	if err != nil {
+		e.model.log(job.ctx, "batch-engine", "status", "preempt-failed", "slot", s.id, "id", job.id, "err", err)
+		return false
+	}
+
+	e.model.decodeMu.Lock()
+	llama.Synchronize(e.model.lctx)
+	size := llama.StateSeqGetSize(e.model.lctx, s.seqID)
+	n := llama.StateSeqGetData(e.model.lctx, store.Prepare(int(size)), s.seqID)
+	if size == 0 || n != size {
+		e.model.decodeMu.Unlock()
+		store.Close()
+		e.model.log(job.ctx, "batch-engine", "status", "preempt-failed", "slot", s.id, "id", job.id,
+			"extracted_bytes", n, "expected_bytes", size)
+		return false
+	}
+	llama.MemorySeqRm(e.model.mem, s.seqID, -1, -1)
+	e.model.decodeMu.Unlock()
+	store.Commit(int(n))
+
+	// The job no longer holds a resident sequence, so its IMC session must
+	// not be bound to one either.
+	if job.imcSession != nil {
+		e.model.cacheMu.Lock()
+		if job.imcSession.seqID == s.seqID {
+			job.imcSession.seqID = imcSeqIDUnbound
+		}
+		e.model.cacheMu.Unlock()
+	}
+
+	e.model.log(job.ctx, "request-lifecycle",
+		"stage", 4,
+		"stage_name", "execute-in-slot",
+		"status", "preempted",
+		"id", job.id,
+		"slot", s.id,
+		"seq", s.seqID,
+		"priority", job.schedule.Priority,
+		"tenant", job.schedule.Tenant,
+		"n_past", s.nPast,
+		"kv_bytes", fmtBytes(n),
+	)
+	metrics.AddChatPreemption(e.model.modelInfo.ID, job.schedule.Priority.String())
+
+	s.preempted = true
+	e.slots[s.id] = newSlot(e.model, s.id)
+	e.preempted = append(e.preempted, &preemptedSlot{
+		slot:     s,
+		kv:       store,
+		pausedAt: time.Now(),
+	})
+
+	return true
+}
+
+// nextPreempted returns the index of the paused generation to resume next, or
+// -1 when none is paused: the highest priority, then the longest paused.
+func (e *batchEngine) nextPreempted() int {
+	best := -1
+	for i, p := range e.preempted {
+		if best < 0 || p.slot.job.schedule.Priority > e.preempted[best].slot.job.schedule.Priority {
+			best = i
+		}
+	}
+
+	return best
+}
+
+// resumeSlot restores the paused generation at index i of preempted into the
+// idle slot free and continues it from the token it was about to decode.
+func (e *batchEngine) resumeSlot(free *slot, i int) {
+	p := e.preempted[i]
+	e.preempted = slices.Delete(e.preempted, i, i+1)
+	defer p.kv.Close()
+
+	// The paused slot adopts the identity of the free one, keeping the draft
+	// cache bookkeeping that belongs to the sequence rather than the request.
+	s := p.slot
+	from := s.id
+	s.id = free.id
+	s.seqID = free.seqID
+	s.seqIDs = free.seqIDs
+	s.draftCachedTokens = free.draftCachedTokens
+	s.iBatch = -1
+	s.preempted = false
+	e.slots[s.id] = s
+
+	paused := time.Since(p.pausedAt)
+	if !s.startTime.IsZero() {
+		s.startTime = s.startTime.Add(paused)
+	}
+
+	kv := p.kv.Bytes()
+
+	e.model.decodeMu.Lock()
+	n := llama.StateSeqSetData(e.model.lctx, kv, s.seqID)
+	if n != uint64(len(kv)) {
+		llama.MemorySeqRm(e.model.mem, s.seqID, -1, -1)
+	}
+	e.model.decodeMu.Unlock()
+
+	if n != uint64(len(kv)) {
+		e.finishSlot(s, fmt.Errorf("resume-slot: restore for seq %d read %d bytes, expected %d", s.seqID, n, len(kv)))
+		return
+	}
+
+	e.model.log(s.job.ctx, "request-lifecycle",
+		"stage", 4,
+		"stage_name", "execute-in-slot",
+		"status", "resumed",
+		"id", s.job.id,
+		"slot", s.id,
+		"from_slot", from,
+		"seq", s.seqID,
+		"priority", s.job.schedule.Priority,
+		"n_past", s.nPast,
+		"paused", paused.String(),
+	)
+}
+
+// failPreempted finishes every paused generation whose request was cancelled.
+func (e *batchEngine) failPreempted() {
+	remaining := e.preempted[:0]
+	for _, p := range e.preempted {
+		if err := p.slot.job.ctx.Err(); err != nil {
+			e.finishPreempted(p, err)
+			continue
+		}
+		remaining = append(remaining, p)
+	}
+	clear(e.preempted[len(remaining):])
+	e.preempted = remaining
+}
+
+// finishPreempted ends a paused generation without resuming it.
+func (e *batchEngine) finishPreempted(p *preemptedSlot, err error) {
+	p.kv.Close()
+	e.finishSlot(p.slot, err)
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation"
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+func newPreemptTestEngine(slots ...*slot) *batchEngine {
+	e := batchEngine{
+		model: &Model{},
+		slots: slots,
+	}
+	e.speculation = speculation.NewDisabled(nil)
+
+	return &e
+}
+
+func newPreemptTestSlot(id int, priority Priority, nPast int) *slot {
+	return &slot{
+		id:          id,
+		active:      true,
+		prefillDone: true,
+		nPast:       llama.Pos(nPast),
+		job:         &chatJob{schedule: Schedule{Priority: priority}},
+	}
+}
+
+func TestPreemptionVictim(t *testing.T) {
+	prefilling := newPreemptTestSlot(0, PriorityLow, 10)
+	prefilling.prefillDone = false
+
+	lowLong := newPreemptTestSlot(1, PriorityLow, 900)
+	lowShort := newPreemptTestSlot(2, PriorityLow, 100)
+	normal := newPreemptTestSlot(3, PriorityNormal, 50)
+
+	e := newPreemptTestEngine(prefilling, lowLong, lowShort, normal)
+
+	if got := e.preemptionVictim(PriorityHigh); got != lowShort {
+		t.Errorf("victim for high = slot %v, want slot %d", slotID(got), lowShort.id)
+	}
+	if got := e.preemptionVictim(PriorityNormal); got != lowShort {
+		t.Errorf("victim for normal = slot %v, want slot %d", slotID(got), lowShort.id)
+	}
+	if got := e.preemptionVictim(PriorityLow); got != nil {
+		t.Errorf("victim for low = slot %d, want none", got.id)
+	}
+
+	// Only the normal generation runs below high once the low ones finish.
+	lowLong.active = false
+	lowShort.active = false
+	if got := e.preemptionVictim(PriorityHigh); got != normal {
+		t.Errorf("victim for high = slot %v, want slot %d", slotID(got), normal.id)
+	}
+}
+
+func TestCanPreemptSkipsUnsafeSlots(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(s *slot)
+	}{
+		{name: "idle", setup: func(s *slot) { s.active = false }},
+		{name: "prefilling", setup: func(s *slot) { s.prefillDone = false }},
+		{name: "imc preparation", setup: func(s *slot) { s.imcPrep = &imcPreparation{} }},
+		{name: "media prefill", setup: func(s *slot) { s.mediaPrefilling = true }},
+		{name: "shared prefix wait", setup: func(s *slot) { s.prefixTokens = []llama.Token{1} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newPreemptTestSlot(0, PriorityLow, 10)
+			tt.setup(s)
+
+			e := newPreemptTestEngine(s)
+			if e.canPreempt(s) {
+				t.Error("canPreempt = true, want false")
+			}
+		})
+	}
+
+	s := newPreemptTestSlot(0, PriorityLow, 10)
+	if e := newPreemptTestEngine(s); !e.canPreempt(s) {
+		t.Error("canPreempt for a generating slot = false, want true")
+	}
+}
+
+func TestNextPreempted(t *testing.T) {
+	e := newPreemptTestEngine()
+	if got := e.nextPreempted(); got != -1 {
+		t.Fatalf("nextPreempted with none paused = %d, want -1", got)
+	}
+
+	for _, priority := range []Priority{PriorityLow, PriorityNormal, PriorityLow, PriorityNormal} {
+		e.preempted = append(e.preempted, &preemptedSlot{slot: newPreemptTestSlot(0, priority, 10)})
+	}
+
+	// The first paused normal generation resumes before the later one and
+	// before any low generation.
+	if got := e.nextPreempted(); got != 1 {
+		t.Errorf("nextPreempted = %d, want 1", got)
+	}
+}
+
+func slotID(s *slot) any {
+	if s == nil {
+		return nil
+	}
+	return s.id
+}
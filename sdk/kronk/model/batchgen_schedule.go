@@ -1,5 +1,11 @@
 package model
 
+import (
+	"slices"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/observ/metrics"
+)
+
 // hasActiveSlots returns true if any generation slot is currently processing.
 func (e *batchEngine) hasActiveSlots() bool {
 	for _, s := range e.slots {
@@ -16,6 +22,15 @@ func (e *batchEngine) hasActiveSlots() bool {
 // restored from RAM via StateSeqSetData. Non-IMC jobs also use
 // first-available.
 //
+// When more jobs are pending than slots are free, the fair queue picks the
+// order: higher priority lanes first, then the tenant furthest behind its
+// fair share within the lane. A tenant's own jobs keep their arrival order.
+//
+// When every slot is busy, a pending job may preempt a generation running at
+// a lower priority: the generation is paused with its sequence state spilled
+// to a session store and resumes, ahead of pending jobs of the same or lower
+// priority, once a slot frees up.
+//
 // Jobs that can't be assigned yet are held in pendingJobs (engine-local
 // slice) rather than re-queued into requestQ, which would risk deadlocking
 // the batch engine goroutine.
@@ -24,12 +39,15 @@ func (e *batchEngine) fillSlots(buf []byte) {
 	for {
 		select {
 		case job := <-e.requestQ:
+			e.fair.enqueue(job)
 			e.pendingJobs = append(e.pendingJobs, job)
 			e.model.log(job.ctx, "request-lifecycle",
 				"stage", 3,
 				"stage_name", "schedule-job",
 				"status", "queued",
 				"id", job.id,
+				"priority", job.schedule.Priority,
+				"tenant", job.schedule.Tenant,
 				"pending_jobs", len(e.pendingJobs),
 			)
 		default:
@@ -38,28 +56,113 @@ func (e *batchEngine) fillSlots(buf []byte) {
 	}
 
 assign:
-	// Try to assign pending jobs to available slots.
+	// Fail cancelled jobs before they can be picked.
 	remaining := e.pendingJobs[:0]
 	for _, job := range e.pendingJobs {
 		if job.ctx.Err() != nil {
 			e.failJob(job, job.ctx.Err())
 			continue
 		}
+		remaining = append(remaining, job)
+	}
+	clear(e.pendingJobs[len(remaining):])
+	e.pendingJobs = remaining
+	e.failPreempted()
+
+	// Assign paused and pending jobs while slots are free, preempting lower
+	// priority generations for pending jobs when none are.
+	for len(e.pendingJobs) > 0 || len(e.preempted) > 0 {
+		i := e.fair.next(e.pendingJobs)
+		p := e.nextPreempted()
+		resume := p >= 0 && (i < 0 || e.preempted[p].slot.job.schedule.Priority >= e.pendingJobs[i].schedule.Priority)
 
-		assigned := false
+		job := e.preempted[max(p, 0)].slot.job
+		if !resume {
+			job = e.pendingJobs[i]
+		}
 
-		for _, s := range e.slots {
-			if s.active || e.batchReleased[s.id] {
+		// A job for another adapter set waits until the running generations
+		// drain and the context can switch to its set. Jobs for the current
+		// set may start meanwhile.
+		admit, err := e.admitAdapters(job)
+		if err != nil {
+			if resume {
+				paused := e.preempted[p]
+				e.preempted = slices.Delete(e.preempted, p, p+1)
+				e.finishPreempted(paused, err)
 				continue
 			}
-			e.startSlot(s, job, buf)
-			assigned = true
-			break
+			e.pendingJobs = slices.Delete(e.pendingJobs, i, i+1)
+			e.fair.dequeue(job)
+			e.failJob(job, err)
+			continue
+		}
+		switching := !admit
+		if switching {
+			if i = e.nextForAdapterSwitch(job); i < 0 {
+				break
+			}
+			resume = false
+			job = e.pendingJobs[i]
+		}
+
+		s := e.freeSlot()
+		if s == nil {
+			if i < 0 || !e.preemptFor(e.pendingJobs[i].schedule.Priority) {
+				break
+			}
+			if s = e.freeSlot(); s == nil {
+				break
+			}
+		}
+
+		if resume {
+			e.resumeSlot(s, p)
+			continue
+		}
+
+		e.pendingJobs = slices.Delete(e.pendingJobs, i, i+1)
+		e.fair.dequeue(job)
+
+		e.startSlot(s, job, buf)
+
+		if switching {
+			e.adapterSwitchAdmitted++
+		}
+	}
+
+	e.publishQueueDepth()
+}
+
+// freeSlot returns the first slot that can take a new job, or nil.
+func (e *batchEngine) freeSlot() *slot {
+	for _, s := range e.slots {
+		if !s.active && !e.batchReleased[s.id] {
+			return s
+		}
+	}
+	return nil
+}
+
+// publishQueueDepth reports the number of pending and paused jobs in each
+// priority lane when it changed since the last call.
+func (e *batchEngine) publishQueueDepth() {
+	for i, priority := range Priorities {
+		var n int
+		for _, job := range e.pendingJobs {
+			if job.schedule.Priority == priority {
+				n++
+			}
+		}
+		for _, p := range e.preempted {
+			if p.slot.job.schedule.Priority == priority {
+				n++
+			}
 		}
 
-		if !assigned {
-			remaining = append(remaining, job)
+		if n != e.queueDepth[i] {
+			e.queueDepth[i] = n
+			metrics.SetChatQueueDepth(e.model.modelInfo.ID, priority.String(), n)
 		}
 	}
-	e.pendingJobs = remaining
 }
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+// canSharePrefix reports whether the additional choices of an n > 1 request
+// may copy prompt KV from their prefix source. A draft model or MTP head keeps
+// per-slot draft state that a KV copy does not reproduce, and hybrid models
+// carry recurrent state, so those choices prefill the prompt themselves.
+// N-gram drafting seeds each slot's history from the prompt at admission and
+// shares prefixes like target-only generation.
+func (e *batchEngine) canSharePrefix() bool {
+	return e.model.draft == nil && e.model.modelInfo.Type != ModelTypeHybrid
+}
+
+// prefixSourceSlot returns the active slot running the prefix source of the
+// choice in s, or nil when that job is not executing.
+func (e *batchEngine) prefixSourceSlot(s *slot) *slot {
+	for _, source := range e.slots {
+		if source.active && source != s && source.job == s.job.prefixSource {
+			return source
+		}
+	}
+
+	return nil
+}
+
+// shareSlotPrefix copies the prompt KV of source, which has just completed
+// prefill, into every choice slot waiting on it and samples each choice's
+// first token from the same logits row. The copy happens before source's
+// first generated token is decoded, so each destination sequence holds
+// exactly the prompt.
+func (e *batchEngine) shareSlotPrefix(source *slot, iBatch int32, buf []byte) {
+	for _, s := range e.slots {
+		if s == source || !s.active || s.prefixTokens == nil || s.job.prefixSource != source.job {
+			continue
+		}
+
+		if source.useMRoPE || len(s.prefixTokens) != source.nPrompt || int(source.nPast) != source.nPrompt {
+			e.abandonSharedPrefix(s, "prompt-mismatch")
+			continue
+		}
+
+		// Copy the complete source sequence. Slots without a unified KV
+		// cache live in separate streams, and llama.cpp only copies those
+		// streams whole.
+		e.model.decodeMu.Lock()
+		err := llama.MemorySeqCp(e.model.mem, source.seqID, s.seqID, -1, -1)
+		e.model.decodeMu.Unlock()
+		if err != nil {
+			e.abandonSharedPrefix(s, err.Error())
+			continue
+		}
+
+		s.prefixTokens = nil
+		s.nPast = source.nPast
+		s.nPrompt = source.nPrompt
+
+		e.model.log(s.job.ctx, "batch-engine", "status", "shared-prefix-copied",
+			"slot", s.id, "seq", s.seqID, "id", s.job.id, "choice", s.job.choiceIndex,
+			"source_slot", source.id, "source_seq", source.seqID, "prompt_tokens", s.nPrompt)
+
+		token := e.sampleSlotToken(s, iBatch)
+		e.handleSampledToken(s, token, iBatch, buf)
+	}
+}
+
+// releaseSharedPrefixWaits starts an ordinary prefill for every waiting choice
+// whose prefix source can no longer share its prompt KV. The source may have
+// finished or failed before prefill completed, or produced its first token
+// through a path that does not copy.
+func (e *batchEngine) releaseSharedPrefixWaits() {
+	for _, s := range e.slots {
+		if !s.active || s.prefixTokens == nil {
+			continue
+		}
+
+		if err := s.job.ctx.Err(); err != nil {
+			e.finishSlot(s, err)
+			continue
+		}
+
+		if source := e.prefixSourceSlot(s); source != nil && !source.prefillDone {
+			continue
+		}
+
+		e.abandonSharedPrefix(s, "source-unavailable")
+	}
+}
+
+// abandonSharedPrefix moves a waiting choice onto the ordinary prefill path.
+func (e *batchEngine) abandonSharedPrefix(s *slot, reason string) {
+	e.model.log(s.job.ctx, "batch-engine", "status", "shared-prefix-abandoned",
+		"slot", s.id, "id", s.job.id, "choice", s.job.choiceIndex, "reason", reason)
+
+	s.prefillTokens = s.prefixTokens
+	s.nPrefilled = 0
+	s.prefixTokens = nil
+}
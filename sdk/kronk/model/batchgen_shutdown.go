@@ -20,7 +20,7 @@ func (e *batchEngine) drainSlots() {
 	pendingCount := len(e.requestQ) + len(e.pendingJobs)
 
 	e.model.log(ctx, "batch-engine", "status", "drain-started", "active_slots", activeCount,
-		"pending_jobs", pendingCount)
+		"preempted_slots", len(e.preempted), "pending_jobs", pendingCount)
 
 	for _, s := range e.slots {
 		if s.active {
@@ -28,11 +28,18 @@ func (e *batchEngine) drainSlots() {
 		}
 	}
 
+	// Finish generations that were paused for a higher priority job.
+	for _, p := range e.preempted {
+		e.finishPreempted(p, shutdownErr)
+	}
+	e.preempted = nil
+
 	// Fail pending jobs that were dequeued but not yet assigned to a slot.
 	for _, job := range e.pendingJobs {
 		e.failJob(job, shutdownErr)
 	}
 	e.pendingJobs = nil
+	e.publishQueueDepth()
 
 	// Drain pending jobs still in the request queue.
 	drained := 0
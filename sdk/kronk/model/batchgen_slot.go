@@ -8,6 +8,7 @@ import (
 
 	classicengine "github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/classic"
 	mtpengine "github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/mtp"
+	ngramengine "github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/ngram"
 	"github.com/hybridgroup/yzma/pkg/llama"
 	"github.com/hybridgroup/yzma/pkg/mtmd"
 	"go.opentelemetry.io/otel/trace"
@@ -26,6 +27,10 @@ type chatJob struct {
 	queueWaitSpan trace.Span          // Span covering time spent waiting in the queue
 	queuedAt      time.Time           // Time when the job was submitted to the queue
 	requestStart  time.Time           // Time when the request entered the SDK (for end-to-end TTFT)
+	choiceIndex   int                 // Choice index reported in responses when a request asks for n > 1
+	prefixSource  *chatJob            // Choice whose prefilled prompt KV this choice copies instead of prefilling
+	schedule      Schedule            // Priority lane and tenant used to order the job against other pending jobs
+	fairStart     float64             // Virtual start time assigned by the fair queue when the job was queued
 
 	// -------------------------------------------------------------------------
 	// Request Content
@@ -33,8 +38,10 @@ type chatJob struct {
 	d                   D             // Original request document (messages, parameters)
 	object              string        // Request type: ObjectChatText or ObjectChatMedia
 	prompt              string        // Templated prompt string ready for tokenization
+	raw                 bool          // Raw completion: the prompt skipped the chat template and output skips the parser
 	media               [][]byte      // Raw media bytes (images/audio) for vision/audio models
 	params              Params        // Sampling and generation parameters
+	adapters            adapterSet    // LoRA adapters the context must apply while the job runs
 	textTokens          []llama.Token // Complete text prompt tokenized during synchronous request preparation.
 	samplerPromptTokens []llama.Token // Complete logical text-token prompt used to prime the request sampler.
 	tailTokens          []llama.Token // Non-empty inference tail after the stable cached target.
@@ -87,6 +94,17 @@ type chatJob struct {
 	imcMediaNativeChunks  []imcMediaChunk // Authoritative mtmd chunk stream for validating a media append.
 }
 
+// classify routes decoded output through the slot's parser state machine. A
+// raw completion has no template framing to parse, so all of its output is
+// answer content.
+func (s *slot) classify(content string) (Result, bool) {
+	if s.job.raw {
+		return Result{Channel: ChannelAnswer, Content: content}, false
+	}
+
+	return s.stateMachine.Classify(content)
+}
+
 func (j *chatJob) hasIMCReservation() bool {
 	return j != nil && j.imcSession != nil && j.imcReservationHeld
 }
@@ -114,6 +132,7 @@ type slot struct {
 	seqIDs       []llama.SeqId // Pre-allocated slice for batch.Add calls
 	job          *chatJob      // Current request being processed
 	active       bool          // True when slot is processing a request
+	preempted    bool          // True while the job is paused and its sequence state lives in a preemption store instead of the KV cache
 	span         trace.Span    // OpenTelemetry span for request tracing
 	stateMachine StateMachine  // Per-slot state machine; created from m.parser.NewStateMachine()
 
@@ -142,6 +161,7 @@ type slot struct {
 	prefillDone   bool            // True when prefill complete, generation started
 	imcPrep       *imcPreparation // Resumable text IMC build or extension state
 	imcRestoring  bool            // True while session sequence state is restored into this slot
+	prefixTokens  []llama.Token   // Prompt held while waiting to copy the prefix source's KV; prefilled if the copy is abandoned
 
 	// -------------------------------------------------------------------------
 	// MTMD Prefill (vision/audio requests)
@@ -168,6 +188,7 @@ type slot struct {
 	finalTooling     strings.Builder // Accumulated tool call JSON
 	rawOutput        strings.Builder // Decoded model output retained for insecure logging
 	respToolCalls    []ResponseToolCall
+	streamedToolArgs map[int]string // Tool-call argument text streamed per call index
 	finishReason     string
 	stopSource       string
 	utf8Buf          []byte // Buffered bytes from partial multi-byte UTF-8 codepoints
@@ -207,6 +228,10 @@ type slot struct {
 	// state. The engine stores it without duplicating those fields in slot.
 	mtp mtpengine.SlotState
 
+	// ngram holds the prompt and output tokens n-gram drafting searches. It is
+	// only filled when the model speculates in ngram mode.
+	ngram ngramengine.SlotState
+
 	// specSnapshot holds a snapshot of the target context's per-sequence
 	// state taken right before a speculative batch is decoded. It is
 	// required for HYBRID target models (transformer + recurrent layers):
@@ -276,6 +301,7 @@ func (s *slot) reset() {
 	s.finalTooling.Reset()
 	s.rawOutput.Reset()
 	s.respToolCalls = nil
+	s.streamedToolArgs = nil
 	s.finishReason = ""
 	s.stopSource = ""
 	s.utf8Buf = s.utf8Buf[:0]
@@ -285,9 +311,11 @@ func (s *slot) reset() {
 	s.iBatch = -1
 	s.sampled = 0
 	s.active = false
+	s.preempted = false
 	s.prefillDone = false
 	s.prefillTokens = nil
 	s.nPrefilled = 0
+	s.prefixTokens = nil
 	s.imcPrep = nil
 	s.imcRestoring = false
 	s.logprobsData = nil
@@ -317,6 +345,7 @@ func (s *slot) reset() {
 	// Note: draftCachedTokens persists across requests for incremental draft KV reuse.
 
 	s.mtp.Reset()
+	s.ngram.Reset()
 	if s.draftSampler != 0 {
 		llama.SamplerFree(s.draftSampler)
 		s.draftSampler = 0
@@ -32,6 +32,9 @@ func (e *batchEngine) startSlot(s *slot, job *chatJob, buf []byte) {
 		tools, _ := job.d["tools"].([]D)
 		stateMachine.SetTools(tools)
 	}
+	if stateMachine, ok := s.stateMachine.(ToolCallArgumentStreamer); ok && job.params.Stream && !s.suppressTools && !job.raw {
+		stateMachine.StreamToolCallArguments()
+	}
 
 	// If the rendered prompt ends with a reasoning opener followed by any
 	// trailing whitespace, the template has already opened a reasoning block.
@@ -48,8 +51,10 @@ func (e *batchEngine) startSlot(s *slot, job *chatJob, buf []byte) {
 	// Skip reasoning mode when grammar is specified — grammar constrains
 	// the output format, so free-form thinking is counterproductive and
 	// would consume max_tokens before producing any constrained content.
+	//
+	// Raw completions bypass the parser, so their prompts are never primed.
 	trimmedPrompt := strings.TrimRight(job.prompt, " \t\r\n")
-	if (strings.HasSuffix(trimmedPrompt, "<think>") ||
+	if !job.raw && (strings.HasSuffix(trimmedPrompt, "<think>") ||
 		strings.HasSuffix(trimmedPrompt, "<|open|>think<|sep|>")) && job.params.Grammar == "" {
 		// Drive the state machine into reasoning mode by feeding the same
 		// marker the model would have emitted. Parsers that recognize
@@ -73,7 +78,7 @@ func (e *batchEngine) startSlot(s *slot, job *chatJob, buf []byte) {
 	}
 	if !job.queuedAt.IsZero() {
 		queueWait = time.Since(job.queuedAt)
-		metrics.ObserveChatQueueWait(e.model.modelInfo.ID, queueWait)
+		metrics.ObserveChatQueueWait(e.model.modelInfo.ID, job.schedule.Priority.String(), queueWait)
 	}
 	e.model.log(job.ctx, "request-lifecycle",
 		"stage", 3,
@@ -81,6 +86,8 @@ func (e *batchEngine) startSlot(s *slot, job *chatJob, buf []byte) {
 		"status", "complete",
 		"id", job.id,
 		"slot", s.id,
+		"priority", job.schedule.Priority,
+		"tenant", job.schedule.Tenant,
 		"queue_wait", queueWait.String(),
 	)
 
@@ -1018,6 +1025,11 @@ func (e *batchEngine) startSlotText(s *slot, job *chatJob, cacheIdx llama.Pos) b
 	}
 	primeSampler(s.sampler, samplerTokens, job.params)
 
+	// N-gram drafting copies continuations from the same logical prompt.
+	if e.model.cfg.speculationPlan.Mode == SpeculationNGram {
+		s.ngram.Begin(samplerTokens)
+	}
+
 	// Store full prompt tokens for draft model prefill if speculative decoding
 	// is enabled. The draft model needs all tokens (cached + new suffix) to
 	// build its KV cache after the target's prefill completes. Reuses the
@@ -1108,6 +1120,13 @@ func (e *batchEngine) startSlotText(s *slot, job *chatJob, cacheIdx llama.Pos) b
 		s.draftPrefillNeeded = true
 	}
 
+	// An additional choice of an n > 1 request waits for its prefix source
+	// to prefill the identical prompt and copies that KV instead.
+	if job.prefixSource != nil && cacheIdx == 0 && e.canSharePrefix() {
+		s.prefixTokens = tokens
+		return true
+	}
+
 	// Store tokens for chunked prefill.
 	s.prefillTokens = tokens
 	s.nPrefilled = 0
@@ -1287,6 +1306,9 @@ func (e *batchEngine) applyContextTokenBudget(s *slot, operation string) bool {
 	}
 
 	requestedMaxTokens := s.job.params.MaxTokens
+	if e.canShiftContext(s) {
+		effectiveMaxTokens = requestedMaxTokens
+	}
 	s.job.params.MaxTokens = effectiveMaxTokens
 	if effectiveMaxTokens < requestedMaxTokens {
 		e.model.log(s.job.ctx, operation,
@@ -2,11 +2,13 @@ package model
 
 import (
 	"context"
+	"slices"
 	"testing"
 	"time"
 
 	classicengine "github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/classic"
 	mtpengine "github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/mtp"
+	ngramengine "github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/ngram"
 	"github.com/hybridgroup/yzma/pkg/llama"
 )
 
@@ -23,6 +25,9 @@ func TestSlotResetClearsPerRequestLifecycleState(t *testing.T) {
 			AcceptanceEMA: 0.17,
 			ProbeTick:     31,
 		},
+		ngram: ngramengine.SlotState{
+			History: []llama.Token{1, 2, 3},
+		},
 	}
 
 	s.reset()
@@ -48,6 +53,9 @@ func TestSlotResetClearsPerRequestLifecycleState(t *testing.T) {
 	if s.classic.ProbeTick != 0 {
 		t.Errorf("classic.ProbeTick = %d, want 0", s.classic.ProbeTick)
 	}
+	if len(s.ngram.History) != 0 {
+		t.Errorf("len(ngram.History) = %d, want 0", len(s.ngram.History))
+	}
 }
 
 func TestNeedsTargetSpecSnapshot(t *testing.T) {
@@ -111,6 +119,50 @@ func TestMaxDraftForSlot(t *testing.T) {
 	}
 }
 
+func TestNGramGenerationInput(t *testing.T) {
+	cfg := Config{PtrContextWindow: new(100)}
+	cfg.speculationPlan = speculationPlan{Mode: SpeculationNGram, Source: speculationSourceNGram, NDraft: 3, Available: true}
+
+	generating := &slot{
+		id:          0,
+		prefillDone: true,
+		nPast:       6,
+		job:         &chatJob{params: Params{MaxTokens: 100}},
+		classic:     classicengine.SlotState{AcceptanceEMA: 1},
+	}
+	generating.ngram.Begin([]llama.Token{1, 2, 3, 4, 5, 1})
+	generating.ngram.Append(2)
+
+	prefilling := &slot{
+		id:      1,
+		job:     &chatJob{params: Params{MaxTokens: 100}},
+		classic: classicengine.SlotState{AcceptanceEMA: 1},
+	}
+	prefilling.ngram.Begin([]llama.Token{1, 2, 3, 1, 2})
+
+	e := batchEngine{model: &Model{cfg: cfg}, slots: []*slot{generating, prefilling}}
+
+	input, err := e.NGramGenerationInput(0)
+	if err != nil {
+		t.Fatalf("NGramGenerationInput() error = %v, want nil", err)
+	}
+	result, err := classicengine.Generate(input)
+	if err != nil {
+		t.Fatalf("Generate() error = %v, want nil", err)
+	}
+	if want := []llama.Token{3, 4, 5}; !slices.Equal(result.Candidates, want) {
+		t.Errorf("Candidates = %v, want %v", result.Candidates, want)
+	}
+
+	input, err = e.NGramGenerationInput(1)
+	if err != nil {
+		t.Fatalf("NGramGenerationInput() error = %v, want nil", err)
+	}
+	if input.MaxDraft != 0 {
+		t.Errorf("MaxDraft before the first token = %d, want 0", input.MaxDraft)
+	}
+}
+
 func TestContextOutputBudget(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -169,6 +221,49 @@ func TestApplyContextTokenBudgetClampsRequest(t *testing.T) {
 	}
 }
 
+func TestApplyContextTokenBudgetWithContextShift(t *testing.T) {
+	contextWindow := 8192
+	e := batchEngine{
+		model: &Model{
+			cfg:          Config{PtrContextWindow: &contextWindow},
+			log:          noopLog,
+			contextShift: true,
+		},
+	}
+
+	tests := []struct {
+		name          string
+		object        string
+		useMRoPE      bool
+		wantMaxTokens int
+	}{
+		{name: "text keeps requested budget", object: ObjectChatText, wantMaxTokens: 2048},
+		{name: "media is clamped", object: ObjectChatMedia, wantMaxTokens: 1192},
+		{name: "mrope is clamped", object: ObjectChatText, useMRoPE: true, wantMaxTokens: 1192},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := slot{
+				nPrompt:  7000,
+				useMRoPE: tt.useMRoPE,
+				job: &chatJob{
+					ctx:    context.Background(),
+					object: tt.object,
+					params: Params{MaxTokens: 2048},
+				},
+			}
+
+			if !e.applyContextTokenBudget(&s, "start-slot") {
+				t.Fatal("applyContextTokenBudget() = false, want true")
+			}
+			if s.job.params.MaxTokens != tt.wantMaxTokens {
+				t.Errorf("MaxTokens = %d, want %d", s.job.params.MaxTokens, tt.wantMaxTokens)
+			}
+		})
+	}
+}
+
 func TestValidMTPDraftState(t *testing.T) {
 	tests := []struct {
 		name          string
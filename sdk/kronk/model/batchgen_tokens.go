@@ -10,9 +10,14 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 )
 
-// processSlotToken samples and processes a generated token for a slot.
+// processSlotToken samples and processes a generated token for a slot. The
+// first token after prefill also hands the prompt KV to choices waiting to
+// share it, before the slot can finish and clear its sequence.
 func (e *batchEngine) processSlotToken(s *slot, buf []byte) {
 	token := e.sampleSlotToken(s, s.iBatch)
+	if !s.prefillDone {
+		e.shareSlotPrefix(s, s.iBatch, buf)
+	}
 	e.handleSampledToken(s, token, s.iBatch, buf)
 }
 
@@ -71,7 +76,7 @@ func (e *batchEngine) handleToken(s *slot, token llama.Token, iBatch int32, buf
 
 	// Check for end of generation.
 	if llama.VocabIsEOG(e.model.vocab, token) {
-		if consumer, ok := s.stateMachine.(VocabEOGConsumer); ok {
+		if consumer, ok := s.stateMachine.(VocabEOGConsumer); ok && !s.job.raw {
 			l := llama.TokenToPiece(e.model.vocab, token, buf, 0, true)
 			consumer.ConsumeVocabEOG(string(buf[:l]))
 		}
@@ -107,6 +112,9 @@ func (e *batchEngine) handleToken(s *slot, token llama.Token, iBatch int32, buf
 	}
 
 	s.sampled = token
+	if e.model.cfg.speculationPlan.Mode == SpeculationNGram {
+		s.ngram.Append(token)
+	}
 
 	if !s.prefillDone {
 		s.prefillDone = true
@@ -261,7 +269,7 @@ func (e *batchEngine) processDecodedPiece(s *slot, piece stopPiece, logprobIndex
 		s.rawOutput.WriteString(piece.content)
 	}
 
-	result, eog := s.stateMachine.Classify(piece.content)
+	result, eog := s.classify(piece.content)
 	if s.suppressTools && result.Channel == ChannelTool {
 		result.Channel = ChannelAnswer
 	}
@@ -288,11 +296,17 @@ func (e *batchEngine) processDecodedPiece(s *slot, piece stopPiece, logprobIndex
 		e.model.log(s.job.ctx, "chat-completion", args...)
 	}
 
-	if streamer, ok := s.stateMachine.(ToolCallDeltaStreamer); ok && !s.suppressTools {
+	if streamer, ok := s.stateMachine.(ToolCallDeltaStreamer); ok && !s.suppressTools && !s.job.raw {
 		deltas := streamer.ToolCallDeltas()
 		if s.job.params.Stream {
 			for _, delta := range deltas {
-				if err := e.model.sendToolCallDeltaResponse(s.job.ctx, s.job.ch, s.job.id, s.job.object, 0, delta); err != nil {
+				if delta.ID == "" && delta.Function.Arguments != "" {
+					if s.streamedToolArgs == nil {
+						s.streamedToolArgs = make(map[int]string)
+					}
+					s.streamedToolArgs[delta.Index] += delta.Function.Arguments
+				}
+				if err := e.model.sendToolCallDeltaResponse(s.job.ctx, s.job.ch, s.job.id, s.job.object, s.job.choiceIndex, delta); err != nil {
 					return decodedPieceOutcome{err: err}
 				}
 			}
@@ -391,7 +405,7 @@ func reconcileStopPiece(s *slot, piece stopPiece) {
 // retainAndStreamResult applies response cleanup once, before both the final
 // accumulators and streaming deltas consume the content.
 func (e *batchEngine) retainAndStreamResult(s *slot, result Result, outputTokens int, logprob *ContentLogprob, logprobIndex int) error {
-	if result.Channel != ChannelTool && e.model.isUnnecessaryCRLF(s.reasonFlag, s.completionFlag, result.Content) {
+	if result.Channel != ChannelTool && !s.job.raw && e.model.isUnnecessaryCRLF(s.reasonFlag, s.completionFlag, result.Content) {
 		if logprob != nil && logprobIndex >= 0 && logprobIndex < len(s.logprobsData) {
 			s.logprobsData = append(s.logprobsData[:logprobIndex], s.logprobsData[logprobIndex+1:]...)
 			s.currentLogprob = nil
@@ -412,7 +426,7 @@ func (e *batchEngine) retainAndStreamResult(s *slot, result Result, outputTokens
 	}
 
 	// Per OpenAI spec, usage is only sent in the final response, not deltas.
-	return e.model.sendDeltaResponse(s.job.ctx, s.job.ch, s.job.id, s.job.object, 0, result.Content, result.Channel, s.reasonTokens, outputTokens, logprob)
+	return e.model.sendDeltaResponse(s.job.ctx, s.job.ch, s.job.id, s.job.object, s.job.choiceIndex, result.Content, result.Channel, s.reasonTokens, outputTokens, logprob)
 }
 
 func updateSlotChannel(s *slot, channel Channel) {
@@ -28,6 +28,7 @@ const (
 // IMCSessionDetail is a scalar snapshot of one allocated IMC cache entry.
 type IMCSessionDetail struct {
 	ID             int
+	CacheKey       string
 	State          IMCSessionState
 	Context        int
 	Allocated      int
@@ -69,6 +70,7 @@ func (m *Model) IMCSessions() []IMCSessionDetail {
 
 		details = append(details, IMCSessionDetail{
 			ID:             session.id,
+			CacheKey:       session.cacheKey,
 			State:          state,
 			Context:        context,
 			Allocated:      allocated,
@@ -88,6 +90,27 @@ func (m *Model) IMCSessions() []IMCSessionDetail {
 	return details
 }
 
+// maxCacheKeyLen bounds a client-supplied prompt_cache_key.
+const maxCacheKeyLen = 256
+
+// parseCacheKey returns the client-supplied prompt_cache_key. A keyed request
+// only reuses sessions committed under the same key, so clients that share a
+// system prompt do not compete for one session. An omitted or null key
+// returns "" and keeps prefix-only matching.
+func parseCacheKey(d D) (string, error) {
+	val, exists := d["prompt_cache_key"]
+	if !exists || val == nil {
+		return "", nil
+	}
+
+	key, ok := val.(string)
+	if !ok || len(key) > maxCacheKeyLen {
+		return "", fmt.Errorf("%w: prompt_cache_key must be a string of at most %d bytes", ErrInvalidRequest, maxCacheKeyLen)
+	}
+
+	return key, nil
+}
+
 func imcSnapshotBytes(targetStore, draftStore SessionStore, pendingH []float32) int {
 	bytes := cap(pendingH) * 4
 	if targetStore != nil {
@@ -224,6 +247,8 @@ func imcResetCurrentSession(s *imcSession) {
 // (write lock).
 func imcResetSession(s *imcSession) {
 	s.seqID = imcSeqIDUnbound
+	s.cacheKey = ""
+	s.adapterKey = ""
 	s.usageVersion++
 	imcResetCurrentSession(s)
 	s.inputMessages = 0
@@ -235,6 +260,21 @@ func imcResetSession(s *imcSession) {
 	s.reserved = false
 }
 
+// imcRebuildSession chooses the session a rebuild overwrites. A keyed request
+// rebuilds its own least recently used session in place so it never evicts
+// another client's cache while it still owns one; otherwise an empty session
+// is preferred over the global LRU. The caller must hold m.cacheMu.
+func imcRebuildSession(keyed, empty, lru *imcSession) *imcSession {
+	switch {
+	case keyed != nil:
+		return keyed
+	case empty != nil:
+		return empty
+	default:
+		return lru
+	}
+}
+
 // imcReleaseReservation clears a session's reserved flag.
 // Safe to call even if the session wasn't reserved. sessionID is the
 // session-pool index (imcSession.id), not an execution slot id; the
@@ -0,0 +1,291 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+// ErrIMCSessionNotFound indicates that an IMC session does not exist or holds
+// no snapshot to export.
+var ErrIMCSessionNotFound = errors.New("imc session not found")
+
+// ErrIMCSessionBusy indicates that an IMC session is reserved by an in-flight
+// request, or that no session is free to receive an import.
+var ErrIMCSessionBusy = errors.New("imc session busy")
+
+// imcExportMagic opens every exported session file. The version that follows
+// it changes whenever the header or payload layout changes.
+var imcExportMagic = [8]byte{'K', 'R', 'O', 'N', 'K', 'I', 'M', 'C'}
+
+const (
+	imcExportVersion = 1
+
+	// imcExportMaxHeader bounds the JSON header so a corrupt length cannot
+	// force a huge allocation before the header is validated.
+	imcExportMaxHeader = 64 << 20
+)
+
+// imcExportHeader describes an exported session. The target and draft
+// SessionStore bytes follow it in that order.
+type imcExportHeader struct {
+	Model          string        `json:"model"`
+	ModelSize      uint64        `json:"model_size"`
+	CacheTypeK     string        `json:"cache_type_k"`
+	CacheTypeV     string        `json:"cache_type_v"`
+	CacheKey       string        `json:"cache_key,omitempty"`
+	AdapterKey     string        `json:"adapter_key,omitempty"`
+	CachedTokens   []llama.Token `json:"cached_tokens"`
+	CachedMessages int           `json:"cached_messages"`
+	MessagesHash   string        `json:"messages_hash"`
+	RenderHash     string        `json:"render_hash,omitempty"`
+	SamplingSeed   *uint32       `json:"sampling_seed,omitempty"`
+	PendingH       []float32     `json:"pending_h,omitempty"`
+	TargetBytes    int           `json:"target_bytes"`
+	DraftBytes     int           `json:"draft_bytes"`
+}
+
+// ExportIMCSession writes the snapshot and metadata of the IMC session with
+// the given id to w in a format ImportIMCSession can restore on the same
+// model, in this process or another one. The session is reserved while it is
+// written, so requests that would reuse it rebuild elsewhere until the export
+// finishes. Media sessions cannot be exported.
+func (m *Model) ExportIMCSession(w io.Writer, id int) error {
+	m.cacheMu.Lock()
+	if id < 0 || id >= len(m.imcSessions) {
+		m.cacheMu.Unlock()
+		return fmt.Errorf("export-imc-session: %w: id %d", ErrIMCSessionNotFound, id)
+	}
+
+	session := m.imcSessions[id]
+	switch {
+	case session.reserved:
+		m.cacheMu.Unlock()
+		return fmt.Errorf("export-imc-session: %w: id %d", ErrIMCSessionBusy, id)
+	case session.totalTokensCached == 0 || session.kvState == nil || session.kvState.Len() == 0:
+		m.cacheMu.Unlock()
+		return fmt.Errorf("export-imc-session: %w: id %d has no snapshot", ErrIMCSessionNotFound, id)
+	case session.hasMedia:
+		m.cacheMu.Unlock()
+		return fmt.Errorf("export-imc-session: %w: id %d caches media", ErrInvalidRequest, id)
+	}
+
+	session.reserved = true
+	hdr := imcExportHeader{
+		Model:          m.modelInfo.ID,
+		ModelSize:      m.modelInfo.Size,
+		CacheTypeK:     m.cfg.CacheTypeK.String(),
+		CacheTypeV:     m.cfg.CacheTypeV.String(),
+		CacheKey:       session.cacheKey,
+		AdapterKey:     session.adapterKey,
+		CachedTokens:   session.cachedTokens,
+		CachedMessages: session.cachedMsgCount,
+		MessagesHash:   session.cachedMsgsHash,
+		RenderHash:     session.cachedRenderInputHash,
+		PendingH:       session.pendingH,
+	}
+	if session.hasSamplingSeed {
+		hdr.SamplingSeed = new(session.samplingSeed)
+	}
+	m.cacheMu.Unlock()
+
+	defer m.imcReleaseReservation(id)
+
+	// The reservation keeps writers away from the stores until it is
+	// released, so the bytes can be read without holding cacheMu.
+	target := session.kvState.Bytes()
+	var draft []byte
+	if session.draftKVState != nil {
+		draft = session.draftKVState.Bytes()
+	}
+	hdr.TargetBytes = len(target)
+	hdr.DraftBytes = len(draft)
+
+	if len(target) == 0 {
+		return fmt.Errorf("export-imc-session: %w: id %d has no snapshot", ErrIMCSessionNotFound, id)
+	}
+
+	data, err := json.Marshal(hdr)
+	if err != nil {
+		return fmt.Errorf("export-imc-session: marshal header: %w", err)
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.Write(imcExportMagic[:])
+	binary.Write(bw, binary.LittleEndian, uint32(imcExportVersion))
+	binary.Write(bw, binary.LittleEndian, uint32(len(data)))
+	bw.Write(data)
+	bw.Write(target)
+	bw.Write(draft)
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("export-imc-session: write: %w", err)
+	}
+
+	return nil
+}
+
+// ImportIMCSession restores a session written by ExportIMCSession for the same
+// model. A keyed session replaces the idle session holding the same key;
+// otherwise an empty session, or the least recently used idle one, receives
+// the import. It returns the state of the restored session.
+func (m *Model) ImportIMCSession(r io.Reader) (IMCSessionDetail, error) {
+	hdr, err := m.readIMCExportHeader(r)
+	if err != nil {
+		return IMCSessionDetail{}, fmt.Errorf("import-imc-session: %w", err)
+	}
+
+	m.cacheMu.Lock()
+	var empty, lru, keyed *imcSession
+	for _, session := range m.imcSessions {
+		if session.reserved {
+			continue
+		}
+		if session.totalTokensCached == 0 {
+			if empty == nil {
+				empty = session
+			}
+			continue
+		}
+		if lru == nil || session.lastUsed.Before(lru.lastUsed) {
+			lru = session
+		}
+		if hdr.CacheKey != "" && session.cacheKey == hdr.CacheKey && (keyed == nil || session.lastUsed.Before(keyed.lastUsed)) {
+			keyed = session
+		}
+	}
+
+	session := imcRebuildSession(keyed, empty, lru)
+	if session == nil {
+		m.cacheMu.Unlock()
+		return IMCSessionDetail{}, fmt.Errorf("import-imc-session: %w: no idle session", ErrIMCSessionBusy)
+	}
+	imcResetSession(session)
+	session.reserved = true
+	m.cacheMu.Unlock()
+
+	if err := m.readIMCExportStores(r, session, hdr); err != nil {
+		m.cacheMu.Lock()
+		imcResetSession(session)
+		session.reserved = false
+		m.cacheMu.Unlock()
+		return IMCSessionDetail{}, fmt.Errorf("import-imc-session: %w", err)
+	}
+
+	m.cacheMu.Lock()
+	session.cacheKey = hdr.CacheKey
+	session.adapterKey = hdr.AdapterKey
+	session.cachedTokens = hdr.CachedTokens
+	session.totalTokensCached = len(hdr.CachedTokens)
+	session.cachedMsgCount = hdr.CachedMessages
+	session.cachedMsgsHash = hdr.MessagesHash
+	session.cachedRenderInputHash = hdr.RenderHash
+	if hdr.SamplingSeed != nil {
+		session.samplingSeed = *hdr.SamplingSeed
+		session.hasSamplingSeed = true
+	}
+	session.lastUsed = time.Now()
+	m.cacheMu.Unlock()
+
+	m.imcPublishSession(session)
+
+	for _, detail := range m.IMCSessions() {
+		if detail.ID == session.id {
+			return detail, nil
+		}
+	}
+
+	return IMCSessionDetail{}, fmt.Errorf("import-imc-session: %w: id %d", ErrIMCSessionNotFound, session.id)
+}
+
+// readIMCExportHeader reads and validates the header of an exported session
+// against this model.
+func (m *Model) readIMCExportHeader(r io.Reader) (imcExportHeader, error) {
+	var prefix struct {
+		Magic   [8]byte
+		Version uint32
+		Length  uint32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &prefix); err != nil {
+		return imcExportHeader{}, fmt.Errorf("%w: read header: %w", ErrInvalidRequest, err)
+	}
+
+	switch {
+	case prefix.Magic != imcExportMagic:
+		return imcExportHeader{}, fmt.Errorf("%w: not an exported imc session", ErrInvalidRequest)
+	case prefix.Version != imcExportVersion:
+		return imcExportHeader{}, fmt.Errorf("%w: unsupported export version %d", ErrInvalidRequest, prefix.Version)
+	case prefix.Length > imcExportMaxHeader:
+		return imcExportHeader{}, fmt.Errorf("%w: header of %d bytes exceeds %d", ErrInvalidRequest, prefix.Length, imcExportMaxHeader)
+	}
+
+	data := make([]byte, prefix.Length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return imcExportHeader{}, fmt.Errorf("%w: read header: %w", ErrInvalidRequest, err)
+	}
+
+	var hdr imcExportHeader
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&hdr); err != nil {
+		return imcExportHeader{}, fmt.Errorf("%w: decode header: %w", ErrInvalidRequest, err)
+	}
+
+	switch {
+	case hdr.Model != m.modelInfo.ID || hdr.ModelSize != m.modelInfo.Size:
+		return imcExportHeader{}, fmt.Errorf("%w: session was exported from model %q, not %q", ErrInvalidRequest, hdr.Model, m.modelInfo.ID)
+	case hdr.CacheTypeK != m.cfg.CacheTypeK.String() || hdr.CacheTypeV != m.cfg.CacheTypeV.String():
+		return imcExportHeader{}, fmt.Errorf("%w: session cache types %s/%s do not match the model's %s/%s", ErrInvalidRequest,
+			hdr.CacheTypeK, hdr.CacheTypeV, m.cfg.CacheTypeK, m.cfg.CacheTypeV)
+	case len(hdr.CachedTokens) == 0 || hdr.TargetBytes <= 0 || hdr.DraftBytes < 0:
+		return imcExportHeader{}, fmt.Errorf("%w: session has no snapshot", ErrInvalidRequest)
+	case len(hdr.CachedTokens) > m.cfg.ContextWindow():
+		return imcExportHeader{}, fmt.Errorf("%w: session tokens [%d] exceed context window [%d]", ErrInvalidRequest, len(hdr.CachedTokens), m.cfg.ContextWindow())
+	case len(hdr.CacheKey) > maxCacheKeyLen:
+		return imcExportHeader{}, fmt.Errorf("%w: cache key exceeds %d bytes", ErrInvalidRequest, maxCacheKeyLen)
+	}
+
+	return hdr, nil
+}
+
+// readIMCExportStores fills the session's stores from the payload following
+// the header. A draft snapshot is kept only when this model captures one; it
+// is discarded otherwise and the session restores without MTP state. The
+// caller holds the session's reservation.
+func (m *Model) readIMCExportStores(r io.Reader, session *imcSession, hdr imcExportHeader) error {
+	if err := readIMCExportStore(r, session.kvState, hdr.TargetBytes); err != nil {
+		return fmt.Errorf("read target snapshot: %w", err)
+	}
+
+	if session.draftKVState == nil || hdr.DraftBytes == 0 {
+		if _, err := io.CopyN(io.Discard, r, int64(hdr.DraftBytes)); err != nil {
+			return fmt.Errorf("%w: read draft snapshot: %w", ErrInvalidRequest, err)
+		}
+		return nil
+	}
+
+	if err := readIMCExportStore(r, session.draftKVState, hdr.DraftBytes); err != nil {
+		return fmt.Errorf("read draft snapshot: %w", err)
+	}
+	session.pendingH = append(session.pendingH[:0], hdr.PendingH...)
+
+	return nil
+}
+
+func readIMCExportStore(r io.Reader, store SessionStore, n int) error {
+	buf := store.Prepare(n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		store.Commit(0)
+		return fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+	store.Commit(n)
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/kronk/sdk/applog"
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+func newExportTestModel(sessions ...*imcSession) *Model {
+	return &Model{
+		cfg:         Config{PtrContextWindow: new(64)},
+		log:         applog.DiscardLogger,
+		modelInfo:   ModelInfo{ID: "test-model", Size: 1024},
+		imcSessions: sessions,
+	}
+}
+
+func TestIMCSessionExportImportRoundTrip(t *testing.T) {
+	source := &imcSession{
+		id:                    0,
+		cacheKey:              "user-42",
+		cachedTokens:          []llama.Token{1, 2, 3},
+		totalTokensCached:     3,
+		cachedMsgCount:        2,
+		cachedMsgsHash:        "msgs",
+		cachedRenderInputHash: "render",
+		lastUsed:              time.Now(),
+		kvState:               ramSessionStore(),
+	}
+	buf := source.kvState.Prepare(4)
+	copy(buf, "snap")
+	source.kvState.Commit(4)
+
+	var file bytes.Buffer
+	if err := newExportTestModel(source).ExportIMCSession(&file, 0); err != nil {
+		t.Fatalf("ExportIMCSession() error = %v, want nil", err)
+	}
+	if source.reserved {
+		t.Error("source reserved after export, want released")
+	}
+
+	target := newExportTestModel(&imcSession{id: 0, kvState: ramSessionStore()})
+	detail, err := target.ImportIMCSession(&file)
+	if err != nil {
+		t.Fatalf("ImportIMCSession() error = %v, want nil", err)
+	}
+
+	got := target.imcSessions[0]
+	if got.reserved {
+		t.Error("imported session reserved, want published")
+	}
+	if !slices.Equal(got.cachedTokens, source.cachedTokens) {
+		t.Errorf("cachedTokens = %v, want %v", got.cachedTokens, source.cachedTokens)
+	}
+	if got.cachedMsgCount != 2 || got.cachedMsgsHash != "msgs" || got.cachedRenderInputHash != "render" {
+		t.Errorf("metadata = %d/%q/%q, want 2/msgs/render", got.cachedMsgCount, got.cachedMsgsHash, got.cachedRenderInputHash)
+	}
+	if string(got.kvState.Bytes()) != "snap" {
+		t.Errorf("kvState = %q, want %q", got.kvState.Bytes(), "snap")
+	}
+	if detail.CacheKey != "user-42" || detail.Context != 3 {
+		t.Errorf("detail = %+v, want cache key user-42 with 3 tokens", detail)
+	}
+}
+
+func TestExportIMCSessionErrors(t *testing.T) {
+	reserved := &imcSession{id: 1, reserved: true, cachedTokens: []llama.Token{1}, totalTokensCached: 1, kvState: populatedTestSessionStore()}
+	empty := &imcSession{id: 0, kvState: ramSessionStore()}
+	m := newExportTestModel(empty, reserved)
+
+	tests := []struct {
+		name string
+		id   int
+		want error
+	}{
+		{name: "out of range", id: 5, want: ErrIMCSessionNotFound},
+		{name: "empty", id: 0, want: ErrIMCSessionNotFound},
+		{name: "reserved", id: 1, want: ErrIMCSessionBusy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.ExportIMCSession(&bytes.Buffer{}, tt.id)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportIMCSessionRejectsOtherModel(t *testing.T) {
+	source := &imcSession{id: 0, cachedTokens: []llama.Token{1}, totalTokensCached: 1, kvState: populatedTestSessionStore()}
+
+	var file bytes.Buffer
+	if err := newExportTestModel(source).ExportIMCSession(&file, 0); err != nil {
+		t.Fatalf("ExportIMCSession() error = %v, want nil", err)
+	}
+
+	target := newExportTestModel(&imcSession{id: 0, kvState: ramSessionStore()})
+	target.modelInfo.ID = "other-model"
+
+	if _, err := target.ImportIMCSession(&file); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidRequest)
+	}
+	if target.imcSessions[0].totalTokensCached != 0 || target.imcSessions[0].reserved {
+		t.Error("target session changed by a rejected import")
+	}
+}
+
+func TestImportIMCSessionRejectsTruncatedFile(t *testing.T) {
+	source := &imcSession{id: 0, cachedTokens: []llama.Token{1}, totalTokensCached: 1, kvState: populatedTestSessionStore()}
+
+	var file bytes.Buffer
+	if err := newExportTestModel(source).ExportIMCSession(&file, 0); err != nil {
+		t.Fatalf("ExportIMCSession() error = %v, want nil", err)
+	}
+	file.Truncate(file.Len() - 1)
+
+	target := newExportTestModel(&imcSession{id: 0, kvState: ramSessionStore()})
+	if _, err := target.ImportIMCSession(&file); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("err = %v, want %v", err, ErrInvalidRequest)
+	}
+
+	session := target.imcSessions[0]
+	if session.totalTokensCached != 0 || session.reserved || session.kvState.Len() != 0 {
+		t.Error("target session not reset after a truncated import")
+	}
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/hybridgroup/yzma/pkg/llama"
 )
 
-func (m *Model) processIMCMediaTokenPlan(ctx context.Context, d, stableD D, actualPrompt, stablePrompt string, actualMedia, stableMedia [][]byte, requestStart time.Time) cacheResult {
+func (m *Model) processIMCMediaTokenPlan(ctx context.Context, d, stableD D, actualPrompt, stablePrompt string, actualMedia, stableMedia [][]byte, requestStart time.Time, adapterKey string) cacheResult {
 	result := cacheResult{modifiedD: d}
 	actual, err := buildPromptPlan(m.vocab, actualPrompt, actualMedia)
 	if err != nil {
@@ -26,10 +26,10 @@ func (m *Model) processIMCMediaTokenPlan(ctx context.Context, d, stableD D, actu
 		m.log(ctx, "imc-media-cache", "status", "plan-fallback", "cache_mode", "token-v2", "reason", "non-text-or-empty-tail")
 		return result
 	}
-	return m.processIMCMediaPlans(ctx, d, stableD, actual, stable, tail, requestStart)
+	return m.processIMCMediaPlans(ctx, d, stableD, actual, stable, tail, requestStart, adapterKey)
 }
 
-func (m *Model) processIMCMediaPlans(ctx context.Context, d, stableD D, actual, stable promptPlan, defaultTail []llama.Token, requestStart time.Time) cacheResult {
+func (m *Model) processIMCMediaPlans(ctx context.Context, d, stableD D, actual, stable promptPlan, defaultTail []llama.Token, requestStart time.Time, adapterKey string) cacheResult {
 	result := cacheResult{modifiedD: d,
 		imcTokenPlan:         true,
 		imcTailTokens:        slices.Clone(defaultTail),
@@ -39,8 +39,10 @@ func (m *Model) processIMCMediaPlans(ctx context.Context, d, stableD D, actual,
 		imcMediaCacheD:       stableD}
 	renderFingerprint, fingerprintOK := m.imcRenderFingerprint(d, dMessages(d))
 
+	cacheKey, _ := parseCacheKey(d)
+
 	m.cacheMu.Lock()
-	var match, empty, lru *imcSession
+	var match, empty, lru, keyed *imcSession
 	for _, session := range m.imcSessions {
 		if session.reserved {
 			continue
@@ -54,6 +56,12 @@ func (m *Model) processIMCMediaPlans(ctx context.Context, d, stableD D, actual,
 		if lru == nil || session.lastUsed.Before(lru.lastUsed) {
 			lru = session
 		}
+		if session.cacheKey != cacheKey || session.adapterKey != adapterKey {
+			continue
+		}
+		if cacheKey != "" && (keyed == nil || session.lastUsed.Before(keyed.lastUsed)) {
+			keyed = session
+		}
 		mediaAnchor := validMediaAnchorSession(session) && stable.hasPrefix(session.promptPlan)
 		textAnchor := validTextToMediaAnchorSession(session, stable)
 		if !mediaAnchor && !textAnchor {
@@ -125,16 +133,15 @@ func (m *Model) processIMCMediaPlans(ctx context.Context, d, stableD D, actual,
 		}
 	}
 	if selected == nil {
-		selected = empty
-		if selected == nil {
-			selected = lru
-		}
+		selected = imcRebuildSession(keyed, empty, lru)
 		if selected == nil {
 			m.cacheMu.Unlock()
 			result.err = fmt.Errorf("imc: server busy processing other requests, try again shortly")
 			return result
 		}
 		imcResetSession(selected)
+		selected.cacheKey = cacheKey
+		selected.adapterKey = adapterKey
 		selected.reserved = true
 		result.imcMediaBuild = true
 		result.imcClearSeq = true
@@ -12,7 +12,9 @@ import (
 // processIMCTokenPlan selects a text session using cached tokens as the
 // authority. Only complete cached sequences are reusable; divergence never
 // trims an existing session and instead rebuilds an empty/LRU session.
-func (m *Model) processIMCTokenPlan(ctx context.Context, d D, actual, stable, system []llama.Token, requestStart time.Time) cacheResult {
+// Sessions only match requests running the same adapter set, since the cached
+// KV depends on the adapters applied when it was built.
+func (m *Model) processIMCTokenPlan(ctx context.Context, d D, actual, stable, system []llama.Token, requestStart time.Time, adapterKey string) cacheResult {
 	result := cacheResult{modifiedD: d}
 	if len(actual) == 0 || len(stable) >= len(actual) || !tokensHavePrefix(actual, stable) {
 		return result
@@ -37,11 +39,14 @@ func (m *Model) processIMCTokenPlan(ctx context.Context, d D, actual, stable, sy
 		return result
 	}
 
+	cacheKey, _ := parseCacheKey(d)
+
 	m.cacheMu.Lock()
 	var best *imcSession
 	var bestLen int
 	var empty *imcSession
 	var lru *imcSession
+	var keyed *imcSession
 	for _, session := range m.imcSessions {
 		if session.reserved {
 			continue
@@ -55,9 +60,15 @@ func (m *Model) processIMCTokenPlan(ctx context.Context, d D, actual, stable, sy
 		if lru == nil || session.lastUsed.Before(lru.lastUsed) {
 			lru = session
 		}
+		if session.cacheKey != cacheKey || session.adapterKey != adapterKey {
+			continue
+		}
+		if cacheKey != "" && (keyed == nil || session.lastUsed.Before(keyed.lastUsed)) {
+			keyed = session
+		}
 		currentExact := len(session.cachedTokens) == len(target)
 		currentFingerprintOK := !currentExact || exactRenderFingerprintMatches(session.cachedRenderInputHash, renderFingerprint, fingerprintOK)
-		if !session.hasMedia && len(session.cachedTokens) > 0 && session.kvState != nil && len(session.kvState.Bytes()) > 0 &&
+		if !session.hasMedia && len(session.cachedTokens) > 0 && session.kvState != nil && session.kvState.Len() > 0 &&
 			tokensHavePrefix(target, session.cachedTokens) && currentFingerprintOK {
 			if len(session.cachedTokens) > bestLen {
 				best = session
@@ -72,7 +83,7 @@ func (m *Model) processIMCTokenPlan(ctx context.Context, d D, actual, stable, sy
 	var systemCache *imcSystemCache
 	if best == nil {
 		for _, candidate := range m.imcSystemCaches {
-			if candidate != nil && !candidate.building && candidate.kvState != nil && len(candidate.kvState.Bytes()) > 0 && slices.Equal(candidate.cachedTokens, system) {
+			if candidate != nil && !candidate.building && candidate.kvState != nil && candidate.kvState.Len() > 0 && slices.Equal(candidate.cachedTokens, system) {
 				systemCache = candidate
 				candidate.activeRestores++
 				candidate.restoreCount++
@@ -103,10 +114,7 @@ func (m *Model) processIMCTokenPlan(ctx context.Context, d D, actual, stable, sy
 		}
 		best.lastUsed = time.Now()
 	} else {
-		selected = empty
-		if selected == nil {
-			selected = lru
-		}
+		selected = imcRebuildSession(keyed, empty, lru)
 		if selected == nil {
 			if systemCache != nil {
 				systemCache.activeRestores--
@@ -116,6 +124,8 @@ func (m *Model) processIMCTokenPlan(ctx context.Context, d D, actual, stable, sy
 			return result
 		}
 		imcResetSession(selected)
+		selected.cacheKey = cacheKey
+		selected.adapterKey = adapterKey
 		selected.reserved = true
 		if systemCache != nil {
 			reusable = len(systemCache.cachedTokens)
@@ -3,6 +3,7 @@ package model
 import (
 	"context"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -62,7 +63,7 @@ func TestProcessIMCTokenPlanPublishesVerifiedSystemBoundary(t *testing.T) {
 		imcSessions: []*imcSession{session},
 	}
 	target := []llama.Token{1, 2, 3, 4, 5, 6}
-	result := m.processIMCTokenPlan(context.Background(), D{"messages": []D{{"role": "system", "content": "rules"}, {"role": "user", "content": "hello"}}}, append(slices.Clone(target), 9), target, target[:2], time.Now())
+	result := m.processIMCTokenPlan(context.Background(), D{"messages": []D{{"role": "system", "content": "rules"}, {"role": "user", "content": "hello"}}}, append(slices.Clone(target), 9), target, target[:2], time.Now(), "")
 
 	if result.cacheIdx != 0 || result.imcSystemBoundaryTokens != 2 {
 		t.Errorf("plan restored/system tokens = %d/%d, want 0/2", result.cacheIdx, result.imcSystemBoundaryTokens)
@@ -76,7 +77,7 @@ func TestProcessIMCTokenPlanRejectsUnverifiedSystemBoundary(t *testing.T) {
 		imcSessions: []*imcSession{{id: 0, kvState: ramSessionStore()}},
 	}
 	target := []llama.Token{1, 2, 3, 4, 5}
-	result := m.processIMCTokenPlan(context.Background(), D{"messages": []D{{"role": "system", "content": "rules"}}}, append(slices.Clone(target), 9), target, []llama.Token{1, 9}, time.Now())
+	result := m.processIMCTokenPlan(context.Background(), D{"messages": []D{{"role": "system", "content": "rules"}}}, append(slices.Clone(target), 9), target, []llama.Token{1, 9}, time.Now(), "")
 
 	if result.imcSystemBoundaryTokens != 0 {
 		t.Errorf("imcSystemBoundaryTokens = %d, want 0", result.imcSystemBoundaryTokens)
@@ -96,7 +97,7 @@ func TestProcessIMCTokenPlanSelectsLongestCompletePrefix(t *testing.T) {
 
 	actual := []llama.Token{1, 2, 3, 4}
 	stable := []llama.Token{1, 2, 3}
-	result := m.processIMCTokenPlan(context.Background(), D{"messages": []D{{"role": "user", "content": "x"}}}, actual, stable, nil, time.Now())
+	result := m.processIMCTokenPlan(context.Background(), D{"messages": []D{{"role": "user", "content": "x"}}}, actual, stable, nil, time.Now(), "")
 
 	if result.imcSessionID != 1 {
 		t.Errorf("imcSessionID = %d, want 1", result.imcSessionID)
@@ -114,7 +115,7 @@ func TestProcessIMCTokenPlanSelectsLongestCompletePrefix(t *testing.T) {
 
 func TestProcessIMCTokenPlanRejectsNonPrefixRender(t *testing.T) {
 	m := Model{cfg: Config{PtrCacheMinTokens: new(1)}}
-	result := m.processIMCTokenPlan(context.Background(), nil, []llama.Token{1, 2}, []llama.Token{1, 9}, nil, time.Now())
+	result := m.processIMCTokenPlan(context.Background(), nil, []llama.Token{1, 2}, []llama.Token{1, 9}, nil, time.Now(), "")
 	if result.imcTokenPlan {
 		t.Fatal("imcTokenPlan = true, want false")
 	}
@@ -132,7 +133,7 @@ func TestProcessIMCTokenPlanUsesSystemCacheAfterCurrentDiverges(t *testing.T) {
 	cache := &imcSystemCache{id: 0, cachedTokens: system, kvState: populatedTestSessionStore()}
 	m := Model{cfg: Config{PtrCacheMinTokens: new(1)}, log: applog.DiscardLogger, imcSessions: []*imcSession{session}, imcSystemCaches: []*imcSystemCache{cache}}
 	stable := []llama.Token{1, 2, 3, 4}
-	result := m.processIMCTokenPlan(context.Background(), D{"messages": []D{{"role": "system", "content": "rules"}}}, append(slices.Clone(stable), 9), stable, system, time.Now())
+	result := m.processIMCTokenPlan(context.Background(), D{"messages": []D{{"role": "system", "content": "rules"}}}, append(slices.Clone(stable), 9), stable, system, time.Now(), "")
 
 	if result.imcMatchKind != "append" {
 		t.Errorf("imcMatchKind = %q, want append", result.imcMatchKind)
@@ -166,7 +167,7 @@ func TestProcessIMCTokenPlanSkipsSystemCacheBeingRebuilt(t *testing.T) {
 	}
 	stable := []llama.Token{1, 2, 3, 4}
 
-	result := m.processIMCTokenPlan(context.Background(), D{"messages": []D{{"role": "system", "content": "rules"}}}, append(slices.Clone(stable), 9), stable, []llama.Token{1, 2}, time.Now())
+	result := m.processIMCTokenPlan(context.Background(), D{"messages": []D{{"role": "system", "content": "rules"}}}, append(slices.Clone(stable), 9), stable, []llama.Token{1, 2}, time.Now(), "")
 
 	if result.imcSystemCache != nil || cache.activeRestores != 0 {
 		t.Error("System cache was restored while its allocation was being rebuilt")
@@ -190,7 +191,7 @@ func TestProcessIMCTokenPlanChecksAllCurrentCachesBeforeSystem(t *testing.T) {
 		imcSystemCaches: []*imcSystemCache{checkpoint},
 	}
 
-	result := m.processIMCTokenPlan(context.Background(), D{"messages": []D{{"role": "user", "content": "x"}}}, []llama.Token{1, 2, 3, 4, 5}, []llama.Token{1, 2, 3, 4}, nil, time.Now())
+	result := m.processIMCTokenPlan(context.Background(), D{"messages": []D{{"role": "user", "content": "x"}}}, []llama.Token{1, 2, 3, 4, 5}, []llama.Token{1, 2, 3, 4}, nil, time.Now(), "")
 
 	if result.imcSessionID != current.id || result.cacheIdx != 1 {
 		t.Errorf("selected session/cacheIdx = %d/%d, want %d/1", result.imcSessionID, result.cacheIdx, current.id)
@@ -214,7 +215,7 @@ func TestProcessIMCTokenPlanPrefersLongerCurrentSnapshotOverCheckpoint(t *testin
 		imcSystemCaches: []*imcSystemCache{{id: 0, cachedTokens: []llama.Token{1, 2}, kvState: populatedTestSessionStore()}},
 	}
 
-	result := m.processIMCTokenPlan(context.Background(), D{"messages": []D{{"role": "user", "content": "x"}}}, []llama.Token{1, 2, 3, 4, 5}, []llama.Token{1, 2, 3, 4}, []llama.Token{1, 2}, time.Now())
+	result := m.processIMCTokenPlan(context.Background(), D{"messages": []D{{"role": "user", "content": "x"}}}, []llama.Token{1, 2, 3, 4, 5}, []llama.Token{1, 2, 3, 4}, []llama.Token{1, 2}, time.Now(), "")
 
 	if result.cacheIdx != 3 {
 		t.Errorf("cacheIdx = %d, want longer current prefix 3", result.cacheIdx)
@@ -243,7 +244,7 @@ func TestProcessIMCTokenPlanReservesExactMatch(t *testing.T) {
 	}
 	session.cachedRenderInputHash, _ = m.imcRenderFingerprint(d, dMessages(d))
 
-	result := m.processIMCTokenPlan(context.Background(), d, []llama.Token{1, 2, 3}, []llama.Token{1, 2}, nil, time.Now())
+	result := m.processIMCTokenPlan(context.Background(), d, []llama.Token{1, 2, 3}, []llama.Token{1, 2}, nil, time.Now(), "")
 
 	if result.imcMatchKind != "exact" {
 		t.Errorf("imcMatchKind = %q, want exact", result.imcMatchKind)
@@ -285,7 +286,7 @@ func TestProcessIMCTokenPlanPreservesCompletePrompt(t *testing.T) {
 			}
 			sessions[0].cachedRenderInputHash, _ = m.imcRenderFingerprint(d, dMessages(d))
 
-			result := m.processIMCTokenPlan(context.Background(), d, tt.actual, tt.stable, nil, time.Now())
+			result := m.processIMCTokenPlan(context.Background(), d, tt.actual, tt.stable, nil, time.Now(), "")
 			if result.imcMatchKind != tt.wantMatch {
 				t.Errorf("imcMatchKind = %q, want %q", result.imcMatchKind, tt.wantMatch)
 			}
@@ -320,7 +321,7 @@ func TestProcessIMCTokenPlanRebuildsExactTokensWhenRenderFingerprintChanges(t *t
 	}
 	m.imcSessions[0].cachedRenderInputHash, _ = m.imcRenderFingerprint(priorD, dMessages(priorD))
 
-	result := m.processIMCTokenPlan(context.Background(), currentD, []llama.Token{1, 2, 3}, []llama.Token{1, 2}, nil, time.Now())
+	result := m.processIMCTokenPlan(context.Background(), currentD, []llama.Token{1, 2, 3}, []llama.Token{1, 2}, nil, time.Now(), "")
 	if result.imcMatchKind != "rebuild" {
 		t.Errorf("imcMatchKind: got %q, want %q", result.imcMatchKind, "rebuild")
 	}
@@ -336,3 +337,89 @@ func populatedTestSessionStore() SessionStore {
 	store.Commit(1)
 	return store
 }
+
+func TestParseCacheKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		d       D
+		want    string
+		wantErr bool
+	}{
+		{name: "absent", d: D{}, want: ""},
+		{name: "set", d: D{"prompt_cache_key": "user-42"}, want: "user-42"},
+		{name: "not a string", d: D{"prompt_cache_key": 42}, wantErr: true},
+		{name: "too long", d: D{"prompt_cache_key": strings.Repeat("k", maxCacheKeyLen+1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCacheKey(tt.d)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("key = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessIMCTokenPlanIsolatesCacheKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		wantMatch   string
+		wantSession int
+	}{
+		{name: "same key", key: "a", wantMatch: "append", wantSession: 0},
+		{name: "other key", key: "b", wantMatch: "rebuild", wantSession: 2},
+		{name: "no key", key: "", wantMatch: "append", wantSession: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := D{"messages": []D{{"role": "user", "content": "x"}}}
+			if tt.key != "" {
+				d["prompt_cache_key"] = tt.key
+			}
+
+			sessions := []*imcSession{
+				{id: 0, cacheKey: "a", cachedTokens: []llama.Token{1}, totalTokensCached: 1, kvState: populatedTestSessionStore()},
+				{id: 1, cachedTokens: []llama.Token{1}, totalTokensCached: 1, kvState: populatedTestSessionStore()},
+				{id: 2, kvState: ramSessionStore()},
+			}
+			m := Model{
+				cfg:         Config{PtrCacheMinTokens: new(1)},
+				log:         applog.DiscardLogger,
+				imcSessions: sessions,
+			}
+
+			result := m.processIMCTokenPlan(context.Background(), d, []llama.Token{1, 2, 3}, []llama.Token{1, 2}, nil, time.Now(), "")
+			if result.imcMatchKind != tt.wantMatch {
+				t.Errorf("imcMatchKind = %q, want %q", result.imcMatchKind, tt.wantMatch)
+			}
+			if result.imcSessionID != tt.wantSession {
+				t.Errorf("imcSessionID = %d, want %d", result.imcSessionID, tt.wantSession)
+			}
+			if got := sessions[tt.wantSession].cacheKey; got != tt.key {
+				t.Errorf("session cacheKey = %q, want %q", got, tt.key)
+			}
+		})
+	}
+}
+
+func TestIMCRebuildSessionPrefersKeyedSession(t *testing.T) {
+	keyed := &imcSession{id: 0}
+	empty := &imcSession{id: 1}
+	lru := &imcSession{id: 2}
+
+	if got := imcRebuildSession(keyed, empty, lru); got != keyed {
+		t.Errorf("with keyed session: got id %d, want %d", got.id, keyed.id)
+	}
+	if got := imcRebuildSession(nil, empty, lru); got != empty {
+		t.Errorf("without keyed session: got id %d, want %d", got.id, empty.id)
+	}
+	if got := imcRebuildSession(nil, nil, lru); got != lru {
+		t.Errorf("without empty session: got id %d, want %d", got.id, lru.id)
+	}
+}
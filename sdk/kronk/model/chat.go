@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"math"
 	"slices"
 	"strings"
 	"time"
@@ -20,7 +21,11 @@ import (
 
 const streamChBuffer = 32
 
-// ErrFileInputsUnsupported indicates file content parts are not supported.
+// maxChoiceCount is the largest n accepted by a chat request.
+const maxChoiceCount = 128
+
+// ErrFileInputsUnsupported indicates file content parts that were not resolved
+// by ResolveFileInputs.
 var ErrFileInputsUnsupported = errors.New("file inputs are not currently supported")
 
 // ErrMessagesMissing indicates that a chat request has no messages field.
@@ -37,6 +42,8 @@ var ErrInvalidRequest = errors.New("validate-document: invalid request")
 // All requests (including vision/audio) use batch processing and can run
 // concurrently based on the NSeqMax config value, which controls parallel
 // sequence processing.
+// When n requests more than one choice, the response holds one choice per
+// index and its usage covers every choice.
 func (m *Model) Chat(ctx context.Context, d D) (ChatResponse, error) {
 	if err := ValidateChatRequest(d); err != nil {
 		return ChatResponse{}, err
@@ -73,9 +80,8 @@ func (m *Model) Chat(ctx context.Context, d D) (ChatResponse, error) {
 		lastMsg.Object = ObjectChatTextFinal
 	}
 
-	if len(lastMsg.Choices) > 0 {
-		lastMsg.Choices[0].Index = 0
-		lastMsg.Choices[0].Delta = nil
+	for i := range lastMsg.Choices {
+		lastMsg.Choices[i].Delta = nil
 	}
 
 	return lastMsg, nil
@@ -87,12 +93,26 @@ func (m *Model) Chat(ctx context.Context, d D) (ChatResponse, error) {
 // sequence processing.
 // When stream_options.include_usage is true, the terminal choice is followed
 // by a usage response with an empty Choices slice.
+// When n requests more than one choice, responses for every choice index are
+// interleaved on the channel and a single usage response follows the last
+// terminal choice.
 // Validation failures are returned before a response channel is created.
 func (m *Model) ChatStreaming(ctx context.Context, d D) (<-chan ChatResponse, error) {
 	return m.chatStreaming(ctx, d, true)
 }
 
 func (m *Model) chatStreaming(ctx context.Context, d D, streaming bool) (<-chan ChatResponse, error) {
+	prepare := func(ctx context.Context, requestStart time.Time) (preparedChat, error) {
+		return m.prepareChat(ctx, d, streaming, requestStart)
+	}
+
+	return m.streamPrepared(ctx, "chatcmpl-", prepare)
+}
+
+// streamPrepared prepares a request with the prepare function and submits it
+// to the batch engine, returning the channel its responses arrive on. The
+// response IDs start with idPrefix.
+func (m *Model) streamPrepared(ctx context.Context, idPrefix string, prepare func(ctx context.Context, requestStart time.Time) (preparedChat, error)) (<-chan ChatResponse, error) {
 	requestStart := time.Now()
 
 	// Increment active streams before preparing the request to prevent Unload
@@ -100,7 +120,7 @@ func (m *Model) chatStreaming(ctx context.Context, d D, streaming bool) (<-chan
 	active := m.activeStreams.Add(1)
 	metrics.AddPoolActiveStreams(m.modelInfo.ID, 1)
 
-	id := "chatcmpl-" + uuid.New().String()
+	id := idPrefix + uuid.New().String()
 
 	m.log(ctx, "chat-streaming", "status", "started", "id", id, "active_streams", active)
 	m.log(ctx, "request-lifecycle",
@@ -112,7 +132,7 @@ func (m *Model) chatStreaming(ctx context.Context, d D, streaming bool) (<-chan
 
 	prepCtx, prepSpan := otel.AddSpan(ctx, "prepare-request")
 
-	prepared, err := m.prepareChat(prepCtx, d, streaming, requestStart)
+	prepared, err := prepare(prepCtx, requestStart)
 	if err != nil {
 		m.releaseIMCReservationIfHeld(prepared.cache)
 		prepSpan.End()
@@ -172,7 +192,13 @@ func (m *Model) chatStreaming(ctx context.Context, d D, streaming bool) (<-chan
 			}
 		}()
 
-		if m.submitToBatchEngine(ctx, ch, id, prepared, requestStart) {
+		if prepared.choices > 1 {
+			m.submitChoices(ctx, ch, id, prepared, requestStart)
+			batching = true
+			return
+		}
+
+		if m.submitToBatchEngine(ctx, ch, id, prepared, requestStart) != nil {
 			batching = true
 			return
 		}
@@ -185,10 +211,17 @@ type preparedChat struct {
 	d          D
 	object     string
 	prompt     string
+	raw        bool
 	media      [][]byte
 	params     Params
+	adapters   adapterSet
 	cache      cacheResult
 	textTokens []llama.Token
+
+	// Multiple choices fan one preparation out into one batch job per choice.
+	choices      int      // Number of choices requested with n.
+	choiceIndex  int      // Choice submitted by this preparation.
+	prefixSource *chatJob // Choice whose prefilled prompt KV this choice copies.
 }
 
 func (m *Model) prepareChat(ctx context.Context, d D, streaming bool, requestStart time.Time) (preparedChat, error) {
@@ -203,24 +236,46 @@ func (m *Model) prepareChat(ctx context.Context, d D, streaming bool, requestSta
 	}
 	params.Stream = streaming
 
+	choices, err := m.validateChoiceSlots(d)
+	if err != nil {
+		return preparedChat{}, err
+	}
+
 	d, object, err := m.prepareContext(ctx, d)
 	if err != nil {
 		return preparedChat{}, err
 	}
+	if choices > 1 && object != ObjectChatText {
+		return preparedChat{}, fmt.Errorf("%w: n greater than 1 is not supported for image or audio requests", ErrInvalidRequest)
+	}
 
-	prompt, media, cache, err := m.prepareCacheAndPrompt(ctx, d, object, requestStart)
-	prepared := preparedChat{
-		d:      cache.modifiedD,
-		object: object,
-		prompt: prompt,
-		media:  media,
-		params: params,
-		cache:  cache,
+	adapters, err := m.resolveAdapters(params.Adapters)
+	if err != nil {
+		return preparedChat{}, err
 	}
+
+	prepared, err := m.preparePrompt(ctx, d, object, params, adapters, choices, requestStart)
 	if err != nil {
 		return prepared, err
 	}
 
+	// Truncation changes the conversation, so the cache lookup and prompt are
+	// prepared again from the shortened messages.
+	m.tokenizeTextPrompt(&prepared)
+	if m.truncationRequired(prepared) {
+		m.releaseIMCReservationIfHeld(prepared.cache)
+
+		d, err = m.truncateChat(ctx, d, params.Truncation, params.MaxTokens)
+		if err != nil {
+			return preparedChat{}, err
+		}
+
+		prepared, err = m.preparePrompt(ctx, d, object, params, adapters, choices, requestStart)
+		if err != nil {
+			return prepared, err
+		}
+	}
+
 	if err := m.prepareTextBudget(ctx, &prepared); err != nil {
 		return prepared, err
 	}
@@ -228,6 +283,36 @@ func (m *Model) prepareChat(ctx context.Context, d D, streaming bool, requestSta
 	return prepared, nil
 }
 
+// preparePrompt performs the cache lookup and renders the prompt.
+func (m *Model) preparePrompt(ctx context.Context, d D, object string, params Params, adapters adapterSet, choices int, requestStart time.Time) (preparedChat, error) {
+	prompt, media, cache, err := m.prepareCacheAndPrompt(ctx, d, object, adapters.key, requestStart)
+	prepared := preparedChat{
+		d:        cache.modifiedD,
+		object:   object,
+		prompt:   prompt,
+		media:    media,
+		params:   params,
+		adapters: adapters,
+		cache:    cache,
+		choices:  choices,
+	}
+
+	return prepared, err
+}
+
+// tokenizeTextPrompt sets the complete prompt tokens of a text request once.
+func (m *Model) tokenizeTextPrompt(prepared *preparedChat) {
+	if prepared.object != ObjectChatText || prepared.textTokens != nil {
+		return
+	}
+
+	if prepared.cache.imcTokenPlan {
+		prepared.textTokens = prepared.cache.imcSamplerPromptTokens
+	} else {
+		prepared.textTokens = llama.Tokenize(m.vocab, prepared.prompt, m.addBOSToken, true)
+	}
+}
+
 func (m *Model) prepareTextBudget(ctx context.Context, prepared *preparedChat) error {
 	// Multimodal token usage depends on mtmd processing performed by the batch
 	// slot. Text prompts can be fully tokenized and rejected before an HTTP
@@ -236,11 +321,7 @@ func (m *Model) prepareTextBudget(ctx context.Context, prepared *preparedChat) e
 		return nil
 	}
 
-	if prepared.cache.imcTokenPlan {
-		prepared.textTokens = prepared.cache.imcSamplerPromptTokens
-	} else {
-		prepared.textTokens = llama.Tokenize(m.vocab, prepared.prompt, m.addBOSToken, true)
-	}
+	m.tokenizeTextPrompt(prepared)
 
 	contextWindow := m.cfg.ContextWindow()
 	requestedMaxTokens := prepared.params.MaxTokens
@@ -249,6 +330,12 @@ func (m *Model) prepareTextBudget(ctx context.Context, prepared *preparedChat) e
 		return fmt.Errorf("%w: input tokens [%d] exceed context window [%d]", ErrInvalidRequest, len(prepared.textTokens), contextWindow)
 	}
 
+	// Context shifting makes room as generation reaches the context window,
+	// so the requested output length is not limited by the prompt.
+	if m.contextShiftEnabled() {
+		effectiveMaxTokens = requestedMaxTokens
+	}
+
 	prepared.params.MaxTokens = effectiveMaxTokens
 	if effectiveMaxTokens < requestedMaxTokens {
 		m.log(ctx, "prepare-chat",
@@ -393,8 +480,9 @@ func (m *Model) prepareContext(ctx context.Context, d D) (D, string, error) {
 }
 
 // prepareCacheAndPrompt handles cache processing and prompt creation. Returns
-// the prompt, media bytes, cache result, and any error.
-func (m *Model) prepareCacheAndPrompt(ctx context.Context, d D, object string, requestStart time.Time) (string, [][]byte, cacheResult, error) {
+// the prompt, media bytes, cache result, and any error. The adapter key keeps
+// requests from reusing KV built under a different adapter set.
+func (m *Model) prepareCacheAndPrompt(ctx context.Context, d D, object string, adapterKey string, requestStart time.Time) (string, [][]byte, cacheResult, error) {
 	var cache cacheResult
 
 	// Deserialize tool call arguments from JSON strings to maps so Jinja
@@ -426,7 +514,7 @@ func (m *Model) prepareCacheAndPrompt(ctx context.Context, d D, object string, r
 		}
 
 		if object == ObjectChatMedia {
-			cache = m.processIMCMediaTokenPlan(ctx, d, stableD, actualPrompt, stablePrompt, actualMedia, stableMedia, requestStart)
+			cache = m.processIMCMediaTokenPlan(ctx, d, stableD, actualPrompt, stablePrompt, actualMedia, stableMedia, requestStart, adapterKey)
 		} else {
 			actualTokens := llama.Tokenize(m.vocab, actualPrompt, m.addBOSToken, true)
 			stableTokens := llama.Tokenize(m.vocab, stablePrompt, m.addBOSToken, true)
@@ -441,7 +529,9 @@ func (m *Model) prepareCacheAndPrompt(ctx context.Context, d D, object string, r
 				}
 				systemMessages++
 			}
-			if systemMessages > 0 {
+			// System caches are shared by every request, so only the default
+			// adapter set builds and restores them.
+			if systemMessages > 0 && adapterKey == m.defaultAdapters.key {
 				systemD := maps.Clone(stableD)
 				systemD["messages"] = messages[:systemMessages]
 				systemPrompt, _, systemErr := m.createPrompt(ctx, systemD)
@@ -457,7 +547,7 @@ func (m *Model) prepareCacheAndPrompt(ctx context.Context, d D, object string, r
 				}
 			}
 
-			cache = m.processIMCTokenPlan(ctx, d, actualTokens, stableTokens, systemTokens, requestStart)
+			cache = m.processIMCTokenPlan(ctx, d, actualTokens, stableTokens, systemTokens, requestStart, adapterKey)
 		}
 		if cache.err != nil {
 			return "", nil, cache, cache.err
@@ -495,9 +585,9 @@ func (m *Model) releaseIMCReservationIfHeld(cache cacheResult) {
 }
 
 // submitToBatchEngine attempts to submit the prepared request to the batch engine.
-// Returns true if the job was submitted (caller should set batching=true),
-// false if batch engine is not available or not applicable.
-func (m *Model) submitToBatchEngine(ctx context.Context, ch chan ChatResponse, id string, prepared preparedChat, requestStart time.Time) bool {
+// Returns the submitted job (caller should set batching=true), or nil if the
+// batch engine is not available or not applicable.
+func (m *Model) submitToBatchEngine(ctx context.Context, ch chan ChatResponse, id string, prepared preparedChat, requestStart time.Time) *chatJob {
 	cache := prepared.cache
 	imcCacheHit := m.cfg.IncrementalCache() && (cache.cacheIdx > 0 || len(cache.imcNewCacheTokens) > 0 || cache.imcMediaBuild || cache.imcMediaAppend)
 
@@ -518,9 +608,14 @@ func (m *Model) submitToBatchEngine(ctx context.Context, ch chan ChatResponse, i
 		d:                   prepared.d,
 		object:              prepared.object,
 		prompt:              prepared.prompt,
+		raw:                 prepared.raw,
 		media:               prepared.media,
 		params:              prepared.params,
+		adapters:            prepared.adapters,
 		ch:                  ch,
+		choiceIndex:         prepared.choiceIndex,
+		prefixSource:        prepared.prefixSource,
+		schedule:            GetSchedule(ctx),
 		textTokens:          prepared.textTokens,
 		samplerPromptTokens: cache.imcSamplerPromptTokens,
 		tailTokens:          cache.imcTailTokens,
@@ -567,7 +662,7 @@ func (m *Model) submitToBatchEngine(ctx context.Context, ch chan ChatResponse, i
 		queueSpan.RecordError(err)
 		queueSpan.End()
 		if !job.queuedAt.IsZero() {
-			metrics.ObserveChatQueueWait(m.modelInfo.ID, time.Since(job.queuedAt))
+			metrics.ObserveChatQueueWait(m.modelInfo.ID, job.schedule.Priority.String(), time.Since(job.queuedAt))
 		}
 
 		// The batch engine never took ownership, so release any exact,
@@ -584,10 +679,10 @@ func (m *Model) submitToBatchEngine(ctx context.Context, ch chan ChatResponse, i
 
 		m.recordChatFailure(ctx, requestStart, err)
 		m.sendChatError(ctx, ch, id, err)
-		return false
+		return nil
 	}
 
-	return true
+	return &job
 }
 
 // prepareTextContext converts messages using the OpenAI array format
@@ -813,38 +908,64 @@ func ValidateChatRequest(d D) error {
 	if err := validateToolChoice(d); err != nil {
 		return err
 	}
+	if _, err := parseCacheKey(d); err != nil {
+		return err
+	}
 
 	return nil
 }
 
 func validateChoiceCount(d D) error {
+	_, err := parseChoiceCount(d)
+	return err
+}
+
+// parseChoiceCount returns the number of choices requested with n. An omitted
+// or null n requests one choice.
+func parseChoiceCount(d D) (int, error) {
 	val, exists := d["n"]
 	if !exists || val == nil {
-		return nil
+		return 1, nil
 	}
 
+	var n float64
 	var supported bool
 	switch value := val.(type) {
 	case json.Number:
-		n, err := value.Float64()
-		supported = err == nil && n == 1
+		v, err := value.Float64()
+		n, supported = v, err == nil
 	case float32:
-		supported = value == 1
+		n, supported = float64(value), true
 	case float64:
-		supported = value == 1
+		n, supported = value, true
 	case int:
-		supported = value == 1
+		n, supported = float64(value), true
 	case int32:
-		supported = value == 1
+		n, supported = float64(value), true
 	case int64:
-		supported = value == 1
+		n, supported = float64(value), true
 	}
 
-	if !supported {
-		return fmt.Errorf("%w: n values other than 1 are not supported", ErrInvalidRequest)
+	if !supported || n != math.Trunc(n) || n < 1 || n > maxChoiceCount {
+		return 0, fmt.Errorf("%w: n must be an integer between 1 and %d", ErrInvalidRequest, maxChoiceCount)
 	}
 
-	return nil
+	return int(n), nil
+}
+
+// validateChoiceSlots returns the number of requested choices after checking
+// that every choice can run in its own batch slot at the same time.
+func (m *Model) validateChoiceSlots(d D) (int, error) {
+	choices, err := parseChoiceCount(d)
+	if err != nil {
+		return 0, err
+	}
+
+	if slots := max(m.cfg.NSeqMax(), 1); choices > slots {
+		return 0, fmt.Errorf("%w: n [%d] exceeds the model's parallel slots [%d]", ErrInvalidRequest, choices, slots)
+	}
+
+	return choices, nil
 }
 
 func parseStop(val any) ([]string, error) {
@@ -0,0 +1,268 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/observ/metrics"
+)
+
+// choiceResponse is a response received from one choice of an n > 1 request.
+type choiceResponse struct {
+	index int
+	resp  ChatResponse
+}
+
+// submitChoices fans a request for more than one choice out into one batch
+// job per choice. The first choice owns the request's IMC reservation; the
+// other choices render nothing new and, when the batch engine can share it,
+// copy the first choice's prefilled prompt KV instead of prefilling the same
+// prompt again. Responses from every choice are merged into ch, which is
+// closed after the last choice finishes.
+func (m *Model) submitChoices(ctx context.Context, ch chan ChatResponse, id string, prepared preparedChat, requestStart time.Time) {
+	n := prepared.choices
+
+	// The request holds one active stream and every batch job releases one
+	// when it finishes, so account for the additional choices up front.
+	m.activeStreams.Add(int32(n - 1))
+	metrics.AddPoolActiveStreams(m.modelInfo.ID, n-1)
+
+	// A failed submission cancels the choices already running rather than
+	// letting them generate output the caller will never receive.
+	jobCtx, cancel := context.WithCancel(ctx)
+
+	chs := make([]chan ChatResponse, 0, n)
+	var primary *chatJob
+	for i := range n {
+		choice := prepared
+		choice.choiceIndex = i
+		if i > 0 {
+			choice.cache = cacheResult{}
+			choice.params.Seed = choiceSeed(prepared.params.Seed, i)
+			choice.prefixSource = primary
+		}
+
+		choiceCh := make(chan ChatResponse, streamChBuffer)
+		chs = append(chs, choiceCh)
+
+		job := m.submitToBatchEngine(jobCtx, choiceCh, id, choice, requestStart)
+		if job == nil {
+			cancel()
+			close(choiceCh)
+
+			unsubmitted := n - i
+			remaining := m.activeStreams.Add(-int32(unsubmitted))
+			metrics.AddPoolActiveStreams(m.modelInfo.ID, -unsubmitted)
+			m.log(ctx, "chat-streaming", "status", "choice-submit-failed", "id", id,
+				"choice", i, "choices", n, "active_streams", remaining)
+			break
+		}
+
+		if i == 0 {
+			primary = job
+		}
+	}
+
+	go func() {
+		defer cancel()
+		mergeChoices(ctx, ch, chs, prepared.params.Stream, prepared.params.IncludeUsage)
+	}()
+}
+
+// choiceSeed derives a distinct repeatable seed for each additional choice so
+// a seeded n > 1 request does not return n identical choices.
+func choiceSeed(seed *uint32, index int) *uint32 {
+	if seed == nil {
+		return nil
+	}
+
+	return new(*seed + uint32(index))
+}
+
+// mergeChoices forwards the responses of every choice channel into ch and
+// closes ch once all choice channels are closed.
+//
+// Streaming responses are forwarded as they arrive. When usage was requested,
+// each terminal chunk carries its choice's usage and one combined usage chunk
+// follows the last choice. Non-streaming responses are combined into a single
+// final response with one choice per index; if any choice fails, the first
+// error response is sent instead.
+func mergeChoices(ctx context.Context, ch chan<- ChatResponse, chs []chan ChatResponse, streaming bool, includeUsage bool) {
+	defer close(ch)
+
+	merged := make(chan choiceResponse)
+
+	var wg sync.WaitGroup
+	for i, choiceCh := range chs {
+		wg.Go(func() {
+			for resp := range choiceCh {
+				merged <- choiceResponse{index: i, resp: resp}
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+
+	send := func(resp ChatResponse) {
+		select {
+		case ch <- resp:
+		case <-ctx.Done():
+		}
+	}
+
+	if streaming {
+		mergeStreamingChoices(merged, send, len(chs), includeUsage)
+		return
+	}
+
+	mergeFinalChoices(ctx, merged, send, len(chs))
+}
+
+func mergeStreamingChoices(merged <-chan choiceResponse, send func(ChatResponse), n int, includeUsage bool) {
+	usages := make([]Usage, n)
+	pending := make([]*ChatResponse, n)
+
+	var usageResp *ChatResponse
+	for cr := range merged {
+		resp := cr.resp
+
+		switch {
+		case len(resp.Choices) == 0:
+			if resp.Usage != nil {
+				usages[cr.index] = *resp.Usage
+				usageResp = &resp
+			}
+			if final := pending[cr.index]; final != nil && resp.Usage != nil {
+				final.Choices[0].Usage = new(*resp.Usage)
+				send(*final)
+				pending[cr.index] = nil
+			}
+
+		case includeUsage && isTerminalChoice(resp.Choices[0]):
+			pending[cr.index] = &resp
+
+		default:
+			send(resp)
+		}
+	}
+
+	for _, final := range pending {
+		if final != nil {
+			send(*final)
+		}
+	}
+
+	if usageResp != nil {
+		send(chatResponseUsage(*usageResp, combineChoiceUsage(usages)))
+	}
+}
+
+func mergeFinalChoices(ctx context.Context, merged <-chan choiceResponse, send func(ChatResponse), n int) {
+	finals := make([]*ChatResponse, n)
+
+	var failed *ChatResponse
+	var last *ChatResponse
+	for cr := range merged {
+		resp := cr.resp
+		if len(resp.Choices) == 0 {
+			continue
+		}
+		last = &resp
+
+		switch {
+		case resp.Choices[0].FinishReason() == FinishReasonError:
+			if failed == nil {
+				failed = &resp
+			}
+
+		case isTerminalChoice(resp.Choices[0]):
+			finals[cr.index] = &resp
+		}
+	}
+
+	if failed != nil {
+		send(*failed)
+		return
+	}
+
+	if slices.Contains(finals, nil) {
+		if last == nil {
+			return
+		}
+
+		err := ctx.Err()
+		if err == nil {
+			err = errors.New("choice finished without a final response")
+		}
+		send(ChatResponseErr(last.ID, last.Object, last.Model, 0, err, Usage{}))
+		return
+	}
+
+	send(combineChoices(finals))
+}
+
+// combineChoices builds one response holding the final choice of every
+// request choice. Each choice reports its own usage and the response usage
+// covers all choices.
+func combineChoices(finals []*ChatResponse) ChatResponse {
+	resp := *finals[0]
+	resp.Choices = make([]Choice, 0, len(finals))
+
+	usages := make([]Usage, len(finals))
+	for i, final := range finals {
+		choice := final.Choices[0]
+		if final.Usage != nil {
+			usages[i] = *final.Usage
+			choice.Usage = new(*final.Usage)
+		}
+		resp.Choices = append(resp.Choices, choice)
+	}
+
+	if resp.Usage != nil {
+		resp.Usage = new(combineChoiceUsage(usages))
+	}
+
+	return resp
+}
+
+// combineChoiceUsage reports the usage of all choices of one request. The
+// prompt is counted once because every choice decodes the same prompt, while
+// generated tokens and throughput are summed across choices.
+func combineChoiceUsage(usages []Usage) Usage {
+	combined := usages[0]
+	combined.CompletionTokens = 0
+	combined.CompletionTokensDetails = CompletionTokensDetails{}
+	combined.TokensPerSecond = 0
+	combined.DraftTokens = 0
+	combined.DraftAcceptedTokens = 0
+
+	for _, usage := range usages {
+		combined.CompletionTokens += usage.CompletionTokens
+		combined.CompletionTokensDetails.ReasoningTokens += usage.CompletionTokensDetails.ReasoningTokens
+		combined.TokensPerSecond += usage.TokensPerSecond
+		combined.DraftTokens += usage.DraftTokens
+		combined.DraftAcceptedTokens += usage.DraftAcceptedTokens
+	}
+
+	combined.TotalTokens = combined.PromptTokens + combined.CompletionTokens
+	combined.DraftAcceptanceRate = 0
+	if combined.DraftTokens > 0 {
+		combined.DraftAcceptanceRate = float64(combined.DraftAcceptedTokens) / float64(combined.DraftTokens)
+	}
+
+	return combined
+}
+
+func isTerminalChoice(choice Choice) bool {
+	switch choice.FinishReason() {
+	case FinishReasonStop, FinishReasonLength, FinishReasonTool:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,221 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateChoiceSlots(t *testing.T) {
+	slots := 2
+	m := Model{cfg: Config{PtrNSeqMax: &slots}}
+
+	tests := []struct {
+		name    string
+		value   any
+		want    int
+		wantErr bool
+	}{
+		{name: "omitted", want: 1},
+		{name: "within slots", value: 2, want: 2},
+		{name: "exceeds slots", value: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := D{}
+			if tt.value != nil {
+				d["n"] = tt.value
+			}
+
+			got, err := m.validateChoiceSlots(d)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRequest) {
+					t.Errorf("validateChoiceSlots: got %v, want ErrInvalidRequest", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateChoiceSlots: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("choices: got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChoiceSeed(t *testing.T) {
+	if got := choiceSeed(nil, 2); got != nil {
+		t.Errorf("unseeded choice: got %d, want nil", *got)
+	}
+
+	seed := uint32(41)
+	got := choiceSeed(&seed, 2)
+	if got == nil || *got != 43 {
+		t.Errorf("seeded choice: got %v, want 43", got)
+	}
+	if seed != 41 {
+		t.Errorf("request seed modified: got %d, want 41", seed)
+	}
+}
+
+func TestCombineChoiceUsage(t *testing.T) {
+	usages := []Usage{
+		{
+			PromptTokens:            10,
+			PromptTokensDetails:     PromptTokensDetails{CachedTokens: 4},
+			CompletionTokens:        5,
+			CompletionTokensDetails: CompletionTokensDetails{ReasoningTokens: 2},
+			TotalTokens:             15,
+			TokensPerSecond:         20,
+		},
+		{
+			PromptTokens:            10,
+			CompletionTokens:        7,
+			CompletionTokensDetails: CompletionTokensDetails{ReasoningTokens: 3},
+			TotalTokens:             17,
+			TokensPerSecond:         30,
+		},
+	}
+
+	got := combineChoiceUsage(usages)
+	want := Usage{
+		PromptTokens:            10,
+		PromptTokensDetails:     PromptTokensDetails{CachedTokens: 4},
+		CompletionTokens:        12,
+		CompletionTokensDetails: CompletionTokensDetails{ReasoningTokens: 5},
+		TotalTokens:             22,
+		TokensPerSecond:         50,
+	}
+	if got != want {
+		t.Errorf("usage: got %+v, want %+v", got, want)
+	}
+}
+
+func TestMergeChoicesNonStreaming(t *testing.T) {
+	chs := []chan ChatResponse{
+		make(chan ChatResponse, 4),
+		make(chan ChatResponse, 4),
+	}
+
+	chs[1] <- chatResponseDelta("id", ObjectChatText, "model", 1, "b", false, nil)
+	chs[1] <- chatResponseFinal("id", ObjectChatText, "model", 1, "b", "", nil, nil, FinishReasonLength, true, Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5})
+	chs[0] <- chatResponseDelta("id", ObjectChatText, "model", 0, "a", false, nil)
+	chs[0] <- chatResponseFinal("id", ObjectChatText, "model", 0, "a", "", nil, nil, "", true, Usage{PromptTokens: 3, CompletionTokens: 1, TotalTokens: 4})
+	for _, ch := range chs {
+		close(ch)
+	}
+
+	out := make(chan ChatResponse, 4)
+	mergeChoices(t.Context(), out, chs, false, false)
+
+	var got []ChatResponse
+	for resp := range out {
+		got = append(got, resp)
+	}
+	if len(got) != 1 {
+		t.Fatalf("responses: got %d, want 1", len(got))
+	}
+
+	resp := got[0]
+	if len(resp.Choices) != 2 {
+		t.Fatalf("choices: got %d, want 2", len(resp.Choices))
+	}
+	for i, wantContent := range []string{"a", "b"} {
+		choice := resp.Choices[i]
+		if choice.Index != i {
+			t.Errorf("choice %d index: got %d", i, choice.Index)
+		}
+		if choice.Message == nil || choice.Message.Content != wantContent {
+			t.Errorf("choice %d message: got %+v, want %q", i, choice.Message, wantContent)
+		}
+		if choice.Usage == nil {
+			t.Errorf("choice %d usage: got nil", i)
+		}
+	}
+	if got := resp.Choices[0].FinishReason(); got != FinishReasonStop {
+		t.Errorf("choice 0 finish reason: got %q, want %q", got, FinishReasonStop)
+	}
+	if got := resp.Choices[1].FinishReason(); got != FinishReasonLength {
+		t.Errorf("choice 1 finish reason: got %q, want %q", got, FinishReasonLength)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 3 || resp.Usage.CompletionTokens != 3 || resp.Usage.TotalTokens != 6 {
+		t.Errorf("usage: got %+v, want prompt 3, completion 3, total 6", resp.Usage)
+	}
+}
+
+func TestMergeChoicesNonStreamingError(t *testing.T) {
+	wantErr := errors.New("boom")
+	chs := []chan ChatResponse{
+		make(chan ChatResponse, 1),
+		make(chan ChatResponse, 1),
+	}
+
+	chs[0] <- chatResponseFinal("id", ObjectChatText, "model", 0, "a", "", nil, nil, "", true, Usage{})
+	chs[1] <- ChatResponseErr("id", ObjectChatText, "model", 1, wantErr, Usage{})
+	for _, ch := range chs {
+		close(ch)
+	}
+
+	out := make(chan ChatResponse, 2)
+	mergeChoices(t.Context(), out, chs, false, false)
+
+	var last ChatResponse
+	for resp := range out {
+		last = resp
+	}
+	if len(last.Choices) != 1 || last.Choices[0].FinishReason() != FinishReasonError {
+		t.Fatalf("response: got %+v, want error", last)
+	}
+	if !errors.Is(last.internal.cause, wantErr) {
+		t.Errorf("cause: got %v, want %v", last.internal.cause, wantErr)
+	}
+}
+
+func TestMergeChoicesStreamingUsage(t *testing.T) {
+	chs := []chan ChatResponse{
+		make(chan ChatResponse, 4),
+		make(chan ChatResponse, 4),
+	}
+
+	for i, ch := range chs {
+		usage := Usage{PromptTokens: 3, CompletionTokens: i + 1, TotalTokens: 4 + i}
+		final := chatResponseFinal("id", ObjectChatText, "model", i, "", "", nil, nil, "", false, usage)
+		ch <- chatResponseDelta("id", ObjectChatText, "model", i, "x", false, nil)
+		ch <- final
+		ch <- chatResponseUsage(final, usage)
+		close(ch)
+	}
+
+	out := make(chan ChatResponse, 8)
+	mergeChoices(t.Context(), out, chs, true, true)
+
+	var deltas, terminals, usageChunks int
+	var usage *Usage
+	for resp := range out {
+		switch {
+		case len(resp.Choices) == 0:
+			usageChunks++
+			usage = resp.Usage
+
+		case isTerminalChoice(resp.Choices[0]):
+			terminals++
+			if resp.Choices[0].Usage == nil {
+				t.Errorf("choice %d terminal chunk: missing usage", resp.Choices[0].Index)
+			}
+			if usageChunks > 0 {
+				t.Error("terminal chunk arrived after the usage chunk")
+			}
+
+		default:
+			deltas++
+		}
+	}
+
+	if deltas != 2 || terminals != 2 || usageChunks != 1 {
+		t.Fatalf("chunks: got deltas %d, terminals %d, usage %d; want 2, 2, 1", deltas, terminals, usageChunks)
+	}
+	if usage == nil || usage.PromptTokens != 3 || usage.CompletionTokens != 3 || usage.TotalTokens != 6 {
+		t.Errorf("usage: got %+v, want prompt 3, completion 3, total 6", usage)
+	}
+}
@@ -383,7 +383,7 @@ func TestChatPreservesValidationError(t *testing.T) {
 		{name: "invalid integer parameter", field: "max_tokens", value: D{"invalid": true}},
 		{name: "invalid boolean parameter", field: "logprobs", value: "invalid"},
 		{name: "invalid reasoning parameter type", field: "reasoning_effort", value: 1},
-		{name: "unsupported choice count", field: "n", value: 4},
+		{name: "invalid choice count", field: "n", value: 0},
 	}
 
 	for _, tt := range tests {
@@ -437,7 +437,9 @@ func TestChatChoiceCountValidation(t *testing.T) {
 		{name: "one decoded", value: json.Number("1")},
 		{name: "one decoded decimal", value: json.Number("1.0")},
 		{name: "one native", value: 1},
-		{name: "multiple", value: json.Number("4"), wantErr: true},
+		{name: "multiple", value: json.Number("4")},
+		{name: "maximum", value: maxChoiceCount},
+		{name: "above maximum", value: maxChoiceCount + 1, wantErr: true},
 		{name: "zero", value: json.Number("0"), wantErr: true},
 		{name: "negative", value: -1, wantErr: true},
 		{name: "fractional", value: json.Number("1.5"), wantErr: true},
@@ -1231,7 +1233,7 @@ func TestLengthTerminatedToolOutputResponse(t *testing.T) {
 	if err := m.sendDeltaResponse(ctx, ch, "id", ObjectChatText, 0, deltaContent, ChannelAnswer, 0, 256, nil); err != nil {
 		t.Fatalf("send retained tool delta: %v", err)
 	}
-	if err := m.sendFinalResponse(ctx, ch, "id", ObjectChatText, 0, &content, &strings.Builder{}, toolCalls, nil, nil, FinishReasonLength, "max-tokens", ChannelAnswer, len(tooling), true, false, Usage{CompletionTokens: 256}); err != nil {
+	if err := m.sendFinalResponse(ctx, ch, "id", ObjectChatText, 0, &content, &strings.Builder{}, toolCalls, nil, nil, FinishReasonLength, "max-tokens", "", ChannelAnswer, len(tooling), true, false, Usage{CompletionTokens: 256}); err != nil {
 		t.Fatalf("sendFinalResponse() error = %v, want nil", err)
 	}
 
@@ -1275,7 +1277,7 @@ func TestSendFinalResponseReturnsCancellation(t *testing.T) {
 
 	m := Model{log: applog.DiscardLogger}
 	ch := make(chan ChatResponse)
-	err := m.sendFinalResponse(ctx, ch, "id", ObjectChatText, 0, &strings.Builder{}, &strings.Builder{}, nil, nil, nil, FinishReasonStop, "request-cancel", ChannelAnswer, 0, false, false, Usage{})
+	err := m.sendFinalResponse(ctx, ch, "id", ObjectChatText, 0, &strings.Builder{}, &strings.Builder{}, nil, nil, nil, FinishReasonStop, "request-cancel", "", ChannelAnswer, 0, false, false, Usage{})
 	if !errors.Is(err, context.Canceled) {
 		t.Errorf("sendFinalResponse() error = %v, want %v", err, context.Canceled)
 	}
@@ -1481,7 +1483,7 @@ func TestChatResponseFinalSeparatesCompletedToolCallsFromFinishReason(t *testing
 			Name: "get_weather",
 		},
 	}}
-	terminal := reconcileStartedToolCalls(toolCalls, started)
+	terminal := reconcileStartedToolCalls(toolCalls, started, nil)
 
 	argumentResp := chatResponseToolCallDelta("id", ObjectChatText, "model", 0, terminal[0])
 	if got := argumentResp.Choices[0].FinishReason(); got != "" {
@@ -1579,7 +1581,7 @@ func TestReconcileStartedToolCalls(t *testing.T) {
 		},
 	}
 
-	terminal := reconcileStartedToolCalls(toolCalls, started)
+	terminal := reconcileStartedToolCalls(toolCalls, started, nil)
 	if len(terminal) != 1 {
 		t.Fatalf("terminal deltas: got %d, want 1", len(terminal))
 	}
@@ -1591,7 +1593,7 @@ func TestReconcileStartedToolCalls(t *testing.T) {
 	}
 
 	toolCalls[0].ID = "final-id"
-	terminal = reconcileStartedToolCalls(toolCalls, nil)
+	terminal = reconcileStartedToolCalls(toolCalls, nil, nil)
 	if len(terminal) != 1 {
 		t.Fatalf("terminal deltas without started calls: got %d, want 1", len(terminal))
 	}
@@ -1620,7 +1622,7 @@ func TestReconcileStartedToolCallWithoutArguments(t *testing.T) {
 		},
 	}}
 
-	terminal := reconcileStartedToolCalls(toolCalls, started)
+	terminal := reconcileStartedToolCalls(toolCalls, started, nil)
 	if len(terminal) != 1 {
 		t.Fatalf("terminal deltas: got %d, want 1", len(terminal))
 	}
@@ -1653,7 +1655,7 @@ func TestReconcileStartedToolCallsIndependently(t *testing.T) {
 		{ID: "good-start-id", Index: 1, Type: "function", Function: ResponseToolCallDeltaFunction{Name: "working"}},
 	}
 
-	terminal := reconcileStartedToolCalls(toolCalls, started)
+	terminal := reconcileStartedToolCalls(toolCalls, started, nil)
 	if len(terminal) != 2 {
 		t.Fatalf("terminal deltas: got %d, want 2", len(terminal))
 	}
@@ -1680,7 +1682,7 @@ func TestReconcileStartedToolCallsWithCardinalityMismatch(t *testing.T) {
 		{ID: "first-start", Index: 0, Type: "function", Function: ResponseToolCallDeltaFunction{Name: "first"}},
 	}
 
-	terminal := reconcileStartedToolCalls(toolCalls, started)
+	terminal := reconcileStartedToolCalls(toolCalls, started, nil)
 	if len(terminal) != 2 {
 		t.Fatalf("terminal deltas: got %d, want 2", len(terminal))
 	}
@@ -1695,6 +1697,61 @@ func TestReconcileStartedToolCallsWithCardinalityMismatch(t *testing.T) {
 	}
 }
 
+func TestReconcileStartedToolCallsWithStreamedArguments(t *testing.T) {
+	toolCalls := []ResponseToolCall{
+		{ID: "a-final", Type: "function", Function: ResponseToolCallFunction{Name: "same", Arguments: ToolCallArguments{"b": 2.0, "a": 1.0}}},
+		{ID: "b-final", Type: "function", Function: ResponseToolCallFunction{Name: "prefix", Arguments: ToolCallArguments{"city": "Paris"}}},
+		{ID: "c-final", Type: "function", Function: ResponseToolCallFunction{Name: "unstreamed", Arguments: ToolCallArguments{"x": true}}},
+	}
+	started := []ResponseToolCallDelta{
+		{ID: "a-start", Index: 0, Type: "function", Function: ResponseToolCallDeltaFunction{Name: "same"}},
+		{ID: "b-start", Index: 1, Type: "function", Function: ResponseToolCallDeltaFunction{Name: "prefix"}},
+		{ID: "c-start", Index: 2, Type: "function", Function: ResponseToolCallDeltaFunction{Name: "unstreamed"}},
+	}
+	streamed := map[int]string{
+		0: `{"b": 2, "a": 1}`,
+		1: `{"city":"Pa`,
+	}
+
+	terminal := reconcileStartedToolCalls(toolCalls, started, streamed)
+	if len(terminal) != 2 {
+		t.Fatalf("terminal deltas: got %+v, want 2 deltas", terminal)
+	}
+	if got, want := terminal[0], (ResponseToolCallDelta{Index: 1, Function: ResponseToolCallDeltaFunction{Arguments: `ris"}`}}); got != want {
+		t.Errorf("prefix terminal delta: got %+v, want %+v", got, want)
+	}
+	if got, want := terminal[1], (ResponseToolCallDelta{Index: 2, Function: ResponseToolCallDeltaFunction{Arguments: `{"x":true}`}}); got != want {
+		t.Errorf("unstreamed terminal delta: got %+v, want %+v", got, want)
+	}
+	if got, want := toolCalls[0].ID, "a-start"; got != want {
+		t.Errorf("streamed call ID: got %q, want %q", got, want)
+	}
+}
+
+func TestJSONObjectPrefix(t *testing.T) {
+	tests := []struct {
+		in       string
+		want     int
+		complete bool
+	}{
+		{in: ""},
+		{in: `"text"`},
+		{in: `{"a": "x`, want: 8},
+		{in: `{"a": "}"`, want: 9},
+		{in: `{"a": "\"}"}, "b": 1}`, want: 12, complete: true},
+		{in: `{"a": [{"b": {}}]} trailing`, want: 18, complete: true},
+		{in: "{\"a\": \"\xe2\x82", want: 7},
+		{in: "{\"a\": \"\xe2\x82\xac", want: 10},
+	}
+
+	for _, tt := range tests {
+		got, complete := JSONObjectPrefix(tt.in)
+		if got != tt.want || complete != tt.complete {
+			t.Errorf("JSONObjectPrefix(%q): got (%d, %v), want (%d, %v)", tt.in, got, complete, tt.want, tt.complete)
+		}
+	}
+}
+
 func TestReconcileStartedToolCallsAfterUnannouncedMalformedCall(t *testing.T) {
 	toolCalls := []ResponseToolCall{
 		{ID: "bad-final", Type: "function", Status: 2, Function: ResponseToolCallFunction{}},
@@ -1704,7 +1761,7 @@ func TestReconcileStartedToolCallsAfterUnannouncedMalformedCall(t *testing.T) {
 		{ID: "good-start", Index: 0, Type: "function", Function: ResponseToolCallDeltaFunction{Name: "working"}},
 	}
 
-	terminal := reconcileStartedToolCalls(toolCalls, started)
+	terminal := reconcileStartedToolCalls(toolCalls, started, nil)
 	if got, want := toolCalls[0].Index, 1; got != want {
 		t.Errorf("unannounced malformed index: got %d, want %d", got, want)
 	}
@@ -0,0 +1,307 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+// ErrPromptMissing indicates that a completion request has no prompt field.
+var ErrPromptMissing = fmt.Errorf("%w: no prompt found in request", ErrInvalidRequest)
+
+// ErrFIMUnsupported indicates that a fill-in-the-middle request was made to a
+// model whose vocabulary has no FIM prefix, suffix, and middle tokens.
+var ErrFIMUnsupported = fmt.Errorf("%w: model does not support fill-in-the-middle", ErrInvalidRequest)
+
+// fimReservedTokens covers the BOS and FIM marker tokens wrapped around the
+// prefix and suffix of an infill prompt.
+const fimReservedTokens = 4
+
+// Completion performs a raw completion request and returns the final
+// response. The prompt is tokenized as given, without the chat template, and
+// the generated text is returned as answer content without reasoning or tool
+// call parsing. A request with a suffix field is a fill-in-the-middle request
+// built from the model's FIM tokens.
+func (m *Model) Completion(ctx context.Context, d D) (ChatResponse, error) {
+	if err := ValidateCompletionRequest(d); err != nil {
+		return ChatResponse{}, err
+	}
+
+	ch, err := m.completionStreaming(ctx, d, false)
+	if err != nil {
+		return ChatResponse{}, err
+	}
+
+	var lastMsg ChatResponse
+	for msg := range ch {
+		lastMsg = msg
+	}
+
+	if len(lastMsg.Choices) > 0 && lastMsg.Choices[0].FinishReason() == FinishReasonError {
+		if err := ctx.Err(); err != nil {
+			return lastMsg, err
+		}
+		if lastMsg.internal.cause != nil {
+			return lastMsg, lastMsg.internal.cause
+		}
+
+		errMsg := "unknown error"
+		if lastMsg.Choices[0].Delta != nil && lastMsg.Choices[0].Delta.Content != "" {
+			errMsg = lastMsg.Choices[0].Delta.Content
+		}
+		return lastMsg, errors.New(errMsg)
+	}
+
+	for i := range lastMsg.Choices {
+		lastMsg.Choices[i].Delta = nil
+	}
+
+	return lastMsg, nil
+}
+
+// CompletionStreaming performs a raw completion request and streams the
+// response. Responses follow the same shape as ChatStreaming with all
+// generated text in the content deltas.
+func (m *Model) CompletionStreaming(ctx context.Context, d D) (<-chan ChatResponse, error) {
+	return m.completionStreaming(ctx, d, true)
+}
+
+func (m *Model) completionStreaming(ctx context.Context, d D, streaming bool) (<-chan ChatResponse, error) {
+	prepare := func(ctx context.Context, requestStart time.Time) (preparedChat, error) {
+		return m.prepareCompletion(ctx, d, streaming)
+	}
+
+	return m.streamPrepared(ctx, "cmpl-", prepare)
+}
+
+// prepareCompletion tokenizes the raw or infill prompt of a completion
+// request. Raw completions bypass the incremental message cache because
+// they have no messages to cache.
+func (m *Model) prepareCompletion(ctx context.Context, d D, streaming bool) (preparedChat, error) {
+	d = d.Clone()
+
+	if err := ValidateCompletionRequest(d); err != nil {
+		return preparedChat{}, err
+	}
+
+	params, err := m.parseParams(ctx, d)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRequest) {
+			return preparedChat{}, err
+		}
+
+		return preparedChat{}, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+	params.Stream = streaming
+
+	m.log(ctx, "completion", "FINAL-PARAMS", params.String())
+
+	choices, err := m.validateChoiceSlots(d)
+	if err != nil {
+		return preparedChat{}, err
+	}
+
+	adapters, err := m.resolveAdapters(params.Adapters)
+	if err != nil {
+		return preparedChat{}, err
+	}
+
+	prompt, tokens, err := m.completionPrompt(ctx, d, params.MaxTokens)
+	if err != nil {
+		return preparedChat{}, err
+	}
+
+	prepared := preparedChat{
+		d:          d,
+		object:     ObjectChatText,
+		prompt:     prompt,
+		raw:        true,
+		params:     params,
+		adapters:   adapters,
+		textTokens: tokens,
+		choices:    choices,
+	}
+
+	if err := m.prepareTextBudget(ctx, &prepared); err != nil {
+		return prepared, err
+	}
+
+	return prepared, nil
+}
+
+// completionPrompt returns the prompt text and tokens of a completion
+// request. Special tokens in a raw prompt are parsed so callers can supply
+// their own framing. An infill prompt is laid out prefix-suffix-middle with
+// the model's FIM tokens, and the code in it is never parsed for special
+// tokens. When the prefix and suffix do not fit in the context window beside
+// the requested output, the start of the prefix and the end of the suffix
+// are dropped, keeping up to a quarter of the room for the suffix.
+func (m *Model) completionPrompt(ctx context.Context, d D, maxTokens int) (string, []llama.Token, error) {
+	prompt, promptTokens, err := parsePrompt(d["prompt"])
+	if err != nil {
+		return "", nil, err
+	}
+
+	nVocab := llama.Token(llama.VocabNTokens(m.vocab))
+	for _, token := range promptTokens {
+		if token >= nVocab {
+			return "", nil, fmt.Errorf("%w: prompt token %d is outside the vocabulary of %d tokens", ErrInvalidRequest, token, nVocab)
+		}
+	}
+
+	suffixVal, infill := d["suffix"]
+	if !infill || suffixVal == nil {
+		if promptTokens == nil {
+			promptTokens = llama.Tokenize(m.vocab, prompt, m.addBOSToken, true)
+		}
+		if len(promptTokens) == 0 {
+			return "", nil, ErrPromptMissing
+		}
+		return prompt, promptTokens, nil
+	}
+
+	suffix, ok := suffixVal.(string)
+	if !ok {
+		return "", nil, fmt.Errorf("%w: suffix must be a string", ErrInvalidRequest)
+	}
+
+	fimPre := llama.VocabFIMPre(m.vocab)
+	fimSuf := llama.VocabFIMSuf(m.vocab)
+	fimMid := llama.VocabFIMMid(m.vocab)
+	if fimPre == llama.TokenNull || fimSuf == llama.TokenNull || fimMid == llama.TokenNull {
+		return "", nil, ErrFIMUnsupported
+	}
+
+	prefixTokens := promptTokens
+	if prefixTokens == nil {
+		prefixTokens = llama.Tokenize(m.vocab, prompt, false, false)
+	}
+	suffixTokens := llama.Tokenize(m.vocab, suffix, false, false)
+
+	if room := m.cfg.ContextWindow() - maxTokens - fimReservedTokens; room > 0 && len(prefixTokens)+len(suffixTokens) > room {
+		keepSuffix := min(len(suffixTokens), room/4)
+		keepPrefix := min(len(prefixTokens), room-keepSuffix)
+
+		m.log(ctx, "completion", "status", "infill-trimmed",
+			"prefix_tokens", len(prefixTokens), "prefix_kept", keepPrefix,
+			"suffix_tokens", len(suffixTokens), "suffix_kept", keepSuffix)
+
+		prefixTokens = prefixTokens[len(prefixTokens)-keepPrefix:]
+		suffixTokens = suffixTokens[:keepSuffix]
+	}
+
+	tokens := make([]llama.Token, 0, len(prefixTokens)+len(suffixTokens)+fimReservedTokens)
+	if m.addBOSToken {
+		if bos := llama.VocabBOS(m.vocab); bos != llama.TokenNull {
+			tokens = append(tokens, bos)
+		}
+	}
+	tokens = append(tokens, fimPre)
+	tokens = append(tokens, prefixTokens...)
+	tokens = append(tokens, fimSuf)
+	tokens = append(tokens, suffixTokens...)
+	tokens = append(tokens, fimMid)
+
+	return prompt, tokens, nil
+}
+
+// ValidateCompletionRequest validates the fields in a raw completion request
+// document.
+func ValidateCompletionRequest(d D) error {
+	if _, _, err := parsePrompt(d["prompt"]); err != nil {
+		return err
+	}
+	if val, exists := d["suffix"]; exists && val != nil {
+		if _, ok := val.(string); !ok {
+			return fmt.Errorf("%w: suffix must be a string", ErrInvalidRequest)
+		}
+	}
+	if _, exists := d["messages"]; exists {
+		return fmt.Errorf("%w: messages are not supported by completions, use prompt", ErrInvalidRequest)
+	}
+	if err := validateChoiceCount(d); err != nil {
+		return err
+	}
+	if val, exists := d["stop"]; exists {
+		if _, err := parseStop(val); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parsePrompt accepts a prompt as a string, a single-element array holding a
+// string, or an array of token IDs. Token IDs are returned as tokens with an
+// empty prompt string.
+func parsePrompt(val any) (string, []llama.Token, error) {
+	switch prompt := val.(type) {
+	case nil:
+		return "", nil, ErrPromptMissing
+
+	case string:
+		return prompt, nil, nil
+
+	case []string:
+		if len(prompt) != 1 {
+			return "", nil, fmt.Errorf("%w: prompt must hold exactly one string", ErrInvalidRequest)
+		}
+		return prompt[0], nil, nil
+
+	case []any:
+		if len(prompt) == 0 {
+			return "", nil, ErrPromptMissing
+		}
+
+		if s, ok := prompt[0].(string); ok {
+			if len(prompt) != 1 {
+				return "", nil, fmt.Errorf("%w: prompt must hold exactly one string", ErrInvalidRequest)
+			}
+			return s, nil, nil
+		}
+
+		tokens := make([]llama.Token, len(prompt))
+		for i, v := range prompt {
+			id, ok := tokenID(v)
+			if !ok {
+				return "", nil, fmt.Errorf("%w: prompt must be a string or an array of token IDs", ErrInvalidRequest)
+			}
+			tokens[i] = id
+		}
+		return "", tokens, nil
+	}
+
+	return "", nil, fmt.Errorf("%w: prompt must be a string or an array of token IDs", ErrInvalidRequest)
+}
+
+func tokenID(v any) (llama.Token, bool) {
+	var f float64
+	switch n := v.(type) {
+	case json.Number:
+		var err error
+		if f, err = n.Float64(); err != nil {
+			return 0, false
+		}
+	case float64:
+		f = n
+	case int:
+		f = float64(n)
+	case int32:
+		f = float64(n)
+	case int64:
+		f = float64(n)
+	default:
+		return 0, false
+	}
+
+	if f != math.Trunc(f) || f < 0 || f > math.MaxInt32 {
+		return 0, false
+	}
+
+	return llama.Token(f), true
+}
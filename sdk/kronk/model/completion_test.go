@@ -0,0 +1,104 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+func TestValidateCompletionRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		d       D
+		wantErr error
+	}{
+		{name: "string", d: D{"prompt": "def add(a, b):"}},
+		{name: "single string array", d: D{"prompt": []any{"hello"}}},
+		{name: "token IDs", d: D{"prompt": []any{json.Number("1"), json.Number("2")}}},
+		{name: "suffix", d: D{"prompt": "def add(a, b):", "suffix": "\n\nprint(add(1, 2))"}},
+		{name: "null suffix", d: D{"prompt": "hello", "suffix": nil}},
+		{name: "missing prompt", d: D{}, wantErr: ErrPromptMissing},
+		{name: "empty array", d: D{"prompt": []any{}}, wantErr: ErrPromptMissing},
+		{name: "several strings", d: D{"prompt": []any{"a", "b"}}, wantErr: ErrInvalidRequest},
+		{name: "fractional token", d: D{"prompt": []any{json.Number("1.5")}}, wantErr: ErrInvalidRequest},
+		{name: "negative token", d: D{"prompt": []any{-1}}, wantErr: ErrInvalidRequest},
+		{name: "mixed array", d: D{"prompt": []any{json.Number("1"), "a"}}, wantErr: ErrInvalidRequest},
+		{name: "wrong type", d: D{"prompt": true}, wantErr: ErrInvalidRequest},
+		{name: "suffix type", d: D{"prompt": "hello", "suffix": 1}, wantErr: ErrInvalidRequest},
+		{name: "messages", d: D{"prompt": "hello", "messages": []D{{"role": "user", "content": "hello"}}}, wantErr: ErrInvalidRequest},
+		{name: "stop", d: D{"prompt": "hello", "stop": []any{"A", 2}}, wantErr: ErrInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCompletionRequest(tt.d)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("ValidateCompletionRequest: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ValidateCompletionRequest: got %v, want %v", err, tt.wantErr)
+			}
+			if !errors.Is(err, ErrInvalidRequest) {
+				t.Errorf("ValidateCompletionRequest: got %v, want ErrInvalidRequest", err)
+			}
+		})
+	}
+}
+
+func TestParsePrompt(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      any
+		wantPrompt string
+		wantTokens []llama.Token
+	}{
+		{name: "string", value: "hello", wantPrompt: "hello"},
+		{name: "native array", value: []string{"hello"}, wantPrompt: "hello"},
+		{name: "decoded array", value: []any{"hello"}, wantPrompt: "hello"},
+		{name: "decoded tokens", value: []any{json.Number("15"), json.Number("7")}, wantTokens: []llama.Token{15, 7}},
+		{name: "native tokens", value: []any{15, float64(7)}, wantTokens: []llama.Token{15, 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt, tokens, err := parsePrompt(tt.value)
+			if err != nil {
+				t.Fatalf("parsePrompt: %v", err)
+			}
+			if prompt != tt.wantPrompt {
+				t.Errorf("prompt: got %q, want %q", prompt, tt.wantPrompt)
+			}
+			if !reflect.DeepEqual(tokens, tt.wantTokens) {
+				t.Errorf("tokens: got %v, want %v", tokens, tt.wantTokens)
+			}
+		})
+	}
+}
+
+func TestProcessDecodedPieceRawSkipsParser(t *testing.T) {
+	sm := &flushStateMachine{classifyResult: Result{Channel: ChannelTool, Content: "tooling"}, classifyEOG: true}
+	s := slot{
+		stateMachine: sm,
+		job:          &chatJob{ctx: context.Background(), ch: make(chan ChatResponse, 1), id: "id", object: ObjectChatText, raw: true},
+	}
+	e := batchEngine{model: &Model{log: noopLog}}
+
+	outcome := e.processDecodedPiece(&s, stopPiece{content: "<tool_call>"}, -1, false)
+
+	if outcome.parserEOG || outcome.err != nil {
+		t.Fatalf("outcome: got %+v, want no parser EOG and no error", outcome)
+	}
+	if got := s.finalContent.String(); got != "<tool_call>" {
+		t.Fatalf("finalContent: got %q, want the raw piece", got)
+	}
+	if got := s.finalTooling.String(); got != "" {
+		t.Fatalf("finalTooling: got %q, want nothing routed to tooling", got)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"maps"
 	"math"
-	"os"
 	"path/filepath"
 	"slices"
 	"strconv"
@@ -107,13 +106,14 @@ func float32Or(p *float32, def float32) float32 {
 //  2. MTP nDraft override (ModelFiles empty): when the target GGUF ships
 //     an auto-detected MTP head, this block sets the number of draft tokens
 //     per round without supplying a separate model. NDraft defaults to
-//     defMTPNDraft when left unset.
+//     defMTPNDraft when left unset. With Speculation set to ngram, it sets
+//     the n-gram candidate ceiling instead, defaulting to defNGramNDraft.
 //
 // A model can have at most one drafter. If ModelFiles is set, the
 // separate-GGUF drafter wins even on a target that also has an MTP head.
 type DraftModelConfig struct {
 	ModelFiles    []string  // Path to the draft model GGUF file(s); empty means MTP nDraft override
-	NDraft        int       // Number of tokens to draft per step (separate-GGUF default 5, MTP default 3)
+	NDraft        int       // Number of tokens to draft per step (separate-GGUF default 5, MTP default 3, n-gram default 8)
 	PtrNGpuLayers *int      // GPU layers for draft model (nil = all layers on GPU)
 	Devices       []string  // Devices for draft model (e.g., ["CUDA0"])
 	PtrMainGPU    *int      // Primary GPU index for draft model
@@ -129,18 +129,34 @@ func (d DraftModelConfig) MainGPU() int    { return intOr(d.PtrMainGPU, 0) }
 func (d DraftModelConfig) IsSeparate() bool { return len(d.ModelFiles) > 0 }
 
 // AdapterConfig configures a local llama.cpp-compatible LoRA adapter GGUF.
-// Scale is fixed for the lifetime of the loaded model.
+// ID names the adapter in requests; when empty, the file name without its
+// extension is used. Scale is the weight applied to requests that do not
+// select adapters; an adapter with a zero scale is loaded but only applied
+// when a request selects it.
 type AdapterConfig struct {
+	ID    string
 	Path  string
 	Scale float32
 }
 
+// AdapterID returns the ID requests use to select the adapter.
+func (a AdapterConfig) AdapterID() string {
+	if a.ID != "" {
+		return a.ID
+	}
+
+	return strings.TrimSuffix(filepath.Base(a.Path), filepath.Ext(a.Path))
+}
+
 // Config represents model level configuration. These values if configured
 // incorrectly can cause the system to panic. The defaults are used when these
 // values are set to 0.
 //
 // Adapters contains local llama.cpp-compatible LoRA adapter GGUF files to load
-// with the model. Adapter scales are fixed for the lifetime of the model.
+// with the model. Requests that do not select adapters run with every
+// configured adapter at its configured scale; a request may instead select
+// any loaded adapters and scales by ID. More adapters can be loaded and
+// unloaded while the model runs.
 //
 // ArtifactIntegrity contains expected artifact verification state keyed by
 // model file path. It is used to avoid repeating completed verification work.
@@ -181,6 +197,13 @@ type AdapterConfig struct {
 // conversation or text generation task.
 // When set to 0, the default value is 4096.
 //
+// PtrContextShift enables context shifting for generation that reaches the
+// context window. Instead of ending the request with an error, Kronk discards
+// the older half of the sequence after its first tokens and keeps generating.
+// It applies only to text requests on models whose KV memory supports
+// position shifts and that run without a draft model or MTP head. When nil
+// or false, generation that reaches the context window fails.
+//
 // DefaultParams contains the default sampling parameters for requests.
 //
 // ChatTemplateKwargs contains model-level defaults passed only to the Jinja
@@ -348,6 +371,10 @@ type AdapterConfig struct {
 // true, SWA layers use the full context window for their KV cache, preserving
 // accuracy at the cost of higher memory usage.
 //
+// Truncation is the default TruncationMode for text chat requests that do not
+// set truncation themselves. When empty, TruncationDisabled is used and a
+// prompt that does not fit the context window is rejected.
+//
 // TensorBuftOverrides is a list of tensor buffer type override patterns that
 // force matching tensors to execute on CPU instead of GPU. This is an expert-level
 // configuration useful for MoE models where certain FFN expert tensors don't fit
@@ -385,6 +412,7 @@ type Config struct {
 	PtrCacheMinTokens          *int
 	CacheTypeK                 GGMLType
 	CacheTypeV                 GGMLType
+	PtrContextShift            *bool
 	PtrContextWindow           *int
 	DefaultParams              Params
 	ChatTemplateKwargs         D
@@ -425,6 +453,7 @@ type Config struct {
 	PtrSWAFull                 *bool
 	TensorBuftOverrides        []string
 	TensorSplit                []float32
+	Truncation                 TruncationMode
 	PtrYarnAttnFactor          *float32
 	PtrYarnBetaFast            *float32
 	PtrYarnBetaSlow            *float32
@@ -467,6 +496,7 @@ func (cfg Config) YarnBetaSlow() float32   { return float32Or(cfg.PtrYarnBetaSlo
 func (cfg Config) YarnExtFactor() float32  { return float32Or(cfg.PtrYarnExtFactor, 0) }
 func (cfg Config) YarnOrigCtx() int        { return intOr(cfg.PtrYarnOrigCtx, 0) }
 func (cfg Config) IncrementalCache() bool  { return boolOr(cfg.PtrIncrementalCache, false) }
+func (cfg Config) ContextShift() bool      { return boolOr(cfg.PtrContextShift, false) }
 func (cfg Config) InsecureLogging() bool   { return boolOr(cfg.PtrInsecureLogging, false) }
 
 // SpeculationMode returns the selected speculative-decoding implementation.
@@ -478,6 +508,15 @@ func (cfg Config) SpeculationMode() SpeculationMode {
 	return cfg.Speculation
 }
 
+// TruncationMode returns the default truncation mode for text chat requests.
+// The zero value disables truncation.
+func (cfg Config) TruncationMode() TruncationMode {
+	if cfg.Truncation == "" {
+		return TruncationDisabled
+	}
+	return cfg.Truncation
+}
+
 // FlashAttention returns the configured flash attention mode. An unset value
 // defaults to auto so llama.cpp can select the supported mode.
 func (cfg Config) FlashAttention() FlashAttentionType {
@@ -536,9 +575,9 @@ func (cfg Config) String() string {
 		return fmt.Sprintf("{mode:%s top_n:%s}", m.Mode, topN)
 	}
 
-	return fmt.Sprintf("\nAdapters[%v]\nAdmissionTimeout[%s]\nAutoTune[%t]\nCacheMinTokens[%s]\nCacheTypeK[%s]\nCacheTypeV[%s]\nContextWindow[%s]\nDefaultParams[%s]\nChatTemplateKwargs[%s]\nDevices[%v]\nFlashAttention[%s]\nIMCSessionCapacity[%d]\nIncrementalCache[%s]\nInsecureLogging[%s]\nJinjaFile[%s]\nLoadMode[%s]\nMainGPU[%s]\nMoE[%s]\nModelFiles[%v]\nNGpuLayers[%s]\nNSeqMax[%s]\nNThreads[%s]\nNThreadsBatch[%s]\nPrefillBatchSize[%s]\nEffectiveNBatch[%d]\nEffectiveNUBatch[%d]\nNUMA[%s]\nOffloadKQV[%s]\nOpOffload[%s]\nOpOffloadMinBatch[%s]\nProjFile[%s]\nMTPDrafterFile[%s]\nProjOnCPU[%s]\nProjDevice[%s]\nQueueDepth[%d]\nRopeFreqBase[%s]\nRopeFreqScale[%s]\nRopeScaling[%s]\nSessionStoreFactory[%t]\nSpeculation[%s]\nSplitMode[%s]\nSWAFull[%s]\nTensorBuftOverrides[%v]\nTensorSplit[%v]\nYarnAttnFactor[%s]\nYarnBetaFast[%s]\nYarnBetaSlow[%s]\nYarnExtFactor[%s]\nYarnOrigCtx[%s]\nDraftModel[%v]\n",
+	return fmt.Sprintf("\nAdapters[%v]\nAdmissionTimeout[%s]\nAutoTune[%t]\nCacheMinTokens[%s]\nCacheTypeK[%s]\nCacheTypeV[%s]\nContextShift[%s]\nContextWindow[%s]\nDefaultParams[%s]\nChatTemplateKwargs[%s]\nDevices[%v]\nFlashAttention[%s]\nIMCSessionCapacity[%d]\nIncrementalCache[%s]\nInsecureLogging[%s]\nJinjaFile[%s]\nLoadMode[%s]\nMainGPU[%s]\nMoE[%s]\nModelFiles[%v]\nNGpuLayers[%s]\nNSeqMax[%s]\nNThreads[%s]\nNThreadsBatch[%s]\nPrefillBatchSize[%s]\nEffectiveNBatch[%d]\nEffectiveNUBatch[%d]\nNUMA[%s]\nOffloadKQV[%s]\nOpOffload[%s]\nOpOffloadMinBatch[%s]\nProjFile[%s]\nMTPDrafterFile[%s]\nProjOnCPU[%s]\nProjDevice[%s]\nQueueDepth[%d]\nRopeFreqBase[%s]\nRopeFreqScale[%s]\nRopeScaling[%s]\nSessionStoreFactory[%t]\nSpeculation[%s]\nSplitMode[%s]\nSWAFull[%s]\nTensorBuftOverrides[%v]\nTensorSplit[%v]\nTruncation[%s]\nYarnAttnFactor[%s]\nYarnBetaFast[%s]\nYarnBetaSlow[%s]\nYarnExtFactor[%s]\nYarnOrigCtx[%s]\nDraftModel[%v]\n",
 		cfg.Adapters, formatDurationPtr(cfg.PtrAdmissionTimeout), cfg.AutoTune, formatIntPtr(cfg.PtrCacheMinTokens), cfg.CacheTypeK, cfg.CacheTypeV,
-		formatIntPtr(cfg.PtrContextWindow), cfg.DefaultParams.String(), chatTemplateKwargsSummary(cfg.ChatTemplateKwargs), cfg.Devices, cfg.FlashAttention(),
+		formatBoolPtr(cfg.PtrContextShift), formatIntPtr(cfg.PtrContextWindow), cfg.DefaultParams.String(), chatTemplateKwargsSummary(cfg.ChatTemplateKwargs), cfg.Devices, cfg.FlashAttention(),
 		cfg.IMCSessionCapacity(), formatBoolPtr(cfg.PtrIncrementalCache), formatBoolPtr(cfg.PtrInsecureLogging), cfg.JinjaFile,
 		cfg.LoadMode, formatIntPtr(cfg.PtrMainGPU), formatMoEPtr(cfg.PtrMoE), cfg.ModelFiles,
 		formatIntPtr(cfg.PtrNGpuLayers), formatIntPtr(cfg.PtrNSeqMax), formatIntPtr(cfg.PtrNThreads), formatIntPtr(cfg.PtrNThreadsBatch), formatIntPtr(cfg.PtrPrefillBatchSize), cfg.EffectiveNBatch(), cfg.EffectiveNUBatch(),
@@ -547,7 +586,7 @@ func (cfg Config) String() string {
 		formatFloat32Ptr(cfg.PtrRopeFreqBase), formatFloat32Ptr(cfg.PtrRopeFreqScale), cfg.RopeScaling,
 		cfg.SessionStoreFactory != nil, cfg.SpeculationMode(),
 		formatSplitModePtr(cfg.PtrSplitMode),
-		formatBoolPtr(cfg.PtrSWAFull), cfg.TensorBuftOverrides, cfg.TensorSplit,
+		formatBoolPtr(cfg.PtrSWAFull), cfg.TensorBuftOverrides, cfg.TensorSplit, cfg.TruncationMode(),
 		formatFloat32Ptr(cfg.PtrYarnAttnFactor),
 		formatFloat32Ptr(cfg.PtrYarnBetaFast), formatFloat32Ptr(cfg.PtrYarnBetaSlow), formatFloat32Ptr(cfg.PtrYarnExtFactor), formatIntPtr(cfg.PtrYarnOrigCtx), cfg.PtrDraftModel)
 }
@@ -608,31 +647,23 @@ func validateConfig(ctx context.Context, cfg Config, log applog.Logger) error {
 		}
 	}
 
-	seenAdapters := make(map[string]struct{}, len(cfg.Adapters))
+	seenPaths := make(map[string]struct{}, len(cfg.Adapters))
+	seenIDs := make(map[string]struct{}, len(cfg.Adapters))
 	for i, adapter := range cfg.Adapters {
-		if !filepath.IsAbs(adapter.Path) {
-			return fmt.Errorf("validate-config: adapter[%d] path must be absolute: %q", i, adapter.Path)
-		}
-		if !strings.EqualFold(filepath.Ext(adapter.Path), ".gguf") {
-			return fmt.Errorf("validate-config: adapter[%d] path must have a .gguf extension: %q", i, adapter.Path)
-		}
-		if adapter.Scale < 0 || math.IsNaN(float64(adapter.Scale)) || math.IsInf(float64(adapter.Scale), 0) {
-			return fmt.Errorf("validate-config: adapter[%d] scale must be finite and >= 0, got %g", i, adapter.Scale)
+		if err := validateAdapter(adapter); err != nil {
+			return fmt.Errorf("validate-config: adapter[%d] %w", i, err)
 		}
 
 		adapterPath := filepath.Clean(adapter.Path)
-		if _, exists := seenAdapters[adapterPath]; exists {
+		if _, exists := seenPaths[adapterPath]; exists {
 			return fmt.Errorf("validate-config: duplicate adapter path: %q", adapterPath)
 		}
-		seenAdapters[adapterPath] = struct{}{}
+		seenPaths[adapterPath] = struct{}{}
 
-		fi, err := os.Stat(adapterPath)
-		if err != nil {
-			return fmt.Errorf("validate-config: adapter[%d] path %q: %w", i, adapterPath, err)
-		}
-		if !fi.Mode().IsRegular() {
-			return fmt.Errorf("validate-config: adapter[%d] path is not a regular file: %q", i, adapterPath)
+		if _, exists := seenIDs[adapter.AdapterID()]; exists {
+			return fmt.Errorf("validate-config: duplicate adapter id: %q", adapter.AdapterID())
 		}
+		seenIDs[adapter.AdapterID()] = struct{}{}
 	}
 
 	if len(cfg.TensorSplit) > 0 && len(cfg.Devices) > 0 && len(cfg.TensorSplit) != len(cfg.Devices) {
@@ -654,10 +685,18 @@ func validateConfig(ctx context.Context, cfg Config, log applog.Logger) error {
 	}
 
 	switch cfg.SpeculationMode() {
-	case SpeculationAuto, SpeculationDisabled, SpeculationClassic, SpeculationMTP:
+	case SpeculationAuto, SpeculationDisabled, SpeculationClassic, SpeculationMTP, SpeculationNGram:
 		// valid
 	default:
-		return fmt.Errorf("validate-config: unknown speculation mode %q (valid: auto, disabled, classic, mtp)", cfg.Speculation)
+		return fmt.Errorf("validate-config: unknown speculation mode %q (valid: auto, disabled, classic, mtp, ngram)", cfg.Speculation)
+	}
+
+	if _, err := ParseTruncationMode(string(cfg.Truncation)); err != nil {
+		return fmt.Errorf("validate-config: %w", err)
+	}
+
+	if cfg.SpeculationMode() == SpeculationNGram && cfg.PtrDraftModel != nil && cfg.PtrDraftModel.IsSeparate() {
+		return fmt.Errorf("validate-config: speculation mode ngram cannot use a separate draft model")
 	}
 
 	if cfg.SpeculationMode() != SpeculationDisabled && cfg.PtrDraftModel != nil {
@@ -1860,6 +1899,7 @@ func WithAutoTune(v bool) Option        { return func(c *Config) { c.AutoTune =
 func WithCacheMinTokens(v int) Option   { return func(c *Config) { c.PtrCacheMinTokens = new(v) } }
 func WithCacheTypeK(v GGMLType) Option  { return func(c *Config) { c.CacheTypeK = v } }
 func WithCacheTypeV(v GGMLType) Option  { return func(c *Config) { c.CacheTypeV = v } }
+func WithContextShift(v bool) Option    { return func(c *Config) { c.PtrContextShift = new(v) } }
 func WithContextWindow(v int) Option    { return func(c *Config) { c.PtrContextWindow = new(v) } }
 func WithDefaultParams(v Params) Option { return func(c *Config) { c.DefaultParams = v } }
 func WithChatTemplateKwargs(v D) Option {
@@ -1904,6 +1944,7 @@ func WithSplitMode(v SplitMode) Option          { return func(c *Config) { c.Ptr
 func WithSWAFull(v bool) Option                 { return func(c *Config) { c.PtrSWAFull = new(v) } }
 func WithTensorBuftOverrides(v []string) Option { return func(c *Config) { c.TensorBuftOverrides = v } }
 func WithTensorSplit(v []float32) Option        { return func(c *Config) { c.TensorSplit = v } }
+func WithTruncation(v TruncationMode) Option    { return func(c *Config) { c.Truncation = v } }
 func WithYarnAttnFactor(v float32) Option       { return func(c *Config) { c.PtrYarnAttnFactor = new(v) } }
 func WithYarnBetaFast(v float32) Option         { return func(c *Config) { c.PtrYarnBetaFast = new(v) } }
 func WithYarnBetaSlow(v float32) Option         { return func(c *Config) { c.PtrYarnBetaSlow = new(v) } }
@@ -655,6 +655,15 @@ func TestSpeculationMode(t *testing.T) {
 	}
 }
 
+func TestTruncationMode(t *testing.T) {
+	if got := NewConfig().TruncationMode(); got != TruncationDisabled {
+		t.Errorf("default TruncationMode() = %q, want %q", got, TruncationDisabled)
+	}
+	if got := NewConfig(WithTruncation(TruncationAuto)).TruncationMode(); got != TruncationAuto {
+		t.Errorf("TruncationMode() = %q, want %q", got, TruncationAuto)
+	}
+}
+
 func TestMTPNDraft(t *testing.T) {
 	tests := []struct {
 		name string
@@ -682,6 +691,45 @@ func TestMTPNDraft(t *testing.T) {
 	}
 }
 
+func TestResolveSpeculationPlanNGram(t *testing.T) {
+	discardLogger := func(ctx context.Context, msg string, args ...any) {}
+
+	tests := []struct {
+		name       string
+		cfg        Config
+		wantNDraft int
+		wantErr    bool
+	}{
+		{"default ceiling", NewConfig(WithSpeculationMode(SpeculationNGram)), defNGramNDraft, false},
+		{"override sets ceiling", NewConfig(
+			WithSpeculationMode(SpeculationNGram),
+			WithDraftModel(&DraftModelConfig{NDraft: 12}),
+		), 12, false},
+		{"separate draft rejected", NewConfig(
+			WithSpeculationMode(SpeculationNGram),
+			WithDraftModel(&DraftModelConfig{ModelFiles: []string{"d.gguf"}}),
+		), 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := resolveSpeculationPlan(context.Background(), discardLogger, tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveSpeculationPlan() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if plan.Source != speculationSourceNGram || !plan.Active() || plan.Drafter() {
+				t.Errorf("plan = %+v, want an active n-gram plan without a drafter", plan)
+			}
+			if plan.NDraft != tt.wantNDraft {
+				t.Errorf("NDraft = %d, want %d", plan.NDraft, tt.wantNDraft)
+			}
+		})
+	}
+}
+
 func TestValidateConfig(t *testing.T) {
 	discardLogger := func(ctx context.Context, msg string, args ...any) {}
 	tempDir := t.TempDir()
@@ -689,6 +737,10 @@ func TestValidateConfig(t *testing.T) {
 	if err := os.WriteFile(adapterFile, []byte("adapter"), 0600); err != nil {
 		t.Fatalf("WriteFile() error = %v", err)
 	}
+	otherAdapterFile := filepath.Join(tempDir, "other.gguf")
+	if err := os.WriteFile(otherAdapterFile, []byte("adapter"), 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
 
 	tests := []struct {
 		want    string
@@ -740,6 +792,10 @@ func TestValidateConfig(t *testing.T) {
 			WithModelFiles([]string{"dummy.gguf"}),
 			WithAdapters([]AdapterConfig{{Path: adapterFile, Scale: 1}, {Path: adapterFile, Scale: 0.5}}),
 		), true},
+		{"duplicate adapter id is invalid", NewConfig(
+			WithModelFiles([]string{"dummy.gguf"}),
+			WithAdapters([]AdapterConfig{{Path: adapterFile, Scale: 1}, {ID: "adapter", Path: otherAdapterFile, Scale: 0}}),
+		), true},
 		{"negative queue depth is invalid", NewConfig(
 			WithModelFiles([]string{"dummy.gguf"}),
 			WithQueueDepth(-1),
@@ -812,6 +868,14 @@ func TestValidateConfig(t *testing.T) {
 			WithProjDevice("CUDA1"),
 			WithProjOnCPU(true),
 		), true},
+		{"middle-out truncation is valid", NewConfig(
+			WithModelFiles([]string{"dummy.gguf"}),
+			WithTruncation(TruncationMiddleOut),
+		), false},
+		{"unknown truncation mode", NewConfig(
+			WithModelFiles([]string{"dummy.gguf"}),
+			WithTruncation("oldest"),
+		), true},
 	}
 	{
 		for _, tt := range tests {
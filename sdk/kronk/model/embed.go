@@ -15,10 +15,17 @@ import (
 // Embeddings performs embedding for one or more inputs.
 //
 // Supported options in d:
-//   - input ([]string): the texts to embed (required)
+//   - input (string, []string, []int, or [][]int): the texts or token IDs to embed (required)
 //   - truncate (bool): if true, truncate inputs to fit context window (default: false)
 //   - truncate_direction (string): "right" (default) or "left"
 //   - dimensions (int): reduce output to first N dimensions (for Matryoshka models)
+//   - encoding_format (string): "float" (default) or "base64"
+//   - chunking (bool or object): split over-long inputs into overlapping chunks and pool them
+//
+// The chunking object holds size (tokens per chunk, default: the most the
+// model accepts), overlap (tokens shared by neighboring chunks, default: 0),
+// pooling ("mean" weighted by chunk length, the default, or "max"), and
+// return_chunks (include each chunk's embedding and token offsets).
 //
 // Supported models process inputs together as a multi-sequence batch. Other
 // models use the context-pool fallback.
@@ -46,50 +53,38 @@ func (m *Model) Embeddings(ctx context.Context, d D) (response EmbedReponse, err
 		metrics.ObserveInferenceRequest(m.modelInfo.ID, "embedding", runtimeName, status, time.Since(started), totalPromptTokens)
 	}()
 
-	var inputs []string
-
-	switch v := d["input"].(type) {
-	case string:
-		inputs = []string{v}
-
-	case []string:
-		inputs = v
-
-	case []any:
-		inputs = make([]string, len(v))
-		for i, item := range v {
-			s, ok := item.(string)
-			if !ok {
-				return EmbedReponse{}, fmt.Errorf("embeddings: input[%d] is not a string", i)
-			}
-			inputs[i] = s
-		}
-
-	default:
-		return EmbedReponse{}, fmt.Errorf("embeddings: missing or invalid input parameter (expected string or []string)")
+	inputs, err := parseEmbedInputs(d["input"])
+	if err != nil {
+		return EmbedReponse{}, fmt.Errorf("embeddings: %w", err)
 	}
 
-	if len(inputs) == 0 {
-		return EmbedReponse{}, fmt.Errorf("embeddings: input cannot be empty")
+	opts, err := parseEmbedOptions(d)
+	if err != nil {
+		return EmbedReponse{}, fmt.Errorf("embeddings: %w", err)
 	}
 
 	// -------------------------------------------------------------------------
 
-	truncate, _ := d["truncate"].(bool)
-	direction, _ := d["truncate_direction"].(string)
 	nativeDim := llama.ModelNEmbd(m.model)
-	requestedDim, _ := d["dimensions"].(float64)
 
-	if requestedDim > 0 && int(requestedDim) > int(nativeDim) {
-		return EmbedReponse{}, fmt.Errorf("embeddings: requested %d dimensions but model only has %d", int(requestedDim), nativeDim)
+	if opts.dimensions > int(nativeDim) {
+		return EmbedReponse{}, fmt.Errorf("embeddings: requested %d dimensions but model only has %d", opts.dimensions, nativeDim)
 	}
 
 	// -------------------------------------------------------------------------
 
-	var embedData []EmbedData
+	var seqs []embedSequence
+	var vectors [][]float32
 
 	if m.batchSeq != nil {
-		embedData, totalPromptTokens, err = m.processEmbeddingsBatchSeq(ctx, inputs, truncate, direction, nativeDim, int(requestedDim))
+		maxTokens := min(m.batchSeq.maxTokens, m.cfg.ContextWindow())
+
+		seqs, err = m.embedSequences(ctx, inputs, maxTokens, opts)
+		if err != nil {
+			return EmbedReponse{}, err
+		}
+
+		vectors, err = m.processEmbeddingsBatchSeq(ctx, seqs, nativeDim)
 	} else {
 		// The fallback runtime processes concurrent requests on independent
 		// single-sequence contexts.
@@ -99,19 +94,30 @@ func (m *Model) Embeddings(ctx context.Context, d D) (response EmbedReponse, err
 		}
 		defer m.pool.release(pc)
 
-		embedData, totalPromptTokens, err = m.processEmbeddings(ctx, pc, inputs, truncate, direction, nativeDim, int(requestedDim))
+		maxTokens := min(int(llama.NUBatch(pc.lctx)), int(llama.NCtx(pc.lctx)))
+
+		seqs, err = m.embedSequences(ctx, inputs, maxTokens, opts)
+		if err != nil {
+			return EmbedReponse{}, err
+		}
+
+		vectors, err = m.processEmbeddings(ctx, pc, seqs, nativeDim)
 	}
 	if err != nil {
 		return EmbedReponse{}, err
 	}
 
+	for _, seq := range seqs {
+		totalPromptTokens += len(seq.tokens)
+	}
+
 	// -------------------------------------------------------------------------
 
 	er := EmbedReponse{
 		Object:  "list",
 		Created: time.Now().Unix(),
 		Model:   m.responseModelID(),
-		Data:    embedData,
+		Data:    toEmbedData(len(inputs), seqs, vectors, opts),
 		Usage: EmbedUsage{
 			PromptTokens: totalPromptTokens,
 			TotalTokens:  totalPromptTokens,
@@ -121,124 +127,161 @@ func (m *Model) Embeddings(ctx context.Context, d D) (response EmbedReponse, err
 	return er, nil
 }
 
-// processEmbeddingsBatchSeq processes inputs as multi-sequence batches on one
-// llama context.
-func (m *Model) processEmbeddingsBatchSeq(ctx context.Context, inputs []string, truncate bool, direction string, nativeDim int32, requestedDim int) ([]EmbedData, int, error) {
-	maxTokens := min(m.batchSeq.maxTokens, m.cfg.ContextWindow())
-	items := make([]batchSeqItem, len(inputs))
-	totalTokens := 0
+// embedSequences tokenizes the inputs and returns the token sequences to
+// embed. Inputs longer than maxTokens are split into chunks when chunking is
+// requested, truncated when truncate is set, and rejected otherwise.
+func (m *Model) embedSequences(ctx context.Context, inputs []embedInput, maxTokens int, opts embedOptions) ([]embedSequence, error) {
+	nVocab := llama.Token(llama.VocabNTokens(m.vocab))
+
+	chunkSize := maxTokens
+	if opts.chunking != nil && opts.chunking.size > 0 {
+		if opts.chunking.size > maxTokens {
+			return nil, fmt.Errorf("embeddings: %w: chunking size %d exceeds the maximum of %d tokens", ErrInvalidRequest, opts.chunking.size, maxTokens)
+		}
+		chunkSize = opts.chunking.size
+	}
+
+	seqs := make([]embedSequence, 0, len(inputs))
 
 	for i, input := range inputs {
 		if err := ctx.Err(); err != nil {
-			return nil, 0, err
+			return nil, err
 		}
 
-		tokens := llama.Tokenize(m.vocab, input, m.addBOSToken, true)
-		if len(tokens) > maxTokens {
-			if !truncate {
-				return nil, 0, fmt.Errorf("embeddings: input[%d] has %d tokens but max is %d (set truncate=true to auto-truncate)", i, len(tokens), maxTokens)
+		tokens := input.tokens
+		if tokens == nil {
+			tokens = llama.Tokenize(m.vocab, input.text, m.addBOSToken, true)
+		}
+
+		for _, token := range input.tokens {
+			if token >= nVocab {
+				return nil, fmt.Errorf("embeddings: %w: input[%d] token %d is outside the vocabulary of %d tokens", ErrInvalidRequest, i, token, nVocab)
+			}
+		}
+
+		switch {
+		case opts.chunking != nil && len(tokens) > chunkSize:
+			prefix, suffix := m.specialTokenSpan(tokens)
+			content := tokens[prefix : len(tokens)-suffix]
+
+			windows, err := chunkWindows(len(content), chunkSize-prefix-suffix, opts.chunking.overlap)
+			if err != nil {
+				return nil, fmt.Errorf("embeddings: %w: %w", ErrInvalidRequest, err)
+			}
+
+			for _, w := range windows {
+				chunk := make([]llama.Token, 0, prefix+w.end-w.start+suffix)
+				chunk = append(chunk, tokens[:prefix]...)
+				chunk = append(chunk, content[w.start:w.end]...)
+				chunk = append(chunk, tokens[len(tokens)-suffix:]...)
+
+				seqs = append(seqs, embedSequence{input: i, start: prefix + w.start, end: prefix + w.end, tokens: chunk})
+			}
+
+			m.log(ctx, "embeddings", "status", "chunked input", "index", i, "tokens", len(tokens), "chunk_size", chunkSize, "overlap", opts.chunking.overlap, "chunks", len(windows))
+
+			continue
+
+		case len(tokens) > maxTokens:
+			if !opts.truncate {
+				return nil, fmt.Errorf("embeddings: %w: input[%d] has %d tokens but max is %d (set truncate=true to auto-truncate or chunking=true to pool chunks)", ErrInvalidRequest, i, len(tokens), maxTokens)
 			}
 
 			originalLen := len(tokens)
-			switch direction {
+
+			switch opts.direction {
 			case "left":
 				tokens = tokens[len(tokens)-maxTokens:]
+
 			default:
 				tokens = tokens[:maxTokens]
 			}
 
-			m.log(ctx, "embeddings", "status", "truncated input", "index", i, "original_tokens", originalLen, "max_tokens", maxTokens, "direction", direction, "truncated_tokens", len(tokens))
+			m.log(ctx, "embeddings", "status", "truncated input", "index", i, "original_tokens", originalLen, "max_tokens", maxTokens, "direction", opts.direction, "truncated_tokens", len(tokens))
 		}
 
-		items[i] = batchSeqItem{index: i, tokens: tokens}
-		totalTokens += len(tokens)
+		seqs = append(seqs, embedSequence{input: i, start: 0, end: len(tokens), tokens: tokens})
 	}
 
-	outputs, err := m.batchSeq.run(ctx, items, int(nativeDim))
-	if err != nil {
-		return nil, 0, fmt.Errorf("embeddings: batchseq inference: %w", err)
-	}
+	return seqs, nil
+}
 
-	embedData := make([]EmbedData, len(outputs))
-	for i, output := range outputs {
-		embedData[i] = EmbedData{
-			Object:    "embedding",
-			Index:     i,
-			Embedding: embeddingVector(output, requestedDim),
-		}
+// specialTokenSpan returns how many tokens at the start and end of tokens are
+// the special tokens the tokenizer adds around an input, so every chunk of a
+// split input can carry them.
+func (m *Model) specialTokenSpan(tokens []llama.Token) (int, int) {
+	if len(tokens) == 0 {
+		return 0, 0
 	}
 
-	return embedData, totalTokens, nil
-}
+	var prefix, suffix int
 
-// processEmbeddings processes all inputs on a single context.
-func (m *Model) processEmbeddings(ctx context.Context, pc poolContext, inputs []string, truncate bool, direction string, nativeDim int32, requestedDim int) ([]EmbedData, int, error) {
-	maxTokens := int(llama.NUBatch(pc.lctx))
-	ctxTokens := int(llama.NCtx(pc.lctx))
-	if ctxTokens < maxTokens {
-		maxTokens = ctxTokens
+	if m.addBOSToken && tokens[0] == llama.VocabBOS(m.vocab) {
+		prefix = 1
 	}
 
-	embedData := make([]EmbedData, len(inputs))
-	totalTokens := 0
-
-	for i, input := range inputs {
-		select {
-		case <-ctx.Done():
-			return nil, 0, ctx.Err()
+	last := tokens[len(tokens)-1]
+	if len(tokens) > prefix &&
+		((llama.VocabGetAddSEP(m.vocab) && last == llama.VocabSEP(m.vocab)) ||
+			(llama.VocabGetAddEOS(m.vocab) && last == llama.VocabEOS(m.vocab))) {
+		suffix = 1
+	}
 
-		default:
-		}
+	return prefix, suffix
+}
 
-		tokens := llama.Tokenize(m.vocab, input, m.addBOSToken, true)
+// processEmbeddingsBatchSeq embeds the sequences as multi-sequence batches on
+// one llama context and returns the native vector of each sequence.
+func (m *Model) processEmbeddingsBatchSeq(ctx context.Context, seqs []embedSequence, nativeDim int32) ([][]float32, error) {
+	items := make([]batchSeqItem, len(seqs))
+	for i, seq := range seqs {
+		items[i] = batchSeqItem{index: i, tokens: seq.tokens}
+	}
 
-		if len(tokens) > maxTokens {
-			if !truncate {
-				return nil, 0, fmt.Errorf("embeddings: input[%d] has %d tokens but max is %d (set truncate=true to auto-truncate)", i, len(tokens), maxTokens)
-			}
+	outputs, err := m.batchSeq.run(ctx, items, int(nativeDim))
+	if err != nil {
+		return nil, fmt.Errorf("embeddings: batchseq inference: %w", err)
+	}
 
-			originalLen := len(tokens)
+	return outputs, nil
+}
 
-			switch direction {
-			case "left":
-				tokens = tokens[len(tokens)-maxTokens:]
+// processEmbeddings embeds the sequences one at a time on a single context
+// and returns the native vector of each sequence.
+func (m *Model) processEmbeddings(ctx context.Context, pc poolContext, seqs []embedSequence, nativeDim int32) ([][]float32, error) {
+	vectors := make([][]float32, len(seqs))
 
-			default:
-				tokens = tokens[:maxTokens]
-			}
+	for i, seq := range seqs {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
 
-			m.log(ctx, "embeddings", "status", "truncated input", "index", i, "original_tokens", originalLen, "max_tokens", maxTokens, "direction", direction, "truncated_tokens", len(tokens))
+		default:
 		}
 
-		totalTokens += len(tokens)
-
-		batch := llama.BatchGetOne(tokens)
+		batch := llama.BatchGetOne(seq.tokens)
 
 		ret, err := llama.Decode(pc.lctx, batch)
 		if err != nil {
-			return nil, 0, fmt.Errorf("embeddings: decode failed for input[%d]: %w", i, err)
+			return nil, fmt.Errorf("embeddings: decode failed for input[%d]: %w", seq.input, err)
 		}
 
 		if ret != 0 {
-			return nil, 0, fmt.Errorf("embeddings: decode returned non-zero for input[%d]: %d", i, ret)
+			return nil, fmt.Errorf("embeddings: decode returned non-zero for input[%d]: %d", seq.input, ret)
 		}
 
 		rawVec, err := llama.GetEmbeddingsSeq(pc.lctx, 0, nativeDim)
 		if err != nil {
-			return nil, 0, fmt.Errorf("embeddings: unable to get embeddings for input[%d]: %w", i, err)
+			return nil, fmt.Errorf("embeddings: unable to get embeddings for input[%d]: %w", seq.input, err)
 		}
 
-		embedData[i] = EmbedData{
-			Object:    "embedding",
-			Index:     i,
-			Embedding: embeddingVector(rawVec, requestedDim),
-		}
+		vectors[i] = slices.Clone(rawVec)
 
 		// Clear KV cache before next input.
 		llama.MemoryClear(pc.mem, true)
 	}
 
-	return embedData, totalTokens, nil
+	return vectors, nil
 }
 
 // embeddingVector copies, optionally reduces, and normalizes a native
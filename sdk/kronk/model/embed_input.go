@@ -0,0 +1,366 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+// embedInput is one input of an embeddings request, given as text or as
+// token IDs. Token IDs are embedded as given, without special tokens added.
+type embedInput struct {
+	text   string
+	tokens []llama.Token
+}
+
+// embedOptions holds the options of an embeddings request.
+type embedOptions struct {
+	truncate   bool
+	direction  string
+	dimensions int
+	base64     bool
+	chunking   *embedChunking
+}
+
+// embedChunking configures how over-long inputs are split and pooled. A zero
+// size uses the most tokens the model accepts.
+type embedChunking struct {
+	size         int
+	overlap      int
+	pooling      string
+	returnChunks bool
+}
+
+// embedSequence is a token sequence to embed. Start and end are the offsets
+// of its tokens in the tokens of its input, which span the whole input
+// unless the input was split into chunks.
+type embedSequence struct {
+	input  int
+	start  int
+	end    int
+	tokens []llama.Token
+}
+
+// embedWindow is the token range of one chunk.
+type embedWindow struct {
+	start int
+	end   int
+}
+
+// parseEmbedInputs accepts a string, an array of strings, an array of token
+// IDs, or an array of token ID arrays.
+func parseEmbedInputs(val any) ([]embedInput, error) {
+	switch v := val.(type) {
+	case string:
+		return []embedInput{{text: v}}, nil
+
+	case []string:
+		if len(v) == 0 {
+			return nil, fmt.Errorf("%w: input cannot be empty", ErrInvalidRequest)
+		}
+
+		inputs := make([]embedInput, len(v))
+		for i, s := range v {
+			inputs[i] = embedInput{text: s}
+		}
+		return inputs, nil
+
+	case []int:
+		tokens, err := embedTokens(v)
+		if err != nil {
+			return nil, err
+		}
+		return []embedInput{{tokens: tokens}}, nil
+
+	case [][]int:
+		if len(v) == 0 {
+			return nil, fmt.Errorf("%w: input cannot be empty", ErrInvalidRequest)
+		}
+
+		inputs := make([]embedInput, len(v))
+		for i, ids := range v {
+			tokens, err := embedTokens(ids)
+			if err != nil {
+				return nil, fmt.Errorf("input[%d]: %w", i, err)
+			}
+			inputs[i] = embedInput{tokens: tokens}
+		}
+		return inputs, nil
+
+	case []any:
+		if len(v) == 0 {
+			return nil, fmt.Errorf("%w: input cannot be empty", ErrInvalidRequest)
+		}
+
+		switch v[0].(type) {
+		case string:
+			inputs := make([]embedInput, len(v))
+			for i, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("%w: input[%d] is not a string", ErrInvalidRequest, i)
+				}
+				inputs[i] = embedInput{text: s}
+			}
+			return inputs, nil
+
+		case []any, []int:
+			inputs := make([]embedInput, len(v))
+			for i, item := range v {
+				tokens, err := embedTokens(item)
+				if err != nil {
+					return nil, fmt.Errorf("input[%d]: %w", i, err)
+				}
+				inputs[i] = embedInput{tokens: tokens}
+			}
+			return inputs, nil
+
+		default:
+			tokens, err := embedTokens(v)
+			if err != nil {
+				return nil, err
+			}
+			return []embedInput{{tokens: tokens}}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: missing or invalid input parameter (expected a string, an array of strings, or token ID arrays)", ErrInvalidRequest)
+}
+
+func embedTokens(val any) ([]llama.Token, error) {
+	var ids []any
+	switch v := val.(type) {
+	case []any:
+		ids = v
+	case []int:
+		ids = make([]any, len(v))
+		for i, id := range v {
+			ids[i] = id
+		}
+	default:
+		return nil, fmt.Errorf("%w: input must be an array of token IDs", ErrInvalidRequest)
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: token ID array cannot be empty", ErrInvalidRequest)
+	}
+
+	tokens := make([]llama.Token, len(ids))
+	for i, v := range ids {
+		id, ok := tokenID(v)
+		if !ok {
+			return nil, fmt.Errorf("%w: input must be an array of token IDs", ErrInvalidRequest)
+		}
+		tokens[i] = id
+	}
+
+	return tokens, nil
+}
+
+// parseEmbedOptions reads the truncation, dimensions, encoding and chunking
+// options of an embeddings request.
+func parseEmbedOptions(d D) (embedOptions, error) {
+	opts := embedOptions{}
+	opts.truncate, _ = d["truncate"].(bool)
+	opts.direction, _ = d["truncate_direction"].(string)
+
+	if val, exists := d["dimensions"]; exists && val != nil {
+		dims, err := parseInt("dimensions", val)
+		if err != nil {
+			return embedOptions{}, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+		}
+		if dims < 0 {
+			return embedOptions{}, fmt.Errorf("%w: dimensions must not be negative", ErrInvalidRequest)
+		}
+		opts.dimensions = dims
+	}
+
+	switch format := d["encoding_format"]; format {
+	case nil, "float":
+	case "base64":
+		opts.base64 = true
+	default:
+		return embedOptions{}, fmt.Errorf("%w: encoding_format must be float or base64", ErrInvalidRequest)
+	}
+
+	chunking, err := parseEmbedChunking(d["chunking"])
+	if err != nil {
+		return embedOptions{}, fmt.Errorf("%w: chunking: %w", ErrInvalidRequest, err)
+	}
+	opts.chunking = chunking
+
+	return opts, nil
+}
+
+// parseEmbedChunking accepts a boolean, which turns chunking on with the
+// defaults, or an object with size, overlap, pooling and return_chunks.
+func parseEmbedChunking(val any) (*embedChunking, error) {
+	if val == nil {
+		return nil, nil
+	}
+
+	if enabled, ok := val.(bool); ok {
+		if !enabled {
+			return nil, nil
+		}
+		return &embedChunking{pooling: "mean"}, nil
+	}
+
+	obj, ok := mapFromPart(val)
+	if !ok {
+		return nil, errors.New("must be a boolean or an object")
+	}
+
+	c := embedChunking{pooling: "mean"}
+
+	if v, exists := obj["size"]; exists && v != nil {
+		size, err := parseInt("chunking.size", v)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, errors.New("size must not be negative")
+		}
+		c.size = size
+	}
+
+	if v, exists := obj["overlap"]; exists && v != nil {
+		overlap, err := parseInt("chunking.overlap", v)
+		if err != nil {
+			return nil, err
+		}
+		if overlap < 0 {
+			return nil, errors.New("overlap must not be negative")
+		}
+		c.overlap = overlap
+	}
+
+	if v, exists := obj["pooling"]; exists && v != nil {
+		switch v {
+		case "mean", "max":
+			c.pooling = v.(string)
+		default:
+			return nil, errors.New("pooling must be mean or max")
+		}
+	}
+
+	if v, exists := obj["return_chunks"]; exists && v != nil {
+		returnChunks, ok := v.(bool)
+		if !ok {
+			return nil, errors.New("return_chunks must be a boolean")
+		}
+		c.returnChunks = returnChunks
+	}
+
+	return &c, nil
+}
+
+// chunkWindows splits n tokens into windows of at most size tokens, where
+// each window starts overlap tokens before the end of the previous one.
+func chunkWindows(n int, size int, overlap int) ([]embedWindow, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("chunk size leaves no room for input tokens")
+	}
+
+	if overlap >= size {
+		return nil, fmt.Errorf("overlap %d must be smaller than the chunk size of %d input tokens", overlap, size)
+	}
+
+	stride := size - overlap
+
+	var windows []embedWindow
+	for start := 0; ; start += stride {
+		end := min(start+size, n)
+		windows = append(windows, embedWindow{start: start, end: end})
+
+		if end == n {
+			return windows, nil
+		}
+	}
+}
+
+// toEmbedData builds the embedding of each input from the native vectors of
+// its sequences. The vectors of a chunked input are reduced to the requested
+// dimensions and normalized before they are pooled.
+func toEmbedData(nInputs int, seqs []embedSequence, vectors [][]float32, opts embedOptions) []EmbedData {
+	embedData := make([]EmbedData, nInputs)
+	for i := range embedData {
+		embedData[i] = EmbedData{
+			Object: "embedding",
+			Index:  i,
+			base64: opts.base64,
+		}
+	}
+
+	chunkVecs := make([][][]float32, nInputs)
+	weights := make([][]int, nInputs)
+
+	for i, seq := range seqs {
+		vec := embeddingVector(vectors[i], opts.dimensions)
+
+		if opts.chunking == nil {
+			embedData[seq.input].Embedding = vec
+			continue
+		}
+
+		chunkVecs[seq.input] = append(chunkVecs[seq.input], vec)
+		weights[seq.input] = append(weights[seq.input], seq.end-seq.start)
+
+		if opts.chunking.returnChunks {
+			data := &embedData[seq.input]
+			data.Chunks = append(data.Chunks, EmbedChunk{
+				Index:      len(data.Chunks),
+				StartToken: seq.start,
+				EndToken:   seq.end,
+				Embedding:  vec,
+				base64:     opts.base64,
+			})
+		}
+	}
+
+	if opts.chunking != nil {
+		for i := range embedData {
+			embedData[i].Embedding = poolEmbeddings(chunkVecs[i], weights[i], opts.chunking.pooling)
+		}
+	}
+
+	return embedData
+}
+
+// poolEmbeddings combines chunk embeddings into one normalized embedding,
+// either by their mean weighted by chunk length or by their element-wise
+// maximum.
+func poolEmbeddings(vecs [][]float32, weights []int, pooling string) []float32 {
+	if len(vecs) == 0 {
+		return nil
+	}
+
+	pooled := make([]float32, len(vecs[0]))
+
+	switch pooling {
+	case "max":
+		copy(pooled, vecs[0])
+		for _, vec := range vecs[1:] {
+			for j, v := range vec {
+				pooled[j] = max(pooled[j], v)
+			}
+		}
+
+	default:
+		var total float64
+		sums := make([]float64, len(pooled))
+		for i, vec := range vecs {
+			w := float64(max(weights[i], 1))
+			total += w
+			for j, v := range vec {
+				sums[j] += float64(v) * w
+			}
+		}
+		for j, sum := range sums {
+			pooled[j] = float32(sum / total)
+		}
+	}
+
+	return normalizeVector(pooled)
+}
@@ -1,9 +1,16 @@
 package model
 
 import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"math"
 	"slices"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hybridgroup/yzma/pkg/llama"
 )
 
 func TestEmbeddingVector(t *testing.T) {
@@ -24,3 +31,150 @@ func TestEmbeddingVector(t *testing.T) {
 		t.Errorf("raw vector: got %v, want [3 4 12]", raw)
 	}
 }
+
+func TestParseEmbedInputs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input any
+		want  []embedInput
+	}{
+		{name: "string", input: "hello", want: []embedInput{{text: "hello"}}},
+		{name: "strings", input: []any{"a", "b"}, want: []embedInput{{text: "a"}, {text: "b"}}},
+		{name: "token ids", input: []any{json.Number("1"), float64(2)}, want: []embedInput{{tokens: []llama.Token{1, 2}}}},
+		{name: "token arrays", input: []any{[]any{float64(1)}, []any{float64(2), float64(3)}}, want: []embedInput{{tokens: []llama.Token{1}}, {tokens: []llama.Token{2, 3}}}},
+		{name: "go token arrays", input: [][]int{{4}, {5, 6}}, want: []embedInput{{tokens: []llama.Token{4}}, {tokens: []llama.Token{5, 6}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEmbedInputs(tt.input)
+			if err != nil {
+				t.Fatalf("parseEmbedInputs: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(embedInput{})); diff != "" {
+				t.Errorf("inputs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, input := range []any{nil, []any{}, []any{"a", float64(1)}, []any{float64(-1)}, []any{float64(1.5)}, []any{[]any{}}, []any{[]any{"a"}}} {
+		if _, err := parseEmbedInputs(input); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("parseEmbedInputs(%v): got %v, want ErrInvalidRequest", input, err)
+		}
+	}
+}
+
+func TestParseEmbedOptions(t *testing.T) {
+	opts, err := parseEmbedOptions(D{
+		"dimensions":      json.Number("256"),
+		"encoding_format": "base64",
+		"chunking":        D{"size": float64(512), "overlap": float64(64), "pooling": "max", "return_chunks": true},
+	})
+	if err != nil {
+		t.Fatalf("parseEmbedOptions: %v", err)
+	}
+
+	want := embedOptions{
+		dimensions: 256,
+		base64:     true,
+		chunking:   &embedChunking{size: 512, overlap: 64, pooling: "max", returnChunks: true},
+	}
+	if diff := cmp.Diff(want, opts, cmp.AllowUnexported(embedOptions{}, embedChunking{})); diff != "" {
+		t.Errorf("options mismatch (-want +got):\n%s", diff)
+	}
+
+	opts, err = parseEmbedOptions(D{"chunking": true})
+	if err != nil {
+		t.Fatalf("parseEmbedOptions: %v", err)
+	}
+	if opts.chunking == nil || opts.chunking.pooling != "mean" {
+		t.Errorf("chunking true: got %+v, want mean pooling defaults", opts.chunking)
+	}
+
+	for _, d := range []D{
+		{"encoding_format": "int8"},
+		{"dimensions": float64(-1)},
+		{"chunking": "yes"},
+		{"chunking": D{"pooling": "median"}},
+		{"chunking": D{"overlap": float64(-2)}},
+	} {
+		if _, err := parseEmbedOptions(d); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("parseEmbedOptions(%v): got %v, want ErrInvalidRequest", d, err)
+		}
+	}
+}
+
+func TestChunkWindows(t *testing.T) {
+	got, err := chunkWindows(10, 4, 1)
+	if err != nil {
+		t.Fatalf("chunkWindows: %v", err)
+	}
+
+	want := []embedWindow{{0, 4}, {3, 7}, {6, 10}}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(embedWindow{})); diff != "" {
+		t.Errorf("windows mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := chunkWindows(10, 4, 4); err == nil {
+		t.Error("overlap equal to size: want an error")
+	}
+}
+
+func TestToEmbedDataPoolsChunks(t *testing.T) {
+	seqs := []embedSequence{
+		{input: 0, start: 0, end: 3},
+		{input: 1, start: 1, end: 4},
+		{input: 1, start: 3, end: 4},
+	}
+	vectors := [][]float32{{3, 4}, {1, 0}, {0, 1}}
+
+	opts := embedOptions{chunking: &embedChunking{pooling: "mean", returnChunks: true}}
+	data := toEmbedData(2, seqs, vectors, opts)
+
+	// Input 1 weighs its first chunk three times its second.
+	want := []float32{0.948683, 0.316228}
+	for i := range want {
+		if math.Abs(float64(data[1].Embedding[i]-want[i])) > 1e-5 {
+			t.Errorf("mean pooled[%d]: got %f, want %f", i, data[1].Embedding[i], want[i])
+		}
+	}
+
+	if len(data[1].Chunks) != 2 || data[1].Chunks[1].StartToken != 3 || data[1].Chunks[1].EndToken != 4 {
+		t.Errorf("chunks: got %+v", data[1].Chunks)
+	}
+
+	opts.chunking.pooling = "max"
+	data = toEmbedData(2, seqs, vectors, opts)
+	if got := data[1].Embedding; math.Abs(float64(got[0]-got[1])) > 1e-6 {
+		t.Errorf("max pooled: got %v, want equal components", got)
+	}
+}
+
+func TestEmbedDataBase64(t *testing.T) {
+	data := toEmbedData(1, []embedSequence{{input: 0}}, [][]float32{{3, 4}}, embedOptions{base64: true})
+
+	b, err := json.Marshal(data[0])
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var got struct {
+		Embedding string `json:"embedding"`
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("unmarshal %s: %v", b, err)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(got.Embedding)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	vec := []float32{
+		math.Float32frombits(binary.LittleEndian.Uint32(raw[0:])),
+		math.Float32frombits(binary.LittleEndian.Uint32(raw[4:])),
+	}
+	if len(raw) != 8 || math.Abs(float64(vec[0]-0.6)) > 1e-6 || math.Abs(float64(vec[1]-0.8)) > 1e-6 {
+		t.Errorf("embedding: got %v from %d bytes, want [0.6 0.8]", vec, len(raw))
+	}
+}
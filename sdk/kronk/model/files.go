@@ -0,0 +1,219 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// FileData is the content of an uploaded file referenced by a file input.
+type FileData struct {
+	Filename string
+	Data     []byte
+}
+
+// FileLookup returns the content of an uploaded file by its ID.
+type FileLookup func(ctx context.Context, fileID string) (FileData, error)
+
+// ResolveFileInputs replaces the file content parts in the messages and
+// Responses input of d with the parts the media pipeline consumes. Images
+// become image_url parts, audio becomes input_audio parts, and PDF and plain
+// text files become text parts holding the extracted text. It accepts the chat
+// form {"type":"file","file":{"file_id":...}}, the Responses forms
+// {"type":"input_file","file_id":...} and {"type":"input_image","file_id":...},
+// and inline file_data in place of a file_id. Parts are replaced copy-on-write
+// so the documents in d that hold them are not modified. When lookup is nil,
+// parts that reference a file_id are left in place.
+func ResolveFileInputs(ctx context.Context, d D, lookup FileLookup) error {
+	for _, key := range []string{"messages", "input"} {
+		items, ok := d[key].([]D)
+		if !ok {
+			continue
+		}
+
+		resolved, err := replaceContentParts(items, func(part D) (D, bool, error) {
+			return resolveFilePart(ctx, part, lookup)
+		})
+		if err != nil {
+			return fmt.Errorf("resolve-file-inputs: %s%w", key, err)
+		}
+
+		if resolved != nil {
+			d[key] = resolved
+		}
+	}
+
+	return nil
+}
+
+// replaceContentParts returns a copy of items with the content parts that
+// replace reports as replaced swapped in, or nil when no part was replaced.
+func replaceContentParts(items []D, replace func(part D) (D, bool, error)) ([]D, error) {
+	var result []D
+
+	for i, item := range items {
+		content, ok := item["content"].([]D)
+		if !ok {
+			continue
+		}
+
+		var parts []D
+		for j, part := range content {
+			replaced, ok, err := replace(part)
+			if err != nil {
+				return nil, fmt.Errorf("[%d].content[%d]: %w", i, j, err)
+			}
+			if !ok {
+				continue
+			}
+
+			if parts == nil {
+				parts = make([]D, len(content))
+				copy(parts, content)
+			}
+			parts[j] = replaced
+		}
+
+		if parts == nil {
+			continue
+		}
+
+		if result == nil {
+			result = make([]D, len(items))
+			copy(result, items)
+		}
+
+		clone := item.ShallowClone()
+		clone["content"] = parts
+		result[i] = clone
+	}
+
+	return result, nil
+}
+
+// resolveFilePart returns the replacement for a file part and reports whether
+// the part was replaced.
+func resolveFilePart(ctx context.Context, part D, lookup FileLookup) (D, bool, error) {
+	var src map[string]any
+	var image bool
+
+	switch part["type"] {
+	case "file":
+		file, ok := mapFromPart(part["file"])
+		if !ok {
+			return nil, false, fmt.Errorf("%w: file part requires a file object", ErrInvalidRequest)
+		}
+		src = file
+
+	case "input_file":
+		src = part
+
+	case "input_image":
+		if _, exists := part["file_id"]; !exists {
+			return nil, false, nil
+		}
+		src = part
+		image = true
+
+	default:
+		return nil, false, nil
+	}
+
+	fileID, _ := src["file_id"].(string)
+	fileData, _ := src["file_data"].(string)
+	filename, _ := src["filename"].(string)
+
+	var file FileData
+	switch {
+	case fileData != "":
+		data, err := decodeFileData(fileData)
+		if err != nil {
+			return nil, false, err
+		}
+		file = FileData{Filename: filename, Data: data}
+
+	case fileID != "":
+		if lookup == nil {
+			return nil, false, nil
+		}
+
+		var err error
+		if file, err = lookup(ctx, fileID); err != nil {
+			return nil, false, err
+		}
+		if filename != "" {
+			file.Filename = filename
+		}
+
+	default:
+		return nil, false, fmt.Errorf("%w: file part requires a file_id or file_data", ErrInvalidRequest)
+	}
+
+	replaced, err := fileContentPart(file)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if image && replaced["type"] != "image_url" {
+		return nil, false, fmt.Errorf("%w: input_image file %q is not an image", ErrInvalidRequest, file.Filename)
+	}
+
+	return replaced, true, nil
+}
+
+// fileContentPart converts file content to the chat content part that
+// carries it to the model.
+func fileContentPart(file FileData) (D, error) {
+	data := file.Data
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: file %q is empty", ErrInvalidRequest, file.Filename)
+	}
+
+	switch mediaTypeFromMagicBytes(data) {
+	case MediaTypeVision:
+		url := fmt.Sprintf("data:%s;base64,%s", http.DetectContentType(data), base64.StdEncoding.EncodeToString(data))
+		return D{"type": "image_url", "image_url": D{"url": url}}, nil
+
+	case MediaTypeAudio:
+		return D{"type": "input_audio", "input_audio": D{"data": base64.StdEncoding.EncodeToString(data)}}, nil
+	}
+
+	var text string
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		extracted, err := extractPDFText(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: file %q: %w", ErrInvalidRequest, file.Filename, err)
+		}
+		text = extracted
+
+	case utf8.Valid(data):
+		text = string(data)
+
+	default:
+		return nil, fmt.Errorf("%w: file %q is not a supported image, audio, PDF, or text file", ErrInvalidRequest, file.Filename)
+	}
+
+	return D{"type": "text", "text": fmt.Sprintf("<file name=%q>\n%s\n</file>", file.Filename, strings.TrimSpace(text))}, nil
+}
+
+func decodeFileData(fileData string) ([]byte, error) {
+	if strings.HasPrefix(fileData, "data:") {
+		_, after, ok := strings.Cut(fileData, ";base64,")
+		if !ok {
+			return nil, fmt.Errorf("%w: file_data must be base64 encoded", ErrInvalidRequest)
+		}
+		fileData = after
+	}
+
+	data, err := base64.StdEncoding.DecodeString(fileData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: file_data: %w", ErrInvalidRequest, err)
+	}
+
+	return data, nil
+}
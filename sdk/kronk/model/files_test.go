@@ -0,0 +1,180 @@
+package model
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolveFileInputs(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	wav := []byte("RIFF0000WAVEfmt ")
+	pdf := testPDF("BT /F1 12 Tf 72 712 Td (Quarterly report) Tj ET", false)
+
+	files := map[string]FileData{
+		"file-png":  {Filename: "chart.png", Data: png},
+		"file-wav":  {Filename: "clip.wav", Data: wav},
+		"file-pdf":  {Filename: "report.pdf", Data: pdf},
+		"file-text": {Filename: "notes.md", Data: []byte("# Notes\n")},
+	}
+	lookup := func(ctx context.Context, fileID string) (FileData, error) {
+		f, exists := files[fileID]
+		if !exists {
+			return FileData{}, fmt.Errorf("%w: file %q not found", ErrInvalidRequest, fileID)
+		}
+		return f, nil
+	}
+
+	input := []D{
+		{"role": "user", "content": []D{
+			{"type": "input_text", "text": "Summarize these."},
+			{"type": "input_file", "file_id": "file-pdf"},
+			{"type": "input_image", "file_id": "file-png"},
+			{"type": "input_file", "filename": "inline.txt", "file_data": "data:text/plain;base64," + base64.StdEncoding.EncodeToString([]byte("inline"))},
+		}},
+	}
+	d := D{
+		"messages": []D{
+			{"role": "system", "content": "Be brief."},
+			{"role": "user", "content": []D{
+				{"type": "file", "file": D{"file_id": "file-wav"}},
+				{"type": "file", "file": D{"file_id": "file-text"}},
+			}},
+		},
+		"input": input,
+	}
+
+	if err := ResolveFileInputs(t.Context(), d, lookup); err != nil {
+		t.Fatalf("ResolveFileInputs: %v", err)
+	}
+
+	wantMessages := []D{
+		{"role": "system", "content": "Be brief."},
+		{"role": "user", "content": []D{
+			{"type": "input_audio", "input_audio": D{"data": base64.StdEncoding.EncodeToString(wav)}},
+			{"type": "text", "text": "<file name=\"notes.md\">\n# Notes\n</file>"},
+		}},
+	}
+	if diff := cmp.Diff(wantMessages, d["messages"]); diff != "" {
+		t.Errorf("messages mismatch (-want +got):\n%s", diff)
+	}
+
+	wantInput := []D{
+		{"role": "user", "content": []D{
+			{"type": "input_text", "text": "Summarize these."},
+			{"type": "text", "text": "<file name=\"report.pdf\">\nQuarterly report\n</file>"},
+			{"type": "image_url", "image_url": D{"url": "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)}},
+			{"type": "text", "text": "<file name=\"inline.txt\">\ninline\n</file>"},
+		}},
+	}
+	if diff := cmp.Diff(wantInput, d["input"]); diff != "" {
+		t.Errorf("input mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := input[0]["content"].([]D)[1]["file_id"]; got != "file-pdf" {
+		t.Errorf("original input: got file_id %v, want the file reference left in place", got)
+	}
+}
+
+func TestResolveFileInputsRejects(t *testing.T) {
+	lookup := func(ctx context.Context, fileID string) (FileData, error) {
+		switch fileID {
+		case "file-binary":
+			return FileData{Filename: "data.bin", Data: []byte{0x00, 0xFE, 0xFF, 0x80}}, nil
+		case "file-text":
+			return FileData{Filename: "notes.txt", Data: []byte("notes")}, nil
+		}
+		return FileData{}, fmt.Errorf("%w: file %q not found", ErrInvalidRequest, fileID)
+	}
+
+	tests := []struct {
+		name string
+		part D
+	}{
+		{name: "missing file", part: D{"type": "input_file", "file_id": "file-missing"}},
+		{name: "no reference", part: D{"type": "file", "file": D{"filename": "a.txt"}}},
+		{name: "bad file object", part: D{"type": "file", "file": "file-text"}},
+		{name: "bad base64", part: D{"type": "input_file", "file_data": "not base64!"}},
+		{name: "binary", part: D{"type": "input_file", "file_id": "file-binary"}},
+		{name: "image not an image", part: D{"type": "input_image", "file_id": "file-text"}},
+		{name: "pdf without text", part: D{"type": "input_file", "file_data": base64.StdEncoding.EncodeToString([]byte("%PDF-1.4\n%%EOF"))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := D{"messages": []D{{"role": "user", "content": []D{tt.part}}}}
+
+			err := ResolveFileInputs(t.Context(), d, lookup)
+			if !errors.Is(err, ErrInvalidRequest) {
+				t.Fatalf("ResolveFileInputs: got %v, want ErrInvalidRequest", err)
+			}
+		})
+	}
+}
+
+func TestResolveFileInputsWithoutLookup(t *testing.T) {
+	part := D{"type": "file", "file": D{"file_id": "file-1"}}
+	d := D{"messages": []D{{"role": "user", "content": []D{part}}}}
+
+	if err := ResolveFileInputs(t.Context(), d, nil); err != nil {
+		t.Fatalf("ResolveFileInputs: %v", err)
+	}
+
+	if err := validateMessageContentParts(d["messages"].([]D)); !errors.Is(err, ErrFileInputsUnsupported) {
+		t.Errorf("validate: got %v, want ErrFileInputsUnsupported", err)
+	}
+}
+
+func TestExtractPDFText(t *testing.T) {
+	content := strings.Join([]string{
+		"BT /F1 12 Tf 72 712 Td (Hello) Tj ( World) Tj",
+		"0 -14 Td [(Sec)20(ond)-400(line)] TJ",
+		"T* <FEFF00E9> Tj (\\(x\\)) ' ET",
+	}, "\n")
+
+	want := "Hello World\nSecond line\né\n(x)"
+
+	for _, compressed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compressed=%t", compressed), func(t *testing.T) {
+			got, err := extractPDFText(testPDF(content, compressed))
+			if err != nil {
+				t.Fatalf("extractPDFText: %v", err)
+			}
+			if got != want {
+				t.Errorf("text: got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// testPDF returns a single page PDF whose page content stream is content.
+func testPDF(content string, compressed bool) []byte {
+	stream := []byte(content)
+	filter := ""
+	if compressed {
+		var b bytes.Buffer
+		w := zlib.NewWriter(&b)
+		w.Write(stream)
+		w.Close()
+		stream = b.Bytes()
+		filter = " /Filter /FlateDecode"
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	b.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	b.WriteString("2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n")
+	b.WriteString("3 0 obj\n<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>\nendobj\n")
+	fmt.Fprintf(&b, "4 0 obj\n<< /Length %d%s >>\nstream\n", len(stream), filter)
+	b.Write(stream)
+	b.WriteString("\nendstream\nendobj\n%%EOF\n")
+
+	return b.Bytes()
+}
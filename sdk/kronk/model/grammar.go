@@ -3,32 +3,39 @@ package model
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"net/url"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/hybridgroup/yzma/pkg/llama"
 )
 
 var commonRules = map[string]string{
-	"ws":      `[ \t\n\r]*`,
-	"string":  `"\"" ( [^"\\] | "\\" ( ["\\bfnrt] | "u" [0-9a-fA-F]{4} ) )* "\""`,
-	"number":  `"-"? ( "0" | [1-9][0-9]* ) ( "." [0-9]+ )? ( [eE] [+-]? [0-9]+ )?`,
-	"integer": `"-"? ( "0" | [1-9][0-9]* )`,
-	"boolean": `"true" | "false"`,
-	"value":   `string | number | object | array | boolean | "null"`,
-	"object":  `"{" ws ( pair ( ws "," ws pair )* )? ws "}"`,
-	"pair":    `string ws ":" ws value`,
-	"array":   `"[" ws ( value ( ws "," ws value )* )? ws "]"`,
+	"ws":              `[ \t\n\r]*`,
+	"char":            `[^"\\] | "\\" ( ["\\bfnrt] | "u" [0-9a-fA-F]{4} )`,
+	"string":          `"\"" char* "\""`,
+	"number":          `"-"? unsigned-number`,
+	"unsigned-number": `( "0" | [1-9][0-9]* ) ( "." [0-9]+ )? ( [eE] [+-]? [0-9]+ )?`,
+	"integer":         `"-"? ( "0" | [1-9][0-9]* )`,
+	"boolean":         `"true" | "false"`,
+	"value":           `string | number | object | array | boolean | "null"`,
+	"object":          `"{" ws ( pair ( ws "," ws pair )* )? ws "}"`,
+	"pair":            `string ws ":" ws value`,
+	"array":           `"[" ws ( value ( ws "," ws value )* )? ws "]"`,
 }
 
 var builtinRules = map[string]bool{
-	"value":   true,
-	"string":  true,
-	"number":  true,
-	"integer": true,
-	"boolean": true,
-	"object":  true,
-	"array":   true,
+	"value":           true,
+	"string":          true,
+	"number":          true,
+	"unsigned-number": true,
+	"integer":         true,
+	"boolean":         true,
+	"object":          true,
+	"array":           true,
 }
 
 func isBuiltinRule(rule string) bool {
@@ -39,8 +46,20 @@ func isBuiltinRule(rule string) bool {
 // JSON Schema to GBNF conversion.
 
 // fromJSONSchema converts a JSON Schema (as map or D) to a GBNF grammar string.
-// It supports object, array, string, number, integer, boolean, and enum types.
+// Keywords the converter cannot express are ignored and the affected value is
+// left unconstrained. Use fromStrictJSONSchema to reject them instead.
 func fromJSONSchema(schema any) (string, error) {
+	return jsonSchemaToGrammar(schema, false)
+}
+
+// fromStrictJSONSchema converts a JSON Schema like fromJSONSchema but returns
+// an ErrInvalidRequest error for any keyword, format, bound, or pattern the
+// grammar cannot enforce.
+func fromStrictJSONSchema(schema any) (string, error) {
+	return jsonSchemaToGrammar(schema, true)
+}
+
+func jsonSchemaToGrammar(schema any, strict bool) (string, error) {
 	var schemaMap map[string]any
 
 	switch s := schema.(type) {
@@ -62,10 +81,13 @@ func fromJSONSchema(schema any) (string, error) {
 	}
 
 	gb := grammarBuilder{
-		rules: make(map[string]string),
+		rules:  map[string]string{"root": ""},
+		root:   schemaMap,
+		refs:   map[string]string{"#": "root"},
+		strict: strict,
 	}
 
-	rootRule, err := gb.schemaToRule("root", schemaMap)
+	rootRule, err := gb.schemaToRule("root", "#", schemaMap)
 	if err != nil {
 		return "", fmt.Errorf("from-json-schema: %w", err)
 	}
@@ -79,7 +101,8 @@ func fromJSONSchema(schema any) (string, error) {
 // fromResponseFormat converts the OpenAI-compatible "response_format" object
 // into an equivalent GBNF grammar string. It supports "text" (no constraint),
 // "json_object", and "json_schema" types. Returns ("", nil) when the format is
-// "text" or empty.
+// "text" or empty. A json_schema with "strict": true is converted with
+// fromStrictJSONSchema.
 func fromResponseFormat(rf any) (string, error) {
 	var rfMap map[string]any
 
@@ -104,26 +127,21 @@ func fromResponseFormat(rf any) (string, error) {
 		return fromJSONSchema(map[string]any{"type": "object"})
 
 	case "json_schema":
-		var wrapper map[string]any
-
-		switch s := rfMap["json_schema"].(type) {
-		case map[string]any:
-			wrapper = s
-
-		case D:
-			wrapper = map[string]any(s)
-
-		default:
+		wrapper, ok := schemaObject(rfMap["json_schema"])
+		if !ok {
 			return "", fmt.Errorf("%w: from-response-format: missing json_schema field", ErrInvalidRequest)
 		}
 
+		strict, _ := wrapper["strict"].(bool)
+
 		// OpenAI's standard wraps the schema under a "schema" key. Accept the
 		// schema being passed directly as a fallback for lenient clients.
-		if schema, ok := wrapper["schema"]; ok {
-			return fromJSONSchema(schema)
+		schema, ok := wrapper["schema"]
+		if !ok {
+			schema = wrapper
 		}
 
-		return fromJSONSchema(wrapper)
+		return jsonSchemaToGrammar(schema, strict)
 
 	default:
 		return "", fmt.Errorf("%w: from-response-format: unsupported type %q", ErrInvalidRequest, formatType)
@@ -133,32 +151,155 @@ func fromResponseFormat(rf any) (string, error) {
 // =============================================================================
 // Grammar builder for JSON Schema conversion.
 
+// maxSchemaDepth bounds allOf merging so a schema whose allOf members refer
+// back to themselves fails instead of recursing forever.
+const maxSchemaDepth = 32
+
+// schemaKeywords are the JSON Schema keywords the converter enforces.
+var schemaKeywords = map[string]bool{
+	"$ref":                 true,
+	"additionalItems":      true,
+	"additionalProperties": true,
+	"allOf":                true,
+	"anyOf":                true,
+	"const":                true,
+	"enum":                 true,
+	"exclusiveMaximum":     true,
+	"exclusiveMinimum":     true,
+	"format":               true,
+	"items":                true,
+	"maxItems":             true,
+	"maxLength":            true,
+	"maximum":              true,
+	"minItems":             true,
+	"minLength":            true,
+	"minimum":              true,
+	"nullable":             true,
+	"oneOf":                true,
+	"pattern":              true,
+	"prefixItems":          true,
+	"properties":           true,
+	"required":             true,
+	"type":                 true,
+}
+
+// schemaAnnotations are keywords that carry no constraint on the generated
+// value and are ignored even in strict mode.
+var schemaAnnotations = map[string]bool{
+	"$anchor":          true,
+	"$comment":         true,
+	"$defs":            true,
+	"$id":              true,
+	"$schema":          true,
+	"contentEncoding":  true,
+	"contentMediaType": true,
+	"default":          true,
+	"definitions":      true,
+	"deprecated":       true,
+	"description":      true,
+	"examples":         true,
+	"readOnly":         true,
+	"title":            true,
+	"writeOnly":        true,
+}
+
 type grammarBuilder struct {
-	rules map[string]string
+	rules  map[string]string
+	root   map[string]any
+	refs   map[string]string
+	strict bool
+}
+
+// unsupported reports a keyword the grammar cannot enforce. It returns an
+// error in strict mode and nil otherwise, so the caller falls back to a looser
+// rule.
+func (gb *grammarBuilder) unsupported(path string, format string, args ...any) error {
+	if !gb.strict {
+		return nil
+	}
+
+	return fmt.Errorf("%w: json schema at %s: %s", ErrInvalidRequest, path, fmt.Sprintf(format, args...))
+}
+
+func (gb *grammarBuilder) checkKeywords(path string, schema map[string]any) error {
+	if !gb.strict {
+		return nil
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(schema)) {
+		if !schemaKeywords[key] && !schemaAnnotations[key] {
+			return gb.unsupported(path, "keyword %q is not supported", key)
+		}
+	}
+
+	return nil
 }
 
-func (gb *grammarBuilder) schemaToRule(name string, schema map[string]any) (string, error) {
-	schemaType, _ := schema["type"].(string)
+func (gb *grammarBuilder) schemaToRule(name string, path string, schema map[string]any) (string, error) {
+	if err := gb.checkKeywords(path, schema); err != nil {
+		return "", err
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		return gb.refToRule(ref)
+	}
+
+	if value, ok := schema["const"]; ok {
+		return jsonLiteral(value)
+	}
 
-	if enum, ok := schema["enum"].([]any); ok {
+	if enum, ok := schemaArray(schema["enum"]); ok {
 		return gb.enumToRule(enum)
 	}
 
+	if alts, ok := schemaArray(schema["anyOf"]); ok {
+		return gb.alternativesToRule(name, path+"/anyOf", alts)
+	}
+
+	// The grammar cannot express that exactly one alternative matches, so
+	// oneOf accepts any of its alternatives.
+	if alts, ok := schemaArray(schema["oneOf"]); ok {
+		return gb.alternativesToRule(name, path+"/oneOf", alts)
+	}
+
+	if _, ok := schema["allOf"]; ok {
+		merged, err := gb.mergeAllOf(path, schema, 0)
+		if err != nil {
+			return "", err
+		}
+
+		return gb.schemaToRule(name, path, merged)
+	}
+
+	types, err := schemaTypes(path, schema)
+	if err != nil {
+		return "", err
+	}
+
+	if len(types) > 1 {
+		return gb.typesToRule(name, path, schema, types)
+	}
+
+	var schemaType string
+	if len(types) == 1 {
+		schemaType = types[0]
+	}
+
 	switch schemaType {
 	case "object":
-		return gb.objectToRule(name, schema)
+		return gb.objectToRule(name, path, schema)
 
 	case "array":
-		return gb.arrayToRule(name, schema)
+		return gb.arrayToRule(name, path, schema)
 
 	case "string":
-		return gb.stringToRule(schema)
+		return gb.stringToRule(path, schema)
 
 	case "number":
-		return "number", nil
+		return gb.numberToRule(path, schema)
 
 	case "integer":
-		return "integer", nil
+		return gb.integerToRule(path, schema)
 
 	case "boolean":
 		return "boolean", nil
@@ -166,152 +307,593 @@ func (gb *grammarBuilder) schemaToRule(name string, schema map[string]any) (stri
 	case "null":
 		return `"null"`, nil
 
+	case "":
+		return "value", nil
+
 	default:
+		if err := gb.unsupported(path, "type %q is not supported", schemaType); err != nil {
+			return "", err
+		}
+
 		return "value", nil
 	}
 }
 
-func (gb *grammarBuilder) objectToRule(name string, schema map[string]any) (string, error) {
-	props, _ := schema["properties"].(map[string]any)
-	if props == nil {
-		if propsD, ok := schema["properties"].(D); ok {
-			props = map[string]any(propsD)
+// namedRule converts a subschema and stores the result under its own rule
+// name, returning the name. Builtin rules, literals, and existing rules are
+// returned as is.
+func (gb *grammarBuilder) namedRule(name string, path string, value any) (string, error) {
+	rule, err := gb.subschemaToRule(name, path, value)
+	if err != nil {
+		return "", err
+	}
+
+	if isBuiltinRule(rule) {
+		return rule, nil
+	}
+
+	if _, exists := gb.rules[rule]; exists {
+		return rule, nil
+	}
+
+	name = gb.uniqueRuleName(name)
+	gb.rules[name] = rule
+
+	return name, nil
+}
+
+// subschemaToRule converts a subschema that may be a boolean schema.
+func (gb *grammarBuilder) subschemaToRule(name string, path string, value any) (string, error) {
+	if accept, ok := value.(bool); ok {
+		if !accept {
+			return "", fmt.Errorf("%w: json schema at %s accepts no value", ErrInvalidRequest, path)
 		}
+
+		return "value", nil
 	}
 
-	if len(props) == 0 {
-		return "object", nil
+	schema, ok := schemaObject(value)
+	if !ok {
+		return "", fmt.Errorf("%w: json schema at %s must be an object or boolean", ErrInvalidRequest, path)
 	}
 
-	required := make(map[string]bool)
-	if reqArr, ok := schema["required"].([]any); ok {
-		for _, r := range reqArr {
-			if s, ok := r.(string); ok {
-				required[s] = true
+	return gb.schemaToRule(name, path, schema)
+}
+
+func (gb *grammarBuilder) uniqueRuleName(base string) string {
+	taken := func(name string) bool {
+		_, rule := gb.rules[name]
+		_, common := commonRules[name]
+		_, format := formatRules[name]
+		return rule || common || format
+	}
+
+	if !taken(base) {
+		return base
+	}
+
+	for i := 2; ; i++ {
+		if name := fmt.Sprintf("%s-%d", base, i); !taken(name) {
+			return name
+		}
+	}
+}
+
+// refToRule returns the rule for a local reference such as "#/$defs/node".
+// The referenced schema gets its own rule, which is registered before it is
+// converted so recursive references resolve to it.
+func (gb *grammarBuilder) refToRule(ref string) (string, error) {
+	if name, ok := gb.refs[ref]; ok {
+		return name, nil
+	}
+
+	target, err := gb.resolveRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	base := ref[strings.LastIndex(ref, "/")+1:]
+	name := gb.uniqueRuleName("def-" + ruleName(base))
+
+	gb.refs[ref] = name
+	gb.rules[name] = ""
+
+	rule, err := gb.subschemaToRule(name, ref, target)
+	if err != nil {
+		return "", err
+	}
+
+	gb.rules[name] = rule
+
+	return name, nil
+}
+
+// resolveRef follows a local JSON Pointer reference from the root schema.
+func (gb *grammarBuilder) resolveRef(ref string) (any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("%w: json schema $ref %q: only local references are supported", ErrInvalidRequest, ref)
+	}
+
+	var current any = gb.root
+	if pointer == "" {
+		return current, nil
+	}
+
+	for segment := range strings.SplitSeq(strings.TrimPrefix(pointer, "/"), "/") {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segment = strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+
+		if obj, ok := schemaObject(current); ok {
+			if current, ok = obj[segment]; !ok {
+				return nil, fmt.Errorf("%w: json schema $ref %q: %q not found", ErrInvalidRequest, ref, segment)
+			}
+			continue
+		}
+
+		if arr, ok := schemaArray(current); ok {
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(arr) {
+				return nil, fmt.Errorf("%w: json schema $ref %q: invalid index %q", ErrInvalidRequest, ref, segment)
 			}
+			current = arr[idx]
+			continue
+		}
+
+		return nil, fmt.Errorf("%w: json schema $ref %q: cannot resolve %q", ErrInvalidRequest, ref, segment)
+	}
+
+	return current, nil
+}
+
+// alternativesToRule builds the rule for anyOf and oneOf.
+func (gb *grammarBuilder) alternativesToRule(name string, path string, alts []any) (string, error) {
+	if len(alts) == 0 {
+		return "", fmt.Errorf("%w: json schema at %s: no alternatives", ErrInvalidRequest, path)
+	}
+
+	options := make([]string, 0, len(alts))
+	for i, alt := range alts {
+		rule, err := gb.namedRule(fmt.Sprintf("%s-%d", name, i), fmt.Sprintf("%s/%d", path, i), alt)
+		if err != nil {
+			return "", err
 		}
+		options = append(options, rule)
 	}
 
-	if reqArr, ok := schema["required"].([]string); ok {
-		for _, r := range reqArr {
-			required[r] = true
+	return alternation(options), nil
+}
+
+// typesToRule builds the rule for a type array such as ["string", "null"].
+func (gb *grammarBuilder) typesToRule(name string, path string, schema map[string]any, types []string) (string, error) {
+	options := make([]string, 0, len(types))
+	for _, schemaType := range types {
+		typed := maps.Clone(schema)
+		typed["type"] = schemaType
+		delete(typed, "nullable")
+
+		rule, err := gb.namedRule(name+"-"+schemaType, path, typed)
+		if err != nil {
+			return "", err
 		}
+		options = append(options, rule)
 	}
 
-	var keys []string
-	for k := range props {
-		keys = append(keys, k)
+	return alternation(options), nil
+}
+
+// mergeAllOf folds the allOf members of schema into one schema. Properties
+// and required lists are combined; for any other keyword the last member
+// wins.
+func (gb *grammarBuilder) mergeAllOf(path string, schema map[string]any, depth int) (map[string]any, error) {
+	if depth > maxSchemaDepth {
+		return nil, fmt.Errorf("%w: json schema at %s: allOf nested too deeply", ErrInvalidRequest, path)
 	}
-	sort.Strings(keys)
 
-	var pairs []string
-	for _, key := range keys {
-		var propSchema map[string]any
+	members, ok := schemaArray(schema["allOf"])
+	if !ok {
+		return nil, fmt.Errorf("%w: json schema at %s: allOf must be an array", ErrInvalidRequest, path)
+	}
 
-		switch v := props[key].(type) {
-		case map[string]any:
-			propSchema = v
-		case D:
-			propSchema = map[string]any(v)
-		default:
+	merged := make(map[string]any, len(schema))
+	for key, value := range schema {
+		if key != "allOf" {
+			merged[key] = value
+		}
+	}
+
+	for i, member := range members {
+		memberPath := fmt.Sprintf("%s/allOf/%d", path, i)
+
+		if accept, ok := member.(bool); ok && accept {
 			continue
 		}
 
-		propRuleName := fmt.Sprintf("%s-%s", name, key)
-		propRule, err := gb.schemaToRule(propRuleName, propSchema)
+		obj, ok := schemaObject(member)
+		if !ok {
+			return nil, fmt.Errorf("%w: json schema at %s must be an object", ErrInvalidRequest, memberPath)
+		}
+
+		if ref, ok := obj["$ref"].(string); ok {
+			target, err := gb.resolveRef(ref)
+			if err != nil {
+				return nil, err
+			}
+			if obj, ok = schemaObject(target); !ok {
+				return nil, fmt.Errorf("%w: json schema $ref %q must point to an object", ErrInvalidRequest, ref)
+			}
+		}
+
+		if _, ok := obj["allOf"]; ok {
+			var err error
+			if obj, err = gb.mergeAllOf(memberPath, obj, depth+1); err != nil {
+				return nil, err
+			}
+		}
+
+		mergeSchema(merged, obj)
+	}
+
+	return merged, nil
+}
+
+func mergeSchema(dst map[string]any, src map[string]any) {
+	for key, value := range src {
+		switch key {
+		case "properties":
+			props := make(map[string]any)
+			if existing, ok := schemaObject(dst[key]); ok {
+				maps.Copy(props, existing)
+			}
+			if added, ok := schemaObject(value); ok {
+				maps.Copy(props, added)
+			}
+			dst[key] = props
+
+		case "required":
+			required := schemaStrings(dst[key])
+			for _, name := range schemaStrings(value) {
+				if !slices.Contains(required, name) {
+					required = append(required, name)
+				}
+			}
+			dst[key] = required
+
+		default:
+			dst[key] = value
+		}
+	}
+}
+
+func (gb *grammarBuilder) objectToRule(name string, path string, schema map[string]any) (string, error) {
+	props, _ := schemaObject(schema["properties"])
+
+	required := make(map[string]bool)
+	for _, key := range schemaStrings(schema["required"]) {
+		required[key] = true
+	}
+
+	var extra string
+	switch additional := schema["additionalProperties"].(type) {
+	case nil:
+		if len(props) == 0 && len(required) == 0 {
+			return "object", nil
+		}
+
+	case bool:
+		if additional {
+			extra = "pair"
+		}
+
+	default:
+		rule, err := gb.namedRule(name+"-additional", path+"/additionalProperties", additional)
 		if err != nil {
 			return "", err
 		}
+		extra = "string ws \":\" ws " + rule
+	}
 
-		if !isBuiltinRule(propRule) {
-			gb.rules[propRuleName] = propRule
-			propRule = propRuleName
+	// Required properties are emitted first, then optional properties, each
+	// group in sorted key order. A required key without a property schema
+	// accepts any value.
+	keys := slices.Collect(maps.Keys(props))
+	for key := range required {
+		if _, ok := props[key]; !ok {
+			keys = append(keys, key)
 		}
+	}
+	slices.Sort(keys)
 
-		pair := fmt.Sprintf(`"\"" "%s" "\"" ws ":" ws %s`, key, propRule)
-		if !required[key] {
-			pair = fmt.Sprintf("( %s )?", pair)
+	var requiredPairs, optionalPairs []string
+	for _, key := range keys {
+		propRule := "value"
+		if propSchema, ok := props[key]; ok {
+			var err error
+			if propRule, err = gb.namedRule(name+"-"+ruleName(key), path+"/properties/"+key, propSchema); err != nil {
+				return "", err
+			}
 		}
-		pairs = append(pairs, pair)
+
+		pair, err := propertyPair(key, propRule)
+		if err != nil {
+			return "", err
+		}
+
+		if required[key] {
+			requiredPairs = append(requiredPairs, pair)
+			continue
+		}
+		optionalPairs = append(optionalPairs, pair)
 	}
 
-	if len(pairs) == 0 {
-		return "object", nil
+	const sep = `ws "," ws`
+
+	var extraTail string
+	if extra != "" {
+		extraTail = fmt.Sprintf("( %s %s )*", sep, extra)
 	}
 
-	return fmt.Sprintf(`"{" ws %s ws "}"`, strings.Join(pairs, ` ws "," ws `)), nil
-}
+	if len(requiredPairs) > 0 {
+		parts := []string{strings.Join(requiredPairs, " "+sep+" ")}
+		for _, pair := range optionalPairs {
+			parts = append(parts, fmt.Sprintf("( %s %s )?", sep, pair))
+		}
+		if extraTail != "" {
+			parts = append(parts, extraTail)
+		}
+
+		return fmt.Sprintf(`"{" ws %s ws "}"`, strings.Join(parts, " ")), nil
+	}
+
+	if len(optionalPairs) == 0 && extra == "" {
+		return `"{" ws "}"`, nil
+	}
 
-func (gb *grammarBuilder) arrayToRule(name string, schema map[string]any) (string, error) {
-	items, _ := schema["items"].(map[string]any)
-	if items == nil {
-		if itemsD, ok := schema["items"].(D); ok {
-			items = map[string]any(itemsD)
+	// Without a required property any optional property can come first, so
+	// each alternative starts with a different optional property and the
+	// commas only appear between properties that are present.
+	var alts []string
+	for i, pair := range optionalPairs {
+		parts := []string{pair}
+		for _, next := range optionalPairs[i+1:] {
+			parts = append(parts, fmt.Sprintf("( %s %s )?", sep, next))
 		}
+		if extraTail != "" {
+			parts = append(parts, extraTail)
+		}
+		alts = append(alts, strings.Join(parts, " "))
+	}
+	if extra != "" {
+		alts = append(alts, extra+" "+extraTail)
 	}
 
-	if items == nil {
-		return "array", nil
+	return fmt.Sprintf(`"{" ws ( %s )? ws "}"`, strings.Join(alts, " | ")), nil
+}
+
+func propertyPair(key string, rule string) (string, error) {
+	literal, err := jsonLiteral(key)
+	if err != nil {
+		return "", err
 	}
 
-	itemRuleName := fmt.Sprintf("%s-item", name)
-	itemRule, err := gb.schemaToRule(itemRuleName, items)
+	return fmt.Sprintf(`%s ws ":" ws %s`, literal, rule), nil
+}
+
+func (gb *grammarBuilder) arrayToRule(name string, path string, schema map[string]any) (string, error) {
+	minItems, maxItems, err := schemaBounds(path, schema, "minItems", "maxItems")
 	if err != nil {
 		return "", err
 	}
 
-	if !isBuiltinRule(itemRule) {
-		gb.rules[itemRuleName] = itemRule
-		itemRule = itemRuleName
+	// Draft 4 spells a tuple as an items array.
+	prefix, hasPrefix := schemaArray(schema["prefixItems"])
+	items, hasItems := schema["items"]
+	if arr, ok := schemaArray(items); ok && !hasPrefix {
+		prefix, hasPrefix = arr, true
+		items, hasItems = schema["additionalItems"]
 	}
 
-	return fmt.Sprintf(`"[" ws ( %s ( ws "," ws %s )* )? ws "]"`, itemRule, itemRule), nil
+	if hasPrefix {
+		if minItems > 0 || maxItems >= 0 {
+			if err := gb.unsupported(path, "minItems and maxItems cannot be combined with prefixItems"); err != nil {
+				return "", err
+			}
+		}
+
+		return gb.tupleToRule(name, path, prefix, items, hasItems)
+	}
+
+	if accept, ok := items.(bool); ok && !accept {
+		return `"[" ws "]"`, nil
+	}
+
+	itemRule := "value"
+	if hasItems {
+		if itemRule, err = gb.namedRule(name+"-item", path+"/items", items); err != nil {
+			return "", err
+		}
+	}
+
+	if !hasItems && minItems == 0 && maxItems < 0 {
+		return "array", nil
+	}
+
+	if maxItems == 0 {
+		return `"[" ws "]"`, nil
+	}
+
+	return fmt.Sprintf(`"[" ws %s ws "]"`, repeatRule(itemRule, `ws "," ws`, minItems, maxItems)), nil
 }
 
-func (gb *grammarBuilder) stringToRule(schema map[string]any) (string, error) {
-	if enum, ok := schema["enum"].([]any); ok {
-		return gb.enumToRule(enum)
+// tupleToRule builds the rule for prefixItems. The listed items are required;
+// further items are allowed only when items holds a schema.
+func (gb *grammarBuilder) tupleToRule(name string, path string, prefix []any, items any, hasItems bool) (string, error) {
+	const sep = ` ws "," ws `
+
+	rules := make([]string, 0, len(prefix))
+	for i, item := range prefix {
+		rule, err := gb.namedRule(fmt.Sprintf("%s-%d", name, i), fmt.Sprintf("%s/prefixItems/%d", path, i), item)
+		if err != nil {
+			return "", err
+		}
+		rules = append(rules, rule)
+	}
+
+	body := strings.Join(rules, sep)
+
+	if accept, ok := items.(bool); hasItems && (!ok || accept) {
+		rest, err := gb.namedRule(name+"-rest", path+"/items", items)
+		if err != nil {
+			return "", err
+		}
+
+		switch body {
+		case "":
+			body = repeatRule(rest, strings.TrimSpace(sep), 0, -1)
+		default:
+			body += fmt.Sprintf(" (%s%s )*", sep, rest)
+		}
+	}
+
+	if body == "" {
+		return `"[" ws "]"`, nil
+	}
+
+	return fmt.Sprintf(`"[" ws %s ws "]"`, body), nil
+}
+
+func (gb *grammarBuilder) stringToRule(path string, schema map[string]any) (string, error) {
+	minLength, maxLength, err := schemaBounds(path, schema, "minLength", "maxLength")
+	if err != nil {
+		return "", err
+	}
+	hasLength := minLength > 0 || maxLength >= 0
+
+	if format, ok := schema["format"].(string); ok {
+		if _, known := formatRules[format]; !known {
+			if err := gb.unsupported(path, "format %q is not supported", format); err != nil {
+				return "", err
+			}
+		} else {
+			if hasLength || schema["pattern"] != nil {
+				if err := gb.unsupported(path, "format cannot be combined with pattern or length bounds"); err != nil {
+					return "", err
+				}
+			}
+
+			gb.addFormatRule(format)
+			return fmt.Sprintf(`"\"" %s "\""`, format), nil
+		}
 	}
 
 	if pattern, ok := schema["pattern"].(string); ok {
-		return fmt.Sprintf(`"\"" %s "\""`, pattern), nil
+		rule, err := patternToRule(pattern)
+		if err != nil {
+			if err := gb.unsupported(path, "pattern %q: %s", pattern, err); err != nil {
+				return "", err
+			}
+		} else {
+			if hasLength {
+				if err := gb.unsupported(path, "pattern cannot be combined with length bounds"); err != nil {
+					return "", err
+				}
+			}
+
+			return fmt.Sprintf(`"\"" %s "\""`, rule), nil
+		}
+	}
+
+	if hasLength {
+		return fmt.Sprintf(`"\"" char%s "\""`, quantifier(minLength, maxLength)), nil
 	}
 
 	return "string", nil
 }
 
+func (gb *grammarBuilder) integerToRule(path string, schema map[string]any) (string, error) {
+	lo, hi, err := integerBounds(path, schema)
+	if err != nil {
+		return "", err
+	}
+
+	if lo == nil && hi == nil {
+		return "integer", nil
+	}
+
+	if lo != nil && hi != nil && *lo > *hi {
+		return "", fmt.Errorf("%w: json schema at %s: no integer lies between minimum %d and maximum %d", ErrInvalidRequest, path, *lo, *hi)
+	}
+
+	return integerRangeRule(lo, hi), nil
+}
+
+// numberToRule supports a lower bound of zero, which rules out a sign. Other
+// bounds on non-integer numbers cannot be expressed digit by digit and leave
+// the number unconstrained.
+func (gb *grammarBuilder) numberToRule(path string, schema map[string]any) (string, error) {
+	bounded := false
+	for _, key := range []string{"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum"} {
+		if _, ok := schema[key]; ok {
+			bounded = true
+		}
+	}
+
+	if !bounded {
+		return "number", nil
+	}
+
+	minimum, hasMinimum, err := schemaNumber(path, schema, "minimum")
+	if err != nil {
+		return "", err
+	}
+
+	_, hasMaximum := schema["maximum"]
+	_, hasExclusiveMax := schema["exclusiveMaximum"]
+	exclusiveMin, hasExclusiveMin := schema["exclusiveMinimum"]
+	if flag, ok := exclusiveMin.(bool); ok && !flag {
+		hasExclusiveMin = false
+	}
+
+	if hasMinimum && minimum == 0 && !hasExclusiveMin && !hasMaximum && !hasExclusiveMax {
+		return "unsigned-number", nil
+	}
+
+	if err := gb.unsupported(path, "number bounds other than minimum 0 are not supported, use an integer type"); err != nil {
+		return "", err
+	}
+
+	if hasMinimum && minimum >= 0 {
+		return "unsigned-number", nil
+	}
+
+	return "number", nil
+}
+
 func (gb *grammarBuilder) enumToRule(values []any) (string, error) {
 	var options []string
 	for _, v := range values {
-		switch val := v.(type) {
-		case string:
-			options = append(options, fmt.Sprintf(`"\"" "%s" "\""`, val))
-
-		case float64:
-			switch {
-			case val == float64(int(val)):
-				options = append(options, fmt.Sprintf(`"%d"`, int(val)))
-			default:
-				options = append(options, fmt.Sprintf(`"%v"`, val))
-			}
-
-		case bool:
-			options = append(options, fmt.Sprintf(`"%t"`, val))
-
-		default:
-			options = append(options, fmt.Sprintf(`"%v"`, val))
+		literal, err := jsonLiteral(v)
+		if err != nil {
+			return "", err
 		}
+		options = append(options, literal)
 	}
 
 	if len(options) == 0 {
 		return "value", nil
 	}
 
-	if len(options) == 1 {
-		return options[0], nil
+	return alternation(options), nil
+}
+
+func (gb *grammarBuilder) addFormatRule(format string) {
+	for _, dep := range formatDeps[format] {
+		gb.addFormatRule(dep)
 	}
 
-	return fmt.Sprintf("( %s )", strings.Join(options, " | ")), nil
+	gb.rules[format] = formatRules[format]
 }
 
 func (gb *grammarBuilder) addCommonRules() {
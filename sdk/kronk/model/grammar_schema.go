@@ -0,0 +1,728 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp/syntax"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// formatRules holds the rules for the JSON Schema string formats the grammar
+// can enforce. A rule is added to a grammar only when a schema uses it.
+var formatRules = map[string]string{
+	"date":      `[0-9]{4} "-" ( "0" [1-9] | "1" [0-2] ) "-" ( "0" [1-9] | [12] [0-9] | "3" [01] )`,
+	"time":      `( [01] [0-9] | "2" [0-3] ) ":" [0-5] [0-9] ":" [0-5] [0-9] ( "." [0-9]+ )? ( "Z" | [+-] ( [01] [0-9] | "2" [0-3] ) ":" [0-5] [0-9] )`,
+	"date-time": `date "T" time`,
+	"uuid":      `[0-9a-fA-F]{8} "-" [0-9a-fA-F]{4} "-" [0-9a-fA-F]{4} "-" [0-9a-fA-F]{4} "-" [0-9a-fA-F]{12}`,
+	"email":     `[a-zA-Z0-9._%+-]+ "@" [a-zA-Z0-9-]+ ( "." [a-zA-Z0-9-]+ )+`,
+	"ipv4":      `ipv4-octet "." ipv4-octet "." ipv4-octet "." ipv4-octet`,
+
+	"ipv4-octet": `( "25" [0-5] | "2" [0-4] [0-9] | "1" [0-9] [0-9] | [1-9] [0-9] | [0-9] )`,
+}
+
+// formatDeps lists the rules a format rule refers to.
+var formatDeps = map[string][]string{
+	"date-time": {"date", "time"},
+	"ipv4":      {"ipv4-octet"},
+}
+
+// =============================================================================
+// Schema value access.
+
+func schemaObject(value any) (map[string]any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		return v, true
+
+	case D:
+		return map[string]any(v), true
+	}
+
+	return nil, false
+}
+
+func schemaArray(value any) ([]any, bool) {
+	switch v := value.(type) {
+	case []any:
+		return v, true
+
+	case []string:
+		arr := make([]any, len(v))
+		for i, s := range v {
+			arr[i] = s
+		}
+		return arr, true
+
+	case []D:
+		arr := make([]any, len(v))
+		for i, d := range v {
+			arr[i] = d
+		}
+		return arr, true
+
+	case []map[string]any:
+		arr := make([]any, len(v))
+		for i, m := range v {
+			arr[i] = m
+		}
+		return arr, true
+	}
+
+	return nil, false
+}
+
+func schemaStrings(value any) []string {
+	arr, _ := schemaArray(value)
+
+	strs := make([]string, 0, len(arr))
+	for _, v := range arr {
+		if s, ok := v.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+
+	return strs
+}
+
+// schemaTypes returns the types a schema allows. A nullable schema also
+// allows null, and a schema without a type that declares properties or items
+// is treated as an object or array.
+func schemaTypes(path string, schema map[string]any) ([]string, error) {
+	var types []string
+
+	switch v := schema["type"].(type) {
+	case nil:
+		switch {
+		case schema["properties"] != nil || schema["additionalProperties"] != nil || schema["required"] != nil:
+			types = []string{"object"}
+
+		case schema["items"] != nil || schema["prefixItems"] != nil:
+			types = []string{"array"}
+		}
+
+	case string:
+		types = []string{v}
+
+	default:
+		arr, ok := schemaArray(v)
+		if !ok {
+			return nil, fmt.Errorf("%w: json schema at %s: type must be a string or an array of strings", ErrInvalidRequest, path)
+		}
+
+		for _, t := range arr {
+			s, ok := t.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: json schema at %s: type must be a string or an array of strings", ErrInvalidRequest, path)
+			}
+			if !slices.Contains(types, s) {
+				types = append(types, s)
+			}
+		}
+	}
+
+	if nullable, _ := schema["nullable"].(bool); nullable && len(types) > 0 && !slices.Contains(types, "null") {
+		types = append(types, "null")
+	}
+
+	return types, nil
+}
+
+func schemaNumber(path string, schema map[string]any, key string) (float64, bool, error) {
+	switch v := schema[key].(type) {
+	case nil:
+		return 0, false, nil
+
+	case float64:
+		return v, true, nil
+
+	case float32:
+		return float64(v), true, nil
+
+	case int:
+		return float64(v), true, nil
+
+	case int64:
+		return float64(v), true, nil
+
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, false, fmt.Errorf("%w: json schema at %s: %s must be a number", ErrInvalidRequest, path, key)
+		}
+		return f, true, nil
+
+	default:
+		return 0, false, fmt.Errorf("%w: json schema at %s: %s must be a number", ErrInvalidRequest, path, key)
+	}
+}
+
+// schemaBounds reads a pair of count keywords such as minItems and maxItems.
+// A missing minimum is 0 and a missing maximum is -1.
+func schemaBounds(path string, schema map[string]any, minKey string, maxKey string) (int, int, error) {
+	read := func(key string, missing int) (int, error) {
+		v, ok, err := schemaNumber(path, schema, key)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			return missing, nil
+		}
+		if v < 0 || v != math.Trunc(v) || v > math.MaxInt32 {
+			return 0, fmt.Errorf("%w: json schema at %s: %s must be a non-negative integer", ErrInvalidRequest, path, key)
+		}
+		return int(v), nil
+	}
+
+	minimum, err := read(minKey, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	maximum, err := read(maxKey, -1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if maximum >= 0 && maximum < minimum {
+		return 0, 0, fmt.Errorf("%w: json schema at %s: %s is greater than %s", ErrInvalidRequest, path, minKey, maxKey)
+	}
+
+	return minimum, maximum, nil
+}
+
+// integerBounds returns the inclusive integer range allowed by minimum,
+// maximum, and their exclusive forms. Draft 4 boolean exclusive flags are
+// honored. A nil bound is unbounded.
+func integerBounds(path string, schema map[string]any) (*int64, *int64, error) {
+	var lo, hi *int64
+
+	raise := func(v int64) {
+		if lo == nil || v > *lo {
+			lo = &v
+		}
+	}
+
+	lower := func(v int64) {
+		if hi == nil || v < *hi {
+			hi = &v
+		}
+	}
+
+	exclusiveMin, minIsFlag := schema["exclusiveMinimum"].(bool)
+	exclusiveMax, maxIsFlag := schema["exclusiveMaximum"].(bool)
+
+	v, ok, err := schemaNumber(path, schema, "minimum")
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok {
+		bound := clampInt64(math.Ceil(v))
+		if exclusiveMin && float64(bound) == v {
+			bound++
+		}
+		raise(bound)
+	}
+
+	v, ok, err = schemaNumber(path, schema, "maximum")
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok {
+		bound := clampInt64(math.Floor(v))
+		if exclusiveMax && float64(bound) == v {
+			bound--
+		}
+		lower(bound)
+	}
+
+	if !minIsFlag {
+		v, ok, err := schemaNumber(path, schema, "exclusiveMinimum")
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			raise(clampInt64(math.Floor(v)) + 1)
+		}
+	}
+
+	if !maxIsFlag {
+		v, ok, err := schemaNumber(path, schema, "exclusiveMaximum")
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			lower(clampInt64(math.Ceil(v)) - 1)
+		}
+	}
+
+	return lo, hi, nil
+}
+
+func clampInt64(v float64) int64 {
+	const limit = 1 << 62
+
+	return int64(max(min(v, limit), -limit))
+}
+
+// =============================================================================
+// Rule text helpers.
+
+// ruleName turns a property or definition name into a valid rule name.
+func ruleName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+func alternation(options []string) string {
+	if len(options) == 1 {
+		return options[0]
+	}
+
+	return fmt.Sprintf("( %s )", strings.Join(options, " | "))
+}
+
+// quantifier returns the GBNF repetition suffix for min to max occurrences,
+// where a negative max is unbounded.
+func quantifier(minimum int, maximum int) string {
+	switch {
+	case maximum < 0 && minimum == 0:
+		return "*"
+
+	case maximum < 0 && minimum == 1:
+		return "+"
+
+	case maximum < 0:
+		return fmt.Sprintf("{%d,}", minimum)
+
+	case minimum == 1 && maximum == 1:
+		return ""
+
+	case minimum == 0 && maximum == 1:
+		return "?"
+
+	case minimum == maximum:
+		return fmt.Sprintf("{%d}", minimum)
+
+	default:
+		return fmt.Sprintf("{%d,%d}", minimum, maximum)
+	}
+}
+
+// repeatRule returns min to max occurrences of item separated by sep, where a
+// negative max is unbounded. max must not be 0.
+func repeatRule(item string, sep string, minimum int, maximum int) string {
+	restMax := -1
+	if maximum > 0 {
+		restMax = maximum - 1
+	}
+
+	restMin := max(minimum-1, 0)
+
+	rest := ""
+	if restMax != 0 {
+		rest = fmt.Sprintf(" ( %s %s )%s", sep, item, quantifier(restMin, restMax))
+	}
+
+	if minimum == 0 {
+		return fmt.Sprintf("( %s%s )?", item, rest)
+	}
+
+	return item + rest
+}
+
+// jsonText encodes a value as compact JSON without HTML escaping, which is
+// the form a model is expected to emit.
+func jsonText(value any) (string, error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(value); err != nil {
+		return "", fmt.Errorf("%w: json schema value: %w", ErrInvalidRequest, err)
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// jsonLiteral returns the GBNF literal matching the JSON encoding of value.
+func jsonLiteral(value any) (string, error) {
+	text, err := jsonText(value)
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := value.(string); ok {
+		inner := text[1 : len(text)-1]
+		if inner == "" {
+			return `"\"" "\""`, nil
+		}
+
+		return fmt.Sprintf(`"\"" %s "\""`, gbnfQuote(inner)), nil
+	}
+
+	return gbnfQuote(text), nil
+}
+
+var gbnfEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+func gbnfQuote(s string) string {
+	return `"` + gbnfEscaper.Replace(s) + `"`
+}
+
+// =============================================================================
+// Integer ranges.
+
+// integerRangeRule returns a rule matching exactly the integers between lo
+// and hi, where a nil bound is unbounded.
+func integerRangeRule(lo *int64, hi *int64) string {
+	var options []string
+
+	if lo == nil || *lo < 0 {
+		minMag := uint64(1)
+		if hi != nil && *hi < 0 {
+			minMag = magnitude(*hi)
+		}
+
+		var maxMag *uint64
+		if lo != nil {
+			maxMag = new(magnitude(*lo))
+		}
+
+		options = append(options, `"-" `+naturalRangeRule(minMag, maxMag))
+	}
+
+	if hi == nil || *hi >= 0 {
+		var minNat uint64
+		if lo != nil && *lo > 0 {
+			minNat = uint64(*lo)
+		}
+
+		var maxNat *uint64
+		if hi != nil {
+			maxNat = new(uint64(*hi))
+		}
+
+		options = append(options, naturalRangeRule(minNat, maxNat))
+	}
+
+	return alternation(options)
+}
+
+func magnitude(v int64) uint64 {
+	return uint64(-(v + 1)) + 1
+}
+
+// naturalRangeRule matches the non-negative integers from lo to hi without
+// leading zeros, where a nil hi is unbounded. The range is split by digit
+// count so each part compares digits position by position.
+func naturalRangeRule(lo uint64, hi *uint64) string {
+	from := strconv.FormatUint(lo, 10)
+
+	if hi == nil {
+		return alternation([]string{
+			digitRangeRule(from, strings.Repeat("9", len(from))),
+			"[1-9] [0-9]" + quantifier(len(from), -1),
+		})
+	}
+
+	to := strconv.FormatUint(*hi, 10)
+
+	var options []string
+	for n := len(from); n <= len(to); n++ {
+		lower := "1" + strings.Repeat("0", n-1)
+		if n == len(from) {
+			lower = from
+		}
+
+		upper := strings.Repeat("9", n)
+		if n == len(to) {
+			upper = to
+		}
+
+		options = append(options, digitRangeRule(lower, upper))
+	}
+
+	return alternation(options)
+}
+
+// digitRangeRule matches the digit strings from lo to hi, which have the same
+// length.
+func digitRangeRule(lo string, hi string) string {
+	if lo == hi {
+		return `"` + lo + `"`
+	}
+
+	if len(lo) == 1 {
+		return digitClass(lo[0], hi[0])
+	}
+
+	if lo[0] == hi[0] {
+		return fmt.Sprintf(`"%c" %s`, lo[0], digitRangeRule(lo[1:], hi[1:]))
+	}
+
+	rest := len(lo) - 1
+
+	var options []string
+
+	first := lo[0]
+	if strings.Trim(lo[1:], "0") != "" {
+		options = append(options, fmt.Sprintf(`"%c" %s`, lo[0], digitRangeRule(lo[1:], strings.Repeat("9", rest))))
+		first++
+	}
+
+	last := hi[0]
+	var tail string
+	if strings.Trim(hi[1:], "9") != "" {
+		tail = fmt.Sprintf(`"%c" %s`, hi[0], digitRangeRule(strings.Repeat("0", rest), hi[1:]))
+		last--
+	}
+
+	if first <= last {
+		options = append(options, digitClass(first, last)+" [0-9]"+quantifier(rest, rest))
+	}
+
+	if tail != "" {
+		options = append(options, tail)
+	}
+
+	return alternation(options)
+}
+
+func digitClass(lo byte, hi byte) string {
+	if lo == hi {
+		return fmt.Sprintf(`"%c"`, lo)
+	}
+
+	return fmt.Sprintf("[%c-%c]", lo, hi)
+}
+
+// =============================================================================
+// Regular expression patterns.
+
+// patternToRule converts a JSON Schema pattern into a rule for the characters
+// of a JSON string. Patterns are unanchored unless they start with ^ or end
+// with $, so the unanchored ends accept any characters.
+func patternToRule(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+
+	subs := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		subs = re.Sub
+	}
+
+	anchoredStart := len(subs) > 0 && subs[0].Op == syntax.OpBeginText
+	if anchoredStart {
+		subs = subs[1:]
+	}
+
+	anchoredEnd := len(subs) > 0 && subs[len(subs)-1].Op == syntax.OpEndText
+	if anchoredEnd {
+		subs = subs[:len(subs)-1]
+	}
+
+	var parts []string
+	if !anchoredStart {
+		parts = append(parts, "char*")
+	}
+
+	for _, sub := range subs {
+		rule, err := regexpToRule(sub)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, rule)
+	}
+
+	if !anchoredEnd {
+		parts = append(parts, "char*")
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+func regexpToRule(re *syntax.Regexp) (string, error) {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		return `""`, nil
+
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			parts := make([]string, 0, len(re.Rune))
+			for _, r := range re.Rune {
+				rule, err := charClassRule(foldRanges(r))
+				if err != nil {
+					return "", err
+				}
+				parts = append(parts, rule)
+			}
+			return strings.Join(parts, " "), nil
+		}
+
+		text, err := jsonText(string(re.Rune))
+		if err != nil {
+			return "", err
+		}
+		return gbnfQuote(text[1 : len(text)-1]), nil
+
+	case syntax.OpCharClass:
+		return charClassRule(re.Rune)
+
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return "char", nil
+
+	case syntax.OpCapture:
+		return groupRegexp(re.Sub[0], "")
+
+	case syntax.OpStar:
+		return groupRegexp(re.Sub[0], "*")
+
+	case syntax.OpPlus:
+		return groupRegexp(re.Sub[0], "+")
+
+	case syntax.OpQuest:
+		return groupRegexp(re.Sub[0], "?")
+
+	case syntax.OpRepeat:
+		return groupRegexp(re.Sub[0], quantifier(re.Min, re.Max))
+
+	case syntax.OpConcat:
+		parts := make([]string, 0, len(re.Sub))
+		for _, sub := range re.Sub {
+			rule, err := regexpToRule(sub)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, rule)
+		}
+		return strings.Join(parts, " "), nil
+
+	case syntax.OpAlternate:
+		options := make([]string, 0, len(re.Sub))
+		for _, sub := range re.Sub {
+			rule, err := regexpToRule(sub)
+			if err != nil {
+				return "", err
+			}
+			options = append(options, rule)
+		}
+		return alternation(options), nil
+
+	default:
+		return "", fmt.Errorf("unsupported regular expression construct %q", re.String())
+	}
+}
+
+// groupRegexp converts re and applies a quantifier suffix, grouping the
+// result unless it is already a single character rule or group.
+func groupRegexp(re *syntax.Regexp, suffix string) (string, error) {
+	rule, err := regexpToRule(re)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case suffix == "":
+		return rule, nil
+
+	case re.Op == syntax.OpCharClass, re.Op == syntax.OpAnyChar, re.Op == syntax.OpAnyCharNotNL, re.Op == syntax.OpAlternate,
+		re.Op == syntax.OpLiteral && len(re.Rune) == 1:
+		return rule + suffix, nil
+
+	default:
+		return fmt.Sprintf("( %s )%s", rule, suffix), nil
+	}
+}
+
+func foldRanges(r rune) []rune {
+	runes := []rune{r}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		runes = append(runes, f)
+	}
+	slices.Sort(runes)
+
+	ranges := make([]rune, 0, 2*len(runes))
+	for _, f := range runes {
+		ranges = append(ranges, f, f)
+	}
+
+	return ranges
+}
+
+// charClassRule converts character class ranges into a rule for characters
+// inside a JSON string. Control characters are dropped, and the quote and
+// backslash characters are matched in their escaped form.
+func charClassRule(ranges []rune) (string, error) {
+	var class strings.Builder
+	var escaped []string
+
+	add := func(lo rune, hi rune) {
+		class.WriteString(classChar(lo))
+		if hi > lo {
+			class.WriteString("-" + classChar(hi))
+		}
+	}
+
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], 0x20), ranges[i+1]
+		if lo > hi {
+			continue
+		}
+
+		for _, special := range []rune{'"', '\\'} {
+			if special < lo || special > hi {
+				continue
+			}
+			if special > lo {
+				add(lo, special-1)
+			}
+			escaped = append(escaped, gbnfQuote(`\`+string(special)))
+			lo = special + 1
+		}
+
+		if lo <= hi {
+			add(lo, hi)
+		}
+	}
+
+	var options []string
+	if class.Len() > 0 {
+		options = append(options, "["+class.String()+"]")
+	}
+	options = append(options, escaped...)
+
+	if len(options) == 0 {
+		return "", fmt.Errorf("character class matches no JSON string character")
+	}
+
+	return alternation(options), nil
+}
+
+func classChar(r rune) string {
+	switch {
+	case strings.ContainsRune(`[]\^-`, r):
+		return fmt.Sprintf(`\x%02X`, r)
+
+	case r >= 0x20 && r < 0x7F:
+		return string(r)
+
+	case r <= 0xFFFF:
+		return fmt.Sprintf(`\u%04X`, r)
+
+	default:
+		return fmt.Sprintf(`\U%08X`, r)
+	}
+}
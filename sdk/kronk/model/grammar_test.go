@@ -3,6 +3,8 @@ package model
 import (
 	"errors"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -129,21 +131,36 @@ func TestFromJSONSchema_WithEnum(t *testing.T) {
 	}
 }
 
-func TestFromJSONSchema_EnumGrammarInitializes(t *testing.T) {
-	schema := D{
-		"type": "object",
-		"properties": D{
-			"verdict": D{
-				"type": "string",
-				"enum": []any{"yes", "no", "maybe"},
+func TestFromJSONSchema_GrammarInitializes(t *testing.T) {
+	schemas := map[string]D{
+		"enum": {
+			"type": "object",
+			"properties": D{
+				"verdict": D{
+					"type": "string",
+					"enum": []any{"yes", "no", "maybe"},
+				},
 			},
+			"required": []string{"verdict"},
+		},
+		"keywords": {
+			"$defs": D{
+				"node": D{
+					"type": "object",
+					"properties": D{
+						"id":       D{"type": "string", "format": "uuid"},
+						"label":    D{"type": []any{"string", "null"}, "pattern": `^[a-z]+(-[a-z]+)*$`},
+						"score":    D{"type": "integer", "minimum": -5, "maximum": 250},
+						"created":  D{"type": "string", "format": "date-time"},
+						"tags":     D{"type": "array", "items": D{"type": "string", "maxLength": 8}, "maxItems": 4},
+						"children": D{"type": "array", "items": D{"$ref": "#/$defs/node"}},
+					},
+					"required":             []string{"id"},
+					"additionalProperties": false,
+				},
+			},
+			"$ref": "#/$defs/node",
 		},
-		"required": []string{"verdict"},
-	}
-
-	grammar, err := fromJSONSchema(schema)
-	if err != nil {
-		t.Fatalf("fromJSONSchema: unexpected error: %v", err)
 	}
 
 	pattern := filepath.Join(defaults.BaseDir(""), "models", "*", "*", grammarTestModelFile)
@@ -174,13 +191,22 @@ func TestFromJSONSchema_EnumGrammarInitializes(t *testing.T) {
 		}
 	})
 
-	sampler := llama.SamplerInitGrammar(llama.ModelGetVocab(mdl), grammar, "root")
-	if sampler == 0 {
-		t.Fatal("SamplerInitGrammar: got zero sampler, want initialized sampler")
+	for name, schema := range schemas {
+		t.Run(name, func(t *testing.T) {
+			grammar, err := fromStrictJSONSchema(schema)
+			if err != nil {
+				t.Fatalf("fromStrictJSONSchema: unexpected error: %v", err)
+			}
+
+			sampler := llama.SamplerInitGrammar(llama.ModelGetVocab(mdl), grammar, "root")
+			if sampler == 0 {
+				t.Fatalf("SamplerInitGrammar: got zero sampler, want initialized sampler for\n%s", grammar)
+			}
+			t.Cleanup(func() {
+				llama.SamplerFree(sampler)
+			})
+		})
 	}
-	t.Cleanup(func() {
-		llama.SamplerFree(sampler)
-	})
 }
 
 func TestFromJSONSchema_Array(t *testing.T) {
@@ -398,3 +424,252 @@ func TestFromResponseFormat_MapStringAny(t *testing.T) {
 		t.Error("grammar should contain flag property")
 	}
 }
+
+func TestFromJSONSchema_Keywords(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    D
+		wantRules []string
+	}{
+		{
+			name: "recursive ref",
+			schema: D{
+				"$defs": D{
+					"node": D{
+						"type": "object",
+						"properties": D{
+							"value":    D{"type": "integer"},
+							"children": D{"type": "array", "items": D{"$ref": "#/$defs/node"}},
+						},
+						"required": []string{"value", "children"},
+					},
+				},
+				"$ref": "#/$defs/node",
+			},
+			wantRules: []string{
+				`root ::= def-node`,
+				`def-node ::= "{" ws "\"" "children" "\"" ws ":" ws "[" ws ( def-node ( ws "," ws def-node )* )? ws "]" ws "," ws "\"" "value" "\"" ws ":" ws integer ws "}"`,
+			},
+		},
+		{
+			name: "any of with const",
+			schema: D{
+				"anyOf": []any{
+					D{"type": "integer"},
+					D{"const": `say "hi"`},
+				},
+			},
+			wantRules: []string{
+				`root ::= ( integer | "\"" "say \\\"hi\\\"" "\"" )`,
+			},
+		},
+		{
+			name: "nullable type array",
+			schema: D{
+				"type": []any{"string", "null"},
+			},
+			wantRules: []string{
+				`root ::= ( string | "null" )`,
+			},
+		},
+		{
+			name: "item and length bounds",
+			schema: D{
+				"type":     "array",
+				"items":    D{"type": "string", "minLength": 2, "maxLength": 5},
+				"minItems": 1,
+				"maxItems": 3,
+			},
+			wantRules: []string{
+				`root ::= "[" ws "\"" char{2,5} "\"" ( ws "," ws "\"" char{2,5} "\"" ){0,2} ws "]"`,
+			},
+		},
+		{
+			name: "optional properties",
+			schema: D{
+				"type": "object",
+				"properties": D{
+					"a": D{"type": "string"},
+					"b": D{"type": "boolean"},
+				},
+				"additionalProperties": false,
+			},
+			wantRules: []string{
+				`root ::= "{" ws ( "\"" "a" "\"" ws ":" ws string ( ws "," ws "\"" "b" "\"" ws ":" ws boolean )? | "\"" "b" "\"" ws ":" ws boolean )? ws "}"`,
+			},
+		},
+		{
+			name: "additional properties schema",
+			schema: D{
+				"type":                 "object",
+				"properties":           D{"id": D{"type": "integer"}},
+				"required":             []string{"id"},
+				"additionalProperties": D{"type": "number"},
+			},
+			wantRules: []string{
+				`root ::= "{" ws "\"" "id" "\"" ws ":" ws integer ( ws "," ws string ws ":" ws number )* ws "}"`,
+			},
+		},
+		{
+			name: "all of",
+			schema: D{
+				"allOf": []any{
+					D{"type": "object", "properties": D{"a": D{"type": "string"}}, "required": []string{"a"}},
+					D{"properties": D{"b": D{"type": "boolean"}}, "required": []string{"b"}},
+				},
+			},
+			wantRules: []string{
+				`root ::= "{" ws "\"" "a" "\"" ws ":" ws string ws "," ws "\"" "b" "\"" ws ":" ws boolean ws "}"`,
+			},
+		},
+		{
+			name: "format",
+			schema: D{
+				"type":       "object",
+				"properties": D{"user_id": D{"type": "string", "format": "uuid"}},
+				"required":   []string{"user_id"},
+			},
+			wantRules: []string{
+				`root ::= "{" ws "\"" "user_id" "\"" ws ":" ws "\"" uuid "\"" ws "}"`,
+				`uuid ::= [0-9a-fA-F]{8} "-"`,
+			},
+		},
+		{
+			name: "pattern",
+			schema: D{
+				"type":    "string",
+				"pattern": `^[A-Z]{2}-\d+$`,
+			},
+			wantRules: []string{
+				`root ::= "\"" [A-Z]{2} "-" [0-9]+ "\""`,
+			},
+		},
+		{
+			name: "integer range",
+			schema: D{
+				"type":    "integer",
+				"minimum": 1,
+				"maximum": 120,
+			},
+			wantRules: []string{
+				`root ::= ( [1-9] | [1-9] [0-9] | "1" ( [0-1] [0-9] | "2" "0" ) )`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grammar, err := fromStrictJSONSchema(tt.schema)
+			if err != nil {
+				t.Fatalf("fromStrictJSONSchema: unexpected error: %v", err)
+			}
+
+			for _, want := range tt.wantRules {
+				if !strings.Contains(grammar, want) {
+					t.Errorf("grammar: got\n%s\nwant rule %q", grammar, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFromResponseFormat_Strict(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema D
+	}{
+		{name: "unsupported keyword", schema: D{"type": "array", "uniqueItems": true}},
+		{name: "unsupported format", schema: D{"type": "string", "format": "hostname"}},
+		{name: "number bounds", schema: D{"type": "number", "maximum": 1.5}},
+		{name: "unsupported pattern", schema: D{"type": "string", "pattern": `\bword\b`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rf := D{
+				"type":        "json_schema",
+				"json_schema": D{"name": "out", "strict": true, "schema": tt.schema},
+			}
+
+			if _, err := fromResponseFormat(rf); !errors.Is(err, ErrInvalidRequest) {
+				t.Errorf("strict: got %v, want ErrInvalidRequest", err)
+			}
+
+			rf["json_schema"].(D)["strict"] = false
+
+			if _, err := fromResponseFormat(rf); err != nil {
+				t.Errorf("lenient: unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFromJSONSchema_InvalidRef(t *testing.T) {
+	for _, ref := range []string{"#/$defs/missing", "https://example.com/schema.json"} {
+		schema := D{"$ref": ref}
+
+		if _, err := fromJSONSchema(schema); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("ref %q: got %v, want ErrInvalidRequest", ref, err)
+		}
+	}
+}
+
+func TestIntegerRangeRule(t *testing.T) {
+	tests := []struct {
+		name string
+		lo   *int64
+		hi   *int64
+	}{
+		{name: "bounded", lo: new(int64(7)), hi: new(int64(1234))},
+		{name: "negative", lo: new(int64(-305)), hi: new(int64(-12))},
+		{name: "spans zero", lo: new(int64(-99)), hi: new(int64(100))},
+		{name: "minimum only", lo: new(int64(42))},
+		{name: "maximum only", hi: new(int64(-3))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := integerRangeRule(tt.lo, tt.hi)
+
+			// The rule only uses digit literals, digit classes, groups, and
+			// quantifiers, which read as a regular expression once the quotes
+			// and spaces are removed.
+			re, err := regexp.Compile("^" + strings.NewReplacer(`"`, "", " ", "").Replace(rule) + "$")
+			if err != nil {
+				t.Fatalf("compile %q: %v", rule, err)
+			}
+
+			for v := int64(-2000); v <= 2000; v++ {
+				want := (tt.lo == nil || v >= *tt.lo) && (tt.hi == nil || v <= *tt.hi)
+				if got := re.MatchString(strconv.FormatInt(v, 10)); got != want {
+					t.Fatalf("%d: got match %t, want %t in %s", v, got, want, rule)
+				}
+			}
+		})
+	}
+}
+
+func TestPatternToRule(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{pattern: `^abc$`, want: `"abc"`},
+		{pattern: `abc`, want: `char* "abc" char*`},
+		{pattern: `^(cat|dog)s?$`, want: `( "cat" | "dog" ) "s"?`},
+		{pattern: `^[^a-z]$`, want: `( [ -!#-\x5B\x5D-` + "`" + `{-\U0010FFFF] | "\\\"" | "\\\\" )`},
+		{pattern: `^.{3}$`, want: `char{3}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got, err := patternToRule(tt.pattern)
+			if err != nil {
+				t.Fatalf("patternToRule: unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("rule: got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
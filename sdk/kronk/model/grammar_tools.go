@@ -0,0 +1,372 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ToolGrammar builds the grammar that forces a model to emit a tool call.
+// Kronk converts each declared tool's parameter schema into GBNF rules, and
+// the selected parser combines them into its native tool-call syntax through
+// ToolCallGrammarParser. Rule names returned by the methods are valid only in
+// the grammar being built.
+type ToolGrammar struct {
+	gb       *grammarBuilder
+	tools    []D
+	parallel bool
+	until    map[string]string
+}
+
+// GrammarParameter describes one declared parameter of a tool for parsers
+// whose native format writes each argument separately.
+type GrammarParameter struct {
+	// Name is the parameter name.
+	Name string
+
+	// Required reports whether the tool schema requires the parameter.
+	Required bool
+
+	// Value is the rule matching the parameter's value as JSON.
+	Value string
+
+	// String reports whether the parameter is declared as a string, which
+	// formats such as XML arguments write without JSON quoting.
+	String bool
+
+	// Raw is the rule matching the unquoted text of a string parameter
+	// restricted by enum or const, and is empty for other parameters.
+	Raw string
+}
+
+// Names returns the names of the tools a call may use.
+func (tg *ToolGrammar) Names() []string {
+	names := make([]string, 0, len(tg.tools))
+	for _, tool := range tg.tools {
+		function, _ := tool["function"].(D)
+		name, _ := function["name"].(string)
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// Parallel reports whether the request allows more than one tool call.
+func (tg *ToolGrammar) Parallel() bool {
+	return tg.parallel
+}
+
+// Repeat returns call once, or one or more times separated by sep when the
+// request allows parallel tool calls.
+func (tg *ToolGrammar) Repeat(call string, sep string) string {
+	if !tg.parallel {
+		return call
+	}
+
+	if sep == "" {
+		return fmt.Sprintf("( %s )+", call)
+	}
+
+	return fmt.Sprintf("%s ( %s %s )*", call, sep, call)
+}
+
+// Literal returns the grammar literal matching s exactly.
+func (tg *ToolGrammar) Literal(s string) string {
+	return gbnfQuote(s)
+}
+
+// Alternatives returns a rule matching any one of options.
+func (tg *ToolGrammar) Alternatives(options []string) string {
+	return alternation(options)
+}
+
+// Rule adds a rule to the grammar and returns its name, which is derived from
+// name and made unique.
+func (tg *ToolGrammar) Rule(name string, body string) string {
+	name = tg.gb.uniqueRuleName(ruleName(name))
+	tg.gb.rules[name] = body
+
+	return name
+}
+
+// Arguments returns the rule matching the JSON object of arguments for the
+// named tool.
+func (tg *ToolGrammar) Arguments(name string) (string, error) {
+	tool, err := tg.tool(name)
+	if err != nil {
+		return "", err
+	}
+
+	params, ok := tool.parameters()
+	if !ok {
+		return "object", nil
+	}
+
+	tg.begin(params)
+
+	return tg.gb.namedRule(tool.ruleName+"-args", "#", params)
+}
+
+// Parameters returns the declared parameters of the named tool, required
+// parameters first and each group sorted by name.
+func (tg *ToolGrammar) Parameters(name string) ([]GrammarParameter, error) {
+	tool, err := tg.tool(name)
+	if err != nil {
+		return nil, err
+	}
+
+	params, ok := tool.parameters()
+	if !ok {
+		return nil, nil
+	}
+
+	tg.begin(params)
+
+	props, _ := schemaObject(params["properties"])
+	required := schemaStrings(params["required"])
+
+	keys := slices.Sorted(maps.Keys(props))
+	slices.SortStableFunc(keys, func(a string, b string) int {
+		switch ra, rb := slices.Contains(required, a), slices.Contains(required, b); {
+		case ra && !rb:
+			return -1
+		case rb && !ra:
+			return 1
+		}
+		return 0
+	})
+
+	parameters := make([]GrammarParameter, 0, len(keys))
+	for _, key := range keys {
+		value, err := tg.gb.namedRule(tool.ruleName+"-"+ruleName(key), "#/properties/"+key, props[key])
+		if err != nil {
+			return nil, err
+		}
+
+		propSchema, _ := schemaObject(props[key])
+		schemaType, _ := propSchema["type"].(string)
+
+		parameters = append(parameters, GrammarParameter{
+			Name:     key,
+			Required: slices.Contains(required, key),
+			Value:    value,
+			String:   schemaType == "string",
+			Raw:      rawStringRule(propSchema),
+		})
+	}
+
+	return parameters, nil
+}
+
+// rawStringRule returns the rule matching the unquoted text of a string
+// schema limited to fixed values, or "" when the schema allows other text.
+func rawStringRule(schema map[string]any) string {
+	values, ok := schemaArray(schema["enum"])
+	if v, exists := schema["const"]; exists {
+		values, ok = []any{v}, true
+	}
+	if !ok || len(values) == 0 {
+		return ""
+	}
+
+	options := make([]string, 0, len(values))
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return ""
+		}
+		options = append(options, gbnfQuote(s))
+	}
+
+	return alternation(options)
+}
+
+// TextUntil returns a rule matching any text that does not contain stop or
+// any character in exclude, for raw argument values closed by a marker. Such
+// values are not checked against the parameter's schema beyond being text.
+func (tg *ToolGrammar) TextUntil(stop string, exclude string) string {
+	key := stop + "\x00" + exclude
+	if rule, exists := tg.until[key]; exists {
+		return rule
+	}
+
+	runes := []rune(stop)
+	base := tg.gb.uniqueRuleName("until-" + strings.Trim(ruleName(stop), "-"))
+	tg.until[key] = base
+
+	chars := slices.Compact(slices.Sorted(slices.Values(runes)))
+
+	var others strings.Builder
+	for _, r := range slices.Compact(slices.Sorted(slices.Values([]rune(string(chars) + exclude)))) {
+		others.WriteString(classChar(r))
+	}
+
+	// Each state records how much of stop the text currently ends with. A
+	// transition that would complete stop is left out.
+	state := func(q int) string {
+		if q == 0 {
+			return base
+		}
+		return fmt.Sprintf("%s-%d", base, q)
+	}
+
+	for q := range runes {
+		var options []string
+		for _, r := range chars {
+			if strings.ContainsRune(exclude, r) {
+				continue
+			}
+			next := nextMatchState(runes, q, r)
+			if next == len(runes) {
+				continue
+			}
+			options = append(options, fmt.Sprintf("%s %s", gbnfQuote(string(r)), state(next)))
+		}
+		options = append(options, fmt.Sprintf("[^%s] %s", others.String(), base))
+
+		tg.gb.rules[state(q)] = fmt.Sprintf("( %s )?", strings.Join(options, " | "))
+	}
+
+	return base
+}
+
+// nextMatchState returns the length of the longest prefix of stop that ends
+// the text after appending r to a text ending with the first q runes of stop.
+func nextMatchState(stop []rune, q int, r rune) int {
+	text := append(slices.Clone(stop[:q]), r)
+	for k := min(len(text), len(stop)); k > 0; k-- {
+		if slices.Equal(text[len(text)-k:], stop[:k]) {
+			return k
+		}
+	}
+
+	return 0
+}
+
+// JSONEnvelope returns a rule matching one call in the common JSON envelope
+// {"name": "<tool>", "<argumentsKey>": {...}}, for any declared tool.
+func (tg *ToolGrammar) JSONEnvelope(argumentsKey string) (string, error) {
+	nameKey, err := jsonLiteral("name")
+	if err != nil {
+		return "", err
+	}
+
+	argsKey, err := jsonLiteral(argumentsKey)
+	if err != nil {
+		return "", err
+	}
+
+	var calls []string
+	for _, name := range tg.Names() {
+		args, err := tg.Arguments(name)
+		if err != nil {
+			return "", err
+		}
+
+		nameValue, err := jsonLiteral(name)
+		if err != nil {
+			return "", err
+		}
+
+		calls = append(calls, fmt.Sprintf(`"{" ws %s ws ":" ws %s ws "," ws %s ws ":" ws %s ws "}"`, nameKey, nameValue, argsKey, args))
+	}
+
+	return alternation(calls), nil
+}
+
+// begin points schema references at the parameters of the tool being
+// converted, since each tool's $ref paths are relative to its own schema.
+func (tg *ToolGrammar) begin(params map[string]any) {
+	tg.gb.root = params
+	tg.gb.refs = make(map[string]string)
+}
+
+type grammarTool struct {
+	function D
+	ruleName string
+}
+
+func (tg *ToolGrammar) tool(name string) (grammarTool, error) {
+	for _, tool := range tg.tools {
+		function, _ := tool["function"].(D)
+		if function["name"] == name {
+			tg.gb.strict, _ = function["strict"].(bool)
+			return grammarTool{function: function, ruleName: "tool-" + ruleName(name)}, nil
+		}
+	}
+
+	return grammarTool{}, fmt.Errorf("%w: tool %q is not declared", ErrInvalidRequest, name)
+}
+
+func (t grammarTool) parameters() (map[string]any, bool) {
+	params, ok := schemaObject(t.function["parameters"])
+	if !ok || len(params) == 0 {
+		return nil, false
+	}
+
+	return params, true
+}
+
+// =============================================================================
+
+// toolCallGrammar returns the grammar forcing a tool call when tool_choice is
+// "required" or names a function, or "" when the request does not require a
+// call or the selected parser cannot express its tool-call syntax. applyToolChoice
+// must run first so a named function is the only declared tool.
+func (m *Model) toolCallGrammar(ctx context.Context, d D) (string, error) {
+	mode, _, _ := parseToolChoice(d["tool_choice"])
+	if mode != "required" && mode != "function" {
+		return "", nil
+	}
+
+	tools := functionTools(d)
+	if len(tools) == 0 {
+		return "", nil
+	}
+
+	gp, ok := m.parser.(ToolCallGrammarParser)
+	if !ok {
+		if m.parser != nil {
+			m.log(ctx, "tool-call-grammar", "status", "unsupported-parser", "parser", m.parser.Name())
+		}
+		return "", nil
+	}
+
+	parallel := true
+	if v, ok := d["parallel_tool_calls"].(bool); ok {
+		parallel = v
+	}
+
+	grammar, err := BuildToolCallGrammar(gp, tools, parallel)
+	if err != nil {
+		return "", fmt.Errorf("tool-call-grammar: %w", err)
+	}
+
+	return grammar, nil
+}
+
+// BuildToolCallGrammar returns the complete grammar the parser produces for
+// the declared function tools. When parallel is false the grammar allows a
+// single call.
+func BuildToolCallGrammar(gp ToolCallGrammarParser, tools []D, parallel bool) (string, error) {
+	tg := ToolGrammar{
+		gb: &grammarBuilder{
+			rules: map[string]string{"root": ""},
+		},
+		tools:    tools,
+		parallel: parallel,
+		until:    make(map[string]string),
+	}
+
+	root, err := gp.ToolCallGrammar(&tg)
+	if err != nil {
+		return "", err
+	}
+
+	tg.gb.rules["root"] = root
+	tg.gb.addCommonRules()
+
+	return tg.gb.build(), nil
+}
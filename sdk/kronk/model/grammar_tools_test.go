@@ -0,0 +1,266 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/applog"
+)
+
+// grammarParser is a minimal parser whose tool-call grammar is supplied by
+// the test.
+type grammarParser func(tg *ToolGrammar) (string, error)
+
+func (grammarParser) Name() string                                       { return "grammar-test" }
+func (grammarParser) NewStateMachine() StateMachine                      { return nil }
+func (gp grammarParser) ToolCallGrammar(tg *ToolGrammar) (string, error) { return gp(tg) }
+func (grammarParser) ToolCall(context.Context, applog.Logger, string) []ResponseToolCall {
+	return nil
+}
+
+func hermesGrammar(tg *ToolGrammar) (string, error) {
+	envelope, err := tg.JSONEnvelope("arguments")
+	if err != nil {
+		return "", err
+	}
+
+	call := tg.Rule("tool-call", `"<tool_call>" ws `+envelope+` ws "</tool_call>"`)
+
+	return tg.Repeat(call, "ws"), nil
+}
+
+func grammarTestTools() []D {
+	return []D{
+		{
+			"type": "function",
+			"function": D{
+				"name": "get_weather",
+				"parameters": D{
+					"type": "object",
+					"properties": D{
+						"location": D{"type": "string"},
+						"days":     D{"type": "integer", "minimum": 1, "maximum": 7},
+					},
+					"required": []string{"location"},
+				},
+			},
+		},
+		{
+			"type":     "function",
+			"function": D{"name": "get_time"},
+		},
+	}
+}
+
+func grammarRules(grammar string) map[string]string {
+	rules := make(map[string]string)
+	for line := range strings.SplitSeq(grammar, "\n") {
+		name, body, _ := strings.Cut(line, " ::= ")
+		rules[name] = body
+	}
+
+	return rules
+}
+
+func TestBuildToolCallGrammar(t *testing.T) {
+	grammar, err := BuildToolCallGrammar(grammarParser(hermesGrammar), grammarTestTools(), true)
+	if err != nil {
+		t.Fatalf("BuildToolCallGrammar: %v", err)
+	}
+
+	rules := grammarRules(grammar)
+	want := map[string]string{
+		"root":                       `tool-call ( ws tool-call )*`,
+		"tool-call":                  `"<tool_call>" ws ( "{" ws "\"" "name" "\"" ws ":" ws "\"" "get_weather" "\"" ws "," ws "\"" "arguments" "\"" ws ":" ws "{" ws "\"" "location" "\"" ws ":" ws string ( ws "," ws "\"" "days" "\"" ws ":" ws tool-get-weather-args-days )? ws "}" ws "}" | "{" ws "\"" "name" "\"" ws ":" ws "\"" "get_time" "\"" ws "," ws "\"" "arguments" "\"" ws ":" ws object ws "}" ) ws "</tool_call>"`,
+		"tool-get-weather-args-days": `[1-7]`,
+	}
+	for name, body := range want {
+		if got, exists := rules[name]; !exists || got != body {
+			t.Errorf("rule %s:\ngot  %q\nwant %q\ngrammar:\n%s", name, got, body, grammar)
+		}
+	}
+	for _, name := range []string{"object", "string", "ws"} {
+		if _, exists := rules[name]; !exists {
+			t.Errorf("rule %s: missing from grammar:\n%s", name, grammar)
+		}
+	}
+
+	grammar, err = BuildToolCallGrammar(grammarParser(hermesGrammar), grammarTestTools(), false)
+	if err != nil {
+		t.Fatalf("BuildToolCallGrammar: %v", err)
+	}
+	if got := grammarRules(grammar)["root"]; got != "tool-call" {
+		t.Errorf("single call root: got %q, want %q", got, "tool-call")
+	}
+}
+
+func TestBuildToolCallGrammar_Strict(t *testing.T) {
+	tools := []D{
+		{
+			"type": "function",
+			"function": D{
+				"name":   "score",
+				"strict": true,
+				"parameters": D{
+					"type":       "object",
+					"properties": D{"value": D{"type": "number", "multipleOf": 0.5}},
+				},
+			},
+		},
+	}
+
+	_, err := BuildToolCallGrammar(grammarParser(hermesGrammar), tools, true)
+	if !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("BuildToolCallGrammar: got %v, want ErrInvalidRequest", err)
+	}
+	if !strings.Contains(err.Error(), "multipleOf") {
+		t.Errorf("error: got %q, want it to name the multipleOf keyword", err)
+	}
+
+	tools[0]["function"].(D)["strict"] = false
+	if _, err := BuildToolCallGrammar(grammarParser(hermesGrammar), tools, true); err != nil {
+		t.Errorf("BuildToolCallGrammar: non-strict tool: %v", err)
+	}
+}
+
+func TestToolGrammar_Parameters(t *testing.T) {
+	tools := []D{
+		{
+			"type": "function",
+			"function": D{
+				"name": "search",
+				"parameters": D{
+					"type": "object",
+					"properties": D{
+						"query": D{"type": "string"},
+						"limit": D{"type": "integer"},
+						"exact": D{"type": "boolean"},
+						"mode":  D{"type": "string", "enum": []any{"fast", "full"}},
+					},
+					"required": []string{"query"},
+				},
+			},
+		},
+	}
+
+	var got []GrammarParameter
+	parser := grammarParser(func(tg *ToolGrammar) (string, error) {
+		var err error
+		got, err = tg.Parameters("search")
+		return `"x"`, err
+	})
+	if _, err := BuildToolCallGrammar(parser, tools, true); err != nil {
+		t.Fatalf("BuildToolCallGrammar: %v", err)
+	}
+
+	want := []GrammarParameter{
+		{Name: "query", Required: true, Value: "string", String: true},
+		{Name: "exact", Value: "boolean"},
+		{Name: "limit", Value: "integer"},
+		{Name: "mode", Value: "tool-search-mode", String: true, Raw: `( "fast" | "full" )`},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parameters: got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parameter %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestToolGrammar_TextUntil(t *testing.T) {
+	parser := grammarParser(func(tg *ToolGrammar) (string, error) {
+		rule := tg.TextUntil("aab", "\n")
+		if again := tg.TextUntil("aab", "\n"); again != rule {
+			t.Errorf("TextUntil: got %q on second call, want %q", again, rule)
+		}
+		return rule, nil
+	})
+
+	grammar, err := BuildToolCallGrammar(parser, nil, true)
+	if err != nil {
+		t.Fatalf("BuildToolCallGrammar: %v", err)
+	}
+
+	rules := grammarRules(grammar)
+	want := map[string]string{
+		"root":        `until-aab`,
+		"until-aab":   `( "a" until-aab-1 | "b" until-aab | [^\u000Aab] until-aab )?`,
+		"until-aab-1": `( "a" until-aab-2 | "b" until-aab | [^\u000Aab] until-aab )?`,
+		"until-aab-2": `( "a" until-aab-2 | [^\u000Aab] until-aab )?`,
+	}
+	for name, body := range want {
+		if got := rules[name]; got != body {
+			t.Errorf("rule %s:\ngot  %q\nwant %q", name, got, body)
+		}
+	}
+}
+
+func TestToolCallGrammar(t *testing.T) {
+	m := Model{log: noopLog, parser: grammarParser(hermesGrammar)}
+
+	tests := []struct {
+		name        string
+		toolChoice  any
+		wantGrammar bool
+	}{
+		{name: "omitted"},
+		{name: "auto", toolChoice: "auto"},
+		{name: "none", toolChoice: "none"},
+		{name: "required", toolChoice: "required", wantGrammar: true},
+		{name: "function", toolChoice: D{"type": "function", "function": D{"name": "get_time"}}, wantGrammar: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := D{
+				"messages": []D{{"role": "user", "content": "hi"}},
+				"tools":    grammarTestTools(),
+			}
+			if tt.toolChoice != nil {
+				d["tool_choice"] = tt.toolChoice
+			}
+
+			params, err := m.validateDocument(t.Context(), d)
+			if err != nil {
+				t.Fatalf("validateDocument: %v", err)
+			}
+
+			if got := params.Grammar != ""; got != tt.wantGrammar {
+				t.Fatalf("grammar set: got %v, want %v", got, tt.wantGrammar)
+			}
+			if !tt.wantGrammar {
+				return
+			}
+
+			if params.Thinking != ThinkingDisabled {
+				t.Errorf("thinking: got %q, want %q", params.Thinking, ThinkingDisabled)
+			}
+			if tt.name == "function" && strings.Contains(params.Grammar, "get_weather") {
+				t.Errorf("named function grammar allows other tools:\n%s", params.Grammar)
+			}
+		})
+	}
+}
+
+func TestToolCallGrammar_Precedence(t *testing.T) {
+	m := Model{log: noopLog, parser: grammarParser(hermesGrammar)}
+
+	d := D{
+		"messages":        []D{{"role": "user", "content": "hi"}},
+		"tools":           grammarTestTools(),
+		"tool_choice":     "required",
+		"response_format": D{"type": "json_object"},
+	}
+
+	params, err := m.validateDocument(t.Context(), d)
+	if err != nil {
+		t.Fatalf("validateDocument: %v", err)
+	}
+	if strings.Contains(params.Grammar, "tool_call") {
+		t.Errorf("grammar: response_format should take precedence over tool_choice:\n%s", params.Grammar)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation"
 	"github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/classic"
 	"github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/mtp"
+	"github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/ngram"
 	"github.com/hybridgroup/yzma/pkg/llama"
 )
 
@@ -101,6 +102,44 @@ func TestMTPController(t *testing.T) {
 	}
 }
 
+func TestNGramController(t *testing.T) {
+	host := fakeHost{
+		active:     []bool{true, true, false},
+		hasRound:   []bool{true, false, false},
+		pending:    []bool{true, false, false},
+		candidates: map[int][]llama.Token{0: {31, 32}},
+	}
+	controller := ngram.New(&host)
+
+	if got := controller.Mode(); got != speculation.ModeNGram {
+		t.Errorf("Mode() = %q, want %q", got, speculation.ModeNGram)
+	}
+
+	controller.BeginBatch()
+	controller.Prepare()
+	generation, err := controller.PlanGeneration(0)
+	if err != nil {
+		t.Fatalf("PlanGeneration() error = %v, want nil", err)
+	}
+	if want := []llama.Token{31, 32}; !slices.Equal(generation.Candidates, want) {
+		t.Errorf("Candidates = %v, want %v", generation.Candidates, want)
+	}
+	if generation.Mode != "ngram" {
+		t.Errorf("Mode = %q, want ngram", generation.Mode)
+	}
+
+	targetRange := speculation.TargetRange{Start: 0, Count: 3, BasePos: 12}
+	if err := controller.CommitGeneration(0, generation.Candidates, targetRange); err != nil {
+		t.Fatalf("CommitGeneration() error = %v, want nil", err)
+	}
+	controller.AfterTargetDecode(nil)
+
+	wantEvents := []string{"generate:0", "commit:0", "ordinary:1", "verify:0", "finalize:0"}
+	if !slices.Equal(host.events, wantEvents) {
+		t.Errorf("events = %v, want %v", host.events, wantEvents)
+	}
+}
+
 type fakeHost struct {
 	active       []bool
 	needsPrefill []bool
@@ -139,6 +178,10 @@ func (fh *fakeHost) ClassicGenerationInput(slot int) (classic.GenerationInput, e
 
 func (*fakeHost) CommitClassicDraft(int, classic.GenerationResult) {}
 
+func (fh *fakeHost) NGramGenerationInput(slot int) (classic.GenerationInput, error) {
+	return fh.ClassicGenerationInput(slot)
+}
+
 func (fh *fakeHost) MTPDraftInput(slot int) (mtp.DraftInput, error) {
 	fh.event("generate", slot)
 	candidates := fh.candidates[slot]
@@ -225,3 +268,4 @@ func (fh *fakeHost) event(name string, slot int) {
 
 var _ speculation.Host = (*fakeHost)(nil)
 var _ classic.Host = (*fakeHost)(nil)
+var _ ngram.Host = (*fakeHost)(nil)
@@ -0,0 +1,72 @@
+package ngram
+
+import (
+	"slices"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/classic"
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+// Bounds on the length of the history suffix matched against earlier tokens.
+// Longer matches are tried first because they predict the continuation more
+// reliably; a single token repeats too often to be worth verifying.
+const (
+	MinMatch = 2
+	MaxMatch = 4
+)
+
+// Lookup appends to dst up to count tokens that followed the most recent
+// earlier occurrence of the longest matching suffix of history, and returns
+// the extended slice. dst is returned unchanged when no suffix of at least
+// MinMatch tokens occurs earlier in history.
+func Lookup(dst, history []llama.Token, count int) []llama.Token {
+	if count <= 0 {
+		return dst
+	}
+
+	for n := min(MaxMatch, len(history)-1); n >= MinMatch; n-- {
+		suffix := history[len(history)-n:]
+
+		// Scan backward so the most recent occurrence wins. The match must
+		// end before the last token so at least one token follows it.
+		for start := len(history) - n - 1; start >= 0; start-- {
+			if history[start] != suffix[0] || !slices.Equal(history[start:start+n], suffix) {
+				continue
+			}
+			end := min(start+n+count, len(history))
+			return append(dst, history[start+n:end]...)
+		}
+	}
+
+	return dst
+}
+
+// Draft proposes up to count candidates continuing the slot's history.
+//
+// A lookup is a point guess rather than a sample, so for sampled requests each
+// candidate carries a one-hot draft distribution. Classic verification then
+// accepts the candidate with the target's probability for it and otherwise
+// samples the target's remaining mass, which leaves the output distribution
+// unchanged. Greedy requests compare tokens and need no distributions.
+func (s *SlotState) Draft(count int, greedy bool) classic.GenerationResult {
+	s.candidates = Lookup(s.candidates[:0], s.History, count)
+	if len(s.candidates) == 0 {
+		return classic.GenerationResult{}
+	}
+
+	result := classic.GenerationResult{Candidates: s.candidates}
+	if greedy {
+		return result
+	}
+
+	if cap(s.distributions) < len(s.candidates) {
+		s.distributions = make([][]llama.DraftCandidate, len(s.candidates))
+	}
+	s.distributions = s.distributions[:len(s.candidates)]
+	for i, token := range s.candidates {
+		s.distributions[i] = append(s.distributions[i][:0], llama.DraftCandidate{Tok: token, Prob: 1})
+	}
+	result.Distributions = s.distributions
+
+	return result
+}
@@ -0,0 +1,91 @@
+package ngram
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/classic"
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []llama.Token
+		count   int
+		want    []llama.Token
+	}{
+		{name: "copies continuation", history: []llama.Token{1, 2, 3, 4, 5, 9, 1, 2}, count: 3, want: []llama.Token{3, 4, 5}},
+		{name: "stops at history end", history: []llama.Token{1, 2, 3, 1, 2}, count: 5, want: []llama.Token{3, 1, 2}},
+		{name: "prefers longest match", history: []llama.Token{1, 2, 3, 5, 9, 2, 3, 8, 0, 1, 2, 3}, count: 1, want: []llama.Token{5}},
+		{name: "prefers most recent match", history: []llama.Token{1, 2, 6, 1, 2, 7, 1, 2}, count: 1, want: []llama.Token{7}},
+		{name: "ignores single token match", history: []llama.Token{1, 6, 2, 1}, count: 2},
+		{name: "no match", history: []llama.Token{1, 2, 3, 4}, count: 2},
+		{name: "zero count", history: []llama.Token{1, 2, 3, 1, 2}, count: 0},
+		{name: "empty history", count: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Lookup(nil, tt.history, tt.count)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Lookup() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDraftDistributions(t *testing.T) {
+	var state SlotState
+	state.Begin([]llama.Token{1, 2, 3, 4, 1})
+	state.Append(2)
+
+	greedy := state.Draft(2, true)
+	if want := []llama.Token{3, 4}; !slices.Equal(greedy.Candidates, want) {
+		t.Fatalf("greedy Candidates = %v, want %v", greedy.Candidates, want)
+	}
+	if greedy.Distributions != nil {
+		t.Errorf("greedy Distributions = %v, want nil", greedy.Distributions)
+	}
+
+	sampled := state.Draft(2, false)
+	if len(sampled.Distributions) != 2 {
+		t.Fatalf("len(Distributions) = %d, want 2", len(sampled.Distributions))
+	}
+	for i, dist := range sampled.Distributions {
+		want := []llama.DraftCandidate{{Tok: sampled.Candidates[i], Prob: 1}}
+		if !slices.Equal(dist, want) {
+			t.Errorf("Distributions[%d] = %v, want %v", i, dist, want)
+		}
+	}
+}
+
+func TestDraftVerifiesAgainstTarget(t *testing.T) {
+	var state SlotState
+	state.Begin([]llama.Token{1, 2, 0, 1})
+	state.Append(2)
+	result := state.Draft(1, false)
+
+	// The target gives the drafted token 0 a probability of 0.25, so a draw
+	// of 0.5 rejects it and the replacement comes from the remaining mass.
+	classicState := classic.SlotState{AcceptanceEMA: 1}
+	random := []float64{0.5, 0.1}
+	verify := classic.Verify(classic.VerifyInput{
+		State:         &classicState,
+		Candidates:    result.Candidates,
+		Distributions: result.Distributions,
+		Target: func(int) classic.Target {
+			return classic.Target{Probabilities: []float32{0.25, 0.5, 0.25}}
+		},
+		Accept: func(int, llama.Token, bool) bool { return true },
+		Random: func() float64 {
+			value := random[0]
+			random = random[1:]
+			return value
+		},
+	})
+
+	if verify.Accepted != 0 || verify.Bonus != 1 || !verify.Complete {
+		t.Errorf("Verify() = %+v, want rejection replaced by token 1", verify)
+	}
+}
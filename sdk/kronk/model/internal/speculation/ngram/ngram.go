@@ -0,0 +1,103 @@
+// Package ngram provides prompt-lookup speculative decoding. Candidates are
+// copied from the slot's own prompt and output tokens, so no draft model or
+// draft KV is involved and every execution slot can speculate.
+package ngram
+
+import (
+	"fmt"
+
+	"github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation"
+	"github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/classic"
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+// Controller implements the n-gram speculative-decoding lifecycle. Drafts are
+// verified and finalized with the classic algorithm; only draft generation
+// differs, and there is no draft state to roll back.
+type Controller struct {
+	host Host
+}
+
+// Host exposes target and output operations to the n-gram algorithm.
+type Host interface {
+	SlotCount() int
+	SlotActive(slot int) bool
+	NGramGenerationInput(slot int) (classic.GenerationInput, error)
+	CommitClassicDraft(slot int, result classic.GenerationResult)
+	CommitSpeculative(slot int, candidates []llama.Token, targetRange speculation.TargetRange) error
+	HasSpeculativeRound(slot int) bool
+	HasPendingFinalize(slot int) bool
+	ProcessOrdinary(slot int, buf []byte)
+	ClassicVerifyInput(slot int, buf []byte) (classic.VerifyInput, error)
+	CommitClassicVerify(slot int, buf []byte, result classic.VerifyResult)
+	ClassicFinalizePlan(slot int) (classic.FinalizePlan, bool)
+	RollbackClassicTarget(slot int, plan classic.FinalizePlan) (bool, error)
+	CompleteClassicFinalize(slot int, buf []byte, plan classic.FinalizePlan, hybridRestore bool)
+	Fail(slot int, err error)
+}
+
+// New constructs an n-gram speculation controller.
+func New(host Host) *Controller {
+	return &Controller{host: host}
+}
+
+func (*Controller) Mode() speculation.Mode { return speculation.ModeNGram }
+func (*Controller) Enabled() bool          { return true }
+func (*Controller) BeginBatch()            {}
+func (*Controller) Prepare()               {}
+
+func (c *Controller) PlanGeneration(slot int) (speculation.Generation, error) {
+	input, err := c.host.NGramGenerationInput(slot)
+	if err != nil {
+		return speculation.Generation{}, err
+	}
+	result, err := classic.Generate(input)
+	if err != nil {
+		return speculation.Generation{}, fmt.Errorf("generating n-gram draft tokens: %w", err)
+	}
+	c.host.CommitClassicDraft(slot, result)
+	return speculation.Generation{Candidates: result.Candidates, Mode: "ngram"}, nil
+}
+
+func (c *Controller) CommitGeneration(slot int, candidates []llama.Token, targetRange speculation.TargetRange) error {
+	return c.host.CommitSpeculative(slot, candidates, targetRange)
+}
+
+func (*Controller) TargetRowsStaged(int, speculation.TargetRange) {}
+
+func (c *Controller) AfterTargetDecode(buf []byte) {
+	for slot := range c.host.SlotCount() {
+		if c.host.SlotActive(slot) && !c.host.HasSpeculativeRound(slot) {
+			c.host.ProcessOrdinary(slot, buf)
+		}
+	}
+	for slot := range c.host.SlotCount() {
+		if !c.host.SlotActive(slot) || !c.host.HasSpeculativeRound(slot) {
+			continue
+		}
+		input, err := c.host.ClassicVerifyInput(slot, buf)
+		if err != nil {
+			c.host.Fail(slot, err)
+			continue
+		}
+		c.host.CommitClassicVerify(slot, buf, classic.Verify(input))
+	}
+	for slot := range c.host.SlotCount() {
+		if c.host.SlotActive(slot) && c.host.HasPendingFinalize(slot) {
+			c.finalize(slot, buf)
+		}
+	}
+}
+
+func (c *Controller) finalize(slot int, buf []byte) {
+	plan, ok := c.host.ClassicFinalizePlan(slot)
+	if !ok {
+		return
+	}
+	hybridRestore, err := c.host.RollbackClassicTarget(slot, plan)
+	if err != nil {
+		c.host.Fail(slot, err)
+		return
+	}
+	c.host.CompleteClassicFinalize(slot, buf, plan, hybridRestore)
+}
@@ -0,0 +1,28 @@
+package ngram
+
+import "github.com/hybridgroup/yzma/pkg/llama"
+
+// SlotState contains request-local state owned by n-gram speculation. History
+// holds the logical prompt followed by every emitted output token, ending with
+// the token the next target decode consumes.
+type SlotState struct {
+	History       []llama.Token
+	candidates    []llama.Token
+	distributions [][]llama.DraftCandidate
+}
+
+// Reset begins a request while retaining reusable buffer capacity.
+func (s *SlotState) Reset() {
+	s.History = s.History[:0]
+	s.candidates = s.candidates[:0]
+}
+
+// Begin seeds the history with the request's logical prompt.
+func (s *SlotState) Begin(prompt []llama.Token) {
+	s.History = append(s.History[:0], prompt...)
+}
+
+// Append records an emitted output token.
+func (s *SlotState) Append(token llama.Token) {
+	s.History = append(s.History, token)
+}
@@ -23,6 +23,10 @@ const (
 
 	// ModeMTP requires a companion or embedded MTP implementation.
 	ModeMTP Mode = "mtp"
+
+	// ModeNGram drafts by prompt lookup, matching the recent suffix against
+	// earlier prompt and output tokens, without a draft model.
+	ModeNGram Mode = "ngram"
 )
 
 // Source identifies the concrete implementation selected for a model.
@@ -40,6 +44,9 @@ const (
 
 	// SourceMTPEmbedded selects an embedded, own-KV MTP head.
 	SourceMTPEmbedded
+
+	// SourceNGram selects the prompt-lookup drafter.
+	SourceNGram
 )
 
 // Config contains the capabilities needed to resolve one speculation plan.
@@ -48,6 +55,7 @@ type Config struct {
 	ClassicConfigured bool
 	ClassicNDraft     int
 	MTPNDraft         int
+	NGramNDraft       int
 	EmbeddedMTP       bool
 	CompanionMTP      bool
 	MTPAvailable      bool
@@ -73,6 +81,12 @@ func (p Plan) MTP() bool {
 	return p.Source == SourceMTPCompanion || p.Source == SourceMTPEmbedded
 }
 
+// Drafter reports whether the plan loads a draft model or MTP head. N-gram
+// drafting proposes from the slot's own tokens and loads nothing.
+func (p Plan) Drafter() bool {
+	return p.Active() && p.Source != SourceNGram
+}
+
 // RowsPerSequence returns the worst-case target rows contributed per sequence.
 func (p Plan) RowsPerSequence() int {
 	if !p.Active() {
@@ -93,6 +107,12 @@ func Resolve(cfg Config) (Plan, error) {
 		}
 		return Plan{Mode: cfg.Mode, Source: SourceClassic, NDraft: cfg.ClassicNDraft, Available: true}, nil
 
+	case ModeNGram:
+		if cfg.ClassicConfigured {
+			return Plan{}, fmt.Errorf("speculation mode %q cannot use a separate draft model", cfg.Mode)
+		}
+		return Plan{Mode: cfg.Mode, Source: SourceNGram, NDraft: cfg.NGramNDraft, Available: true}, nil
+
 	case ModeMTP:
 		if cfg.ClassicConfigured {
 			return Plan{}, fmt.Errorf("speculation mode %q cannot use a separate draft model", cfg.Mode)
@@ -18,6 +18,8 @@ func TestResolve(t *testing.T) {
 		{"MTP rejects classic model", Config{Mode: ModeMTP, ClassicConfigured: true}, SourceNone, true},
 		{"MTP requires source", Config{Mode: ModeMTP, MTPAvailable: true}, SourceNone, true},
 		{"MTP requires library support", Config{Mode: ModeMTP, EmbeddedMTP: true}, SourceNone, true},
+		{"ngram selects prompt lookup", Config{Mode: ModeNGram, NGramNDraft: 8, EmbeddedMTP: true, MTPAvailable: true}, SourceNGram, false},
+		{"ngram rejects classic model", Config{Mode: ModeNGram, ClassicConfigured: true}, SourceNone, true},
 		{"unknown mode rejected", Config{Mode: "future"}, SourceNone, true},
 	}
 
@@ -41,4 +43,16 @@ func TestPlanRowsPerSequence(t *testing.T) {
 	if got := (Plan{Source: SourceClassic, NDraft: 5, Available: true}).RowsPerSequence(); got != 6 {
 		t.Errorf("classic rows = %d, want 6", got)
 	}
+	if got := (Plan{Source: SourceNGram, NDraft: 8, Available: true}).RowsPerSequence(); got != 9 {
+		t.Errorf("ngram rows = %d, want 9", got)
+	}
+}
+
+func TestPlanDrafter(t *testing.T) {
+	if (Plan{Source: SourceNGram, NDraft: 8, Available: true}).Drafter() {
+		t.Error("ngram Drafter() = true, want false")
+	}
+	if !(Plan{Source: SourceMTPEmbedded, NDraft: 3, Available: true}).Drafter() {
+		t.Error("MTP Drafter() = false, want true")
+	}
 }
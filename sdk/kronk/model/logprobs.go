@@ -114,6 +114,15 @@ type filterState struct {
 	rawProbs []float64
 }
 
+// samplingScratch holds the buffers speculative verification uses to build
+// the target distribution. Stored on draftCore, or on the batch engine when
+// n-gram drafting runs without a draft model.
+type samplingScratch struct {
+	targetProbs []float32   // Reusable buffer for target probability distribution
+	sortIndices []int       // Reusable buffer for applySamplerFilters top-K indices
+	filterBuf   filterState // Reusable buffers for applySamplerFilters heap/rawProbs
+}
+
 // applySamplerFilters zeroes out tokens that would be removed by the sampler
 // chain (top-k → top-p → min-p) and renormalizes, so the resulting distribution
 // matches what the draft sampler produces. This makes p_target comparable to
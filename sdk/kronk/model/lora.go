@@ -4,11 +4,131 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"os"
 	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/hybridgroup/yzma/pkg/llama"
 )
 
+// ErrAdapterNotFound indicates that no loaded adapter has the requested ID.
+var ErrAdapterNotFound = errors.New("adapter not found")
+
+// ErrAdapterExists indicates that a loaded adapter already uses the ID or
+// file of an adapter being loaded.
+var ErrAdapterExists = errors.New("adapter already loaded")
+
+// ErrAdapterConfigured indicates an attempt to unload an adapter that belongs
+// to the model configuration.
+var ErrAdapterConfigured = errors.New("adapter belongs to the model configuration")
+
+// maxAdapterIDLen bounds the ID requests use to select an adapter.
+const maxAdapterIDLen = 256
+
+// AdapterDetail describes a LoRA adapter loaded against a model.
+type AdapterDetail struct {
+	ID         string
+	Path       string
+	Scale      float32 // Scale applied to requests that do not select adapters.
+	Configured bool    // Loaded from the model configuration; cannot be unloaded.
+}
+
+// AdapterSelection selects a loaded adapter by ID and the scale a request
+// applies it at.
+type AdapterSelection struct {
+	ID    string  `json:"id"`
+	Scale float32 `json:"scale"`
+}
+
+// loraAdapter is a LoRA adapter loaded against the target model.
+type loraAdapter struct {
+	cfg        AdapterConfig
+	handle     llama.AdapterLora
+	configured bool // Loaded from the model configuration.
+	retired    bool // Unloaded; the handle is freed once no context applies it.
+}
+
+// adapterSet is a resolved selection of loaded adapters and their scales,
+// ordered by adapter path. The key identifies the selection for grouping
+// generation and matching IMC sessions and is empty when no adapter applies.
+type adapterSet struct {
+	key      string
+	adapters []*loraAdapter
+	scales   []float32
+}
+
+// newAdapterSet returns the set applying each adapter at the matching scale.
+// Adapters at a zero scale contribute nothing and are left out.
+func newAdapterSet(adapters []*loraAdapter, scales []float32) adapterSet {
+	var set adapterSet
+	for i, adapter := range adapters {
+		if scales[i] == 0 {
+			continue
+		}
+		set.adapters = append(set.adapters, adapter)
+		set.scales = append(set.scales, scales[i])
+	}
+
+	order := make([]int, len(set.adapters))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		return strings.Compare(set.adapters[a].cfg.Path, set.adapters[b].cfg.Path)
+	})
+
+	sorted := adapterSet{
+		adapters: make([]*loraAdapter, len(order)),
+		scales:   make([]float32, len(order)),
+	}
+	keys := make([]string, len(order))
+	for i, j := range order {
+		sorted.adapters[i] = set.adapters[j]
+		sorted.scales[i] = set.scales[j]
+		keys[i] = set.adapters[j].cfg.Path + "@" + strconv.FormatFloat(float64(set.scales[j]), 'g', -1, 32)
+	}
+	sorted.key = strings.Join(keys, ";")
+
+	return sorted
+}
+
+// contains reports whether the set applies adapter.
+func (s adapterSet) contains(adapter *loraAdapter) bool {
+	return slices.Contains(s.adapters, adapter)
+}
+
+// validateAdapter checks an adapter's ID, path, and scale.
+func validateAdapter(adapter AdapterConfig) error {
+	id := adapter.AdapterID()
+	if id == "" || id != strings.TrimSpace(id) || len(id) > maxAdapterIDLen {
+		return fmt.Errorf("id must be non-empty, at most %d bytes, and have no surrounding whitespace: %q", maxAdapterIDLen, id)
+	}
+	if !filepath.IsAbs(adapter.Path) {
+		return fmt.Errorf("path must be absolute: %q", adapter.Path)
+	}
+	if !strings.EqualFold(filepath.Ext(adapter.Path), ".gguf") {
+		return fmt.Errorf("path must have a .gguf extension: %q", adapter.Path)
+	}
+	if adapter.Scale < 0 || math.IsNaN(float64(adapter.Scale)) || math.IsInf(float64(adapter.Scale), 0) {
+		return fmt.Errorf("scale must be finite and >= 0, got %g", adapter.Scale)
+	}
+
+	adapterPath := filepath.Clean(adapter.Path)
+	fi, err := os.Stat(adapterPath)
+	if err != nil {
+		return fmt.Errorf("path %q: %w", adapterPath, err)
+	}
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("path is not a regular file: %q", adapterPath)
+	}
+
+	return nil
+}
+
 // loadAdapters loads the configured LoRA adapter handles against the target
 // model. No context owns the handles yet, so partial failures can release the
 // handles immediately.
@@ -17,8 +137,8 @@ func (m *Model) loadAdapters(ctx context.Context) error {
 		return nil
 	}
 
-	m.adapterHandles = make([]llama.AdapterLora, 0, len(m.cfg.Adapters))
-	m.adapterScales = make([]float32, 0, len(m.cfg.Adapters))
+	m.adapters = make([]*loraAdapter, 0, len(m.cfg.Adapters))
+	scales := make([]float32, 0, len(m.cfg.Adapters))
 
 	for i, adapter := range m.cfg.Adapters {
 		handle, err := llama.AdapterLoraInit(m.model, adapter.Path)
@@ -35,23 +155,52 @@ func (m *Model) loadAdapters(ctx context.Context) error {
 			)
 		}
 
-		m.adapterHandles = append(m.adapterHandles, handle)
-		m.adapterScales = append(m.adapterScales, adapter.Scale)
-		m.log(ctx, "load-adapter", "status", "loaded", "adapter", path.Base(adapter.Path), "scale", adapter.Scale)
+		adapter.Path = filepath.Clean(adapter.Path)
+		m.adapters = append(m.adapters, &loraAdapter{cfg: adapter, handle: handle, configured: true})
+		scales = append(scales, adapter.Scale)
+		m.log(ctx, "load-adapter", "status", "loaded", "adapter", path.Base(adapter.Path), "id", adapter.AdapterID(), "scale", adapter.Scale)
 	}
 
+	m.defaultAdapters = newAdapterSet(m.adapters, scales)
+
 	return nil
 }
 
-// applyAdapters registers the loaded adapter handles and their fixed scales on
-// a target-model context. It is a no-op when no adapters are configured.
+// applyAdapters registers the configured adapters at their configured scales
+// on a target-model context. It is a no-op when no configured adapter
+// applies.
 func (m *Model) applyAdapters(lctx llama.Context) error {
-	if len(m.adapterHandles) == 0 {
+	if len(m.defaultAdapters.adapters) == 0 {
 		return nil
 	}
 
-	if rc := llama.SetAdaptersLora(lctx, m.adapterHandles, m.adapterScales); rc != 0 {
-		return fmt.Errorf("apply-adapters: set adapters failed: rc=%d", rc)
+	if err := setContextAdapters(lctx, m.defaultAdapters, nil); err != nil {
+		return fmt.Errorf("apply-adapters: %w", err)
+	}
+
+	return nil
+}
+
+// setContextAdapters registers the adapters of set on lctx. The bindings
+// reject an empty list, so an empty set registers fallback at a zero scale,
+// which contributes nothing to the output.
+func setContextAdapters(lctx llama.Context, set adapterSet, fallback *loraAdapter) error {
+	handles := make([]llama.AdapterLora, len(set.adapters))
+	for i, adapter := range set.adapters {
+		handles[i] = adapter.handle
+	}
+	scales := set.scales
+
+	if len(handles) == 0 {
+		if fallback == nil {
+			return errors.New("set adapters failed: no adapter to register")
+		}
+		handles = []llama.AdapterLora{fallback.handle}
+		scales = []float32{0}
+	}
+
+	if rc := llama.SetAdaptersLora(lctx, handles, scales); rc != 0 {
+		return fmt.Errorf("set adapters failed: rc=%d", rc)
 	}
 
 	return nil
@@ -100,19 +249,217 @@ func (m *Model) applyAdaptersToDraft(draft drafter) error {
 	return nil
 }
 
-// freeAdapters releases every loaded adapter handle. Callers must first free
-// every context on which the handles were registered and must call this before
-// freeing the target model.
+// Adapters returns the LoRA adapters loaded against the model in load order.
+func (m *Model) Adapters() []AdapterDetail {
+	m.adapterMu.Lock()
+	defer m.adapterMu.Unlock()
+
+	details := make([]AdapterDetail, len(m.adapters))
+	for i, adapter := range m.adapters {
+		details[i] = AdapterDetail{
+			ID:         adapter.cfg.AdapterID(),
+			Path:       adapter.cfg.Path,
+			Scale:      adapter.cfg.Scale,
+			Configured: adapter.configured,
+		}
+	}
+
+	return details
+}
+
+// LoadAdapter loads a LoRA adapter against the running model so requests can
+// select it by ID. Unlike configured adapters, it is never applied to requests
+// that do not select it, and it only lives as long as the loaded model. Only
+// text generation models accept adapters at runtime.
+func (m *Model) LoadAdapter(ctx context.Context, adapter AdapterConfig) (AdapterDetail, error) {
+	if m.batch == nil {
+		return AdapterDetail{}, fmt.Errorf("load-adapter: %w: adapters can only be loaded on a text generation model", ErrInvalidRequest)
+	}
+	if err := validateAdapter(adapter); err != nil {
+		return AdapterDetail{}, fmt.Errorf("load-adapter: %w: %w", ErrInvalidRequest, err)
+	}
+
+	adapter.Path = filepath.Clean(adapter.Path)
+	adapter.Scale = 0
+	id := adapter.AdapterID()
+
+	m.adapterMu.Lock()
+	defer m.adapterMu.Unlock()
+
+	for _, loaded := range m.adapters {
+		if loaded.cfg.AdapterID() == id || loaded.cfg.Path == adapter.Path {
+			return AdapterDetail{}, fmt.Errorf("load-adapter: %w: id %q path %q", ErrAdapterExists, id, adapter.Path)
+		}
+	}
+
+	handle, err := llama.AdapterLoraInit(m.model, adapter.Path)
+	if err != nil {
+		return AdapterDetail{}, fmt.Errorf("load-adapter: %q: %w", adapter.Path, err)
+	}
+	if handle == 0 {
+		return AdapterDetail{}, fmt.Errorf("load-adapter: %q returned an invalid handle", adapter.Path)
+	}
+
+	m.adapters = append(m.adapters, &loraAdapter{cfg: adapter, handle: handle})
+	m.log(ctx, "load-adapter", "status", "loaded", "adapter", path.Base(adapter.Path), "id", id)
+
+	return AdapterDetail{ID: id, Path: adapter.Path}, nil
+}
+
+// UnloadAdapter unloads an adapter loaded with LoadAdapter. New requests can
+// no longer select it and queued requests that selected it fail; requests
+// already generating with it finish first, and the batch engine frees the
+// adapter once no context applies it.
+func (m *Model) UnloadAdapter(ctx context.Context, id string) error {
+	m.adapterMu.Lock()
+
+	i := slices.IndexFunc(m.adapters, func(adapter *loraAdapter) bool {
+		return adapter.cfg.AdapterID() == id
+	})
+	if i < 0 {
+		m.adapterMu.Unlock()
+		return fmt.Errorf("unload-adapter: %w: %q", ErrAdapterNotFound, id)
+	}
+
+	adapter := m.adapters[i]
+	if adapter.configured {
+		m.adapterMu.Unlock()
+		return fmt.Errorf("unload-adapter: %w: %q", ErrAdapterConfigured, id)
+	}
+
+	m.adapters = slices.Delete(m.adapters, i, i+1)
+	adapter.retired = true
+	m.retiredAdapters = append(m.retiredAdapters, adapter)
+	m.adapterMu.Unlock()
+
+	m.log(ctx, "unload-adapter", "status", "retired", "adapter", path.Base(adapter.cfg.Path), "id", id)
+	if m.batch != nil {
+		m.batch.wake()
+	}
+
+	return nil
+}
+
+// resolveAdapters returns the adapter set for a request's selections. Nil
+// selections resolve to the configured adapters.
+func (m *Model) resolveAdapters(selections []AdapterSelection) (adapterSet, error) {
+	if selections == nil {
+		return m.defaultAdapters, nil
+	}
+
+	m.adapterMu.Lock()
+	defer m.adapterMu.Unlock()
+
+	adapters := make([]*loraAdapter, len(selections))
+	scales := make([]float32, len(selections))
+	for i, selection := range selections {
+		j := slices.IndexFunc(m.adapters, func(adapter *loraAdapter) bool {
+			return adapter.cfg.AdapterID() == selection.ID
+		})
+		if j < 0 {
+			return adapterSet{}, fmt.Errorf("%w: %w: %q", ErrInvalidRequest, ErrAdapterNotFound, selection.ID)
+		}
+		if slices.Contains(adapters[:i], m.adapters[j]) {
+			return adapterSet{}, fmt.Errorf("%w: adapter %q is selected more than once", ErrInvalidRequest, selection.ID)
+		}
+
+		adapters[i] = m.adapters[j]
+		scales[i] = selection.Scale
+	}
+
+	return newAdapterSet(adapters, scales), nil
+}
+
+// checkAdapters returns an error when an adapter in set was unloaded after
+// the request selected it.
+func (m *Model) checkAdapters(set adapterSet) error {
+	m.adapterMu.Lock()
+	defer m.adapterMu.Unlock()
+
+	for _, adapter := range set.adapters {
+		if adapter.retired {
+			return fmt.Errorf("%w: %w: %q was unloaded", ErrInvalidRequest, ErrAdapterNotFound, adapter.cfg.AdapterID())
+		}
+	}
+
+	return nil
+}
+
+// freeAdapters releases every loaded and retired adapter handle. Callers must
+// first free every context on which the handles were registered and must call
+// this before freeing the target model.
 func (m *Model) freeAdapters() error {
-	errs := make([]error, 0, len(m.adapterHandles))
-	for i, handle := range m.adapterHandles {
-		if err := llama.AdapterLoraFree(handle); err != nil {
+	m.adapterMu.Lock()
+	defer m.adapterMu.Unlock()
+
+	var errs []error
+	for i, adapter := range slices.Concat(m.adapters, m.retiredAdapters) {
+		if err := llama.AdapterLoraFree(adapter.handle); err != nil {
 			errs = append(errs, fmt.Errorf("free-adapters: adapter[%d]: %w", i, err))
 		}
 	}
 
-	m.adapterHandles = nil
-	m.adapterScales = nil
+	m.adapters = nil
+	m.retiredAdapters = nil
+	m.defaultAdapters = adapterSet{}
 
 	return errors.Join(errs...)
 }
+
+// parseAdapterSelections parses a request's adapters field: a list of objects
+// with an id and an optional scale, which defaults to 1.
+func parseAdapterSelections(val any) ([]AdapterSelection, error) {
+	var items []any
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case []AdapterSelection:
+		items = make([]any, len(v))
+		for i, selection := range v {
+			items[i] = D{"id": selection.ID, "scale": selection.Scale}
+		}
+	case []D:
+		items = make([]any, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+	case []any:
+		items = v
+	default:
+		return nil, fmt.Errorf("%w: adapters must be an array of objects", ErrInvalidRequest)
+	}
+
+	selections := make([]AdapterSelection, len(items))
+	for i, item := range items {
+		var entry D
+		switch v := item.(type) {
+		case D:
+			entry = v
+		case map[string]any:
+			entry = D(v)
+		default:
+			return nil, fmt.Errorf("%w: adapters[%d] must be an object", ErrInvalidRequest, i)
+		}
+
+		id, ok := entry["id"].(string)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("%w: adapters[%d].id must be a non-empty string", ErrInvalidRequest, i)
+		}
+
+		scale := float32(1)
+		if val, exists := entry["scale"]; exists {
+			v, err := parseFloat32(fmt.Sprintf("adapters[%d].scale", i), val)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+			}
+			scale = v
+		}
+		if scale < 0 || math.IsNaN(float64(scale)) || math.IsInf(float64(scale), 0) {
+			return nil, fmt.Errorf("%w: adapters[%d].scale must be finite and >= 0, got %g", ErrInvalidRequest, i, scale)
+		}
+
+		selections[i] = AdapterSelection{ID: id, Scale: scale}
+	}
+
+	return selections, nil
+}
@@ -3,8 +3,10 @@ package model
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 
+	"github.com/ardanlabs/kronk/sdk/kronk/applog"
 	"github.com/hybridgroup/yzma/pkg/llama"
 )
 
@@ -39,6 +41,177 @@ func TestAdapterDraftContext(t *testing.T) {
 	}
 }
 
+func TestNewAdapterSet(t *testing.T) {
+	sql := &loraAdapter{cfg: AdapterConfig{Path: "/lora/sql.gguf"}}
+	legal := &loraAdapter{cfg: AdapterConfig{Path: "/lora/legal.gguf"}}
+	chat := &loraAdapter{cfg: AdapterConfig{Path: "/lora/chat.gguf"}}
+
+	set := newAdapterSet([]*loraAdapter{sql, legal, chat}, []float32{0.8, 1, 0})
+
+	if want := "/lora/legal.gguf@1;/lora/sql.gguf@0.8"; set.key != want {
+		t.Errorf("key = %q, want %q", set.key, want)
+	}
+	if !slices.Equal(set.adapters, []*loraAdapter{legal, sql}) {
+		t.Errorf("adapters are not ordered by path without the zero scale adapter")
+	}
+	if !slices.Equal(set.scales, []float32{1, 0.8}) {
+		t.Errorf("scales = %v, want [1 0.8]", set.scales)
+	}
+	if set.contains(chat) {
+		t.Errorf("contains(chat) = true, want false for a zero scale adapter")
+	}
+
+	reordered := newAdapterSet([]*loraAdapter{legal, sql}, []float32{1, 0.8})
+	if reordered.key != set.key {
+		t.Errorf("key = %q, want %q regardless of selection order", reordered.key, set.key)
+	}
+
+	if empty := newAdapterSet([]*loraAdapter{chat}, []float32{0}); empty.key != "" || len(empty.adapters) != 0 {
+		t.Errorf("zero scale set = %+v, want empty", empty)
+	}
+}
+
+func TestResolveAdapters(t *testing.T) {
+	sql := &loraAdapter{cfg: AdapterConfig{ID: "sql", Path: "/lora/sql.gguf", Scale: 0.5}, configured: true}
+	legal := &loraAdapter{cfg: AdapterConfig{Path: "/lora/legal.gguf"}}
+	m := Model{
+		adapters:        []*loraAdapter{sql, legal},
+		defaultAdapters: newAdapterSet([]*loraAdapter{sql}, []float32{0.5}),
+	}
+
+	tests := []struct {
+		name       string
+		selections []AdapterSelection
+		wantKey    string
+		wantErr    error
+	}{
+		{name: "nil selects configured adapters", selections: nil, wantKey: "/lora/sql.gguf@0.5"},
+		{name: "empty selects base model", selections: []AdapterSelection{}, wantKey: ""},
+		{name: "file stem selects adapter without id", selections: []AdapterSelection{{ID: "legal", Scale: 1}}, wantKey: "/lora/legal.gguf@1"},
+		{name: "scales are per request", selections: []AdapterSelection{{ID: "sql", Scale: 0.8}, {ID: "legal", Scale: 0.2}}, wantKey: "/lora/legal.gguf@0.2;/lora/sql.gguf@0.8"},
+		{name: "unknown id", selections: []AdapterSelection{{ID: "missing", Scale: 1}}, wantErr: ErrAdapterNotFound},
+		{name: "duplicate id", selections: []AdapterSelection{{ID: "sql", Scale: 1}, {ID: "sql", Scale: 0.5}}, wantErr: ErrInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := m.resolveAdapters(tt.selections)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || !errors.Is(err, ErrInvalidRequest) {
+					t.Fatalf("resolveAdapters() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveAdapters() error = %v", err)
+			}
+			if set.key != tt.wantKey {
+				t.Errorf("key = %q, want %q", set.key, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestUnloadAdapter(t *testing.T) {
+	sql := &loraAdapter{cfg: AdapterConfig{Path: "/lora/sql.gguf", Scale: 1}, configured: true}
+	legal := &loraAdapter{cfg: AdapterConfig{Path: "/lora/legal.gguf"}}
+	m := Model{log: applog.DiscardLogger, adapters: []*loraAdapter{sql, legal}}
+	ctx := context.Background()
+
+	selected, err := m.resolveAdapters([]AdapterSelection{{ID: "legal", Scale: 1}})
+	if err != nil {
+		t.Fatalf("resolveAdapters() error = %v", err)
+	}
+
+	if err := m.UnloadAdapter(ctx, "missing"); !errors.Is(err, ErrAdapterNotFound) {
+		t.Errorf("UnloadAdapter(missing) error = %v, want ErrAdapterNotFound", err)
+	}
+	if err := m.UnloadAdapter(ctx, "sql"); !errors.Is(err, ErrAdapterConfigured) {
+		t.Errorf("UnloadAdapter(sql) error = %v, want ErrAdapterConfigured", err)
+	}
+	if err := m.UnloadAdapter(ctx, "legal"); err != nil {
+		t.Fatalf("UnloadAdapter(legal) error = %v", err)
+	}
+
+	if !slices.Equal(m.adapters, []*loraAdapter{sql}) {
+		t.Errorf("adapters still hold the unloaded adapter")
+	}
+	if !slices.Equal(m.retiredAdapters, []*loraAdapter{legal}) || !legal.retired {
+		t.Errorf("unloaded adapter was not retired")
+	}
+	if err := m.checkAdapters(selected); !errors.Is(err, ErrAdapterNotFound) {
+		t.Errorf("checkAdapters() error = %v, want ErrAdapterNotFound for a queued request", err)
+	}
+	if _, err := m.resolveAdapters([]AdapterSelection{{ID: "legal", Scale: 1}}); !errors.Is(err, ErrAdapterNotFound) {
+		t.Errorf("resolveAdapters() error = %v, want ErrAdapterNotFound after unload", err)
+	}
+}
+
+func TestAdmitAdapters(t *testing.T) {
+	sql := &loraAdapter{cfg: AdapterConfig{Path: "/lora/sql.gguf"}}
+	legal := &loraAdapter{cfg: AdapterConfig{Path: "/lora/legal.gguf"}, retired: true}
+	sqlSet := newAdapterSet([]*loraAdapter{sql}, []float32{1})
+
+	e := batchEngine{
+		model:    &Model{},
+		slots:    []*slot{{active: true}, {}},
+		adapters: sqlSet,
+	}
+
+	admit, err := e.admitAdapters(&chatJob{adapters: sqlSet})
+	if err != nil || !admit {
+		t.Errorf("admitAdapters(current set) = %t, %v, want true", admit, err)
+	}
+
+	admit, err = e.admitAdapters(&chatJob{})
+	if err != nil || admit {
+		t.Errorf("admitAdapters(other set) = %t, %v, want false while a slot is active", admit, err)
+	}
+
+	_, err = e.admitAdapters(&chatJob{adapters: newAdapterSet([]*loraAdapter{legal}, []float32{1})})
+	if !errors.Is(err, ErrAdapterNotFound) {
+		t.Errorf("admitAdapters(unloaded adapter) error = %v, want ErrAdapterNotFound", err)
+	}
+}
+
+func TestParseAdapterSelections(t *testing.T) {
+	tests := []struct {
+		name    string
+		val     any
+		want    []AdapterSelection
+		wantErr bool
+	}{
+		{name: "absent", val: nil, want: nil},
+		{name: "empty", val: []any{}, want: []AdapterSelection{}},
+		{name: "json objects", val: []any{map[string]any{"id": "sql", "scale": 0.5}, map[string]any{"id": "legal"}}, want: []AdapterSelection{{ID: "sql", Scale: 0.5}, {ID: "legal", Scale: 1}}},
+		{name: "documents", val: []D{{"id": "sql", "scale": "0.25"}}, want: []AdapterSelection{{ID: "sql", Scale: 0.25}}},
+		{name: "typed selections", val: []AdapterSelection{{ID: "sql", Scale: 0}}, want: []AdapterSelection{{ID: "sql", Scale: 0}}},
+		{name: "not an array", val: "sql", wantErr: true},
+		{name: "entry not an object", val: []any{"sql"}, wantErr: true},
+		{name: "missing id", val: []any{map[string]any{"scale": 1.0}}, wantErr: true},
+		{name: "negative scale", val: []any{map[string]any{"id": "sql", "scale": -1.0}}, wantErr: true},
+		{name: "invalid scale", val: []any{map[string]any{"id": "sql", "scale": "high"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAdapterSelections(tt.val)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRequest) {
+					t.Fatalf("parseAdapterSelections() error = %v, want ErrInvalidRequest", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAdapterSelections() error = %v", err)
+			}
+			if (got == nil) != (tt.want == nil) || !slices.Equal(got, tt.want) {
+				t.Errorf("parseAdapterSelections() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCleanupGenerationRuntimeClosesStores(t *testing.T) {
 	cause := errors.New("initialization failed")
 	closeErr := errors.New("close failed")
@@ -84,3 +257,43 @@ func (s *testCloseStore) Close() error {
 	s.closes++
 	return s.err
 }
+
+func TestNextForAdapterSwitch(t *testing.T) {
+	sql := &loraAdapter{cfg: AdapterConfig{Path: "/lora/sql.gguf"}}
+	legal := &loraAdapter{cfg: AdapterConfig{Path: "/lora/legal.gguf"}}
+	sqlSet := newAdapterSet([]*loraAdapter{sql}, []float32{1})
+	legalSet := newAdapterSet([]*loraAdapter{legal}, []float32{1})
+
+	waiting := &chatJob{adapters: legalSet}
+	plain := &chatJob{}
+	matching := &chatJob{adapters: sqlSet}
+
+	e := batchEngine{
+		model:       &Model{},
+		nSlots:      2,
+		slots:       []*slot{{active: true}, {}},
+		adapters:    sqlSet,
+		fair:        newFairQueue(),
+		pendingJobs: []*chatJob{waiting, plain, matching},
+	}
+
+	if admit, err := e.admitAdapters(waiting); err != nil || admit {
+		t.Fatalf("admitAdapters(waiting) = %t, %v, want false while a slot is active", admit, err)
+	}
+
+	if got := e.nextForAdapterSwitch(waiting); got != 2 {
+		t.Fatalf("nextForAdapterSwitch() = %d, want 2 for the job using the current set", got)
+	}
+
+	e.adapterSwitchAdmitted = e.nSlots
+	if got := e.nextForAdapterSwitch(waiting); got != -1 {
+		t.Errorf("nextForAdapterSwitch() = %d, want -1 once one job per slot was admitted", got)
+	}
+
+	// Without adapters applied, jobs that select none match the current set.
+	e.adapters = adapterSet{}
+	e.adapterSwitch = nil
+	if got := e.nextForAdapterSwitch(waiting); got != 1 {
+		t.Errorf("nextForAdapterSwitch() = %d, want 1 for the job without adapters", got)
+	}
+}
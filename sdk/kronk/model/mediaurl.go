@@ -0,0 +1,120 @@
+package model
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MediaFetcher downloads the media a content part references by URL.
+type MediaFetcher func(ctx context.Context, url string) ([]byte, error)
+
+// ResolveMediaURLs downloads the http and https URLs in the image_url,
+// video_url, input_audio, and input_image parts of the messages and Responses
+// input of d, and replaces each URL with the downloaded media as base64 data
+// so the media pipeline can consume it. Downloaded media must match a
+// supported image or audio format. Parts are replaced copy-on-write so the
+// documents in d that hold them are not modified. When fetch is nil, URLs are
+// left in place and the media pipeline rejects them.
+func ResolveMediaURLs(ctx context.Context, d D, fetch MediaFetcher) error {
+	if fetch == nil {
+		return nil
+	}
+
+	for _, key := range []string{"messages", "input"} {
+		items, ok := d[key].([]D)
+		if !ok {
+			continue
+		}
+
+		resolved, err := replaceContentParts(items, func(part D) (D, bool, error) {
+			return resolveMediaURLPart(ctx, part, fetch)
+		})
+		if err != nil {
+			return fmt.Errorf("resolve-media-urls: %s%w", key, err)
+		}
+
+		if resolved != nil {
+			d[key] = resolved
+		}
+	}
+
+	return nil
+}
+
+// resolveMediaURLPart returns the replacement for a part that references
+// media by URL and reports whether the part was replaced.
+func resolveMediaURLPart(ctx context.Context, part D, fetch MediaFetcher) (D, bool, error) {
+	typ, _ := part["type"].(string)
+
+	var field, key string
+	var want MediaType
+	switch typ {
+	case "image_url", "input_image":
+		field, key, want = "image_url", "url", MediaTypeVision
+	case "video_url":
+		field, key, want = "video_url", "url", MediaTypeNone
+	case "input_audio":
+		field, key, want = "input_audio", "data", MediaTypeAudio
+	default:
+		return nil, false, nil
+	}
+
+	// The Responses input_image part holds the URL as a string rather than
+	// an object.
+	obj, isObject := mapFromPart(part[field])
+	var url string
+	switch {
+	case isObject:
+		url, _ = obj[key].(string)
+	default:
+		url, _ = part[field].(string)
+	}
+
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil, false, nil
+	}
+
+	data, err := fetch(ctx, url)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %s %q: %w", ErrInvalidRequest, typ, url, err)
+	}
+
+	mt := mediaTypeFromMagicBytes(data)
+	if mt == MediaTypeNone || (want != MediaTypeNone && mt != want) {
+		return nil, false, fmt.Errorf("%w: %s %q: content is not a supported %s format", ErrInvalidRequest, typ, url, mediaKind(typ))
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+	if key == "url" {
+		encoded = fmt.Sprintf("data:%s;base64,%s", http.DetectContentType(data), encoded)
+	}
+
+	replaced := part.ShallowClone()
+	switch {
+	case isObject:
+		value := make(D, len(obj))
+		for k, v := range obj {
+			value[k] = v
+		}
+		value[key] = encoded
+		replaced[field] = value
+	default:
+		replaced[field] = encoded
+	}
+
+	return replaced, true, nil
+}
+
+func mediaKind(typ string) string {
+	switch typ {
+	case "input_audio":
+		return "audio"
+	case "video_url":
+		return "media"
+	}
+
+	return "image"
+}
@@ -0,0 +1,119 @@
+package model
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResolveMediaURLs(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	wav := []byte("RIFF0000WAVEfmt ")
+
+	media := map[string][]byte{
+		"https://example.com/chart.png": png,
+		"https://example.com/clip.wav":  wav,
+	}
+	fetch := func(ctx context.Context, url string) ([]byte, error) {
+		data, exists := media[url]
+		if !exists {
+			return nil, fmt.Errorf("status 404")
+		}
+		return data, nil
+	}
+
+	inline := "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+
+	messages := []D{
+		{"role": "user", "content": []D{
+			{"type": "text", "text": "Describe these."},
+			{"type": "image_url", "image_url": D{"url": "https://example.com/chart.png", "detail": "high"}},
+			{"type": "image_url", "image_url": D{"url": inline}},
+			{"type": "input_audio", "input_audio": D{"data": "https://example.com/clip.wav", "format": "wav"}},
+		}},
+	}
+	d := D{
+		"messages": messages,
+		"input": []D{
+			{"role": "user", "content": []D{
+				{"type": "input_image", "image_url": "https://example.com/chart.png"},
+			}},
+		},
+	}
+
+	if err := ResolveMediaURLs(t.Context(), d, fetch); err != nil {
+		t.Fatalf("ResolveMediaURLs: %v", err)
+	}
+
+	wantMessages := []D{
+		{"role": "user", "content": []D{
+			{"type": "text", "text": "Describe these."},
+			{"type": "image_url", "image_url": D{"url": inline, "detail": "high"}},
+			{"type": "image_url", "image_url": D{"url": inline}},
+			{"type": "input_audio", "input_audio": D{"data": base64.StdEncoding.EncodeToString(wav), "format": "wav"}},
+		}},
+	}
+	if diff := cmp.Diff(wantMessages, d["messages"]); diff != "" {
+		t.Errorf("messages mismatch (-want +got):\n%s", diff)
+	}
+
+	wantInput := []D{
+		{"role": "user", "content": []D{
+			{"type": "input_image", "image_url": inline},
+		}},
+	}
+	if diff := cmp.Diff(wantInput, d["input"]); diff != "" {
+		t.Errorf("input mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := messages[0]["content"].([]D)[1]["image_url"].(D)["url"]; got != "https://example.com/chart.png" {
+		t.Errorf("original messages: got url %v, want the URL left in place", got)
+	}
+}
+
+func TestResolveMediaURLsRejects(t *testing.T) {
+	fetch := func(ctx context.Context, url string) ([]byte, error) {
+		switch url {
+		case "https://example.com/clip.wav":
+			return []byte("RIFF0000WAVEfmt "), nil
+		case "https://example.com/page.html":
+			return []byte("<html></html>"), nil
+		}
+		return nil, fmt.Errorf("status 404")
+	}
+
+	tests := []struct {
+		name string
+		part D
+	}{
+		{name: "fetch fails", part: D{"type": "image_url", "image_url": D{"url": "https://example.com/missing.png"}}},
+		{name: "not media", part: D{"type": "image_url", "image_url": D{"url": "https://example.com/page.html"}}},
+		{name: "audio as image", part: D{"type": "image_url", "image_url": D{"url": "https://example.com/clip.wav"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := D{"messages": []D{{"role": "user", "content": []D{tt.part}}}}
+
+			err := ResolveMediaURLs(t.Context(), d, fetch)
+			if !errors.Is(err, ErrInvalidRequest) {
+				t.Fatalf("ResolveMediaURLs: got %v, want ErrInvalidRequest", err)
+			}
+		})
+	}
+
+	part := D{"type": "image_url", "image_url": D{"url": "https://example.com/chart.png"}}
+	d := D{"messages": []D{{"role": "user", "content": []D{part}}}}
+
+	if err := ResolveMediaURLs(t.Context(), d, nil); err != nil {
+		t.Fatalf("ResolveMediaURLs without a fetcher: %v", err)
+	}
+
+	if _, err := decodeMediaData("https://example.com/chart.png"); err == nil {
+		t.Error("decodeMediaData: want unresolved URLs rejected")
+	}
+}
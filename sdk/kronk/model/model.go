@@ -74,6 +74,8 @@ const imcSeqIDUnbound llama.SeqId = -1
 // request is in flight, and imcSeqIDUnbound otherwise.
 type imcSession struct {
 	id                  int             // Stable session-pool index. Used by imcReleaseReservation lookup and for log correlation; not related to execution slot identity.
+	cacheKey            string          // Client-supplied prompt_cache_key pinning requests to this session; empty for sessions matched by prefix alone.
+	adapterKey          string          // Adapter set the cached KV was built with; only requests running the same set match the session.
 	seqID               llama.SeqId     // KV sequence id the session is currently bound to, or imcSeqIDUnbound when externalized to RAM only.
 	cachedMsgsHash      string          // Hash of all cached messages
 	cachedTokens        []llama.Token   // Full token sequence in KV cache (immutable; replaced, never mutated)
@@ -170,9 +172,7 @@ type draftCore struct {
 
 	// Pre-allocated buffers for speculative sampling to avoid per-round
 	// allocations of vocab-sized slices (~600KB each for 152k vocab).
-	targetProbs []float32   // Reusable buffer for target probability distribution
-	sortIndices []int       // Reusable buffer for applySamplerFilters top-K indices
-	filterBuf   filterState // Reusable buffers for applySamplerFilters heap/rawProbs
+	samplingScratch
 
 	// registeredSampler tracks the sampler currently registered on the draft
 	// context via SetSampler for backend (GPU-side) sampling. This avoids
@@ -183,22 +183,24 @@ type draftCore struct {
 
 // Model represents a model and provides a low-level API for working with it.
 type Model struct {
-	cfg            Config
-	log            applog.Logger
-	model          llama.Model
-	vocab          llama.Vocab
-	suppressTokens []llama.Token
-	ctxParams      llama.ContextParams
-	lctx           llama.Context
-	mem            llama.Memory
-	adapterHandles []llama.AdapterLora
-	adapterScales  []float32
-	batch          *batchEngine
-	template       Template
-	compiledTmpl   *compiledTemplate // Long-lived compiled jinja template (one-time init via templateOnce).
-	templateOnce   sync.Once         // Guards one-time compile of compiledTmpl.
-	projFile       string
-	projDevice     llama.GGMLBackendDevice
+	cfg             Config
+	log             applog.Logger
+	model           llama.Model
+	vocab           llama.Vocab
+	suppressTokens  []llama.Token
+	ctxParams       llama.ContextParams
+	lctx            llama.Context
+	mem             llama.Memory
+	adapterMu       sync.Mutex     // Guards adapters, retiredAdapters, and loraAdapter.retired.
+	adapters        []*loraAdapter // LoRA adapters requests can select, in load order.
+	retiredAdapters []*loraAdapter // Unloaded adapters the batch engine has not freed yet.
+	defaultAdapters adapterSet     // Configured adapters applied to requests that select none.
+	batch           *batchEngine
+	template        Template
+	compiledTmpl    *compiledTemplate // Long-lived compiled jinja template (one-time init via templateOnce).
+	templateOnce    sync.Once         // Guards one-time compile of compiledTmpl.
+	projFile        string
+	projDevice      llama.GGMLBackendDevice
 	// mtmdMetaCtx is a single, long-lived multimodal projector context
 	// loaded in NewModel and freed in Unload. It is used ONLY for
 	// read-only metadata checks (SupportVision/SupportAudio) by chat
@@ -223,6 +225,7 @@ type Model struct {
 	batchSeq        *batchSeqEngine   // Sequence-batch engine for supported embed/rerank models.
 	parser          Parser            // Selected via selectParser at load time; nil for embed/rerank.
 	draft           drafter           // Speculative-decoding strategy (nil, classic, or MTP); see draft.go
+	contextShift    bool              // Context shift is configured and the target KV memory supports position shifts.
 }
 
 // NewModel loads a model from the GGUF files specified in cfg and returns
@@ -747,6 +750,16 @@ func initGenerationRuntime(ctx context.Context, m *Model, nSlots int, plan specu
 	m.mem = mem
 	m.ctxParams.NRsSeq = llama.NRsSeq(lctx)
 
+	// Recurrent and hybrid memory cannot move cached positions, so context
+	// shifting stays off for those models even when configured.
+	if m.cfg.ContextShift() {
+		canShift, err := llama.MemoryCanShift(mem)
+		m.contextShift = err == nil && canShift
+		if !m.contextShift {
+			m.log(ctx, "init-generation-runtime", "status", "context-shift-unsupported", "err", err)
+		}
+	}
+
 	// Initialize the IMC session pool. The default retains at least three
 	// sessions per execution slot, while an explicit IMCSessionCapacity lets
 	// operators tune the warm conversation working set independently. Config
@@ -819,7 +832,7 @@ func initGenerationRuntime(ctx context.Context, m *Model, nSlots int, plan specu
 		return m.cleanupGenerationRuntime(ctx, fmt.Errorf("load-draft-model: %w", err))
 	}
 	m.draft = draft
-	if plan.Active() && m.draft == nil {
+	if plan.Drafter() && m.draft == nil {
 		return m.cleanupGenerationRuntime(ctx, fmt.Errorf("load-draft-model: selected speculation implementation did not create a drafter"))
 	}
 	if err := m.applyAdaptersToDraft(m.draft); err != nil {
@@ -1046,7 +1059,9 @@ func loadDraftModel(ctx context.Context, log applog.Logger, cfg Config, targetMo
 		prefillBatch:   prefillBatch,
 		nDraft:         dCfg.NDraft,
 		draftBuf:       make([]llama.Token, 0, dCfg.NDraft),
-		targetProbs:    make([]float32, nVocab),
+		samplingScratch: samplingScratch{
+			targetProbs: make([]float32, nVocab),
+		},
 	}}, nil
 }
 
@@ -1397,7 +1412,7 @@ func (m *Model) sendToolCallDeltaResponse(ctx context.Context, ch chan<- ChatRes
 	return nil
 }
 
-func (m *Model) sendFinalResponse(ctx context.Context, ch chan<- ChatResponse, id string, object string, choiceIndex int, finalContent *strings.Builder, finalReasoning *strings.Builder, respToolCalls []ResponseToolCall, terminalToolCallDeltas []ResponseToolCallDelta, logprobsData []ContentLogprob, finishReason string, stopSource string, finalChannel Channel, bufferedToolBytes int, streaming bool, includeUsage bool, usage Usage) error {
+func (m *Model) sendFinalResponse(ctx context.Context, ch chan<- ChatResponse, id string, object string, choiceIndex int, finalContent *strings.Builder, finalReasoning *strings.Builder, respToolCalls []ResponseToolCall, terminalToolCallDeltas []ResponseToolCallDelta, logprobsData []ContentLogprob, finishReason string, stopSource string, stopSequence string, finalChannel Channel, bufferedToolBytes int, streaming bool, includeUsage bool, usage Usage) error {
 	effectiveFinishReason := finishReason
 	if effectiveFinishReason == "" {
 		effectiveFinishReason = FinishReasonStop
@@ -1425,11 +1440,11 @@ func (m *Model) sendFinalResponse(ctx context.Context, ch chan<- ChatResponse, i
 		"tool_calls", len(respToolCalls),
 		"buffered_tool_bytes", bufferedToolBytes,
 	}
-	// When a draft model is configured, always emit draft metrics so the
+	// When speculation is configured, always emit draft metrics so the
 	// log schema stays stable for scrapers/dashboards even when
 	// speculation was disabled mid-request (collapsed acceptance EMA).
-	// Models without a draft model omit the fields entirely.
-	if m.draft != nil {
+	// Models without speculation omit the fields entirely.
+	if m.cfg.speculationPlan.Active() {
 		args = append(args, "draft_tokens", usage.DraftTokens, "draft_accepted_tokens", usage.DraftAcceptedTokens, "draft_acceptance_rate", fmt.Sprintf("%.2f", usage.DraftAcceptanceRate), "draft_coverage", fmt.Sprintf("%.2f", usage.DraftCoverage))
 		if usage.DraftDisableReason != "" {
 			args = append(args, "draft_disable_reason", usage.DraftDisableReason)
@@ -1468,6 +1483,7 @@ func (m *Model) sendFinalResponse(ctx context.Context, ch chan<- ChatResponse, i
 		finishReason,
 		includeUsage && !streaming,
 		usage)
+	finalResp.Choices[0].StopSequence = stopSequence
 
 	select {
 	case <-ctx.Done():
@@ -2,11 +2,13 @@ package model
 
 import (
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"maps"
+	"math"
 	"path"
 	"path/filepath"
 	"reflect"
@@ -425,6 +427,7 @@ var logSafeKeys = []string{
 	"dry_allowed_length",
 	"dry_base",
 	"dry_multiplier",
+	"encoding_format",
 	"frequency_penalty",
 	"logprobs",
 	"max_completion_tokens",
@@ -435,6 +438,7 @@ var logSafeKeys = []string{
 	"n",
 	"parallel_tool_calls",
 	"presence_penalty",
+	"previous_response_id",
 	"repeat_last_n",
 	"repeat_penalty",
 	"return_documents",
@@ -890,6 +894,14 @@ type Choice struct {
 	Delta           *ResponseMessage `json:"delta,omitempty"`
 	Logprobs        *Logprobs        `json:"logprobs,omitempty"`
 	FinishReasonPtr *string          `json:"finish_reason"`
+
+	// Usage reports the tokens of this choice when a request asks for more
+	// than one choice. The response usage covers every choice.
+	Usage *Usage `json:"usage,omitempty"`
+
+	// StopSequence is the request stop sequence that ended the choice. It is
+	// not part of the OpenAI wire format.
+	StopSequence string `json:"-"`
 }
 
 // FinishReason return the finish reason as an empty
@@ -1119,11 +1131,76 @@ func ChatResponseErr(id string, object string, model string, index int, err erro
 
 // =============================================================================
 
-// EmbedData represents the data associated with an embedding call.
+// EmbedData represents the data associated with an embedding call. Chunks
+// holds the embedding of each chunk when an input was split by chunking and
+// the request asked for them.
 type EmbedData struct {
-	Object    string    `json:"object"`
-	Index     int       `json:"index"`
-	Embedding []float32 `json:"embedding"`
+	Object    string       `json:"object"`
+	Index     int          `json:"index"`
+	Embedding []float32    `json:"embedding"`
+	Chunks    []EmbedChunk `json:"chunks,omitempty"`
+	base64    bool
+}
+
+// MarshalJSON encodes the embedding as base64 when the request asked for
+// the base64 encoding format.
+func (e EmbedData) MarshalJSON() ([]byte, error) {
+	type embedData struct {
+		Object    string       `json:"object"`
+		Index     int          `json:"index"`
+		Embedding any          `json:"embedding"`
+		Chunks    []EmbedChunk `json:"chunks,omitempty"`
+	}
+
+	return json.Marshal(embedData{
+		Object:    e.Object,
+		Index:     e.Index,
+		Embedding: encodeEmbedding(e.Embedding, e.base64),
+		Chunks:    e.Chunks,
+	})
+}
+
+// EmbedChunk represents the embedding of one chunk of an input. StartToken
+// and EndToken are the offsets of the chunk's tokens in the input's tokens.
+type EmbedChunk struct {
+	Index      int       `json:"index"`
+	StartToken int       `json:"start_token"`
+	EndToken   int       `json:"end_token"`
+	Embedding  []float32 `json:"embedding"`
+	base64     bool
+}
+
+// MarshalJSON encodes the embedding as base64 when the request asked for
+// the base64 encoding format.
+func (c EmbedChunk) MarshalJSON() ([]byte, error) {
+	type embedChunk struct {
+		Index      int `json:"index"`
+		StartToken int `json:"start_token"`
+		EndToken   int `json:"end_token"`
+		Embedding  any `json:"embedding"`
+	}
+
+	return json.Marshal(embedChunk{
+		Index:      c.Index,
+		StartToken: c.StartToken,
+		EndToken:   c.EndToken,
+		Embedding:  encodeEmbedding(c.Embedding, c.base64),
+	})
+}
+
+// encodeEmbedding returns the vector itself, or the base64 encoding of its
+// little-endian float32 values as the OpenAI API returns them.
+func encodeEmbedding(vec []float32, encode bool) any {
+	if !encode {
+		return vec
+	}
+
+	buf := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+
+	return base64.StdEncoding.EncodeToString(buf)
 }
 
 // EmbedUsage provides token usage information for embeddings.
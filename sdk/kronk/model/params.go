@@ -150,6 +150,12 @@ const (
 )
 
 type Params struct {
+	// Adapters selects the loaded LoRA adapters, by ID, and the scale to apply
+	// each at. Nil applies the model's configured adapters; an empty list
+	// runs the base model without adapters. A selection without a scale is
+	// applied at 1.
+	Adapters []AdapterSelection `json:"adapters,omitempty"`
+
 	// AdaptivePDecay controls how quickly the Adaptive-P sampler adjusts.
 	// Default is 0.0.
 	AdaptivePDecay float32 `json:"adaptive_p_decay"`
@@ -261,6 +267,11 @@ type Params struct {
 	// probability P. Default is 0.9.
 	TopP float32 `json:"top_p"`
 
+	// Truncation selects how a text prompt that does not fit the context
+	// window is shortened: disabled, auto, or middle-out. When empty, the
+	// model's configured mode is used, which defaults to disabled.
+	Truncation TruncationMode `json:"truncation"`
+
 	// XtcMinKeep is the minimum tokens to keep after XTC culling. Default is 1.
 	XtcMinKeep uint32 `json:"xtc_min_keep"`
 
@@ -280,6 +291,11 @@ func (p Params) String() string {
 	var b strings.Builder
 
 	fmt.Fprintln(&b)
+	if p.Adapters == nil {
+		fmt.Fprintln(&b, "adapters[default]")
+	} else {
+		fmt.Fprintf(&b, "adapters[%v]\n", p.Adapters)
+	}
 	fmt.Fprintf(&b, "adaptive_p_decay[%v]\n", p.AdaptivePDecay)
 	fmt.Fprintf(&b, "adaptive_p_target[%v]\n", p.AdaptivePTarget)
 	fmt.Fprintf(&b, "dry_allowed_length[%v]\n", p.DryAllowedLen)
@@ -307,6 +323,7 @@ func (p Params) String() string {
 	fmt.Fprintf(&b, "top_k[%v]\n", p.TopK)
 	fmt.Fprintf(&b, "top_logprobs[%v]\n", p.TopLogprobs)
 	fmt.Fprintf(&b, "top_p[%v]\n", p.TopP)
+	fmt.Fprintf(&b, "truncation[%v]\n", p.Truncation)
 	fmt.Fprintf(&b, "xtc_min_keep[%v]\n", p.XtcMinKeep)
 	fmt.Fprintf(&b, "xtc_probability[%v]\n", p.XtcProbability)
 	fmt.Fprintf(&b, "xtc_threshold[%v]\n", p.XtcThreshold)
@@ -317,6 +334,13 @@ func (p Params) String() string {
 // AddParams adds the values from the Params struct into the provided D map.
 // Only non-zero values are added.
 func AddParams(params Params, d D) {
+	if params.Adapters != nil {
+		adapters := make([]D, len(params.Adapters))
+		for i, adapter := range params.Adapters {
+			adapters[i] = D{"id": adapter.ID, "scale": adapter.Scale}
+		}
+		d["adapters"] = adapters
+	}
 	if params.AdaptivePDecay != 0 {
 		d["adaptive_p_decay"] = params.AdaptivePDecay
 	}
@@ -389,6 +413,9 @@ func AddParams(params Params, d D) {
 	if params.TopP != 0 {
 		d["top_p"] = params.TopP
 	}
+	if params.Truncation != "" {
+		d["truncation"] = string(params.Truncation)
+	}
 	if params.XtcMinKeep != 0 {
 		d["xtc_min_keep"] = params.XtcMinKeep
 	}
@@ -405,6 +432,14 @@ func (m *Model) parseParams(ctx context.Context, d D) (Params, error) {
 
 	p := m.cfg.DefaultParams
 
+	if val, exists := d["adapters"]; exists {
+		adapters, err := parseAdapterSelections(val)
+		if err != nil {
+			return Params{}, err
+		}
+		p.Adapters = adapters
+	}
+
 	if val, exists := d["adaptive_p_decay"]; exists {
 		adaptivePDecay, err := parseFloat32("adaptive_p_decay", val)
 		if err != nil {
@@ -496,6 +531,16 @@ func (m *Model) parseParams(ctx context.Context, d D) (Params, error) {
 		}
 	}
 
+	// A required or named tool_choice constrains output to the parser's
+	// native tool-call syntax unless the request supplied its own grammar.
+	if p.Grammar == "" {
+		grammar, err := m.toolCallGrammar(ctx, d)
+		if err != nil {
+			return Params{}, fmt.Errorf("to-params: %w", err)
+		}
+		p.Grammar = grammar
+	}
+
 	if val, exists := d["logprobs"]; exists {
 		logprobs, err := parseBool("logprobs", val)
 		if err != nil {
@@ -649,6 +694,14 @@ func (m *Model) parseParams(ctx context.Context, d D) (Params, error) {
 		p.TopP = topP
 	}
 
+	if val, exists := d["truncation"]; exists {
+		truncation, err := parseTruncation("truncation", val)
+		if err != nil {
+			return Params{}, err
+		}
+		p.Truncation = truncation
+	}
+
 	if val, exists := d["xtc_min_keep"]; exists {
 		xtcMinKeep, err := parseInt("xtc_min_keep", val)
 		if err != nil {
@@ -768,6 +821,9 @@ func (m *Model) adjustParams(p Params, request D) Params {
 			p.TopP = m.cfg.DefaultParams.TopP
 		}
 	}
+	if p.Truncation == "" {
+		p.Truncation = m.cfg.TruncationMode()
+	}
 	if p.XtcMinKeep <= 0 {
 		p.XtcMinKeep = DefXtcMinKeep
 		if m.paramsResolved {
@@ -1165,3 +1221,17 @@ func parseReasoningString(fieldName string, val any) (string, error) {
 
 	return v, nil
 }
+
+func parseTruncation(fieldName string, val any) (TruncationMode, error) {
+	v, ok := val.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: parse-truncation: field-name[%s] must be a string", ErrInvalidRequest, fieldName)
+	}
+
+	mode, err := ParseTruncationMode(v)
+	if err != nil {
+		return "", fmt.Errorf("%w: parse-truncation: field-name[%s]: %w", ErrInvalidRequest, fieldName, err)
+	}
+
+	return mode, nil
+}
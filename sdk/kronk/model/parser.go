@@ -2,6 +2,7 @@ package model
 
 import (
 	"context"
+	"unicode/utf8"
 
 	"github.com/ardanlabs/kronk/sdk/kronk/applog"
 )
@@ -142,6 +143,69 @@ type ToolCallDeltaStreamer interface {
 	StartedToolCalls() []ResponseToolCallDelta
 }
 
+// ToolCallArgumentStreamer is optionally implemented by tool-call delta
+// streamers that can also stream a call's arguments while the model writes
+// them. Kronk enables it for streaming requests after the state machine is
+// reset; it stays enabled until the next Reset.
+//
+// Once enabled, ToolCallDeltas also returns argument deltas that carry only
+// Index and Function.Arguments. They follow the start delta for the same
+// index, and their fragments concatenate to the JSON arguments object as the
+// model wrote it.
+type ToolCallArgumentStreamer interface {
+	StreamToolCallArguments()
+}
+
+// JSONObjectPrefix reports how many leading bytes of s belong to a JSON
+// object that starts at s[0], and whether the object is complete. It stops
+// before a trailing partial UTF-8 sequence so each prefix can be sent as a
+// fragment on its own. Strings are honored, so braces inside them do not
+// count; the object is otherwise not validated.
+func JSONObjectPrefix(s string) (int, bool) {
+	if s == "" || s[0] != '{' {
+		return 0, false
+	}
+
+	depth := 0
+	inString := false
+	escape := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escape:
+			escape = false
+		case inString:
+			switch c {
+			case '\\':
+				escape = true
+			case '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+			if depth == 0 {
+				return i + 1, true
+			}
+		}
+	}
+
+	n := len(s)
+	for start := n - 1; start >= max(n-utf8.UTFMax, 0); start-- {
+		if utf8.RuneStart(s[start]) {
+			if !utf8.FullRuneInString(s[start:]) {
+				n = start
+			}
+			break
+		}
+	}
+
+	return n, false
+}
+
 // Parser is the plugin interface implemented by each model lineage.
 // Implementations live in sdk/kronk/parsers/<name>/ and are registered
 // at startup via RegisterParser.
@@ -168,6 +232,16 @@ type ToolCallSchemaParser interface {
 	ToolCallWithSchema(ctx context.Context, log applog.Logger, buf string, tools []D) []ResponseToolCall
 }
 
+// ToolCallGrammarParser is optionally implemented by parsers that can express
+// their native tool-call syntax as a GBNF grammar. When a request sets
+// tool_choice to "required" or names a function, Kronk asks the parser for the
+// root rule of a grammar built from the declared tool schemas, so the emitted
+// call always parses and its arguments match the schema. Parsers that do not
+// implement it leave such requests unconstrained.
+type ToolCallGrammarParser interface {
+	ToolCallGrammar(tg *ToolGrammar) (string, error)
+}
+
 // ParamsAdjuster is an optional interface a Parser may implement to coerce
 // request Params into values its model lineage's chat template will accept.
 // It is invoked at the end of Model.adjustParams, after global defaults have
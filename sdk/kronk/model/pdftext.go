@@ -0,0 +1,457 @@
+package model
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// maxPDFStreamBytes bounds the size of a single decompressed PDF stream so a
+// small compressed file cannot expand without limit.
+const maxPDFStreamBytes = 64 << 20
+
+// pdfKerningSpace is the TJ adjustment, in thousandths of a text unit, past
+// which a gap between two strings is treated as a word break.
+const pdfKerningSpace = 200
+
+// extractPDFText returns the text drawn by the content streams of a PDF. It
+// reads uncompressed and FlateDecode streams and decodes strings as
+// Windows-1252 or UTF-16, so text set in fonts with custom or CID encodings
+// and text inside images is not recovered.
+func extractPDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", errors.New("extract-pdf-text: not a PDF document")
+	}
+
+	var w pdfTextWriter
+
+	rest := data
+	for {
+		at := bytes.Index(rest, []byte("stream"))
+		if at == -1 {
+			break
+		}
+
+		// Skip the endstream keyword of a stream that was not read.
+		if at >= 3 && string(rest[at-3:at]) == "end" {
+			rest = rest[at+len("stream"):]
+			continue
+		}
+
+		dict := rest[:at]
+		if obj := bytes.LastIndex(dict, []byte("obj")); obj != -1 {
+			dict = dict[obj:]
+		}
+
+		body := rest[at+len("stream"):]
+		switch {
+		case bytes.HasPrefix(body, []byte("\r\n")):
+			body = body[2:]
+		case bytes.HasPrefix(body, []byte("\n")), bytes.HasPrefix(body, []byte("\r")):
+			body = body[1:]
+		}
+
+		end := bytes.Index(body, []byte("endstream"))
+		if end == -1 {
+			break
+		}
+		rest = body[end+len("endstream"):]
+
+		content, ok := decodePDFStream(dict, body[:end])
+		if !ok || !bytes.Contains(content, []byte("BT")) {
+			continue
+		}
+
+		w.writeContent(content)
+	}
+
+	text := w.text()
+	if text == "" {
+		return "", errors.New("extract-pdf-text: no extractable text found")
+	}
+
+	return text, nil
+}
+
+// decodePDFStream returns the decoded bytes of a stream. Image streams and
+// streams with filters other than FlateDecode are skipped.
+func decodePDFStream(dict []byte, stream []byte) ([]byte, bool) {
+	compact := bytes.ReplaceAll(dict, []byte(" "), nil)
+	if bytes.Contains(compact, []byte("/Subtype/Image")) {
+		return nil, false
+	}
+
+	at := bytes.Index(dict, []byte("/Filter"))
+	if at == -1 {
+		return stream, true
+	}
+
+	filter := bytes.TrimLeft(dict[at+len("/Filter"):], " \r\n\t")
+	if bytes.HasPrefix(filter, []byte("[")) {
+		if end := bytes.IndexByte(filter, ']'); end != -1 {
+			filter = filter[1:end]
+		}
+	} else if end := bytes.IndexAny(filter[min(1, len(filter)):], "/>\r\n\t "); end != -1 {
+		filter = filter[:end+1]
+	}
+
+	names := bytes.Fields(bytes.ReplaceAll(filter, []byte("/"), []byte(" ")))
+	if len(names) != 1 || string(names[0]) != "FlateDecode" {
+		return nil, false
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(stream))
+	if err != nil {
+		return nil, false
+	}
+	defer r.Close()
+
+	// Streams are often truncated or padded, so keep whatever inflated
+	// before the error.
+	decoded, _ := io.ReadAll(io.LimitReader(r, maxPDFStreamBytes))
+
+	return decoded, len(decoded) > 0
+}
+
+// =============================================================================
+
+type pdfOperand struct {
+	text     []byte
+	isText   bool
+	number   float64
+	isNumber bool
+}
+
+type pdfTextWriter struct {
+	b      strings.Builder
+	lastY  float64
+	hasY   bool
+	inText bool
+}
+
+// writeContent interprets the text operators of a content stream.
+func (w *pdfTextWriter) writeContent(content []byte) {
+	var operands []pdfOperand
+
+	for i := 0; i < len(content); {
+		c := content[i]
+
+		switch {
+		case isPDFSpace(c):
+			i++
+
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+
+		case c == '(':
+			s, n := readPDFLiteral(content[i:])
+			operands = append(operands, pdfOperand{text: s, isText: true})
+			i += n
+
+		case c == '<' && i+1 < len(content) && content[i+1] == '<':
+			i += 2
+
+		case c == '>' && i+1 < len(content) && content[i+1] == '>':
+			i += 2
+
+		case c == '<':
+			s, n := readPDFHex(content[i:])
+			operands = append(operands, pdfOperand{text: s, isText: true})
+			i += n
+
+		case c == '[':
+			operands = operands[:0]
+			i++
+
+		case c == '/':
+			i++
+			for i < len(content) && !isPDFSpace(content[i]) && !isPDFDelimiter(content[i]) {
+				i++
+			}
+
+		case isPDFDelimiter(c):
+			i++
+
+		default:
+			start := i
+			for i < len(content) && !isPDFSpace(content[i]) && !isPDFDelimiter(content[i]) {
+				i++
+			}
+
+			token := string(content[start:i])
+			if n, err := strconv.ParseFloat(token, 64); err == nil {
+				operands = append(operands, pdfOperand{number: n, isNumber: true})
+				continue
+			}
+
+			w.operator(token, operands)
+			operands = operands[:0]
+		}
+	}
+}
+
+func (w *pdfTextWriter) operator(op string, operands []pdfOperand) {
+	switch op {
+	case "BT":
+		w.inText = true
+
+	case "ET":
+		w.inText = false
+		w.newline()
+	}
+
+	if !w.inText {
+		return
+	}
+
+	switch op {
+	case "Tj":
+		w.writeLast(operands)
+
+	case "'", `"`:
+		w.newline()
+		w.writeLast(operands)
+
+	case "TJ":
+		for _, o := range operands {
+			switch {
+			case o.isText:
+				w.writeString(o.text)
+			case o.isNumber && o.number < -pdfKerningSpace:
+				w.space()
+			}
+		}
+
+	case "Td", "TD":
+		if len(operands) >= 2 && operands[1].isNumber && operands[1].number != 0 {
+			w.newline()
+			return
+		}
+		w.space()
+
+	case "Tm":
+		if len(operands) >= 6 && operands[5].isNumber {
+			y := operands[5].number
+			if w.hasY && y != w.lastY {
+				w.newline()
+			} else {
+				w.space()
+			}
+			w.lastY, w.hasY = y, true
+		}
+
+	case "T*":
+		w.newline()
+	}
+}
+
+func (w *pdfTextWriter) writeLast(operands []pdfOperand) {
+	for i := len(operands) - 1; i >= 0; i-- {
+		if operands[i].isText {
+			w.writeString(operands[i].text)
+			return
+		}
+	}
+}
+
+func (w *pdfTextWriter) writeString(s []byte) {
+	var text string
+	if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+		units := make([]uint16, 0, (len(s)-2)/2)
+		for i := 2; i+1 < len(s); i += 2 {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+		text = string(utf16.Decode(units))
+	} else {
+		decoded, err := charmap.Windows1252.NewDecoder().Bytes(s)
+		if err != nil {
+			return
+		}
+		text = string(decoded)
+	}
+
+	for _, r := range text {
+		switch {
+		case r == '\n' || r == '\r':
+			w.newline()
+		case r == '\t':
+			w.space()
+		case unicode.IsPrint(r):
+			w.b.WriteRune(r)
+		}
+	}
+}
+
+func (w *pdfTextWriter) space() {
+	if s := w.b.String(); s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+		w.b.WriteByte(' ')
+	}
+}
+
+func (w *pdfTextWriter) newline() {
+	if s := w.b.String(); s != "" && !strings.HasSuffix(s, "\n") {
+		w.b.WriteByte('\n')
+	}
+}
+
+// text returns the written text with trailing spaces removed from each line
+// and runs of blank lines collapsed.
+func (w *pdfTextWriter) text() string {
+	lines := strings.Split(w.b.String(), "\n")
+
+	var b strings.Builder
+	var blank bool
+	for _, line := range lines {
+		line = strings.TrimRight(line, " ")
+		if line == "" {
+			blank = true
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+			if blank {
+				b.WriteByte('\n')
+			}
+		}
+		b.WriteString(line)
+		blank = false
+	}
+
+	return b.String()
+}
+
+// =============================================================================
+
+// readPDFLiteral reads a literal string starting at the opening parenthesis
+// and returns its bytes and the number of input bytes consumed.
+func readPDFLiteral(data []byte) ([]byte, int) {
+	var out []byte
+	depth := 0
+
+	i := 0
+	for i < len(data) {
+		c := data[i]
+		i++
+
+		switch c {
+		case '(':
+			if depth > 0 {
+				out = append(out, c)
+			}
+			depth++
+
+		case ')':
+			depth--
+			if depth == 0 {
+				return out, i
+			}
+			out = append(out, c)
+
+		case '\\':
+			if i >= len(data) {
+				return out, i
+			}
+
+			e := data[i]
+			i++
+
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if i < len(data) && data[i] == '\n' {
+					i++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for n := 0; n < 2 && i < len(data) && data[i] >= '0' && data[i] <= '7'; n++ {
+						v = v*8 + int(data[i]-'0')
+						i++
+					}
+					out = append(out, byte(v))
+					continue
+				}
+				out = append(out, e)
+			}
+
+		default:
+			out = append(out, c)
+		}
+	}
+
+	return out, i
+}
+
+// readPDFHex reads a hexadecimal string starting at the opening angle
+// bracket and returns its bytes and the number of input bytes consumed.
+func readPDFHex(data []byte) ([]byte, int) {
+	var out []byte
+	var hi byte
+	var half bool
+
+	for i := 1; i < len(data); i++ {
+		c := data[i]
+
+		var v byte
+		switch {
+		case c == '>':
+			if half {
+				out = append(out, hi<<4)
+			}
+			return out, i + 1
+		case c >= '0' && c <= '9':
+			v = c - '0'
+		case c >= 'a' && c <= 'f':
+			v = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			v = c - 'A' + 10
+		default:
+			continue
+		}
+
+		if half {
+			out = append(out, hi<<4|v)
+		} else {
+			hi = v
+		}
+		half = !half
+	}
+
+	return out, len(data)
+}
+
+func isPDFSpace(c byte) bool {
+	switch c {
+	case ' ', '\n', '\r', '\t', '\f', 0:
+		return true
+	}
+	return false
+}
+
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"context"
+	"fmt"
+)
+
+// Priority selects the scheduling lane of a chat request. When several
+// requests are waiting for a slot, every request in a higher lane is assigned
+// before any request in a lower lane. The zero value is PriorityNormal.
+type Priority int
+
+// Set of scheduling priorities, lowest first.
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// Priorities lists the scheduling priorities from highest to lowest.
+var Priorities = [...]Priority{PriorityHigh, PriorityNormal, PriorityLow}
+
+// ParsePriority parses a priority name: "low", "normal", or "high". An empty
+// name is PriorityNormal.
+func ParsePriority(name string) (Priority, error) {
+	switch name {
+	case "low":
+		return PriorityLow, nil
+	case "", "normal":
+		return PriorityNormal, nil
+	case "high":
+		return PriorityHigh, nil
+	}
+
+	return PriorityNormal, fmt.Errorf("invalid priority %q: want low, normal, or high", name)
+}
+
+// String returns the name of the priority.
+func (p Priority) String() string {
+	switch {
+	case p < PriorityNormal:
+		return "low"
+	case p > PriorityNormal:
+		return "high"
+	}
+
+	return "normal"
+}
+
+// MarshalText provides support for logging and serialization.
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText parses serialized text into a Priority.
+func (p *Priority) UnmarshalText(data []byte) error {
+	priority, err := ParsePriority(string(data))
+	if err != nil {
+		return err
+	}
+
+	*p = priority
+	return nil
+}
+
+// =============================================================================
+
+// Schedule identifies how the batch engine orders a request against other
+// waiting requests. Requests are placed in their priority lane and, within a
+// lane, tenants share the free slots by weighted fair queuing so one tenant's
+// burst cannot starve the others.
+type Schedule struct {
+	Priority Priority
+	Tenant   string
+}
+
+type scheduleKey struct{}
+
+// SetSchedule sets the schedule of requests made with the context.
+func SetSchedule(ctx context.Context, schedule Schedule) context.Context {
+	return context.WithValue(ctx, scheduleKey{}, schedule)
+}
+
+// GetSchedule returns the schedule from the context. A context without a
+// schedule belongs to the anonymous tenant at PriorityNormal.
+func GetSchedule(ctx context.Context) Schedule {
+	schedule, _ := ctx.Value(scheduleKey{}).(Schedule)
+	return schedule
+}
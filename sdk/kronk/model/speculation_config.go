@@ -16,18 +16,33 @@ const (
 	SpeculationDisabled = internalspec.ModeDisabled
 	SpeculationClassic  = internalspec.ModeClassic
 	SpeculationMTP      = internalspec.ModeMTP
+	SpeculationNGram    = internalspec.ModeNGram
 
 	speculationSourceNone         = internalspec.SourceNone
 	speculationSourceClassic      = internalspec.SourceClassic
 	speculationSourceMTPCompanion = internalspec.SourceMTPCompanion
 	speculationSourceMTPEmbedded  = internalspec.SourceMTPEmbedded
+	speculationSourceNGram        = internalspec.SourceNGram
 )
 
+// defNGramNDraft is the default number of candidates an n-gram lookup copies
+// per round. Lookups cost nothing to produce, so the ceiling is set higher
+// than for model drafters; adaptive sizing trims it when copies stop
+// matching.
+const defNGramNDraft = 8
+
 type speculationPlan = internalspec.Plan
 
 func resolveSpeculationPlan(ctx context.Context, log applog.Logger, cfg Config) (speculationPlan, error) {
 	mode := cfg.SpeculationMode()
 	classic := cfg.PtrDraftModel != nil && cfg.PtrDraftModel.IsSeparate()
+	if mode == SpeculationNGram {
+		return internalspec.Resolve(internalspec.Config{
+			Mode:              mode,
+			ClassicConfigured: classic,
+			NGramNDraft:       ngramNDraft(cfg),
+		})
+	}
 	if mode == SpeculationDisabled || classic && mode == SpeculationAuto || mode == SpeculationClassic {
 		return internalspec.Resolve(internalspec.Config{
 			Mode:              mode,
@@ -59,3 +74,12 @@ func configuredClassicNDraft(cfg Config) int {
 	}
 	return defNDraft
 }
+
+// ngramNDraft returns the ceiling on n-gram candidates per round. A DraftModel
+// block with no model files sets it explicitly, as it does for MTP.
+func ngramNDraft(cfg Config) int {
+	if cfg.PtrDraftModel != nil && !cfg.PtrDraftModel.IsSeparate() && cfg.PtrDraftModel.NDraft > 0 {
+		return cfg.PtrDraftModel.NDraft
+	}
+	return defNGramNDraft
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation"
 	classicengine "github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/classic"
 	"github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/mtp"
+	"github.com/ardanlabs/kronk/sdk/kronk/model/internal/speculation/ngram"
 	"github.com/hybridgroup/yzma/pkg/llama"
 )
 
@@ -20,6 +21,8 @@ func newSpeculationController(e *batchEngine) speculation.Controller {
 		return classicengine.New(e)
 	case speculationSourceMTPCompanion, speculationSourceMTPEmbedded:
 		return mtp.New(e)
+	case speculationSourceNGram:
+		return ngram.New(e)
 	}
 	return speculation.NewDisabled(e)
 }
@@ -62,6 +65,23 @@ func (e *batchEngine) ClassicGenerationInput(slotID int) (classicengine.Generati
 	}, nil
 }
 
+// NGramGenerationInput sizes the slot's next n-gram draft with the classic
+// adaptive policy. Slots without a generated token yet have no suffix to match.
+func (e *batchEngine) NGramGenerationInput(slotID int) (classicengine.GenerationInput, error) {
+	s := e.slots[slotID]
+	if !s.prefillDone {
+		return classicengine.GenerationInput{}, nil
+	}
+	greedy := s.job.params.Temperature == 0
+	return classicengine.GenerationInput{
+		State:    &s.classic,
+		MaxDraft: e.maxDraftForSlot(s, e.model.cfg.speculationPlan.NDraft),
+		Generate: func(count int) (classicengine.GenerationResult, error) {
+			return s.ngram.Draft(count, greedy), nil
+		},
+	}, nil
+}
+
 func (e *batchEngine) CommitClassicDraft(slotID int, result classicengine.GenerationResult) {
 	s := e.slots[slotID]
 	s.draftTokensBuf = result.Candidates
@@ -236,8 +256,8 @@ func (e *batchEngine) ProcessOrdinary(slotID int, buf []byte) {
 
 func (e *batchEngine) ClassicVerifyInput(slotID int, buf []byte) (classicengine.VerifyInput, error) {
 	s := e.slots[slotID]
-	draft := e.model.draft.core()
 	nVocab := int(llama.VocabNTokens(e.model.vocab))
+	scratch := e.verifyScratch(nVocab)
 	greedy := s.job.params.Temperature == 0
 	s.specPendingOriginalSampled = s.sampled
 	distributions := s.classic.DraftDistributions
@@ -261,10 +281,10 @@ func (e *batchEngine) ClassicVerifyInput(slotID int, buf []byte) (classicengine.
 				maskSuppressTokenLogits(logits, e.model.suppressTokens)
 				return classicengine.Target{Token: classicengine.GreedyToken(logits)}
 			}
-			draft.sortIndices = applySamplerFilters(logits, draft.targetProbs, e.model.suppressTokens,
+			scratch.sortIndices = applySamplerFilters(logits, scratch.targetProbs, e.model.suppressTokens,
 				s.job.params.Temperature, s.job.params.TopP, s.job.params.MinP, s.job.params.TopK,
-				draft.sortIndices, &draft.filterBuf)
-			return classicengine.Target{Probabilities: draft.targetProbs}
+				scratch.sortIndices, &scratch.filterBuf)
+			return classicengine.Target{Probabilities: scratch.targetProbs}
 		},
 		Accept: func(index int, token llama.Token, samplerAccepted bool) bool {
 			s.specAcceptedTotal++
@@ -277,6 +297,19 @@ func (e *batchEngine) ClassicVerifyInput(slotID int, buf []byte) (classicengine.
 	}, nil
 }
 
+// verifyScratch returns the buffers for building target distributions. The
+// draft core owns them when a draft model is loaded; n-gram drafting keeps a
+// set on the engine, allocated on first use.
+func (e *batchEngine) verifyScratch(nVocab int) *samplingScratch {
+	if e.model.draft != nil {
+		return &e.model.draft.core().samplingScratch
+	}
+	if len(e.ngramScratch.targetProbs) < nVocab {
+		e.ngramScratch.targetProbs = make([]float32, nVocab)
+	}
+	return &e.ngramScratch
+}
+
 func (e *batchEngine) CommitClassicVerify(slotID int, buf []byte, result classicengine.VerifyResult) {
 	s := e.slots[slotID]
 	if !result.Complete || !s.active {
@@ -523,3 +556,4 @@ func (e *batchEngine) Fail(slotID int, err error) {
 var _ speculation.Host = (*batchEngine)(nil)
 var _ classicengine.Host = (*batchEngine)(nil)
 var _ mtp.Host = (*batchEngine)(nil)
+var _ ngram.Host = (*batchEngine)(nil)
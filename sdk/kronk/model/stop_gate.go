@@ -14,6 +14,7 @@ type stopGate struct {
 	stops     []string
 	pending   []stopPiece
 	discarded []stopPiece
+	matched   string
 }
 
 func newStopGate(stops []string) *stopGate {
@@ -49,6 +50,7 @@ func (g *stopGate) feed(piece stopPiece) ([]stopPiece, bool) {
 		}
 	}
 	if matchEnd >= 0 {
+		g.matched = g.stops[matchOrder]
 		emitted := g.takeBefore(matchStart, true)
 		g.discarded = append(g.discarded[:0], g.pending...)
 		g.pending = nil
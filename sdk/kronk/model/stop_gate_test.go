@@ -65,6 +65,31 @@ func TestStopGate(t *testing.T) {
 	}
 }
 
+func TestStopGateRecordsMatchedSequence(t *testing.T) {
+	tests := []struct {
+		name   string
+		stops  []string
+		pieces []string
+		want   string
+	}{
+		{name: "causal completion beats earlier start", stops: []string{"abcde", "bc"}, pieces: []string{"abc"}, want: "bc"},
+		{name: "request order tie", stops: []string{"abc", "bc"}, pieces: []string{"abc"}, want: "abc"},
+		{name: "no match", stops: []string{"STOP"}, pieces: []string{"hello"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gate := newStopGate(tt.stops)
+			for _, content := range tt.pieces {
+				gate.feed(stopPiece{content: content})
+			}
+			if gate.matched != tt.want {
+				t.Errorf("matched: got %q, want %q", gate.matched, tt.want)
+			}
+		})
+	}
+}
+
 func TestStopGateMatchesCodepointSplitAcrossUTF8Bytes(t *testing.T) {
 	world := []byte("🌍")
 	chunks := [][]byte{
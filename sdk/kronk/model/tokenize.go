@@ -11,16 +11,29 @@ import (
 // Tokenize returns the token count for a text input.
 //
 // Supported options in d:
-//   - input (string): the text to tokenize (required)
+//   - input (string): the text to tokenize (required unless messages is set)
 //   - apply_template (bool): if true, wrap input as a user message and apply
 //     the model's chat template before tokenizing (default: false)
 //   - add_generation_prompt (bool): when apply_template is true, controls whether
 //     the assistant role prefix is appended to the prompt (default: true)
+//   - messages ([]D): a chat conversation to count instead of input. It is
+//     rendered with the model's chat template together with the tools and
+//     thinking settings in d, as a chat request would be. Media content is
+//     not counted.
 //
 // When apply_template is true, the returned count includes all template
 // overhead (role markers, separators, generation prompt). This reflects the
 // actual number of tokens that would be fed to the model.
 func (m *Model) Tokenize(ctx context.Context, d D) (TokenizeResponse, error) {
+	if _, exists := d["messages"]; exists {
+		prompt, err := m.chatPrompt(ctx, d)
+		if err != nil {
+			return TokenizeResponse{}, fmt.Errorf("tokenize: %w", err)
+		}
+
+		return m.tokenizeText(prompt), nil
+	}
+
 	input, ok := d["input"].(string)
 	if !ok || input == "" {
 		return TokenizeResponse{}, fmt.Errorf("%w: tokenize: missing or invalid input parameter (expected non-empty string)", ErrInvalidRequest)
@@ -52,14 +65,39 @@ func (m *Model) Tokenize(ctx context.Context, d D) (TokenizeResponse, error) {
 		text = prompt
 	}
 
+	return m.tokenizeText(text), nil
+}
+
+// chatPrompt renders the prompt a chat request for d would feed the model,
+// keeping only the text content of messages.
+func (m *Model) chatPrompt(ctx context.Context, d D) (string, error) {
+	d = d.Clone()
+
+	if err := normalizeChatTemplateKwargs(d, m.cfg.ChatTemplateKwargs); err != nil {
+		return "", err
+	}
+
+	if _, err := m.validateDocument(ctx, d); err != nil {
+		return "", err
+	}
+
+	d = deserializeToolCallArguments(m.prepareTextContext(d))
+
+	prompt, err := m.applyJinjaTemplate(ctx, d)
+	if err != nil {
+		return "", fmt.Errorf("apply-template: %w", err)
+	}
+
+	return prompt, nil
+}
+
+func (m *Model) tokenizeText(text string) TokenizeResponse {
 	tokens := llama.Tokenize(m.vocab, text, m.addBOSToken, true)
 
-	tr := TokenizeResponse{
+	return TokenizeResponse{
 		Object:  "tokenize",
 		Created: time.Now().Unix(),
 		Model:   m.responseModelID(),
 		Tokens:  len(tokens),
 	}
-
-	return tr, nil
 }
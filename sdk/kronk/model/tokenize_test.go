@@ -24,3 +24,10 @@ func TestTokenizeInputValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenizeMessagesValidation(t *testing.T) {
+	m := Model{}
+	if _, err := m.Tokenize(t.Context(), D{"messages": "hello"}); !errors.Is(err, ErrMessagesInvalid) {
+		t.Errorf("Tokenize: got %v, want ErrMessagesInvalid", err)
+	}
+}
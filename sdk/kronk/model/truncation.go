@@ -0,0 +1,344 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/hybridgroup/yzma/pkg/llama"
+)
+
+// TruncationMode selects how a text chat request whose prompt does not fit the
+// context window is shortened before generation. The zero value uses the
+// model's configured mode, which defaults to TruncationDisabled.
+type TruncationMode string
+
+// Set of truncation modes.
+const (
+	// TruncationDisabled rejects a prompt that does not fit.
+	TruncationDisabled TruncationMode = "disabled"
+
+	// TruncationAuto drops the oldest conversation turns until the prompt
+	// fits. System and developer messages, the last user message, and the
+	// most recent message are always kept, and an assistant tool call is
+	// dropped together with its tool results.
+	TruncationAuto TruncationMode = "auto"
+
+	// TruncationMiddleOut first shortens long tool results by removing the
+	// middle of their content, then drops turns like TruncationAuto if the
+	// prompt still does not fit.
+	TruncationMiddleOut TruncationMode = "middle-out"
+)
+
+// ParseTruncationMode parses a truncation mode name. An empty name returns
+// the empty mode, which defers to the model's configured mode.
+func ParseTruncationMode(name string) (TruncationMode, error) {
+	switch mode := TruncationMode(name); mode {
+	case "", TruncationDisabled, TruncationAuto, TruncationMiddleOut:
+		return mode, nil
+	}
+
+	return "", fmt.Errorf("invalid truncation %q: want disabled, auto, or middle-out", name)
+}
+
+const (
+	// truncationOutputDivisor bounds the room truncation leaves for output
+	// to a fraction of the context window, so a request that omits
+	// max_tokens does not lose its whole conversation to the default.
+	truncationOutputDivisor = 4
+
+	// minToolResultChars is the shortest tool result middle-out trimming
+	// produces. Below this the result no longer says anything useful.
+	minToolResultChars = 256
+)
+
+// truncationOutputReserve returns the output room truncation keeps free
+// after the prompt.
+func truncationOutputReserve(maxTokens, contextWindow int) int {
+	return max(min(maxTokens, contextWindow/truncationOutputDivisor), 0)
+}
+
+// truncationRequired reports whether the prepared text prompt leaves less than
+// the output reserve free and the request allows truncation.
+func (m *Model) truncationRequired(prepared preparedChat) bool {
+	if prepared.object != ObjectChatText || prepared.params.Truncation == TruncationDisabled {
+		return false
+	}
+
+	contextWindow := m.cfg.ContextWindow()
+	reserve := truncationOutputReserve(prepared.params.MaxTokens, contextWindow)
+
+	return len(prepared.textTokens)+reserve > contextWindow
+}
+
+// truncateChat shortens the conversation in d according to mode so its prompt
+// plus the output reserve fits the context window. When no allowed truncation
+// fits, the most truncated conversation is returned and the caller's context
+// budget check decides whether it can still run.
+func (m *Model) truncateChat(ctx context.Context, d D, mode TruncationMode, maxTokens int) (D, error) {
+	contextWindow := m.cfg.ContextWindow()
+	limit := contextWindow - truncationOutputReserve(maxTokens, contextWindow)
+
+	measure := func(messages []D) (int, error) {
+		candidate := maps.Clone(d)
+		candidate["messages"] = messages
+		prompt, _, err := m.createPrompt(ctx, deserializeToolCallArguments(candidate))
+		if err != nil {
+			return 0, err
+		}
+		return len(llama.Tokenize(m.vocab, prompt, m.addBOSToken, true)), nil
+	}
+
+	messages := dMessages(d)
+	result, err := truncateMessages(messages, mode, limit, measure)
+	if err != nil {
+		return nil, fmt.Errorf("truncate-chat: %w", err)
+	}
+
+	m.log(ctx, "prepare-chat", "status", "truncated",
+		"truncation", mode,
+		"input_messages", len(messages),
+		"kept_messages", len(result.messages),
+		"trimmed_tool_results", result.trimmed,
+		"prompt_tokens", result.tokens,
+		"token_limit", limit)
+
+	truncated := maps.Clone(d)
+	truncated["messages"] = result.messages
+
+	return truncated, nil
+}
+
+// truncation is the outcome of truncateMessages.
+type truncation struct {
+	messages []D
+	tokens   int // Prompt tokens measured for messages.
+	trimmed  int // Tool results shortened by middle-out trimming.
+}
+
+// truncateMessages returns the least truncated form of messages whose measured
+// prompt is at most limit tokens, or the most truncated form when none is.
+// Each step only removes content, so the measured size decreases
+// monotonically and a binary search finds the answer in a logarithmic number
+// of measurements.
+func truncateMessages(messages []D, mode TruncationMode, limit int, measure func([]D) (int, error)) (truncation, error) {
+	if mode == TruncationMiddleOut {
+		longest := 0
+		for _, msg := range messages {
+			if content, ok := toolResult(msg); ok {
+				longest = max(longest, utf8.RuneCountInString(content))
+			}
+		}
+
+		if longest > minToolResultChars {
+			chars, trimmed, tokens, err := searchTruncation(minToolResultChars, longest, limit, true, func(chars int) ([]D, int, error) {
+				trimmed := trimToolResults(messages, chars)
+				tokens, err := measure(trimmed)
+				return trimmed, tokens, err
+			})
+			if err != nil {
+				return truncation{}, err
+			}
+
+			var count int
+			for _, msg := range messages {
+				if content, ok := toolResult(msg); ok && utf8.RuneCountInString(content) > chars {
+					count++
+				}
+			}
+			if tokens <= limit {
+				return truncation{messages: trimmed, tokens: tokens, trimmed: count}, nil
+			}
+			result, err := dropTurns(trimmed, limit, measure)
+			result.trimmed = count
+			return result, err
+		}
+	}
+
+	return dropTurns(messages, limit, measure)
+}
+
+// dropTurns removes the fewest leading truncation units from messages that
+// brings the measured prompt within limit.
+func dropTurns(messages []D, limit int, measure func([]D) (int, error)) (truncation, error) {
+	units := truncationUnits(messages)
+
+	_, kept, tokens, err := searchTruncation(0, len(units), limit, false, func(drop int) ([]D, int, error) {
+		kept := withoutUnits(messages, units[:drop])
+		tokens, err := measure(kept)
+		return kept, tokens, err
+	})
+	if err != nil {
+		return truncation{}, err
+	}
+
+	return truncation{messages: kept, tokens: tokens}, nil
+}
+
+// searchTruncation binary searches the integer range [lo, hi] for the value
+// whose candidate fits within limit while truncating the least. When keepHigh
+// is true larger values truncate less, otherwise smaller values do. The most
+// truncating candidate is returned when none fits.
+func searchTruncation(lo, hi, limit int, keepHigh bool, candidate func(int) ([]D, int, error)) (int, []D, int, error) {
+	value := hi
+	if keepHigh {
+		value = lo
+	}
+
+	best, bestTokens, err := candidate(value)
+	if err != nil || bestTokens > limit {
+		return value, best, bestTokens, err
+	}
+
+	// Invariant: the value at the most-truncating end of [lo, hi] fits.
+	for lo < hi {
+		var mid int
+		switch {
+		case keepHigh:
+			mid = lo + (hi-lo+1)/2
+		default:
+			mid = lo + (hi-lo)/2
+		}
+
+		messages, tokens, err := candidate(mid)
+		if err != nil {
+			return 0, nil, 0, err
+		}
+
+		fits := tokens <= limit
+		if fits {
+			value, best, bestTokens = mid, messages, tokens
+		}
+
+		switch {
+		case keepHigh && fits:
+			lo = mid
+		case keepHigh:
+			hi = mid - 1
+		case fits:
+			hi = mid
+		default:
+			lo = mid + 1
+		}
+	}
+
+	return value, best, bestTokens, nil
+}
+
+// truncationUnits groups the droppable messages of a conversation into units
+// ordered oldest first, each a list of message indexes. Before the last user
+// message, a unit is a whole turn: a user message and every reply up to the
+// next user message, so the remaining conversation still starts with a user
+// turn. After it, a unit is one message plus the tool results that follow it,
+// which keeps an assistant tool call with its results. System and developer
+// messages, the last user message, and the final unit are never included.
+func truncationUnits(messages []D) [][]int {
+	lastUser := -1
+	for i, msg := range messages {
+		if messageRole(msg) == RoleUser {
+			lastUser = i
+		}
+	}
+
+	var units [][]int
+	var unit []int
+	flush := func() {
+		if len(unit) > 0 {
+			units = append(units, unit)
+			unit = nil
+		}
+	}
+
+	for i, msg := range messages {
+		role := messageRole(msg)
+		switch {
+		case role == RoleSystem || role == "developer" || i == lastUser:
+			continue
+		case i < lastUser && role == RoleUser:
+			flush()
+		case i > lastUser && role != RoleTool:
+			flush()
+		}
+		unit = append(unit, i)
+	}
+	flush()
+
+	// The most recent message is what the request asks the model to answer.
+	if n := len(units); n > 0 && slices.Contains(units[n-1], len(messages)-1) {
+		units = units[:n-1]
+	}
+
+	return units
+}
+
+// withoutUnits returns messages without the messages in units.
+func withoutUnits(messages []D, units [][]int) []D {
+	if len(units) == 0 {
+		return messages
+	}
+
+	drop := make(map[int]bool)
+	for _, unit := range units {
+		for _, i := range unit {
+			drop[i] = true
+		}
+	}
+
+	kept := make([]D, 0, len(messages)-len(drop))
+	for i, msg := range messages {
+		if !drop[i] {
+			kept = append(kept, msg)
+		}
+	}
+
+	return kept
+}
+
+// trimToolResults returns messages with every tool result longer than chars
+// characters cut to its first and last chars/2 characters around a note
+// recording how much was removed.
+func trimToolResults(messages []D, chars int) []D {
+	var trimmed []D
+	for i, msg := range messages {
+		content, ok := toolResult(msg)
+		if !ok || utf8.RuneCountInString(content) <= chars {
+			continue
+		}
+
+		if trimmed == nil {
+			trimmed = make([]D, len(messages))
+			copy(trimmed, messages)
+		}
+
+		runes := []rune(content)
+		head, tail := chars/2, chars-chars/2
+		omitted := len(runes) - head - tail
+
+		msg = msg.ShallowClone()
+		msg["content"] = fmt.Sprintf("%s\n\n[... %d characters omitted ...]\n\n%s", string(runes[:head]), omitted, string(runes[len(runes)-tail:]))
+		trimmed[i] = msg
+	}
+
+	if trimmed == nil {
+		return messages
+	}
+
+	return trimmed
+}
+
+// toolResult returns the text content of a tool result message.
+func toolResult(msg D) (string, bool) {
+	if messageRole(msg) != RoleTool {
+		return "", false
+	}
+
+	content, ok := msg["content"].(string)
+	return content, ok
+}
+
+func messageRole(msg D) string {
+	role, _ := msg["role"].(string)
+	return role
+}
@@ -0,0 +1,176 @@
+package model
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseTruncationMode(t *testing.T) {
+	for _, name := range []string{"", "disabled", "auto", "middle-out"} {
+		if got, err := ParseTruncationMode(name); err != nil || string(got) != name {
+			t.Errorf("ParseTruncationMode(%q) = (%q, %v), want (%q, nil)", name, got, err, name)
+		}
+	}
+
+	if _, err := ParseTruncationMode("oldest"); err == nil {
+		t.Error("ParseTruncationMode(oldest) error = nil, want error")
+	}
+	if _, err := parseTruncation("truncation", 1); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("parseTruncation(1) error = %v, want %v", err, ErrInvalidRequest)
+	}
+}
+
+func TestTruncationUnits(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []string
+		want  [][]int
+	}{
+		{
+			name:  "earlier turns drop whole",
+			roles: []string{"system", "user", "assistant", "user", "assistant", "user"},
+			want:  [][]int{{1, 2}, {3, 4}},
+		},
+		{
+			name:  "tool calls drop with their results",
+			roles: []string{"system", "user", "assistant", "tool", "tool", "assistant", "tool", "assistant", "tool"},
+			want:  [][]int{{2, 3, 4}, {5, 6}},
+		},
+		{
+			name:  "leading assistant greeting forms a unit",
+			roles: []string{"developer", "assistant", "user", "assistant", "user"},
+			want:  [][]int{{1}, {2, 3}},
+		},
+		{
+			name:  "single question keeps everything",
+			roles: []string{"system", "user"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncationUnits(testConversation(tt.roles...))
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("truncationUnits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateMessagesAuto(t *testing.T) {
+	messages := testConversation("system", "user", "assistant", "user", "assistant", "tool", "assistant", "tool")
+
+	// Each message costs 10 tokens; dropping the first turn (2 messages) and
+	// the first tool call (2 messages) leaves 4 messages.
+	result, err := truncateMessages(messages, TruncationAuto, 40, countContentTokens)
+	if err != nil {
+		t.Fatalf("truncateMessages() error = %v", err)
+	}
+
+	if got, want := testRoles(result.messages), []string{"system", "user", "assistant", "tool"}; !slices.Equal(got, want) {
+		t.Errorf("kept roles = %v, want %v", got, want)
+	}
+	if result.messages[1]["content"] != "3" {
+		t.Errorf("kept user message = %v, want the last user message", result.messages[1]["content"])
+	}
+	if result.tokens != 40 || result.trimmed != 0 {
+		t.Errorf("result = (tokens %d, trimmed %d), want (40, 0)", result.tokens, result.trimmed)
+	}
+}
+
+func TestTruncateMessagesAutoReturnsMostTruncated(t *testing.T) {
+	messages := testConversation("system", "user", "assistant", "user")
+
+	result, err := truncateMessages(messages, TruncationAuto, 5, countContentTokens)
+	if err != nil {
+		t.Fatalf("truncateMessages() error = %v", err)
+	}
+
+	if got, want := testRoles(result.messages), []string{"system", "user"}; !slices.Equal(got, want) {
+		t.Errorf("kept roles = %v, want %v", got, want)
+	}
+	if result.tokens != 20 {
+		t.Errorf("tokens = %d, want 20", result.tokens)
+	}
+}
+
+func TestTruncateMessagesMiddleOut(t *testing.T) {
+	messages := testConversation("system", "user", "assistant", "tool")
+	messages[3]["content"] = strings.Repeat("a", 500) + strings.Repeat("z", 500)
+
+	measure := func(messages []D) (int, error) {
+		var n int
+		for _, msg := range messages {
+			n += utf8.RuneCountInString(msg["content"].(string))
+		}
+		return n, nil
+	}
+
+	result, err := truncateMessages(messages, TruncationMiddleOut, 600, measure)
+	if err != nil {
+		t.Fatalf("truncateMessages() error = %v", err)
+	}
+
+	if len(result.messages) != len(messages) || result.trimmed != 1 || result.tokens > 600 {
+		t.Fatalf("result = (%d messages, trimmed %d, tokens %d), want (4, 1, <= 600)", len(result.messages), result.trimmed, result.tokens)
+	}
+
+	content := result.messages[3]["content"].(string)
+	if !strings.HasPrefix(content, "aaa") || !strings.HasSuffix(content, "zzz") || !strings.Contains(content, "characters omitted") {
+		t.Errorf("trimmed content = %q, want head, omission note, and tail", content)
+	}
+	if messages[3]["content"] == content {
+		t.Error("truncateMessages() modified the caller's message")
+	}
+}
+
+func TestTruncateMessagesMiddleOutFallsBackToDroppingTurns(t *testing.T) {
+	messages := testConversation("user", "assistant", "tool", "user", "assistant", "tool")
+	messages[2]["content"] = strings.Repeat("x", 2000)
+	messages[5]["content"] = strings.Repeat("y", 2000)
+
+	measure := func(messages []D) (int, error) {
+		var n int
+		for _, msg := range messages {
+			n += len(msg["content"].(string))
+		}
+		return n, nil
+	}
+
+	result, err := truncateMessages(messages, TruncationMiddleOut, 400, measure)
+	if err != nil {
+		t.Fatalf("truncateMessages() error = %v", err)
+	}
+
+	if got, want := testRoles(result.messages), []string{"user", "assistant", "tool"}; !slices.Equal(got, want) {
+		t.Errorf("kept roles = %v, want %v", got, want)
+	}
+	if result.trimmed != 2 {
+		t.Errorf("trimmed = %d, want 2", result.trimmed)
+	}
+}
+
+// testConversation returns messages with the given roles whose content is the
+// message index.
+func testConversation(roles ...string) []D {
+	messages := make([]D, len(roles))
+	for i, role := range roles {
+		messages[i] = D{"role": role, "content": string(rune('0' + i))}
+	}
+	return messages
+}
+
+func testRoles(messages []D) []string {
+	roles := make([]string, len(messages))
+	for i, msg := range messages {
+		roles[i] = messageRole(msg)
+	}
+	return roles
+}
+
+func countContentTokens(messages []D) (int, error) {
+	return 10 * len(messages), nil
+}
@@ -79,6 +79,11 @@ var (
 	// queue wait and can stretch into the tens of seconds under load.
 	requestTTFTBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
 
+	// imageBuckets covers image generation, which ranges from a few
+	// seconds for distilled models on a GPU to several minutes for
+	// large images on CPU.
+	imageBuckets = []float64{1, 2, 5, 10, 20, 30, 60, 120, 300, 600}
+
 	// tpsBuckets covers per-request decode rates from very small models
 	// (~5 tps on CPU) to small quantized models on fast GPUs (~1000 tps).
 	tpsBuckets = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000}
@@ -144,7 +149,9 @@ type promMetrics struct {
 	chatRequestsTotal    *prometheus.CounterVec   // labels: model_id, status.
 	chatErrorsTotal      *prometheus.CounterVec   // labels: model_id, class.
 	chatRequestDuration  *prometheus.HistogramVec // labels: model_id.
-	chatQueueWaitSeconds *prometheus.HistogramVec // labels: model_id.
+	chatQueueWaitSeconds *prometheus.HistogramVec // labels: model_id, priority.
+	chatQueueDepth       *prometheus.GaugeVec     // labels: model_id, priority.
+	chatPreemptionsTotal *prometheus.CounterVec   // labels: model_id, priority.
 
 	// -------------------------------------------------------------------------
 	// Embedding/reranking request and sequence-batch metrics.
@@ -156,6 +163,13 @@ type promMetrics struct {
 	batchSeqItems            *prometheus.HistogramVec // labels: model_id, operation.
 	batchSeqBatchesTotal     *prometheus.CounterVec   // labels: model_id, operation, status.
 
+	// -------------------------------------------------------------------------
+	// Image generation metrics.
+
+	imageRequestsTotal   *prometheus.CounterVec   // labels: model_id, operation, status.
+	imageRequestDuration *prometheus.HistogramVec // labels: model_id, operation.
+	imagesGeneratedTotal *prometheus.CounterVec   // labels: model_id, operation.
+
 	// -------------------------------------------------------------------------
 	// IMC pure-hit snapshot-skip metrics.
 
@@ -336,7 +350,12 @@ func init() {
 			Help: "Chat completion errors by model_id and error class.",
 		}, []string{"model_id", "class"}),
 		chatRequestDuration:  newHistVec("chat_request_duration_seconds", "End-to-end chat request duration in seconds.", requestTTFTBuckets),
-		chatQueueWaitSeconds: newHistVec("chat_queue_wait_seconds", "Time spent waiting in the batch engine queue before being assigned a slot.", subSecondBuckets),
+		chatQueueWaitSeconds: newHistVec("chat_queue_wait_seconds", "Time spent waiting in the batch engine queue before being assigned a slot, by priority (low|normal|high).", subSecondBuckets, "priority"),
+		chatQueueDepth:       newGaugeVec("chat_queue_depth", "Chat requests waiting in the batch engine for a free slot, by priority (low|normal|high).", "priority"),
+		chatPreemptionsTotal: auto.NewCounterVec(prometheus.CounterOpts{
+			Name: "chat_preemptions_total",
+			Help: "Running chat generations paused to free a slot for a higher priority request, by model_id and the paused request's priority (low|normal|high).",
+		}, []string{"model_id", "priority"}),
 
 		inferenceRequestsTotal: auto.NewCounterVec(prometheus.CounterOpts{
 			Name: "inference_requests_total",
@@ -366,6 +385,20 @@ func init() {
 			Help: "Total native sequence batches by model_id, operation, and status (ok|error).",
 		}, []string{"model_id", "operation", "status"}),
 
+		imageRequestsTotal: auto.NewCounterVec(prometheus.CounterOpts{
+			Name: "image_requests_total",
+			Help: "Total image requests by model_id, operation (generation|edit), and status (ok|error|cancel).",
+		}, []string{"model_id", "operation", "status"}),
+		imageRequestDuration: auto.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "image_request_duration_seconds",
+			Help:    "Image request duration in seconds, including every generated image.",
+			Buckets: imageBuckets,
+		}, []string{"model_id", "operation"}),
+		imagesGeneratedTotal: auto.NewCounterVec(prometheus.CounterOpts{
+			Name: "images_generated_total",
+			Help: "Total images generated by model_id and operation (generation|edit).",
+		}, []string{"model_id", "operation"}),
+
 		imcSnapshotSkippedTotal: auto.NewCounterVec(prometheus.CounterOpts{
 			Name: "imc_snapshot_skipped_total",
 			Help: "Total IMC post-restore snapshots skipped on text-only exact pure hits.",
@@ -721,10 +754,22 @@ func ObserveChatRequestDuration(modelID string, d time.Duration) {
 	m.chatRequestDuration.WithLabelValues(normalizeModelID(modelID)).Observe(d.Seconds())
 }
 
-// ObserveChatQueueWait records the time a request spent in the batch
-// engine queue before being assigned to a slot.
-func ObserveChatQueueWait(modelID string, d time.Duration) {
-	m.chatQueueWaitSeconds.WithLabelValues(normalizeModelID(modelID)).Observe(d.Seconds())
+// ObserveChatQueueWait records the time a request of the given priority
+// spent in the batch engine queue before being assigned to a slot.
+func ObserveChatQueueWait(modelID, priority string, d time.Duration) {
+	m.chatQueueWaitSeconds.WithLabelValues(normalizeModelID(modelID), priority).Observe(d.Seconds())
+}
+
+// SetChatQueueDepth sets the number of chat requests of the given priority
+// waiting in the batch engine for a free slot.
+func SetChatQueueDepth(modelID, priority string, n int) {
+	m.chatQueueDepth.WithLabelValues(normalizeModelID(modelID), priority).Set(float64(n))
+}
+
+// AddChatPreemption records a running chat generation of the given priority
+// that was paused to free its slot for a higher priority request.
+func AddChatPreemption(modelID, priority string) {
+	m.chatPreemptionsTotal.WithLabelValues(normalizeModelID(modelID), priority).Inc()
 }
 
 // ObserveInferenceRequest records one completed embedding or reranking request.
@@ -761,6 +806,21 @@ func ObserveBatchSeqBatch(modelID, operation, status string, items int) {
 	}
 }
 
+// =============================================================================
+// Image generation helpers.
+
+// ObserveImageRequest records one completed image request. Operation
+// values are "generation" and "edit"; status values are "ok", "error",
+// and "cancel". Images is the number of images returned to the caller.
+func ObserveImageRequest(modelID, operation, status string, d time.Duration, images int) {
+	id := normalizeModelID(modelID)
+	m.imageRequestsTotal.WithLabelValues(id, operation, status).Inc()
+	m.imageRequestDuration.WithLabelValues(id, operation).Observe(d.Seconds())
+	if images > 0 {
+		m.imagesGeneratedTotal.WithLabelValues(id, operation).Add(float64(images))
+	}
+}
+
 // =============================================================================
 // IMC pure-hit snapshot-skip helpers.
 
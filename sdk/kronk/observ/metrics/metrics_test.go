@@ -83,6 +83,55 @@ func TestInferenceAndBatchSeqMetrics(t *testing.T) {
 	}
 }
 
+func TestImageMetrics(t *testing.T) {
+	const modelID = "metrics-test-image"
+
+	ObserveImageRequest(modelID, "generation", "ok", 3*time.Second, 2)
+	ObserveImageRequest(modelID, "generation", "error", time.Second, 0)
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		value  func(*dto.Metric) float64
+		want   float64
+	}{
+		{
+			name:   "image_requests_total",
+			labels: map[string]string{"model_id": modelID, "operation": "generation", "status": "ok"},
+			value:  func(m *dto.Metric) float64 { return m.GetCounter().GetValue() },
+			want:   1,
+		},
+		{
+			name:   "image_request_duration_seconds",
+			labels: map[string]string{"model_id": modelID, "operation": "generation"},
+			value:  func(m *dto.Metric) float64 { return float64(m.GetHistogram().GetSampleCount()) },
+			want:   2,
+		},
+		{
+			name:   "images_generated_total",
+			labels: map[string]string{"model_id": modelID, "operation": "generation"},
+			value:  func(m *dto.Metric) float64 { return m.GetCounter().GetValue() },
+			want:   2,
+		},
+	}
+
+	families, err := Gatherer().Gather()
+	if err != nil {
+		t.Fatalf("Gather: unexpected error: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metric := findMetric(families, tt.name, tt.labels)
+			if metric == nil {
+				t.Fatalf("metric %q with labels %v not found", tt.name, tt.labels)
+			}
+			if got := tt.value(metric); got != tt.want {
+				t.Errorf("value: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func findMetric(families []*dto.MetricFamily, name string, labels map[string]string) *dto.Metric {
 	for _, family := range families {
 		if family.GetName() != name {
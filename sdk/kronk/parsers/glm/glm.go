@@ -8,6 +8,7 @@ package glm
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/ardanlabs/kronk/sdk/kronk/applog"
@@ -58,6 +59,66 @@ func (Parser) ToolCall(_ context.Context, _ applog.Logger, buf string) []model.R
 	return parseGLM(buf)
 }
 
+// ToolCallGrammar returns the grammar root for one or more GLM tool calls.
+// Each call is written on one line because the tool-call parser reads one
+// call per line, so string values may not contain a newline.
+func (Parser) ToolCallGrammar(tg *model.ToolGrammar) (string, error) {
+	var calls []string
+	for _, toolName := range tg.Names() {
+		params, err := tg.Parameters(toolName)
+		if err != nil {
+			return "", err
+		}
+		if len(params) == 0 {
+			return "", fmt.Errorf("%w: tool %q declares no parameters, which GLM tool calls cannot express", model.ErrInvalidRequest, toolName)
+		}
+
+		args := make([]string, len(params))
+		for i, param := range params {
+			value := param.Value
+			switch {
+			case param.Raw != "":
+				value = param.Raw
+			case param.String:
+				value = tg.TextUntil("</arg_value>", "\n")
+			}
+
+			args[i] = fmt.Sprintf(`%s "<arg_value>" %s "</arg_value>"`, tg.Literal("<arg_key>"+param.Name+"</arg_key>"), value)
+		}
+
+		calls = append(calls, fmt.Sprintf(`%s %s "</tool_call>"`, tg.Literal("<tool_call>"+toolName), glmArguments(tg, params, args)))
+	}
+
+	call := tg.Rule("tool-call", tg.Alternatives(calls))
+
+	return tg.Repeat(call, `"\n"`), nil
+}
+
+// glmArguments returns the rule text for a call's arguments. The parser
+// rejects a call without arguments, so when no parameter is required the
+// call must still write at least one of them.
+func glmArguments(tg *model.ToolGrammar, params []model.GrammarParameter, args []string) string {
+	var required, optional []string
+	for i, param := range params {
+		if param.Required {
+			required = append(required, args[i])
+			continue
+		}
+		optional = append(optional, fmt.Sprintf("( %s )?", args[i]))
+	}
+
+	if len(required) > 0 {
+		return strings.Join(append(required, optional...), " ")
+	}
+
+	options := make([]string, len(args))
+	for i := range args {
+		options[i] = strings.Join(append([]string{args[i]}, optional[i+1:]...), " ")
+	}
+
+	return tg.Alternatives(options)
+}
+
 // containsGLMMarkers reports whether a chat template carries distinctive
 // GLM tool-call tokens. The <arg_key>/<arg_value> pair is unique to GLM's
 // tool-call format and unlikely to appear in any other lineage's template.
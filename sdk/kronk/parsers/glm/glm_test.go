@@ -272,3 +272,39 @@ func TestParser_Reset(t *testing.T) {
 		t.Errorf("after Reset got %+v", got)
 	}
 }
+
+// TestParser_ToolCallGrammar checks that each call stays on one line with
+// <arg_key>/<arg_value> pairs, required arguments first.
+func TestParser_ToolCallGrammar(t *testing.T) {
+	tools := []model.D{{
+		"type": "function",
+		"function": model.D{
+			"name": "get_weather",
+			"parameters": model.D{
+				"type": "object",
+				"properties": model.D{
+					"location": model.D{"type": "string"},
+					"unit":     model.D{"type": "string", "enum": []any{"c", "f"}},
+					"days":     model.D{"type": "integer"},
+				},
+				"required": []string{"location"},
+			},
+		},
+	}}
+
+	grammar, err := model.BuildToolCallGrammar(Parser{}, tools, true)
+	if err != nil {
+		t.Fatalf("BuildToolCallGrammar: %v", err)
+	}
+
+	for _, want := range []string{
+		`root ::= tool-call ( "\n" tool-call )*`,
+		`tool-call ::= "<tool_call>get_weather" "<arg_key>location</arg_key>" "<arg_value>" until-arg-value "</arg_value>"`,
+		`( "<arg_key>unit</arg_key>" "<arg_value>" ( "c" | "f" ) "</arg_value>" )? "</tool_call>"`,
+		`[^\u000A/<>_aeglruv] until-arg-value`,
+	} {
+		if !strings.Contains(grammar, want) {
+			t.Errorf("grammar missing %q:\n%s", want, grammar)
+		}
+	}
+}
@@ -13,6 +13,7 @@ package gpt
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/ardanlabs/kronk/sdk/kronk/applog"
@@ -52,6 +53,24 @@ func (Parser) ToolCall(ctx context.Context, log applog.Logger, buf string) []mod
 	return parseGPTToolCall(ctx, log, buf)
 }
 
+// ToolCallGrammar returns the grammar root for a Harmony commentary-channel
+// tool call. Harmony ends a tool call with <|call|>, which is an
+// end-of-generation token, so the grammar allows exactly one call and the
+// model closes it once the arguments are complete.
+func (Parser) ToolCallGrammar(tg *model.ToolGrammar) (string, error) {
+	var calls []string
+	for _, toolName := range tg.Names() {
+		args, err := tg.Arguments(toolName)
+		if err != nil {
+			return "", err
+		}
+
+		calls = append(calls, fmt.Sprintf(`%s ( " "? "<|constrain|>json" )? "<|message|>" %s`, tg.Literal("<|channel|>commentary to=functions."+toolName), args))
+	}
+
+	return tg.Rule("tool-call", tg.Alternatives(calls)), nil
+}
+
 // containsHarmonyMarkers reports whether a chat template carries the
 // distinctive GPT-OSS Harmony tokens. Any one is sufficient because no
 // other parser uses these exact tokens.
@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/ardanlabs/kronk/sdk/kronk/model"
 )
@@ -220,6 +221,53 @@ func TestParser_ToolCallFlushMultipleAndReset(t *testing.T) {
 	}
 }
 
+func TestParser_ToolCallArgumentDeltas(t *testing.T) {
+	c := Parser{}.NewStateMachine()
+	streamer := c.(model.ToolCallDeltaStreamer)
+	c.(model.ToolCallArgumentStreamer).StreamToolCallArguments()
+
+	var deltas []model.ResponseToolCallDelta
+	for _, token := range []string{
+		"<|start|>assistant",
+		"<|channel|>commentary to=functions.get_weather",
+		"<|constrain|>json",
+		"<|message|>",
+		` {"location": "Lon`,
+		"don \xc3",
+		"\xa9 <|call|> {x} \\\"}\"",
+		"}",
+	} {
+		if _, eog := c.Classify(token); eog {
+			t.Fatalf("Classify(%q): got EOG before the call completed", token)
+		}
+		deltas = append(deltas, streamer.ToolCallDeltas()...)
+	}
+	if _, eog := c.Classify("<|call|>"); !eog {
+		t.Fatal("Classify(<|call|>): want EOG")
+	}
+	deltas = append(deltas, streamer.ToolCallDeltas()...)
+
+	if len(deltas) == 0 || deltas[0].ID == "" || deltas[0].Function.Name != "get_weather" {
+		t.Fatalf("deltas: got %+v, want a get_weather start first", deltas)
+	}
+
+	var arguments string
+	for _, delta := range deltas[1:] {
+		if delta.ID != "" || delta.Function.Name != "" || delta.Type != "" || delta.Index != 0 {
+			t.Errorf("argument delta: got %+v, want only index and arguments", delta)
+		}
+		if !utf8.ValidString(delta.Function.Arguments) {
+			t.Errorf("argument delta: got invalid UTF-8 %q", delta.Function.Arguments)
+		}
+		arguments += delta.Function.Arguments
+	}
+
+	want := "{\"location\": \"London \u00e9 <|call|> {x} \\\"}\"}"
+	if arguments != want {
+		t.Errorf("arguments: got %q, want %q", arguments, want)
+	}
+}
+
 // TestParser_RecoversFromMissingEnd covers the resilience path where the
 // model emits <|start|> or <|channel|> without first closing the previous
 // block with <|end|>.
@@ -428,3 +476,37 @@ func TestNew_ClaimsHarmonyTemplate(t *testing.T) {
 		})
 	}
 }
+
+// TestParser_ToolCallGrammar checks that the grammar allows exactly one
+// commentary-channel call, since <|call|> ends generation.
+func TestParser_ToolCallGrammar(t *testing.T) {
+	tools := []model.D{{
+		"type": "function",
+		"function": model.D{
+			"name": "get_weather",
+			"parameters": model.D{
+				"type": "object",
+				"properties": model.D{
+					"location": model.D{"type": "string"},
+					"unit":     model.D{"type": "string", "enum": []any{"c", "f"}},
+					"days":     model.D{"type": "integer"},
+				},
+				"required": []string{"location"},
+			},
+		},
+	}}
+
+	grammar, err := model.BuildToolCallGrammar(Parser{}, tools, true)
+	if err != nil {
+		t.Fatalf("BuildToolCallGrammar: %v", err)
+	}
+
+	for _, want := range []string{
+		"root ::= tool-call\n",
+		`tool-call ::= "<|channel|>commentary to=functions.get_weather" ( " "? "<|constrain|>json" )? "<|message|>" "{" ws`,
+	} {
+		if !strings.Contains(grammar, want) {
+			t.Errorf("grammar missing %q:\n%s", want, grammar)
+		}
+	}
+}
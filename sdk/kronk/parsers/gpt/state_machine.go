@@ -25,6 +25,10 @@ type stateMachine struct {
 	results           []model.Result
 	toolCallDeltas    []model.ResponseToolCallDelta
 	startedCalls      []model.ResponseToolCallDelta
+	streamArgs        bool
+	deltaStarted      bool
+	deltaArgsAt       int
+	deltaArgsSent     int
 }
 
 // Reset returns the stateMachine to its initial state.
@@ -69,6 +73,7 @@ func (sm *stateMachine) consume(flush bool) bool {
 		// Harmony-like text in a tool JSON string is payload, not framing.
 		if sm.status == model.ChannelTool && sm.collecting && toolMarkerIsData(sm.toolCallBuf.String(), marker) {
 			sm.toolCallBuf.WriteString(marker)
+			sm.updateArgumentDeltas()
 			sm.inputBuf = sm.inputBuf[len(marker):]
 			continue
 		}
@@ -108,6 +113,7 @@ func (sm *stateMachine) consumeText(text string) {
 	}
 	if sm.status == model.ChannelTool {
 		sm.toolCallBuf.WriteString(text)
+		sm.updateArgumentDeltas()
 		return
 	}
 	if sm.status != model.ChannelNone {
@@ -256,6 +262,7 @@ func (sm *stateMachine) beginMessage() {
 		sm.toolCallBuf.WriteString(sm.toolFuncName)
 		sm.toolCallBuf.WriteByte(' ')
 		sm.toolCallBuf.WriteString(messageMarker)
+		sm.startToolCallDelta(sm.toolFuncName)
 		sm.toolFuncName = ""
 		sm.toolChannel = false
 	}
@@ -293,6 +300,7 @@ func (sm *stateMachine) queueToolCall() {
 	}
 	sm.results = append(sm.results, model.Result{Channel: model.ChannelTool, Content: sm.toolCallBuf.String()})
 	sm.toolCallBuf.Reset()
+	sm.deltaStarted = false
 }
 
 func (sm *stateMachine) nextResult() model.Result {
@@ -329,7 +337,7 @@ func (sm *stateMachine) Flush() model.Result {
 	return sm.nextResult()
 }
 
-// ToolCallDeltas drains tool-call identity deltas produced by Classify.
+// ToolCallDeltas drains tool-call deltas produced by Classify.
 func (sm *stateMachine) ToolCallDeltas() []model.ResponseToolCallDelta {
 	deltas := sm.toolCallDeltas
 	sm.toolCallDeltas = nil
@@ -339,6 +347,56 @@ func (sm *stateMachine) ToolCallDeltas() []model.ResponseToolCallDelta {
 // StartedToolCalls returns identities emitted during the current request.
 func (sm *stateMachine) StartedToolCalls() []model.ResponseToolCallDelta { return sm.startedCalls }
 
+// StreamToolCallArguments makes ToolCallDeltas return a call's identity once
+// its frame's message starts and its arguments as the model writes them.
+// Without it identities stay deferred, since only <|call|> makes a frame
+// executable.
+func (sm *stateMachine) StreamToolCallArguments() { sm.streamArgs = true }
+
+func (sm *stateMachine) startToolCallDelta(name string) {
+	if !sm.streamArgs || !safeFunctionName(name) {
+		return
+	}
+
+	delta := model.ResponseToolCallDelta{
+		ID:    newToolCallID(),
+		Index: len(sm.startedCalls),
+		Type:  "function",
+		Function: model.ResponseToolCallDeltaFunction{
+			Name: name,
+		},
+	}
+	sm.toolCallDeltas = append(sm.toolCallDeltas, delta)
+	sm.startedCalls = append(sm.startedCalls, delta)
+	sm.deltaStarted = true
+	sm.deltaArgsAt = sm.toolCallBuf.Len()
+	sm.deltaArgsSent = 0
+}
+
+// updateArgumentDeltas emits the arguments object text written after the
+// frame's message marker since the previous call.
+func (sm *stateMachine) updateArgumentDeltas() {
+	if !sm.deltaStarted {
+		return
+	}
+
+	args := sm.toolCallBuf.String()[sm.deltaArgsAt:]
+	at := len(args) - len(strings.TrimLeft(args, " \t\r\n"))
+
+	n, _ := model.JSONObjectPrefix(args[at:])
+	if n <= sm.deltaArgsSent {
+		return
+	}
+
+	sm.toolCallDeltas = append(sm.toolCallDeltas, model.ResponseToolCallDelta{
+		Index: len(sm.startedCalls) - 1,
+		Function: model.ResponseToolCallDeltaFunction{
+			Arguments: args[at+sm.deltaArgsSent : at+n],
+		},
+	})
+	sm.deltaArgsSent = n
+}
+
 func nextHarmonyMarker(s string) (int, string) {
 	best := -1
 	var marker string
@@ -6,6 +6,7 @@ package llama
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/ardanlabs/kronk/sdk/kronk/applog"
@@ -44,3 +45,15 @@ func (Parser) NewStateMachine() model.StateMachine {
 func (Parser) ToolCall(ctx context.Context, log applog.Logger, buf string) []model.ResponseToolCall {
 	return parseJSON(ctx, log, buf)
 }
+
+// ToolCallGrammar returns the grammar root for a single name-and-parameters
+// JSON tool call, optionally prefixed with <|python_tag|>. The envelope
+// carries one call, so parallel calls are not expressed.
+func (Parser) ToolCallGrammar(tg *model.ToolGrammar) (string, error) {
+	envelope, err := tg.JSONEnvelope("parameters")
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("( %s ws )? %s", tg.Literal(pythonTag), tg.Rule("tool-call", envelope)), nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/ardanlabs/kronk/sdk/kronk/model"
 )
@@ -115,6 +116,76 @@ func TestBareJSONRequiresTheCompleteAnswer(t *testing.T) {
 	}
 }
 
+func TestToolCallArgumentDeltas(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []string
+		want   string
+	}{
+		{
+			name:   "python tag",
+			tokens: []string{"<|python", "_tag|>", `{"name": "get_weather", "parameters": `, `{"location": "Lon`, "don \xc3", "\xa9 {x} \\\"}\"}", "}"},
+			want:   "{\"location\": \"London \u00e9 {x} \\\"}\"}",
+		},
+		{
+			name:   "bare JSON",
+			tokens: []string{" {\"parameters\": {\"days\": [1,", " 2]}, \"name\": \"get_", "weather\"}"},
+			want:   `{"days": [1, 2]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := &stateMachine{}
+			sm.Reset()
+			sm.SetTools(testTools())
+			sm.StreamToolCallArguments()
+
+			var deltas []model.ResponseToolCallDelta
+			for _, token := range tt.tokens {
+				if got, _ := sm.Classify(token); got != (model.Result{}) {
+					t.Fatalf("Classify(%q): got %+v, want buffered", token, got)
+				}
+				deltas = append(deltas, sm.ToolCallDeltas()...)
+			}
+
+			if len(deltas) == 0 || deltas[0].ID == "" || deltas[0].Function.Name != "get_weather" {
+				t.Fatalf("deltas: got %+v, want a get_weather start first", deltas)
+			}
+
+			var arguments string
+			for _, delta := range deltas[1:] {
+				if delta.ID != "" || delta.Function.Name != "" || delta.Type != "" || delta.Index != 0 {
+					t.Errorf("argument delta: got %+v, want only index and arguments", delta)
+				}
+				if !utf8.ValidString(delta.Function.Arguments) {
+					t.Errorf("argument delta: got invalid UTF-8 %q", delta.Function.Arguments)
+				}
+				arguments += delta.Function.Arguments
+			}
+
+			if arguments != tt.want {
+				t.Errorf("arguments: got %q, want %q", arguments, tt.want)
+			}
+			if got := sm.Flush(); got.Channel != model.ChannelTool {
+				t.Errorf("Flush: got %+v, want the tool call", got)
+			}
+		})
+	}
+}
+
+func TestToolCallArgumentDeltasRequireDeclaredTool(t *testing.T) {
+	sm := &stateMachine{}
+	sm.Reset()
+	sm.SetTools(testTools())
+	sm.StreamToolCallArguments()
+
+	sm.Classify(`<|python_tag|>{"name": "get_time", "parameters": {"zone": "UTC"}}`)
+	if deltas := sm.ToolCallDeltas(); len(deltas) != 0 {
+		t.Errorf("ToolCallDeltas: got %+v, want none for an undeclared tool", deltas)
+	}
+}
+
 func TestLeadingWhitespaceBeforePythonTag(t *testing.T) {
 	sm := &stateMachine{status: model.ChannelAnswer}
 	sm.SetTools(testTools())
@@ -380,3 +451,37 @@ func TestMarkedUnknownAndIncomplete(t *testing.T) {
 		})
 	}
 }
+
+// TestParser_ToolCallGrammar checks the name-and-parameters envelope with an
+// optional <|python_tag|> prefix.
+func TestParser_ToolCallGrammar(t *testing.T) {
+	tools := []model.D{{
+		"type": "function",
+		"function": model.D{
+			"name": "get_weather",
+			"parameters": model.D{
+				"type": "object",
+				"properties": model.D{
+					"location": model.D{"type": "string"},
+					"unit":     model.D{"type": "string", "enum": []any{"c", "f"}},
+					"days":     model.D{"type": "integer"},
+				},
+				"required": []string{"location"},
+			},
+		},
+	}}
+
+	grammar, err := model.BuildToolCallGrammar(Parser{}, tools, false)
+	if err != nil {
+		t.Fatalf("BuildToolCallGrammar: %v", err)
+	}
+
+	for _, want := range []string{
+		`root ::= ( "<|python_tag|>" ws )? tool-call`,
+		`"\"" "parameters" "\"" ws ":" ws "{" ws "\"" "location" "\"" ws ":" ws string`,
+	} {
+		if !strings.Contains(grammar, want) {
+			t.Errorf("grammar missing %q:\n%s", want, grammar)
+		}
+	}
+}
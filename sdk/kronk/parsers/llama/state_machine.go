@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"strings"
 
+	"uuid"
+
 	"github.com/ardanlabs/kronk/sdk/kronk/model"
 )
 
@@ -23,6 +25,13 @@ type stateMachine struct {
 	answerStarted bool
 	marked        bool
 	queue         []model.Result
+
+	// OpenAI-compatible deltas for a declared call, produced only once
+	// argument streaming is enabled.
+	toolCallDeltas []model.ResponseToolCallDelta
+	startedCalls   []model.ResponseToolCallDelta
+	streamArgs     bool
+	deltaArgsSent  int
 }
 
 // Reset returns the state machine to its initial state.
@@ -33,6 +42,10 @@ func (sm *stateMachine) Reset() {
 	sm.answerStarted = false
 	sm.marked = false
 	sm.queue = nil
+	sm.toolCallDeltas = nil
+	sm.startedCalls = nil
+	sm.streamArgs = false
+	sm.deltaArgsSent = 0
 }
 
 // SetTools supplies the request's declared tools.
@@ -48,6 +61,9 @@ func (sm *stateMachine) SetTools(tools []model.D) {
 // Classify classifies one decoded piece.
 func (sm *stateMachine) Classify(content string) (model.Result, bool) {
 	sm.process(content)
+	if sm.streamArgs {
+		sm.updateToolCallDeltas()
+	}
 	if len(sm.queue) == 0 {
 		return model.Result{}, false
 	}
@@ -195,6 +211,165 @@ func (sm *stateMachine) Flush() model.Result {
 	return model.Result{Channel: model.ChannelAnswer, Content: content}
 }
 
+// ToolCallDeltas drains tool-call deltas produced by the most recent Classify
+// call.
+func (sm *stateMachine) ToolCallDeltas() []model.ResponseToolCallDelta {
+	deltas := sm.toolCallDeltas
+	sm.toolCallDeltas = nil
+	return deltas
+}
+
+// StartedToolCalls returns the tool-call identities emitted during the current
+// request.
+func (sm *stateMachine) StartedToolCalls() []model.ResponseToolCallDelta {
+	return sm.startedCalls
+}
+
+// StreamToolCallArguments makes ToolCallDeltas return the identity of a
+// candidate call once its name is written and declared, then its parameters
+// as the model writes them. Without it nothing is reported before EOS, when
+// the complete output decides whether the candidate is a call.
+func (sm *stateMachine) StreamToolCallArguments() {
+	sm.streamArgs = true
+}
+
+func (sm *stateMachine) updateToolCallDeltas() {
+	if sm.answerStarted || sm.status == model.ChannelReasoning {
+		return
+	}
+
+	content := strings.TrimLeft(sm.pending.String(), " \t\r\n")
+	if sm.marked {
+		content = strings.TrimLeft(strings.TrimPrefix(content, pythonTag), " \t\r\n")
+	}
+	if content == "" || content[0] != '{' {
+		return
+	}
+
+	if len(sm.startedCalls) == 0 {
+		name, ok := envelopePrefixName(content)
+		if !ok {
+			return
+		}
+		if _, declared := sm.toolNames[name]; !declared {
+			return
+		}
+
+		delta := model.ResponseToolCallDelta{
+			ID:   "call_" + uuid.New().String(),
+			Type: "function",
+			Function: model.ResponseToolCallDeltaFunction{
+				Name: name,
+			},
+		}
+		sm.toolCallDeltas = append(sm.toolCallDeltas, delta)
+		sm.startedCalls = append(sm.startedCalls, delta)
+	}
+
+	start, ok := jsonFieldValueStart(content, "parameters")
+	if !ok || start >= len(content) {
+		return
+	}
+
+	n, _ := model.JSONObjectPrefix(content[start:])
+	if n <= sm.deltaArgsSent {
+		return
+	}
+
+	sm.toolCallDeltas = append(sm.toolCallDeltas, model.ResponseToolCallDelta{
+		Function: model.ResponseToolCallDeltaFunction{
+			Arguments: content[start+sm.deltaArgsSent : start+n],
+		},
+	})
+	sm.deltaArgsSent = n
+}
+
+// envelopePrefixName returns the name of an envelope that may still be
+// incomplete, once its name string has been written.
+func envelopePrefixName(content string) (string, bool) {
+	start, ok := jsonFieldValueStart(content, "name")
+	if !ok || start >= len(content) || content[start] != '"' {
+		return "", false
+	}
+
+	end, ok := jsonStringEnd(content, start)
+	if !ok {
+		return "", false
+	}
+
+	var name string
+	if err := json.Unmarshal([]byte(content[start:end]), &name); err != nil {
+		return "", false
+	}
+
+	return name, name != ""
+}
+
+// jsonFieldValueStart returns where the value of a top-level field starts in
+// a JSON object that may still be incomplete.
+func jsonFieldValueStart(content string, field string) (int, bool) {
+	depth := 0
+	for i := 0; i < len(content); {
+		switch content[i] {
+		case '{', '[':
+			depth++
+			i++
+		case '}', ']':
+			depth--
+			i++
+		case '"':
+			end, ok := jsonStringEnd(content, i)
+			if !ok {
+				return 0, false
+			}
+			if depth != 1 {
+				i = end
+				continue
+			}
+
+			var key string
+			if err := json.Unmarshal([]byte(content[i:end]), &key); err != nil {
+				return 0, false
+			}
+			i = skipJSONWhitespace(content, end)
+			if i >= len(content) || content[i] != ':' {
+				continue
+			}
+			i = skipJSONWhitespace(content, i+1)
+			if key == field {
+				return i, true
+			}
+		default:
+			i++
+		}
+	}
+
+	return 0, false
+}
+
+func jsonStringEnd(content string, start int) (int, bool) {
+	escape := false
+	for i := start + 1; i < len(content); i++ {
+		switch {
+		case escape:
+			escape = false
+		case content[i] == '\\':
+			escape = true
+		case content[i] == '"':
+			return i + 1, true
+		}
+	}
+
+	return 0, false
+}
+
+func skipJSONWhitespace(content string, i int) int {
+	for i < len(content) && strings.IndexByte(" \t\r\n", content[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
 func envelopeName(content string) (string, bool) {
 	var function model.ResponseToolCallFunction
 	if unmarshalFunction(content, &function) != nil {
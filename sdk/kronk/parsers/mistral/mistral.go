@@ -11,6 +11,7 @@ package mistral
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/ardanlabs/kronk/sdk/kronk/applog"
@@ -86,6 +87,24 @@ func (Parser) ToolCall(ctx context.Context, log applog.Logger, buf string) []mod
 	return parseMistral(ctx, log, buf)
 }
 
+// ToolCallGrammar returns the grammar root for one or more tool calls in the
+// [TOOL_CALLS]name[ARGS]{...} format.
+func (Parser) ToolCallGrammar(tg *model.ToolGrammar) (string, error) {
+	var calls []string
+	for _, toolName := range tg.Names() {
+		args, err := tg.Arguments(toolName)
+		if err != nil {
+			return "", err
+		}
+
+		calls = append(calls, fmt.Sprintf("%s %s %s %s", tg.Literal(toolCallsMarker), tg.Literal(toolName), tg.Literal(argsMarker), args))
+	}
+
+	call := tg.Rule("tool-call", tg.Alternatives(calls))
+
+	return tg.Repeat(call, ""), nil
+}
+
 // AdjustParams coerces request Params into values the model's chat template
 // will accept. For templates that restrict reasoning_effort to "none" or
 // "high" (Mistral Medium 3.5+), any other explicit value is coerced to "high"
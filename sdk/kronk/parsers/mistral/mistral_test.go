@@ -3,6 +3,7 @@ package mistral
 import (
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/ardanlabs/kronk/sdk/kronk/model"
 )
@@ -177,6 +178,56 @@ func TestParser_MultipleToolCallsAndReset(t *testing.T) {
 	}
 }
 
+func TestParser_ToolCallArgumentDeltas(t *testing.T) {
+	c := Parser{}.NewStateMachine()
+	streamer := c.(model.ToolCallDeltaStreamer)
+	c.(model.ToolCallArgumentStreamer).StreamToolCallArguments()
+
+	var deltas []model.ResponseToolCallDelta
+	for _, token := range []string{
+		"[TOOL_CALLS]",
+		"get_weather[AR",
+		"GS] ",
+		`{"location": "Lon`,
+		"don \xc3",
+		"\xa9 [TOOL_CALLS]x[ARGS]{} \\\"}\"",
+		"}",
+		"[TOOL_CALLS]get_time[ARGS]{}",
+	} {
+		if got, _ := c.Classify(token); got != (model.Result{}) {
+			t.Fatalf("Classify(%q): got %+v, want buffered", token, got)
+		}
+		deltas = append(deltas, streamer.ToolCallDeltas()...)
+	}
+
+	var names [2]string
+	var arguments [2]string
+	for _, delta := range deltas {
+		if delta.ID != "" {
+			if arguments[delta.Index] != "" {
+				t.Errorf("call %d: start delta after argument deltas", delta.Index)
+			}
+			names[delta.Index] = delta.Function.Name
+			continue
+		}
+		if delta.Function.Name != "" || delta.Type != "" {
+			t.Errorf("argument delta: got %+v, want only index and arguments", delta)
+		}
+		if !utf8.ValidString(delta.Function.Arguments) {
+			t.Errorf("argument delta: got invalid UTF-8 %q", delta.Function.Arguments)
+		}
+		arguments[delta.Index] += delta.Function.Arguments
+	}
+
+	if want := [2]string{"get_weather", "get_time"}; names != want {
+		t.Errorf("names: got %q, want %q", names, want)
+	}
+	want := [2]string{"{\"location\": \"London \u00e9 [TOOL_CALLS]x[ARGS]{} \\\"}\"}", "{}"}
+	if arguments != want {
+		t.Errorf("arguments: got %q, want %q", arguments, want)
+	}
+}
+
 func TestParser_ToolMarkerInsideArgumentsIsNotActivity(t *testing.T) {
 	c := Parser{}.NewStateMachine()
 
@@ -333,3 +384,37 @@ func TestParser_WhitespaceOnlyEOSIsAnswer(t *testing.T) {
 		t.Fatalf("Flush: got %+v, want empty repeated flush", got)
 	}
 }
+
+// TestParser_ToolCallGrammar checks that calls follow the
+// [TOOL_CALLS]name[ARGS]{...} format and may repeat.
+func TestParser_ToolCallGrammar(t *testing.T) {
+	tools := []model.D{{
+		"type": "function",
+		"function": model.D{
+			"name": "get_weather",
+			"parameters": model.D{
+				"type": "object",
+				"properties": model.D{
+					"location": model.D{"type": "string"},
+					"unit":     model.D{"type": "string", "enum": []any{"c", "f"}},
+					"days":     model.D{"type": "integer"},
+				},
+				"required": []string{"location"},
+			},
+		},
+	}}
+
+	grammar, err := model.BuildToolCallGrammar(Parser{}, tools, true)
+	if err != nil {
+		t.Fatalf("BuildToolCallGrammar: %v", err)
+	}
+
+	for _, want := range []string{
+		"root ::= ( tool-call )+",
+		`tool-call ::= "[TOOL_CALLS]" "get_weather" "[ARGS]" "{" ws "\"" "location" "\"" ws ":" ws string`,
+	} {
+		if !strings.Contains(grammar, want) {
+			t.Errorf("grammar missing %q:\n%s", want, grammar)
+		}
+	}
+}
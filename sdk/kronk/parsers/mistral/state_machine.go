@@ -14,6 +14,17 @@ type stateMachine struct {
 	toolCallBuf strings.Builder
 	inToolCall  bool
 	output      []model.Result
+
+	// OpenAI-compatible deltas for the calls being written, produced only
+	// once argument streaming is enabled. deltaCursor is where the first
+	// call not yet completely streamed starts in toolCallBuf.
+	toolCallDeltas []model.ResponseToolCallDelta
+	startedCalls   []model.ResponseToolCallDelta
+	streamArgs     bool
+	deltaCursor    int
+	deltaStarted   bool
+	deltaArgsAt    int
+	deltaArgsSent  int
 }
 
 var streamMarkers = []string{toolCallsMarker, "<think>", "</think>", "[THINK]", "[/THINK]"}
@@ -26,6 +37,13 @@ func (sm *stateMachine) Reset() {
 	sm.toolCallBuf.Reset()
 	sm.inToolCall = false
 	sm.output = nil
+	sm.toolCallDeltas = nil
+	sm.startedCalls = nil
+	sm.streamArgs = false
+	sm.deltaCursor = 0
+	sm.deltaStarted = false
+	sm.deltaArgsAt = 0
+	sm.deltaArgsSent = 0
 }
 
 // Classify classifies a single decoded chunk.
@@ -36,6 +54,9 @@ func (sm *stateMachine) Classify(content string) (model.Result, bool) {
 		sm.pending.WriteString(content)
 		sm.scan()
 	}
+	if sm.inToolCall && sm.streamArgs {
+		sm.updateToolCallDeltas()
+	}
 	return sm.popOutput(), false
 }
 
@@ -97,6 +118,81 @@ func (sm *stateMachine) popOutput() model.Result {
 	return result
 }
 
+// ToolCallDeltas drains tool-call deltas produced by the most recent Classify
+// call.
+func (sm *stateMachine) ToolCallDeltas() []model.ResponseToolCallDelta {
+	deltas := sm.toolCallDeltas
+	sm.toolCallDeltas = nil
+	return deltas
+}
+
+// StartedToolCalls returns the tool-call identities emitted during the current
+// request.
+func (sm *stateMachine) StartedToolCalls() []model.ResponseToolCallDelta {
+	return sm.startedCalls
+}
+
+// StreamToolCallArguments makes ToolCallDeltas return each call's identity
+// once its [ARGS] marker is written, then its arguments as the model writes
+// them. Without it nothing is reported before EOS.
+func (sm *stateMachine) StreamToolCallArguments() {
+	sm.streamArgs = true
+}
+
+func (sm *stateMachine) updateToolCallDeltas() {
+	content := sm.toolCallBuf.String()
+	for {
+		if !sm.deltaStarted {
+			cursor := skipASCIIWhitespace(content, sm.deltaCursor)
+			if !strings.HasPrefix(content[cursor:], toolCallsMarker) {
+				return
+			}
+			cursor += len(toolCallsMarker)
+
+			argsOffset := strings.Index(content[cursor:], argsMarker)
+			if argsOffset < 0 {
+				return
+			}
+			name := strings.Trim(content[cursor:cursor+argsOffset], " \t\r\n")
+			if name == "" {
+				return
+			}
+
+			delta := model.ResponseToolCallDelta{
+				ID:    newToolCallID(),
+				Index: len(sm.startedCalls),
+				Type:  "function",
+				Function: model.ResponseToolCallDeltaFunction{
+					Name: name,
+				},
+			}
+			sm.toolCallDeltas = append(sm.toolCallDeltas, delta)
+			sm.startedCalls = append(sm.startedCalls, delta)
+			sm.deltaStarted = true
+			sm.deltaArgsAt = cursor + argsOffset + len(argsMarker)
+			sm.deltaArgsSent = 0
+		}
+
+		at := skipASCIIWhitespace(content, sm.deltaArgsAt)
+		n, complete := model.JSONObjectPrefix(content[at:])
+		if n > sm.deltaArgsSent {
+			sm.toolCallDeltas = append(sm.toolCallDeltas, model.ResponseToolCallDelta{
+				Index: len(sm.startedCalls) - 1,
+				Function: model.ResponseToolCallDeltaFunction{
+					Arguments: content[at+sm.deltaArgsSent : at+n],
+				},
+			})
+			sm.deltaArgsSent = n
+		}
+		if !complete {
+			return
+		}
+
+		sm.deltaCursor = at + n
+		sm.deltaStarted = false
+	}
+}
+
 func nextMarker(content string) (int, string) {
 	at := -1
 	marker := ""
@@ -12,6 +12,7 @@ package qwen
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/ardanlabs/kronk/sdk/kronk/applog"
@@ -22,7 +23,11 @@ import (
 const name = "qwen"
 
 // Parser implements model.Parser for Qwen.
-type Parser struct{}
+type Parser struct {
+	// xml is set when the chat template declares the direct-XML tool-call
+	// format, which the tool-call grammar then follows.
+	xml bool
+}
 
 // New returns a Parser value if the fingerprint indicates a Qwen model,
 // otherwise returns false. Detection is layered: GGUF
@@ -31,19 +36,21 @@ type Parser struct{}
 // markers (<function=, <parameter=) is the next, and the model name
 // substring is a last-resort legacy fallback.
 func New(fp model.Fingerprint) (model.Parser, bool) {
+	p := Parser{xml: strings.Contains(fp.ChatTemplate, "<function=")}
+
 	// 1. GGUF architecture prefix.
 	if strings.HasPrefix(strings.ToLower(fp.Architecture), "qwen") {
-		return Parser{}, true
+		return p, true
 	}
 
 	// 2. Chat template markers distinctive to Qwen tool calls.
 	if containsQwenMarkers(fp.ChatTemplate) {
-		return Parser{}, true
+		return p, true
 	}
 
 	// 3. Model name fallback.
 	if strings.Contains(strings.ToLower(fp.ModelName), "qwen") {
-		return Parser{}, true
+		return p, true
 	}
 
 	return Parser{}, false
@@ -87,6 +94,56 @@ func (Parser) ToolCallWithSchema(ctx context.Context, log applog.Logger, buf str
 	return toolCalls
 }
 
+// ToolCallGrammar returns the grammar root for one or more Qwen tool calls,
+// in the direct-XML format when the chat template uses it and in the JSON
+// envelope otherwise. Direct-XML string arguments are written raw, as the
+// template renders them; other arguments are JSON.
+func (p Parser) ToolCallGrammar(tg *model.ToolGrammar) (string, error) {
+	if !p.xml {
+		envelope, err := tg.JSONEnvelope("arguments")
+		if err != nil {
+			return "", err
+		}
+
+		call := tg.Rule("tool-call", fmt.Sprintf(`"<tool_call>" ws %s ws "</tool_call>"`, envelope))
+
+		return tg.Repeat(call, "ws"), nil
+	}
+
+	var functions []string
+	for _, toolName := range tg.Names() {
+		params, err := tg.Parameters(toolName)
+		if err != nil {
+			return "", err
+		}
+
+		var b strings.Builder
+		b.WriteString(tg.Literal("<function=" + toolName + ">\n"))
+		for _, param := range params {
+			value := param.Value
+			switch {
+			case param.Raw != "":
+				value = param.Raw
+			case param.String:
+				value = tg.TextUntil("\n</parameter>", "")
+			}
+
+			arg := fmt.Sprintf("%s %s %s", tg.Literal("<parameter="+param.Name+">\n"), value, tg.Literal("\n</parameter>\n"))
+			if !param.Required {
+				arg = fmt.Sprintf("( %s )?", arg)
+			}
+			b.WriteString(" " + arg)
+		}
+		b.WriteString(" " + tg.Literal("</function>"))
+
+		functions = append(functions, b.String())
+	}
+
+	call := tg.Rule("tool-call", fmt.Sprintf(`"<tool_call>\n" %s "\n</tool_call>"`, tg.Rule("tool-function", tg.Alternatives(functions))))
+
+	return tg.Repeat(call, "ws"), nil
+}
+
 // containsQwenMarkers reports whether a chat template carries distinctive
 // Qwen tool-call tokens. The <function= and <parameter= openers are
 // specific to Qwen's direct-XML tool-call format and unlikely to appear
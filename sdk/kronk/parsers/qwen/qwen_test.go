@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/ardanlabs/kronk/sdk/kronk/model"
 )
@@ -147,6 +148,53 @@ func TestParser_JSONToolCallActivityDelta(t *testing.T) {
 	}
 }
 
+func TestParser_JSONToolCallArgumentDeltas(t *testing.T) {
+	c := Parser{}.NewStateMachine()
+	streamer := c.(model.ToolCallDeltaStreamer)
+	c.(model.ToolCallArgumentStreamer).StreamToolCallArguments()
+
+	var deltas []model.ResponseToolCallDelta
+	for _, token := range []string{
+		"<tool_call>",
+		`{"name": "get_weather", "arguments": `,
+		`{"location": "Lon`,
+		"don \xc3",
+		"\xa9 {x} \\\"}\"}",
+		"}",
+		"</tool_call>",
+		"\n<tool_call>",
+		`{"name": "get_time", "arguments": {}}`,
+		"</tool_call>",
+	} {
+		if _, eog := c.Classify(token); eog {
+			t.Fatalf("Classify(%q): got EOG before tool calls completed", token)
+		}
+		deltas = append(deltas, streamer.ToolCallDeltas()...)
+	}
+
+	var arguments [2]string
+	for _, delta := range deltas {
+		if delta.ID != "" {
+			if arguments[delta.Index] != "" {
+				t.Errorf("call %d: start delta after argument deltas", delta.Index)
+			}
+			continue
+		}
+		if delta.Function.Name != "" || delta.Type != "" {
+			t.Errorf("argument delta: got %+v, want only index and arguments", delta)
+		}
+		if !utf8.ValidString(delta.Function.Arguments) {
+			t.Errorf("argument delta: got invalid UTF-8 %q", delta.Function.Arguments)
+		}
+		arguments[delta.Index] += delta.Function.Arguments
+	}
+
+	want := [2]string{"{\"location\": \"London \u00e9 {x} \\\"}\"}", "{}"}
+	if arguments != want {
+		t.Errorf("arguments: got %q, want %q", arguments, want)
+	}
+}
+
 func TestParser_WrappedDirectToolCallActivityDelta(t *testing.T) {
 	c := Parser{}.NewStateMachine()
 	streamer := c.(model.ToolCallDeltaStreamer)
@@ -464,3 +512,50 @@ func TestParser_Reset(t *testing.T) {
 		t.Errorf("after Reset got %+v", got)
 	}
 }
+
+// TestParser_ToolCallGrammar checks the direct-XML grammar: raw string values,
+// JSON values for other types, and enum values written unquoted. Templates
+// without the direct-XML format get the JSON envelope.
+func TestParser_ToolCallGrammar(t *testing.T) {
+	tools := []model.D{{
+		"type": "function",
+		"function": model.D{
+			"name": "get_weather",
+			"parameters": model.D{
+				"type": "object",
+				"properties": model.D{
+					"location": model.D{"type": "string"},
+					"unit":     model.D{"type": "string", "enum": []any{"c", "f"}},
+					"days":     model.D{"type": "integer"},
+				},
+				"required": []string{"location"},
+			},
+		},
+	}}
+
+	grammar, err := model.BuildToolCallGrammar(Parser{xml: true}, tools, true)
+	if err != nil {
+		t.Fatalf("BuildToolCallGrammar: %v", err)
+	}
+
+	for _, want := range []string{
+		"root ::= tool-call ( ws tool-call )*",
+		`tool-call ::= "<tool_call>\n" tool-function "\n</tool_call>"`,
+		`"<function=get_weather>\n" "<parameter=location>\n" until-parameter "\n</parameter>\n"`,
+		`( "<parameter=days>\n" integer "\n</parameter>\n" )?`,
+		`( "<parameter=unit>\n" ( "c" | "f" ) "\n</parameter>\n" )?`,
+	} {
+		if !strings.Contains(grammar, want) {
+			t.Errorf("grammar missing %q:\n%s", want, grammar)
+		}
+	}
+
+	p, _ := New(model.Fingerprint{Architecture: "qwen3"})
+	grammar, err = model.BuildToolCallGrammar(p.(model.ToolCallGrammarParser), tools, true)
+	if err != nil {
+		t.Fatalf("BuildToolCallGrammar: %v", err)
+	}
+	if want := `tool-call ::= "<tool_call>" ws "{"`; !strings.Contains(grammar, want) {
+		t.Errorf("JSON envelope grammar missing %q:\n%s", want, grammar)
+	}
+}
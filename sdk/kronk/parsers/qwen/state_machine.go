@@ -30,11 +30,14 @@ type stateMachine struct {
 	pendingTagBuf strings.Builder
 	inPendingTag  bool
 
-	// OpenAI-compatible activity deltas for tool-call starts.
+	// OpenAI-compatible activity deltas for tool-call starts and, when
+	// enabled, the JSON arguments of the call being written.
 	toolCallDeltas []model.ResponseToolCallDelta
 	startedCalls   []model.ResponseToolCallDelta
 	deltaCallID    string
 	deltaCallIndex int
+	streamArgs     bool
+	deltaArgsSent  int
 }
 
 // Reset returns the stateMachine to its initial state for reuse on a new
@@ -52,6 +55,8 @@ func (sm *stateMachine) Reset() {
 	sm.startedCalls = nil
 	sm.deltaCallID = ""
 	sm.deltaCallIndex = 0
+	sm.streamArgs = false
+	sm.deltaArgsSent = 0
 }
 
 // Classify classifies a single decoded token's content.
@@ -207,6 +212,7 @@ func (sm *stateMachine) startToolCall(wrapped bool, content string) {
 	sm.toolCallBuf.Reset()
 	sm.toolCallBuf.WriteString(content)
 	sm.deltaCallID = ""
+	sm.deltaArgsSent = 0
 	sm.updateToolCallDeltas()
 }
 
@@ -243,27 +249,64 @@ func (sm *stateMachine) StartedToolCalls() []model.ResponseToolCallDelta {
 	return sm.startedCalls
 }
 
+// StreamToolCallArguments makes ToolCallDeltas also return the arguments of
+// JSON-envelope calls as the model writes them. Direct XML calls are still
+// reported once parsed, since their arguments are not JSON.
+func (sm *stateMachine) StreamToolCallArguments() {
+	sm.streamArgs = true
+}
+
 func (sm *stateMachine) updateToolCallDeltas() {
-	if sm.deltaCallID != "" {
+	if sm.deltaCallID == "" {
+		name, ok := toolCallName(sm.toolCallBuf.String())
+		if !ok {
+			return
+		}
+
+		sm.deltaCallID = newToolCallID()
+		delta := model.ResponseToolCallDelta{
+			ID:    sm.deltaCallID,
+			Index: sm.deltaCallIndex,
+			Type:  "function",
+			Function: model.ResponseToolCallDeltaFunction{
+				Name: name,
+			},
+		}
+		sm.toolCallDeltas = append(sm.toolCallDeltas, delta)
+		sm.startedCalls = append(sm.startedCalls, delta)
+	}
+
+	if sm.streamArgs {
+		sm.updateArgumentDeltas()
+	}
+}
+
+// updateArgumentDeltas emits the arguments object text written since the
+// previous call, stopping before a partial UTF-8 sequence and after the
+// object's closing brace.
+func (sm *stateMachine) updateArgumentDeltas() {
+	content := sm.toolCallBuf.String()
+	if !strings.HasPrefix(strings.TrimSpace(content), "{") {
 		return
 	}
 
-	name, ok := toolCallName(sm.toolCallBuf.String())
-	if !ok {
+	start, ok := jsonFieldValueStart(content, "arguments")
+	if !ok || start >= len(content) {
 		return
 	}
 
-	sm.deltaCallID = newToolCallID()
-	delta := model.ResponseToolCallDelta{
-		ID:    sm.deltaCallID,
+	n, _ := model.JSONObjectPrefix(content[start:])
+	if n <= sm.deltaArgsSent {
+		return
+	}
+
+	sm.toolCallDeltas = append(sm.toolCallDeltas, model.ResponseToolCallDelta{
 		Index: sm.deltaCallIndex,
-		Type:  "function",
 		Function: model.ResponseToolCallDeltaFunction{
-			Name: name,
+			Arguments: content[start+sm.deltaArgsSent : start+n],
 		},
-	}
-	sm.toolCallDeltas = append(sm.toolCallDeltas, delta)
-	sm.startedCalls = append(sm.startedCalls, delta)
+	})
+	sm.deltaArgsSent = n
 }
 
 func toolCallName(content string) (string, bool) {
@@ -7,6 +7,7 @@ package toolcall
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/ardanlabs/kronk/sdk/kronk/applog"
@@ -45,3 +46,16 @@ func (Parser) NewStateMachine() model.StateMachine {
 func (Parser) ToolCall(ctx context.Context, log applog.Logger, buf string) []model.ResponseToolCall {
 	return parseJSON(ctx, log, buf)
 }
+
+// ToolCallGrammar returns the grammar root for one or more marked JSON tool
+// calls.
+func (Parser) ToolCallGrammar(tg *model.ToolGrammar) (string, error) {
+	envelope, err := tg.JSONEnvelope("arguments")
+	if err != nil {
+		return "", err
+	}
+
+	call := tg.Rule("tool-call", fmt.Sprintf(`"<tool_call>" ws %s ws "</tool_call>"`, envelope))
+
+	return tg.Repeat(call, "ws"), nil
+}
@@ -328,3 +328,39 @@ func TestParser_PortParity(t *testing.T) {
 		t.Errorf("tool = %q, want %q", got, wantTool)
 	}
 }
+
+// TestParser_ToolCallGrammar checks that the grammar wraps each JSON envelope in
+// <tool_call> markers and allows parallel calls.
+func TestParser_ToolCallGrammar(t *testing.T) {
+	tools := []model.D{{
+		"type": "function",
+		"function": model.D{
+			"name": "get_weather",
+			"parameters": model.D{
+				"type": "object",
+				"properties": model.D{
+					"location": model.D{"type": "string"},
+					"unit":     model.D{"type": "string", "enum": []any{"c", "f"}},
+					"days":     model.D{"type": "integer"},
+				},
+				"required": []string{"location"},
+			},
+		},
+	}}
+
+	grammar, err := model.BuildToolCallGrammar(Parser{}, tools, true)
+	if err != nil {
+		t.Fatalf("BuildToolCallGrammar: %v", err)
+	}
+
+	for _, want := range []string{
+		"root ::= tool-call ( ws tool-call )*",
+		`tool-call ::= "<tool_call>" ws "{" ws "\"" "name" "\"" ws ":" ws "\"" "get_weather" "\""`,
+		`"\"" "arguments" "\"" ws ":" ws "{" ws "\"" "location" "\"" ws ":" ws string`,
+		`ws "}" ws "}" ws "</tool_call>"`,
+	} {
+		if !strings.Contains(grammar, want) {
+			t.Errorf("grammar missing %q:\n%s", want, grammar)
+		}
+	}
+}
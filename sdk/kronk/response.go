@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"reflect"
 	"slices"
+	"strings"
 	"time"
 
 	"uuid"
@@ -174,6 +176,10 @@ func (krn *Kronk) ResponseStreaming(ctx context.Context, d model.D) (<-chan Resp
 		return nil, fmt.Errorf("responses-streaming: %w", err)
 	}
 
+	// Responses clients never ask for usage while streaming, but the
+	// completed response reports it, so it is always requested.
+	d["stream_options"] = model.D{"include_usage": true}
+
 	f := func(m *model.Model) (<-chan model.ChatResponse, error) {
 		return m.ChatStreaming(ctx, d)
 	}
@@ -295,7 +301,9 @@ type streamState struct {
 	fcItems         []ResponseOutputItem
 	fcIDs           []string
 	fcArgsAccum     []string
+	fcFinal         []bool
 	toolCallsSeenID map[string]int
+	toolCallIndexes map[int]int
 	lastChatResp    model.ChatResponse
 	usage           *model.Usage
 }
@@ -347,6 +355,10 @@ func (ss *streamState) process(chatResp model.ChatResponse) []ResponseStreamEven
 		}
 	}
 
+	if choice.Delta != nil && len(choice.Delta.ToolCallDeltas) > 0 {
+		events = append(events, ss.handleToolCallDeltas(choice.Delta.ToolCallDeltas)...)
+	}
+
 	if choice.Message != nil && len(choice.Message.ToolCalls) > 0 {
 		events = append(events, ss.handleToolCalls(choice.Message.ToolCalls)...)
 	}
@@ -414,6 +426,7 @@ func (ss *streamState) buildInProgressResponse() ResponseResponse {
 		Model:            ss.modelID,
 		Output:           []ResponseOutputItem{},
 		ParallelToolCall: ss.params.ParallelToolCalls,
+		PrevResponseID:   ss.params.PrevResponseID,
 		Reasoning:        ResponseReasoning{},
 		Store:            ss.params.Store,
 		Temperature:      ss.params.Temperature,
@@ -497,65 +510,152 @@ func (ss *streamState) emitMessageItemAdded() []ResponseStreamEvent {
 	return events
 }
 
-func (ss *streamState) handleToolCalls(toolCalls []model.ResponseToolCall) []ResponseStreamEvent {
-	if ss.toolCallsSeenID == nil {
-		ss.toolCallsSeenID = make(map[string]int)
+// handleToolCallDeltas streams tool calls while the model writes them. A
+// delta carrying an ID starts a function_call item, and argument fragments
+// extend the arguments of the item started at the same index.
+func (ss *streamState) handleToolCallDeltas(deltas []model.ResponseToolCallDelta) []ResponseStreamEvent {
+	if ss.toolCallIndexes == nil {
+		ss.toolCallIndexes = make(map[int]int)
+	}
+
+	var events []ResponseStreamEvent
+
+	for _, delta := range deltas {
+		idx, started := ss.toolCallIndexes[delta.Index]
+		if delta.ID != "" {
+			if _, seen := ss.toolCallsSeenID[delta.ID]; !seen {
+				var added []ResponseStreamEvent
+				idx, added = ss.addFunctionCallItem(delta.ID, delta.Function.Name)
+				events = append(events, added...)
+				ss.toolCallIndexes[delta.Index] = idx
+				started = true
+			}
+		}
+
+		if !started || delta.Function.Arguments == "" {
+			continue
+		}
+
+		events = append(events, ss.appendFunctionCallArguments(idx, delta.Function.Arguments))
 	}
 
+	return events
+}
+
+// handleToolCalls completes the function_call items for the parsed tool calls
+// of the final response. Calls that were not streamed are added with their
+// arguments. Streamed calls receive the arguments not yet sent; when the
+// streamed text is not a prefix of the parsed arguments and does not decode
+// to the same value, the parsed arguments replace it in the done events.
+func (ss *streamState) handleToolCalls(toolCalls []model.ResponseToolCall) []ResponseStreamEvent {
 	var events []ResponseStreamEvent
 
 	for _, tc := range toolCalls {
+		args, _ := json.Marshal(map[string]any(tc.Function.Arguments))
+		arguments := string(args)
+
 		idx, seen := ss.toolCallsSeenID[tc.ID]
 		if !seen {
-			idx = len(ss.fcItems)
-			ss.toolCallsSeenID[tc.ID] = idx
+			idx, seen = ss.startedFunctionCall(tc.Function.Name)
+		}
+		if !seen {
+			var added []ResponseStreamEvent
+			idx, added = ss.addFunctionCallItem(tc.ID, tc.Function.Name)
+			events = append(events, added...)
+		}
+		ss.fcFinal[idx] = true
 
-			if ss.msgItemEmitted {
-				ss.outputIndex++
+		streamed := ss.fcArgsAccum[idx]
+		switch {
+		case strings.HasPrefix(arguments, streamed):
+			if suffix := arguments[len(streamed):]; suffix != "" {
+				events = append(events, ss.appendFunctionCallArguments(idx, suffix))
 			}
+		case !sameJSON(streamed, arguments):
+			ss.fcArgsAccum[idx] = arguments
+		}
+	}
 
-			fcID := fmt.Sprintf("call_%s", uuid.New().String())
-			ss.fcIDs = append(ss.fcIDs, fcID)
-			ss.fcArgsAccum = append(ss.fcArgsAccum, "")
+	return events
+}
 
-			emptyArgs := ""
-			fcItem := ResponseOutputItem{
-				Type:      "function_call",
-				ID:        fcID,
-				CallID:    tc.ID,
-				Name:      tc.Function.Name,
-				Status:    "in_progress",
-				Arguments: &emptyArgs,
-			}
-			ss.fcItems = append(ss.fcItems, fcItem)
-
-			outIdx := ss.outputIndex + idx
-			events = append(events, ResponseStreamEvent{
-				Type:           "response.output_item.added",
-				SequenceNumber: ss.seq,
-				OutputIndex:    &outIdx,
-				Item:           &fcItem,
-			})
-			ss.seq++
+// startedFunctionCall returns the first streamed item for name not yet
+// completed by a parsed tool call, for parsed calls whose ID differs from
+// the streamed one.
+func (ss *streamState) startedFunctionCall(name string) (int, bool) {
+	for idx, fcItem := range ss.fcItems {
+		if !ss.fcFinal[idx] && fcItem.Name == name {
+			return idx, true
 		}
+	}
 
-		args, _ := json.Marshal(map[string]any(tc.Function.Arguments))
-		argsDelta := string(args)
+	return 0, false
+}
 
-		ss.fcArgsAccum[idx] = argsDelta
+func (ss *streamState) addFunctionCallItem(callID string, name string) (int, []ResponseStreamEvent) {
+	if ss.toolCallsSeenID == nil {
+		ss.toolCallsSeenID = make(map[string]int)
+	}
 
-		outIdx := ss.outputIndex + idx
-		events = append(events, ResponseStreamEvent{
-			Type:           "response.function_call_arguments.delta",
-			SequenceNumber: ss.seq,
-			ItemID:         ss.fcIDs[idx],
-			OutputIndex:    &outIdx,
-			Delta:          argsDelta,
-		})
-		ss.seq++
+	idx := len(ss.fcItems)
+	ss.toolCallsSeenID[callID] = idx
+
+	if ss.msgItemEmitted {
+		ss.outputIndex++
 	}
 
-	return events
+	fcID := fmt.Sprintf("call_%s", uuid.New().String())
+	ss.fcIDs = append(ss.fcIDs, fcID)
+	ss.fcArgsAccum = append(ss.fcArgsAccum, "")
+	ss.fcFinal = append(ss.fcFinal, false)
+
+	emptyArgs := ""
+	fcItem := ResponseOutputItem{
+		Type:      "function_call",
+		ID:        fcID,
+		CallID:    callID,
+		Name:      name,
+		Status:    "in_progress",
+		Arguments: &emptyArgs,
+	}
+	ss.fcItems = append(ss.fcItems, fcItem)
+
+	outIdx := ss.outputIndex + idx
+	event := ResponseStreamEvent{
+		Type:           "response.output_item.added",
+		SequenceNumber: ss.seq,
+		OutputIndex:    &outIdx,
+		Item:           &fcItem,
+	}
+	ss.seq++
+
+	return idx, []ResponseStreamEvent{event}
+}
+
+func (ss *streamState) appendFunctionCallArguments(idx int, argsDelta string) ResponseStreamEvent {
+	ss.fcArgsAccum[idx] += argsDelta
+
+	outIdx := ss.outputIndex + idx
+	event := ResponseStreamEvent{
+		Type:           "response.function_call_arguments.delta",
+		SequenceNumber: ss.seq,
+		ItemID:         ss.fcIDs[idx],
+		OutputIndex:    &outIdx,
+		Delta:          argsDelta,
+	}
+	ss.seq++
+
+	return event
+}
+
+// sameJSON reports whether a and b decode to the same JSON value.
+func sameJSON(a string, b string) bool {
+	var va, vb any
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(va, vb)
 }
 
 func (ss *streamState) finalizeMessageItem() []ResponseStreamEvent {
@@ -711,7 +811,7 @@ func toChatResponseToResponses(chatResp model.ChatResponse, d model.D) ResponseR
 		Model:            chatResp.Model,
 		Output:           outputItems,
 		ParallelToolCall: inputParams.ParallelToolCalls,
-		PrevResponseID:   nil,
+		PrevResponseID:   inputParams.PrevResponseID,
 		Reasoning: ResponseReasoning{
 			Effort:  nil,
 			Summary: reasoningSummary,
@@ -797,6 +897,7 @@ type inputParams struct {
 	ParallelToolCalls bool
 	Store             bool
 	Instructions      *string
+	PrevResponseID    *string
 }
 
 func extractInputParams(d model.D) inputParams {
@@ -848,6 +949,10 @@ func extractInputParams(d model.D) inputParams {
 		params.Instructions = &v
 	}
 
+	if v, ok := d["previous_response_id"].(string); ok && v != "" {
+		params.PrevResponseID = &v
+	}
+
 	return params
 }
 
@@ -1220,62 +1325,43 @@ func injectInstructions(d model.D) {
 }
 
 func inputToMessages(input any) ([]model.D, error) {
-	inputItems, ok := input.([]any)
-	if !ok {
-		if str, ok := input.(string); ok {
-			return []model.D{
-				{"role": "user", "content": str},
-			}, nil
-		}
-		if docs, ok := input.([]model.D); ok {
-			return docs, nil
-		}
-		return nil, model.ErrMessagesInvalid
-	}
+	var items []model.D
+	switch v := input.(type) {
+	case string:
+		return []model.D{
+			{"role": "user", "content": v},
+		}, nil
 
-	if len(inputItems) == 0 {
-		return nil, nil
-	}
-
-	firstItem, ok := inputItems[0].(map[string]any)
-	if !ok {
-		firstDoc, ok := inputItems[0].(model.D)
-		switch ok {
-		case true:
-			firstItem = firstDoc
-		case false:
-			return nil, model.ErrMessagesInvalid
-		}
-	}
+	case []model.D:
+		items = v
 
-	if _, hasRole := firstItem["role"]; hasRole {
-		var messages []model.D
-		for _, item := range inputItems {
+	case []any:
+		items = make([]model.D, 0, len(v))
+		for _, item := range v {
 			switch v := item.(type) {
 			case map[string]any:
-				messages = append(messages, model.D(v))
+				items = append(items, model.D(v))
 			case model.D:
-				messages = append(messages, v)
+				items = append(items, v)
 			default:
 				return nil, model.ErrMessagesInvalid
 			}
 		}
-		return messages, nil
+
+	default:
+		return nil, model.ErrMessagesInvalid
 	}
 
-	var content []model.D
-	for _, item := range inputItems {
-		var itemMap map[string]any
-
-		switch v := item.(type) {
-		case map[string]any:
-			itemMap = v
-		case model.D:
-			itemMap = v
-		default:
-			continue
-		}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	if isInputItem(items[0]) {
+		return items, nil
+	}
 
+	var content []model.D
+	for _, itemMap := range items {
 		switch itemMap["type"] {
 		case "input_text":
 			content = append(content, model.D{
@@ -1296,3 +1382,67 @@ func inputToMessages(input any) ([]model.D, error) {
 		{"role": "user", "content": content},
 	}, nil
 }
+
+// isInputItem reports whether item is a conversation item rather than a
+// content part of a single user message.
+func isInputItem(item model.D) bool {
+	if _, hasRole := item["role"]; hasRole {
+		return true
+	}
+
+	switch item["type"] {
+	case "message", "function_call", "function_call_output":
+		return true
+	}
+
+	return false
+}
+
+// ResponseInputItems returns the input of a Responses API request as a list
+// of conversation items. A string becomes a user message, and a list of
+// content parts becomes a single user message holding them.
+func ResponseInputItems(input any) ([]model.D, error) {
+	items, err := inputToMessages(input)
+	if err != nil {
+		return nil, fmt.Errorf("response-input-items: %w", err)
+	}
+
+	return items, nil
+}
+
+// InputItems returns the output of the response as input items, so a later
+// request can continue the conversation from it. Message text is joined into
+// a single string so the replayed turn renders as the model produced it.
+func (r ResponseResponse) InputItems() []model.D {
+	items := make([]model.D, 0, len(r.Output))
+	for _, item := range r.Output {
+		switch item.Type {
+		case "message":
+			var content strings.Builder
+			for _, part := range item.Content {
+				content.WriteString(part.Text)
+			}
+
+			items = append(items, model.D{
+				"type":    "message",
+				"role":    item.Role,
+				"content": content.String(),
+			})
+
+		case "function_call":
+			var arguments string
+			if item.Arguments != nil {
+				arguments = *item.Arguments
+			}
+
+			items = append(items, model.D{
+				"type":      "function_call",
+				"call_id":   item.CallID,
+				"name":      item.Name,
+				"arguments": arguments,
+			})
+		}
+	}
+
+	return items
+}
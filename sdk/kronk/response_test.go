@@ -208,6 +208,101 @@ func TestStreamStateCompleteTokenLimit(t *testing.T) {
 	}
 }
 
+func TestStreamStateCompleteUsageChunk(t *testing.T) {
+	finishReason := model.FinishReasonStop
+	ss := streamState{}
+
+	ss.process(model.ChatResponse{
+		Choices: []model.Choice{{FinishReasonPtr: &finishReason}},
+	})
+
+	usage := model.ChatResponse{
+		Usage: &model.Usage{PromptTokens: 12, CompletionTokens: 5, TotalTokens: 17},
+	}
+	if events := ss.process(usage); len(events) != 0 {
+		t.Errorf("usage chunk: got %d events, want 0", len(events))
+	}
+
+	events := ss.complete(usage)
+	if len(events) == 0 {
+		t.Fatal("complete: got no events")
+	}
+	last := events[len(events)-1]
+	if got, want := last.Type, "response.completed"; got != want {
+		t.Errorf("event type: got %q, want %q", got, want)
+	}
+	if last.Response == nil {
+		t.Fatal("event response: got nil")
+	}
+	if got, want := last.Response.Usage.InputTokens, 12; got != want {
+		t.Errorf("InputTokens: got %d, want %d", got, want)
+	}
+	if got, want := last.Response.Usage.OutputTokens, 5; got != want {
+		t.Errorf("OutputTokens: got %d, want %d", got, want)
+	}
+}
+
+func TestStreamStateStreamsToolCallArguments(t *testing.T) {
+	deltaResp := func(delta model.ResponseToolCallDelta) model.ChatResponse {
+		return model.ChatResponse{Choices: []model.Choice{{
+			Delta: &model.ResponseMessage{ToolCallDeltas: []model.ResponseToolCallDelta{delta}},
+		}}}
+	}
+
+	finishReason := model.FinishReasonTool
+	final := model.ChatResponse{Choices: []model.Choice{{
+		Delta: &model.ResponseMessage{},
+		Message: &model.ResponseMessage{ToolCalls: []model.ResponseToolCall{
+			{ID: "call-1", Type: "function", Function: model.ResponseToolCallFunction{Name: "get_weather", Arguments: model.ToolCallArguments{"location": "Paris", "days": 2.0}}},
+			{ID: "call-2", Type: "function", Function: model.ResponseToolCallFunction{Name: "get_time", Arguments: model.ToolCallArguments{}}},
+		}},
+		FinishReasonPtr: &finishReason,
+	}}}
+
+	ss := streamState{}
+	var events []ResponseStreamEvent
+	for _, resp := range []model.ChatResponse{
+		deltaResp(model.ResponseToolCallDelta{ID: "call-1", Type: "function", Function: model.ResponseToolCallDeltaFunction{Name: "get_weather"}}),
+		deltaResp(model.ResponseToolCallDelta{Function: model.ResponseToolCallDeltaFunction{Arguments: `{"location": `}}),
+		deltaResp(model.ResponseToolCallDelta{Function: model.ResponseToolCallDeltaFunction{Arguments: `"Paris", "days": 2}`}}),
+		final,
+	} {
+		events = append(events, ss.process(resp)...)
+	}
+	events = append(events, ss.complete(final)...)
+
+	var types []string
+	var done []string
+	for _, event := range events {
+		types = append(types, event.Type)
+		if event.Type == "response.function_call_arguments.done" {
+			done = append(done, event.Arguments)
+		}
+	}
+
+	wantTypes := []string{
+		"response.output_item.added",
+		"response.function_call_arguments.delta",
+		"response.function_call_arguments.delta",
+		"response.output_item.added",
+		"response.function_call_arguments.delta",
+		"response.function_call_arguments.done",
+		"response.output_item.done",
+		"response.function_call_arguments.done",
+		"response.output_item.done",
+		"response.completed",
+	}
+	if diff := cmp.Diff(wantTypes, types); diff != "" {
+		t.Errorf("event types mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{`{"location": "Paris", "days": 2}`, `{}`}, done); diff != "" {
+		t.Errorf("done arguments mismatch (-want +got):\n%s", diff)
+	}
+	if got := len(events[len(events)-1].Response.Output); got != 2 {
+		t.Errorf("output items: got %d, want 2", got)
+	}
+}
+
 func TestConvertInputToMessagesRoleShapedImage(t *testing.T) {
 	const imageURL = "data:image/jpeg;base64,aW1hZ2U="
 
@@ -313,3 +408,102 @@ func TestConvertInputToMessagesRejectsFileInput(t *testing.T) {
 		})
 	}
 }
+
+func TestResponseInputItems(t *testing.T) {
+	tests := []struct {
+		name  string
+		input any
+		want  []model.D
+	}{
+		{
+			name:  "string",
+			input: "hello",
+			want:  []model.D{{"role": "user", "content": "hello"}},
+		},
+		{
+			name: "content parts",
+			input: []any{
+				map[string]any{"type": "input_text", "text": "hello"},
+			},
+			want: []model.D{
+				{"role": "user", "content": []model.D{{"type": "text", "text": "hello"}}},
+			},
+		},
+		{
+			name: "conversation items",
+			input: []any{
+				map[string]any{"type": "function_call_output", "call_id": "call_1", "output": "sunny"},
+				map[string]any{"role": "user", "content": "thanks"},
+			},
+			want: []model.D{
+				{"type": "function_call_output", "call_id": "call_1", "output": "sunny"},
+				{"role": "user", "content": "thanks"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResponseInputItems(tt.input)
+			if err != nil {
+				t.Fatalf("ResponseInputItems: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ResponseInputItems mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := ResponseInputItems(42); !errors.Is(err, model.ErrMessagesInvalid) {
+		t.Errorf("invalid input: got %v, want %v", err, model.ErrMessagesInvalid)
+	}
+}
+
+func TestResponseInputItemsReplayThroughConversion(t *testing.T) {
+	arguments := `{"location":"Paris"}`
+
+	prev := ResponseResponse{
+		Output: []ResponseOutputItem{
+			{Type: "reasoning"},
+			{Type: "message", Role: "assistant", Content: []ResponseContentItem{{Type: "output_text", Text: "Let me "}, {Type: "output_text", Text: "check."}}},
+			{Type: "function_call", CallID: "call_1", Name: "get_weather", Arguments: &arguments},
+		},
+	}
+
+	history := prev.InputItems()
+
+	wantHistory := []model.D{
+		{"type": "message", "role": "assistant", "content": "Let me check."},
+		{"type": "function_call", "call_id": "call_1", "name": "get_weather", "arguments": arguments},
+	}
+	if diff := cmp.Diff(wantHistory, history); diff != "" {
+		t.Fatalf("InputItems mismatch (-want +got):\n%s", diff)
+	}
+
+	d := model.D{
+		"previous_response_id": "resp_1",
+		"input": append(history, model.D{
+			"type": "function_call_output", "call_id": "call_1", "output": "sunny",
+		}),
+	}
+
+	got, err := convertInputToMessages(d)
+	if err != nil {
+		t.Fatalf("convertInputToMessages: %v", err)
+	}
+
+	messages, _ := got["messages"].([]model.D)
+	roles := make([]string, len(messages))
+	for i, msg := range messages {
+		roles[i], _ = msg["role"].(string)
+	}
+
+	if diff := cmp.Diff([]string{"assistant", "assistant", "tool"}, roles); diff != "" {
+		t.Errorf("message roles mismatch (-want +got):\n%s", diff)
+	}
+
+	resp := toChatResponseToResponses(model.ChatResponse{}, got)
+	if resp.PrevResponseID == nil || *resp.PrevResponseID != "resp_1" {
+		t.Errorf("PrevResponseID: got %v, want resp_1", resp.PrevResponseID)
+	}
+}
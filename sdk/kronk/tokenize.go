@@ -12,11 +12,13 @@ import (
 // Tokenize returns the token count for a text input.
 //
 // Supported options in d:
-//   - input (string): the text to tokenize (required)
+//   - input (string): the text to tokenize (required unless messages is set)
 //   - apply_template (bool): if true, wrap input as a user message and apply
 //     the model's chat template before tokenizing (default: false)
 //   - add_generation_prompt (bool): when apply_template is true, controls whether
 //     the assistant role prefix is appended to the prompt (default: true)
+//   - messages ([]D): a chat conversation to count instead of input, rendered
+//     with the chat template, tools, and thinking settings in d
 //
 // When apply_template is true, the returned count includes all template
 // overhead (role markers, separators, generation prompt). This reflects the
@@ -0,0 +1,73 @@
+package pool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/kronk/sdk/malina/model"
+	"github.com/ardanlabs/kronk/sdk/pool/engine/resman"
+	malinamodels "github.com/ardanlabs/kronk/sdk/tools/malina/models"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateConfigTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		wantErr bool
+	}{
+		{name: "negative", ttl: -time.Second, wantErr: true},
+		{name: "disabled"},
+		{name: "enabled", ttl: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateConfig(Config{
+				Log:    func(context.Context, string, ...any) {},
+				Models: &malinamodels.Models{},
+				Resman: &resman.Manager{},
+				TTL:    tt.ttl,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.TTL != tt.ttl {
+				t.Errorf("TTL: got %s, want %s", got.TTL, tt.ttl)
+			}
+		})
+	}
+}
+
+func TestConfigFromManifest(t *testing.T) {
+	manifest := malinamodels.Manifest{
+		Bundle: malinamodels.BundleFlux2Klein4B,
+		Files: map[string]string{
+			string(malinamodels.RoleDiffusion): "/m/flux.gguf",
+			string(malinamodels.RoleVAE):       "/m/ae.safetensors",
+			string(malinamodels.RoleLLM):       "/m/qwen.gguf",
+		},
+	}
+
+	got, err := configFromManifest(manifest)
+	if err != nil {
+		t.Fatalf("should be able to map manifest: %s", err)
+	}
+
+	want := model.Config{
+		DiffusionModelPath: "/m/flux.gguf",
+		VAEPath:            "/m/ae.safetensors",
+		LLMPath:            "/m/qwen.gguf",
+		Concurrency:        1,
+		AdmissionTimeout:   admissionTimeout,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("config mismatch (-want +got):\n%s", diff)
+	}
+
+	manifest.Files = map[string]string{string(malinamodels.RoleVAE): "/m/ae.safetensors"}
+	if _, err := configFromManifest(manifest); err == nil {
+		t.Error("manifest without a model file: expected an error")
+	}
+}
@@ -0,0 +1,234 @@
+// This file provides the stable-diffusion-backed loader.Loader
+// implementation that plugs the malina / stable-diffusion.cpp runtime
+// into the generic pool core. It owns the bundle manifest resolution
+// against the malina catalog and the construction of a *malina.Malina
+// handle. The pool core invokes it for every load/unload/display
+// operation, leaving the cache, eviction, and budget logic entirely
+// backend-agnostic in sdk/pool/engine.
+
+package pool
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/kronk/sdk/applog"
+	"github.com/ardanlabs/kronk/sdk/malina"
+	"github.com/ardanlabs/kronk/sdk/malina/model"
+	"github.com/ardanlabs/kronk/sdk/pool/engine/loader"
+	"github.com/ardanlabs/kronk/sdk/pool/engine/resman"
+	"github.com/ardanlabs/kronk/sdk/tools/malina/models"
+)
+
+// diffusionOverhead is the additional resident memory we reserve on
+// top of the raw bundle file sizes to account for the UNet/DiT
+// activations and the VAE decode buffer. The figure is conservative
+// for 1024x1024 generations, the largest size the SDK accepts.
+const diffusionOverhead int64 = 1500 * 1000 * 1000
+
+// admissionTimeout bounds how long a request waits for the handle's
+// single generation context before it is rejected.
+const admissionTimeout = 3 * time.Minute
+
+// Diffusion is the loader.Loader[*malina.Malina] implementation for
+// the stable-diffusion.cpp backend. It is constructed by sdk/pool and
+// any future programs that want to build a pool around malina bundles
+// manually.
+type Diffusion struct {
+	log    applog.Logger
+	models *models.Models
+	resman *resman.Manager
+}
+
+// newDiffusion constructs a stable-diffusion loader.
+func newDiffusion(log applog.Logger, mdls *models.Models, rm *resman.Manager) *Diffusion {
+	d := Diffusion{
+		log:    log,
+		models: mdls,
+		resman: rm,
+	}
+	return &d
+}
+
+// Models returns the underlying models system. Pool wrappers expose
+// this for catalog-flavored APIs.
+func (d *Diffusion) Models() *models.Models {
+	return d.models
+}
+
+// Plan implements loader.Loader.Plan for the stable-diffusion backend.
+//
+// The resident footprint is every component file in the bundle plus
+// the generation overhead. The estimate is charged to VRAM when the
+// resman has GPUs and to system RAM otherwise.
+func (d *Diffusion) Plan(ctx context.Context, req loader.LoadRequest) (resman.PlanRequest, error) {
+	size, err := d.bundleSize(req.ModelID)
+	if err != nil {
+		return resman.PlanRequest{}, fmt.Errorf("plan: %w", err)
+	}
+
+	planReq := resman.PlanRequest{
+		Key: req.Key,
+	}
+
+	total := size + diffusionOverhead
+	if d.resman.HasGPUs() {
+		planReq.VRAMBytes = total
+	} else {
+		planReq.RAMBytes = total
+	}
+
+	d.log(ctx, "malina-plan-request",
+		"key", req.Key,
+		"model-id", req.ModelID,
+		"predicted-total", total,
+		"model-size", size,
+		"overhead", diffusionOverhead,
+		"vram", planReq.VRAMBytes,
+		"ram", planReq.RAMBytes,
+	)
+
+	return planReq, nil
+}
+
+// Load implements loader.Loader.Load for the stable-diffusion backend.
+func (d *Diffusion) Load(ctx context.Context, req loader.LoadRequest) (*malina.Malina, error) {
+	cfg, err := d.resolveConfig(req)
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+
+	handle, err := malina.NewWithContext(ctx, model.WithConfig(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("load: unable to create malina handle: %w", err)
+	}
+
+	d.log(ctx, "malina-load",
+		"status", "load new model",
+		"model-name", req.ModelID,
+		"model-path", cfg.ModelPath,
+		"diffusion-model-path", cfg.DiffusionModelPath,
+	)
+
+	return handle, nil
+}
+
+// Display implements loader.Loader.Display for the stable-diffusion
+// backend.
+//
+// Stable-diffusion does not maintain a KV cache and each handle runs
+// one generation at a time, so KVCache is zero and Slots is one.
+func (d *Diffusion) Display(h *malina.Malina, modelID string) loader.Display {
+	_ = h
+
+	out := loader.Display{
+		Slots: 1,
+	}
+
+	if size, err := d.bundleSize(modelID); err == nil {
+		out.VRAMTotal = size + diffusionOverhead
+	}
+
+	return out
+}
+
+// =============================================================================
+
+// resolveConfig produces a model.Config for the request. When the
+// caller has supplied a pre-built config via req.Custom it is used
+// as-is. Otherwise the bundle manifest is consulted to map every
+// component file onto its configuration role.
+func (d *Diffusion) resolveConfig(req loader.LoadRequest) (model.Config, error) {
+	if req.Custom != nil {
+		cfg, ok := req.Custom.(model.Config)
+		if !ok {
+			return model.Config{}, fmt.Errorf("resolve-config: custom config is %T, want model.Config", req.Custom)
+		}
+		return cfg, nil
+	}
+
+	name, err := models.ParseBundleName(req.ModelID)
+	if err != nil {
+		return model.Config{}, fmt.Errorf("resolve-config: %w", err)
+	}
+
+	manifest, err := d.models.LoadManifest(name)
+	if err != nil {
+		return model.Config{}, fmt.Errorf("resolve-config: model-id[%s]: %w", req.ModelID, err)
+	}
+
+	return configFromManifest(manifest)
+}
+
+// configFromManifest maps the role-to-path entries of a bundle
+// manifest onto a single-context model.Config.
+func configFromManifest(manifest models.Manifest) (model.Config, error) {
+	cfg := model.Config{
+		Concurrency:      1,
+		AdmissionTimeout: admissionTimeout,
+	}
+
+	for role, path := range manifest.Files {
+		switch models.FileRole(role) {
+		case models.RoleModel:
+			cfg.ModelPath = path
+		case models.RoleDiffusion:
+			cfg.DiffusionModelPath = path
+		case models.RoleVAE:
+			cfg.VAEPath = path
+		case models.RoleClipL:
+			cfg.ClipLPath = path
+		case models.RoleClipG:
+			cfg.ClipGPath = path
+		case models.RoleT5XXL:
+			cfg.T5XXLPath = path
+		case models.RoleLLM:
+			cfg.LLMPath = path
+		case models.RoleLLMVision:
+			cfg.LLMVisionPath = path
+		case models.RoleControlNet:
+			cfg.ControlNetPath = path
+		case models.RoleTAESD:
+			cfg.TAESDPath = path
+		case models.RolePhotoMaker:
+			cfg.PhotoMakerPath = path
+		case models.RoleClipVision:
+			cfg.ClipVisionPath = path
+		case models.RoleHighNoise:
+			cfg.HighNoiseDiffusionModelPath = path
+		case models.RoleEmbeddingsConn:
+			cfg.EmbeddingsConnectorsPath = path
+		default:
+			return model.Config{}, fmt.Errorf("config-from-manifest: bundle[%s]: unknown role %q", manifest.Bundle, role)
+		}
+	}
+
+	if cfg.ModelPath == "" && cfg.DiffusionModelPath == "" {
+		return model.Config{}, fmt.Errorf("config-from-manifest: bundle[%s]: no model or diffusion file", manifest.Bundle)
+	}
+
+	return cfg, nil
+}
+
+// bundleSize returns the combined on-disk size of every component
+// file in the resolved bundle in bytes.
+func (d *Diffusion) bundleSize(modelID string) (int64, error) {
+	path, err := d.models.FullPath(modelID)
+	if err != nil {
+		return 0, fmt.Errorf("bundle-size: %w", err)
+	}
+	if len(path.FileSizes) == 0 {
+		return 0, fmt.Errorf("bundle-size: model-id[%s]: missing file sizes", modelID)
+	}
+
+	var total int64
+	for _, size := range path.FileSizes {
+		if size <= 0 {
+			return 0, fmt.Errorf("bundle-size: model-id[%s]: missing file size", modelID)
+		}
+		total += size
+	}
+
+	return total, nil
+}
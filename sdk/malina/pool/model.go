@@ -0,0 +1,29 @@
+package pool
+
+import "time"
+
+// Model status values surfaced to BUI/observability. Values mirror
+// the kronk pool's status strings so callers can render mixed
+// kronk/bucky/malina listings without switching on backend.
+const (
+	ModelStatusLoaded  = "loaded"
+	ModelStatusLoading = "loading"
+)
+
+// ModelDetail describes a single malina (stable-diffusion) bundle
+// from the pool's point of view. Field semantics intentionally match
+// sdk/kronk/pool.ModelDetail so the BUI's "Loaded Models" table can
+// render every backend through a single response shape.
+//
+// Stable-diffusion has no KV cache and each handle runs one
+// generation at a time, so KVCache stays zero and Slots is reported
+// as 1 for display parity.
+type ModelDetail struct {
+	ID            string
+	Backend       string
+	Size          int64
+	VRAMTotal     int64
+	ExpiresAt     time.Time
+	ActiveStreams int
+	Status        string
+}
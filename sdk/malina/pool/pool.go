@@ -0,0 +1,206 @@
+// Package pool manages a pool of malina APIs for specific
+// stable-diffusion model bundles. Used by the model server to manage
+// the number of image models that are maintained in memory at any
+// given time.
+//
+// The pool reuses the same resman.Manager instance as the llama and
+// whisper pools so VRAM and RAM accounting is unified across every
+// backend running on the host.
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/kronk/sdk/applog"
+	"github.com/ardanlabs/kronk/sdk/malina"
+	"github.com/ardanlabs/kronk/sdk/pool/engine"
+	"github.com/ardanlabs/kronk/sdk/pool/engine/loader"
+	"github.com/ardanlabs/kronk/sdk/pool/engine/resman"
+	malinamodels "github.com/ardanlabs/kronk/sdk/tools/malina/models"
+)
+
+// ErrServerBusy is returned when the pool cannot make room for a new
+// entry because no idle pool entry is available to evict. It aliases
+// the core sentinel so errors.Is works across both packages.
+var ErrServerBusy = engine.ErrServerBusy
+
+// Config represents settings for the malina (stable-diffusion) pool.
+//
+// Models is the curated bundle catalog the pool consults for path
+// resolution. Required.
+//
+// Resman is the shared resource manager. Building it outside the pool
+// lets every backend (kronk, bucky, malina) charge the same byte
+// budget. Required.
+//
+// ModelsInPool falls back to its default when zero. A zero TTL disables
+// idle expiration; negative TTL values are invalid.
+type Config struct {
+	Log          applog.Logger
+	Models       *malinamodels.Models
+	Resman       *resman.Manager
+	ModelsInPool int
+	TTL          time.Duration
+}
+
+// Default config value applied when ModelsInPool is zero.
+const (
+	defaultModelsInPool = 10
+)
+
+func validateConfig(cfg Config) (Config, error) {
+	if cfg.Log == nil {
+		return Config{}, errors.New("log is required")
+	}
+	if cfg.Models == nil {
+		return Config{}, errors.New("models is required")
+	}
+	if cfg.Resman == nil {
+		return Config{}, errors.New("resman is required")
+	}
+
+	if cfg.ModelsInPool <= 0 {
+		cfg.ModelsInPool = defaultModelsInPool
+	}
+	if cfg.TTL < 0 {
+		return Config{}, errors.New("ttl must be >= 0")
+	}
+
+	return cfg, nil
+}
+
+// =============================================================================
+
+// Pool manages a set of *malina.Malina handles. It maintains a cache of
+// these handles and unloads them on TTL or capacity overflow.
+type Pool struct {
+	engine *engine.Pool[*malina.Malina]
+	loader *Diffusion
+	models *malinamodels.Models
+	resman *resman.Manager
+}
+
+// New constructs the malina pool for use.
+func New(cfg Config) (*Pool, error) {
+	cfg, err := validateConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("new: %w", err)
+	}
+
+	dl := newDiffusion(cfg.Log, cfg.Models, cfg.Resman)
+
+	c, err := engine.New(engine.Config{
+		Log:      cfg.Log,
+		Resman:   cfg.Resman,
+		MaxItems: cfg.ModelsInPool,
+		TTL:      cfg.TTL,
+	}, dl)
+	if err != nil {
+		return nil, fmt.Errorf("new: constructing pool core: %w", err)
+	}
+
+	p := Pool{
+		engine: c,
+		loader: dl,
+		models: cfg.Models,
+		resman: cfg.Resman,
+	}
+
+	return &p, nil
+}
+
+// ResourceManager returns the pool's underlying resource manager.
+func (p *Pool) ResourceManager() *resman.Manager {
+	return p.resman
+}
+
+// Shutdown releases all handles from the pool and performs a proper
+// unloading.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	return p.engine.Shutdown(ctx)
+}
+
+// AquireModel will provide a malina handle for the specified bundle.
+// If the bundle is not in the pool, a handle for it will be created.
+func (p *Pool) AquireModel(ctx context.Context, modelID string) (*malina.Malina, error) {
+	return p.engine.Acquire(ctx, loader.LoadRequest{
+		ModelID: modelID,
+		Key:     modelID,
+	})
+}
+
+// GetExisting returns a pooled handle if it exists, without creating
+// one.
+func (p *Pool) GetExisting(key string) (*malina.Malina, bool) {
+	return p.engine.GetExisting(key)
+}
+
+// Invalidate removes a single entry from the pool, triggering unload
+// asynchronously.
+func (p *Pool) Invalidate(key string) {
+	p.engine.Invalidate(key)
+}
+
+// InvalidateSync invalidates a cache entry and waits for the eviction
+// callback to release the underlying resource manager reservation.
+func (p *Pool) InvalidateSync(ctx context.Context, key string) error {
+	return p.engine.InvalidateSync(ctx, key)
+}
+
+// ModelStatus returns information about the malina bundles currently
+// represented in the pool. Loaded bundles come from the engine cache;
+// in-flight loads come from the shared resman, filtered by
+// engine.HasTicket so this pool does not surface another backend's
+// reservations.
+//
+// VRAMTotal on each entry reports the bytes the resman has actually
+// charged for the bundle (component weights + planner overhead).
+func (p *Pool) ModelStatus() ([]ModelDetail, error) {
+	usage := p.resman.Usage()
+	reservedByKey := make(map[string]int64, len(usage.Reservations))
+	for _, r := range usage.Reservations {
+		reservedByKey[r.Key] = r.VRAMBytes + r.RAMBytes
+	}
+
+	ps := make([]ModelDetail, 0)
+	loaded := make(map[string]struct{})
+
+	for entry := range p.engine.Coldest() {
+		size, _ := p.loader.bundleSize(entry.Key)
+
+		ps = append(ps, ModelDetail{
+			ID:            entry.Key,
+			Backend:       "malina",
+			Size:          size,
+			VRAMTotal:     reservedByKey[entry.Key],
+			ExpiresAt:     p.engine.EntryExpiresAt(entry),
+			ActiveStreams: entry.Value.ActiveStreams(),
+			Status:        ModelStatusLoaded,
+		})
+		loaded[entry.Key] = struct{}{}
+	}
+
+	for _, r := range usage.Reservations {
+		if _, ok := loaded[r.Key]; ok {
+			continue
+		}
+		if !p.engine.HasTicket(r.Key) {
+			continue
+		}
+
+		size, _ := p.loader.bundleSize(r.Key)
+
+		ps = append(ps, ModelDetail{
+			ID:        r.Key,
+			Backend:   "malina",
+			Size:      size,
+			VRAMTotal: r.VRAMBytes + r.RAMBytes,
+			Status:    ModelStatusLoading,
+		})
+	}
+
+	return ps, nil
+}
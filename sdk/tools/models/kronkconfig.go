@@ -342,50 +342,55 @@ func (s SamplingConfig) toParams() model.Params {
 
 // ModelConfig represents default model config settings.
 type ModelConfig struct {
-	Adapters              []AdapterConfig           `yaml:"adapters,omitempty"`
-	PtrAdmissionTimeout   *Duration                 `yaml:"admission-timeout,omitempty"`
-	PtrCacheMinTokens     *int                      `yaml:"cache-min-tokens,omitempty"`
-	CacheTypeK            model.GGMLType            `yaml:"cache-type-k,omitempty"`
-	CacheTypeV            model.GGMLType            `yaml:"cache-type-v,omitempty"`
-	ChatTemplateKwargs    ChatTemplateKwargs        `yaml:"chat-template-kwargs,omitempty"`
-	PtrContextWindow      *int                      `yaml:"context-window,omitempty"`
-	Devices               []string                  `yaml:"devices,omitempty"`
-	DraftModel            *DraftModelConfig         `yaml:"draft-model,omitempty"`
-	FlashAttention        *model.FlashAttentionType `yaml:"flash-attention,omitempty"`
-	PtrIMCSessionCapacity *int                      `yaml:"imc-session-capacity,omitempty"`
-	PtrIncrementalCache   *bool                     `yaml:"incremental-cache,omitempty"`
-	PtrInsecureLogging    *bool                     `yaml:"insecure-logging,omitempty"`
-	PtrLoadMode           *model.LoadMode           `yaml:"load-mode,omitempty"`
-	PtrMainGPU            *int                      `yaml:"main-gpu,omitempty"`
-	MoE                   *model.MoEConfig          `yaml:"moe,omitempty"`
-	PtrNGpuLayers         *int                      `yaml:"ngpu-layers,omitempty"`
-	PtrNSeqMax            *int                      `yaml:"nseq-max,omitempty"`
-	PtrNThreads           *int                      `yaml:"nthreads,omitempty"`
-	PtrNThreadsBatch      *int                      `yaml:"nthreads-batch,omitempty"`
-	PtrPrefillBatchSize   *int                      `yaml:"prefill-batch-size,omitempty"`
-	NUMA                  string                    `yaml:"numa,omitempty"`
-	PtrOffloadKQV         *bool                     `yaml:"offload-kqv,omitempty"`
-	PtrOpOffload          *bool                     `yaml:"op-offload,omitempty"`
-	PtrOpOffloadMinBatch  *int                      `yaml:"op-offload-min-batch,omitempty"`
-	PtrProjOnCPU          *bool                     `yaml:"proj-on-cpu,omitempty"`
-	ProjDevice            string                    `yaml:"proj-device,omitempty"`
-	PtrQueueDepth         *int                      `yaml:"queue-depth,omitempty"`
-	PtrRopeFreqBase       *float32                  `yaml:"rope-freq-base,omitempty"`
-	PtrRopeFreqScale      *float32                  `yaml:"rope-freq-scale,omitempty"`
-	RopeScaling           model.RopeScalingType     `yaml:"rope-scaling-type,omitempty"`
-	Sampling              SamplingConfig            `yaml:"sampling-parameters,omitempty"`
-	SessionStoreKind      kvstorage.Kind            `yaml:"session-store-kind,omitempty"`
-	Speculation           model.SpeculationMode     `yaml:"speculation,omitempty"`
-	PtrSplitMode          *model.SplitMode          `yaml:"split-mode,omitempty"`
-	PtrSWAFull            *bool                     `yaml:"swa-full,omitempty"`
-	TensorBuftOverrides   []string                  `yaml:"tensor-buft-overrides,omitempty"`
-	TensorSplit           []float32                 `yaml:"tensor-split,omitempty"`
-	Template              string                    `yaml:"template,omitempty"`
-	PtrYarnAttnFactor     *float32                  `yaml:"yarn-attn-factor,omitempty"`
-	PtrYarnBetaFast       *float32                  `yaml:"yarn-beta-fast,omitempty"`
-	PtrYarnBetaSlow       *float32                  `yaml:"yarn-beta-slow,omitempty"`
-	PtrYarnExtFactor      *float32                  `yaml:"yarn-ext-factor,omitempty"`
-	PtrYarnOrigCtx        *int                      `yaml:"yarn-orig-ctx,omitempty"`
+	Adapters               []AdapterConfig           `yaml:"adapters,omitempty"`
+	PtrAdmissionTimeout    *Duration                 `yaml:"admission-timeout,omitempty"`
+	PtrCacheMinTokens      *int                      `yaml:"cache-min-tokens,omitempty"`
+	CacheTypeK             model.GGMLType            `yaml:"cache-type-k,omitempty"`
+	CacheTypeV             model.GGMLType            `yaml:"cache-type-v,omitempty"`
+	ChatTemplateKwargs     ChatTemplateKwargs        `yaml:"chat-template-kwargs,omitempty"`
+	PtrContextShift        *bool                     `yaml:"context-shift,omitempty"`
+	PtrContextWindow       *int                      `yaml:"context-window,omitempty"`
+	Devices                []string                  `yaml:"devices,omitempty"`
+	DraftModel             *DraftModelConfig         `yaml:"draft-model,omitempty"`
+	FlashAttention         *model.FlashAttentionType `yaml:"flash-attention,omitempty"`
+	PtrIMCSessionCapacity  *int                      `yaml:"imc-session-capacity,omitempty"`
+	PtrIncrementalCache    *bool                     `yaml:"incremental-cache,omitempty"`
+	PtrInsecureLogging     *bool                     `yaml:"insecure-logging,omitempty"`
+	PtrLoadMode            *model.LoadMode           `yaml:"load-mode,omitempty"`
+	PtrMainGPU             *int                      `yaml:"main-gpu,omitempty"`
+	MoE                    *model.MoEConfig          `yaml:"moe,omitempty"`
+	PtrNGpuLayers          *int                      `yaml:"ngpu-layers,omitempty"`
+	PtrNSeqMax             *int                      `yaml:"nseq-max,omitempty"`
+	PtrNThreads            *int                      `yaml:"nthreads,omitempty"`
+	PtrNThreadsBatch       *int                      `yaml:"nthreads-batch,omitempty"`
+	PtrPrefillBatchSize    *int                      `yaml:"prefill-batch-size,omitempty"`
+	NUMA                   string                    `yaml:"numa,omitempty"`
+	PtrOffloadKQV          *bool                     `yaml:"offload-kqv,omitempty"`
+	PtrOpOffload           *bool                     `yaml:"op-offload,omitempty"`
+	PtrOpOffloadMinBatch   *int                      `yaml:"op-offload-min-batch,omitempty"`
+	PtrProjOnCPU           *bool                     `yaml:"proj-on-cpu,omitempty"`
+	ProjDevice             string                    `yaml:"proj-device,omitempty"`
+	PtrQueueDepth          *int                      `yaml:"queue-depth,omitempty"`
+	PtrRopeFreqBase        *float32                  `yaml:"rope-freq-base,omitempty"`
+	PtrRopeFreqScale       *float32                  `yaml:"rope-freq-scale,omitempty"`
+	RopeScaling            model.RopeScalingType     `yaml:"rope-scaling-type,omitempty"`
+	Sampling               SamplingConfig            `yaml:"sampling-parameters,omitempty"`
+	SessionStoreKind       kvstorage.Kind            `yaml:"session-store-kind,omitempty"`
+	SessionStoreDir        string                    `yaml:"session-store-dir,omitempty"`
+	PtrSessionStoreMaxRAM  *int                      `yaml:"session-store-max-ram-mb,omitempty"`
+	PtrSessionStoreMaxDisk *int                      `yaml:"session-store-max-disk-mb,omitempty"`
+	Speculation            model.SpeculationMode     `yaml:"speculation,omitempty"`
+	PtrSplitMode           *model.SplitMode          `yaml:"split-mode,omitempty"`
+	PtrSWAFull             *bool                     `yaml:"swa-full,omitempty"`
+	TensorBuftOverrides    []string                  `yaml:"tensor-buft-overrides,omitempty"`
+	TensorSplit            []float32                 `yaml:"tensor-split,omitempty"`
+	Template               string                    `yaml:"template,omitempty"`
+	Truncation             model.TruncationMode      `yaml:"truncation,omitempty"`
+	PtrYarnAttnFactor      *float32                  `yaml:"yarn-attn-factor,omitempty"`
+	PtrYarnBetaFast        *float32                  `yaml:"yarn-beta-fast,omitempty"`
+	PtrYarnBetaSlow        *float32                  `yaml:"yarn-beta-slow,omitempty"`
+	PtrYarnExtFactor       *float32                  `yaml:"yarn-ext-factor,omitempty"`
+	PtrYarnOrigCtx         *int                      `yaml:"yarn-orig-ctx,omitempty"`
 }
 
 // AdapterConfig identifies a local llama.cpp-compatible LoRA adapter GGUF.
@@ -402,7 +407,8 @@ type AdapterConfig struct {
 // Set model-id to use a separate draft GGUF (classic speculative decoding;
 // requires nseq-max: 1). Omit model-id and set only ndraft to override the
 // draft-token count for an auto-detected MTP head on the target (defaults to
-// 3 when unset).
+// 3 when unset), or the candidate count for speculation: ngram (defaults to
+// 8 when unset).
 type DraftModelConfig struct {
 	Devices       []string  `yaml:"devices,omitempty"`
 	PtrMainGPU    *int      `yaml:"main-gpu,omitempty"`
@@ -419,6 +425,7 @@ func (mc ModelConfig) ToKronkConfig() model.Config {
 		CacheTypeK:            mc.CacheTypeK,
 		CacheTypeV:            mc.CacheTypeV,
 		ChatTemplateKwargs:    model.D(mc.ChatTemplateKwargs).Clone(),
+		PtrContextShift:       mc.PtrContextShift,
 		PtrContextWindow:      mc.PtrContextWindow,
 		DefaultParams:         mc.Sampling.toParams(),
 		Devices:               mc.Devices,
@@ -450,6 +457,7 @@ func (mc ModelConfig) ToKronkConfig() model.Config {
 		PtrSWAFull:            mc.PtrSWAFull,
 		TensorBuftOverrides:   mc.TensorBuftOverrides,
 		TensorSplit:           mc.TensorSplit,
+		Truncation:            mc.Truncation,
 		PtrYarnAttnFactor:     mc.PtrYarnAttnFactor,
 		PtrYarnBetaFast:       mc.PtrYarnBetaFast,
 		PtrYarnBetaSlow:       mc.PtrYarnBetaSlow,
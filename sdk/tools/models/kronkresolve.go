@@ -10,6 +10,7 @@ import (
 
 	"github.com/ardanlabs/kronk/sdk/kronk/gguf"
 	"github.com/ardanlabs/kronk/sdk/kronk/kvstorage"
+	"github.com/ardanlabs/kronk/sdk/kronk/kvstorage/disk"
 	"github.com/ardanlabs/kronk/sdk/kronk/kvstorage/ram"
 	"github.com/ardanlabs/kronk/sdk/kronk/model"
 	"github.com/ardanlabs/kronk/sdk/tools/devices"
@@ -90,7 +91,7 @@ func (m *Models) kronkResolvedConfig(modelID string, mc map[string]ModelConfig,
 	out.AutoTuned = autoTuned
 	out.ModelFiles = fp.ModelFiles
 	out.ProjFile = fp.ProjFile
-	out.SessionStoreFactory, err = resolveSessionStoreFactory(cfg)
+	out.SessionStoreFactory, err = m.resolveSessionStoreFactory(modelID, cfg)
 	if err != nil {
 		return model.Config{}, fmt.Errorf("kronk-resolved-config: %w", err)
 	}
@@ -137,12 +138,45 @@ func (m *Models) kronkResolvedConfig(modelID string, mc map[string]ModelConfig,
 	return out, nil
 }
 
-func resolveSessionStoreFactory(cfg ModelConfig) (model.SessionStoreFactory, error) {
+// resolveSessionStoreFactory constructs the IMC session-store factory
+// selected by session-store-kind. A relative session-store-dir resolves
+// against the kronk base directory; the disk kind defaults to a per-model
+// folder beneath it.
+func (m *Models) resolveSessionStoreFactory(modelID string, cfg ModelConfig) (model.SessionStoreFactory, error) {
 	switch cfg.SessionStoreKind {
 	case kvstorage.Kind{}, kvstorage.RAM:
 		return ram.NewFactory(), nil
+
+	case kvstorage.Disk:
+		dir := cfg.SessionStoreDir
+		switch {
+		case dir == "":
+			dir = filepath.Join(m.basePath, "sessions", filepath.FromSlash(modelID))
+		case !filepath.IsAbs(dir):
+			dir = filepath.Join(m.basePath, dir)
+		}
+
+		var maxRAM, maxDisk int64
+		if cfg.PtrSessionStoreMaxRAM != nil {
+			maxRAM = int64(*cfg.PtrSessionStoreMaxRAM) << 20
+		}
+		if cfg.PtrSessionStoreMaxDisk != nil {
+			maxDisk = int64(*cfg.PtrSessionStoreMaxDisk) << 20
+		}
+
+		factory, err := disk.NewFactory(disk.Config{
+			Dir:          dir,
+			MaxRAMBytes:  maxRAM,
+			MaxDiskBytes: maxDisk,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("session-store: %w", err)
+		}
+
+		return factory, nil
+
 	default:
-		return nil, fmt.Errorf("session-store: unknown kind %q (valid: %q)", cfg.SessionStoreKind, kvstorage.RAM)
+		return nil, fmt.Errorf("session-store: unknown kind %q (valid: %q, %q)", cfg.SessionStoreKind, kvstorage.RAM, kvstorage.Disk)
 	}
 }
 
@@ -240,12 +274,24 @@ func (m *Models) resolveAdapters(adapters []AdapterConfig) ([]model.AdapterConfi
 		}
 
 		seen[adapterPath] = struct{}{}
-		resolved = append(resolved, model.AdapterConfig{Path: adapterPath, Scale: scale})
+		resolved = append(resolved, model.AdapterConfig{ID: adapter.ID, Path: adapterPath, Scale: scale})
 	}
 
 	return resolved, nil
 }
 
+// ResolveAdapter converts a single adapter id or path into the concrete
+// runtime adapter, applying the same rules as the adapters of a model
+// configuration. It resolves adapters loaded against a running model.
+func (m *Models) ResolveAdapter(adapter AdapterConfig) (model.AdapterConfig, error) {
+	resolved, err := m.resolveAdapters([]AdapterConfig{adapter})
+	if err != nil {
+		return model.AdapterConfig{}, err
+	}
+
+	return resolved[0], nil
+}
+
 func validateAdapterID(id string) error {
 	if id != strings.TrimSpace(id) || id == "" {
 		return fmt.Errorf("must not be empty or contain surrounding whitespace")
@@ -470,9 +516,24 @@ func MergeModelConfig(dst *ModelConfig, src ModelConfig) {
 	if src.SessionStoreKind != (kvstorage.Kind{}) {
 		dst.SessionStoreKind = src.SessionStoreKind
 	}
+	if src.SessionStoreDir != "" {
+		dst.SessionStoreDir = src.SessionStoreDir
+	}
+	if src.PtrSessionStoreMaxRAM != nil {
+		dst.PtrSessionStoreMaxRAM = src.PtrSessionStoreMaxRAM
+	}
+	if src.PtrSessionStoreMaxDisk != nil {
+		dst.PtrSessionStoreMaxDisk = src.PtrSessionStoreMaxDisk
+	}
 	if src.Speculation != "" {
 		dst.Speculation = src.Speculation
 	}
+	if src.Truncation != "" {
+		dst.Truncation = src.Truncation
+	}
+	if src.PtrContextShift != nil {
+		dst.PtrContextShift = src.PtrContextShift
+	}
 	if src.PtrInsecureLogging != nil {
 		dst.PtrInsecureLogging = src.PtrInsecureLogging
 	}
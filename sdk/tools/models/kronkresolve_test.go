@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"math"
 	"os"
 	"path/filepath"
@@ -9,24 +10,34 @@ import (
 	"time"
 
 	"github.com/ardanlabs/kronk/sdk/kronk/kvstorage"
-	"github.com/ardanlabs/kronk/sdk/kronk/kvstorage/ram"
 	"github.com/ardanlabs/kronk/sdk/kronk/model"
 	"go.yaml.in/yaml/v2"
 )
 
 func TestResolveSessionStoreFactory(t *testing.T) {
+	basePath := t.TempDir()
+	m := Models{basePath: basePath}
+
+	absDir := t.TempDir()
+
 	tests := []struct {
-		name    string
-		cfg     ModelConfig
-		wantErr bool
+		name      string
+		cfg       ModelConfig
+		wantStore string
+		wantDir   string
+		wantErr   bool
 	}{
-		{"default RAM", ModelConfig{}, false},
-		{"explicit RAM", ModelConfig{SessionStoreKind: kvstorage.RAM}, false},
+		{"default RAM", ModelConfig{}, "*ram.Store", "", false},
+		{"explicit RAM", ModelConfig{SessionStoreKind: kvstorage.RAM}, "*ram.Store", "", false},
+		{"disk default dir", ModelConfig{SessionStoreKind: kvstorage.Disk}, "*disk.Store", filepath.Join(basePath, "sessions", "acme", "model"), false},
+		{"disk relative dir", ModelConfig{SessionStoreKind: kvstorage.Disk, SessionStoreDir: "parked"}, "*disk.Store", filepath.Join(basePath, "parked"), false},
+		{"disk absolute dir", ModelConfig{SessionStoreKind: kvstorage.Disk, SessionStoreDir: absDir, PtrSessionStoreMaxRAM: new(64), PtrSessionStoreMaxDisk: new(1024)}, "*disk.Store", absDir, false},
+		{"disk negative RAM", ModelConfig{SessionStoreKind: kvstorage.Disk, PtrSessionStoreMaxRAM: new(-1)}, "", "", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			factory, err := resolveSessionStoreFactory(tt.cfg)
+			factory, err := m.resolveSessionStoreFactory("acme/model", tt.cfg)
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("resolveSessionStoreFactory() error = %v, wantErr %t", err, tt.wantErr)
 			}
@@ -44,8 +55,14 @@ func TestResolveSessionStoreFactory(t *testing.T) {
 				}
 			})
 
-			if _, ok := store.(*ram.Store); !ok {
-				t.Errorf("factory() store = %T, want *ram.Store", store)
+			if got := fmt.Sprintf("%T", store); got != tt.wantStore {
+				t.Errorf("factory() store = %s, want %s", got, tt.wantStore)
+			}
+
+			if tt.wantDir != "" {
+				if fi, err := os.Stat(tt.wantDir); err != nil || !fi.IsDir() {
+					t.Errorf("session-store dir %q was not created: %v", tt.wantDir, err)
+				}
 			}
 		})
 	}
@@ -59,6 +76,7 @@ func TestSessionStoreKindYAML(t *testing.T) {
 		wantErr bool
 	}{
 		{"RAM", "ram", kvstorage.RAM, false},
+		{"disk", "disk", kvstorage.Disk, false},
 		{"unknown", "unknown", kvstorage.Kind{}, true},
 	}
 
@@ -107,6 +125,7 @@ func TestResolveAdapters(t *testing.T) {
 	tests := []struct {
 		name      string
 		adapters  []AdapterConfig
+		wantID    string
 		wantPath  string
 		wantScale float32
 		wantErr   string
@@ -114,12 +133,14 @@ func TestResolveAdapters(t *testing.T) {
 		{
 			name:      "id resolves under lora folder with default scale",
 			adapters:  []AdapterConfig{{ID: "acme/support"}},
+			wantID:    "acme/support",
 			wantPath:  idPath,
 			wantScale: 1,
 		},
 		{
 			name:      "id without organization resolves under lora folder",
 			adapters:  []AdapterConfig{{ID: "support"}},
+			wantID:    "support",
 			wantPath:  plainIDPath,
 			wantScale: 1,
 		},
@@ -155,6 +176,9 @@ func TestResolveAdapters(t *testing.T) {
 			if len(got) != 1 {
 				t.Fatalf("resolveAdapters() returned %d adapters, want 1", len(got))
 			}
+			if got[0].ID != tt.wantID {
+				t.Errorf("ID = %q, want %q", got[0].ID, tt.wantID)
+			}
 			if got[0].Path != tt.wantPath {
 				t.Errorf("Path = %q, want %q", got[0].Path, tt.wantPath)
 			}
@@ -444,6 +468,36 @@ func TestModelConfigSpeculation(t *testing.T) {
 	}
 }
 
+func TestModelConfigTruncation(t *testing.T) {
+	data := []byte(`test-model:
+  truncation: auto
+  context-shift: true
+`)
+
+	var configs map[string]ModelConfig
+	if err := yaml.Unmarshal(data, &configs); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	cfg := configs["test-model"]
+	kcfg := cfg.ToKronkConfig()
+	if got := kcfg.TruncationMode(); got != model.TruncationAuto {
+		t.Errorf("TruncationMode() = %q, want %q", got, model.TruncationAuto)
+	}
+	if !kcfg.ContextShift() {
+		t.Error("ContextShift() = false, want true")
+	}
+
+	MergeModelConfig(&cfg, ModelConfig{Truncation: model.TruncationMiddleOut, PtrContextShift: new(false)})
+	kcfg = cfg.ToKronkConfig()
+	if got := kcfg.TruncationMode(); got != model.TruncationMiddleOut {
+		t.Errorf("merged TruncationMode() = %q, want %q", got, model.TruncationMiddleOut)
+	}
+	if kcfg.ContextShift() {
+		t.Error("merged ContextShift() = true, want false")
+	}
+}
+
 func TestModelConfigFlashAttentionPresence(t *testing.T) {
 	unset := ModelConfig{}.ToKronkConfig()
 	if unset.PtrFlashAttention != nil {